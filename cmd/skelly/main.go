@@ -9,6 +9,9 @@ import (
 var version = "0.1.0-dev"
 
 func main() {
+	if code, delegated := cli.Delegate(version, os.Args[1:]); delegated {
+		os.Exit(code)
+	}
 	if err := cli.NewRootCommand(version).Execute(); err != nil {
 		os.Exit(1)
 	}
@@ -0,0 +1,128 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/morozRed/skelly/internal/nav"
+	"github.com/morozRed/skelly/internal/output"
+	"github.com/morozRed/skelly/internal/state"
+	"github.com/spf13/cobra"
+)
+
+// RunAliases lists forwarding entries recorded for moved and renamed symbols.
+func RunAliases(cmd *cobra.Command, args []string) error {
+	rootPath, err := resolveWorkingDirectory()
+	if err != nil {
+		return err
+	}
+	asJSON, err := cmd.Flags().GetBool("json")
+	if err != nil {
+		return fmt.Errorf("failed to read --json flag: %w", err)
+	}
+
+	st, err := loadAliasState(rootPath)
+	if err != nil {
+		return err
+	}
+
+	ids := make([]string, 0, len(st.Aliases))
+	for id := range st.Aliases {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	records := make([]AliasRecord, 0, len(ids))
+	for _, id := range ids {
+		alias := st.Aliases[id]
+		records = append(records, AliasRecord{
+			ID:        id,
+			Target:    alias.Target,
+			Reason:    alias.Reason,
+			CreatedAt: alias.CreatedAt.UTC().Format(time.RFC3339),
+		})
+	}
+
+	if asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(records)
+	}
+	if len(records) == 0 {
+		fmt.Println("no symbol aliases")
+		return nil
+	}
+	for _, record := range records {
+		fmt.Printf("%s -> %s (%s %s)\n", record.ID, record.Target, record.Reason, record.CreatedAt)
+	}
+	return nil
+}
+
+// RunAliasesPrune retires forwarding entries older than --older-than (all by
+// default) plus entries whose target symbol no longer exists.
+func RunAliasesPrune(cmd *cobra.Command, args []string) error {
+	rootPath, err := resolveWorkingDirectory()
+	if err != nil {
+		return err
+	}
+	asJSON, err := cmd.Flags().GetBool("json")
+	if err != nil {
+		return fmt.Errorf("failed to read --json flag: %w", err)
+	}
+	olderThan, err := cmd.Flags().GetDuration("older-than")
+	if err != nil {
+		return fmt.Errorf("failed to read --older-than flag: %w", err)
+	}
+	if olderThan < 0 {
+		return fmt.Errorf("--older-than must be >= 0")
+	}
+
+	st, err := loadAliasState(rootPath)
+	if err != nil {
+		return err
+	}
+
+	cutoff := time.Now().Add(-olderThan)
+	removed := st.PruneAliases(cutoff)
+	if len(removed) > 0 {
+		contextDir := filepath.Join(rootPath, output.ContextDir)
+		if err := st.Save(contextDir); err != nil {
+			return fmt.Errorf("failed to persist state: %w", err)
+		}
+		if err := nav.WriteAliases(contextDir, st.AliasTargets()); err != nil {
+			return err
+		}
+	}
+
+	summary := AliasesPruneSummary{
+		Mode:      "aliases-prune",
+		RootPath:  rootPath,
+		Removed:   len(removed),
+		Remaining: len(st.Aliases),
+		IDs:       removed,
+	}
+	if asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(summary)
+	}
+	fmt.Printf("aliases prune: removed=%d remaining=%d\n", summary.Removed, summary.Remaining)
+	if len(removed) > 0 {
+		fmt.Printf("removed (%d): %s\n", len(removed), SummarizePaths(removed, 8))
+	}
+	return nil
+}
+
+func loadAliasState(rootPath string) (*state.State, error) {
+	st, err := state.Load(filepath.Join(rootPath, output.ContextDir))
+	if err != nil {
+		if IsCorruptStateError(err) {
+			return nil, fmt.Errorf("state is corrupt; run `skelly generate` first")
+		}
+		return nil, fmt.Errorf("failed to load state: %w", err)
+	}
+	return st, nil
+}
@@ -0,0 +1,165 @@
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/morozRed/skelly/internal/fileutil"
+	"github.com/morozRed/skelly/internal/graph"
+	"github.com/morozRed/skelly/internal/output"
+	"github.com/morozRed/skelly/internal/state"
+	"github.com/spf13/cobra"
+)
+
+// LanguageCalibration is one language's row in `skelly calibration`.
+type LanguageCalibration struct {
+	Language   string                  `json:"language"`
+	CallSites  int                     `json:"call_sites"`
+	Resolved   float64                 `json:"resolved"`
+	Heuristic  float64                 `json:"heuristic"`
+	Ambiguous  float64                 `json:"ambiguous"`
+	Unresolved float64                 `json:"unresolved"`
+	Counts     state.CalibrationCounts `json:"counts"`
+	// Trend is the change in each fraction since the previous recorded run.
+	Trend   *CalibrationTrend      `json:"trend,omitempty"`
+	Samples []graph.UnresolvedCall `json:"unresolved_samples,omitempty"`
+}
+
+// CalibrationTrend is the change in resolution fractions between two runs.
+type CalibrationTrend struct {
+	Since      time.Time `json:"since"`
+	CallSites  int       `json:"call_sites"`
+	Resolved   float64   `json:"resolved"`
+	Heuristic  float64   `json:"heuristic"`
+	Ambiguous  float64   `json:"ambiguous"`
+	Unresolved float64   `json:"unresolved"`
+}
+
+// RunCalibration reports, per language, how call sites resolve in the
+// current index, with sampled misses and the trend since the previous run.
+func RunCalibration(cmd *cobra.Command, args []string) error {
+	rootPath, err := resolveWorkingDirectory()
+	if err != nil {
+		return err
+	}
+	asJSON, err := cmd.Flags().GetBool("json")
+	if err != nil {
+		return fmt.Errorf("failed to read --json flag: %w", err)
+	}
+	samples, err := cmd.Flags().GetInt("samples")
+	if err != nil {
+		return fmt.Errorf("failed to read --samples flag: %w", err)
+	}
+
+	contextDir := filepath.Join(rootPath, output.ContextDir)
+	st, err := state.Load(contextDir)
+	if err != nil {
+		if IsCorruptStateError(err) {
+			return fmt.Errorf("state is corrupt; run `skelly generate` first")
+		}
+		return fmt.Errorf("failed to load state: %w", err)
+	}
+	if len(st.Files) == 0 {
+		return fmt.Errorf("no indexed files found; run `skelly generate` first")
+	}
+
+	hashes := make(map[string]string, len(st.Files))
+	for file, fileState := range st.Files {
+		hashes[file] = fileState.Hash
+	}
+	g := graph.BuildFromParseResult(fileutil.ParseResultFromState(st, rootPath, hashes))
+	report := BuildCalibrationReport(g, st, samples)
+
+	if asJSON {
+		return fileutil.PrintJSON(map[string]any{
+			"mode":      "calibration",
+			"languages": report,
+		})
+	}
+
+	for _, row := range report {
+		fmt.Printf("%s: call_sites=%d resolved=%.1f%% heuristic=%.1f%% ambiguous=%.1f%% unresolved=%.1f%%\n",
+			row.Language, row.CallSites, row.Resolved*100, row.Heuristic*100, row.Ambiguous*100, row.Unresolved*100)
+		if row.Trend != nil {
+			fmt.Printf("  trend since %s: call_sites=%+d resolved=%+.1f%% ambiguous=%+.1f%% unresolved=%+.1f%%\n",
+				row.Trend.Since.Format(time.RFC3339), row.Trend.CallSites,
+				row.Trend.Resolved*100, row.Trend.Ambiguous*100, row.Trend.Unresolved*100)
+		}
+		for _, sample := range row.Samples {
+			kind := "unresolved"
+			if sample.Ambiguous {
+				kind = "ambiguous"
+			}
+			fmt.Printf("  - %s:%d %s -> %s (%s)\n", sample.File, sample.Line, sample.Caller, sample.Call, kind)
+		}
+	}
+	return nil
+}
+
+// BuildCalibrationReport rows are sorted weakest first (highest share of
+// ambiguous plus unresolved call sites), keeping up to samples misses each.
+func BuildCalibrationReport(g *graph.Graph, st *state.State, samples int) []LanguageCalibration {
+	current := CalibrationRunFromGraph(g, time.Now())
+	previous, hasPrevious := st.PreviousCalibration(current.Languages)
+
+	rows := make([]LanguageCalibration, 0, len(current.Languages))
+	for language, counts := range current.Languages {
+		row := LanguageCalibration{Language: language, CallSites: counts.Total(), Counts: counts}
+		row.Resolved, row.Heuristic, row.Ambiguous, row.Unresolved = calibrationShares(counts)
+		if hasPrevious {
+			if before, ok := previous.Languages[language]; ok {
+				resolved, heuristic, ambiguous, unresolved := calibrationShares(before)
+				row.Trend = &CalibrationTrend{
+					Since:      previous.At,
+					CallSites:  counts.Total() - before.Total(),
+					Resolved:   row.Resolved - resolved,
+					Heuristic:  row.Heuristic - heuristic,
+					Ambiguous:  row.Ambiguous - ambiguous,
+					Unresolved: row.Unresolved - unresolved,
+				}
+			}
+		}
+		if stats := g.Resolution[language]; stats != nil && samples > 0 {
+			row.Samples = stats.Samples[:min(samples, len(stats.Samples))]
+		}
+		rows = append(rows, row)
+	}
+
+	sort.Slice(rows, func(i, j int) bool {
+		missI := rows[i].Ambiguous + rows[i].Unresolved
+		missJ := rows[j].Ambiguous + rows[j].Unresolved
+		if missI != missJ {
+			return missI > missJ
+		}
+		return rows[i].Language < rows[j].Language
+	})
+	return rows
+}
+
+// CalibrationRunFromGraph snapshots the graph's per-language resolution counts.
+func CalibrationRunFromGraph(g *graph.Graph, at time.Time) state.CalibrationRun {
+	run := state.CalibrationRun{At: at, Languages: make(map[string]state.CalibrationCounts, len(g.Resolution))}
+	for language, stats := range g.Resolution {
+		if stats.Total() == 0 {
+			continue
+		}
+		run.Languages[language] = state.CalibrationCounts{
+			Resolved:   stats.Resolved,
+			Heuristic:  stats.Heuristic,
+			Ambiguous:  stats.Ambiguous,
+			Unresolved: stats.Unresolved,
+		}
+	}
+	return run
+}
+
+func calibrationShares(counts state.CalibrationCounts) (resolved, heuristic, ambiguous, unresolved float64) {
+	total := float64(counts.Total())
+	if total == 0 {
+		return 0, 0, 0, 0
+	}
+	return float64(counts.Resolved) / total, float64(counts.Heuristic) / total,
+		float64(counts.Ambiguous) / total, float64(counts.Unresolved) / total
+}
@@ -0,0 +1,266 @@
+package cli
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/morozRed/skelly/internal/config"
+	"github.com/morozRed/skelly/internal/conventions"
+	"github.com/morozRed/skelly/internal/dirdocs"
+	"github.com/morozRed/skelly/internal/embed"
+	"github.com/morozRed/skelly/internal/enrich"
+	"github.com/morozRed/skelly/internal/fileutil"
+	"github.com/morozRed/skelly/internal/languages"
+	"github.com/morozRed/skelly/internal/nav"
+	"github.com/morozRed/skelly/internal/output"
+	"github.com/morozRed/skelly/internal/search"
+	"github.com/morozRed/skelly/internal/state"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// CapabilitiesSchemaVersion versions the `skelly capabilities` payload itself.
+const CapabilitiesSchemaVersion = "capabilities-v1"
+
+// Capabilities describes what the installed skelly supports, for wrapper
+// tools that should not parse --help text.
+type Capabilities struct {
+	SchemaVersion string               `json:"schema_version"`
+	Version       string               `json:"version"`
+	Languages     []LanguageCapability `json:"languages"`
+	LangFilters   []string             `json:"lang_filters"`
+	Formats       []string             `json:"formats"`
+	Orders        []string             `json:"orders"`
+	StateBackends []string             `json:"state_backends"`
+	Commands      []CommandCapability  `json:"commands"`
+	Artifacts     []ArtifactCapability `json:"artifacts"`
+	Features      map[string]bool      `json:"features"`
+}
+
+// LanguageCapability is one registered parser.
+type LanguageCapability struct {
+	Name       string   `json:"name"`
+	Extensions []string `json:"extensions"`
+}
+
+// CommandCapability is one runnable command, named by its path below the root.
+type CommandCapability struct {
+	Name  string           `json:"name"`
+	Use   string           `json:"use"`
+	Short string           `json:"short"`
+	Flags []FlagCapability `json:"flags,omitempty"`
+}
+
+// FlagCapability is one local flag of a command.
+type FlagCapability struct {
+	Name      string `json:"name"`
+	Shorthand string `json:"shorthand,omitempty"`
+	Type      string `json:"type"`
+	Default   string `json:"default,omitempty"`
+	Usage     string `json:"usage"`
+}
+
+// ArtifactCapability is a file skelly writes, relative to the repository root.
+type ArtifactCapability struct {
+	Path          string `json:"path"`
+	Format        string `json:"format,omitempty"`
+	SchemaVersion string `json:"schema_version,omitempty"`
+	Description   string `json:"description"`
+}
+
+// capabilityFeatures are optional behaviors wrappers may want to branch on.
+var capabilityFeatures = map[string]bool{
+	"lsp":                   true,
+	"watch_write_behind":    true,
+	"update_quick":          true,
+	"update_since":          true,
+	"hook_verify":           true,
+	"hook_stage_artifacts":  true,
+	"jsonl_namespaces":      true,
+	"jsonl_streaming":       true,
+	"state_backend_binary":  true,
+	"symbol_aliases":        true,
+	"calibration":           true,
+	"enrich_bootstrap":      true,
+	"docs_dirs":             true,
+	"trace_direction":       true,
+	"path_all":              true,
+	"module_graph":          true,
+	"export_dot":            true,
+	"export_mermaid":        true,
+	"export_lsif":           true,
+	"search_signature":      true,
+	"search_hybrid":         true,
+	"structural_grep":       true,
+	"http_api":              true,
+	"web_ui":                true,
+	"daemon":                true,
+	"enrich_summary_merge":  true,
+	"enrich_overview":       true,
+	"enrich_filters":        true,
+	"enrich_gc":             true,
+	"doctor_exit_codes":     true,
+	"ci_check":              true,
+	"pr_report":             true,
+	"monorepo_roots":        true,
+	"artifact_compression":  true,
+	"nav_shards":            true,
+	"incremental_indexes":   true,
+	"incremental_pagerank":  true,
+	"centrality_metrics":    true,
+	"symbol_spans":          true,
+	"symbol_visibility":     true,
+	"value_symbols":         true,
+	"enrich_full_body":      true,
+	"related_git_cochange":  true,
+	"snapshot_diff":         true,
+	"structural_diff":       true,
+	"conventions_notes":     true,
+	"deadcode_report":       true,
+	"languages_report":      true,
+	"parse_issues":          true,
+	"parse_error_recovery":  true,
+	"file_size_limit":       true,
+	"binary_detection":      true,
+	"structured_logging":    true,
+	"run_metrics":           true,
+	"managed_llm_templates": true,
+	"project_config":        true,
+	"gitignore":             true,
+	"generated_files":       true,
+	"parser_features":       true,
+	"external_code":         true,
+	"http_routes":           true,
+	"test_linkage":          true,
+	"codeowners":            true,
+	"context_pack":          true,
+	"semantic_search":       true,
+}
+
+// RunCapabilities prints the capability description of this build.
+func RunCapabilities(cmd *cobra.Command, version string) error {
+	asJSON, err := cmd.Flags().GetBool("json")
+	if err != nil {
+		return fmt.Errorf("failed to read --json flag: %w", err)
+	}
+
+	caps := BuildCapabilities(cmd.Root(), version)
+	if asJSON {
+		return fileutil.PrintJSON(caps)
+	}
+
+	fmt.Printf("skelly %s (%s)\n", caps.Version, caps.SchemaVersion)
+	for _, language := range caps.Languages {
+		fmt.Printf("language %s: %s\n", language.Name, strings.Join(language.Extensions, " "))
+	}
+	fmt.Printf("formats: %s\n", strings.Join(caps.Formats, ", "))
+	fmt.Printf("state backends: %s\n", strings.Join(caps.StateBackends, ", "))
+	for _, command := range caps.Commands {
+		fmt.Printf("command %s: %s\n", command.Name, command.Short)
+	}
+	for _, artifact := range caps.Artifacts {
+		fmt.Printf("artifact %s: %s\n", artifact.Path, artifact.Description)
+	}
+	features := fileutil.MapKeysSorted(caps.Features)
+	fmt.Printf("features: %s\n", strings.Join(features, ", "))
+	return nil
+}
+
+// BuildCapabilities walks the command tree under root and collects the
+// languages, formats, artifacts and features compiled into this build.
+func BuildCapabilities(root *cobra.Command, version string) Capabilities {
+	registry := languages.NewDefaultRegistry()
+	extensions := registry.LanguageExtensions()
+	languageCaps := make([]LanguageCapability, 0, len(extensions))
+	for name, exts := range extensions {
+		languageCaps = append(languageCaps, LanguageCapability{Name: name, Extensions: exts})
+	}
+	sort.Slice(languageCaps, func(i, j int) bool { return languageCaps[i].Name < languageCaps[j].Name })
+
+	features := make(map[string]bool, len(capabilityFeatures))
+	for name, enabled := range capabilityFeatures {
+		features[name] = enabled
+	}
+
+	return Capabilities{
+		SchemaVersion: CapabilitiesSchemaVersion,
+		Version:       version,
+		Languages:     languageCaps,
+		LangFilters:   languages.SupportedLanguages(),
+		Formats:       []string{string(output.FormatText), string(output.FormatJSONL), string(output.FormatCtags)},
+		Orders:        []string{string(output.OrderImportance), string(output.OrderPath)},
+		StateBackends: []string{string(state.BackendJSON), string(state.BackendBinary)},
+		Commands:      collectCommandCapabilities(root, nil),
+		Artifacts:     artifactCapabilities(),
+		Features:      features,
+	}
+}
+
+func collectCommandCapabilities(cmd *cobra.Command, parents []string) []CommandCapability {
+	commands := make([]CommandCapability, 0)
+	for _, child := range cmd.Commands() {
+		if child.Hidden || child.Name() == "help" || child.Name() == "completion" {
+			continue
+		}
+		names := append(append([]string(nil), parents...), child.Name())
+		if child.Runnable() {
+			commands = append(commands, CommandCapability{
+				Name:  strings.Join(names, " "),
+				Use:   child.Use,
+				Short: child.Short,
+				Flags: flagCapabilities(child),
+			})
+		}
+		commands = append(commands, collectCommandCapabilities(child, names)...)
+	}
+	sort.Slice(commands, func(i, j int) bool { return commands[i].Name < commands[j].Name })
+	return commands
+}
+
+func flagCapabilities(cmd *cobra.Command) []FlagCapability {
+	flags := make([]FlagCapability, 0)
+	cmd.LocalFlags().VisitAll(func(flag *pflag.Flag) {
+		if flag.Hidden || flag.Name == "help" {
+			return
+		}
+		flags = append(flags, FlagCapability{
+			Name:      flag.Name,
+			Shorthand: flag.Shorthand,
+			Type:      flag.Value.Type(),
+			Default:   flag.DefValue,
+			Usage:     flag.Usage,
+		})
+	})
+	return flags
+}
+
+func artifactCapabilities() []ArtifactCapability {
+	contextPath := func(name string) string { return path.Join(output.ContextDir, name) }
+	return []ArtifactCapability{
+		{Path: contextPath(state.StateFile), Format: "json", SchemaVersion: state.CurrentStateVersion, Description: "incremental state: file hashes, symbol snapshots, dependencies, output hashes"},
+		{Path: contextPath(state.BinaryStateFile), Format: "gob", SchemaVersion: state.CurrentStateVersion, Description: "binary state backend; replaces the JSON state file when selected"},
+		{Path: contextPath(output.IndexFile), Format: string(output.FormatText), SchemaVersion: state.CurrentOutputVersion, Description: "overview of key symbols and modules"},
+		{Path: contextPath(output.GraphFile), Format: string(output.FormatText), SchemaVersion: state.CurrentOutputVersion, Description: "dependency adjacency list"},
+		{Path: contextPath(output.ModulesDir) + "/", Format: string(output.FormatText), SchemaVersion: state.CurrentOutputVersion, Description: "per-module breakdown"},
+		{Path: contextPath(output.SymbolsFile), Format: string(output.FormatJSONL), SchemaVersion: output.JSONLSchemaVersion, Description: "one symbol per line with its file's CODEOWNERS owners (primary namespace)"},
+		{Path: contextPath(output.EdgesFile), Format: string(output.FormatJSONL), SchemaVersion: output.JSONLSchemaVersion, Description: "one call, inherit, implement, reference, render or generated-from edge per line (primary namespace)"},
+		{Path: contextPath(output.ModulesFile), Format: string(output.FormatJSONL), SchemaVersion: output.JSONLSchemaVersion, Description: "directory-level module graph: module summaries (LOC, imports, top symbols by rank, fan-in/fan-out), then weighted dependencies"},
+		{Path: contextPath(output.ManifestFile), Format: "json", SchemaVersion: output.JSONLSchemaVersion, Description: "JSONL counts, artifact hashes, namespaces and CODEOWNERS owner counts"},
+		{Path: contextPath(output.TagsFile), Format: string(output.FormatCtags), Description: "extended-format tags file sorted by name, for vim and other ctags consumers"},
+		{Path: contextPath(nav.NavigationIndexFile), Format: "json", SchemaVersion: nav.NavigationIndexVersion, Description: "navigation index for symbol/callers/callees/trace/path/pack, with docs, PageRank and betweenness"},
+		{Path: contextPath(nav.NavigationShardDir) + "/", Format: "json", SchemaVersion: nav.NavigationIndexVersion, Description: "navigation index shards, loaded on demand; written instead of inline nodes for large graphs"},
+		{Path: contextPath(nav.RoutesFile), Format: string(output.FormatJSONL), Description: "HTTP routes (method, path) mapped to handler symbol IDs"},
+		{Path: contextPath(nav.TestsFile), Format: string(output.FormatJSONL), Description: "test symbols mapped to the production symbols they call"},
+		{Path: contextPath(output.IssuesFile), Format: string(output.FormatJSONL), Description: "parse warnings and errors of the last generate: file, language, severity, message"},
+		{Path: contextPath(search.IndexFile), Format: "json", SchemaVersion: search.Version, Description: "lexical search index"},
+		{Path: contextPath(embed.File), Format: "gob", SchemaVersion: embed.Version, Description: "symbol embeddings from `enrich embed` for `search --semantic`"},
+		{Path: contextPath(enrich.OutputFile), Format: string(output.FormatJSONL), Description: "agent-written and bootstrapped symbol descriptions"},
+		{Path: contextPath(enrich.OverviewFile), Format: string(output.FormatJSONL), Description: "derived and agent-written file and directory overviews from `enrich overview`"},
+		{Path: config.File, Format: "yaml", Description: "project defaults for format, languages, order, jobs, state backend, ignore and .gitignore handling, generated and build-ignored file skipping, LLM integrations, update hooks and the embeddings provider"},
+		{Path: config.MetricsFile, Format: "json", Description: "phase durations, file counts, memory high-water mark and cache hit rates of the last generate or update, when metrics is set in the config"},
+		{Path: path.Join(output.SkellyDir, conventions.File), Format: "markdown", Description: "derived project conventions plus agent notes"},
+		{Path: path.Join("<dir>", dirdocs.File), Format: "markdown", Description: "per-directory orientation doc from `docs dirs`"},
+	}
+}
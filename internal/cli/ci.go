@@ -0,0 +1,246 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/morozRed/skelly/internal/config"
+	"github.com/morozRed/skelly/internal/enrich"
+	"github.com/morozRed/skelly/internal/fileutil"
+	"github.com/morozRed/skelly/internal/languages"
+	"github.com/morozRed/skelly/internal/nav"
+	"github.com/morozRed/skelly/internal/output"
+	"github.com/morozRed/skelly/internal/state"
+	"github.com/spf13/cobra"
+)
+
+// ciDiffLines caps the diff printed for one stale artifact.
+const ciDiffLines = 60
+
+// CISummary reports whether the committed context matches what a fresh
+// generate of the working tree would write.
+type CISummary struct {
+	Mode     string `json:"mode"`
+	RootPath string `json:"root_path"`
+	Format   string `json:"format"`
+	OK       bool   `json:"ok"`
+	// ChangedSources and DeletedSources are source files the recorded state
+	// does not reflect.
+	ChangedSources []string `json:"changed_sources,omitempty"`
+	DeletedSources []string `json:"deleted_sources,omitempty"`
+	// StaleArtifacts differ from the regenerated ones; MissingArtifacts
+	// would be created.
+	StaleArtifacts   []string `json:"stale_artifacts,omitempty"`
+	MissingArtifacts []string `json:"missing_artifacts,omitempty"`
+	DurationMS       int64    `json:"duration_ms"`
+}
+
+// RunCI regenerates the context of a copy of the working tree in a temporary
+// directory and compares it with the committed artifacts, like gofmt -l. It
+// prints the stale artifacts with a diff and fails when regenerating would
+// change anything; the repository is never written.
+func RunCI(cmd *cobra.Command, args []string) error {
+	start := time.Now()
+	rootPath, err := resolveWorkingDirectory()
+	if err != nil {
+		return err
+	}
+	if _, err := ApplyProjectConfig(cmd, rootPath); err != nil {
+		return err
+	}
+	languageFilter, err := ParseLanguageFilter(cmd)
+	if err != nil {
+		return err
+	}
+	format, err := ParseOutputFormat(cmd)
+	if err != nil {
+		return err
+	}
+	order, err := ParseIndexOrder(cmd)
+	if err != nil {
+		return err
+	}
+	asJSON, err := cmd.Flags().GetBool("json")
+	if err != nil {
+		return fmt.Errorf("failed to read --json flag: %w", err)
+	}
+	noDiff, err := cmd.Flags().GetBool("no-diff")
+	if err != nil {
+		return fmt.Errorf("failed to read --no-diff flag: %w", err)
+	}
+	noGitignore, err := nav.OptionalBoolFlag(cmd, "no-gitignore", false)
+	if err != nil {
+		return err
+	}
+
+	contextDir := filepath.Join(rootPath, output.ContextDir)
+	statePath, hasState := state.Path(contextDir)
+	if !hasState {
+		return fmt.Errorf("no committed context in %s; run `skelly generate` and commit it", output.ContextDir)
+	}
+	st, err := state.Load(contextDir)
+	if err != nil {
+		return fmt.Errorf("failed to load state: %w", err)
+	}
+	// Without a flag or config value, check against what was committed.
+	format = RecordedOutputFormat(cmd, rootPath, format)
+	if !cmd.Flags().Changed("order") && st.IndexOrder != "" {
+		if recorded, err := output.ParseOrder(st.IndexOrder); err == nil {
+			order = recorded
+		}
+	}
+	if !cmd.Flags().Changed("no-gitignore") {
+		noGitignore = st.NoGitignore
+	}
+	ignoreRules, err := loadIgnoreRules(rootPath, !noGitignore)
+	if err != nil {
+		return err
+	}
+	currentHashes, err := fileutil.ScanFileHashes(rootPath, languages.NewDefaultRegistry(), ignoreRules)
+	if err != nil {
+		return fmt.Errorf("failed to scan files: %w", err)
+	}
+	currentFiles := make(map[string]bool, len(currentHashes))
+	for file := range currentHashes {
+		currentFiles[file] = true
+	}
+
+	summary := CISummary{Mode: "ci", RootPath: rootPath, Format: string(format)}
+	summary.ChangedSources = fileutil.DedupeStrings(st.ChangedFiles(currentHashes))
+	summary.DeletedSources = st.DeletedFiles(currentFiles)
+	sort.Strings(summary.ChangedSources)
+	sort.Strings(summary.DeletedSources)
+
+	// The copy holds the scanned sources, the ignore and config files that
+	// shaped the scan, and the context inputs generate reads: the previous
+	// state (for alias history) and enrich.jsonl (for summaries).
+	tempRoot, err := os.MkdirTemp("", "skelly-ci-")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tempRoot)
+	tempContextDir := filepath.Join(tempRoot, output.ContextDir)
+	copies := []string{".skellyignore", filepath.FromSlash(config.File)}
+	for file := range currentHashes {
+		copies = append(copies, filepath.FromSlash(file))
+	}
+	for _, rel := range []string{filepath.Base(statePath), enrich.OutputFile} {
+		copies = append(copies, filepath.Join(output.ContextDir, rel))
+	}
+	for _, rel := range copies {
+		if err := copyFileIfExists(filepath.Join(rootPath, rel), filepath.Join(tempRoot, rel)); err != nil {
+			return err
+		}
+	}
+	if _, err := generateContext(tempRoot, languageFilter, format, order, true, 0, !noGitignore); err != nil {
+		return fmt.Errorf("failed to regenerate context: %w", err)
+	}
+	regenerated, err := state.Load(tempContextDir)
+	if err != nil {
+		return fmt.Errorf("failed to load regenerated state: %w", err)
+	}
+
+	artifacts := make([]string, 0, len(regenerated.OutputHashes))
+	for rel := range regenerated.OutputHashes {
+		artifacts = append(artifacts, rel)
+	}
+	sort.Strings(artifacts)
+	for _, rel := range artifacts {
+		committed, err := fileutil.HashArtifact(filepath.Join(contextDir, rel))
+		switch {
+		case os.IsNotExist(err):
+			summary.MissingArtifacts = append(summary.MissingArtifacts, filepath.ToSlash(rel))
+		case err != nil || committed != regenerated.OutputHashes[rel]:
+			summary.StaleArtifacts = append(summary.StaleArtifacts, filepath.ToSlash(rel))
+		}
+	}
+	summary.OK = len(summary.ChangedSources) == 0 && len(summary.DeletedSources) == 0 &&
+		len(summary.StaleArtifacts) == 0 && len(summary.MissingArtifacts) == 0
+	summary.DurationMS = time.Since(start).Milliseconds()
+
+	if asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(summary); err != nil {
+			return err
+		}
+	} else {
+		contextPath := filepath.ToSlash(output.ContextDir)
+		for _, file := range summary.ChangedSources {
+			fmt.Printf("changed source: %s\n", file)
+		}
+		for _, file := range summary.DeletedSources {
+			fmt.Printf("deleted source: %s\n", file)
+		}
+		for _, rel := range summary.MissingArtifacts {
+			fmt.Printf("missing: %s/%s\n", contextPath, rel)
+		}
+		for _, rel := range summary.StaleArtifacts {
+			fmt.Printf("stale: %s/%s\n", contextPath, rel)
+		}
+		if !noDiff {
+			for _, rel := range summary.StaleArtifacts {
+				fmt.Print(artifactDiff(rootPath, tempRoot, filepath.Join(output.ContextDir, rel)))
+			}
+		}
+		if summary.OK {
+			fmt.Printf("ci: ok (context matches %d sources, %dms)\n", len(currentHashes), summary.DurationMS)
+		}
+	}
+	if !summary.OK {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("context is out of date (stale=%d missing=%d changed sources=%d deleted sources=%d); run `skelly update` and commit %s",
+			len(summary.StaleArtifacts), len(summary.MissingArtifacts), len(summary.ChangedSources), len(summary.DeletedSources), filepath.ToSlash(output.ContextDir))
+	}
+	return nil
+}
+
+// artifactDiff returns `git diff --no-index` of a committed artifact against
+// its regenerated copy, with both sides named by the repository path and at
+// most ciDiffLines lines. It is empty when git is unavailable.
+func artifactDiff(rootPath, tempRoot, rel string) string {
+	diff := exec.Command("git", "diff", "--no-index", "--no-color", "--", filepath.Join(rootPath, rel), filepath.Join(tempRoot, rel))
+	out, err := diff.Output()
+	if err != nil {
+		if _, ok := err.(*exec.ExitError); !ok {
+			return ""
+		}
+	}
+	// git names both sides by their absolute paths without the leading slash;
+	// the headers name both by the repository path instead.
+	lines := strings.SplitAfter(string(out), "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, "diff --git ") || strings.HasPrefix(line, "--- ") || strings.HasPrefix(line, "+++ ") {
+			line = strings.ReplaceAll(line, strings.TrimPrefix(filepath.ToSlash(tempRoot), "/")+"/", "")
+			lines[i] = strings.ReplaceAll(line, strings.TrimPrefix(filepath.ToSlash(rootPath), "/")+"/", "")
+		}
+	}
+	if len(lines) > ciDiffLines {
+		hidden := len(lines) - ciDiffLines
+		lines = append(lines[:ciDiffLines], fmt.Sprintf("... (%d more diff lines)\n", hidden))
+	}
+	return strings.Join(lines, "")
+}
+
+func copyFileIfExists(src, dst string) error {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read %s: %w", src, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(dst), err)
+	}
+	if err := os.WriteFile(dst, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", dst, err)
+	}
+	return nil
+}
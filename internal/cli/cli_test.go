@@ -3,17 +3,38 @@ package cli
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
+	"fmt"
 	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"os"
+	"os/exec"
+	"path"
 	"path/filepath"
 	"reflect"
+	"runtime"
+	"slices"
+	"sort"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/morozRed/skelly/internal/config"
+	"github.com/morozRed/skelly/internal/daemon"
+	"github.com/morozRed/skelly/internal/deadcode"
+	"github.com/morozRed/skelly/internal/enrich"
+	"github.com/morozRed/skelly/internal/graph"
 	"github.com/morozRed/skelly/internal/llm"
 	"github.com/morozRed/skelly/internal/nav"
 	"github.com/morozRed/skelly/internal/output"
+	"github.com/morozRed/skelly/internal/owners"
+	"github.com/morozRed/skelly/internal/parser"
+	"github.com/morozRed/skelly/internal/search"
+	"github.com/morozRed/skelly/internal/snapshot"
 	"github.com/morozRed/skelly/internal/state"
 	"github.com/spf13/cobra"
 )
@@ -133,6 +154,57 @@ func TestInitWithLLMGeneratesIntegrationFilesIdempotently(t *testing.T) {
 	})
 }
 
+func TestDoctorFlagsOutdatedManagedBlocksAndInitRefreshes(t *testing.T) {
+	root := t.TempDir()
+
+	withWorkingDir(t, root, func() {
+		initCmd := newInitCmdForTest()
+		mustSetFlag(t, initCmd, "llm", "codex")
+		if err := RunInit(initCmd, nil); err != nil {
+			t.Fatalf("RunInit failed: %v", err)
+		}
+
+		legacy := "# Team notes\n\n" + llm.ManagedBlockStart + "\n# Old skelly guidance\n" + llm.ManagedBlockEnd + "\n\nkeep me\n"
+		mustWriteFile(t, filepath.Join(root, "AGENTS.md"), legacy)
+
+		doctorCmd := newDoctorCmdForTest()
+		mustSetFlag(t, doctorCmd, "json", "true")
+		var summary DoctorSummary
+		stdout := captureStdout(t, func() {
+			// No context has been generated, so doctor reports and fails.
+			if err := RunDoctor(doctorCmd, nil); err == nil || !strings.Contains(err.Error(), "no generated context") {
+				t.Fatalf("expected RunDoctor to fail without generated context, got %v", err)
+			}
+		})
+		if err := json.Unmarshal([]byte(stdout), &summary); err != nil {
+			t.Fatalf("failed to decode doctor output: %v\noutput=%s", err, stdout)
+		}
+		statuses := make(map[string]string)
+		for _, block := range summary.ManagedBlocks {
+			statuses[block.File] = block.Status
+		}
+		if statuses["AGENTS.md"] != llm.ManagedBlockOutdated || statuses["CONTEXT.md"] != llm.ManagedBlockCurrent {
+			t.Fatalf("expected outdated AGENTS.md and current CONTEXT.md, got %#v", summary.ManagedBlocks)
+		}
+		if !strings.Contains(strings.Join(summary.Suggestions, "\n"), "run skelly init --refresh") {
+			t.Fatalf("expected refresh suggestion, got %#v", summary.Suggestions)
+		}
+
+		refreshCmd := newInitCmdForTest()
+		mustSetFlag(t, refreshCmd, "refresh", "true")
+		if err := RunInit(refreshCmd, nil); err != nil {
+			t.Fatalf("RunInit --refresh failed: %v", err)
+		}
+		agents := mustReadFile(t, filepath.Join(root, "AGENTS.md"))
+		if strings.Contains(agents, "Old skelly guidance") || !strings.Contains(agents, "keep me") || !strings.HasPrefix(agents, "# Team notes") {
+			t.Fatalf("expected only the managed block to be refreshed, got:\n%s", agents)
+		}
+		if status := llm.CheckManagedBlock(filepath.Join(root, "AGENTS.md"), "AGENTS.md", llm.BuildRootAdapterBlock("Codex")); status.Status != llm.ManagedBlockCurrent {
+			t.Fatalf("expected AGENTS.md block to be current after refresh, got %#v", status)
+		}
+	})
+}
+
 func TestDoctorReportsHealthyAndStaleStates(t *testing.T) {
 	root := t.TempDir()
 	mustWriteFile(t, filepath.Join(root, "demo.go"), `package demo
@@ -179,8 +251,8 @@ func B() {}
 
 		var stale DoctorSummary
 		stdout = captureStdout(t, func() {
-			if err := RunDoctor(doctorCmd, nil); err != nil {
-				t.Fatalf("RunDoctor after edits failed: %v", err)
+			if err := RunDoctor(doctorCmd, nil); err == nil || !strings.Contains(err.Error(), "context is stale: changed=1") {
+				t.Fatalf("expected RunDoctor to fail on stale context, got %v", err)
 			}
 		})
 		if err := json.Unmarshal([]byte(stdout), &stale); err != nil {
@@ -198,6 +270,180 @@ func B() {}
 	})
 }
 
+func TestDoctorFailsOnExpiredOrModifiedContextAndStrictSetupGaps(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "demo.go"), `package demo
+
+func A() {}
+`)
+
+	withWorkingDir(t, root, func() {
+		if err := RunGenerate(newGenerateCmdForTest(), []string{"."}); err != nil {
+			t.Fatalf("RunGenerate failed: %v", err)
+		}
+		doctor := func(flags map[string]string) (DoctorSummary, error) {
+			t.Helper()
+			cmd := newDoctorCmdForTest()
+			cmd.Flags().Bool("strict", false, "")
+			cmd.Flags().Duration("max-age", 0, "")
+			mustSetFlag(t, cmd, "json", "true")
+			for name, value := range flags {
+				mustSetFlag(t, cmd, name, value)
+			}
+			var runErr error
+			out := captureStdout(t, func() { runErr = RunDoctor(cmd, nil) })
+			var summary DoctorSummary
+			if err := json.Unmarshal([]byte(out), &summary); err != nil {
+				t.Fatalf("failed to decode doctor output: %v\n%s", err, out)
+			}
+			return summary, runErr
+		}
+
+		// Missing LLM integrations are setup gaps: reported, but fatal only with --strict.
+		summary, err := doctor(nil)
+		if err != nil || summary.Healthy || summary.GeneratedAt == nil {
+			t.Fatalf("expected an unhealthy but passing doctor run, got err=%v summary=%+v", err, summary)
+		}
+		if _, err := doctor(map[string]string{"strict": "true"}); err == nil || !strings.Contains(err.Error(), "setup incomplete") {
+			t.Fatalf("expected --strict to fail on setup gaps, got %v", err)
+		}
+
+		if _, err := doctor(map[string]string{"max-age": "1h"}); err != nil {
+			t.Fatalf("expected fresh context to pass --max-age, got %v", err)
+		}
+		time.Sleep(20 * time.Millisecond)
+		if summary, err := doctor(map[string]string{"max-age": "10ms"}); err == nil || !summary.Expired || !strings.Contains(err.Error(), "more than --max-age 10ms") {
+			t.Fatalf("expected --max-age to fail on old context, got err=%v expired=%t", err, summary.Expired)
+		}
+
+		contextDir := filepath.Join(root, output.ContextDir)
+		mustWriteFile(t, filepath.Join(contextDir, output.IndexFile), "tampered\n")
+		if err := os.Remove(filepath.Join(contextDir, output.GraphFile)); err != nil {
+			t.Fatalf("failed to remove graph file: %v", err)
+		}
+		summary, err = doctor(nil)
+		if err == nil || !strings.Contains(err.Error(), "artifacts differ from state: modified=1 missing=1") {
+			t.Fatalf("expected modified artifacts to fail doctor, got %v", err)
+		}
+		if !reflect.DeepEqual(summary.ModifiedArtifacts, []string{output.IndexFile}) || !reflect.DeepEqual(summary.MissingArtifacts, []string{output.GraphFile}) {
+			t.Fatalf("unexpected artifact report: modified=%v missing=%v", summary.ModifiedArtifacts, summary.MissingArtifacts)
+		}
+	})
+}
+
+func TestCIChecksCommittedContextWithoutWriting(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "demo.go"), `package demo
+
+func A() {}
+`)
+
+	withWorkingDir(t, root, func() {
+		generateCmd := newGenerateCmdForTest()
+		mustSetFlag(t, generateCmd, "format", "jsonl")
+		if err := RunGenerate(generateCmd, []string{"."}); err != nil {
+			t.Fatalf("RunGenerate failed: %v", err)
+		}
+		out := captureStdout(t, func() {
+			if err := RunCI(newCICmdForTest(), nil); err != nil {
+				t.Fatalf("expected committed context to pass ci, got %v", err)
+			}
+		})
+		if !strings.Contains(out, "ci: ok") {
+			t.Fatalf("expected ci ok, got:\n%s", out)
+		}
+
+		contextDir := filepath.Join(root, output.ContextDir)
+		before := mustReadFile(t, filepath.Join(contextDir, output.SymbolsFile))
+		mustWriteFile(t, filepath.Join(root, "demo.go"), `package demo
+
+func A() { B() }
+
+func B() {}
+`)
+		cmd := newCICmdForTest()
+		mustSetFlag(t, cmd, "json", "true")
+		var runErr error
+		out = captureStdout(t, func() { runErr = RunCI(cmd, nil) })
+		if runErr == nil || !strings.Contains(runErr.Error(), "context is out of date") {
+			t.Fatalf("expected ci to fail on stale context, got %v", runErr)
+		}
+		var summary CISummary
+		if err := json.Unmarshal([]byte(out), &summary); err != nil {
+			t.Fatalf("failed to decode ci output: %v\n%s", err, out)
+		}
+		if summary.OK || summary.Format != "jsonl" || !reflect.DeepEqual(summary.ChangedSources, []string{"demo.go"}) ||
+			!containsString(summary.StaleArtifacts, output.SymbolsFile) || !containsString(summary.StaleArtifacts, output.EdgesFile) {
+			t.Fatalf("unexpected ci summary: %+v", summary)
+		}
+		if after := mustReadFile(t, filepath.Join(contextDir, output.SymbolsFile)); after != before {
+			t.Fatalf("expected ci to leave the committed context untouched")
+		}
+	})
+}
+
+func TestGenerateAllRootsWritesCrossRootGraph(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, ".skelly", "config.yaml"), "roots: [services/*, libs/shared]\nformat: jsonl\n")
+	mustWriteFile(t, filepath.Join(root, "services", "api", ".skelly", "config.yaml"), "format: text\n")
+	mustWriteFile(t, filepath.Join(root, "services", "api", "handler.go"), `package api
+
+import "example.com/libs/shared"
+
+func Handle() { shared.Validate() }
+`)
+	mustWriteFile(t, filepath.Join(root, "services", "billing", "charge.go"), `package billing
+
+func Charge() {}
+`)
+	mustWriteFile(t, filepath.Join(root, "libs", "shared", "validate.go"), `package shared
+
+func Validate() {}
+`)
+
+	withWorkingDir(t, root, func() {
+		cmd := newGenerateCmdForTest()
+		mustSetFlag(t, cmd, "all-roots", "true")
+		captureStdout(t, func() {
+			if err := RunGenerate(cmd, []string{"."}); err != nil {
+				t.Fatalf("RunGenerate --all-roots failed: %v", err)
+			}
+		})
+	})
+
+	for rel, file := range map[string]string{
+		"services/api":     output.IndexFile,
+		"services/billing": output.SymbolsFile,
+		"libs/shared":      output.SymbolsFile,
+	} {
+		if _, err := os.Stat(filepath.Join(root, filepath.FromSlash(rel), output.ContextDir, file)); err != nil {
+			t.Fatalf("expected %s in the context of %s: %v", file, rel, err)
+		}
+	}
+	data, err := os.ReadFile(filepath.Join(root, output.ContextDir, RootsGraphFile))
+	if err != nil {
+		t.Fatalf("expected cross-root graph: %v", err)
+	}
+	var rootsGraph RootsGraph
+	if err := json.Unmarshal(data, &rootsGraph); err != nil {
+		t.Fatalf("failed to decode %s: %v", RootsGraphFile, err)
+	}
+	if len(rootsGraph.Roots) != 3 || rootsGraph.Roots[1].Path != "services/api" || rootsGraph.Roots[1].Format != "text" {
+		t.Fatalf("unexpected roots: %+v", rootsGraph.Roots)
+	}
+	if len(rootsGraph.Calls) != 1 || rootsGraph.Calls[0].FromRoot != "services/api" || rootsGraph.Calls[0].ToRoot != "libs/shared" ||
+		!strings.HasPrefix(rootsGraph.Calls[0].From, "handler.go|func|Handle|") || !strings.HasPrefix(rootsGraph.Calls[0].To, "validate.go|func|Validate|") {
+		t.Fatalf("expected one cross-root call, got %+v", rootsGraph.Calls)
+	}
+	if !reflect.DeepEqual(rootsGraph.Links, []CrossRootCount{{From: "services/api", To: "libs/shared", Calls: 1, Imports: 1}}) {
+		t.Fatalf("unexpected cross-root links: %+v", rootsGraph.Links)
+	}
+
+	if _, err := ExpandRoots(root, []string{"apps/*"}); err == nil || !strings.Contains(err.Error(), "matches no directory") {
+		t.Fatalf("expected an unmatched root pattern to fail, got %v", err)
+	}
+}
+
 func TestGenerateWritesNavigationIndex(t *testing.T) {
 	root := t.TempDir()
 	mustWriteFile(t, filepath.Join(root, "demo.go"), `package demo
@@ -234,6 +480,211 @@ func B() {}
 	})
 }
 
+func TestGenerateCompressStoresGzipAndZstdArtifacts(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "demo.go"), `package demo
+
+func A() { B() }
+func B() {}
+`)
+
+	withWorkingDir(t, root, func() {
+		contextDir := filepath.Join(root, output.ContextDir)
+		generateCmd := newGenerateCmdForTest()
+		mustSetFlag(t, generateCmd, "format", "jsonl")
+		mustSetFlag(t, generateCmd, "compress", "gzip")
+		if err := RunGenerate(generateCmd, []string{"."}); err != nil {
+			t.Fatalf("RunGenerate failed: %v", err)
+		}
+		for _, file := range []string{output.SymbolsFile, output.EdgesFile, nav.NavigationIndexFile, search.IndexFile} {
+			assertExists(t, filepath.Join(contextDir, file+".gz"))
+			assertNotExists(t, filepath.Join(contextDir, file))
+		}
+		if format := llm.DetectContextFormat(contextDir); format != "jsonl" {
+			t.Fatalf("expected compressed context to be detected as jsonl, got %q", format)
+		}
+		st, err := state.Load(contextDir)
+		if err != nil {
+			t.Fatalf("failed to load state: %v", err)
+		}
+		if _, ok := st.OutputHashes[output.SymbolsFile]; !ok {
+			t.Fatalf("expected output hashes under uncompressed names, got %#v", st.OutputHashes)
+		}
+		if modified, missing := verifyOutputHashes(contextDir, st); len(modified) > 0 || len(missing) > 0 {
+			t.Fatalf("expected compressed artifacts to match their hashes, modified=%v missing=%v", modified, missing)
+		}
+		lookup, err := nav.LoadLookup(root)
+		if err != nil {
+			t.Fatalf("failed to load compressed navigation index: %v", err)
+		}
+		if len(lookup.ByID) < 2 {
+			t.Fatalf("expected compressed navigation index to include at least 2 nodes, got %d", len(lookup.ByID))
+		}
+		if _, err := search.Load(root); err != nil {
+			t.Fatalf("failed to load compressed search index: %v", err)
+		}
+
+		// Without --compress, update keeps the artifacts compressed.
+		mustWriteFile(t, filepath.Join(root, "demo.go"), `package demo
+
+func A() { B() }
+func B() { C() }
+func C() {}
+`)
+		updateCmd := newUpdateCmdForTest()
+		mustSetFlag(t, updateCmd, "format", "jsonl")
+		if err := RunUpdate(updateCmd, nil); err != nil {
+			t.Fatalf("RunUpdate failed: %v", err)
+		}
+		assertExists(t, filepath.Join(contextDir, output.SymbolsFile+".gz"))
+		assertNotExists(t, filepath.Join(contextDir, output.SymbolsFile))
+
+		generateCmd = newGenerateCmdForTest()
+		mustSetFlag(t, generateCmd, "format", "jsonl")
+		mustSetFlag(t, generateCmd, "compress", "none")
+		if err := RunGenerate(generateCmd, []string{"."}); err != nil {
+			t.Fatalf("RunGenerate failed: %v", err)
+		}
+		for _, file := range []string{output.SymbolsFile, output.EdgesFile, nav.NavigationIndexFile, search.IndexFile} {
+			assertExists(t, filepath.Join(contextDir, file))
+			assertNotExists(t, filepath.Join(contextDir, file+".gz"))
+		}
+
+		mustSetFlag(t, generateCmd, "compress", "zstd")
+		if err := RunGenerate(generateCmd, []string{"."}); err != nil {
+			t.Fatalf("RunGenerate failed: %v", err)
+		}
+		for _, file := range []string{output.SymbolsFile, output.EdgesFile, nav.NavigationIndexFile, search.IndexFile} {
+			assertExists(t, filepath.Join(contextDir, file+".zst"))
+			assertNotExists(t, filepath.Join(contextDir, file))
+			assertNotExists(t, filepath.Join(contextDir, file+".gz"))
+		}
+		st, err = state.Load(contextDir)
+		if err != nil {
+			t.Fatalf("failed to load state: %v", err)
+		}
+		if modified, missing := verifyOutputHashes(contextDir, st); len(modified) > 0 || len(missing) > 0 {
+			t.Fatalf("expected zstd artifacts to match their hashes, modified=%v missing=%v", modified, missing)
+		}
+		if lookup, err = nav.LoadLookup(root); err != nil || len(lookup.ByID) < 2 {
+			t.Fatalf("failed to load zstd navigation index: %v", err)
+		}
+		if _, err := search.Load(root); err != nil {
+			t.Fatalf("failed to load zstd search index: %v", err)
+		}
+
+		// Without --compress, update keeps zstd and leaves unchanged artifacts alone.
+		symbolsPath := filepath.Join(contextDir, output.SymbolsFile+".zst")
+		before, err := os.Stat(symbolsPath)
+		if err != nil {
+			t.Fatalf("failed to stat %s: %v", symbolsPath, err)
+		}
+		mustWriteFile(t, filepath.Join(root, "extra.go"), "package demo\n")
+		updateCmd = newUpdateCmdForTest()
+		mustSetFlag(t, updateCmd, "format", "jsonl")
+		if err := RunUpdate(updateCmd, nil); err != nil {
+			t.Fatalf("RunUpdate failed: %v", err)
+		}
+		after, err := os.Stat(symbolsPath)
+		if err != nil {
+			t.Fatalf("expected update to keep %s: %v", symbolsPath, err)
+		}
+		if !after.ModTime().Equal(before.ModTime()) {
+			t.Fatalf("expected unchanged zstd symbols not to be rewritten")
+		}
+
+		mustSetFlag(t, generateCmd, "compress", "brotli")
+		if err := RunGenerate(generateCmd, []string{"."}); err == nil || !strings.Contains(err.Error(), "brotli") {
+			t.Fatalf("expected brotli to be rejected, got %v", err)
+		}
+	})
+}
+
+func TestGenerateShardsLargeNavigationIndex(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "api", "handler.go"), `package api
+
+import "example.com/demo/store"
+
+func Handle() { store.Save() }
+`)
+	mustWriteFile(t, filepath.Join(root, "store", "store.go"), `package store
+
+func Save() { flush() }
+func flush() {}
+`)
+	previous := nav.ShardMinNodes
+	nav.ShardMinNodes = 1
+	t.Cleanup(func() { nav.ShardMinNodes = previous })
+
+	withWorkingDir(t, root, func() {
+		contextDir := filepath.Join(root, output.ContextDir)
+		if err := RunGenerate(newGenerateCmdForTest(), []string{"."}); err != nil {
+			t.Fatalf("RunGenerate failed: %v", err)
+		}
+		data, err := os.ReadFile(filepath.Join(contextDir, nav.NavigationIndexFile))
+		if err != nil {
+			t.Fatalf("failed to read navigation index: %v", err)
+		}
+		var manifest nav.Index
+		if err := json.Unmarshal(data, &manifest); err != nil {
+			t.Fatalf("failed to decode navigation index: %v", err)
+		}
+		if manifest.Shards == nil || manifest.Shards.Count != 3 || len(manifest.Nodes) != 0 {
+			t.Fatalf("expected a shard manifest for 3 nodes without inline nodes, got %s", data)
+		}
+		assertExists(t, filepath.Join(contextDir, nav.NavigationShardDir, "nodes-0000.json"))
+
+		lookup, err := nav.LoadLookup(root)
+		if err != nil {
+			t.Fatalf("LoadLookup failed: %v", err)
+		}
+		if len(lookup.ByID) != 0 {
+			t.Fatalf("expected no shard to load before a lookup, got %d nodes", len(lookup.ByID))
+		}
+		save, err := nav.ResolveSingleSymbol(lookup, "Save")
+		if err != nil {
+			t.Fatalf("failed to resolve Save in sharded index: %v", err)
+		}
+		if len(save.InEdges) != 1 || lookup.Node(save.InEdges[0]) == nil || lookup.Node(save.InEdges[0]).Name != "Handle" {
+			t.Fatalf("expected Handle to call Save in the sharded index, got %#v", save.InEdges)
+		}
+
+		callersCmd := newCallersCmdForTest()
+		mustSetFlag(t, callersCmd, "json", "true")
+		stdout := captureStdout(t, func() {
+			if err := nav.RunCallers(callersCmd, []string{"Save"}); err != nil {
+				t.Fatalf("RunCallers failed: %v", err)
+			}
+		})
+		if !strings.Contains(stdout, "Handle") {
+			t.Fatalf("expected callers of Save to include Handle, got %s", stdout)
+		}
+
+		st, err := state.Load(contextDir)
+		if err != nil {
+			t.Fatalf("failed to load state: %v", err)
+		}
+		if _, ok := st.OutputHashes[filepath.Join(nav.NavigationShardDir, "nodes-0000.json")]; !ok {
+			t.Fatalf("expected shard output hashes, got %#v", st.OutputHashes)
+		}
+
+		// Below the threshold the index is written whole again.
+		nav.ShardMinNodes = previous
+		if err := RunGenerate(newGenerateCmdForTest(), []string{"."}); err != nil {
+			t.Fatalf("RunGenerate failed: %v", err)
+		}
+		assertNotExists(t, filepath.Join(contextDir, nav.NavigationShardDir))
+		lookup, err = nav.LoadLookup(root)
+		if err != nil {
+			t.Fatalf("LoadLookup failed: %v", err)
+		}
+		if len(lookup.ByID) != 3 {
+			t.Fatalf("expected a monolithic index with 3 nodes, got %d", len(lookup.ByID))
+		}
+	})
+}
+
 func TestNavigationCommandsJSON(t *testing.T) {
 	root := t.TempDir()
 	mustWriteFile(t, filepath.Join(root, "demo.go"), `package demo
@@ -386,13 +837,21 @@ func BuildGraph() {}
 	})
 }
 
-func TestNavigationCommandsJSONWithLSPMetadata(t *testing.T) {
+func TestSymbolAndCallersAcceptQualifiedNames(t *testing.T) {
 	root := t.TempDir()
-	mustWriteFile(t, filepath.Join(root, "demo.go"), `package demo
+	mustWriteFile(t, filepath.Join(root, "models.py"), `class User:
+    def save(self):
+        self.validate()
 
-func Start() { Mid() }
-func Mid() { End() }
-func End() {}
+    def validate(self):
+        pass
+
+class Order:
+    def save(self):
+        self.validate()
+
+    def validate(self):
+        pass
 `)
 
 	withWorkingDir(t, root, func() {
@@ -403,38 +862,59 @@ func End() {}
 			t.Fatalf("RunGenerate failed: %v", err)
 		}
 
+		symbolCmd := newSymbolCmdForTest()
+		mustSetFlag(t, symbolCmd, "json", "true")
+		var symbolPayload struct {
+			Matches []nav.SymbolRecord `json:"matches"`
+		}
+		stdout := captureStdout(t, func() {
+			if err := nav.RunSymbol(symbolCmd, []string{"User.validate"}); err != nil {
+				t.Fatalf("RunSymbol failed: %v", err)
+			}
+		})
+		if err := json.Unmarshal([]byte(stdout), &symbolPayload); err != nil {
+			t.Fatalf("failed to decode symbol output: %v\noutput=%s", err, stdout)
+		}
+		if len(symbolPayload.Matches) != 1 || symbolPayload.Matches[0].Container != "User" {
+			t.Fatalf("expected User.validate to match one symbol, got %#v", symbolPayload.Matches)
+		}
+
 		callersCmd := newCallersCmdForTest()
 		mustSetFlag(t, callersCmd, "json", "true")
-		mustSetFlag(t, callersCmd, "lsp", "true")
-
-		var payload struct {
+		var callersPayload struct {
 			Callers []nav.EdgeRecord `json:"callers"`
-			LSP     *nav.LSPStatus   `json:"lsp"`
 		}
-		stdout := captureStdout(t, func() {
-			if err := nav.RunCallers(callersCmd, []string{"Mid"}); err != nil {
+		stdout = captureStdout(t, func() {
+			if err := nav.RunCallers(callersCmd, []string{"Order.validate"}); err != nil {
 				t.Fatalf("RunCallers failed: %v", err)
 			}
 		})
-		if err := json.Unmarshal([]byte(stdout), &payload); err != nil {
-			t.Fatalf("failed to decode callers output with lsp metadata: %v\noutput=%s", err, stdout)
-		}
-		if payload.LSP == nil {
-			t.Fatalf("expected lsp metadata in payload, got %#v", payload)
+		if err := json.Unmarshal([]byte(stdout), &callersPayload); err != nil {
+			t.Fatalf("failed to decode callers output: %v\noutput=%s", err, stdout)
 		}
-		if len(payload.Callers) == 0 || payload.Callers[0].Source != "parser" {
-			t.Fatalf("expected callers source=parser when --lsp enabled, got %#v", payload.Callers)
+		if len(callersPayload.Callers) != 1 || callersPayload.Callers[0].Symbol.Container != "Order" {
+			t.Fatalf("expected only Order.save to call Order.validate, got %#v", callersPayload.Callers)
 		}
 	})
 }
 
-func TestDefinitionAndReferencesCommandsJSON(t *testing.T) {
+func TestImplementationsListsGoInterfaceImplementers(t *testing.T) {
 	root := t.TempDir()
-	mustWriteFile(t, filepath.Join(root, "demo.go"), `package demo
+	mustWriteFile(t, filepath.Join(root, "store.go"), `package store
 
-func Start() { Mid() }
-func Mid() { End() }
-func End() {}
+type Store interface {
+	Get(key string) ([]byte, error)
+	Put(key string, value []byte) error
+}
+
+type Memory struct{}
+
+func (m *Memory) Get(key string) ([]byte, error) { return nil, nil }
+func (m *Memory) Put(key string, value []byte) error { return nil }
+
+type ReadOnly struct{}
+
+func (r ReadOnly) Get(key string) ([]byte, error) { return nil, nil }
 `)
 
 	withWorkingDir(t, root, func() {
@@ -445,420 +925,5329 @@ func End() {}
 			t.Fatalf("RunGenerate failed: %v", err)
 		}
 
-		definitionCmd := newDefinitionCmdForTest()
-		mustSetFlag(t, definitionCmd, "json", "true")
-
-		var definitionPayload struct {
-			Definition nav.SymbolRecord `json:"definition"`
+		cmd := newImplementationsCmdForTest()
+		mustSetFlag(t, cmd, "json", "true")
+		var payload struct {
+			Implementations []nav.SymbolRecord `json:"implementations"`
 		}
 		stdout := captureStdout(t, func() {
-			if err := nav.RunDefinition(definitionCmd, []string{"demo.go:4"}); err != nil {
-				t.Fatalf("RunDefinition failed: %v", err)
+			if err := nav.RunImplementations(cmd, []string{"Store"}); err != nil {
+				t.Fatalf("RunImplementations failed: %v", err)
 			}
 		})
-		if err := json.Unmarshal([]byte(stdout), &definitionPayload); err != nil {
-			t.Fatalf("failed to decode definition output: %v\noutput=%s", err, stdout)
+		if err := json.Unmarshal([]byte(stdout), &payload); err != nil {
+			t.Fatalf("failed to decode implementations output: %v\noutput=%s", err, stdout)
 		}
-		if definitionPayload.Definition.Name != "Mid" {
-			t.Fatalf("expected Mid definition from line lookup, got %#v", definitionPayload.Definition)
+		if len(payload.Implementations) != 1 || payload.Implementations[0].Name != "Memory" {
+			t.Fatalf("expected only Memory to implement Store, got %#v", payload.Implementations)
 		}
 
-		referencesCmd := newReferencesCmdForTest()
-		mustSetFlag(t, referencesCmd, "json", "true")
-
-		var referencesPayload struct {
-			References []nav.EdgeRecord `json:"references"`
-		}
 		stdout = captureStdout(t, func() {
-			if err := nav.RunReferences(referencesCmd, []string{"Mid"}); err != nil {
-				t.Fatalf("RunReferences failed: %v", err)
+			if err := nav.RunImplementations(newImplementationsCmdForTest(), []string{"Memory"}); err != nil {
+				t.Fatalf("RunImplementations failed: %v", err)
 			}
 		})
-		if err := json.Unmarshal([]byte(stdout), &referencesPayload); err != nil {
-			t.Fatalf("failed to decode references output: %v\noutput=%s", err, stdout)
-		}
-		if len(referencesPayload.References) != 1 || referencesPayload.References[0].Symbol.Name != "Start" {
-			t.Fatalf("expected Start to reference Mid, got %#v", referencesPayload.References)
+		if !strings.Contains(stdout, "interfaces implemented by") || !strings.Contains(stdout, "|interface|Store") {
+			t.Fatalf("expected Memory to list Store, got:\n%s", stdout)
 		}
 	})
 }
 
-func TestGenerateParsesSupportedLanguageFixtures(t *testing.T) {
+func TestTraceDirectionFollowsCallersAndCallees(t *testing.T) {
 	root := t.TempDir()
-	mustWriteFile(t, filepath.Join(root, "go", "main.go"), `package demo
-func A() {}
-`)
-	mustWriteFile(t, filepath.Join(root, "python", "main.py"), `def run():
-    return helper()
+	mustWriteFile(t, filepath.Join(root, "a.go"), `package demo
 
-def helper():
-    return 1
-`)
-	mustWriteFile(t, filepath.Join(root, "ruby", "main.rb"), `def run
-  helper
-end
+func Handler() { Service() }
 
-def helper
-  1
-end
-`)
-	mustWriteFile(t, filepath.Join(root, "typescript", "main.ts"), `export function run() { return helper(); }
-function helper() { return 1; }
-`)
-	mustWriteFile(t, filepath.Join(root, "javascript", "main.js"), `export function run() { return helper(); }
-function helper() { return 1; }
+func Job() { Service() }
+
+func Service() { Store() }
+
+func Store() { Write() }
+
+func Write() {}
 `)
 
 	withWorkingDir(t, root, func() {
-		if err := RunInit(&cobra.Command{}, nil); err != nil {
-			t.Fatalf("RunInit failed: %v", err)
-		}
-
 		if err := RunGenerate(newGenerateCmdForTest(), []string{"."}); err != nil {
 			t.Fatalf("RunGenerate failed: %v", err)
 		}
 
-		indexData, err := os.ReadFile(filepath.Join(root, output.ContextDir, "index.txt"))
-		if err != nil {
-			t.Fatalf("failed to read index output: %v", err)
+		runTrace := func(direction, depth string) []nav.TraceHop {
+			cmd := newTraceCmdForTest()
+			mustSetFlag(t, cmd, "json", "true")
+			mustSetFlag(t, cmd, "direction", direction)
+			mustSetFlag(t, cmd, "depth", depth)
+			var payload struct {
+				Direction string         `json:"direction"`
+				Hops      []nav.TraceHop `json:"hops"`
+			}
+			stdout := captureStdout(t, func() {
+				if err := nav.RunTrace(cmd, []string{"Store"}); err != nil {
+					t.Fatalf("RunTrace --direction %s failed: %v", direction, err)
+				}
+			})
+			if err := json.Unmarshal([]byte(stdout), &payload); err != nil {
+				t.Fatalf("failed to decode trace output: %v\noutput=%s", err, stdout)
+			}
+			if payload.Direction != direction {
+				t.Fatalf("expected direction %q, got %q", direction, payload.Direction)
+			}
+			return payload.Hops
 		}
-		indexText := string(indexData)
-		for _, expected := range []string{
-			"go/main.go",
-			"python/main.py",
-			"ruby/main.rb",
-			"typescript/main.ts",
-			"javascript/main.js",
-		} {
-			if !strings.Contains(indexText, expected) {
-				t.Fatalf("expected index to contain %s", expected)
+		describe := func(hops []nav.TraceHop) []string {
+			out := make([]string, 0, len(hops))
+			for _, hop := range hops {
+				out = append(out, fmt.Sprintf("%d %s %s->%s", hop.Depth, hop.Direction, hop.From.Name, hop.To.Name))
 			}
+			return out
+		}
+
+		if got, want := describe(runTrace("in", "2")), []string{
+			"1 in Service->Store",
+			"2 in Handler->Service",
+			"2 in Job->Service",
+		}; strings.Join(got, "|") != strings.Join(want, "|") {
+			t.Fatalf("unexpected inbound trace: %#v", got)
+		}
+		if got := describe(runTrace("in", "1")); len(got) != 1 {
+			t.Fatalf("expected depth to bound the inbound trace, got %#v", got)
+		}
+		if got, want := describe(runTrace("both", "1")), []string{
+			"1 out Store->Write",
+			"1 in Service->Store",
+		}; strings.Join(got, "|") != strings.Join(want, "|") {
+			t.Fatalf("unexpected two-way trace: %#v", got)
+		}
+
+		cmd := newTraceCmdForTest()
+		mustSetFlag(t, cmd, "direction", "sideways")
+		if err := nav.RunTrace(cmd, []string{"Store"}); err == nil || !strings.Contains(err.Error(), "--direction") {
+			t.Fatalf("expected invalid direction error, got %v", err)
 		}
 	})
 }
 
-func TestGenerateJSONLDeterministic(t *testing.T) {
+func TestPathAllEnumeratesDistinctPathsShortestFirst(t *testing.T) {
 	root := t.TempDir()
-	mustWriteFile(t, filepath.Join(root, "demo.go"), `package demo
+	mustWriteFile(t, filepath.Join(root, "a.go"), `package demo
 
-func A() {
-	B()
+func Start() {
+	Save()
+	Validate()
+	Audit()
 }
 
-func B() {}
+func Validate() { Save() }
+
+func Audit() { Validate() }
+
+func Save() {}
 `)
 
 	withWorkingDir(t, root, func() {
-		if err := RunInit(&cobra.Command{}, nil); err != nil {
-			t.Fatalf("RunInit failed: %v", err)
-		}
-
-		genCmd := newGenerateCmdForTest()
-		mustSetFlag(t, genCmd, "format", "jsonl")
-		if err := RunGenerate(genCmd, []string{"."}); err != nil {
+		if err := RunGenerate(newGenerateCmdForTest(), []string{"."}); err != nil {
 			t.Fatalf("RunGenerate failed: %v", err)
 		}
 
-		symbolsPath := filepath.Join(root, output.ContextDir, "symbols.jsonl")
-		edgesPath := filepath.Join(root, output.ContextDir, "edges.jsonl")
-		manifestPath := filepath.Join(root, output.ContextDir, "manifest.json")
-
-		assertExists(t, symbolsPath)
-		assertExists(t, edgesPath)
-		assertExists(t, manifestPath)
-		assertNotExists(t, filepath.Join(root, output.ContextDir, "index.txt"))
-		assertNotExists(t, filepath.Join(root, output.ContextDir, "graph.txt"))
+		runPaths := func(maxDepth, limit string) []nav.PathResult {
+			cmd := newPathCmdForTest()
+			mustSetFlag(t, cmd, "all", "true")
+			mustSetFlag(t, cmd, "json", "true")
+			mustSetFlag(t, cmd, "max-depth", maxDepth)
+			mustSetFlag(t, cmd, "limit", limit)
+			var payload struct {
+				Count int              `json:"count"`
+				Paths []nav.PathResult `json:"paths"`
+			}
+			stdout := captureStdout(t, func() {
+				if err := nav.RunPath(cmd, []string{"Start", "Save"}); err != nil {
+					t.Fatalf("RunPath --all failed: %v", err)
+				}
+			})
+			if err := json.Unmarshal([]byte(stdout), &payload); err != nil {
+				t.Fatalf("failed to decode path output: %v\noutput=%s", err, stdout)
+			}
+			if payload.Count != len(payload.Paths) {
+				t.Fatalf("count %d does not match %d paths", payload.Count, len(payload.Paths))
+			}
+			return payload.Paths
+		}
+		describe := func(paths []nav.PathResult) []string {
+			out := make([]string, 0, len(paths))
+			for _, result := range paths {
+				names := make([]string, 0, len(result.Path))
+				for _, symbol := range result.Path {
+					names = append(names, symbol.Name)
+				}
+				if len(result.Edges) != result.Length || result.Edges[0].Confidence == "" {
+					t.Fatalf("expected one edge with confidence per hop, got %#v", result.Edges)
+				}
+				out = append(out, strings.Join(names, ">"))
+			}
+			return out
+		}
 
-		firstSymbols, err := os.ReadFile(symbolsPath)
-		if err != nil {
-			t.Fatalf("failed to read symbols jsonl: %v", err)
+		want := "Start>Save|Start>Validate>Save|Start>Audit>Validate>Save"
+		if got := strings.Join(describe(runPaths("6", "0")), "|"); got != want {
+			t.Fatalf("unexpected paths: %s", got)
 		}
-		firstEdges, err := os.ReadFile(edgesPath)
-		if err != nil {
-			t.Fatalf("failed to read edges jsonl: %v", err)
+		if got := strings.Join(describe(runPaths("2", "0")), "|"); got != "Start>Save|Start>Validate>Save" {
+			t.Fatalf("expected --max-depth to bound path length, got %s", got)
 		}
-		firstManifest, err := os.ReadFile(manifestPath)
-		if err != nil {
-			t.Fatalf("failed to read manifest: %v", err)
+		if got := describe(runPaths("6", "1")); len(got) != 1 || got[0] != "Start>Save" {
+			t.Fatalf("expected --limit to keep the shortest path, got %#v", got)
 		}
+	})
+}
 
-		assertSortedSymbolJSONL(t, firstSymbols)
+func TestTraceAndPathLanguageFilterReportsBoundaryCuts(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "a.ts"), `export function Start() { bridge(); }
+`)
+	mustWriteFile(t, filepath.Join(root, "b.js"), `function bridge() { finish(); }
 
-		if err := RunGenerate(genCmd, []string{"."}); err != nil {
-			t.Fatalf("second RunGenerate failed: %v", err)
+function finish() {}
+`)
+
+	withWorkingDir(t, root, func() {
+		if err := RunInit(newInitCmdForTest(), nil); err != nil {
+			t.Fatalf("RunInit failed: %v", err)
+		}
+		if err := RunGenerate(newGenerateCmdForTest(), []string{"."}); err != nil {
+			t.Fatalf("RunGenerate failed: %v", err)
 		}
 
-		secondSymbols, err := os.ReadFile(symbolsPath)
-		if err != nil {
-			t.Fatalf("failed to read symbols jsonl on second run: %v", err)
+		traceCmd := newTraceCmdForTest()
+		mustSetFlag(t, traceCmd, "json", "true")
+		mustSetFlag(t, traceCmd, "depth", "3")
+		mustSetFlag(t, traceCmd, "lang", "ts")
+		var tracePayload struct {
+			Languages []string          `json:"languages"`
+			Hops      []nav.TraceHop    `json:"hops"`
+			CutEdges  []nav.BoundaryCut `json:"cut_edges"`
 		}
-		secondEdges, err := os.ReadFile(edgesPath)
-		if err != nil {
-			t.Fatalf("failed to read edges jsonl on second run: %v", err)
+		stdout := captureStdout(t, func() {
+			if err := nav.RunTrace(traceCmd, []string{"Start"}); err != nil {
+				t.Fatalf("RunTrace failed: %v", err)
+			}
+		})
+		if err := json.Unmarshal([]byte(stdout), &tracePayload); err != nil {
+			t.Fatalf("failed to decode trace output: %v\noutput=%s", err, stdout)
 		}
-		secondManifest, err := os.ReadFile(manifestPath)
-		if err != nil {
-			t.Fatalf("failed to read manifest on second run: %v", err)
+		if len(tracePayload.Languages) != 1 || tracePayload.Languages[0] != "typescript" {
+			t.Fatalf("expected typescript language filter, got %#v", tracePayload.Languages)
 		}
-
-		if !bytes.Equal(firstSymbols, secondSymbols) {
-			t.Fatalf("expected deterministic symbols jsonl output")
+		if len(tracePayload.Hops) != 0 {
+			t.Fatalf("expected javascript hops to be filtered, got %#v", tracePayload.Hops)
 		}
-		if !bytes.Equal(firstEdges, secondEdges) {
-			t.Fatalf("expected deterministic edges jsonl output")
+		if len(tracePayload.CutEdges) != 1 || tracePayload.CutEdges[0].To.Name != "bridge" || tracePayload.CutEdges[0].To.Language != "javascript" {
+			t.Fatalf("expected one cut edge into javascript bridge, got %#v", tracePayload.CutEdges)
 		}
-		if !bytes.Equal(firstManifest, secondManifest) {
-			t.Fatalf("expected deterministic manifest output")
+
+		pathCmd := newPathCmdForTest()
+		mustSetFlag(t, pathCmd, "lang", "ts")
+		err := nav.RunPath(pathCmd, []string{"Start", "finish"})
+		if err == nil || !strings.Contains(err.Error(), "outside the --lang filter") {
+			t.Fatalf("expected path endpoint outside filter to fail, got %v", err)
+		}
+
+		pathCmd = newPathCmdForTest()
+		mustSetFlag(t, pathCmd, "lang", "ts,js")
+		stdout = captureStdout(t, func() {
+			if err := nav.RunPath(pathCmd, []string{"Start", "finish"}); err != nil {
+				t.Fatalf("RunPath failed: %v", err)
+			}
+		})
+		if !strings.Contains(stdout, "length=2") {
+			t.Fatalf("expected polyglot path with both languages allowed, got:\n%s", stdout)
 		}
 	})
 }
 
-func TestUpdateJSONLTracksArtifactHashesIncrementally(t *testing.T) {
+func TestSearchSignatureMatchesGoShapes(t *testing.T) {
 	root := t.TempDir()
-	mustWriteFile(t, filepath.Join(root, "demo.go"), `package demo
+	mustWriteFile(t, filepath.Join(root, "server.go"), `package demo
 
-func A() {
-	B()
-}
+import "net/http"
 
-func B() {}
+type Server struct{}
+
+func (s *Server) HandleUsers(w http.ResponseWriter, r *http.Request) {}
+func (s *Server) HandleOrders(w http.ResponseWriter, req *http.Request) {}
+func (s *Server) helper(w http.ResponseWriter) {}
+func (s Server) HandleOther(w http.ResponseWriter, r *http.Request) {}
+func LoadConfig(path string) (cfg *Server, err error) { return nil, nil }
+func Count() int { return 0 }
 `)
 
 	withWorkingDir(t, root, func() {
-		if err := RunInit(&cobra.Command{}, nil); err != nil {
-			t.Fatalf("RunInit failed: %v", err)
-		}
-
-		genCmd := newGenerateCmdForTest()
-		mustSetFlag(t, genCmd, "format", "jsonl")
-		if err := RunGenerate(genCmd, []string{"."}); err != nil {
+		if err := RunGenerate(newGenerateCmdForTest(), []string{"."}); err != nil {
 			t.Fatalf("RunGenerate failed: %v", err)
 		}
 
-		contextDir := filepath.Join(root, output.ContextDir)
-		stBefore, err := state.Load(contextDir)
-		if err != nil {
-			t.Fatalf("failed to load state after generate: %v", err)
+		searchCmd := newSearchCmdForTest()
+		mustSetFlag(t, searchCmd, "json", "true")
+		mustSetFlag(t, searchCmd, "signature", "func (*Server) Handle*(http.ResponseWriter, *http.Request)")
+		var payload struct {
+			Total   int                `json:"total"`
+			Matches []nav.SymbolRecord `json:"matches"`
+		}
+		stdout := captureStdout(t, func() {
+			if err := nav.RunSearch(searchCmd, nil); err != nil {
+				t.Fatalf("RunSearch failed: %v", err)
+			}
+		})
+		if err := json.Unmarshal([]byte(stdout), &payload); err != nil {
+			t.Fatalf("failed to decode search output: %v\noutput=%s", err, stdout)
+		}
+		if payload.Total != 2 || payload.Matches[0].Name != "HandleUsers" || payload.Matches[1].Name != "HandleOrders" {
+			t.Fatalf("expected both pointer-receiver HTTP handlers, got %#v", payload.Matches)
 		}
-		hashesBefore := cloneStringMap(stBefore.OutputHashes)
 
-		updateCmd := newUpdateCmdForTest()
-		mustSetFlag(t, updateCmd, "format", "jsonl")
-		if err := RunUpdate(updateCmd, nil); err != nil {
-			t.Fatalf("RunUpdate failed: %v", err)
+		for pattern, want := range map[string][]string{
+			`func (\*Server) Handle*`:  {"HandleUsers", "HandleOrders"},
+			"func (Server) Handle*(*)": {"HandleOther"},
+			"func (*) Handle*(*)":      {"HandleUsers", "HandleOrders", "HandleOther"},
+		} {
+			searchCmd = newSearchCmdForTest()
+			mustSetFlag(t, searchCmd, "json", "true")
+			mustSetFlag(t, searchCmd, "signature", pattern)
+			stdout = captureStdout(t, func() {
+				if err := nav.RunSearch(searchCmd, nil); err != nil {
+					t.Fatalf("RunSearch failed: %v", err)
+				}
+			})
+			if err := json.Unmarshal([]byte(stdout), &payload); err != nil {
+				t.Fatalf("failed to decode search output: %v\noutput=%s", err, stdout)
+			}
+			names := make([]string, 0, len(payload.Matches))
+			for _, match := range payload.Matches {
+				names = append(names, match.Name)
+			}
+			if !slices.Equal(names, want) {
+				t.Fatalf("expected %q to match %v, got %v", pattern, want, names)
+			}
 		}
 
-		stUnchanged, err := state.Load(contextDir)
-		if err != nil {
-			t.Fatalf("failed to load state after unchanged update: %v", err)
+		searchCmd = newSearchCmdForTest()
+		mustSetFlag(t, searchCmd, "json", "true")
+		mustSetFlag(t, searchCmd, "regex", "true")
+		mustSetFlag(t, searchCmd, "signature", `\(\*?\w+, error\)$`)
+		stdout = captureStdout(t, func() {
+			if err := nav.RunSearch(searchCmd, nil); err != nil {
+				t.Fatalf("RunSearch failed: %v", err)
+			}
+		})
+		if err := json.Unmarshal([]byte(stdout), &payload); err != nil {
+			t.Fatalf("failed to decode search output: %v\noutput=%s", err, stdout)
 		}
-		if !reflect.DeepEqual(hashesBefore, stUnchanged.OutputHashes) {
-			t.Fatalf("expected output hashes to remain stable on unchanged update")
+		if payload.Total != 1 || payload.Matches[0].Name != "LoadConfig" {
+			t.Fatalf("expected LoadConfig as the only (T, error) function, got %#v", payload.Matches)
 		}
+	})
+}
 
-		mustWriteFile(t, filepath.Join(root, "demo.go"), `package demo
+func TestSearchQueryRanksNamesAndFiltersByKindAndFile(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "store", "store.go"), `package store
 
-func A() {
-	B()
-	C()
-}
+type Store struct{}
 
-func B() {}
-func C() {}
+// Save writes the store to disk.
+func (s *Store) Save() error { return nil }
+
+// SaveAll calls save for every store.
+func SaveAll(stores []*Store) error { return nil }
 `)
+	mustWriteFile(t, filepath.Join(root, "cache", "cache.go"), `package cache
 
-		if err := RunUpdate(updateCmd, nil); err != nil {
-			t.Fatalf("RunUpdate after changes failed: %v", err)
+// Save keeps an entry in memory.
+func Save(key string) {}
+`)
+
+	withWorkingDir(t, root, func() {
+		if err := RunGenerate(newGenerateCmdForTest(), []string{"."}); err != nil {
+			t.Fatalf("RunGenerate failed: %v", err)
 		}
 
-		stAfter, err := state.Load(contextDir)
-		if err != nil {
-			t.Fatalf("failed to load state after changed update: %v", err)
+		runSearch := func(query string, flags map[string]string) (int, []nav.HybridMatch) {
+			searchCmd := newSearchCmdForTest()
+			mustSetFlag(t, searchCmd, "json", "true")
+			for name, value := range flags {
+				mustSetFlag(t, searchCmd, name, value)
+			}
+			var payload struct {
+				Total   int               `json:"total"`
+				Matches []nav.HybridMatch `json:"matches"`
+			}
+			stdout := captureStdout(t, func() {
+				if err := nav.RunSearch(searchCmd, []string{query}); err != nil {
+					t.Fatalf("RunSearch failed: %v", err)
+				}
+			})
+			if err := json.Unmarshal([]byte(stdout), &payload); err != nil {
+				t.Fatalf("failed to decode search output: %v\noutput=%s", err, stdout)
+			}
+			return payload.Total, payload.Matches
 		}
 
-		if stAfter.OutputHashes["symbols.jsonl"] == hashesBefore["symbols.jsonl"] {
-			t.Fatalf("expected symbols.jsonl hash to change after source updates")
+		total, matches := runSearch("save", nil)
+		if total < 3 || !matches[0].Exact || !matches[1].Exact || matches[2].Name != "SaveAll" {
+			t.Fatalf("expected both Save symbols before SaveAll, got %#v", matches)
 		}
-		if stAfter.OutputHashes["edges.jsonl"] == hashesBefore["edges.jsonl"] {
-			t.Fatalf("expected edges.jsonl hash to change after source updates")
+		if _, matches = runSearch("save", map[string]string{"kind": "method"}); len(matches) != 1 || matches[0].File != "store/store.go" {
+			t.Fatalf("expected --kind method to keep Store.Save only, got %#v", matches)
 		}
-		if stAfter.OutputHashes["manifest.json"] == hashesBefore["manifest.json"] {
-			t.Fatalf("expected manifest.json hash to change after source updates")
+		if _, matches = runSearch("save", map[string]string{"file": "cache"}); len(matches) != 1 || matches[0].File != "cache/cache.go" {
+			t.Fatalf("expected --file cache to keep cache.Save only, got %#v", matches)
+		}
+		if _, matches = runSearch("SaevAll", nil); len(matches) == 0 || matches[0].Name != "SaveAll" || matches[0].Fuzzy == 0 {
+			t.Fatalf("expected a typo to fall back to SaveAll, got %#v", matches)
+		}
+
+		searchCmd := newSearchCmdForTest()
+		mustSetFlag(t, searchCmd, "signature", "func Save*")
+		if err := nav.RunSearch(searchCmd, []string{"save"}); err == nil {
+			t.Fatalf("expected a query with --signature to be rejected")
 		}
 	})
 }
 
-func TestUpdateCanSwitchToJSONLWithoutSourceChanges(t *testing.T) {
+func TestGrepMatchesCallsAndArityAnchoredToSymbols(t *testing.T) {
 	root := t.TempDir()
-	mustWriteFile(t, filepath.Join(root, "demo.go"), `package demo
+	mustWriteFile(t, filepath.Join(root, "store", "store.go"), `package store
 
-func A() {}
+type Store struct{}
+
+func (s *Store) Save(key, value string, ttl int) error { return nil }
+
+func Sync(s *Store) {
+	s.Save("a", "b", 1)
+	Save("a")
+}
+`)
+	mustWriteFile(t, filepath.Join(root, "app", "main.py"), `def run(store, key, value, ttl):
+    store.save(key, value)
 `)
 
 	withWorkingDir(t, root, func() {
-		if err := RunInit(&cobra.Command{}, nil); err != nil {
-			t.Fatalf("RunInit failed: %v", err)
-		}
 		if err := RunGenerate(newGenerateCmdForTest(), []string{"."}); err != nil {
 			t.Fatalf("RunGenerate failed: %v", err)
 		}
 
-		contextDir := filepath.Join(root, output.ContextDir)
-		assertExists(t, filepath.Join(contextDir, "index.txt"))
-		assertNotExists(t, filepath.Join(contextDir, "symbols.jsonl"))
+		runGrep := func(flags map[string]string) []grepMatch {
+			grepCmd := newGrepCmdForTest()
+			mustSetFlag(t, grepCmd, "json", "true")
+			for name, value := range flags {
+				mustSetFlag(t, grepCmd, name, value)
+			}
+			var payload struct {
+				Total   int         `json:"total"`
+				Matches []grepMatch `json:"matches"`
+			}
+			stdout := captureStdout(t, func() {
+				if err := RunGrep(grepCmd, nil); err != nil {
+					t.Fatalf("RunGrep failed: %v", err)
+				}
+			})
+			if err := json.Unmarshal([]byte(stdout), &payload); err != nil {
+				t.Fatalf("failed to decode grep output: %v\noutput=%s", err, stdout)
+			}
+			return payload.Matches
+		}
 
-		updateCmd := newUpdateCmdForTest()
-		mustSetFlag(t, updateCmd, "format", "jsonl")
-		if err := RunUpdate(updateCmd, nil); err != nil {
-			t.Fatalf("RunUpdate failed: %v", err)
+		matches := runGrep(map[string]string{"call": "Save", "min-args": "2"})
+		if len(matches) != 1 || matches[0].File != "store/store.go" || matches[0].Line != 8 || matches[0].Count != 3 {
+			t.Fatalf("expected the three-argument Save call only, got %#v", matches)
+		}
+		if matches[0].Symbol == nil || matches[0].Symbol.Name != "Sync" {
+			t.Fatalf("expected the call to be anchored to Sync, got %#v", matches[0].Symbol)
 		}
 
-		assertExists(t, filepath.Join(contextDir, "symbols.jsonl"))
-		assertExists(t, filepath.Join(contextDir, "edges.jsonl"))
-		assertExists(t, filepath.Join(contextDir, "manifest.json"))
-		assertNotExists(t, filepath.Join(contextDir, "index.txt"))
-		assertNotExists(t, filepath.Join(contextDir, "graph.txt"))
+		matches = runGrep(map[string]string{"min-params": "3"})
+		if len(matches) != 2 || matches[0].Symbol == nil || matches[0].Symbol.Name != "run" || matches[1].Symbol == nil || matches[1].Symbol.Name != "Save" {
+			t.Fatalf("expected run and Store.Save as functions with 3+ parameters, got %#v", matches)
+		}
+
+		matches = runGrep(map[string]string{"lang": "python", "query": `(call function: (attribute attribute: (identifier) @name)) @match`})
+		if len(matches) != 1 || matches[0].Captures["name"] != "save" || matches[0].Symbol == nil || matches[0].Symbol.Name != "run" {
+			t.Fatalf("expected the raw query to find store.save in run, got %#v", matches)
+		}
+
+		grepCmd := newGrepCmdForTest()
+		mustSetFlag(t, grepCmd, "query", "(call) @match")
+		if err := RunGrep(grepCmd, nil); err == nil || !strings.Contains(err.Error(), "--lang") {
+			t.Fatalf("expected --query without --lang to be rejected, got %v", err)
+		}
 	})
 }
 
-func TestUpdateRefreshesMissingTextModulesOnNoop(t *testing.T) {
+func TestServeHTTPAPIExposesNavigationSearchAndEnrich(t *testing.T) {
 	root := t.TempDir()
-	mustWriteFile(t, filepath.Join(root, "demo.go"), `package demo
+	mustWriteFile(t, filepath.Join(root, "auth", "login.go"), `package auth
 
-func A() {}
+// Login checks the credentials and opens a session.
+func Login(user string) error {
+	return ValidateToken(user)
+}
+
+func ValidateToken(token string) error {
+	return nil
+}
 `)
 
 	withWorkingDir(t, root, func() {
-		if err := RunInit(newInitCmdForTest(), nil); err != nil {
-			t.Fatalf("RunInit failed: %v", err)
+		if err := RunGenerate(newGenerateCmdForTest(), []string{"."}); err != nil {
+			t.Fatalf("RunGenerate failed: %v", err)
+		}
+		if err := RunEnrich(newEnrichCmdForTest(), []string{"auth/login.go:Login", "Authenticates a user and starts a session."}); err != nil {
+			t.Fatalf("RunEnrich failed: %v", err)
+		}
+	})
+
+	server := httptest.NewServer(nav.NewServer(root).Handler())
+	defer server.Close()
+	get := func(path string, wantStatus int, payload any) {
+		t.Helper()
+		resp, err := http.Get(server.URL + path)
+		if err != nil {
+			t.Fatalf("GET %s failed: %v", path, err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		if resp.StatusCode != wantStatus {
+			t.Fatalf("GET %s: expected status %d, got %d: %s", path, wantStatus, resp.StatusCode, body)
+		}
+		if err := json.Unmarshal(body, payload); err != nil {
+			t.Fatalf("GET %s: failed to decode %s: %v", path, body, err)
+		}
+	}
+
+	var symbol struct {
+		Symbol  nav.SymbolRecord `json:"symbol"`
+		Callees int              `json:"callees"`
+		Doc     string           `json:"doc"`
+		Enrich  enrich.Output    `json:"enrich"`
+	}
+	get("/symbols/Login", http.StatusOK, &symbol)
+	if symbol.Symbol.Name != "Login" || symbol.Callees != 1 || !strings.Contains(symbol.Doc, "opens a session") || symbol.Enrich.Summary != "Authenticates a user and starts a session." {
+		t.Fatalf("unexpected symbol payload: %+v", symbol)
+	}
+
+	var byID struct {
+		Symbol nav.SymbolRecord `json:"symbol"`
+	}
+	get("/symbols/"+url.PathEscape(symbol.Symbol.ID), http.StatusOK, &byID)
+	if byID.Symbol.ID != symbol.Symbol.ID {
+		t.Fatalf("expected an escaped symbol ID to resolve, got %+v", byID)
+	}
+
+	var callers struct {
+		Callers []nav.EdgeRecord `json:"callers"`
+	}
+	get("/callers/ValidateToken", http.StatusOK, &callers)
+	if len(callers.Callers) != 1 || callers.Callers[0].Symbol.Name != "Login" {
+		t.Fatalf("expected Login to call ValidateToken, got %+v", callers)
+	}
+
+	var trace struct {
+		Hops []nav.TraceHop `json:"hops"`
+	}
+	get("/trace/Login?depth=2&direction=out", http.StatusOK, &trace)
+	if len(trace.Hops) != 1 || trace.Hops[0].To.Name != "ValidateToken" {
+		t.Fatalf("expected one hop to ValidateToken, got %+v", trace)
+	}
+
+	var results struct {
+		Total   int               `json:"total"`
+		Matches []nav.HybridMatch `json:"matches"`
+	}
+	get("/search?q=validate+token&kind=func", http.StatusOK, &results)
+	if results.Total == 0 || results.Matches[0].Name != "ValidateToken" {
+		t.Fatalf("expected ValidateToken to rank first, got %+v", results)
+	}
+
+	var listing struct {
+		Total   int                `json:"total"`
+		Symbols []nav.SymbolRecord `json:"symbols"`
+	}
+	get("/symbols?file=auth&limit=1", http.StatusOK, &listing)
+	if listing.Total != 2 || len(listing.Symbols) != 1 || listing.Symbols[0].Name != "Login" {
+		t.Fatalf("expected the listing capped at one of two symbols, got %+v", listing)
+	}
+
+	var records struct {
+		Records []enrich.Record `json:"records"`
+	}
+	get("/enrich/Login", http.StatusOK, &records)
+	if len(records.Records) != 1 {
+		t.Fatalf("expected one enrich record, got %+v", records)
+	}
+
+	var failure struct {
+		Error string `json:"error"`
+	}
+	get("/callers/Missing", http.StatusNotFound, &failure)
+	get("/trace/Login?direction=sideways", http.StatusBadRequest, &failure)
+	if !strings.Contains(failure.Error, "direction") {
+		t.Fatalf("expected a direction error, got %+v", failure)
+	}
+}
+
+func TestServeUIServesExplorerNextToAPI(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "main.go"), `package main
+
+func main() {}
+`)
+	withWorkingDir(t, root, func() {
+		if err := RunGenerate(newGenerateCmdForTest(), []string{"."}); err != nil {
+			t.Fatalf("RunGenerate failed: %v", err)
+		}
+	})
+
+	apiOnly := httptest.NewServer(nav.NewServer(root).Handler())
+	defer apiOnly.Close()
+	resp, err := http.Get(apiOnly.URL + "/ui/")
+	if err != nil {
+		t.Fatalf("GET /ui/ failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected no explorer without --ui, got status %d", resp.StatusCode)
+	}
+
+	server := nav.NewServer(root)
+	server.EnableUI()
+	withUI := httptest.NewServer(server.Handler())
+	defer withUI.Close()
+	get := func(path string) string {
+		t.Helper()
+		resp, err := http.Get(withUI.URL + path)
+		if err != nil {
+			t.Fatalf("GET %s failed: %v", path, err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("GET %s: expected status 200, got %d: %s", path, resp.StatusCode, body)
+		}
+		return string(body)
+	}
+	// The root redirects to the explorer, which loads its script.
+	if page := get("/"); !strings.Contains(page, `<script src="app.js">`) {
+		t.Fatalf("expected the explorer page, got %s", page)
+	}
+	if script := get("/ui/app.js"); !strings.Contains(script, `"/search?limit=30&q="`) || !strings.Contains(script, `symbolPath("/callers", id)`) {
+		t.Fatalf("expected the explorer script to read the API, got %s", script)
+	}
+	if health := get("/health"); !strings.Contains(health, `"status":"ok"`) {
+		t.Fatalf("expected the API next to the explorer, got %s", health)
+	}
+}
+
+func TestDelegateRunsQueriesThroughRunningDaemon(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "main.go"), `package main
+
+func main() {
+	Save()
+}
+
+func Save() {}
+`)
+
+	withWorkingDir(t, root, func() {
+		if err := RunGenerate(newGenerateCmdForTest(), []string{"."}); err != nil {
+			t.Fatalf("RunGenerate failed: %v", err)
+		}
+		if _, delegated := Delegate("test", []string{"callers", "Save"}); delegated {
+			t.Fatalf("expected no delegation without a daemon")
+		}
+
+		rootPath, err := os.Getwd()
+		if err != nil {
+			t.Fatalf("failed to get cwd: %v", err)
+		}
+		contextDir := filepath.Join(rootPath, output.ContextDir)
+		var served []string
+		allow := func(args []string) error { return checkDaemonCommand("test", args) }
+		server := daemon.NewServer(rootPath, contextDir, allow, func(args []string) error {
+			served = append(served, args[0])
+			command := NewRootCommand("test")
+			command.SetArgs(args)
+			return command.Execute()
+		})
+		done := make(chan error, 1)
+		go func() { done <- server.Serve(context.Background()) }()
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			if _, err := daemon.Call(contextDir, daemon.Request{Op: daemon.OpStatus}); err == nil {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("daemon did not start")
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+
+		var code int
+		var delegated bool
+		out := captureStdout(t, func() {
+			code, delegated = Delegate("test", []string{"callers", "Save"})
+		})
+		if !delegated || code != 0 || !strings.Contains(out, "main") {
+			t.Fatalf("expected callers from the daemon, got delegated=%v code=%d output:\n%s", delegated, code, out)
+		}
+		if code, delegated = Delegate("test", []string{"callers", "Missing"}); !delegated || code != 1 {
+			t.Fatalf("expected a failing query to exit 1 through the daemon, got delegated=%v code=%d", delegated, code)
+		}
+		if _, delegated = Delegate("test", []string{"update"}); delegated {
+			t.Fatalf("expected update to run in-process")
+		}
+		// Clients other than Delegate are refused too.
+		for _, args := range [][]string{{"update", "--exec", "touch pwned"}, {"watch"}} {
+			if _, err := daemon.Call(contextDir, daemon.Request{Op: daemon.OpRun, Args: args, Dir: rootPath}); err == nil || !strings.Contains(err.Error(), "only read-only queries") {
+				t.Fatalf("expected the daemon to refuse %v, got %v", args, err)
+			}
+		}
+		t.Setenv(DaemonDisableEnv, "1")
+		if _, delegated = Delegate("test", []string{"callers", "Save"}); delegated {
+			t.Fatalf("expected %s to disable delegation", DaemonDisableEnv)
+		}
+		if !reflect.DeepEqual(served, []string{"callers", "callers"}) {
+			t.Fatalf("expected two queries served by the daemon, got %v", served)
+		}
+
+		if _, err := daemon.Call(contextDir, daemon.Request{Op: daemon.OpStop}); err != nil {
+			t.Fatalf("stop failed: %v", err)
+		}
+		if err := <-done; err != nil {
+			t.Fatalf("Serve failed: %v", err)
+		}
+	})
+}
+
+func TestEnrichSummariesMergeIntoArtifactsAndNavigation(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "auth", "login.go"), `package auth
+
+func Login(user string) error {
+	return ValidateToken(user)
+}
+
+func ValidateToken(token string) error {
+	return nil
+}
+`)
+
+	withWorkingDir(t, root, func() {
+		if err := RunGenerate(newGenerateCmdForTest(), []string{"."}); err != nil {
+			t.Fatalf("RunGenerate failed: %v", err)
+		}
+		if err := RunEnrich(newEnrichCmdForTest(), []string{"auth/login.go:ValidateToken", "Checks that a session token is valid."}); err != nil {
+			t.Fatalf("RunEnrich failed: %v", err)
+		}
+		// No sources changed, but the new summary makes update rewrite the artifacts.
+		if err := RunUpdate(newUpdateCmdForTest(), nil); err != nil {
+			t.Fatalf("RunUpdate failed: %v", err)
 		}
 
 		contextDir := filepath.Join(root, output.ContextDir)
-		modulePath := filepath.Join(contextDir, "modules", "root.txt")
-		assertExists(t, modulePath)
+		moduleText, err := os.ReadFile(filepath.Join(contextDir, output.ModulesDir, "auth.txt"))
+		if err != nil {
+			t.Fatalf("failed to read module file: %v", err)
+		}
+		if !strings.Contains(string(moduleText), "### ValidateToken [func]\nsig: func ValidateToken(token string) error\nsummary: Checks that a session token is valid.\n") {
+			t.Fatalf("expected the summary in the module file, got:\n%s", moduleText)
+		}
 
-		if err := os.Remove(modulePath); err != nil {
-			t.Fatalf("failed to remove module artifact: %v", err)
+		plain := captureStdout(t, func() {
+			if err := nav.RunCallees(newCalleesCmdForTest(), []string{"Login"}); err != nil {
+				t.Fatalf("RunCallees failed: %v", err)
+			}
+		})
+		if strings.Contains(plain, "summary:") {
+			t.Fatalf("expected no summaries without --with-summary, got:\n%s", plain)
 		}
-		assertNotExists(t, modulePath)
+		calleesCmd := newCalleesCmdForTest()
+		mustSetFlag(t, calleesCmd, "with-summary", "true")
+		out := captureStdout(t, func() {
+			if err := nav.RunCallees(calleesCmd, []string{"Login"}); err != nil {
+				t.Fatalf("RunCallees failed: %v", err)
+			}
+		})
+		if !strings.Contains(out, "\n  summary: Checks that a session token is valid.\n") {
+			t.Fatalf("expected the callee summary, got:\n%s", out)
+		}
+
+		symbolCmd := newSymbolCmdForTest()
+		mustSetFlag(t, symbolCmd, "with-summary", "true")
+		mustSetFlag(t, symbolCmd, "json", "true")
+		out = captureStdout(t, func() {
+			if err := nav.RunSymbol(symbolCmd, []string{"ValidateToken"}); err != nil {
+				t.Fatalf("RunSymbol failed: %v", err)
+			}
+		})
+		var payload struct {
+			Matches []nav.SymbolRecord `json:"matches"`
+		}
+		if err := json.Unmarshal([]byte(out), &payload); err != nil {
+			t.Fatalf("failed to decode symbol output: %v", err)
+		}
+		if len(payload.Matches) != 1 || payload.Matches[0].Summary != "Checks that a session token is valid." {
+			t.Fatalf("expected the summary in the JSON record, got %+v", payload.Matches)
+		}
+	})
+}
+
+func TestEnrichOverviewDerivesFileAndModuleOverviews(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "auth", "login.go"), `package auth
+
+import "strings"
+
+// Login signs a user in after checking their token.
+func Login(user string) error {
+	return ValidateToken(strings.TrimSpace(user))
+}
+
+func ValidateToken(token string) error {
+	return nil
+}
+`)
+	mustWriteFile(t, filepath.Join(root, "main.go"), `package main
+
+func main() {}
+`)
+
+	withWorkingDir(t, root, func() {
+		if err := RunGenerate(newGenerateCmdForTest(), []string{"."}); err != nil {
+			t.Fatalf("RunGenerate failed: %v", err)
+		}
+		if err := RunEnrich(newEnrichCmdForTest(), []string{"auth/login.go:ValidateToken", "Checks that a session token is valid."}); err != nil {
+			t.Fatalf("RunEnrich failed: %v", err)
+		}
+		if err := RunUpdate(newUpdateCmdForTest(), nil); err != nil {
+			t.Fatalf("RunUpdate failed: %v", err)
+		}
+		captureStdout(t, func() {
+			if err := RunEnrichOverview(newEnrichCmdForTest(), nil); err != nil {
+				t.Fatalf("RunEnrichOverview failed: %v", err)
+			}
+		})
+
+		overviewPath := filepath.Join(root, output.ContextDir, enrich.OverviewFile)
+		overviews, err := enrich.LoadOverviews(overviewPath)
+		if err != nil {
+			t.Fatalf("LoadOverviews failed: %v", err)
+		}
+		if len(overviews) != 4 {
+			t.Fatalf("expected overviews for two files and two modules, got %+v", overviews)
+		}
+		file := overviews[enrich.OverviewKey(enrich.OverviewScopeFile, "auth/login.go")]
+		if file.Source != enrich.OverviewSourceDerived || file.Symbols != 2 ||
+			!strings.Contains(file.Summary, "ValidateToken (func): Checks that a session token is valid") ||
+			!strings.Contains(file.Summary, "Imports: strings.") {
+			t.Fatalf("expected a derived file overview with symbol summaries and imports, got %+v", file)
+		}
+		module := overviews[enrich.OverviewKey(enrich.OverviewScopeModule, "auth")]
+		if module.Files != 1 || !strings.HasPrefix(module.Summary, "1 file, 2 symbols.") {
+			t.Fatalf("expected a module overview for auth, got %+v", module)
+		}
+
+		if err := RunEnrichOverview(newEnrichCmdForTest(), []string{"auth", "Session sign-in and token checks."}); err != nil {
+			t.Fatalf("RunEnrichOverview with a description failed: %v", err)
+		}
+		mustWriteFile(t, filepath.Join(root, "auth", "login.go"), `package auth
+
+import "strings"
+
+// Login signs a user in after checking their token.
+func Login(user string) error {
+	return ValidateToken(strings.TrimSpace(user))
+}
+
+func ValidateToken(token string) error {
+	return nil
+}
 
+func Logout() {}
+`)
 		if err := RunUpdate(newUpdateCmdForTest(), nil); err != nil {
 			t.Fatalf("RunUpdate failed: %v", err)
 		}
 
-		assertExists(t, modulePath)
-	})
+		doctorCmd := newDoctorCmdForTest()
+		mustSetFlag(t, doctorCmd, "json", "true")
+		out := captureStdout(t, func() {
+			if err := RunDoctor(doctorCmd, nil); err != nil {
+				t.Fatalf("RunDoctor failed: %v", err)
+			}
+		})
+		var doctor DoctorSummary
+		if err := json.Unmarshal([]byte(out), &doctor); err != nil {
+			t.Fatalf("failed to decode doctor output: %v", err)
+		}
+		if doctor.Overviews == nil || doctor.Overviews.Files != 2 || doctor.Overviews.FilesTotal != 2 ||
+			doctor.Overviews.Modules != 2 || doctor.Overviews.Stale != 2 {
+			t.Fatalf("expected full coverage with the auth file and module stale, got %+v", doctor.Overviews)
+		}
+
+		out = captureStdout(t, func() {
+			if err := RunEnrichOverview(newEnrichCmdForTest(), []string{"auth"}); err != nil {
+				t.Fatalf("RunEnrichOverview failed: %v", err)
+			}
+		})
+		if !strings.Contains(out, "files=1 modules=0 kept=1 stale=1 removed=0") {
+			t.Fatalf("expected the agent module overview to be kept and reported stale, got:\n%s", out)
+		}
+		overviews, err = enrich.LoadOverviews(overviewPath)
+		if err != nil {
+			t.Fatalf("LoadOverviews failed: %v", err)
+		}
+		if got := overviews[enrich.OverviewKey(enrich.OverviewScopeModule, "auth")]; got.Source != enrich.OverviewSourceAgent || got.Summary != "Session sign-in and token checks." {
+			t.Fatalf("expected the agent overview to survive a derive run, got %+v", got)
+		}
+
+		out = captureStdout(t, func() {
+			if err := nav.RunPack(newPackCmdForTest(), nil); err != nil {
+				t.Fatalf("RunPack failed: %v", err)
+			}
+		})
+		if !strings.Contains(out, "## auth/login.go\n\n3 symbols. Key: ") {
+			t.Fatalf("expected the file overview under its pack heading, got:\n%s", out)
+		}
+	})
+}
+
+func TestRelatedRanksFilesByCombinedSignals(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "api", "handler.go"), `package api
+
+func HandleOrder() {
+	svc.PlaceOrder()
+	db.QueryRows()
+}
+`)
+	mustWriteFile(t, filepath.Join(root, "api", "health.go"), `package api
+
+func HealthCheck() {}
+`)
+	mustWriteFile(t, filepath.Join(root, "svc", "orders.go"), `package svc
+
+func PlaceOrder() {
+	db.QueryRows()
+}
+`)
+	mustWriteFile(t, filepath.Join(root, "db", "db.go"), `package db
+
+func QueryRows() {}
+`)
+	mustWriteFile(t, filepath.Join(root, "tools", "report.go"), `package tools
+
+func Report() {}
+`)
+
+	withWorkingDir(t, root, func() {
+		if err := RunGenerate(newGenerateCmdForTest(), []string{"."}); err != nil {
+			t.Fatalf("RunGenerate failed: %v", err)
+		}
+
+		relatedCmd := newRelatedCmdForTest()
+		mustSetFlag(t, relatedCmd, "json", "true")
+		var payload struct {
+			Total   int               `json:"total"`
+			Related []nav.RelatedFile `json:"related"`
+		}
+		stdout := captureStdout(t, func() {
+			if err := nav.RunRelated(relatedCmd, []string{"api/handler.go"}); err != nil {
+				t.Fatalf("RunRelated failed: %v", err)
+			}
+		})
+		if err := json.Unmarshal([]byte(stdout), &payload); err != nil {
+			t.Fatalf("failed to decode related output: %v\noutput=%s", err, stdout)
+		}
+		files := make([]string, 0, len(payload.Related))
+		for _, entry := range payload.Related {
+			files = append(files, entry.File)
+		}
+		// svc/orders.go is called and shares the db dependency; db/db.go is only called.
+		want := []string{"svc/orders.go", "db/db.go", "api/health.go"}
+		if strings.Join(files, ",") != strings.Join(want, ",") {
+			t.Fatalf("expected related files %v, got %#v", want, payload.Related)
+		}
+		if !payload.Related[0].Calls || payload.Related[0].SharedDeps != 1 {
+			t.Fatalf("expected svc/orders.go to be a callee sharing one dependency, got %#v", payload.Related[0])
+		}
+
+		lookup, err := nav.LoadLookup(root)
+		if err != nil {
+			t.Fatalf("LoadLookup failed: %v", err)
+		}
+		history := &nav.CoChangeHistory{Commits: 2, CoChanges: map[string]int{"db/db.go": 2, "tools/report.go": 1}}
+		related, err := nav.RankRelated(lookup, "api/handler.go", history)
+		if err != nil {
+			t.Fatalf("RankRelated failed: %v", err)
+		}
+		if related[0].File != "db/db.go" || related[0].CoChanges != 2 || related[len(related)-1].File != "api/health.go" {
+			t.Fatalf("expected co-change history to promote db/db.go and add tools/report.go, got %#v", related)
+		}
+
+		if err := nav.RunRelated(newRelatedCmdForTest(), []string{"missing.go"}); err == nil {
+			t.Fatalf("expected related to reject a file that is not indexed")
+		}
+	})
+}
+
+func TestNavigationCommandsJSONWithLSPMetadata(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "demo.go"), `package demo
+
+func Start() { Mid() }
+func Mid() { End() }
+func End() {}
+`)
+
+	withWorkingDir(t, root, func() {
+		if err := RunInit(newInitCmdForTest(), nil); err != nil {
+			t.Fatalf("RunInit failed: %v", err)
+		}
+		if err := RunGenerate(newGenerateCmdForTest(), []string{"."}); err != nil {
+			t.Fatalf("RunGenerate failed: %v", err)
+		}
+
+		callersCmd := newCallersCmdForTest()
+		mustSetFlag(t, callersCmd, "json", "true")
+		mustSetFlag(t, callersCmd, "lsp", "true")
+
+		var payload struct {
+			Callers []nav.EdgeRecord `json:"callers"`
+			LSP     *nav.LSPStatus   `json:"lsp"`
+		}
+		stdout := captureStdout(t, func() {
+			if err := nav.RunCallers(callersCmd, []string{"Mid"}); err != nil {
+				t.Fatalf("RunCallers failed: %v", err)
+			}
+		})
+		if err := json.Unmarshal([]byte(stdout), &payload); err != nil {
+			t.Fatalf("failed to decode callers output with lsp metadata: %v\noutput=%s", err, stdout)
+		}
+		if payload.LSP == nil {
+			t.Fatalf("expected lsp metadata in payload, got %#v", payload)
+		}
+		if len(payload.Callers) == 0 || payload.Callers[0].Source != "parser" {
+			t.Fatalf("expected callers source=parser when --lsp enabled, got %#v", payload.Callers)
+		}
+	})
+}
+
+func TestDefinitionAndReferencesCommandsJSON(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "demo.go"), `package demo
+
+func Start() { Mid() }
+func Mid() { End() }
+func End() {}
+`)
+
+	withWorkingDir(t, root, func() {
+		if err := RunInit(newInitCmdForTest(), nil); err != nil {
+			t.Fatalf("RunInit failed: %v", err)
+		}
+		if err := RunGenerate(newGenerateCmdForTest(), []string{"."}); err != nil {
+			t.Fatalf("RunGenerate failed: %v", err)
+		}
+
+		definitionCmd := newDefinitionCmdForTest()
+		mustSetFlag(t, definitionCmd, "json", "true")
+
+		var definitionPayload struct {
+			Definition nav.SymbolRecord `json:"definition"`
+		}
+		stdout := captureStdout(t, func() {
+			if err := nav.RunDefinition(definitionCmd, []string{"demo.go:4"}); err != nil {
+				t.Fatalf("RunDefinition failed: %v", err)
+			}
+		})
+		if err := json.Unmarshal([]byte(stdout), &definitionPayload); err != nil {
+			t.Fatalf("failed to decode definition output: %v\noutput=%s", err, stdout)
+		}
+		if definitionPayload.Definition.Name != "Mid" {
+			t.Fatalf("expected Mid definition from line lookup, got %#v", definitionPayload.Definition)
+		}
+
+		referencesCmd := newReferencesCmdForTest()
+		mustSetFlag(t, referencesCmd, "json", "true")
+
+		var referencesPayload struct {
+			References []nav.EdgeRecord `json:"references"`
+		}
+		stdout = captureStdout(t, func() {
+			if err := nav.RunReferences(referencesCmd, []string{"Mid"}); err != nil {
+				t.Fatalf("RunReferences failed: %v", err)
+			}
+		})
+		if err := json.Unmarshal([]byte(stdout), &referencesPayload); err != nil {
+			t.Fatalf("failed to decode references output: %v\noutput=%s", err, stdout)
+		}
+		if len(referencesPayload.References) != 1 || referencesPayload.References[0].Symbol.Name != "Start" {
+			t.Fatalf("expected Start to reference Mid, got %#v", referencesPayload.References)
+		}
+	})
+}
+
+func TestGenerateParsesSupportedLanguageFixtures(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "go", "main.go"), `package demo
+func A() {}
+`)
+	mustWriteFile(t, filepath.Join(root, "python", "main.py"), `def run():
+    return helper()
+
+def helper():
+    return 1
+`)
+	mustWriteFile(t, filepath.Join(root, "ruby", "main.rb"), `def run
+  helper
+end
+
+def helper
+  1
+end
+`)
+	mustWriteFile(t, filepath.Join(root, "typescript", "main.ts"), `export function run() { return helper(); }
+function helper() { return 1; }
+`)
+	mustWriteFile(t, filepath.Join(root, "javascript", "main.js"), `export function run() { return helper(); }
+function helper() { return 1; }
+`)
+
+	withWorkingDir(t, root, func() {
+		if err := RunInit(&cobra.Command{}, nil); err != nil {
+			t.Fatalf("RunInit failed: %v", err)
+		}
+
+		if err := RunGenerate(newGenerateCmdForTest(), []string{"."}); err != nil {
+			t.Fatalf("RunGenerate failed: %v", err)
+		}
+
+		indexData, err := os.ReadFile(filepath.Join(root, output.ContextDir, "index.txt"))
+		if err != nil {
+			t.Fatalf("failed to read index output: %v", err)
+		}
+		indexText := string(indexData)
+		for _, expected := range []string{
+			"go/main.go",
+			"python/main.py",
+			"ruby/main.rb",
+			"typescript/main.ts",
+			"javascript/main.js",
+		} {
+			if !strings.Contains(indexText, expected) {
+				t.Fatalf("expected index to contain %s", expected)
+			}
+		}
+	})
+}
+
+func TestGenerateJSONLRecordsEdgeTypes(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "shapes.ts"), `interface Shape { area(): number }
+class Base { describe() { return "" } }
+class Square extends Base implements Shape {
+  area() { return this.describe().length }
+}
+`)
+	mustWriteFile(t, filepath.Join(root, "legacy.js"), `import { Base } from "./shapes"
+class Legacy extends Base {}
+`)
+
+	withWorkingDir(t, root, func() {
+		if err := RunInit(newInitCmdForTest(), nil); err != nil {
+			t.Fatalf("RunInit failed: %v", err)
+		}
+		genCmd := newGenerateCmdForTest()
+		mustSetFlag(t, genCmd, "format", "jsonl")
+		if err := RunGenerate(genCmd, []string{"."}); err != nil {
+			t.Fatalf("RunGenerate failed: %v", err)
+		}
+
+		edges := make(map[string]string)
+		for _, line := range strings.Split(strings.TrimSpace(mustReadFile(t, filepath.Join(root, output.ContextDir, "edges.jsonl"))), "\n") {
+			var record struct {
+				SourceID string `json:"source_id"`
+				TargetID string `json:"target_id"`
+				EdgeType string `json:"edge_type"`
+			}
+			if err := json.Unmarshal([]byte(line), &record); err != nil {
+				t.Fatalf("failed to decode edge %q: %v", line, err)
+			}
+			source, target := record.SourceID, record.TargetID
+			if record.EdgeType != "import" {
+				_, source = graph.ParseNodeID(source)
+				_, target = graph.ParseNodeID(target)
+			}
+			edges[source+"->"+target] = record.EdgeType
+		}
+
+		want := map[string]string{
+			"Square->Base":               "inherit",
+			"Square->Shape":              "implement",
+			"Legacy->Base":               "inherit",
+			"Square.area->Base.describe": "call",
+			"legacy.js->shapes.ts":       "import",
+		}
+		for edge, edgeType := range want {
+			if edges[edge] != edgeType {
+				t.Fatalf("expected %s edge %s, got %#v", edgeType, edge, edges)
+			}
+		}
+	})
+}
+
+func TestCallersKindFilterSelectsEdgeKinds(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "shapes.ts"), `class Base { describe() { return "" } }
+class Square extends Base {
+  area() { return this.describe().length }
+}
+`)
+
+	withWorkingDir(t, root, func() {
+		if err := RunInit(newInitCmdForTest(), nil); err != nil {
+			t.Fatalf("RunInit failed: %v", err)
+		}
+		if err := RunGenerate(newGenerateCmdForTest(), []string{"."}); err != nil {
+			t.Fatalf("RunGenerate failed: %v", err)
+		}
+
+		callersOf := func(kind string) []nav.EdgeRecord {
+			cmd := newCallersCmdForTest()
+			mustSetFlag(t, cmd, "json", "true")
+			if kind != "" {
+				mustSetFlag(t, cmd, "kind", kind)
+			}
+			var payload struct {
+				Callers []nav.EdgeRecord `json:"callers"`
+			}
+			stdout := captureStdout(t, func() {
+				if err := nav.RunCallers(cmd, []string{"Base"}); err != nil {
+					t.Fatalf("RunCallers failed: %v", err)
+				}
+			})
+			if err := json.Unmarshal([]byte(stdout), &payload); err != nil {
+				t.Fatalf("failed to decode callers output: %v\noutput=%s", err, stdout)
+			}
+			return payload.Callers
+		}
+
+		inherit := callersOf("inherit")
+		if len(inherit) != 1 || inherit[0].Symbol.Name != "Square" || inherit[0].Kind != "inherit" {
+			t.Fatalf("expected Square as the only inherit edge into Base, got %#v", inherit)
+		}
+		if calls := callersOf("call"); len(calls) != 0 {
+			t.Fatalf("expected --kind call to drop the inherit edge, got %#v", calls)
+		}
+		if all := callersOf(""); len(all) != 1 || all[0].Kind != "inherit" {
+			t.Fatalf("expected every edge kind without --kind, got %#v", all)
+		}
+
+		stdout := captureStdout(t, func() {
+			if err := nav.RunCallees(newCalleesCmdForTest(), []string{"Square"}); err != nil {
+				t.Fatalf("RunCallees failed: %v", err)
+			}
+		})
+		if !strings.Contains(stdout, "shapes.ts:1 (resolved) kind=inherit") {
+			t.Fatalf("expected callees text to mark the inherit edge, got:\n%s", stdout)
+		}
+
+		cmd := newCallersCmdForTest()
+		mustSetFlag(t, cmd, "kind", "calls")
+		if err := nav.RunCallers(cmd, []string{"Base"}); err == nil || !strings.Contains(err.Error(), "unknown edge kind") {
+			t.Fatalf("expected unknown edge kind error, got %v", err)
+		}
+	})
+}
+
+func TestCallersIncludeReferencesListsTypeUsers(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "store.go"), `package store
+
+type User struct{}
+
+type Order struct {
+	Owner *User
+}
+
+func NewUser() *User { return &User{} }
+
+func Rename(u *User) { NewUser() }
+`)
+
+	withWorkingDir(t, root, func() {
+		if err := RunInit(newInitCmdForTest(), nil); err != nil {
+			t.Fatalf("RunInit failed: %v", err)
+		}
+		if err := RunGenerate(newGenerateCmdForTest(), []string{"."}); err != nil {
+			t.Fatalf("RunGenerate failed: %v", err)
+		}
+
+		callersOf := func(name string, includeReferences bool) map[string]string {
+			cmd := newCallersCmdForTest()
+			mustSetFlag(t, cmd, "json", "true")
+			if includeReferences {
+				mustSetFlag(t, cmd, "include-references", "true")
+			}
+			var payload struct {
+				Callers []nav.EdgeRecord `json:"callers"`
+			}
+			stdout := captureStdout(t, func() {
+				if err := nav.RunCallers(cmd, []string{name}); err != nil {
+					t.Fatalf("RunCallers failed: %v", err)
+				}
+			})
+			if err := json.Unmarshal([]byte(stdout), &payload); err != nil {
+				t.Fatalf("failed to decode callers output: %v\noutput=%s", err, stdout)
+			}
+			out := make(map[string]string, len(payload.Callers))
+			for _, caller := range payload.Callers {
+				out[caller.Symbol.Name] = caller.Kind + "/" + caller.Confidence
+			}
+			return out
+		}
+
+		if callers := callersOf("User", false); len(callers) != 0 {
+			t.Fatalf("expected no callers of User without --include-references, got %#v", callers)
+		}
+		want := map[string]string{
+			"Order":   "reference/heuristic",
+			"NewUser": "reference/heuristic",
+			"Rename":  "reference/heuristic",
+		}
+		if callers := callersOf("User", true); !reflect.DeepEqual(callers, want) {
+			t.Fatalf("expected reference callers %#v, got %#v", want, callers)
+		}
+		if callers := callersOf("NewUser", true); callers["Rename"] != "call/resolved" {
+			t.Fatalf("expected Rename to stay a call edge into NewUser, got %#v", callers)
+		}
+	})
+}
+
+func TestCallersFollowsJSXRenderEdges(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "Button.tsx"), `export function Button() { return <button/> }
+`)
+	mustWriteFile(t, filepath.Join(root, "App.tsx"), `import { Button } from "./Button"
+export function App() { return <main><Button/></main> }
+`)
+
+	withWorkingDir(t, root, func() {
+		if err := RunInit(newInitCmdForTest(), nil); err != nil {
+			t.Fatalf("RunInit failed: %v", err)
+		}
+		if err := RunGenerate(newGenerateCmdForTest(), []string{"."}); err != nil {
+			t.Fatalf("RunGenerate failed: %v", err)
+		}
+
+		cmd := newCallersCmdForTest()
+		mustSetFlag(t, cmd, "json", "true")
+		mustSetFlag(t, cmd, "kind", "render")
+		var payload struct {
+			Symbol  nav.SymbolRecord `json:"symbol"`
+			Callers []nav.EdgeRecord `json:"callers"`
+		}
+		stdout := captureStdout(t, func() {
+			if err := nav.RunCallers(cmd, []string{"Button"}); err != nil {
+				t.Fatalf("RunCallers failed: %v", err)
+			}
+		})
+		if err := json.Unmarshal([]byte(stdout), &payload); err != nil {
+			t.Fatalf("failed to decode callers output: %v\noutput=%s", err, stdout)
+		}
+		if payload.Symbol.Kind != "component" {
+			t.Fatalf("expected Button to be a component, got %#v", payload.Symbol)
+		}
+		if len(payload.Callers) != 1 || payload.Callers[0].Symbol.Name != "App" || payload.Callers[0].Kind != "render" {
+			t.Fatalf("expected App to render Button, got %#v", payload.Callers)
+		}
+	})
+}
+
+func TestGenerateJSONLDeterministic(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "demo.go"), `package demo
+
+func A() {
+	B()
+}
+
+func B() {}
+`)
+
+	withWorkingDir(t, root, func() {
+		if err := RunInit(&cobra.Command{}, nil); err != nil {
+			t.Fatalf("RunInit failed: %v", err)
+		}
+
+		genCmd := newGenerateCmdForTest()
+		mustSetFlag(t, genCmd, "format", "jsonl")
+		if err := RunGenerate(genCmd, []string{"."}); err != nil {
+			t.Fatalf("RunGenerate failed: %v", err)
+		}
+
+		symbolsPath := filepath.Join(root, output.ContextDir, "symbols.jsonl")
+		edgesPath := filepath.Join(root, output.ContextDir, "edges.jsonl")
+		manifestPath := filepath.Join(root, output.ContextDir, "manifest.json")
+
+		assertExists(t, symbolsPath)
+		assertExists(t, edgesPath)
+		assertExists(t, manifestPath)
+		assertNotExists(t, filepath.Join(root, output.ContextDir, "index.txt"))
+		assertNotExists(t, filepath.Join(root, output.ContextDir, "graph.txt"))
+
+		firstSymbols, err := os.ReadFile(symbolsPath)
+		if err != nil {
+			t.Fatalf("failed to read symbols jsonl: %v", err)
+		}
+		firstEdges, err := os.ReadFile(edgesPath)
+		if err != nil {
+			t.Fatalf("failed to read edges jsonl: %v", err)
+		}
+		firstManifest, err := os.ReadFile(manifestPath)
+		if err != nil {
+			t.Fatalf("failed to read manifest: %v", err)
+		}
+
+		assertSortedSymbolJSONL(t, firstSymbols)
+
+		if err := RunGenerate(genCmd, []string{"."}); err != nil {
+			t.Fatalf("second RunGenerate failed: %v", err)
+		}
+
+		secondSymbols, err := os.ReadFile(symbolsPath)
+		if err != nil {
+			t.Fatalf("failed to read symbols jsonl on second run: %v", err)
+		}
+		secondEdges, err := os.ReadFile(edgesPath)
+		if err != nil {
+			t.Fatalf("failed to read edges jsonl on second run: %v", err)
+		}
+		secondManifest, err := os.ReadFile(manifestPath)
+		if err != nil {
+			t.Fatalf("failed to read manifest on second run: %v", err)
+		}
+
+		if !bytes.Equal(firstSymbols, secondSymbols) {
+			t.Fatalf("expected deterministic symbols jsonl output")
+		}
+		if !bytes.Equal(firstEdges, secondEdges) {
+			t.Fatalf("expected deterministic edges jsonl output")
+		}
+		if !bytes.Equal(firstManifest, secondManifest) {
+			t.Fatalf("expected deterministic manifest output")
+		}
+
+		// Streamed artifacts leave no temporary files behind.
+		leftovers, err := filepath.Glob(filepath.Join(root, output.ContextDir, ".*.tmp"))
+		if err != nil {
+			t.Fatalf("failed to glob context dir: %v", err)
+		}
+		if len(leftovers) != 0 {
+			t.Fatalf("expected no temporary artifacts, got %v", leftovers)
+		}
+	})
+}
+
+func TestGenerateJSONLSplitsNamespaces(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, ".skellyignore"), "!vendor/\n")
+	mustWriteFile(t, filepath.Join(root, "main.go"), `package demo
+
+func Run() { Decode() }
+`)
+	mustWriteFile(t, filepath.Join(root, "api.pb.go"), `package demo
+
+func Decode() {}
+`)
+	mustWriteFile(t, filepath.Join(root, "vendor", "lib", "lib.go"), `package lib
+
+func Helper() {}
+`)
+
+	withWorkingDir(t, root, func() {
+		genCmd := newGenerateCmdForTest()
+		mustSetFlag(t, genCmd, "format", "jsonl")
+		if err := RunGenerate(genCmd, []string{"."}); err != nil {
+			t.Fatalf("RunGenerate failed: %v", err)
+		}
+
+		contextDir := filepath.Join(root, output.ContextDir)
+		primary := mustReadFile(t, filepath.Join(contextDir, "symbols.jsonl"))
+		if !strings.Contains(primary, `"name":"Run"`) || strings.Contains(primary, `"name":"Decode"`) || strings.Contains(primary, `"name":"Helper"`) {
+			t.Fatalf("expected primary symbols to contain only handwritten code, got:\n%s", primary)
+		}
+		generated := mustReadFile(t, filepath.Join(contextDir, "namespaces", "generated", "symbols.jsonl"))
+		if !strings.Contains(generated, `"name":"Decode"`) {
+			t.Fatalf("expected generated namespace to contain Decode, got:\n%s", generated)
+		}
+		vendored := mustReadFile(t, filepath.Join(contextDir, "namespaces", "vendor", "symbols.jsonl"))
+		if !strings.Contains(vendored, `"name":"Helper"`) {
+			t.Fatalf("expected vendor namespace to contain Helper, got:\n%s", vendored)
+		}
+
+		var manifest struct {
+			Counts struct {
+				Symbols int `json:"symbols"`
+			} `json:"counts"`
+			Namespaces []struct {
+				Name    string `json:"name"`
+				Primary bool   `json:"primary"`
+			} `json:"namespaces"`
+		}
+		if err := json.Unmarshal([]byte(mustReadFile(t, filepath.Join(contextDir, "manifest.json"))), &manifest); err != nil {
+			t.Fatalf("failed to decode manifest: %v", err)
+		}
+		if manifest.Counts.Symbols != 3 || len(manifest.Namespaces) != 3 || manifest.Namespaces[0].Name != "primary" || !manifest.Namespaces[0].Primary {
+			t.Fatalf("unexpected manifest namespaces: %#v", manifest)
+		}
+
+		st, err := state.Load(contextDir)
+		if err != nil {
+			t.Fatalf("failed to load state: %v", err)
+		}
+		if _, ok := st.OutputHashes["namespaces/generated/symbols.jsonl"]; !ok {
+			t.Fatalf("expected namespace artifacts in output hashes, got %#v", st.OutputHashes)
+		}
+
+		if err := os.Remove(filepath.Join(root, "api.pb.go")); err != nil {
+			t.Fatalf("failed to remove generated file: %v", err)
+		}
+		updateCmd := newUpdateCmdForTest()
+		mustSetFlag(t, updateCmd, "format", "jsonl")
+		if err := RunUpdate(updateCmd, nil); err != nil {
+			t.Fatalf("RunUpdate failed: %v", err)
+		}
+		assertNotExists(t, filepath.Join(contextDir, "namespaces", "generated"))
+	})
+}
+
+func TestGenerateJSONLWritesModuleGraph(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "cmd", "app", "main.go"), `package main
+
+import "example.com/demo/store"
+
+func main() {
+	store.Load()
+	store.Save()
+}
+`)
+	mustWriteFile(t, filepath.Join(root, "store", "store.go"), `package store
+
+func Load() { Save() }
+`)
+	mustWriteFile(t, filepath.Join(root, "store", "save.go"), `package store
+
+func Save() {}
+`)
+
+	withWorkingDir(t, root, func() {
+		genCmd := newGenerateCmdForTest()
+		mustSetFlag(t, genCmd, "format", "jsonl")
+		if err := RunGenerate(genCmd, []string{"."}); err != nil {
+			t.Fatalf("RunGenerate failed: %v", err)
+		}
+
+		contextDir := filepath.Join(root, output.ContextDir)
+		type moduleLine struct {
+			Type      string   `json:"type"`
+			ID        string   `json:"id"`
+			Files     int      `json:"files"`
+			LOC       int      `json:"loc"`
+			Languages []string `json:"languages"`
+			Imports   []string `json:"imports"`
+			Top       []struct {
+				Name string `json:"name"`
+			} `json:"top_symbols"`
+			FanIn   int            `json:"fan_in"`
+			FanOut  int            `json:"fan_out"`
+			CallsIn int            `json:"calls_in"`
+			Source  string         `json:"source"`
+			Target  string         `json:"target"`
+			Weight  int            `json:"weight"`
+			Counts  map[string]int `json:"confidence"`
+		}
+		lines := make([]moduleLine, 0)
+		for _, raw := range strings.Split(strings.TrimSpace(mustReadFile(t, filepath.Join(contextDir, "modules.jsonl"))), "\n") {
+			var line moduleLine
+			if err := json.Unmarshal([]byte(raw), &line); err != nil {
+				t.Fatalf("failed to decode modules.jsonl line %q: %v", raw, err)
+			}
+			lines = append(lines, line)
+		}
+		if len(lines) != 3 {
+			t.Fatalf("expected two modules and one dependency, got %#v", lines)
+		}
+		app, store, dependency := lines[0], lines[1], lines[2]
+		if app.Type != "module" || app.ID != "cmd/app" || app.FanOut != 1 || app.FanIn != 0 {
+			t.Fatalf("unexpected cmd/app module: %#v", app)
+		}
+		if app.LOC != 8 || len(app.Imports) != 1 || app.Imports[0] != "example.com/demo/store" {
+			t.Fatalf("expected cmd/app LOC and imports, got %#v", app)
+		}
+		if store.LOC != 6 || len(store.Imports) != 0 || len(store.Top) != 2 || store.Top[0].Name != "Save" {
+			t.Fatalf("expected store summary with Save ranked first, got %#v", store)
+		}
+		if store.ID != "store" || store.Files != 2 || store.FanIn != 1 || store.CallsIn != 2 || len(store.Languages) != 1 || store.Languages[0] != "go" {
+			t.Fatalf("unexpected store module: %#v", store)
+		}
+		if dependency.Type != "dependency" || dependency.Source != "cmd/app" || dependency.Target != "store" || dependency.Weight != 2 {
+			t.Fatalf("unexpected dependency: %#v", dependency)
+		}
+
+		manifest := mustReadFile(t, filepath.Join(contextDir, "manifest.json"))
+		if !strings.Contains(manifest, `"path": "modules.jsonl"`) || !strings.Contains(manifest, `"modules": 2`) {
+			t.Fatalf("expected manifest to list modules.jsonl, got:\n%s", manifest)
+		}
+
+		updateCmd := newUpdateCmdForTest()
+		mustSetFlag(t, updateCmd, "format", "text")
+		if err := RunUpdate(updateCmd, nil); err != nil {
+			t.Fatalf("RunUpdate failed: %v", err)
+		}
+		assertNotExists(t, filepath.Join(contextDir, "modules.jsonl"))
+	})
+}
+
+func TestGenerateCtagsWritesSortedTagsFile(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "store", "store.go"), `package store
+
+type Store struct{}
+
+func (s *Store) Save() {}
+
+func Load() {}
+`)
+	mustWriteFile(t, filepath.Join(root, "app.go"), `package app
+
+func Main() {}
+`)
+
+	withWorkingDir(t, root, func() {
+		genCmd := newGenerateCmdForTest()
+		mustSetFlag(t, genCmd, "format", "ctags")
+		if err := RunGenerate(genCmd, []string{"."}); err != nil {
+			t.Fatalf("RunGenerate failed: %v", err)
+		}
+
+		contextDir := filepath.Join(root, output.ContextDir)
+		tagsPath := filepath.Join(contextDir, output.TagsFile)
+		tags := mustReadFile(t, tagsPath)
+		assertNotExists(t, filepath.Join(contextDir, output.IndexFile))
+		assertNotExists(t, filepath.Join(contextDir, output.SymbolsFile))
+
+		lines := strings.Split(strings.TrimSpace(tags), "\n")
+		if !strings.HasPrefix(lines[0], "!_TAG_FILE_FORMAT\t2\t") || !strings.HasPrefix(lines[1], "!_TAG_FILE_SORTED\t1\t") {
+			t.Fatalf("expected extended sorted tags header, got:\n%s", tags)
+		}
+		names := make([]string, 0)
+		for _, line := range lines {
+			if !strings.HasPrefix(line, "!_TAG_") {
+				names = append(names, strings.SplitN(line, "\t", 2)[0])
+			}
+		}
+		if !sort.StringsAreSorted(names) || len(names) != 4 {
+			t.Fatalf("expected four tags sorted by name, got %v", names)
+		}
+		if !containsString(lines, "Load\t../../store/store.go\t7;\"\tf\tline:7\tlanguage:go\tsignature:func Load()") {
+			t.Fatalf("expected Load tag relative to the tags file, got:\n%s", tags)
+		}
+
+		genCmd = newGenerateCmdForTest()
+		mustSetFlag(t, genCmd, "format", "ctags")
+		if err := RunGenerate(genCmd, []string{"."}); err != nil {
+			t.Fatalf("second RunGenerate failed: %v", err)
+		}
+		if again := mustReadFile(t, tagsPath); again != tags {
+			t.Fatalf("expected deterministic tags output, got:\n%s\nthen:\n%s", tags, again)
+		}
+
+		updateCmd := newUpdateCmdForTest()
+		mustSetFlag(t, updateCmd, "format", "text")
+		if err := RunUpdate(updateCmd, nil); err != nil {
+			t.Fatalf("RunUpdate failed: %v", err)
+		}
+		assertNotExists(t, tagsPath)
+	})
+}
+
+func TestUpdateKeepsTheRecordedOutputFormat(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "app.go"), "package app\n\nfunc Main() {}\n")
+
+	withWorkingDir(t, root, func() {
+		genCmd := newGenerateCmdForTest()
+		mustSetFlag(t, genCmd, "format", "ctags")
+		if err := RunGenerate(genCmd, []string{"."}); err != nil {
+			t.Fatalf("RunGenerate failed: %v", err)
+		}
+
+		// The pre-commit hook runs a plain update --quick.
+		mustWriteFile(t, filepath.Join(root, "app.go"), "package app\n\nfunc Main() {}\n\nfunc Stop() {}\n")
+		updateCmd := newUpdateCmdForTest()
+		mustSetFlag(t, updateCmd, "quick", "true")
+		captureStdout(t, func() {
+			if err := RunUpdate(updateCmd, nil); err != nil {
+				t.Fatalf("RunUpdate failed: %v", err)
+			}
+		})
+		contextDir := filepath.Join(root, output.ContextDir)
+		if tags := mustReadFile(t, filepath.Join(contextDir, output.TagsFile)); !strings.Contains(tags, "Stop\t") {
+			t.Fatalf("expected update to refresh the tags file, got:\n%s", tags)
+		}
+		assertNotExists(t, filepath.Join(contextDir, output.IndexFile))
+	})
+}
+
+func TestProjectConfigSetsDefaultsThatFlagsOverride(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, ".skelly", "config.yaml"), `format: jsonl
+languages: [go]
+ignore:
+  - skip/
+`)
+	mustWriteFile(t, filepath.Join(root, "app.go"), `package app
+
+func Main() {}
+`)
+	mustWriteFile(t, filepath.Join(root, "skip", "skip.go"), `package skip
+
+func Skipped() {}
+`)
+	mustWriteFile(t, filepath.Join(root, "tool.py"), `def tool():
+    pass
+`)
+
+	withWorkingDir(t, root, func() {
+		if err := RunGenerate(newGenerateCmdForTest(), []string{"."}); err != nil {
+			t.Fatalf("RunGenerate failed: %v", err)
+		}
+		contextDir := filepath.Join(root, output.ContextDir)
+		symbols := mustReadFile(t, filepath.Join(contextDir, output.SymbolsFile))
+		if !strings.Contains(symbols, `"name":"Main"`) || strings.Contains(symbols, "Skipped") || strings.Contains(symbols, "tool") {
+			t.Fatalf("expected config format, languages and ignore rules to apply, got:\n%s", symbols)
+		}
+
+		genCmd := newGenerateCmdForTest()
+		mustSetFlag(t, genCmd, "format", "text")
+		if err := RunGenerate(genCmd, []string{"."}); err != nil {
+			t.Fatalf("RunGenerate --format text failed: %v", err)
+		}
+		assertExists(t, filepath.Join(contextDir, output.IndexFile))
+		assertNotExists(t, filepath.Join(contextDir, output.SymbolsFile))
+
+		mustWriteFile(t, filepath.Join(root, ".skelly", "config.yaml"), "format: yaml\n")
+		if err := RunGenerate(newGenerateCmdForTest(), []string{"."}); err == nil || !strings.Contains(err.Error(), "unsupported format") {
+			t.Fatalf("expected invalid config format to be rejected, got %v", err)
+		}
+	})
+}
+
+func TestProjectConfigOutputDirMovesTheContext(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, ".skelly", "config.yaml"), "output_dir: docs/context\nformat: jsonl\n")
+	mustWriteFile(t, filepath.Join(root, "app.go"), `package app
+
+func Main() {}
+`)
+	t.Cleanup(func() { output.SetContextDir("") })
+
+	withWorkingDir(t, root, func() {
+		run := func(args ...string) {
+			t.Helper()
+			command := NewRootCommand("test")
+			command.SetArgs(args)
+			captureStdout(t, func() {
+				if err := command.Execute(); err != nil {
+					t.Fatalf("skelly %s failed: %v", strings.Join(args, " "), err)
+				}
+			})
+		}
+		run("generate")
+		contextDir := filepath.Join(root, "docs", "context")
+		if output.ContextDir != "docs/context" {
+			t.Fatalf("expected output_dir to move the context, got %q", output.ContextDir)
+		}
+		assertExists(t, filepath.Join(contextDir, output.ManifestFile))
+		assertNotExists(t, filepath.Join(root, output.DefaultContextDir))
+
+		// The context directory is not indexed as source.
+		mustWriteFile(t, filepath.Join(contextDir, "stray.go"), `package stray
+
+func Stray() {}
+`)
+		mustWriteFile(t, filepath.Join(root, "app.go"), `package app
+
+func Main() {}
+
+func Helper() {}
+`)
+		run("update")
+		symbols := mustReadFile(t, filepath.Join(contextDir, output.SymbolsFile))
+		if !strings.Contains(symbols, `"name":"Helper"`) || strings.Contains(symbols, "Stray") {
+			t.Fatalf("expected update to write to output_dir and skip it as source, got:\n%s", symbols)
+		}
+	})
+}
+
+func TestGenerateHonorsNestedGitignoreUnlessDisabled(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, ".gitignore"), "gen/\n")
+	mustWriteFile(t, filepath.Join(root, "web", ".gitignore"), "*.bundle.js\n!keep.bundle.js\n")
+	mustWriteFile(t, filepath.Join(root, ".skellyignore"), "!gen/\n")
+	mustWriteFile(t, filepath.Join(root, "app.go"), `package app
+
+func Main() {}
+`)
+	mustWriteFile(t, filepath.Join(root, "web", "app.bundle.js"), "function bundled() {}\n")
+	mustWriteFile(t, filepath.Join(root, "web", "keep.bundle.js"), "function kept() {}\n")
+	mustWriteFile(t, filepath.Join(root, "web", "src", "other.bundle.js"), "function nested() {}\n")
+	mustWriteFile(t, filepath.Join(root, "gen", "gen.go"), `package gen
+
+func Generated() {}
+`)
+
+	withWorkingDir(t, root, func() {
+		if err := RunGenerate(newGenerateCmdForTest(), []string{"."}); err != nil {
+			t.Fatalf("RunGenerate failed: %v", err)
+		}
+		st, err := state.Load(filepath.Join(root, output.ContextDir))
+		if err != nil {
+			t.Fatalf("state.Load failed: %v", err)
+		}
+		for _, file := range []string{"app.go", "web/keep.bundle.js", "gen/gen.go"} {
+			if _, ok := st.Files[file]; !ok {
+				t.Fatalf("expected %s to be indexed, got %v", file, st.Files)
+			}
+		}
+		for _, file := range []string{"web/app.bundle.js", "web/src/other.bundle.js"} {
+			if _, ok := st.Files[file]; ok {
+				t.Fatalf("expected %s to be excluded by web/.gitignore", file)
+			}
+		}
+
+		genCmd := newGenerateCmdForTest()
+		mustSetFlag(t, genCmd, "no-gitignore", "true")
+		if err := RunGenerate(genCmd, []string{"."}); err != nil {
+			t.Fatalf("RunGenerate --no-gitignore failed: %v", err)
+		}
+		st, err = state.Load(filepath.Join(root, output.ContextDir))
+		if err != nil {
+			t.Fatalf("state.Load failed: %v", err)
+		}
+		if _, ok := st.Files["web/app.bundle.js"]; !ok {
+			t.Fatalf("expected --no-gitignore to index gitignored files, got %v", st.Files)
+		}
+	})
+}
+
+func TestUpdateKeepsNoGitignoreChoiceFromGenerate(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, ".gitignore"), "gen/\n")
+	mustWriteFile(t, filepath.Join(root, "app.go"), `package app
+
+func Main() {}
+`)
+	mustWriteFile(t, filepath.Join(root, "gen", "gen.go"), `package gen
+
+func Generated() {}
+`)
+
+	withWorkingDir(t, root, func() {
+		genCmd := newGenerateCmdForTest()
+		mustSetFlag(t, genCmd, "no-gitignore", "true")
+		if err := RunGenerate(genCmd, []string{"."}); err != nil {
+			t.Fatalf("RunGenerate --no-gitignore failed: %v", err)
+		}
+
+		mustWriteFile(t, filepath.Join(root, "app.go"), `package app
+
+func Main() {}
+
+func Extra() {}
+`)
+		mustWriteFile(t, filepath.Join(root, "gen", "more.go"), `package gen
+
+func More() {}
+`)
+		if err := RunUpdate(newUpdateCmdForTest(), nil); err != nil {
+			t.Fatalf("RunUpdate failed: %v", err)
+		}
+		st, err := state.Load(filepath.Join(root, output.ContextDir))
+		if err != nil {
+			t.Fatalf("state.Load failed: %v", err)
+		}
+		if !st.NoGitignore {
+			t.Fatalf("expected update to keep the recorded --no-gitignore choice")
+		}
+		for _, file := range []string{"gen/gen.go", "gen/more.go"} {
+			if _, ok := st.Files[file]; !ok {
+				t.Fatalf("expected update without the flag to keep indexing %s, got %v", file, st.Files)
+			}
+		}
+
+		updateCmd := newUpdateCmdForTest()
+		mustSetFlag(t, updateCmd, "no-gitignore", "false")
+		if err := RunUpdate(updateCmd, nil); err != nil {
+			t.Fatalf("RunUpdate --no-gitignore=false failed: %v", err)
+		}
+		st, err = state.Load(filepath.Join(root, output.ContextDir))
+		if err != nil {
+			t.Fatalf("state.Load failed: %v", err)
+		}
+		if st.NoGitignore {
+			t.Fatalf("expected --no-gitignore=false to be recorded")
+		}
+		if _, ok := st.Files["gen/gen.go"]; ok {
+			t.Fatalf("expected --no-gitignore=false to drop gitignored files, got %v", st.Files)
+		}
+		if _, ok := st.Files["app.go"]; !ok {
+			t.Fatalf("expected app.go to stay indexed, got %v", st.Files)
+		}
+	})
+}
+
+func TestGenerateWritesRoutesAndRoutesCommandMatchesPaths(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "server.go"), `package server
+
+import "net/http"
+
+type Server struct{}
+
+func (s *Server) Routes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /users/{id}", s.getUser)
+	mux.HandleFunc("POST /users", s.createUser)
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {})
+}
+
+func (s *Server) getUser(w http.ResponseWriter, r *http.Request) {}
+func (s *Server) createUser(w http.ResponseWriter, r *http.Request) {}
+`)
+
+	withWorkingDir(t, root, func() {
+		if err := RunGenerate(newGenerateCmdForTest(), []string{"."}); err != nil {
+			t.Fatalf("RunGenerate failed: %v", err)
+		}
+		routes, err := nav.LoadRoutes(root)
+		if err != nil {
+			t.Fatalf("LoadRoutes failed: %v", err)
+		}
+		if len(routes) != 3 {
+			t.Fatalf("expected three routes, got %#v", routes)
+		}
+		if routes[0].Path != "/healthz" || routes[0].Method != "ANY" || routes[0].Handler != "" {
+			t.Fatalf("expected inline health route first, got %#v", routes[0])
+		}
+
+		routesCmd := newRoutesCmdForTest()
+		mustSetFlag(t, routesCmd, "json", "true")
+		mustSetFlag(t, routesCmd, "method", "get")
+		var payload struct {
+			Total  int               `json:"total"`
+			Routes []nav.RouteRecord `json:"routes"`
+		}
+		stdout := captureStdout(t, func() {
+			if err := nav.RunRoutes(routesCmd, []string{"/users/42"}); err != nil {
+				t.Fatalf("RunRoutes failed: %v", err)
+			}
+		})
+		if err := json.Unmarshal([]byte(stdout), &payload); err != nil {
+			t.Fatalf("failed to decode routes output: %v\noutput=%s", err, stdout)
+		}
+		if payload.Total != 3 || len(payload.Routes) != 1 {
+			t.Fatalf("expected only GET /users/{id} to match, got %#v", payload)
+		}
+		route := payload.Routes[0]
+		if route.Path != "/users/{id}" || route.HandlerName != "s.getUser" || !strings.Contains(route.Handler, "Server.getUser") || route.Confidence != "resolved" {
+			t.Fatalf("expected route resolved to Server.getUser, got %#v", route)
+		}
+	})
+}
+
+func TestGenerateWritesTestsAndTestsForFollowsCallers(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "invoice.go"), `package billing
+
+func Total(amount int) int { return applyTax(amount) }
+
+func applyTax(amount int) int { return amount * 2 }
+
+func Format(amount int) string { return "" }
+`)
+	mustWriteFile(t, filepath.Join(root, "invoice_test.go"), `package billing
+
+import "testing"
+
+func newFixture() int { return Total(10) }
+
+func TestTotal(t *testing.T) {
+	if newFixture() != 20 {
+		t.Fatal("bad total")
+	}
+}
+
+func TestApplyTax(t *testing.T) {
+	applyTax(1)
+}
+`)
+
+	withWorkingDir(t, root, func() {
+		if err := RunGenerate(newGenerateCmdForTest(), []string{"."}); err != nil {
+			t.Fatalf("RunGenerate failed: %v", err)
+		}
+		tests, err := nav.LoadTests(root)
+		if err != nil {
+			t.Fatalf("LoadTests failed: %v", err)
+		}
+		if len(tests) != 2 || tests[0].Name != "TestTotal" || len(tests[0].Targets) != 1 || !strings.Contains(tests[0].Targets[0], "|Total|") {
+			t.Fatalf("expected TestTotal to target Total through its helper, got %#v", tests)
+		}
+
+		testsForCmd := newTestsForCmdForTest()
+		mustSetFlag(t, testsForCmd, "json", "true")
+		var payload struct {
+			Tests []nav.TestMatch `json:"tests"`
+		}
+		stdout := captureStdout(t, func() {
+			if err := nav.RunTestsFor(testsForCmd, []string{"applyTax"}); err != nil {
+				t.Fatalf("RunTestsFor failed: %v", err)
+			}
+		})
+		if err := json.Unmarshal([]byte(stdout), &payload); err != nil {
+			t.Fatalf("failed to decode tests-for output: %v\noutput=%s", err, stdout)
+		}
+		if len(payload.Tests) != 2 {
+			t.Fatalf("expected a direct and an indirect test, got %#v", payload.Tests)
+		}
+		if payload.Tests[0].Test.Name != "TestApplyTax" || payload.Tests[0].Depth != 1 || payload.Tests[0].Via != "" {
+			t.Fatalf("expected TestApplyTax first as a direct test, got %#v", payload.Tests[0])
+		}
+		if payload.Tests[1].Test.Name != "TestTotal" || payload.Tests[1].Depth != 2 || !strings.Contains(payload.Tests[1].Via, "|Total|") {
+			t.Fatalf("expected TestTotal via Total, got %#v", payload.Tests[1])
+		}
+
+		mustSetFlag(t, testsForCmd, "depth", "1")
+		stdout = captureStdout(t, func() {
+			if err := nav.RunTestsFor(testsForCmd, []string{"Format"}); err != nil {
+				t.Fatalf("RunTestsFor failed: %v", err)
+			}
+		})
+		payload.Tests = nil
+		if err := json.Unmarshal([]byte(stdout), &payload); err != nil {
+			t.Fatalf("failed to decode tests-for output: %v\noutput=%s", err, stdout)
+		}
+		if len(payload.Tests) != 0 {
+			t.Fatalf("expected no tests for Format, got %#v", payload.Tests)
+		}
+	})
+}
+
+func TestPackFitsBudgetAndRanksFocusNeighborsFirst(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "billing", "invoice.go"), `package billing
+
+// Total returns the amount with tax applied.
+func Total(amount int) int { return applyTax(amount) }
+
+func applyTax(amount int) int { return amount * 2 }
+`)
+	mustWriteFile(t, filepath.Join(root, "report", "report.go"), `package report
+
+func Render() string { return header() + footer() }
+
+func header() string { return "" }
+
+func footer() string { return "" }
+`)
+
+	withWorkingDir(t, root, func() {
+		if err := RunGenerate(newGenerateCmdForTest(), []string{"."}); err != nil {
+			t.Fatalf("RunGenerate failed: %v", err)
+		}
+
+		packCmd := newPackCmdForTest()
+		mustSetFlag(t, packCmd, "focus", "Total")
+		mustSetFlag(t, packCmd, "json", "true")
+		var bundle nav.PackBundle
+		stdout := captureStdout(t, func() {
+			if err := nav.RunPack(packCmd, nil); err != nil {
+				t.Fatalf("RunPack failed: %v", err)
+			}
+		})
+		if err := json.Unmarshal([]byte(stdout), &bundle); err != nil {
+			t.Fatalf("failed to decode pack output: %v\noutput=%s", err, stdout)
+		}
+		if bundle.Total != 5 || bundle.Packed != 5 || bundle.Tokens > bundle.Budget || len(bundle.Files) != 2 {
+			t.Fatalf("expected every symbol to fit the default budget, got %+v", bundle)
+		}
+		if bundle.Files[0].File != "billing/invoice.go" || bundle.Files[0].Symbols[0].Name != "Total" || bundle.Files[0].Symbols[0].Hops != 0 || bundle.Files[0].Symbols[1].Hops != 1 {
+			t.Fatalf("expected the focus file first with Total and its callee, got %+v", bundle.Files[0])
+		}
+
+		mustSetFlag(t, packCmd, "json", "false")
+		mustSetFlag(t, packCmd, "budget", "60")
+		stdout = captureStdout(t, func() {
+			if err := nav.RunPack(packCmd, nil); err != nil {
+				t.Fatalf("RunPack failed: %v", err)
+			}
+		})
+		if !strings.HasPrefix(stdout, "# Context pack: `Total`") || !strings.Contains(stdout, "- `func Total(amount int) int` (func, invoice.go:4)\n  Total returns the amount with tax applied.\n") {
+			t.Fatalf("expected the focus symbol with its doc in the Markdown bundle, got:\n%s", stdout)
+		}
+		if strings.Contains(stdout, "report.go") || len(stdout) > 60*4 {
+			t.Fatalf("expected a 60-token budget to keep only the focus neighborhood, got:\n%s", stdout)
+		}
+	})
+}
+
+func TestImportanceMetricsExportAndOrderSearchPackAndBootstrap(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "ledger.go"), `package ledger
+
+// Hub stores the ledger entries that every other function eventually writes.
+func Hub() {}
+
+// Middle validates ledger entries before handing them to the hub for storage.
+func Middle() { Hub() }
+
+// Leaf reads ledger entries from the request body and forwards them onward.
+func Leaf() { Middle() }
+
+// Other writes audit ledger entries straight into the hub without validation.
+func Other() { Hub() }
+`)
+
+	withWorkingDir(t, root, func() {
+		generateCmd := newGenerateCmdForTest()
+		mustSetFlag(t, generateCmd, "format", "jsonl")
+		if err := RunGenerate(generateCmd, []string{"."}); err != nil {
+			t.Fatalf("RunGenerate failed: %v", err)
+		}
+		metrics := make(map[string]map[string]any)
+		for _, line := range strings.Split(strings.TrimSpace(mustReadFile(t, filepath.Join(root, output.ContextDir, output.SymbolsFile))), "\n") {
+			var record map[string]any
+			if err := json.Unmarshal([]byte(line), &record); err != nil {
+				t.Fatalf("failed to decode symbol record: %v", err)
+			}
+			metrics[record["name"].(string)] = record
+		}
+		// Leaf -> Middle -> Hub is the only shortest path through Middle, one
+		// of the (4-1)*(4-2) ordered pairs of other symbols.
+		if metrics["Hub"]["in_degree"] != 2.0 || metrics["Leaf"]["out_degree"] != 1.0 || metrics["Middle"]["betweenness"] != 0.166667 {
+			t.Fatalf("unexpected symbol metrics: %v", metrics)
+		}
+		if _, ok := metrics["Hub"]["betweenness"]; ok {
+			t.Fatalf("expected zero betweenness to be omitted, got %v", metrics["Hub"])
+		}
+
+		searchCmd := newSearchCmdForTest()
+		mustSetFlag(t, searchCmd, "json", "true")
+		mustSetFlag(t, searchCmd, "sort", "in-degree")
+		var payload struct {
+			Matches []nav.HybridMatch `json:"matches"`
+		}
+		stdout := captureStdout(t, func() {
+			if err := nav.RunSearch(searchCmd, []string{"ledger"}); err != nil {
+				t.Fatalf("RunSearch failed: %v", err)
+			}
+		})
+		if err := json.Unmarshal([]byte(stdout), &payload); err != nil {
+			t.Fatalf("failed to decode search output: %v\noutput=%s", err, stdout)
+		}
+		if len(payload.Matches) != 4 || payload.Matches[0].Name != "Hub" || payload.Matches[1].Name != "Middle" {
+			t.Fatalf("expected matches by in-degree, got %#v", payload.Matches)
+		}
+		mustSetFlag(t, searchCmd, "sort", "closeness")
+		if err := nav.RunSearch(searchCmd, []string{"ledger"}); err == nil || !strings.Contains(err.Error(), "unsupported metric") {
+			t.Fatalf("expected an unknown metric to fail, got %v", err)
+		}
+
+		packCmd := newPackCmdForTest()
+		mustSetFlag(t, packCmd, "json", "true")
+		mustSetFlag(t, packCmd, "sort", "betweenness")
+		var bundle nav.PackBundle
+		stdout = captureStdout(t, func() {
+			if err := nav.RunPack(packCmd, nil); err != nil {
+				t.Fatalf("RunPack failed: %v", err)
+			}
+		})
+		if err := json.Unmarshal([]byte(stdout), &bundle); err != nil {
+			t.Fatalf("failed to decode pack output: %v\noutput=%s", err, stdout)
+		}
+		for _, symbol := range bundle.Files[0].Symbols {
+			if (symbol.Name == "Middle") != (symbol.Score > 0) {
+				t.Fatalf("expected only Middle to score by betweenness, got %+v", bundle.Files[0].Symbols)
+			}
+		}
+
+		bootstrapCmd := newEnrichBootstrapCmdForTest()
+		mustSetFlag(t, bootstrapCmd, "json", "true")
+		mustSetFlag(t, bootstrapCmd, "order", "betweenness")
+		mustSetFlag(t, bootstrapCmd, "limit", "1")
+		stdout = captureStdout(t, func() {
+			if err := RunEnrichBootstrap(bootstrapCmd, nil); err != nil {
+				t.Fatalf("RunEnrichBootstrap failed: %v", err)
+			}
+		})
+		var summary EnrichRunSummary
+		if err := json.Unmarshal([]byte(stdout), &summary); err != nil {
+			t.Fatalf("failed to decode bootstrap summary: %v\n%s", err, stdout)
+		}
+		records, err := enrich.LoadCache(filepath.Join(root, output.ContextDir, enrich.OutputFile))
+		if err != nil {
+			t.Fatalf("failed to load enrich cache: %v", err)
+		}
+		if summary.Symbols != 1 || summary.Succeeded != 1 || len(records) != 1 {
+			t.Fatalf("expected --limit 1 to bootstrap one symbol, got %#v", summary)
+		}
+		for _, record := range records {
+			if !strings.Contains(record.SymbolID, "|Middle|") {
+				t.Fatalf("expected the most central symbol first, got %s", record.SymbolID)
+			}
+		}
+	})
+}
+
+func TestEnrichEmbedAndSemanticSearchRankByMeaning(t *testing.T) {
+	// The fake provider embeds text as counts of three topic words, so the
+	// query shares a direction with the welcome email symbol only.
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		var body struct {
+			Model string   `json:"model"`
+			Input []string `json:"input"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Model != "fake-embed" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		type item struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		}
+		data := make([]item, 0, len(body.Input))
+		for i, text := range body.Input {
+			text = strings.ToLower(text)
+			data = append(data, item{Index: i, Embedding: []float32{
+				float32(strings.Count(text, "invoice")) + 0.1,
+				float32(strings.Count(text, "mail")) + 0.1,
+				float32(strings.Count(text, "user")) + 0.1,
+			}})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"data": data})
+	}))
+	defer server.Close()
+
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "billing", "invoice.go"), `package billing
+
+// Charge bills the customer for an invoice.
+func Charge(id string) error { return nil }
+
+func Refund(id string) error { return nil }
+`)
+	mustWriteFile(t, filepath.Join(root, "users", "notify.go"), `package users
+
+// Welcome sends the welcome email to a new user.
+func Welcome(address string) error { return nil }
+`)
+
+	withWorkingDir(t, root, func() {
+		if err := RunGenerate(newGenerateCmdForTest(), []string{"."}); err != nil {
+			t.Fatalf("RunGenerate failed: %v", err)
+		}
+
+		runEmbed := func() EmbedRunSummary {
+			embedCmd := newEnrichEmbedCmdForTest()
+			mustSetFlag(t, embedCmd, "embed-endpoint", server.URL)
+			mustSetFlag(t, embedCmd, "embed-model", "fake-embed")
+			mustSetFlag(t, embedCmd, "json", "true")
+			stdout := captureStdout(t, func() {
+				if err := RunEnrichEmbed(embedCmd, nil); err != nil {
+					t.Fatalf("RunEnrichEmbed failed: %v", err)
+				}
+			})
+			var summary EmbedRunSummary
+			if err := json.Unmarshal([]byte(stdout), &summary); err != nil {
+				t.Fatalf("failed to decode embed output: %v\noutput=%s", err, stdout)
+			}
+			return summary
+		}
+		first := runEmbed()
+		if first.Symbols != 3 || first.Embedded != 3 || first.Dimensions != 3 {
+			t.Fatalf("expected three symbols embedded, got %+v", first)
+		}
+		if second := runEmbed(); second.Embedded != 0 || second.Reused != 3 {
+			t.Fatalf("expected unchanged symbols to be reused, got %+v", second)
+		}
+		if requests != 1 {
+			t.Fatalf("expected one embeddings request across both runs, got %d", requests)
+		}
+
+		searchCmd := newSearchCmdForTest()
+		mustSetFlag(t, searchCmd, "semantic", "email for the new user")
+		mustSetFlag(t, searchCmd, "embed-endpoint", server.URL)
+		mustSetFlag(t, searchCmd, "embed-model", "fake-embed")
+		mustSetFlag(t, searchCmd, "json", "true")
+		var payload struct {
+			Total   int                 `json:"total"`
+			Matches []nav.SemanticMatch `json:"matches"`
+		}
+		stdout := captureStdout(t, func() {
+			if err := nav.RunSearch(searchCmd, nil); err != nil {
+				t.Fatalf("RunSearch failed: %v", err)
+			}
+		})
+		if err := json.Unmarshal([]byte(stdout), &payload); err != nil {
+			t.Fatalf("failed to decode search output: %v\noutput=%s", err, stdout)
+		}
+		if payload.Total != 3 || payload.Matches[0].Name != "Welcome" || payload.Matches[0].Score <= payload.Matches[1].Score {
+			t.Fatalf("expected Welcome to rank first, got %#v", payload.Matches)
+		}
+
+		searchCmd = newSearchCmdForTest()
+		mustSetFlag(t, searchCmd, "semantic", "email")
+		mustSetFlag(t, searchCmd, "embed-endpoint", server.URL)
+		mustSetFlag(t, searchCmd, "embed-model", "other-model")
+		if err := nav.RunSearch(searchCmd, nil); err == nil || !strings.Contains(err.Error(), "other-model") {
+			t.Fatalf("expected a model mismatch error, got %v", err)
+		}
+	})
+}
+
+func TestOwnersGroupsImpactedFilesByCodeowners(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, ".github", "CODEOWNERS"), `*          @acme/core
+/billing/  @acme/billing
+`)
+	mustWriteFile(t, filepath.Join(root, "billing", "invoice.py"), `def total(amount):
+    return amount * 2
+`)
+	mustWriteFile(t, filepath.Join(root, "api", "handlers.py"), `from billing.invoice import total
+
+
+def create_invoice(amount):
+    return total(amount)
+`)
+	mustWriteFile(t, filepath.Join(root, "scripts", "seed.py"), `def seed():
+    return 1
+`)
+
+	withWorkingDir(t, root, func() {
+		generateCmd := newGenerateCmdForTest()
+		mustSetFlag(t, generateCmd, "format", "jsonl")
+		if err := RunGenerate(generateCmd, []string{"."}); err != nil {
+			t.Fatalf("RunGenerate failed: %v", err)
+		}
+
+		var manifest struct {
+			Codeowners string `json:"codeowners"`
+			Owners     []struct {
+				Owner   string `json:"owner"`
+				Files   int    `json:"files"`
+				Symbols int    `json:"symbols"`
+			} `json:"owners"`
+		}
+		if err := json.Unmarshal([]byte(mustReadFile(t, filepath.Join(root, output.ContextDir, "manifest.json"))), &manifest); err != nil {
+			t.Fatalf("failed to decode manifest: %v", err)
+		}
+		if manifest.Codeowners != ".github/CODEOWNERS" || len(manifest.Owners) != 2 ||
+			manifest.Owners[0].Owner != "@acme/billing" || manifest.Owners[0].Files != 1 || manifest.Owners[1].Files != 2 {
+			t.Fatalf("unexpected manifest owners: %+v", manifest)
+		}
+		if symbols := mustReadFile(t, filepath.Join(root, output.ContextDir, "symbols.jsonl")); !strings.Contains(symbols, `"name":"total"`) || !strings.Contains(symbols, `"owners":["@acme/billing"]`) {
+			t.Fatalf("expected symbols to carry their owners, got:\n%s", symbols)
+		}
+
+		ownersCmd := newOwnersCmdForTest()
+		mustSetFlag(t, ownersCmd, "json", "true")
+		var payload struct {
+			Targets []ownerTarget  `json:"targets"`
+			Groups  []owners.Group `json:"groups"`
+		}
+		stdout := captureStdout(t, func() {
+			if err := RunOwners(ownersCmd, []string{"total"}); err != nil {
+				t.Fatalf("RunOwners failed: %v", err)
+			}
+		})
+		if err := json.Unmarshal([]byte(stdout), &payload); err != nil {
+			t.Fatalf("failed to decode owners output: %v\noutput=%s", err, stdout)
+		}
+		if len(payload.Targets) != 1 || payload.Targets[0].File != "billing/invoice.py" || strings.Join(payload.Targets[0].Owners, ",") != "@acme/billing" {
+			t.Fatalf("expected total to resolve to the billing team, got %+v", payload.Targets)
+		}
+		want := []owners.Group{
+			{Owner: "@acme/billing", Files: []string{"billing/invoice.py"}},
+			{Owner: "@acme/core", Files: []string{"api/handlers.py"}},
+		}
+		if !reflect.DeepEqual(payload.Groups, want) {
+			t.Fatalf("expected impacted files grouped by owner %+v, got %+v", want, payload.Groups)
+		}
+
+		mustWriteFile(t, filepath.Join(root, "scripts", "seed.py"), `def seed():
+    return 2
+`)
+		mustSetFlag(t, ownersCmd, "json", "false")
+		stdout = captureStdout(t, func() {
+			if err := RunOwners(ownersCmd, nil); err != nil {
+				t.Fatalf("RunOwners failed: %v", err)
+			}
+		})
+		if !strings.Contains(stdout, "@acme/core (1)\n  - scripts/seed.py\n") || strings.Contains(stdout, "billing") {
+			t.Fatalf("expected pending changes grouped by owner, got:\n%s", stdout)
+		}
+	})
+}
+
+func TestGenerateTagsAndSkipsGeneratedAndBuildIgnoredFiles(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "app.go"), `//go:build linux
+
+package app
+
+func Main() {}
+`)
+	mustWriteFile(t, filepath.Join(root, "api.pb.go"), `// Code generated by protoc-gen-go. DO NOT EDIT.
+
+package app
+
+func (x *Request) GetName() string { return "" }
+`)
+	mustWriteFile(t, filepath.Join(root, "gen.go"), `//go:build ignore
+
+package main
+
+func main() {}
+`)
+
+	withWorkingDir(t, root, func() {
+		if err := RunGenerate(newGenerateCmdForTest(), []string{"."}); err != nil {
+			t.Fatalf("RunGenerate failed: %v", err)
+		}
+		st, err := state.Load(filepath.Join(root, output.ContextDir))
+		if err != nil {
+			t.Fatalf("state.Load failed: %v", err)
+		}
+		if !st.Files["api.pb.go"].Generated || len(st.Files["api.pb.go"].Symbols) == 0 {
+			t.Fatalf("expected generated file to be tagged and indexed by default, got %#v", st.Files["api.pb.go"])
+		}
+		if st.Files["app.go"].BuildConstraint != "linux" || st.Files["gen.go"].BuildConstraint != "ignore" {
+			t.Fatalf("expected build constraints to be recorded, got %#v", st.Files)
+		}
+		modules := mustReadFile(t, filepath.Join(root, output.ContextDir, output.ModulesDir, "root.txt"))
+		if !strings.Contains(modules, "## api.pb.go\ngenerated: true\n") || !strings.Contains(modules, "## app.go\nbuild: linux\n") {
+			t.Fatalf("expected file tags in module file, got:\n%s", modules)
+		}
+
+		mustWriteFile(t, filepath.Join(root, ".skelly", "config.yaml"), "skip_generated: true\nskip_build_ignored: true\n")
+		if err := RunGenerate(newGenerateCmdForTest(), []string{"."}); err != nil {
+			t.Fatalf("RunGenerate with skip config failed: %v", err)
+		}
+		st, err = state.Load(filepath.Join(root, output.ContextDir))
+		if err != nil {
+			t.Fatalf("state.Load failed: %v", err)
+		}
+		for _, file := range []string{"api.pb.go", "gen.go"} {
+			fileState, ok := st.Files[file]
+			if !ok || len(fileState.Symbols) != 0 {
+				t.Fatalf("expected %s to stay tracked without symbols, got %#v", file, fileState)
+			}
+		}
+		if len(st.Files["app.go"].Symbols) != 1 {
+			t.Fatalf("expected app.go symbols to be kept, got %#v", st.Files["app.go"])
+		}
+
+		summary, err := UpdateContext(root, output.FormatText, output.OrderImportance, true)
+		if err != nil {
+			t.Fatalf("UpdateContext failed: %v", err)
+		}
+		if summary.Parsed != 0 {
+			t.Fatalf("expected update not to reparse skipped files, got %#v", summary)
+		}
+
+		mustWriteFile(t, filepath.Join(root, "api.pb.go"), `// Code generated by protoc-gen-go. DO NOT EDIT.
+
+package app
+
+func (x *Request) GetID() string { return "" }
+`)
+		if _, err := UpdateContext(root, output.FormatText, output.OrderImportance, true); err != nil {
+			t.Fatalf("UpdateContext failed: %v", err)
+		}
+		st, err = state.Load(filepath.Join(root, output.ContextDir))
+		if err != nil {
+			t.Fatalf("state.Load failed: %v", err)
+		}
+		if fileState := st.Files["api.pb.go"]; !fileState.Generated || len(fileState.Symbols) != 0 {
+			t.Fatalf("expected update to skip the edited generated file, got %#v", fileState)
+		}
+	})
+}
+
+func TestGenerateHonorsPerLanguageParserFeatures(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "app.go"), `package app
+
+// Run starts the app.
+func Run(cfg Config) { start() }
+
+func start() {}
+
+type Config struct{}
+`)
+	mustWriteFile(t, filepath.Join(root, "vendor.js"), `function load(path) { return fetch(path) }
+
+function fetch(path) { return path }
+
+module.exports = { load, fetch }
+`)
+	mustWriteFile(t, filepath.Join(root, "tasks.py"), `def sync():
+    """Sync pulls the latest tasks."""
+    return pull()
+
+
+def pull():
+    return []
+`)
+	mustWriteFile(t, filepath.Join(root, ".skelly", "config.yaml"), "parsers:\n  js:\n    calls: false\n  python:\n    docs: false\n")
+
+	withWorkingDir(t, root, func() {
+		if err := RunGenerate(newGenerateCmdForTest(), []string{"."}); err != nil {
+			t.Fatalf("RunGenerate failed: %v", err)
+		}
+		st, err := state.Load(filepath.Join(root, output.ContextDir))
+		if err != nil {
+			t.Fatalf("state.Load failed: %v", err)
+		}
+		for _, symbol := range st.Files["vendor.js"].Symbols {
+			if len(symbol.Calls) != 0 {
+				t.Fatalf("expected javascript calls to be skipped, got %#v", symbol)
+			}
+		}
+		for _, symbol := range st.Files["tasks.py"].Symbols {
+			if symbol.Doc != "" || (symbol.Name == "sync" && len(symbol.Calls) != 1) {
+				t.Fatalf("expected only python docs to be skipped, got %#v", symbol)
+			}
+		}
+		for _, symbol := range st.Files["app.go"].Symbols {
+			if symbol.Name == "Run" && (len(symbol.Calls) != 1 || symbol.Doc == "" || len(symbol.References) == 0) {
+				t.Fatalf("expected go extractions to be kept, got %#v", symbol)
+			}
+		}
+
+		// The parsers skip the extractions themselves; nothing is cleared afterwards.
+		cfg, err := config.Load(root)
+		if err != nil {
+			t.Fatalf("config.Load failed: %v", err)
+		}
+		parsed, err := NewRegistry(cfg).ParseFile(filepath.Join(root, "vendor.js"))
+		if err != nil {
+			t.Fatalf("ParseFile failed: %v", err)
+		}
+		for _, symbol := range parsed.Symbols {
+			if len(symbol.Calls) != 0 {
+				t.Fatalf("expected the javascript parser to skip calls, got %#v", symbol)
+			}
+		}
+	})
+}
+
+func TestGenerateSkipsOversizedAndBinaryFiles(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "app.go"), "package app\n\nfunc Run() {}\n")
+	mustWriteFile(t, filepath.Join(root, "web", "bundle.min.js"), "function a(){}"+strings.Repeat(";", 256)+"\n")
+	mustWriteFile(t, filepath.Join(root, "blob.py"), "x = 1\x00\x00\n")
+	mustWriteFile(t, filepath.Join(root, ".skelly", "config.yaml"), "max_file_bytes: 200\n")
+
+	withWorkingDir(t, root, func() {
+		cmd := newGenerateCmdForTest()
+		mustSetFlag(t, cmd, "json", "true")
+		out := captureStdout(t, func() {
+			if err := RunGenerate(cmd, []string{"."}); err != nil {
+				t.Fatalf("RunGenerate failed: %v", err)
+			}
+		})
+		var summary RunSummary
+		if err := json.Unmarshal([]byte(out), &summary); err != nil {
+			t.Fatalf("failed to decode summary: %v\n%s", err, out)
+		}
+		if summary.Skipped != 2 || !reflect.DeepEqual(summary.SkippedFiles, []string{"blob.py", "web/bundle.min.js"}) {
+			t.Fatalf("expected the bundle and the binary file to be skipped, got %+v", summary)
+		}
+
+		issues, err := output.LoadIssues(root)
+		if err != nil {
+			t.Fatalf("LoadIssues failed: %v", err)
+		}
+		if len(issues) != 2 || issues[0].Reason != parser.SkipBinary || issues[0].Language != "python" ||
+			issues[1].Reason != parser.SkipTooLarge || issues[1].Language != "javascript" || issues[1].Severity != "warning" {
+			t.Fatalf("expected skip warnings with reasons, got %#v", issues)
+		}
+
+		report, err := BuildLanguageReport(root, 0)
+		if err != nil {
+			t.Fatalf("BuildLanguageReport failed: %v", err)
+		}
+		reasons := make(map[string][]string)
+		for _, skipped := range report.Skipped {
+			reasons[skipped.Reason] = skipped.Files
+		}
+		if !reflect.DeepEqual(reasons[SkipTooLarge], []string{"web/bundle.min.js"}) || !reflect.DeepEqual(reasons[SkipBinary], []string{"blob.py"}) || report.Indexed != 1 {
+			t.Fatalf("expected the languages report to name the skip reasons, got %#v", report)
+		}
+	})
+}
+
+func TestVerboseLoggingTimesEachPhase(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "app.go"), "package app\n\nfunc Run() {}\n")
+
+	var logs bytes.Buffer
+	previous := logger
+	logger = newLogger(&logs, slog.LevelDebug)
+	defer func() { logger = previous }()
+
+	withWorkingDir(t, root, func() {
+		captureStdout(t, func() {
+			if err := RunGenerate(newGenerateCmdForTest(), []string{"."}); err != nil {
+				t.Fatalf("RunGenerate failed: %v", err)
+			}
+		})
+		for _, want := range []string{"run=generate phase=scan", "phase=parse", "phase=graph", "phase=write", "msg=\"run complete\" run=generate"} {
+			if !strings.Contains(logs.String(), want) {
+				t.Fatalf("expected %q in generate logs, got:\n%s", want, logs.String())
+			}
+		}
+
+		logs.Reset()
+		mustWriteFile(t, filepath.Join(root, "app.go"), "package app\n\nfunc Run() {}\n\nfunc Stop() {}\n")
+		if _, err := UpdateContext(root, output.FormatText, output.OrderImportance, true); err != nil {
+			t.Fatalf("UpdateContext failed: %v", err)
+		}
+		for _, want := range []string{"run=update phase=scan", "phase=parse", "phase=graph", "phase=write", "msg=\"run complete\" run=update"} {
+			if !strings.Contains(logs.String(), want) {
+				t.Fatalf("expected %q in update logs, got:\n%s", want, logs.String())
+			}
+		}
+	})
+
+	if _, err := ParseLogLevel("loud"); err == nil {
+		t.Fatalf("expected an unknown log level to be rejected")
+	}
+	if level, err := ParseLogLevel("INFO"); err != nil || level != slog.LevelInfo {
+		t.Fatalf("expected INFO to parse as info, got %v (%v)", level, err)
+	}
+}
+
+func TestMetricsAreWrittenWhenConfigured(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "app.go"), "package app\n\nfunc Run() {}\n")
+	mustWriteFile(t, filepath.Join(root, "util.go"), "package app\n\nfunc Help() {}\n")
+
+	readMetrics := func() RunMetrics {
+		t.Helper()
+		data, err := os.ReadFile(filepath.Join(root, ".skelly", "metrics.json"))
+		if err != nil {
+			t.Fatalf("failed to read metrics: %v", err)
+		}
+		var metrics RunMetrics
+		if err := json.Unmarshal(data, &metrics); err != nil {
+			t.Fatalf("failed to decode metrics: %v", err)
+		}
+		return metrics
+	}
+
+	withWorkingDir(t, root, func() {
+		captureStdout(t, func() {
+			if err := RunGenerate(newGenerateCmdForTest(), []string{"."}); err != nil {
+				t.Fatalf("RunGenerate failed: %v", err)
+			}
+		})
+		if _, err := os.Stat(filepath.Join(root, ".skelly", "metrics.json")); !os.IsNotExist(err) {
+			t.Fatalf("expected no metrics without the config key, got %v", err)
+		}
+
+		mustWriteFile(t, filepath.Join(root, ".skelly", "config.yaml"), "metrics: true\n")
+		captureStdout(t, func() {
+			if err := RunGenerate(newGenerateCmdForTest(), []string{"."}); err != nil {
+				t.Fatalf("RunGenerate failed: %v", err)
+			}
+		})
+		metrics := readMetrics()
+		for _, phase := range []string{"scan", "parse", "graph", "write"} {
+			if _, ok := metrics.PhasesMS[phase]; !ok {
+				t.Fatalf("expected a %s phase in generate metrics, got %#v", phase, metrics.PhasesMS)
+			}
+		}
+		if metrics.Run != "generate" || metrics.Files.Parsed != 2 || metrics.Cache["parse"].HitRate != 0 || metrics.Memory.PeakHeapBytes == 0 {
+			t.Fatalf("unexpected generate metrics: %#v", metrics)
+		}
+
+		mustWriteFile(t, filepath.Join(root, "app.go"), "package app\n\nfunc Run() {}\n\nfunc Stop() {}\n")
+		if _, err := UpdateContext(root, output.FormatText, output.OrderImportance, true); err != nil {
+			t.Fatalf("UpdateContext failed: %v", err)
+		}
+		metrics = readMetrics()
+		parse := metrics.Cache["parse"]
+		if metrics.Run != "update" || metrics.Files.Changed != 1 || parse.Hits != 1 || parse.Misses != 1 || parse.HitRate != 0.5 {
+			t.Fatalf("unexpected update metrics: %#v", metrics)
+		}
+		if outputs := metrics.Cache["outputs"]; outputs.Hits+outputs.Misses == 0 {
+			t.Fatalf("expected output cache counts, got %#v", metrics.Cache)
+		}
+	})
+}
+
+func TestPhaseTimerKeepsThePeakLiveHeap(t *testing.T) {
+	const size = 64 << 20
+	timer := newPhaseTimer("test")
+	timer.sampleHeap = true
+	end := timer.track("alloc")
+	buf := make([]byte, size)
+	end()
+	runtime.KeepAlive(buf)
+
+	runtime.GC()
+	timer.track("idle")()
+	if timer.peakHeap < size {
+		t.Fatalf("expected the peak heap to cover the %d byte buffer, got %d", size, timer.peakHeap)
+	}
+}
+
+func TestUpdateJSONLTracksArtifactHashesIncrementally(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "demo.go"), `package demo
+
+func A() {
+	B()
+}
+
+func B() {}
+`)
+
+	withWorkingDir(t, root, func() {
+		if err := RunInit(&cobra.Command{}, nil); err != nil {
+			t.Fatalf("RunInit failed: %v", err)
+		}
+
+		genCmd := newGenerateCmdForTest()
+		mustSetFlag(t, genCmd, "format", "jsonl")
+		if err := RunGenerate(genCmd, []string{"."}); err != nil {
+			t.Fatalf("RunGenerate failed: %v", err)
+		}
+
+		contextDir := filepath.Join(root, output.ContextDir)
+		stBefore, err := state.Load(contextDir)
+		if err != nil {
+			t.Fatalf("failed to load state after generate: %v", err)
+		}
+		hashesBefore := cloneStringMap(stBefore.OutputHashes)
+
+		updateCmd := newUpdateCmdForTest()
+		mustSetFlag(t, updateCmd, "format", "jsonl")
+		if err := RunUpdate(updateCmd, nil); err != nil {
+			t.Fatalf("RunUpdate failed: %v", err)
+		}
+
+		stUnchanged, err := state.Load(contextDir)
+		if err != nil {
+			t.Fatalf("failed to load state after unchanged update: %v", err)
+		}
+		if !reflect.DeepEqual(hashesBefore, stUnchanged.OutputHashes) {
+			t.Fatalf("expected output hashes to remain stable on unchanged update")
+		}
+
+		mustWriteFile(t, filepath.Join(root, "demo.go"), `package demo
+
+func A() {
+	B()
+	C()
+}
+
+func B() {}
+func C() {}
+`)
+
+		if err := RunUpdate(updateCmd, nil); err != nil {
+			t.Fatalf("RunUpdate after changes failed: %v", err)
+		}
+
+		stAfter, err := state.Load(contextDir)
+		if err != nil {
+			t.Fatalf("failed to load state after changed update: %v", err)
+		}
+
+		if stAfter.OutputHashes["symbols.jsonl"] == hashesBefore["symbols.jsonl"] {
+			t.Fatalf("expected symbols.jsonl hash to change after source updates")
+		}
+		if stAfter.OutputHashes["edges.jsonl"] == hashesBefore["edges.jsonl"] {
+			t.Fatalf("expected edges.jsonl hash to change after source updates")
+		}
+		if stAfter.OutputHashes["manifest.json"] == hashesBefore["manifest.json"] {
+			t.Fatalf("expected manifest.json hash to change after source updates")
+		}
+	})
+}
+
+func TestUpdateCanSwitchToJSONLWithoutSourceChanges(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "demo.go"), `package demo
+
+func A() {}
+`)
+
+	withWorkingDir(t, root, func() {
+		if err := RunInit(&cobra.Command{}, nil); err != nil {
+			t.Fatalf("RunInit failed: %v", err)
+		}
+		if err := RunGenerate(newGenerateCmdForTest(), []string{"."}); err != nil {
+			t.Fatalf("RunGenerate failed: %v", err)
+		}
+
+		contextDir := filepath.Join(root, output.ContextDir)
+		assertExists(t, filepath.Join(contextDir, "index.txt"))
+		assertNotExists(t, filepath.Join(contextDir, "symbols.jsonl"))
+
+		updateCmd := newUpdateCmdForTest()
+		mustSetFlag(t, updateCmd, "format", "jsonl")
+		if err := RunUpdate(updateCmd, nil); err != nil {
+			t.Fatalf("RunUpdate failed: %v", err)
+		}
+
+		assertExists(t, filepath.Join(contextDir, "symbols.jsonl"))
+		assertExists(t, filepath.Join(contextDir, "edges.jsonl"))
+		assertExists(t, filepath.Join(contextDir, "manifest.json"))
+		assertNotExists(t, filepath.Join(contextDir, "index.txt"))
+		assertNotExists(t, filepath.Join(contextDir, "graph.txt"))
+	})
+}
+
+func TestUpdateRefreshesMissingTextModulesOnNoop(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "demo.go"), `package demo
+
+func A() {}
+`)
+
+	withWorkingDir(t, root, func() {
+		if err := RunInit(newInitCmdForTest(), nil); err != nil {
+			t.Fatalf("RunInit failed: %v", err)
+		}
+
+		contextDir := filepath.Join(root, output.ContextDir)
+		modulePath := filepath.Join(contextDir, "modules", "root.txt")
+		assertExists(t, modulePath)
+
+		if err := os.Remove(modulePath); err != nil {
+			t.Fatalf("failed to remove module artifact: %v", err)
+		}
+		assertNotExists(t, modulePath)
+
+		if err := RunUpdate(newUpdateCmdForTest(), nil); err != nil {
+			t.Fatalf("RunUpdate failed: %v", err)
+		}
+
+		assertExists(t, modulePath)
+	})
+}
+
+func TestIndexOrderPutsImportantModulesFirst(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "aaa", "leaf.go"), `package aaa
+
+func Leaf() {}
+`)
+	mustWriteFile(t, filepath.Join(root, "zzz", "core.go"), `package zzz
+
+func Core() {}
+func A() { Core() }
+func B() { Core() }
+func C() { Core() }
+`)
+
+	withWorkingDir(t, root, func() {
+		if err := RunGenerate(newGenerateCmdForTest(), []string{"."}); err != nil {
+			t.Fatalf("RunGenerate failed: %v", err)
+		}
+
+		indexPath := filepath.Join(root, output.ContextDir, output.IndexFile)
+		index := mustReadFile(t, indexPath)
+		coreAt := strings.Index(index, "### zzz")
+		leafAt := strings.Index(index, "### aaa")
+		if coreAt < 0 || leafAt < 0 || coreAt > leafAt {
+			t.Fatalf("expected zzz module before aaa in importance order, got:\n%s", index)
+		}
+		if !strings.Contains(index, "key symbols: [Core,") {
+			t.Fatalf("expected Core to lead zzz key symbols, got:\n%s", index)
+		}
+
+		updateCmd := newUpdateCmdForTest()
+		mustSetFlag(t, updateCmd, "order", "path")
+		if err := RunUpdate(updateCmd, nil); err != nil {
+			t.Fatalf("RunUpdate failed: %v", err)
+		}
+		index = mustReadFile(t, indexPath)
+		if !strings.Contains(index, "## Files\n\n- aaa/leaf.go (1 symbols)\n- zzz/core.go (4 symbols)\n") {
+			t.Fatalf("expected path-ordered file list after update --order path, got:\n%s", index)
+		}
+	})
+}
+
+func TestUpdateForwardsMovedSymbolIDsUntilPruned(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "demo.go"), `package demo
+
+func Target() {}
+`)
+
+	withWorkingDir(t, root, func() {
+		if err := RunGenerate(newGenerateCmdForTest(), []string{"."}); err != nil {
+			t.Fatalf("RunGenerate failed: %v", err)
+		}
+		contextDir := filepath.Join(root, output.ContextDir)
+		before, err := state.Load(contextDir)
+		if err != nil {
+			t.Fatalf("state.Load failed: %v", err)
+		}
+		oldID := before.Files["demo.go"].Symbols[0].ID
+
+		// Shifting the symbol down keeps its stable ID.
+		mustWriteFile(t, filepath.Join(root, "demo.go"), `package demo
+
+func Helper() {}
+
+func Target() {}
+`)
+		if err := RunUpdate(newUpdateCmdForTest(), nil); err != nil {
+			t.Fatalf("RunUpdate failed: %v", err)
+		}
+		shifted, err := state.Load(contextDir)
+		if err != nil {
+			t.Fatalf("state.Load failed: %v", err)
+		}
+		if id := shifted.Files["demo.go"].Symbols[1].ID; id != oldID || len(shifted.Aliases) != 0 {
+			t.Fatalf("expected line shift to keep ID %q, got %q with aliases %#v", oldID, id, shifted.Aliases)
+		}
+
+		// Moving it to another file changes the ID.
+		mustWriteFile(t, filepath.Join(root, "demo.go"), `package demo
+
+func Helper() {}
+`)
+		mustWriteFile(t, filepath.Join(root, "target.go"), `package demo
+
+func Target() {}
+`)
+		if err := RunUpdate(newUpdateCmdForTest(), nil); err != nil {
+			t.Fatalf("RunUpdate after move failed: %v", err)
+		}
+
+		symbolCmd := newSymbolCmdForTest()
+		mustSetFlag(t, symbolCmd, "json", "true")
+		var payload struct {
+			Matches []nav.SymbolRecord `json:"matches"`
+		}
+		stdout := captureStdout(t, func() {
+			if err := nav.RunSymbol(symbolCmd, []string{oldID}); err != nil {
+				t.Fatalf("RunSymbol failed: %v", err)
+			}
+		})
+		if err := json.Unmarshal([]byte(stdout), &payload); err != nil {
+			t.Fatalf("failed to decode symbol output: %v\noutput=%s", err, stdout)
+		}
+		if len(payload.Matches) != 1 || payload.Matches[0].Name != "Target" || payload.Matches[0].File != "target.go" {
+			t.Fatalf("expected old ID to forward to Target in target.go, got %#v", payload.Matches)
+		}
+
+		pruneCmd := newAliasesPruneCmdForTest()
+		mustSetFlag(t, pruneCmd, "json", "true")
+		var summary AliasesPruneSummary
+		stdout = captureStdout(t, func() {
+			if err := RunAliasesPrune(pruneCmd, nil); err != nil {
+				t.Fatalf("RunAliasesPrune failed: %v", err)
+			}
+		})
+		if err := json.Unmarshal([]byte(stdout), &summary); err != nil {
+			t.Fatalf("failed to decode prune output: %v\noutput=%s", err, stdout)
+		}
+		if summary.Removed != 1 || summary.Remaining != 0 || summary.IDs[0] != oldID {
+			t.Fatalf("unexpected prune summary: %#v", summary)
+		}
+		if strings.Contains(mustReadFile(t, filepath.Join(contextDir, nav.NavigationIndexFile)), oldID) {
+			t.Fatalf("expected pruned alias to be removed from navigation index")
+		}
+	})
+}
+
+func TestUpdateMigratesLegacySymbolIDs(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "demo.go"), `package demo
+
+func Target() {}
+`)
+
+	withWorkingDir(t, root, func() {
+		if err := RunGenerate(newGenerateCmdForTest(), []string{"."}); err != nil {
+			t.Fatalf("RunGenerate failed: %v", err)
+		}
+		contextDir := filepath.Join(root, output.ContextDir)
+		st, err := state.Load(contextDir)
+		if err != nil {
+			t.Fatalf("state.Load failed: %v", err)
+		}
+		fileState := st.Files["demo.go"]
+		currentID := fileState.Symbols[0].ID
+		legacyID := "demo.go|3|func|Target"
+		fileState.Symbols[0].ID = legacyID
+		st.Files["demo.go"] = fileState
+		st.SymbolIDVersion = ""
+		if err := st.Save(contextDir); err != nil {
+			t.Fatalf("state.Save failed: %v", err)
+		}
+
+		if err := RunUpdate(newUpdateCmdForTest(), nil); err != nil {
+			t.Fatalf("RunUpdate failed: %v", err)
+		}
+		migrated, err := state.Load(contextDir)
+		if err != nil {
+			t.Fatalf("state.Load failed: %v", err)
+		}
+		if migrated.SymbolIDVersion != parser.SymbolIDVersion || migrated.Files["demo.go"].Symbols[0].ID != currentID {
+			t.Fatalf("expected state to be migrated to %q, got version %q", currentID, migrated.SymbolIDVersion)
+		}
+		if alias := migrated.Aliases[legacyID]; alias.Target != currentID || alias.Reason != state.AliasMigrated {
+			t.Fatalf("expected legacy ID to forward, got %#v", alias)
+		}
+		if !strings.Contains(mustReadFile(t, filepath.Join(contextDir, nav.NavigationIndexFile)), legacyID) {
+			t.Fatalf("expected navigation index to carry the legacy forward")
+		}
+	})
+}
+
+func TestWatchBatchesChangesIntoIncrementalUpdates(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "demo.go"), `package demo
+
+func A() {}
+`)
+
+	withWorkingDir(t, root, func() {
+		if err := RunGenerate(newGenerateCmdForTest(), []string{"."}); err != nil {
+			t.Fatalf("RunGenerate failed: %v", err)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		ready := make(chan struct{})
+		summaries := make(chan RunSummary, 4)
+		done := make(chan error, 1)
+		go func() {
+			done <- WatchContext(ctx, root, WatchOptions{
+				Format:   output.FormatText,
+				Order:    output.OrderImportance,
+				Debounce: 50 * time.Millisecond,
+				OnSummary: func(summary RunSummary) error {
+					summaries <- summary
+					return nil
+				},
+				Ready: ready,
+			})
+		}()
+
+		select {
+		case <-ready:
+		case err := <-done:
+			t.Fatalf("WatchContext exited early: %v", err)
+		case <-time.After(10 * time.Second):
+			t.Fatalf("timed out waiting for watcher to start")
+		}
+
+		mustWriteFile(t, filepath.Join(root, "demo.go"), `package demo
+
+func A() { B() }
+func B() {}
+`)
+		mustWriteFile(t, filepath.Join(root, "pkg", "extra.go"), `package pkg
+
+func C() {}
+`)
+
+		changed := make(map[string]bool)
+		deadline := time.After(10 * time.Second)
+		for !changed["demo.go"] || !changed["pkg/extra.go"] {
+			select {
+			case summary := <-summaries:
+				if summary.Mode != "watch" {
+					t.Fatalf("expected watch mode summary, got %#v", summary)
+				}
+				for _, file := range summary.ChangedFiles {
+					changed[file] = true
+				}
+			case <-deadline:
+				t.Fatalf("timed out waiting for watch batches, saw %v", changed)
+			}
+		}
+
+		cancel()
+		if err := <-done; err != nil {
+			t.Fatalf("WatchContext failed: %v", err)
+		}
+	})
+}
+
+func TestWatchWriteBehindDefersArtifactsUntilFlush(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "demo.go"), `package demo
+
+func A() {}
+`)
+
+	withWorkingDir(t, root, func() {
+		if err := RunGenerate(newGenerateCmdForTest(), []string{"."}); err != nil {
+			t.Fatalf("RunGenerate failed: %v", err)
+		}
+		contextDir := filepath.Join(root, output.ContextDir)
+		if err := RequestFlush(contextDir, time.Second); err == nil || !strings.Contains(err.Error(), "no write-behind watch") {
+			t.Fatalf("expected missing watcher error, got %v", err)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		ready := make(chan struct{})
+		summaries := make(chan RunSummary, 4)
+		done := make(chan error, 1)
+		go func() {
+			done <- WatchContext(ctx, root, WatchOptions{
+				Format:        output.FormatText,
+				Order:         output.OrderImportance,
+				Debounce:      50 * time.Millisecond,
+				WriteBehind:   true,
+				FlushInterval: time.Hour,
+				OnSummary: func(summary RunSummary) error {
+					summaries <- summary
+					return nil
+				},
+				Ready: ready,
+			})
+		}()
+
+		select {
+		case <-ready:
+		case err := <-done:
+			t.Fatalf("WatchContext exited early: %v", err)
+		case <-time.After(10 * time.Second):
+			t.Fatalf("timed out waiting for watcher to start")
+		}
+		assertExists(t, filepath.Join(contextDir, writeBehindMarkerFile))
+
+		mustWriteFile(t, filepath.Join(root, "demo.go"), `package demo
+
+func A() { Beta() }
+func Beta() {}
+`)
+
+		select {
+		case summary := <-summaries:
+			if summary.Mode != "watch" || summary.Rewritten != 0 {
+				t.Fatalf("expected in-memory watch batch, got %#v", summary)
+			}
+		case <-time.After(10 * time.Second):
+			t.Fatalf("timed out waiting for watch batch")
+		}
+		if strings.Contains(mustReadFile(t, filepath.Join(contextDir, nav.NavigationIndexFile)), "Beta") {
+			t.Fatalf("expected navigation index to stay stale until flush")
+		}
+
+		if err := RequestFlush(contextDir, 10*time.Second); err != nil {
+			t.Fatalf("RequestFlush failed: %v", err)
+		}
+		select {
+		case summary := <-summaries:
+			if summary.Mode != "flush" || summary.Rewritten == 0 || len(summary.ChangedFiles) != 1 || summary.ChangedFiles[0] != "demo.go" {
+				t.Fatalf("unexpected flush summary: %#v", summary)
+			}
+		case <-time.After(10 * time.Second):
+			t.Fatalf("timed out waiting for flush summary")
+		}
+		if !strings.Contains(mustReadFile(t, filepath.Join(contextDir, nav.NavigationIndexFile)), "Beta") {
+			t.Fatalf("expected flushed navigation index to include Beta")
+		}
+		assertNotExists(t, filepath.Join(contextDir, flushRequestFile))
+
+		cancel()
+		if err := <-done; err != nil {
+			t.Fatalf("WatchContext failed: %v", err)
+		}
+		assertNotExists(t, filepath.Join(contextDir, writeBehindMarkerFile))
+	})
+}
+
+func TestQueryFlushesPendingWriteBehindEdits(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "demo.go"), `package demo
+
+func A() {}
+`)
+
+	withWorkingDir(t, root, func() {
+		if err := RunGenerate(newGenerateCmdForTest(), []string{"."}); err != nil {
+			t.Fatalf("RunGenerate failed: %v", err)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		ready := make(chan struct{})
+		done := make(chan error, 1)
+		go func() {
+			done <- WatchContext(ctx, root, WatchOptions{
+				Format:        output.FormatText,
+				Order:         output.OrderImportance,
+				Debounce:      time.Hour,
+				WriteBehind:   true,
+				FlushInterval: time.Hour,
+				OnSummary:     func(RunSummary) error { return nil },
+				Ready:         ready,
+			})
+		}()
+		select {
+		case <-ready:
+		case err := <-done:
+			t.Fatalf("WatchContext exited early: %v", err)
+		case <-time.After(10 * time.Second):
+			t.Fatalf("timed out waiting for watcher to start")
+		}
+
+		// The edit is still debouncing in the watcher when the query runs.
+		mustWriteFile(t, filepath.Join(root, "demo.go"), `package demo
+
+func A() { Beta() }
+func Beta() {}
+`)
+		out := captureStdout(t, func() {
+			command := NewRootCommand("test")
+			command.SetArgs([]string{"callers", "Beta"})
+			if err := command.Execute(); err != nil {
+				t.Fatalf("callers failed: %v", err)
+			}
+		})
+		if !strings.Contains(out, "A") {
+			t.Fatalf("expected callers to see the unflushed edit, got:\n%s", out)
+		}
+
+		cancel()
+		if err := <-done; err != nil {
+			t.Fatalf("WatchContext failed: %v", err)
+		}
+	})
+}
+
+func TestUpdateExecHookReceivesImpactedFiles(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "demo.go"), `package demo
+
+func A() {}
+`)
+
+	withWorkingDir(t, root, func() {
+		if err := RunGenerate(newGenerateCmdForTest(), []string{"."}); err != nil {
+			t.Fatalf("RunGenerate failed: %v", err)
+		}
+
+		hookOutput := filepath.Join(root, "hook.out")
+		updateCmd := newUpdateCmdForTest()
+		mustSetFlag(t, updateCmd, "exec", "printf '%s\\n' {impacted} > "+hookOutput)
+
+		if err := RunUpdate(updateCmd, nil); err != nil {
+			t.Fatalf("RunUpdate failed: %v", err)
+		}
+		assertNotExists(t, hookOutput)
+
+		mustWriteFile(t, filepath.Join(root, "demo.go"), `package demo
+
+func A() { B() }
+func B() {}
+`)
+		if err := RunUpdate(updateCmd, nil); err != nil {
+			t.Fatalf("RunUpdate with changes failed: %v", err)
+		}
+		data, err := os.ReadFile(hookOutput)
+		if err != nil {
+			t.Fatalf("expected exec hook to run: %v", err)
+		}
+		if strings.TrimSpace(string(data)) != "demo.go" {
+			t.Fatalf("expected hook to receive impacted file demo.go, got %q", string(data))
+		}
+	})
+}
+
+func TestUpdateSinceTakesChangedFilesFromGit(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "a.go"), `package demo
+
+func A() {}
+`)
+	mustWriteFile(t, filepath.Join(root, "b.go"), `package demo
+
+func B() {}
+`)
+	mustGit(t, root, "init", "-q")
+
+	withWorkingDir(t, root, func() {
+		if err := RunGenerate(newGenerateCmdForTest(), []string{"."}); err != nil {
+			t.Fatalf("RunGenerate failed: %v", err)
+		}
+		mustGit(t, root, "add", "-A")
+		mustGit(t, root, "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "init")
+
+		mustWriteFile(t, filepath.Join(root, "a.go"), `package demo
+
+func A() { C() }
+`)
+		mustWriteFile(t, filepath.Join(root, "c.go"), `package demo
+
+func C() {}
+`)
+		summary, err := updateContext(root, output.FormatText, output.OrderImportance, true, false, "HEAD", nil)
+		if err != nil {
+			t.Fatalf("update --since failed: %v", err)
+		}
+		if summary.Scanned != 2 {
+			t.Fatalf("expected only the modified and untracked files to be hashed, got %d", summary.Scanned)
+		}
+		if !reflect.DeepEqual(summary.ChangedFiles, []string{"a.go", "c.go"}) {
+			t.Fatalf("expected a.go and c.go to change, got %v", summary.ChangedFiles)
+		}
+		if summary.Reused != 1 {
+			t.Fatalf("expected b.go to be reused from its recorded hash, got %d files", summary.Reused)
+		}
+
+		updateCmd := newUpdateCmdForTest()
+		mustSetFlag(t, updateCmd, "since", "no-such-rev")
+		err = RunUpdate(updateCmd, nil)
+		if err == nil || !strings.Contains(err.Error(), "unknown revision") {
+			t.Fatalf("expected unknown revision error, got %v", err)
+		}
+	})
+}
+
+func TestBinaryStateBackendSurvivesGenerateAndUpdate(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "a.go"), `package demo
+
+func A() { B() }
+`)
+	mustWriteFile(t, filepath.Join(root, "b.go"), `package demo
+
+func B() {}
+`)
+
+	withWorkingDir(t, root, func() {
+		contextDir := filepath.Join(root, output.ContextDir)
+		genCmd := newGenerateCmdForTest()
+		mustSetFlag(t, genCmd, "state-backend", "binary")
+		captureStdout(t, func() {
+			if err := RunGenerate(genCmd, []string{"."}); err != nil {
+				t.Fatalf("RunGenerate failed: %v", err)
+			}
+		})
+		assertExists(t, filepath.Join(contextDir, state.BinaryStateFile))
+		assertNotExists(t, filepath.Join(contextDir, state.StateFile))
+
+		// Update keeps the binary store and reparses only the edited file.
+		mustWriteFile(t, filepath.Join(root, "b.go"), `package demo
+
+func B() { C() }
+func C() {}
+`)
+		summary, err := UpdateContext(root, output.FormatText, output.OrderImportance, true)
+		if err != nil {
+			t.Fatalf("UpdateContext failed: %v", err)
+		}
+		if summary.Parsed != 1 || summary.Reused != 1 {
+			t.Fatalf("expected incremental update over binary state, got %#v", summary)
+		}
+		assertNotExists(t, filepath.Join(contextDir, state.StateFile))
+		st, err := state.Load(contextDir)
+		if err != nil {
+			t.Fatalf("failed to load binary state: %v", err)
+		}
+		if st.Backend != state.BackendBinary || len(st.Files["b.go"].Symbols) != 2 {
+			t.Fatalf("unexpected state after update: backend=%q b.go=%#v", st.Backend, st.Files["b.go"])
+		}
+
+		migrateCmd := &cobra.Command{}
+		migrateCmd.Flags().String("to", "json", "")
+		migrateCmd.Flags().Bool("json", false, "")
+		captureStdout(t, func() {
+			if err := RunStateMigrate(migrateCmd, nil); err != nil {
+				t.Fatalf("RunStateMigrate failed: %v", err)
+			}
+		})
+		assertExists(t, filepath.Join(contextDir, state.StateFile))
+		assertNotExists(t, filepath.Join(contextDir, state.BinaryStateFile))
+	})
+}
+
+func TestCalibrationReportsResolutionTrendsBetweenRuns(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "demo.go"), `package demo
+
+func A() {
+	B()
+	Missing()
+}
+
+func B() {}
+`)
+
+	withWorkingDir(t, root, func() {
+		if _, err := generateContext(root, nil, output.FormatText, output.OrderImportance, true, 0, true); err != nil {
+			t.Fatalf("generateContext failed: %v", err)
+		}
+		mustWriteFile(t, filepath.Join(root, "demo.go"), `package demo
+
+func A() {
+	B()
+	Missing()
+}
+
+func B() {}
+func Missing() {}
+`)
+		if _, err := UpdateContext(root, output.FormatText, output.OrderImportance, true); err != nil {
+			t.Fatalf("UpdateContext failed: %v", err)
+		}
+
+		st, err := state.Load(filepath.Join(root, output.ContextDir))
+		if err != nil {
+			t.Fatalf("failed to load state: %v", err)
+		}
+		if len(st.Calibration) != 2 {
+			t.Fatalf("expected a calibration run per distinct result, got %#v", st.Calibration)
+		}
+
+		cmd := &cobra.Command{}
+		cmd.Flags().Int("samples", 5, "")
+		cmd.Flags().Bool("json", false, "")
+		mustSetFlag(t, cmd, "json", "true")
+		out := captureStdout(t, func() {
+			if err := RunCalibration(cmd, nil); err != nil {
+				t.Fatalf("RunCalibration failed: %v", err)
+			}
+		})
+
+		var payload struct {
+			Languages []LanguageCalibration `json:"languages"`
+		}
+		if err := json.Unmarshal([]byte(out), &payload); err != nil {
+			t.Fatalf("failed to decode calibration output: %v\n%s", err, out)
+		}
+		if len(payload.Languages) != 1 || payload.Languages[0].Language != "go" {
+			t.Fatalf("expected a single go row, got %#v", payload.Languages)
+		}
+		row := payload.Languages[0]
+		if row.CallSites != 2 || row.Resolved != 1 || len(row.Samples) != 0 {
+			t.Fatalf("expected both calls resolved now, got %#v", row)
+		}
+		if row.Trend == nil || row.Trend.Unresolved != -0.5 || row.Trend.Resolved != 0.5 {
+			t.Fatalf("expected trend against the previous run, got %#v", row.Trend)
+		}
+	})
+}
+
+func TestHookVerifyReportsMismatchedStagedArtifacts(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "demo.go"), `package demo
+
+func A() {}
+`)
+	mustGit(t, root, "init", "-q")
+
+	withWorkingDir(t, root, func() {
+		summary, err := VerifyStagedContext(root)
+		if err != nil {
+			t.Fatalf("VerifyStagedContext failed: %v", err)
+		}
+		if !summary.OK || summary.Tracked {
+			t.Fatalf("expected untracked context to pass, got %#v", summary)
+		}
+
+		if _, err := generateContext(root, nil, output.FormatText, output.OrderImportance, true, 0, true); err != nil {
+			t.Fatalf("generateContext failed: %v", err)
+		}
+		mustGit(t, root, "add", "-A")
+		mustGit(t, root, "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "init")
+
+		// Source staged, context regenerated but left unstaged.
+		mustWriteFile(t, filepath.Join(root, "demo.go"), `package demo
+
+func A() { B() }
+func B() {}
+`)
+		mustGit(t, root, "add", "demo.go")
+		if _, err := UpdateContext(root, output.FormatText, output.OrderImportance, true); err != nil {
+			t.Fatalf("UpdateContext failed: %v", err)
+		}
+		summary, err = VerifyStagedContext(root)
+		if err != nil {
+			t.Fatalf("VerifyStagedContext failed: %v", err)
+		}
+		if summary.OK || len(summary.UnstagedArtifacts) == 0 || len(summary.StaleSources) != 0 {
+			t.Fatalf("expected unstaged artifacts to be reported, got %#v", summary)
+		}
+
+		mustGit(t, root, "add", output.ContextDir)
+		summary, err = VerifyStagedContext(root)
+		if err != nil {
+			t.Fatalf("VerifyStagedContext failed: %v", err)
+		}
+		if !summary.OK {
+			t.Fatalf("expected staged source and context to pass, got %#v", summary)
+		}
+
+		// Source staged but never indexed.
+		mustWriteFile(t, filepath.Join(root, "demo.go"), `package demo
+
+func A() { C() }
+func C() {}
+`)
+		mustGit(t, root, "add", "demo.go")
+		summary, err = VerifyStagedContext(root)
+		if err != nil {
+			t.Fatalf("VerifyStagedContext failed: %v", err)
+		}
+		if summary.OK || !reflect.DeepEqual(summary.StaleSources, []string{"demo.go"}) {
+			t.Fatalf("expected demo.go to be reported stale, got %#v", summary)
+		}
+
+		// Context staged while the source it describes is left unstaged.
+		mustGit(t, root, "reset", "-q", "demo.go")
+		if _, err := UpdateContext(root, output.FormatText, output.OrderImportance, true); err != nil {
+			t.Fatalf("UpdateContext failed: %v", err)
+		}
+		mustGit(t, root, "add", output.ContextDir)
+		summary, err = VerifyStagedContext(root)
+		if err != nil {
+			t.Fatalf("VerifyStagedContext failed: %v", err)
+		}
+		if summary.OK || !reflect.DeepEqual(summary.UnstagedSources, []string{"demo.go"}) {
+			t.Fatalf("expected unstaged demo.go to be reported, got %#v", summary)
+		}
+	})
+}
+
+func TestStageContextArtifactsGuardsFormat(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "demo.go"), `package demo
+
+func A() {}
+`)
+	mustGit(t, root, "init", "-q")
+
+	withWorkingDir(t, root, func() {
+		if _, err := generateContext(root, nil, output.FormatText, output.OrderImportance, true, 0, true); err != nil {
+			t.Fatalf("generateContext failed: %v", err)
+		}
+		mustGit(t, root, "add", "-A")
+		mustGit(t, root, "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "init")
+
+		// Nothing staged: the hook leaves the context alone.
+		mustWriteFile(t, filepath.Join(root, "demo.go"), `package demo
+
+func A() { B() }
+func B() {}
+`)
+		if _, err := UpdateContext(root, output.FormatText, output.OrderImportance, true); err != nil {
+			t.Fatalf("UpdateContext failed: %v", err)
+		}
+		added, err := StageContextArtifacts(root)
+		if err != nil || len(added) != 0 {
+			t.Fatalf("expected nothing to be staged without staged sources, got %v (%v)", added, err)
+		}
+
+		mustGit(t, root, "add", "demo.go")
+		added, err = StageContextArtifacts(root)
+		if err != nil {
+			t.Fatalf("StageContextArtifacts failed: %v", err)
+		}
+		if !containsString(added, filepath.ToSlash(filepath.Join(output.ContextDir, output.IndexFile))) {
+			t.Fatalf("expected index.txt to be staged, got %v", added)
+		}
+		summary, err := VerifyStagedContext(root)
+		if err != nil || !summary.OK {
+			t.Fatalf("expected staged artifacts to verify, got %#v (%v)", summary, err)
+		}
+		mustGit(t, root, "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "B")
+
+		// The committed context is text; a JSONL regenerate must not be staged.
+		mustWriteFile(t, filepath.Join(root, "demo.go"), `package demo
+
+func A() {}
+`)
+		mustGit(t, root, "add", "demo.go")
+		if _, err := UpdateContext(root, output.FormatJSONL, output.OrderImportance, true); err != nil {
+			t.Fatalf("UpdateContext failed: %v", err)
+		}
+		if _, err := StageContextArtifacts(root); err == nil || !strings.Contains(err.Error(), "committed context is text but the regenerated one is jsonl") {
+			t.Fatalf("expected a format mismatch error, got %v", err)
+		}
+		staged, err := gitLines(root, "diff", "--cached", "--name-only")
+		if err != nil || !reflect.DeepEqual(staged, []string{"demo.go"}) {
+			t.Fatalf("expected only demo.go to stay staged, got %v (%v)", staged, err)
+		}
+	})
+}
+
+func TestStageContextArtifactsSkipsDaemonRuntimeFiles(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "demo.go"), `package demo
+
+func A() {}
+`)
+	mustGit(t, root, "init", "-q")
+
+	withWorkingDir(t, root, func() {
+		if _, err := generateContext(root, nil, output.FormatText, output.OrderImportance, true, 0, true); err != nil {
+			t.Fatalf("generateContext failed: %v", err)
+		}
+		mustGit(t, root, "add", "-A")
+		mustGit(t, root, "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "init")
+
+		contextDir := filepath.Join(root, output.ContextDir)
+		server := daemon.NewServer(root, contextDir, func([]string) error { return nil }, func([]string) error { return nil })
+		done := make(chan error, 1)
+		go func() { done <- server.Serve(context.Background()) }()
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			if _, err := daemon.Call(contextDir, daemon.Request{Op: daemon.OpStatus}); err == nil {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("daemon did not start")
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		defer func() {
+			if _, err := daemon.Call(contextDir, daemon.Request{Op: daemon.OpStop}); err != nil {
+				t.Fatalf("stop failed: %v", err)
+			}
+			if err := <-done; err != nil {
+				t.Fatalf("Serve failed: %v", err)
+			}
+		}()
+		mustWriteFile(t, filepath.Join(contextDir, daemon.LogFile), "daemon started\n")
+		mustWriteFile(t, filepath.Join(contextDir, writeBehindMarkerFile), "123\n")
+		mustWriteFile(t, filepath.Join(contextDir, flushRequestFile), "")
+		mustWriteFile(t, filepath.Join(contextDir, ".index.txt.42.tmp"), "partial")
+
+		mustWriteFile(t, filepath.Join(root, "demo.go"), `package demo
+
+func A() { B() }
+func B() {}
+`)
+		if _, err := UpdateContext(root, output.FormatText, output.OrderImportance, true); err != nil {
+			t.Fatalf("UpdateContext failed: %v", err)
+		}
+		mustGit(t, root, "add", "demo.go")
+		added, err := StageContextArtifacts(root)
+		if err != nil {
+			t.Fatalf("StageContextArtifacts failed: %v", err)
+		}
+		if !containsString(added, filepath.ToSlash(filepath.Join(output.ContextDir, output.IndexFile))) {
+			t.Fatalf("expected index.txt to be staged, got %v", added)
+		}
+		for _, file := range added {
+			switch path.Base(file) {
+			case daemon.SocketFile, daemon.LogFile, writeBehindMarkerFile, flushRequestFile, ".index.txt.42.tmp":
+				t.Fatalf("expected runtime file %s not to be staged, got %v", file, added)
+			}
+		}
+	})
+}
+
+func TestQuickUpdateSkipsSearchIndexUntilFullUpdate(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "demo.go"), `package demo
+
+func A() {}
+`)
+
+	withWorkingDir(t, root, func() {
+		if _, err := generateContext(root, nil, output.FormatText, output.OrderImportance, true, 0, true); err != nil {
+			t.Fatalf("generateContext failed: %v", err)
+		}
+		contextDir := filepath.Join(root, output.ContextDir)
+		searchPath := filepath.Join(contextDir, search.IndexFile)
+		searchBefore := mustReadFile(t, searchPath)
+
+		mustWriteFile(t, filepath.Join(root, "demo.go"), `package demo
+
+func A() { QuickAdded() }
+func QuickAdded() {}
+`)
+		summary, err := QuickUpdateContext(root, output.FormatText, output.OrderImportance, true)
+		if err != nil {
+			t.Fatalf("QuickUpdateContext failed: %v", err)
+		}
+		if summary.Changed != 1 {
+			t.Fatalf("expected quick update to reparse demo.go, got %#v", summary)
+		}
+		if !strings.Contains(mustReadFile(t, filepath.Join(contextDir, nav.NavigationIndexFile)), "QuickAdded") {
+			t.Fatalf("expected quick update to refresh the navigation index")
+		}
+		if mustReadFile(t, searchPath) != searchBefore {
+			t.Fatalf("expected quick update to leave the search index untouched")
+		}
+		st, err := state.Load(contextDir)
+		if err != nil {
+			t.Fatalf("failed to load state: %v", err)
+		}
+		if !st.SearchStale {
+			t.Fatalf("expected quick update to mark the search index stale")
+		}
+
+		// A full update with no source changes still rebuilds the stale search index.
+		summary, err = UpdateContext(root, output.FormatText, output.OrderImportance, true)
+		if err != nil {
+			t.Fatalf("UpdateContext failed: %v", err)
+		}
+		if summary.Rewritten == 0 || !strings.Contains(mustReadFile(t, searchPath), "QuickAdded") {
+			t.Fatalf("expected full update to rebuild the search index, got %#v", summary)
+		}
+		st, err = state.Load(contextDir)
+		if err != nil {
+			t.Fatalf("failed to load state: %v", err)
+		}
+		if st.SearchStale {
+			t.Fatalf("expected full update to clear the stale search marker")
+		}
+
+		st.ParserVersion = "old-parser"
+		if err := st.Save(contextDir); err != nil {
+			t.Fatalf("failed to save state: %v", err)
+		}
+		if _, err := QuickUpdateContext(root, output.FormatText, output.OrderImportance, true); err == nil || !strings.Contains(err.Error(), "without --quick") {
+			t.Fatalf("expected quick update to refuse a full regenerate, got %v", err)
+		}
+	})
+}
+
+func TestUpdatePatchesIndexesToMatchFullGenerate(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "a.go"), `package demo
+
+// A starts the chain.
+func A() { B() }
+`)
+	mustWriteFile(t, filepath.Join(root, "b.go"), `package demo
+
+func B() {}
+func Unchanged() {}
+`)
+
+	withWorkingDir(t, root, func() {
+		if _, err := generateContext(root, nil, output.FormatText, output.OrderImportance, true, 0, true); err != nil {
+			t.Fatalf("generateContext failed: %v", err)
+		}
+		contextDir := filepath.Join(root, output.ContextDir)
+		st, err := state.Load(contextDir)
+		if err != nil {
+			t.Fatalf("failed to load state: %v", err)
+		}
+		if st.Files["b.go"].NavSegment == "" || st.Files["b.go"].SearchSegment == "" {
+			t.Fatalf("expected generate to record index segments, got %#v", st.Files["b.go"])
+		}
+
+		mustWriteFile(t, filepath.Join(root, "a.go"), `package demo
+
+// A starts a longer chain.
+func A() { B(); Added() }
+func Added() {}
+`)
+		if _, err := UpdateContext(root, output.FormatText, output.OrderImportance, true); err != nil {
+			t.Fatalf("UpdateContext failed: %v", err)
+		}
+		navPatched := mustReadFile(t, filepath.Join(contextDir, nav.NavigationIndexFile))
+		searchPatched := mustReadFile(t, filepath.Join(contextDir, search.IndexFile))
+
+		if _, err := generateContext(root, nil, output.FormatText, output.OrderImportance, true, 0, true); err != nil {
+			t.Fatalf("generateContext failed: %v", err)
+		}
+		if searchFull := mustReadFile(t, filepath.Join(contextDir, search.IndexFile)); searchPatched != searchFull {
+			t.Fatalf("expected patched search index to match a full build\npatched:\n%s\nfull:\n%s", searchPatched, searchFull)
+		}
+		// Ranks of untouched files are refreshed by generate only.
+		decode := func(data string) nav.Index {
+			var index nav.Index
+			if err := json.Unmarshal([]byte(data), &index); err != nil {
+				t.Fatalf("failed to decode navigation index: %v", err)
+			}
+			for i := range index.Nodes {
+				index.Nodes[i].Rank = 0
+			}
+			return index
+		}
+		navFull := mustReadFile(t, filepath.Join(contextDir, nav.NavigationIndexFile))
+		if !reflect.DeepEqual(decode(navPatched), decode(navFull)) {
+			t.Fatalf("expected patched navigation index to match a full build\npatched:\n%s\nfull:\n%s", navPatched, navFull)
+		}
+		if !strings.Contains(navPatched, "Added") || !strings.Contains(searchPatched, "longer") {
+			t.Fatalf("expected update to re-encode the changed file")
+		}
+	})
+}
+
+func TestUpdateReusesPageRankUntilTopologyChanges(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "demo.go"), `package demo
+
+func A() { B() }
+func B() {}
+func C() {}
+`)
+
+	withWorkingDir(t, root, func() {
+		if _, err := generateContext(root, nil, output.FormatText, output.OrderImportance, true, 0, true); err != nil {
+			t.Fatalf("generateContext failed: %v", err)
+		}
+		contextDir := filepath.Join(root, output.ContextDir)
+		generated, err := state.Load(contextDir)
+		if err != nil {
+			t.Fatalf("failed to load state: %v", err)
+		}
+		if generated.RankTopology == "" || len(generated.Ranks) != 3 {
+			t.Fatalf("expected generate to record ranks, got %q %v", generated.RankTopology, generated.Ranks)
+		}
+
+		// A doc comment leaves the topology, and so every rank, unchanged.
+		mustWriteFile(t, filepath.Join(root, "demo.go"), `package demo
+
+// A calls B.
+func A() { B() }
+func B() {}
+func C() {}
+`)
+		if _, err := UpdateContext(root, output.FormatText, output.OrderImportance, true); err != nil {
+			t.Fatalf("UpdateContext failed: %v", err)
+		}
+		updated, err := state.Load(contextDir)
+		if err != nil {
+			t.Fatalf("failed to load state: %v", err)
+		}
+		if updated.RankTopology != generated.RankTopology || !reflect.DeepEqual(updated.Ranks, generated.Ranks) {
+			t.Fatalf("expected unchanged topology to keep ranks, got %v, want %v", updated.Ranks, generated.Ranks)
+		}
+
+		mustWriteFile(t, filepath.Join(root, "demo.go"), `package demo
+
+// A calls B.
+func A() { B() }
+func B() { C() }
+func C() {}
+`)
+		if _, err := UpdateContext(root, output.FormatText, output.OrderImportance, true); err != nil {
+			t.Fatalf("UpdateContext failed: %v", err)
+		}
+		updated, err = state.Load(contextDir)
+		if err != nil {
+			t.Fatalf("failed to load state: %v", err)
+		}
+		if updated.RankTopology == generated.RankTopology || reflect.DeepEqual(updated.Ranks, generated.Ranks) {
+			t.Fatalf("expected a new edge to recompute ranks")
+		}
+		// Regenerating from the recorded ranks reproduces the updated context.
+		captureStdout(t, func() {
+			if err := RunCI(newCICmdForTest(), nil); err != nil {
+				t.Fatalf("expected updated context to pass ci, got %v", err)
+			}
+		})
+	})
+}
+
+func TestUpdateTreatsIncludedHeadersAsDependencies(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "include", "point.h"), `typedef struct { int x; int y; } point_t;
+`)
+	mustWriteFile(t, filepath.Join(root, "src", "point.c"), `#include "point.h"
+
+int point_sum(point_t p) { return p.x + p.y; }
+`)
+	mustWriteFile(t, filepath.Join(root, "src", "main.c"), `#include <stdio.h>
+#include "point.h"
+
+int main(void) { point_t p = {1, 2}; return point_sum(p); }
+`)
+	mustWriteFile(t, filepath.Join(root, "src", "other.c"), `int other(void) { return 0; }
+`)
+
+	withWorkingDir(t, root, func() {
+		if _, err := generateContext(root, nil, output.FormatText, output.OrderImportance, true, 0, true); err != nil {
+			t.Fatalf("generateContext failed: %v", err)
+		}
+
+		mustWriteFile(t, filepath.Join(root, "include", "point.h"), `typedef struct { int x; int y; int z; } point_t;
+`)
+		summary, err := UpdateContext(root, output.FormatText, output.OrderImportance, true)
+		if err != nil {
+			t.Fatalf("UpdateContext failed: %v", err)
+		}
+		want := []string{"include/point.h", "src/main.c", "src/point.c"}
+		if strings.Join(summary.ImpactedFiles, ",") != strings.Join(want, ",") {
+			t.Fatalf("expected header change to impact its includers %v, got %v", want, summary.ImpactedFiles)
+		}
+	})
+}
+
+func TestEnrichWritesJSONLForTarget(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "demo.go"), `package demo
+
+func A() {
+	B()
+}
+func B() {}
+`)
+
+	withWorkingDir(t, root, func() {
+		if err := RunInit(&cobra.Command{}, nil); err != nil {
+			t.Fatalf("RunInit failed: %v", err)
+		}
+		if err := RunGenerate(newGenerateCmdForTest(), []string{"."}); err != nil {
+			t.Fatalf("RunGenerate failed: %v", err)
+		}
+
+		enrichCmd := newEnrichCmdForTest()
+		if err := RunEnrich(enrichCmd, []string{"demo.go:A", "Primary entrypoint for demo behavior."}); err != nil {
+			t.Fatalf("RunEnrich failed: %v", err)
+		}
+
+		enrichPath := filepath.Join(root, output.ContextDir, "enrich.jsonl")
+		assertExists(t, enrichPath)
+
+		data, err := os.ReadFile(enrichPath)
+		if err != nil {
+			t.Fatalf("failed to read enrich output: %v", err)
+		}
+		lines := bytes.Split(bytes.TrimSpace(data), []byte("\n"))
+		if len(lines) != 1 {
+			t.Fatalf("expected 1 enrich record, got %d", len(lines))
+		}
+
+		var row map[string]any
+		if err := json.Unmarshal(lines[0], &row); err != nil {
+			t.Fatalf("failed to decode enrich record: %v", err)
+		}
+		if row["symbol_id"] == "" {
+			t.Fatalf("expected enrich record to include symbol_id")
+		}
+		symbolID, _ := row["symbol_id"].(string)
+		if !strings.Contains(symbolID, "|A|") {
+			t.Fatalf("expected targeted enrich record for symbol A, got symbol_id=%q", symbolID)
+		}
+		outputValue, ok := row["output"].(map[string]any)
+		if !ok {
+			t.Fatalf("expected enrich output payload")
+		}
+		if outputValue["summary"] != "Primary entrypoint for demo behavior." {
+			t.Fatalf("unexpected output.summary: %v", outputValue["summary"])
+		}
+		if outputValue["purpose"] != "Primary entrypoint for demo behavior." {
+			t.Fatalf("expected purpose default to description, got: %v", outputValue["purpose"])
+		}
+		if outputValue["confidence"] != "medium" {
+			t.Fatalf("expected confidence=medium, got: %v", outputValue["confidence"])
+		}
+		input, _ := row["input"].(map[string]any)
+		source, _ := input["source"].(map[string]any)
+		if source["start_line"] != float64(3) || source["end_line"] != float64(5) || source["body"] != "func A() {\n\tB()\n}" {
+			t.Fatalf("expected the full body of A as source, got %#v", source)
+		}
+	})
+}
+
+func TestEnrichTruncatesLongBodiesToHeadAndTail(t *testing.T) {
+	root := t.TempDir()
+	var body strings.Builder
+	for i := 0; i < 40; i++ {
+		fmt.Fprintf(&body, "\tstep%02d()\n", i)
+	}
+	mustWriteFile(t, filepath.Join(root, "demo.go"), "package demo\n\nfunc Long(id string) error {\n"+body.String()+"\treturn nil\n}\n")
+	mustWriteFile(t, filepath.Join(root, ".skelly", "config.yaml"), "enrich:\n  max_body_bytes: 200\n")
+
+	withWorkingDir(t, root, func() {
+		if err := RunGenerate(newGenerateCmdForTest(), []string{"."}); err != nil {
+			t.Fatalf("RunGenerate failed: %v", err)
+		}
+		readSource := func() enrich.SourceSpan {
+			records, err := enrich.LoadCache(filepath.Join(root, output.ContextDir, enrich.OutputFile))
+			if err != nil || len(records) != 1 {
+				t.Fatalf("expected one enrich record, got %d (%v)", len(records), err)
+			}
+			for _, record := range records {
+				return record.Input.Source
+			}
+			return enrich.SourceSpan{}
+		}
+
+		if err := RunEnrich(newEnrichCmdForTest(), []string{"demo.go:Long", "Runs every step."}); err != nil {
+			t.Fatalf("RunEnrich failed: %v", err)
+		}
+		source := readSource()
+		if !source.Truncated || len(source.Body) > 240 || source.StartLine != 3 || source.EndLine != 45 {
+			t.Fatalf("expected a truncated span of lines 3-45, got %+v", source)
+		}
+		for _, want := range []string{"func Long(id string) error {\n\tstep00()\n", "bytes omitted) ...\n", "\treturn nil\n}"} {
+			if !strings.Contains(source.Body, want) {
+				t.Fatalf("expected truncated body to contain %q, got:\n%s", want, source.Body)
+			}
+		}
+		if !strings.HasSuffix(source.Body, "\treturn nil\n}") {
+			t.Fatalf("expected the tail to end the body, got:\n%s", source.Body)
+		}
+
+		// The flag overrides the config; 0 keeps the whole body.
+		cmd := newEnrichCmdForTest()
+		mustSetFlag(t, cmd, "max-body-bytes", "0")
+		if err := RunEnrich(cmd, []string{"demo.go:Long", "Runs every step."}); err != nil {
+			t.Fatalf("RunEnrich failed: %v", err)
+		}
+		if source := readSource(); source.Truncated || !strings.Contains(source.Body, "step20()") {
+			t.Fatalf("expected the whole body with --max-body-bytes 0, got %+v", source)
+		}
+	})
+}
+
+func TestEnrichBootstrapSeedsRecordsFromDocComments(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "demo.go"), `package demo
+
+// Documented loads the account ledger from disk. It retries transient read errors twice.
+func Documented() {}
+
+// Short does a thing.
+func Short() {}
+
+// TODO: describe this before release, it is important enough.
+func Pending() {}
+
+// Reviewed parses the configuration file and returns validated settings.
+func Reviewed() {}
+
+func Bare() {}
+`)
+
+	withWorkingDir(t, root, func() {
+		if _, err := generateContext(root, nil, output.FormatText, output.OrderImportance, true, 0, true); err != nil {
+			t.Fatalf("generateContext failed: %v", err)
+		}
+		if err := RunEnrich(newEnrichCmdForTest(), []string{"demo.go:Reviewed", "Agent-written settings loader description."}); err != nil {
+			t.Fatalf("RunEnrich failed: %v", err)
+		}
+
+		cmd := newEnrichBootstrapCmdForTest()
+		mustSetFlag(t, cmd, "json", "true")
+		out := captureStdout(t, func() {
+			if err := RunEnrichBootstrap(cmd, nil); err != nil {
+				t.Fatalf("RunEnrichBootstrap failed: %v", err)
+			}
+		})
+		var summary EnrichRunSummary
+		if err := json.Unmarshal([]byte(out), &summary); err != nil {
+			t.Fatalf("failed to decode bootstrap summary: %v\n%s", err, out)
+		}
+		if summary.Symbols != 5 || summary.Succeeded != 1 || summary.Skipped != 4 {
+			t.Fatalf("expected only Documented to bootstrap, got %#v", summary)
+		}
+
+		records, err := enrich.LoadCache(filepath.Join(root, output.ContextDir, enrich.OutputFile))
+		if err != nil {
+			t.Fatalf("failed to load enrich cache: %v", err)
+		}
+		bootstrapped := 0
+		for _, record := range records {
+			if record.AgentProfile != enrich.BootstrapProfile {
+				if record.Output.Summary != "Agent-written settings loader description." {
+					t.Fatalf("expected agent record to be untouched, got %#v", record.Output)
+				}
+				continue
+			}
+			bootstrapped++
+			if record.Status != enrich.BootstrapStatus || !strings.Contains(record.SymbolID, "|Documented|") {
+				t.Fatalf("unexpected bootstrap record: %#v", record)
+			}
+			if record.Output.Summary != "Documented loads the account ledger from disk." || record.Output.Confidence != "high" {
+				t.Fatalf("unexpected bootstrap output: %#v", record.Output)
+			}
+		}
+		if bootstrapped != 1 {
+			t.Fatalf("expected 1 bootstrap record, got %d", bootstrapped)
+		}
+	})
+}
+
+func TestEnrichUsesConfiguredAgentAndMaxSymbols(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, ".skelly", "config.yaml"), "enrich:\n  agent: codex\n  max_symbols: 1\n")
+	mustWriteFile(t, filepath.Join(root, "demo.go"), `package demo
+
+// First loads the account ledger from disk and retries transient read errors.
+func First() {}
+
+// Second parses the configuration file and returns validated settings.
+func Second() {}
+
+func Bare() {}
+`)
+
+	withWorkingDir(t, root, func() {
+		if _, err := generateContext(root, nil, output.FormatText, output.OrderImportance, true, 0, true); err != nil {
+			t.Fatalf("generateContext failed: %v", err)
+		}
+		enrichCmd := newEnrichCmdForTest()
+		if err := RunEnrich(enrichCmd, []string{"demo.go:Bare", "Placeholder kept for the demo."}); err != nil {
+			t.Fatalf("RunEnrich failed: %v", err)
+		}
+		flagCmd := newEnrichCmdForTest()
+		mustSetFlag(t, flagCmd, "agent", "reviewer")
+		if err := RunEnrich(flagCmd, []string{"demo.go:First", "Ledger loader with retries."}); err != nil {
+			t.Fatalf("RunEnrich --agent failed: %v", err)
+		}
+		reserved := newEnrichCmdForTest()
+		mustSetFlag(t, reserved, "agent", enrich.BootstrapProfile)
+		if err := RunEnrich(reserved, []string{"demo.go:First", "Ledger loader."}); err == nil || !strings.Contains(err.Error(), "reserved") {
+			t.Fatalf("expected the bootstrap profile to be rejected, got %v", err)
+		}
+
+		records, err := enrich.LoadCache(filepath.Join(root, output.ContextDir, enrich.OutputFile))
+		if err != nil {
+			t.Fatalf("failed to load enrich cache: %v", err)
+		}
+		profiles := make([]string, 0, len(records))
+		for _, record := range records {
+			profiles = append(profiles, record.Input.Symbol.Name+"="+record.AgentProfile)
+		}
+		sort.Strings(profiles)
+		if strings.Join(profiles, ",") != "Bare=codex,First=reviewer" {
+			t.Fatalf("expected the configured and flagged agent profiles, got %v", profiles)
+		}
+
+		bootstrapCmd := newEnrichBootstrapCmdForTest()
+		mustSetFlag(t, bootstrapCmd, "json", "true")
+		out := captureStdout(t, func() {
+			if err := RunEnrichBootstrap(bootstrapCmd, nil); err != nil {
+				t.Fatalf("RunEnrichBootstrap failed: %v", err)
+			}
+		})
+		var summary EnrichRunSummary
+		if err := json.Unmarshal([]byte(out), &summary); err != nil {
+			t.Fatalf("failed to decode bootstrap summary: %v\n%s", err, out)
+		}
+		if summary.Symbols != 1 {
+			t.Fatalf("expected max_symbols to cap bootstrap at one symbol, got %#v", summary)
+		}
+	})
+}
+
+func TestEnrichBootstrapFiltersByPathSymbolAndKind(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "internal", "graph", "build.go"), `package graph
+
+// Build assembles the symbol graph from every parsed file in the repository.
+func Build() {}
+
+// Graph holds symbol nodes and the call edges between them for navigation.
+type Graph struct{}
+
+// Resolve links every call site to the symbol it most likely targets here.
+func (g *Graph) Resolve() {}
+`)
+	mustWriteFile(t, filepath.Join(root, "internal", "parser", "parse.go"), `package parser
+
+// Parse reads one source file and extracts its symbols and call sites.
+func Parse() {}
+`)
+
+	withWorkingDir(t, root, func() {
+		if err := RunGenerate(newGenerateCmdForTest(), []string{"."}); err != nil {
+			t.Fatalf("RunGenerate failed: %v", err)
+		}
+		bootstrap := func(flags map[string][]string) EnrichRunSummary {
+			t.Helper()
+			cmd := newEnrichBootstrapCmdForTest()
+			mustSetFlag(t, cmd, "json", "true")
+			mustSetFlag(t, cmd, "dry-run", "true")
+			for name, values := range flags {
+				for _, value := range values {
+					mustSetFlag(t, cmd, name, value)
+				}
+			}
+			out := captureStdout(t, func() {
+				if err := RunEnrichBootstrap(cmd, nil); err != nil {
+					t.Fatalf("RunEnrichBootstrap failed: %v", err)
+				}
+			})
+			var summary EnrichRunSummary
+			if err := json.Unmarshal([]byte(out), &summary); err != nil {
+				t.Fatalf("failed to decode bootstrap summary: %v\n%s", err, out)
+			}
+			return summary
+		}
+
+		if summary := bootstrap(map[string][]string{"path": {"internal/graph/**"}}); summary.Symbols != 3 || summary.Succeeded != 3 {
+			t.Fatalf("expected the three graph symbols, got %#v", summary)
+		}
+		if summary := bootstrap(map[string][]string{"path": {"internal/graph/**"}, "kind": {"function"}}); summary.Symbols != 1 {
+			t.Fatalf("expected --kind function to keep only Build, got %#v", summary)
+		}
+		if summary := bootstrap(map[string][]string{"symbol": {"Graph.Resolve", "Parse"}}); summary.Symbols != 2 || !reflect.DeepEqual(summary.Targets, []string{"internal/graph/build.go", "internal/parser/parse.go"}) {
+			t.Fatalf("expected Resolve and Parse, got %#v", summary)
+		}
+
+		cmd := newEnrichBootstrapCmdForTest()
+		mustSetFlag(t, cmd, "kind", "lambda")
+		if err := RunEnrichBootstrap(cmd, nil); err == nil || !strings.Contains(err.Error(), `unknown --kind "lambda"`) {
+			t.Fatalf("expected an unknown kind error, got %v", err)
+		}
+	})
+}
+
+func TestEnrichGCDropsOrphanedAndSupersededRecords(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "demo.go"), `package demo
+
+func A() {}
+
+func B() {}
+`)
+
+	withWorkingDir(t, root, func() {
+		if err := RunGenerate(newGenerateCmdForTest(), []string{"."}); err != nil {
+			t.Fatalf("RunGenerate failed: %v", err)
+		}
+		for _, target := range []string{"demo.go:A", "demo.go:B"} {
+			if err := RunEnrich(newEnrichCmdForTest(), []string{target, "Agent-written description."}); err != nil {
+				t.Fatalf("RunEnrich failed: %v", err)
+			}
+		}
+		cachePath := filepath.Join(root, output.ContextDir, enrich.OutputFile)
+		records, err := enrich.LoadCache(cachePath)
+		if err != nil {
+			t.Fatalf("LoadCache failed: %v", err)
+		}
+		for _, record := range records {
+			if strings.Contains(record.SymbolID, "|A|") {
+				older := record
+				older.PromptVersion = "agent-note-v0"
+				older.UpdatedAt = "2020-01-01T00:00:00Z"
+				older.CacheKey = enrich.CacheKey(older.SymbolID, older.FileHash, older.PromptVersion, older.AgentProfile, older.Model)
+				records[older.CacheKey] = older
+			}
+		}
+		if err := enrich.WriteCache(cachePath, records); err != nil {
+			t.Fatalf("WriteCache failed: %v", err)
+		}
+		mustWriteFile(t, filepath.Join(root, "demo.go"), `package demo
+
+func A() { println("changed") }
+`)
+		if err := RunUpdate(newUpdateCmdForTest(), nil); err != nil {
+			t.Fatalf("RunUpdate failed: %v", err)
+		}
+
+		statsCmd := newEnrichCmdForTest()
+		mustSetFlag(t, statsCmd, "json", "true")
+		out := captureStdout(t, func() {
+			if err := RunEnrichStats(statsCmd, nil); err != nil {
+				t.Fatalf("RunEnrichStats failed: %v", err)
+			}
+		})
+		var stats enrich.CacheStats
+		if err := json.Unmarshal([]byte(out), &stats); err != nil {
+			t.Fatalf("failed to decode stats: %v\n%s", err, out)
+		}
+		if stats.Records != 3 || stats.Symbols != 1 || stats.Covered != 1 || stats.Fresh != 0 || stats.StaleRecords != 2 ||
+			stats.StaleAge["older"] != 1 || stats.Reclaimable.Orphaned != 1 || stats.Reclaimable.Superseded != 1 || stats.Bytes == 0 {
+			t.Fatalf("unexpected stats: %+v", stats)
+		}
+
+		gc := func(flags ...string) EnrichGCSummary {
+			t.Helper()
+			cmd := &cobra.Command{}
+			cmd.Flags().Bool("stale", false, "")
+			cmd.Flags().Bool("dry-run", false, "")
+			cmd.Flags().Bool("json", false, "")
+			mustSetFlag(t, cmd, "json", "true")
+			for _, flag := range flags {
+				mustSetFlag(t, cmd, flag, "true")
+			}
+			out := captureStdout(t, func() {
+				if err := RunEnrichGC(cmd, nil); err != nil {
+					t.Fatalf("RunEnrichGC failed: %v", err)
+				}
+			})
+			var summary EnrichGCSummary
+			if err := json.Unmarshal([]byte(out), &summary); err != nil {
+				t.Fatalf("failed to decode gc summary: %v\n%s", err, out)
+			}
+			return summary
+		}
+
+		if summary := gc("dry-run"); summary.Kept != 1 || summary.Orphaned != 1 || summary.Superseded != 1 || summary.BytesAfter != summary.BytesBefore {
+			t.Fatalf("unexpected dry-run summary: %+v", summary)
+		}
+		if summary := gc(); summary.Kept != 1 || summary.Stale != 0 || summary.BytesAfter >= summary.BytesBefore {
+			t.Fatalf("unexpected gc summary: %+v", summary)
+		}
+		records, err = enrich.LoadCache(cachePath)
+		if err != nil {
+			t.Fatalf("LoadCache failed: %v", err)
+		}
+		for _, record := range records {
+			if record.PromptVersion != "agent-note-v1" || !strings.Contains(record.SymbolID, "|A|") {
+				t.Fatalf("expected only the newest record of A to remain, got %+v", record)
+			}
+		}
+		if summary := gc("stale"); summary.Kept != 0 || summary.Stale != 1 {
+			t.Fatalf("expected --stale to drop the remaining stale record, got %+v", summary)
+		}
+	})
+}
+
+func TestEnrichRequiresDescription(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "demo.go"), `package demo
+
+func A() {}
+`)
+
+	withWorkingDir(t, root, func() {
+		if err := RunInit(newInitCmdForTest(), nil); err != nil {
+			t.Fatalf("RunInit failed: %v", err)
+		}
+		if err := RunGenerate(newGenerateCmdForTest(), []string{"."}); err != nil {
+			t.Fatalf("RunGenerate failed: %v", err)
+		}
+
+		enrichCmd := newEnrichCmdForTest()
+		err := RunEnrich(enrichCmd, []string{"demo.go:A"})
+		if err == nil {
+			t.Fatalf("expected RunEnrich to fail without description")
+		}
+		if !strings.Contains(err.Error(), "description is required") {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
+
+func TestEnrichRejectsAmbiguousTarget(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "demo.go"), `package demo
+
+func A() {}
+func B() {}
+`)
+
+	withWorkingDir(t, root, func() {
+		if err := RunInit(newInitCmdForTest(), nil); err != nil {
+			t.Fatalf("RunInit failed: %v", err)
+		}
+		if err := RunGenerate(newGenerateCmdForTest(), []string{"."}); err != nil {
+			t.Fatalf("RunGenerate failed: %v", err)
+		}
+
+		enrichCmd := newEnrichCmdForTest()
+		err := RunEnrich(enrichCmd, []string{"demo.go", "desc"})
+		if err == nil {
+			t.Fatalf("expected ambiguous target error")
+		}
+		if !strings.Contains(err.Error(), "matched") {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
+
+func TestConventionsWritesReportAndPreservesNotes(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "cmd", "app", "main.go"), `package main
+
+import "example.com/demo/store"
+
+func main() { store.LoadUser() }
+`)
+	mustWriteFile(t, filepath.Join(root, "store", "user_store.go"), `package store
+
+import (
+	"errors"
+	"fmt"
+)
+
+var errMissing = errors.New("missing")
+
+func LoadUser() error {
+	if err := readUser(); err != nil {
+		return fmt.Errorf("load user: %w", err)
+	}
+	return nil
+}
+
+func readUser() error { return errMissing }
+`)
+	mustWriteFile(t, filepath.Join(root, "store", "user_store_test.go"), `package store
+
+func TestLoadUser() { LoadUser() }
+`)
+
+	withWorkingDir(t, root, func() {
+		if err := RunGenerate(newGenerateCmdForTest(), []string{"."}); err != nil {
+			t.Fatalf("RunGenerate failed: %v", err)
+		}
+
+		cmd := newConventionsCmdForTest()
+		mustSetFlag(t, cmd, "note", "Wrap errors with context before returning.")
+		if err := RunConventions(cmd, nil); err != nil {
+			t.Fatalf("RunConventions failed: %v", err)
+		}
+
+		conventionsPath := filepath.Join(root, output.SkellyDir, "conventions.md")
+		content := mustReadFile(t, conventionsPath)
+		for _, want := range []string{
+			"## Naming",
+			"- go files: lowercase 50%, snake_case 50% (2 names)",
+			"`cmd/app` — entrypoint",
+			"`store` — foundation",
+			"fmt.Errorf wrapping with %w (1 in 1 files)",
+			"- go: 1 test files named `*_test.go`; 1 colocated with sources",
+			"- Wrap errors with context before returning.",
+		} {
+			if !strings.Contains(content, want) {
+				t.Fatalf("expected conventions.md to contain %q, got:\n%s", want, content)
+			}
+		}
+
+		// Regenerating without --note keeps previously recorded notes.
+		if err := RunConventions(newConventionsCmdForTest(), nil); err != nil {
+			t.Fatalf("second RunConventions failed: %v", err)
+		}
+		if content := mustReadFile(t, conventionsPath); !strings.Contains(content, "- Wrap errors with context before returning.") {
+			t.Fatalf("expected agent notes to survive regeneration, got:\n%s", content)
+		}
+	})
+}
+
+func TestDeadcodeListsUnusedPublicSymbols(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "cmd", "app", "main.go"), `package main
+
+import "example.com/demo/store"
+
+func main() { store.LoadUser() }
+`)
+	mustWriteFile(t, filepath.Join(root, "store", "store.go"), `package store
+
+func LoadUser() error { return readUser() }
+
+func readUser() error { return nil }
+
+func ExportUsers() error { return nil }
+
+func LegacyImport() error { return nil }
+
+func CheckedByTests() bool { return true }
+
+type ID string
+
+func (id ID) String() string { return string(id) }
+
+type Draft struct{}
+`)
+	mustWriteFile(t, filepath.Join(root, "store", "store_test.go"), `package store
+
+func TestChecked() { CheckedByTests() }
+`)
+	mustWriteFile(t, filepath.Join(root, ".skelly", "config.yaml"), "deadcode:\n  allow: [LegacyImport]\n")
+
+	withWorkingDir(t, root, func() {
+		if err := RunGenerate(newGenerateCmdForTest(), []string{"."}); err != nil {
+			t.Fatalf("RunGenerate failed: %v", err)
+		}
+
+		cmd := newDeadcodeCmdForTest()
+		mustSetFlag(t, cmd, "json", "true")
+		out := captureStdout(t, func() {
+			if err := RunDeadcode(cmd, nil); err != nil {
+				t.Fatalf("RunDeadcode failed: %v", err)
+			}
+		})
+		var payload struct {
+			Report deadcode.Report `json:"report"`
+		}
+		if err := json.Unmarshal([]byte(out), &payload); err != nil {
+			t.Fatalf("invalid JSON: %v\n%s", err, out)
+		}
+		names := make([]string, 0, len(payload.Report.Symbols))
+		for _, symbol := range payload.Report.Symbols {
+			names = append(names, symbol.Name)
+		}
+		// ID has a String method, which fmt calls, so only Draft is unused.
+		if !reflect.DeepEqual(names, []string{"ExportUsers", "Draft"}) {
+			t.Fatalf("unexpected unused symbols %v", names)
+		}
+		if payload.Report.EntryPoints != 1 || payload.Report.Allowlisted != 1 {
+			t.Fatalf("expected String as entry point and one allowlisted symbol, got %+v", payload.Report)
+		}
+
+		cmd = newDeadcodeCmdForTest()
+		mustSetFlag(t, cmd, "allow", "store/**")
+		out = captureStdout(t, func() {
+			if err := RunDeadcode(cmd, nil); err != nil {
+				t.Fatalf("RunDeadcode failed: %v", err)
+			}
+		})
+		if !strings.Contains(out, "0 unused of") || !strings.Contains(out, "3 allowlisted") {
+			t.Fatalf("expected --allow path glob to cover store, got:\n%s", out)
+		}
+	})
+}
+
+func TestExternalCodeIsClassifiedAndHiddenByDefault(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "go.mod"), "module example.com/app\n")
+	mustWriteFile(t, filepath.Join(root, "app.go"), `package app
+
+import (
+	"example.com/app/api/generated"
+	"example.com/app/sdk"
+)
+
+func Run() {
+	sdk.Connect()
+	generated.NewClient()
+}
+`)
+	mustWriteFile(t, filepath.Join(root, "sdk", "client.go"), "package sdk\n\nfunc Connect() {}\n")
+	mustWriteFile(t, filepath.Join(root, "api", "generated", "client.go"), "package generated\n\nfunc NewClient() {}\n")
+	mustWriteFile(t, filepath.Join(root, ".skelly", "config.yaml"), "external: [sdk/**]\n")
+
+	withWorkingDir(t, root, func() {
+		genCmd := newGenerateCmdForTest()
+		mustSetFlag(t, genCmd, "format", "jsonl")
+		if err := RunGenerate(genCmd, []string{"."}); err != nil {
+			t.Fatalf("RunGenerate failed: %v", err)
+		}
+		contextDir := filepath.Join(root, output.ContextDir)
+		st, err := state.Load(contextDir)
+		if err != nil {
+			t.Fatalf("state.Load failed: %v", err)
+		}
+		if !st.Files["sdk/client.go"].External || !st.Files["api/generated/client.go"].External || st.Files["app.go"].External {
+			t.Fatalf("expected sdk/ and generated/ files to be external, got %#v", st.Files)
+		}
+		var manifest struct {
+			External struct {
+				Files   int `json:"files"`
+				Symbols int `json:"symbols"`
+			} `json:"external"`
+		}
+		if err := json.Unmarshal([]byte(mustReadFile(t, filepath.Join(contextDir, "manifest.json"))), &manifest); err != nil {
+			t.Fatalf("invalid manifest: %v", err)
+		}
+		if manifest.External.Files != 2 || manifest.External.Symbols != 2 {
+			t.Fatalf("expected two external files in the manifest, got %+v", manifest.External)
+		}
+
+		runCallees := func(includeExternal bool) []nav.EdgeRecord {
+			calleesCmd := newCalleesCmdForTest()
+			mustSetFlag(t, calleesCmd, "json", "true")
+			if includeExternal {
+				mustSetFlag(t, calleesCmd, "include-external", "true")
+			}
+			out := captureStdout(t, func() {
+				if err := nav.RunCallees(calleesCmd, []string{"Run"}); err != nil {
+					t.Fatalf("RunCallees failed: %v", err)
+				}
+			})
+			var payload struct {
+				Callees []nav.EdgeRecord `json:"callees"`
+			}
+			if err := json.Unmarshal([]byte(out), &payload); err != nil {
+				t.Fatalf("invalid JSON: %v\n%s", err, out)
+			}
+			return payload.Callees
+		}
+		if callees := runCallees(false); len(callees) != 0 {
+			t.Fatalf("expected external callees to be hidden, got %+v", callees)
+		}
+		callees := runCallees(true)
+		if len(callees) != 2 || !callees[0].Symbol.External || !callees[1].Symbol.External {
+			t.Fatalf("expected both external callees with --include-external, got %+v", callees)
+		}
+
+		searchCmd := newSearchCmdForTest()
+		mustSetFlag(t, searchCmd, "json", "true")
+		out := captureStdout(t, func() {
+			if err := nav.RunSearch(searchCmd, []string{"Connect"}); err != nil {
+				t.Fatalf("RunSearch failed: %v", err)
+			}
+		})
+		if !strings.Contains(out, `"total": 0`) {
+			t.Fatalf("expected search to hide external symbols, got %s", out)
+		}
+		symbolCmd := newSymbolCmdForTest()
+		if err := nav.RunSymbol(symbolCmd, []string{"Connect"}); err == nil || !strings.Contains(err.Error(), "--include-external") {
+			t.Fatalf("expected a hint at --include-external, got %v", err)
+		}
+
+		mustWriteFile(t, filepath.Join(root, "sdk", "client.go"), "package sdk\n\nfunc Connect() {}\n\nfunc Close() {}\n")
+		if _, err := UpdateContext(root, output.FormatJSONL, output.OrderImportance, true); err != nil {
+			t.Fatalf("UpdateContext failed: %v", err)
+		}
+		if st, err = state.Load(contextDir); err != nil {
+			t.Fatalf("state.Load failed: %v", err)
+		}
+		if fileState := st.Files["sdk/client.go"]; !fileState.External || len(fileState.Symbols) != 2 {
+			t.Fatalf("expected update to keep the reparsed file external, got %#v", fileState)
+		}
+	})
+}
+
+func TestVisibilityFiltersSearchAndDeadcode(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "store", "store.go"), `package store
+
+func LoadUser() error { return readUser() }
+
+func readUser() error { return nil }
+
+func staleUser() error { return nil }
+`)
+	mustWriteFile(t, filepath.Join(root, "jobs", "user.py"), `def sync_user():
+    return _fetch_user()
+
+def _fetch_user():
+    return []
+
+def _stale_user():
+    return []
+`)
+
+	withWorkingDir(t, root, func() {
+		if err := RunGenerate(newGenerateCmdForTest(), []string{"."}); err != nil {
+			t.Fatalf("RunGenerate failed: %v", err)
+		}
+
+		searchCmd := newSearchCmdForTest()
+		mustSetFlag(t, searchCmd, "visibility", "private")
+		mustSetFlag(t, searchCmd, "json", "true")
+		out := captureStdout(t, func() {
+			if err := nav.RunSearch(searchCmd, []string{"user"}); err != nil {
+				t.Fatalf("RunSearch failed: %v", err)
+			}
+		})
+		var search struct {
+			Matches []nav.HybridMatch `json:"matches"`
+		}
+		if err := json.Unmarshal([]byte(out), &search); err != nil {
+			t.Fatalf("invalid JSON: %v\n%s", err, out)
+		}
+		names := make([]string, 0, len(search.Matches))
+		for _, match := range search.Matches {
+			if match.Visibility != parser.VisibilityPrivate {
+				t.Fatalf("expected only private matches, got %+v", match)
+			}
+			names = append(names, match.Name)
+		}
+		sort.Strings(names)
+		if !reflect.DeepEqual(names, []string{"_fetch_user", "_stale_user", "readUser", "staleUser"}) {
+			t.Fatalf("unexpected private matches %v", names)
+		}
+
+		searchCmd = newSearchCmdForTest()
+		mustSetFlag(t, searchCmd, "visibility", "internal")
+		if err := nav.RunSearch(searchCmd, []string{"user"}); err == nil || !strings.Contains(err.Error(), "unknown visibility") {
+			t.Fatalf("expected unknown visibility error, got %v", err)
+		}
+
+		deadcodeCmd := newDeadcodeCmdForTest()
+		mustSetFlag(t, deadcodeCmd, "visibility", "public,private")
+		mustSetFlag(t, deadcodeCmd, "json", "true")
+		out = captureStdout(t, func() {
+			if err := RunDeadcode(deadcodeCmd, nil); err != nil {
+				t.Fatalf("RunDeadcode failed: %v", err)
+			}
+		})
+		var payload struct {
+			Report deadcode.Report `json:"report"`
+		}
+		if err := json.Unmarshal([]byte(out), &payload); err != nil {
+			t.Fatalf("invalid JSON: %v\n%s", err, out)
+		}
+		unused := make([]string, 0, len(payload.Report.Symbols))
+		for _, symbol := range payload.Report.Symbols {
+			unused = append(unused, symbol.Name+":"+symbol.Visibility)
+		}
+		want := []string{"sync_user:public", "_stale_user:private", "LoadUser:public", "staleUser:private"}
+		if !reflect.DeepEqual(unused, want) {
+			t.Fatalf("expected unused %v, got %v", want, unused)
+		}
+	})
+}
+
+func TestLanguagesReportsCoverageAndSkipReasons(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "app.go"), `package app
+
+func Run() {}
+
+func Stop() {}
+`)
+	mustWriteFile(t, filepath.Join(root, "api.pb.go"), `// Code generated by protoc-gen-go. DO NOT EDIT.
+
+package app
+
+func (x *Request) GetName() string { return "" }
+`)
+	mustWriteFile(t, filepath.Join(root, "web", "main.js"), "function boot() {}\n")
+	mustWriteFile(t, filepath.Join(root, "README.md"), "# demo\n")
+	mustWriteFile(t, filepath.Join(root, "node_modules", "dep", "index.js"), "function dep() {}\n")
+	mustWriteFile(t, filepath.Join(root, ".skelly", "config.yaml"), "skip_generated: true\n")
+
+	withWorkingDir(t, root, func() {
+		report, err := BuildLanguageReport(root, 0)
+		if err != nil {
+			t.Fatalf("BuildLanguageReport failed: %v", err)
+		}
+		if report.Indexed != 0 || report.SourceFiles != 3 || len(report.Skipped) != 3 || report.Skipped[2].Reason != SkipNotIndexed || report.Skipped[2].Count != 3 {
+			t.Fatalf("expected every source file to be not indexed before generate, got %#v", report)
+		}
+
+		if err := RunGenerate(newGenerateCmdForTest(), []string{"."}); err != nil {
+			t.Fatalf("RunGenerate failed: %v", err)
+		}
+		mustWriteFile(t, filepath.Join(root, "later.go"), "package app\n")
+		report, err = BuildLanguageReport(root, 0)
+		if err != nil {
+			t.Fatalf("BuildLanguageReport failed: %v", err)
+		}
+		if report.Files != 5 || report.SourceFiles != 4 || report.Indexed != 2 || report.Symbols != 3 || report.Coverage != 0.5 {
+			t.Fatalf("unexpected totals: %#v", report)
+		}
+		wantLanguages := []LanguageCoverage{
+			{Language: "go", Files: 3, Parsed: 1, Skipped: 2, Symbols: 2},
+			{Language: "javascript", Files: 1, Parsed: 1, Symbols: 1},
+		}
+		if !reflect.DeepEqual(report.Languages, wantLanguages) {
+			t.Fatalf("unexpected languages:\n got %#v\nwant %#v", report.Languages, wantLanguages)
+		}
+		wantSkipped := []SkippedFiles{
+			{Reason: SkipIgnored, Count: 1, Files: []string{"node_modules/"}},
+			{Reason: SkipUnsupported, Count: 1, Files: []string{"README.md"}, Extensions: map[string]int{".md": 1}},
+			{Reason: SkipGenerated, Count: 1, Files: []string{"api.pb.go"}},
+			{Reason: SkipNotIndexed, Count: 1, Files: []string{"later.go"}},
+		}
+		if !reflect.DeepEqual(report.Skipped, wantSkipped) {
+			t.Fatalf("unexpected skipped files:\n got %#v\nwant %#v", report.Skipped, wantSkipped)
+		}
+
+		cmd := newLanguagesCmdForTest()
+		mustSetFlag(t, cmd, "json", "true")
+		out := captureStdout(t, func() {
+			if err := RunLanguages(cmd, nil); err != nil {
+				t.Fatalf("RunLanguages failed: %v", err)
+			}
+		})
+		if !strings.Contains(out, `"mode": "languages"`) || !strings.Contains(out, `"coverage": 0.5`) {
+			t.Fatalf("unexpected json output:\n%s", out)
+		}
+	})
+}
+
+func TestParseIssuesArePersistedListedAndPruned(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "app.go"), "package app\n\nfunc Run() {}\n")
+	if err := os.Symlink(filepath.Join(root, "missing.go"), filepath.Join(root, "broken.go")); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+
+	withWorkingDir(t, root, func() {
+		if err := RunGenerate(newGenerateCmdForTest(), []string{"."}); err != nil {
+			t.Fatalf("RunGenerate failed: %v", err)
+		}
+		issues, err := output.LoadIssues(root)
+		if err != nil {
+			t.Fatalf("LoadIssues failed: %v", err)
+		}
+		if len(issues) != 1 || issues[0].File != "broken.go" || issues[0].Language != "go" || issues[0].Severity != "error" {
+			t.Fatalf("expected the unreadable file to be recorded, got %#v", issues)
+		}
+
+		cmd := newIssuesCmdForTest()
+		mustSetFlag(t, cmd, "severity", "error")
+		mustSetFlag(t, cmd, "lang", "go")
+		mustSetFlag(t, cmd, "json", "true")
+		out := captureStdout(t, func() {
+			if err := RunIssues(cmd, nil); err != nil {
+				t.Fatalf("RunIssues failed: %v", err)
+			}
+		})
+		if !strings.Contains(out, `"errors": 1`) || !strings.Contains(out, `"file": "broken.go"`) {
+			t.Fatalf("unexpected issues output:\n%s", out)
+		}
+		cmd = newIssuesCmdForTest()
+		mustSetFlag(t, cmd, "file", "internal")
+		out = captureStdout(t, func() {
+			if err := RunIssues(cmd, nil); err != nil {
+				t.Fatalf("RunIssues failed: %v", err)
+			}
+		})
+		if out != "0 issues (0 errors, 0 warnings)\n" {
+			t.Fatalf("expected --file to filter issues out, got:\n%s", out)
+		}
+
+		if err := os.Remove(filepath.Join(root, "broken.go")); err != nil {
+			t.Fatalf("failed to remove symlink: %v", err)
+		}
+		var summary DoctorSummary
+		doctorCmd := newDoctorCmdForTest()
+		mustSetFlag(t, doctorCmd, "json", "true")
+		out = captureStdout(t, func() {
+			if err := RunDoctor(doctorCmd, nil); err != nil {
+				t.Fatalf("RunDoctor failed: %v", err)
+			}
+		})
+		if err := json.Unmarshal([]byte(out), &summary); err != nil {
+			t.Fatalf("failed to decode doctor output: %v\noutput=%s", err, out)
+		}
+		if summary.ParseErrors != 1 || !slices.Contains(summary.Suggestions, "run skelly issues") {
+			t.Fatalf("expected doctor to surface the parse error, got %+v", summary)
+		}
+
+		if _, err := UpdateContext(root, output.FormatText, output.OrderImportance, true); err != nil {
+			t.Fatalf("UpdateContext failed: %v", err)
+		}
+		issues, err = output.LoadIssues(root)
+		if err != nil {
+			t.Fatalf("LoadIssues failed: %v", err)
+		}
+		if len(issues) != 0 {
+			t.Fatalf("expected update to drop the issues of deleted files, got %#v", issues)
+		}
+	})
+}
+
+// failingGoParser stands in for a grammar that errors on a file.
+type failingGoParser struct{}
+
+func (failingGoParser) Language() string     { return "go" }
+func (failingGoParser) Extensions() []string { return []string{".go"} }
+func (failingGoParser) Parse(filename string, content []byte) (*parser.FileSymbols, error) {
+	return nil, fmt.Errorf("tree-sitter: parse failed")
+}
+
+func TestUpdateKeepsPriorStateOfFilesThatFailToParse(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "app.go"), "package app\n\nfunc Run() {}\n")
+	mustWriteFile(t, filepath.Join(root, "tasks.py"), "def sync():\n    return 1\n")
+
+	withWorkingDir(t, root, func() {
+		if err := RunGenerate(newGenerateCmdForTest(), []string{"."}); err != nil {
+			t.Fatalf("RunGenerate failed: %v", err)
+		}
+		mustWriteFile(t, filepath.Join(root, "app.go"), "package app\n\nfunc Run() {}\n\nfunc Stop() {}\n")
+		mustWriteFile(t, filepath.Join(root, "tasks.py"), "def sync():\n    return 1\n\n\ndef pull():\n    return 2\n")
+
+		session, err := loadContextSession(root, output.FormatText, output.OrderImportance, nil)
+		if err != nil {
+			t.Fatalf("loadContextSession failed: %v", err)
+		}
+		previousHash := session.st.Files["app.go"].Hash
+		session.registry.Register(failingGoParser{})
+		summary, err := session.apply(true)
+		if err != nil {
+			t.Fatalf("apply failed: %v", err)
+		}
+		if summary.Parsed != 1 || summary.Skipped != 1 || !reflect.DeepEqual(summary.SkippedFiles, []string{"app.go"}) {
+			t.Fatalf("expected app.go to be skipped and tasks.py parsed, got %+v", summary)
+		}
+		if _, err := session.flush(); err != nil {
+			t.Fatalf("flush failed: %v", err)
+		}
+
+		st, err := state.Load(filepath.Join(root, output.ContextDir))
+		if err != nil {
+			t.Fatalf("state.Load failed: %v", err)
+		}
+		if st.Files["app.go"].Hash != previousHash || len(st.Files["app.go"].Symbols) != 1 || len(st.Files["tasks.py"].Symbols) != 2 {
+			t.Fatalf("expected app.go to keep its previous state, got %#v", st.Files)
+		}
+		issues, err := output.LoadIssues(root)
+		if err != nil {
+			t.Fatalf("LoadIssues failed: %v", err)
+		}
+		want := []parser.ParseIssue{{File: "app.go", Language: "go", Severity: "error", Message: "tree-sitter: parse failed"}}
+		if !reflect.DeepEqual(issues, want) {
+			t.Fatalf("unexpected issues:\n got %#v\nwant %#v", issues, want)
+		}
+
+		summary, err = UpdateContext(root, output.FormatText, output.OrderImportance, true)
+		if err != nil {
+			t.Fatalf("UpdateContext failed: %v", err)
+		}
+		if summary.Parsed != 1 || summary.Skipped != 0 {
+			t.Fatalf("expected the next update to retry app.go, got %+v", summary)
+		}
+		if issues, err = output.LoadIssues(root); err != nil || len(issues) != 0 {
+			t.Fatalf("expected the issue to clear once app.go parses, got %#v (%v)", issues, err)
+		}
+	})
+}
+
+func TestConventionsRequiresIndex(t *testing.T) {
+	root := t.TempDir()
+	withWorkingDir(t, root, func() {
+		err := RunConventions(newConventionsCmdForTest(), nil)
+		if err == nil || !strings.Contains(err.Error(), "skelly generate") {
+			t.Fatalf("expected generate hint, got %v", err)
+		}
+	})
+}
+
+func TestDocsDirsWritesDirectoryDocsAndKeepsOverview(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "cmd", "app", "main.go"), `package main
+
+import "example.com/demo/store"
+
+func main() {
+	store.LoadUser()
+}
+`)
+	mustWriteFile(t, filepath.Join(root, "store", "user.go"), `package store
+
+func LoadUser() error { return readUser() }
+
+func readUser() error { return nil }
+`)
+	mustWriteFile(t, filepath.Join(root, "store", "cache.go"), `package store
+
+func Warm() {}
+`)
+	mustWriteFile(t, filepath.Join(root, "lonely", "one.go"), `package lonely
+
+func Alone() {}
+`)
+
+	withWorkingDir(t, root, func() {
+		if err := RunGenerate(newGenerateCmdForTest(), []string{"."}); err != nil {
+			t.Fatalf("RunGenerate failed: %v", err)
+		}
+		if err := RunDocsDirs(newDocsDirsCmdForTest(), nil); err != nil {
+			t.Fatalf("RunDocsDirs failed: %v", err)
+		}
+
+		storeDoc := filepath.Join(root, "store", "README.skelly.md")
+		content := mustReadFile(t, storeDoc)
+		for _, want := range []string{
+			"# store",
+			"- `user.go` (go, 2 symbols)",
+			"- `LoadUser` func (`user.go:3`), 1 external calls",
+			"- used by `cmd/app` (1 calls)",
+		} {
+			if !strings.Contains(content, want) {
+				t.Fatalf("expected store doc to contain %q, got:\n%s", want, content)
+			}
+		}
+		appDoc := mustReadFile(t, filepath.Join(root, "cmd", "app", "README.skelly.md"))
+		if !strings.Contains(appDoc, "- depends on `store` (1 calls)") || !strings.Contains(appDoc, "- `main` func") {
+			t.Fatalf("expected entrypoint doc for cmd/app, got:\n%s", appDoc)
+		}
+		assertNotExists(t, filepath.Join(root, "lonely", "README.skelly.md"))
+
+		cmd := newDocsDirsCmdForTest()
+		mustSetFlag(t, cmd, "overview", "Persistence for user records.")
+		if err := RunDocsDirs(cmd, []string{"store"}); err != nil {
+			t.Fatalf("RunDocsDirs with overview failed: %v", err)
+		}
+		if err := RunDocsDirs(newDocsDirsCmdForTest(), nil); err != nil {
+			t.Fatalf("regenerating docs failed: %v", err)
+		}
+		if content := mustReadFile(t, storeDoc); !strings.Contains(content, "Persistence for user records.") {
+			t.Fatalf("expected overview to survive regeneration, got:\n%s", content)
+		}
+
+		// Above --min-files, store keeps its doc for the overview and cmd/app
+		// keeps its doc for the main entrypoint.
+		cmd = newDocsDirsCmdForTest()
+		mustSetFlag(t, cmd, "min-files", "3")
+		if err := RunDocsDirs(cmd, nil); err != nil {
+			t.Fatalf("RunDocsDirs with --min-files failed: %v", err)
+		}
+		assertExists(t, storeDoc)
+		assertExists(t, filepath.Join(root, "cmd", "app", "README.skelly.md"))
+
+		// Generated docs without an overview go away once a directory is no
+		// longer significant.
+		lonelyDoc := filepath.Join(root, "lonely", "README.skelly.md")
+		cmd = newDocsDirsCmdForTest()
+		mustSetFlag(t, cmd, "min-files", "1")
+		if err := RunDocsDirs(cmd, nil); err != nil {
+			t.Fatalf("RunDocsDirs with --min-files=1 failed: %v", err)
+		}
+		assertExists(t, lonelyDoc)
+		if err := RunDocsDirs(newDocsDirsCmdForTest(), nil); err != nil {
+			t.Fatalf("RunDocsDirs failed: %v", err)
+		}
+		assertNotExists(t, lonelyDoc)
+	})
+}
+
+func TestCapabilitiesDescribeCommandsLanguagesAndArtifacts(t *testing.T) {
+	rootCmd := NewRootCommand("1.2.3-test")
+	rootCmd.SetArgs([]string{"capabilities", "--json"})
+	var caps Capabilities
+	stdout := captureStdout(t, func() {
+		if err := rootCmd.Execute(); err != nil {
+			t.Fatalf("capabilities failed: %v", err)
+		}
+	})
+	if err := json.Unmarshal([]byte(stdout), &caps); err != nil {
+		t.Fatalf("failed to decode capabilities: %v\noutput=%s", err, stdout)
+	}
+
+	if caps.Version != "1.2.3-test" || caps.SchemaVersion != CapabilitiesSchemaVersion {
+		t.Fatalf("unexpected version fields: %q %q", caps.Version, caps.SchemaVersion)
+	}
+	commands := make(map[string]CommandCapability, len(caps.Commands))
+	for _, command := range caps.Commands {
+		commands[command.Name] = command
+	}
+	for _, name := range []string{"generate", "trace", "enrich bootstrap", "state migrate", "capabilities"} {
+		if _, ok := commands[name]; !ok {
+			t.Fatalf("expected command %q in capabilities, got %#v", name, caps.Commands)
+		}
+	}
+	if _, ok := commands["setup"]; ok {
+		t.Fatalf("expected hidden setup command to be omitted")
+	}
+	foundDirection := false
+	for _, flag := range commands["trace"].Flags {
+		if flag.Name == "direction" && flag.Type == "string" && flag.Default == "out" {
+			foundDirection = true
+		}
+	}
+	if !foundDirection {
+		t.Fatalf("expected trace --direction flag, got %#v", commands["trace"].Flags)
+	}
+
+	foundGo := false
+	for _, language := range caps.Languages {
+		if language.Name == "go" && containsString(language.Extensions, ".go") {
+			foundGo = true
+		}
+	}
+	if !foundGo {
+		t.Fatalf("expected go language with .go extension, got %#v", caps.Languages)
+	}
+	foundNav := false
+	for _, artifact := range caps.Artifacts {
+		if artifact.Path == ".skelly/.context/nav-index.json" && artifact.SchemaVersion == nav.NavigationIndexVersion {
+			foundNav = true
+		}
+	}
+	if !foundNav || !caps.Features["state_backend_binary"] {
+		t.Fatalf("expected nav-index artifact and binary state feature, got %#v %#v", caps.Artifacts, caps.Features)
+	}
 }
 
-func TestEnrichWritesJSONLForTarget(t *testing.T) {
+func TestExportRendersDOTMermaidAndLSIF(t *testing.T) {
 	root := t.TempDir()
-	mustWriteFile(t, filepath.Join(root, "demo.go"), `package demo
+	mustWriteFile(t, filepath.Join(root, "cmd", "app", "main.go"), `package main
 
-func A() { B() }
-func B() {}
+func main() { Load() }
+`)
+	mustWriteFile(t, filepath.Join(root, "store", "store.go"), `package store
+
+func Load() {}
+`)
+	mustWriteFile(t, filepath.Join(root, "util", "log.go"), `package util
+
+func Log() {}
 `)
 
 	withWorkingDir(t, root, func() {
-		if err := RunInit(&cobra.Command{}, nil); err != nil {
-			t.Fatalf("RunInit failed: %v", err)
-		}
 		if err := RunGenerate(newGenerateCmdForTest(), []string{"."}); err != nil {
 			t.Fatalf("RunGenerate failed: %v", err)
 		}
 
-		enrichCmd := newEnrichCmdForTest()
-		if err := RunEnrich(enrichCmd, []string{"demo.go:A", "Primary entrypoint for demo behavior."}); err != nil {
-			t.Fatalf("RunEnrich failed: %v", err)
+		stdout := captureStdout(t, func() {
+			if err := RunExport(newExportCmdForTest(), nil); err != nil {
+				t.Fatalf("RunExport failed: %v", err)
+			}
+		})
+		if !strings.Contains(stdout, `"cmd/app" -> "store"`) || !strings.Contains(stdout, `"util" [label="util"`) {
+			t.Fatalf("expected module-scope DOT graph, got:\n%s", stdout)
+		}
+
+		cmd := newExportCmdForTest()
+		mustSetFlag(t, cmd, "scope", "file")
+		mustSetFlag(t, cmd, "focus", "store/store.go")
+		mustSetFlag(t, cmd, "depth", "1")
+		mustSetFlag(t, cmd, "output", filepath.Join(root, "graph.dot"))
+		captureStdout(t, func() {
+			if err := RunExport(cmd, nil); err != nil {
+				t.Fatalf("RunExport --focus failed: %v", err)
+			}
+		})
+		dot := mustReadFile(t, filepath.Join(root, "graph.dot"))
+		if !strings.Contains(dot, `"cmd/app/main.go" -> "store/store.go"`) || strings.Contains(dot, "util/log.go") {
+			t.Fatalf("expected focused file graph without util, got:\n%s", dot)
 		}
 
-		enrichPath := filepath.Join(root, output.ContextDir, "enrich.jsonl")
-		assertExists(t, enrichPath)
-
-		data, err := os.ReadFile(enrichPath)
-		if err != nil {
-			t.Fatalf("failed to read enrich output: %v", err)
-		}
-		lines := bytes.Split(bytes.TrimSpace(data), []byte("\n"))
-		if len(lines) != 1 {
-			t.Fatalf("expected 1 enrich record, got %d", len(lines))
+		cmd = newExportCmdForTest()
+		mustSetFlag(t, cmd, "format", "mermaid")
+		mustSetFlag(t, cmd, "top", "2")
+		stdout = captureStdout(t, func() {
+			if err := RunExport(cmd, nil); err != nil {
+				t.Fatalf("RunExport --format mermaid failed: %v", err)
+			}
+		})
+		if !strings.HasPrefix(stdout, "```mermaid\nflowchart LR\n") || strings.Count(stdout, `["`) != 2 {
+			t.Fatalf("expected a two-node Mermaid block, got:\n%s", stdout)
 		}
 
-		var row map[string]any
-		if err := json.Unmarshal(lines[0], &row); err != nil {
-			t.Fatalf("failed to decode enrich record: %v", err)
+		cmd = newExportCmdForTest()
+		mustSetFlag(t, cmd, "format", "lsif")
+		mustSetFlag(t, cmd, "output", filepath.Join(root, "dump.lsif"))
+		captureStdout(t, func() {
+			if err := RunExport(cmd, nil); err != nil {
+				t.Fatalf("RunExport --format lsif failed: %v", err)
+			}
+		})
+		lsif := mustReadFile(t, filepath.Join(root, "dump.lsif"))
+		if !strings.Contains(lsif, `"label":"metaData"`) || !strings.Contains(lsif, `"scheme":"skelly"`) || !strings.Contains(lsif, `"label":"textDocument/references"`) {
+			t.Fatalf("expected LSIF dump with monikers and references, got:\n%s", lsif)
 		}
-		if row["symbol_id"] == "" {
-			t.Fatalf("expected enrich record to include symbol_id")
+
+		cmd = newExportCmdForTest()
+		mustSetFlag(t, cmd, "format", "lsif")
+		mustSetFlag(t, cmd, "top", "2")
+		if err := RunExport(cmd, nil); err == nil || !strings.Contains(err.Error(), "do not apply to --format lsif") {
+			t.Fatalf("expected --top to be rejected for lsif, got %v", err)
 		}
-		symbolID, _ := row["symbol_id"].(string)
-		if !strings.Contains(symbolID, "|A|") {
-			t.Fatalf("expected targeted enrich record for symbol A, got symbol_id=%q", symbolID)
+
+		cmd = newExportCmdForTest()
+		mustSetFlag(t, cmd, "scope", "package")
+		if err := RunExport(cmd, nil); err == nil || !strings.Contains(err.Error(), "unsupported scope") {
+			t.Fatalf("expected unsupported scope error, got %v", err)
 		}
-		outputValue, ok := row["output"].(map[string]any)
-		if !ok {
-			t.Fatalf("expected enrich output payload")
+	})
+}
+
+func TestExportLangFilterDropsOtherLanguagesAndReportsCuts(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "proto", "invoice.proto"), "syntax = \"proto3\";\n\nmessage Invoice {\n  string id = 1;\n}\n")
+	mustWriteFile(t, filepath.Join(root, "gen", "invoice.pb.go"), "package gen\n\ntype Invoice struct{}\n")
+	mustWriteFile(t, filepath.Join(root, "app", "app.go"), "package app\n\nfunc Run() {}\n")
+
+	withWorkingDir(t, root, func() {
+		captureStdout(t, func() {
+			if err := RunGenerate(newGenerateCmdForTest(), []string{"."}); err != nil {
+				t.Fatalf("RunGenerate failed: %v", err)
+			}
+		})
+
+		cmd := newExportCmdForTest()
+		mustSetFlag(t, cmd, "scope", "file")
+		mustSetFlag(t, cmd, "lang", "go")
+		origStderr := os.Stderr
+		r, w, err := os.Pipe()
+		if err != nil {
+			t.Fatalf("failed to create pipe: %v", err)
 		}
-		if outputValue["summary"] != "Primary entrypoint for demo behavior." {
-			t.Fatalf("unexpected output.summary: %v", outputValue["summary"])
+		os.Stderr = w
+		var runErr error
+		stdout := captureStdout(t, func() { runErr = RunExport(cmd, nil) })
+		w.Close()
+		os.Stderr = origStderr
+		var stderr bytes.Buffer
+		_, _ = io.Copy(&stderr, r)
+
+		if runErr != nil {
+			t.Fatalf("RunExport --lang failed: %v", runErr)
 		}
-		if outputValue["purpose"] != "Primary entrypoint for demo behavior." {
-			t.Fatalf("expected purpose default to description, got: %v", outputValue["purpose"])
+		if strings.Contains(stdout, "invoice.proto") || !strings.Contains(stdout, "gen/invoice.pb.go") {
+			t.Fatalf("expected only Go files in the export, got:\n%s", stdout)
 		}
-		if outputValue["confidence"] != "medium" {
-			t.Fatalf("expected confidence=medium, got: %v", outputValue["confidence"])
+		if !strings.Contains(stderr.String(), "kept 2 of 3 files") || !strings.Contains(stderr.String(), "language boundary cuts (1):") || !strings.Contains(stderr.String(), "[proto]") {
+			t.Fatalf("expected the cut to the proto declaration on stderr, got:\n%s", stderr.String())
 		}
 	})
 }
 
-func TestEnrichRequiresDescription(t *testing.T) {
+func TestDiffComparesGitRevisionsAndContextDirs(t *testing.T) {
 	root := t.TempDir()
-	mustWriteFile(t, filepath.Join(root, "demo.go"), `package demo
+	mustWriteFile(t, filepath.Join(root, "app.go"), `package app
 
-func A() {}
+func Run() { load() }
+func load() {}
+func Close() {}
 `)
+	mustGit(t, root, "init", "-q")
+	commit := func(message string) {
+		mustGit(t, root, "add", "-A")
+		mustGit(t, root, "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", message)
+	}
+	commit("v1")
+	mustWriteFile(t, filepath.Join(root, "app.go"), `package app
+
+func Run(strict bool) { load(); Check(strict) }
+func load() {}
+func Check(strict bool) error { return nil }
+`)
+	commit("v2")
 
 	withWorkingDir(t, root, func() {
-		if err := RunInit(newInitCmdForTest(), nil); err != nil {
-			t.Fatalf("RunInit failed: %v", err)
+		cmd := newDiffCmdForTest()
+		mustSetFlag(t, cmd, "json", "true")
+		stdout := captureStdout(t, func() {
+			if err := RunDiff(cmd, []string{"HEAD~1", "HEAD"}); err != nil {
+				t.Fatalf("RunDiff failed: %v", err)
+			}
+		})
+		var report snapshot.ChangeReport
+		if err := json.Unmarshal([]byte(stdout), &report); err != nil {
+			t.Fatalf("failed to decode diff JSON: %v\n%s", err, stdout)
+		}
+		if report.Before != "HEAD~1" || report.After != "HEAD" {
+			t.Fatalf("expected revisions as labels, got %q -> %q", report.Before, report.After)
+		}
+		if len(report.Added) != 1 || report.Added[0].Name != "Check" {
+			t.Fatalf("expected Check to be added, got %#v", report.Added)
+		}
+		if len(report.Removed) != 1 || report.Removed[0].Name != "Close" {
+			t.Fatalf("expected Close to be removed, got %#v", report.Removed)
+		}
+		if len(report.SignatureChanged) != 1 || report.SignatureChanged[0].Name != "Run" {
+			t.Fatalf("expected Run signature change, got %#v", report.SignatureChanged)
 		}
+		if len(report.EdgesAdded) != 1 || report.EdgesAdded[0] != (snapshot.EdgeChange{From: "app.go:Run", To: "app.go:Check"}) {
+			t.Fatalf("expected Run -> Check edge, got %#v", report.EdgesAdded)
+		}
+		if worktrees, err := gitLines(root, "worktree", "list"); err != nil || len(worktrees) != 1 {
+			t.Fatalf("expected temporary worktrees to be removed, got %v (%v)", worktrees, err)
+		}
+
 		if err := RunGenerate(newGenerateCmdForTest(), []string{"."}); err != nil {
 			t.Fatalf("RunGenerate failed: %v", err)
 		}
-
-		enrichCmd := newEnrichCmdForTest()
-		err := RunEnrich(enrichCmd, []string{"demo.go:A"})
-		if err == nil {
-			t.Fatalf("expected RunEnrich to fail without description")
+		stdout = captureStdout(t, func() {
+			if err := RunDiff(newDiffCmdForTest(), []string{root, filepath.Join(root, output.ContextDir)}); err != nil {
+				t.Fatalf("RunDiff on context dirs failed: %v", err)
+			}
+		})
+		if !strings.Contains(stdout, "added=0 removed=0 renamed=0 signature_changed=0 edges added=0 removed=0") {
+			t.Fatalf("expected an empty diff for the same context, got:\n%s", stdout)
 		}
-		if !strings.Contains(err.Error(), "description is required") {
-			t.Fatalf("unexpected error: %v", err)
+
+		if err := RunDiff(newDiffCmdForTest(), []string{"no-such-rev", "HEAD"}); err == nil || !strings.Contains(err.Error(), "neither a context directory nor a git revision") {
+			t.Fatalf("expected unknown target error, got %v", err)
 		}
 	})
 }
 
-func TestEnrichRejectsAmbiguousTarget(t *testing.T) {
+func TestReportPRSummarizesBranchAgainstMergeBase(t *testing.T) {
 	root := t.TempDir()
-	mustWriteFile(t, filepath.Join(root, "demo.go"), `package demo
+	mustWriteFile(t, filepath.Join(root, "api", "handler.go"), `package api
 
-func A() {}
-func B() {}
+import "example.com/app/store"
+
+func Handle() { store.Save() }
+`)
+	mustWriteFile(t, filepath.Join(root, "store", "store.go"), `package store
+
+func Save() {}
+func Purge() {}
+`)
+	mustWriteFile(t, filepath.Join(root, "CODEOWNERS"), "/api/ @api\n/store/ @store\n")
+	mustGit(t, root, "init", "-q")
+	commit := func(message string) {
+		mustGit(t, root, "add", "-A")
+		mustGit(t, root, "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", message)
+	}
+	commit("base")
+	mustGit(t, root, "branch", "base")
+	mustWriteFile(t, filepath.Join(root, "store", "store.go"), `package store
+
+func Save(force bool) { validate() }
+func validate() {}
+func Purge() {}
+func Count() int { return 0 }
 `)
+	commit("feature")
 
 	withWorkingDir(t, root, func() {
-		if err := RunInit(newInitCmdForTest(), nil); err != nil {
-			t.Fatalf("RunInit failed: %v", err)
-		}
-		if err := RunGenerate(newGenerateCmdForTest(), []string{"."}); err != nil {
-			t.Fatalf("RunGenerate failed: %v", err)
+		cmd := newReportCmdForTest()
+		mustSetFlag(t, cmd, "pr", "true")
+		mustSetFlag(t, cmd, "since", "base")
+		markdown := captureStdout(t, func() {
+			if err := RunReport(cmd, nil); err != nil {
+				t.Fatalf("RunReport failed: %v", err)
+			}
+		})
+		for _, want := range []string{
+			"<!-- skelly-pr-report -->",
+			"### New Symbols",
+			"`Count` func (`store/store.go:6`)",
+			"`func Save()` -> `func Save(force bool)`",
+			"- **@api** (1): `api/handler.go`",
+			"added call `store/store.go:Save` -> `store/store.go:validate`",
+		} {
+			if !strings.Contains(markdown, want) {
+				t.Fatalf("expected %q in PR report, got:\n%s", want, markdown)
+			}
 		}
 
-		enrichCmd := newEnrichCmdForTest()
-		err := RunEnrich(enrichCmd, []string{"demo.go", "desc"})
-		if err == nil {
-			t.Fatalf("expected ambiguous target error")
+		mustSetFlag(t, cmd, "json", "true")
+		var report PRReport
+		if err := json.Unmarshal([]byte(captureStdout(t, func() {
+			if err := RunReport(cmd, nil); err != nil {
+				t.Fatalf("RunReport --json failed: %v", err)
+			}
+		})), &report); err != nil {
+			t.Fatalf("failed to decode PR report: %v", err)
 		}
-		if !strings.Contains(err.Error(), "matched") {
-			t.Fatalf("unexpected error: %v", err)
+		if report.Base != "base" || report.BaseCommit == "" || report.Codeowners != "CODEOWNERS" ||
+			!reflect.DeepEqual(report.ChangedFiles, []string{"store/store.go"}) ||
+			!reflect.DeepEqual(report.Impacted, []string{"api/handler.go", "store/store.go"}) ||
+			len(report.Symbols.Added) != 2 || len(report.Owners) != 2 {
+			t.Fatalf("unexpected PR report: %+v", report)
+		}
+
+		if err := RunReport(newReportCmdForTest(), nil); err == nil || !strings.Contains(err.Error(), "requires --pr") {
+			t.Fatalf("expected --pr to be required, got %v", err)
 		}
 	})
 }
@@ -887,7 +6276,12 @@ func newGenerateCmdForTest() *cobra.Command {
 	cmd := &cobra.Command{}
 	cmd.Flags().StringSlice("lang", []string{}, "")
 	cmd.Flags().String("format", "text", "")
+	cmd.Flags().String("order", "importance", "")
 	cmd.Flags().Bool("json", false, "")
+	cmd.Flags().String("state-backend", "", "")
+	cmd.Flags().Bool("no-gitignore", false, "")
+	cmd.Flags().Bool("all-roots", false, "")
+	cmd.Flags().String("compress", "", "")
 	return cmd
 }
 
@@ -895,6 +6289,7 @@ func newInitCmdForTest() *cobra.Command {
 	cmd := &cobra.Command{}
 	cmd.Flags().String("llm", "", "")
 	cmd.Flags().Bool("no-generate", false, "")
+	cmd.Flags().Bool("refresh", false, "")
 	cmd.Flags().String("format", "text", "")
 	return cmd
 }
@@ -902,13 +6297,112 @@ func newInitCmdForTest() *cobra.Command {
 func newUpdateCmdForTest() *cobra.Command {
 	cmd := &cobra.Command{}
 	cmd.Flags().Bool("explain", false, "")
-	cmd.Flags().String("format", "text", "")
+	cmd.Flags().String("format", "", "")
+	cmd.Flags().String("order", "importance", "")
+	cmd.Flags().Bool("json", false, "")
+	cmd.Flags().Bool("quick", false, "")
+	cmd.Flags().String("state-backend", "", "")
+	cmd.Flags().String("since", "", "")
+	cmd.Flags().Bool("no-gitignore", false, "")
+	cmd.Flags().StringArray("exec", nil, "")
+	cmd.Flags().String("compress", "", "")
+	return cmd
+}
+
+func newDiffCmdForTest() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Flags().Bool("json", false, "")
+	return cmd
+}
+
+func newReportCmdForTest() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Flags().Bool("pr", false, "")
+	cmd.Flags().String("since", "origin/main", "")
+	cmd.Flags().String("head", "HEAD", "")
 	cmd.Flags().Bool("json", false, "")
+	cmd.Flags().StringP("output", "o", "", "")
 	return cmd
 }
 
 func newEnrichCmdForTest() *cobra.Command {
 	cmd := &cobra.Command{}
+	cmd.Flags().Int("max-body-bytes", enrich.DefaultMaxBodyBytes, "")
+	cmd.Flags().String("agent", defaultEnrichAgent, "")
+	cmd.Flags().Bool("json", false, "")
+	return cmd
+}
+
+func newEnrichBootstrapCmdForTest() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Flags().Int("min-words", 5, "")
+	cmd.Flags().Bool("dry-run", false, "")
+	cmd.Flags().StringArray("path", nil, "")
+	cmd.Flags().StringArray("symbol", nil, "")
+	cmd.Flags().StringSlice("kind", []string{}, "")
+	cmd.Flags().StringSlice("visibility", []string{}, "")
+	cmd.Flags().String("order", "path", "")
+	cmd.Flags().Int("limit", 0, "")
+	cmd.Flags().Int("max-body-bytes", enrich.DefaultMaxBodyBytes, "")
+	cmd.Flags().Bool("json", false, "")
+	return cmd
+}
+
+func newConventionsCmdForTest() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Flags().StringArray("note", nil, "")
+	cmd.Flags().Bool("json", false, "")
+	return cmd
+}
+
+func newDeadcodeCmdForTest() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Flags().StringArray("allow", nil, "")
+	cmd.Flags().StringSlice("visibility", []string{}, "")
+	cmd.Flags().Bool("json", false, "")
+	return cmd
+}
+
+func newLanguagesCmdForTest() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Flags().Int("limit", 10, "")
+	cmd.Flags().Bool("json", false, "")
+	return cmd
+}
+
+func newIssuesCmdForTest() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Flags().String("severity", "", "")
+	cmd.Flags().StringSlice("lang", []string{}, "")
+	cmd.Flags().String("file", "", "")
+	cmd.Flags().Bool("json", false, "")
+	return cmd
+}
+
+func newDocsDirsCmdForTest() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Flags().Int("min-files", 2, "")
+	cmd.Flags().String("overview", "", "")
+	cmd.Flags().Bool("dry-run", false, "")
+	cmd.Flags().Bool("json", false, "")
+	return cmd
+}
+
+func newExportCmdForTest() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Flags().String("format", "dot", "")
+	cmd.Flags().String("scope", "module", "")
+	cmd.Flags().String("focus", "", "")
+	cmd.Flags().Int("depth", 2, "")
+	cmd.Flags().Int("top", 0, "")
+	cmd.Flags().String("output", "", "")
+	cmd.Flags().StringSlice("lang", []string{}, "")
+	return cmd
+}
+
+func newAliasesPruneCmdForTest() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Flags().Duration("older-than", 0, "")
 	cmd.Flags().Bool("json", false, "")
 	return cmd
 }
@@ -919,6 +6413,17 @@ func newSetupCmdForTest() *cobra.Command {
 	return cmd
 }
 
+func newCICmdForTest() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Flags().StringSliceP("lang", "l", []string{}, "")
+	cmd.Flags().String("format", string(output.FormatText), "")
+	cmd.Flags().String("order", string(output.OrderImportance), "")
+	cmd.Flags().Bool("no-gitignore", false, "")
+	cmd.Flags().Bool("no-diff", false, "")
+	cmd.Flags().Bool("json", false, "")
+	return cmd
+}
+
 func newDoctorCmdForTest() *cobra.Command {
 	cmd := &cobra.Command{}
 	cmd.Flags().Bool("json", false, "")
@@ -930,6 +6435,8 @@ func newSymbolCmdForTest() *cobra.Command {
 	cmd.Flags().Bool("json", false, "")
 	cmd.Flags().Bool("fuzzy", false, "")
 	cmd.Flags().Int("limit", 10, "")
+	cmd.Flags().Bool("with-summary", false, "")
+	cmd.Flags().Bool("include-external", false, "")
 	return cmd
 }
 
@@ -937,6 +6444,16 @@ func newCallersCmdForTest() *cobra.Command {
 	cmd := &cobra.Command{}
 	cmd.Flags().Bool("json", false, "")
 	cmd.Flags().Bool("lsp", false, "")
+	cmd.Flags().StringSlice("kind", []string{}, "")
+	cmd.Flags().Bool("include-references", false, "")
+	cmd.Flags().Bool("include-external", false, "")
+	cmd.Flags().Bool("with-summary", false, "")
+	return cmd
+}
+
+func newImplementationsCmdForTest() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Flags().Bool("json", false, "")
 	return cmd
 }
 
@@ -944,21 +6461,110 @@ func newCalleesCmdForTest() *cobra.Command {
 	cmd := &cobra.Command{}
 	cmd.Flags().Bool("json", false, "")
 	cmd.Flags().Bool("lsp", false, "")
+	cmd.Flags().StringSlice("kind", []string{}, "")
+	cmd.Flags().Bool("include-external", false, "")
+	cmd.Flags().Bool("with-summary", false, "")
 	return cmd
 }
 
 func newTraceCmdForTest() *cobra.Command {
 	cmd := &cobra.Command{}
 	cmd.Flags().Int("depth", 2, "")
+	cmd.Flags().String("direction", "out", "")
 	cmd.Flags().Bool("json", false, "")
 	cmd.Flags().Bool("lsp", false, "")
+	cmd.Flags().StringSlice("lang", []string{}, "")
+	cmd.Flags().StringSlice("kind", []string{}, "")
+	cmd.Flags().Bool("include-external", false, "")
+	cmd.Flags().Bool("with-summary", false, "")
 	return cmd
 }
 
 func newPathCmdForTest() *cobra.Command {
 	cmd := &cobra.Command{}
+	cmd.Flags().Bool("all", false, "")
+	cmd.Flags().Int("max-depth", nav.DefaultPathMaxDepth, "")
+	cmd.Flags().Int("limit", nav.DefaultPathLimit, "")
 	cmd.Flags().Bool("json", false, "")
 	cmd.Flags().Bool("lsp", false, "")
+	cmd.Flags().StringSlice("lang", []string{}, "")
+	cmd.Flags().StringSlice("kind", []string{}, "")
+	cmd.Flags().Bool("include-external", false, "")
+	return cmd
+}
+
+func newSearchCmdForTest() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Flags().String("signature", "", "")
+	cmd.Flags().Bool("regex", false, "")
+	cmd.Flags().String("semantic", "", "")
+	cmd.Flags().StringSlice("kind", []string{}, "")
+	cmd.Flags().String("file", "", "")
+	cmd.Flags().StringSlice("visibility", []string{}, "")
+	cmd.Flags().Bool("include-external", false, "")
+	cmd.Flags().Int("limit", 50, "")
+	cmd.Flags().String("sort", "", "")
+	addEmbedProviderFlags(cmd)
+	cmd.Flags().Bool("json", false, "")
+	return cmd
+}
+
+func newEnrichEmbedCmdForTest() *cobra.Command {
+	cmd := &cobra.Command{}
+	addEmbedProviderFlags(cmd)
+	cmd.Flags().Int("batch", 64, "")
+	cmd.Flags().Bool("json", false, "")
+	return cmd
+}
+
+func newGrepCmdForTest() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Flags().String("call", "", "")
+	cmd.Flags().Int("min-args", 0, "")
+	cmd.Flags().Int("min-params", 0, "")
+	cmd.Flags().String("query", "", "")
+	cmd.Flags().StringSlice("lang", []string{}, "")
+	cmd.Flags().String("file", "", "")
+	cmd.Flags().Int("limit", 100, "")
+	cmd.Flags().Bool("json", false, "")
+	return cmd
+}
+
+func newRoutesCmdForTest() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Flags().String("method", "", "")
+	cmd.Flags().Bool("json", false, "")
+	return cmd
+}
+
+func newTestsForCmdForTest() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Flags().Int("depth", 2, "")
+	cmd.Flags().Bool("json", false, "")
+	return cmd
+}
+
+func newPackCmdForTest() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Flags().Int("budget", 8000, "")
+	cmd.Flags().String("focus", "", "")
+	cmd.Flags().Bool("no-git", true, "")
+	cmd.Flags().String("sort", "pagerank", "")
+	cmd.Flags().Bool("json", false, "")
+	return cmd
+}
+
+func newOwnersCmdForTest() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Flags().Bool("json", false, "")
+	return cmd
+}
+
+func newRelatedCmdForTest() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Flags().Int("limit", 10, "")
+	cmd.Flags().Bool("git", false, "")
+	cmd.Flags().Bool("json", false, "")
 	return cmd
 }
 
@@ -1265,6 +6871,15 @@ func Hello() {}
 	})
 }
 
+func mustReadFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read file %s: %v", path, err)
+	}
+	return string(data)
+}
+
 func mustWriteFile(t *testing.T, path, content string) {
 	t.Helper()
 	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
@@ -1274,3 +6889,12 @@ func mustWriteFile(t *testing.T, path, content string) {
 		t.Fatalf("failed to write file %s: %v", path, err)
 	}
 }
+
+func mustGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	gitCmd := exec.Command("git", args...)
+	gitCmd.Dir = dir
+	if out, err := gitCmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %s failed: %v\n%s", strings.Join(args, " "), err, out)
+	}
+}
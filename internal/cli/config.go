@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/morozRed/skelly/internal/config"
+	"github.com/morozRed/skelly/internal/output"
+	"github.com/spf13/cobra"
+)
+
+// ApplyProjectConfig loads .skelly/config.yaml under rootPath and sets each
+// flag it configures on cmd, unless the user passed that flag explicitly.
+// Flags cmd does not define are skipped. The loaded config is returned for
+// settings that have no flag on cmd.
+func ApplyProjectConfig(cmd *cobra.Command, rootPath string) (config.Config, error) {
+	cfg, err := config.Load(rootPath)
+	if err != nil {
+		return config.Config{}, err
+	}
+	if cmd == nil {
+		return cfg, nil
+	}
+
+	defaults := cfg.FlagDefaults()
+	names := make([]string, 0, len(defaults))
+	for name := range defaults {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		flag := cmd.Flags().Lookup(name)
+		if flag == nil || flag.Changed {
+			continue
+		}
+		for _, value := range defaults[name] {
+			if err := cmd.Flags().Set(name, value); err != nil {
+				return config.Config{}, fmt.Errorf("invalid %s value for --%s: %w", config.File, name, err)
+			}
+		}
+	}
+	return cfg, nil
+}
+
+// withProjectConfig applies .skelly/config.yaml to cmd's flags before run,
+// for commands implemented outside this package.
+func withProjectConfig(run func(*cobra.Command, []string) error) func(*cobra.Command, []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		rootPath, err := resolveWorkingDirectory()
+		if err != nil {
+			return err
+		}
+		if _, err := ApplyProjectConfig(cmd, rootPath); err != nil {
+			return err
+		}
+		return run(cmd, args)
+	}
+}
+
+// applyOutputDir moves output.ContextDir to output_dir from the config under
+// rootPath, so every command of the run reads and writes the same context.
+func applyOutputDir(rootPath string) error {
+	cfg, err := config.Load(rootPath)
+	if err != nil {
+		return err
+	}
+	output.SetContextDir(cfg.OutputDir)
+	return nil
+}
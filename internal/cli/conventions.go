@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/morozRed/skelly/internal/conventions"
+	"github.com/morozRed/skelly/internal/fileutil"
+	"github.com/morozRed/skelly/internal/output"
+	"github.com/morozRed/skelly/internal/state"
+	"github.com/spf13/cobra"
+)
+
+func RunConventions(cmd *cobra.Command, args []string) error {
+	rootPath, err := resolveWorkingDirectory()
+	if err != nil {
+		return err
+	}
+	asJSON, err := cmd.Flags().GetBool("json")
+	if err != nil {
+		return fmt.Errorf("failed to read --json flag: %w", err)
+	}
+	newNotes, err := OptionalStringSliceFlag(cmd, "note")
+	if err != nil {
+		return err
+	}
+
+	contextDir := filepath.Join(rootPath, output.ContextDir)
+	st, err := state.Load(contextDir)
+	if err != nil {
+		if IsCorruptStateError(err) {
+			return fmt.Errorf("state is corrupt; run `skelly generate` first")
+		}
+		return fmt.Errorf("failed to load state: %w", err)
+	}
+	if len(st.Files) == 0 {
+		return fmt.Errorf("no indexed files found; run `skelly generate` first")
+	}
+
+	conventionsPath := filepath.Join(rootPath, output.SkellyDir, conventions.File)
+	existingNotes := make([]string, 0)
+	if data, err := os.ReadFile(conventionsPath); err == nil {
+		existingNotes = conventions.ReadNotes(string(data))
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read %s: %w", conventionsPath, err)
+	}
+
+	report := conventions.Analyze(rootPath, st)
+	// Notes come from an agent or reviewer pass and survive regeneration.
+	report.Notes = appendUniqueNotes(existingNotes, newNotes)
+
+	if err := os.MkdirAll(filepath.Dir(conventionsPath), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", output.SkellyDir, err)
+	}
+	changed, err := fileutil.WriteIfChangedTracked(conventionsPath, []byte(conventions.Render(report)))
+	if err != nil {
+		return err
+	}
+
+	relPath := filepath.ToSlash(filepath.Join(output.SkellyDir, conventions.File))
+	if asJSON {
+		return fileutil.PrintJSON(map[string]any{
+			"mode":        "conventions",
+			"output_file": relPath,
+			"changed":     changed,
+			"report":      report,
+		})
+	}
+
+	status := "unchanged"
+	if changed {
+		status = "wrote"
+	}
+	fmt.Printf("%s %s (%d naming, %d directories, %d error idioms, %d test layouts, %d notes)\n",
+		status, relPath, len(report.Naming), len(report.Directories), len(report.ErrorHandling), len(report.Tests), len(report.Notes))
+	return nil
+}
+
+func appendUniqueNotes(existing, added []string) []string {
+	seen := make(map[string]bool, len(existing)+len(added))
+	out := make([]string, 0, len(existing)+len(added))
+	for _, note := range append(append([]string(nil), existing...), added...) {
+		// Notes render as single markdown bullets.
+		note = strings.Join(strings.Fields(note), " ")
+		if note == "" || seen[note] {
+			continue
+		}
+		seen[note] = true
+		out = append(out, note)
+	}
+	return out
+}
@@ -0,0 +1,227 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/morozRed/skelly/internal/daemon"
+	"github.com/morozRed/skelly/internal/fileutil"
+	"github.com/morozRed/skelly/internal/nav"
+	"github.com/morozRed/skelly/internal/output"
+	"github.com/spf13/cobra"
+)
+
+// DaemonDisableEnv makes the CLI run every command itself even when a daemon
+// is running.
+const DaemonDisableEnv = "SKELLY_NO_DAEMON"
+
+const daemonPollInterval = 25 * time.Millisecond
+
+// daemonCommands are the read-only queries a running daemon answers from its
+// warm indexes. They flush a running write-behind watch before reading.
+var daemonCommands = map[string]bool{
+	"symbol":          true,
+	"callers":         true,
+	"callees":         true,
+	"implementations": true,
+	"trace":           true,
+	"path":            true,
+	"definition":      true,
+	"references":      true,
+	"search":          true,
+	"grep":            true,
+	"routes":          true,
+	"tests-for":       true,
+	"related":         true,
+	"pack":            true,
+}
+
+// Delegate runs a query command through the daemon serving the working
+// directory, if one is running, and reports whether it did. Anything the
+// daemon cannot answer falls back to running in-process.
+func Delegate(version string, args []string) (int, bool) {
+	if os.Getenv(DaemonDisableEnv) != "" {
+		return 0, false
+	}
+	if checkDaemonCommand(version, args) != nil {
+		return 0, false
+	}
+	rootPath, err := resolveWorkingDirectory()
+	if err != nil {
+		return 0, false
+	}
+	response, err := daemon.Call(filepath.Join(rootPath, output.ContextDir), daemon.Request{Op: daemon.OpRun, Args: args, Dir: rootPath})
+	if err != nil {
+		return 0, false
+	}
+	fmt.Fprint(os.Stdout, response.Stdout)
+	fmt.Fprint(os.Stderr, response.Stderr)
+	return response.ExitCode, true
+}
+
+// checkDaemonCommand returns an error unless args run one of daemonCommands.
+func checkDaemonCommand(version string, args []string) error {
+	root := NewRootCommand(version)
+	cmd, _, err := root.Find(args)
+	if err != nil {
+		return err
+	}
+	if cmd.Parent() != root || !daemonCommands[cmd.Name()] {
+		return fmt.Errorf("the daemon does not run %q; only read-only queries are served", cmd.CommandPath())
+	}
+	return nil
+}
+
+// RunDaemonStart starts `skelly daemon run` in the background and waits for
+// it to answer.
+func RunDaemonStart(cmd *cobra.Command, args []string) error {
+	rootPath, err := resolveWorkingDirectory()
+	if err != nil {
+		return err
+	}
+	timeout, err := cmd.Flags().GetDuration("timeout")
+	if err != nil {
+		return fmt.Errorf("failed to read --timeout flag: %w", err)
+	}
+	contextDir := filepath.Join(rootPath, output.ContextDir)
+	if response, err := daemon.Call(contextDir, daemon.Request{Op: daemon.OpStatus}); err == nil {
+		fmt.Printf("skelly daemon already running (pid %d)\n", response.Status.PID)
+		return nil
+	}
+	if _, err := os.Stat(fileutil.ArtifactPath(filepath.Join(contextDir, nav.NavigationIndexFile))); err != nil {
+		return fmt.Errorf("navigation index missing (run skelly generate)")
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate the skelly binary: %w", err)
+	}
+	logPath := filepath.Join(contextDir, daemon.LogFile)
+	logFile, err := os.OpenFile(logPath, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open daemon log: %w", err)
+	}
+	defer logFile.Close()
+	process := exec.Command(executable, "daemon", "run")
+	process.Dir = rootPath
+	process.Stdout = logFile
+	process.Stderr = logFile
+	daemon.Detach(process)
+	if err := process.Start(); err != nil {
+		return fmt.Errorf("failed to start daemon: %w", err)
+	}
+	exited := make(chan error, 1)
+	go func() { exited <- process.Wait() }()
+
+	deadline := time.Now().Add(timeout)
+	for {
+		if response, err := daemon.Call(contextDir, daemon.Request{Op: daemon.OpStatus}); err == nil {
+			fmt.Printf("skelly daemon started (pid %d, socket %s)\n", response.Status.PID, daemon.SocketPath(contextDir))
+			return nil
+		}
+		select {
+		case <-exited:
+			return fmt.Errorf("daemon exited during startup; see %s", logPath)
+		default:
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out after %s waiting for the daemon; see %s", timeout, logPath)
+		}
+		time.Sleep(daemonPollInterval)
+	}
+}
+
+// RunDaemonRun serves query commands in the foreground until interrupted or
+// stopped, keeping the navigation and search indexes decoded in memory.
+func RunDaemonRun(cmd *cobra.Command, version string) error {
+	rootPath, err := resolveWorkingDirectory()
+	if err != nil {
+		return err
+	}
+	nav.EnableWarmIndexes()
+	// Warm the indexes now so the first query is as fast as the rest.
+	if _, err := nav.LoadLookup(rootPath); err != nil {
+		return err
+	}
+	if _, err := nav.LoadSearchIndex(rootPath); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: search index not loaded: %v\n", err)
+	}
+
+	allow := func(args []string) error { return checkDaemonCommand(version, args) }
+	server := daemon.NewServer(rootPath, filepath.Join(rootPath, output.ContextDir), allow, func(args []string) error {
+		root := NewRootCommand(version)
+		root.SetArgs(args)
+		return root.Execute()
+	})
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	fmt.Printf("skelly daemon serving %s (pid %d)\n", rootPath, os.Getpid())
+	if err := server.Serve(ctx); err != nil {
+		return err
+	}
+	fmt.Println("skelly daemon stopped")
+	return nil
+}
+
+// RunDaemonStop asks the daemon to exit and waits until its socket is gone.
+func RunDaemonStop(cmd *cobra.Command, args []string) error {
+	rootPath, err := resolveWorkingDirectory()
+	if err != nil {
+		return err
+	}
+	contextDir := filepath.Join(rootPath, output.ContextDir)
+	response, err := daemon.Call(contextDir, daemon.Request{Op: daemon.OpStop})
+	if err != nil {
+		return err
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if _, err := os.Stat(daemon.SocketPath(contextDir)); os.IsNotExist(err) {
+			break
+		}
+		time.Sleep(daemonPollInterval)
+	}
+	fmt.Printf("skelly daemon stopped (pid %d, served %d commands)\n", response.Status.PID, response.Status.Served)
+	return nil
+}
+
+// RunDaemonStatus reports whether a daemon serves the working directory.
+func RunDaemonStatus(cmd *cobra.Command, args []string) error {
+	rootPath, err := resolveWorkingDirectory()
+	if err != nil {
+		return err
+	}
+	asJSON, err := cmd.Flags().GetBool("json")
+	if err != nil {
+		return fmt.Errorf("failed to read --json flag: %w", err)
+	}
+	response, err := daemon.Call(filepath.Join(rootPath, output.ContextDir), daemon.Request{Op: daemon.OpStatus})
+	running := err == nil
+	if err != nil && !errors.Is(err, daemon.ErrNotRunning) {
+		return err
+	}
+
+	if asJSON {
+		payload := map[string]any{"running": running}
+		if running {
+			payload["daemon"] = response.Status
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(payload)
+	}
+	if !running {
+		fmt.Println("skelly daemon not running")
+		return nil
+	}
+	fmt.Printf("skelly daemon running (pid %d, since %s, served %d commands)\n", response.Status.PID, response.Status.StartedAt, response.Status.Served)
+	return nil
+}
@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/morozRed/skelly/internal/config"
+	"github.com/morozRed/skelly/internal/deadcode"
+	"github.com/morozRed/skelly/internal/fileutil"
+	"github.com/morozRed/skelly/internal/graph"
+	"github.com/morozRed/skelly/internal/output"
+	"github.com/morozRed/skelly/internal/parser"
+	"github.com/morozRed/skelly/internal/state"
+	"github.com/spf13/cobra"
+)
+
+// RunDeadcode lists the public symbols nothing in the index uses. Entries
+// from --allow and the deadcode.allow config list are kept out of the report;
+// --visibility checks other visibilities instead.
+func RunDeadcode(cmd *cobra.Command, args []string) error {
+	rootPath, err := resolveWorkingDirectory()
+	if err != nil {
+		return err
+	}
+	asJSON, err := cmd.Flags().GetBool("json")
+	if err != nil {
+		return fmt.Errorf("failed to read --json flag: %w", err)
+	}
+	allow, err := OptionalStringSliceFlag(cmd, "allow")
+	if err != nil {
+		return err
+	}
+	visibilityValues, err := cmd.Flags().GetStringSlice("visibility")
+	if err != nil {
+		return fmt.Errorf("failed to read --visibility flag: %w", err)
+	}
+	visibilities, err := parser.ParseVisibilities(visibilityValues)
+	if err != nil {
+		return fmt.Errorf("--visibility: %w", err)
+	}
+	cfg, err := config.Load(rootPath)
+	if err != nil {
+		return err
+	}
+
+	contextDir := filepath.Join(rootPath, output.ContextDir)
+	st, err := state.Load(contextDir)
+	if err != nil {
+		if IsCorruptStateError(err) {
+			return fmt.Errorf("state is corrupt; run `skelly generate` first")
+		}
+		return fmt.Errorf("failed to load state: %w", err)
+	}
+	if len(st.Files) == 0 {
+		return fmt.Errorf("no indexed files found; run `skelly generate` first")
+	}
+
+	hashes := make(map[string]string, len(st.Files))
+	for file, fileState := range st.Files {
+		hashes[file] = fileState.Hash
+	}
+	g := graph.BuildFromParseResult(fileutil.ParseResultFromState(st, rootPath, hashes))
+	report := deadcode.Find(g, deadcode.NewAllowlist(append(cfg.Deadcode.Allow, allow...)), visibilities)
+
+	if asJSON {
+		return fileutil.PrintJSON(map[string]any{
+			"mode":   "deadcode",
+			"report": report,
+		})
+	}
+	for _, symbol := range report.Symbols {
+		fmt.Printf("%s:%d\t%s %s\n", symbol.File, symbol.Line, symbol.Kind, symbol.Name)
+	}
+	fmt.Printf("%d unused of %d public symbols (%d entry points, %d allowlisted)\n",
+		len(report.Symbols), report.Checked, report.EntryPoints, report.Allowlisted)
+	return nil
+}
@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/morozRed/skelly/internal/output"
+	"github.com/morozRed/skelly/internal/snapshot"
+	"github.com/spf13/cobra"
+)
+
+// RunDiff reports symbols and call edges that changed between two context
+// directories (or repo roots) or two git revisions.
+func RunDiff(cmd *cobra.Command, args []string) error {
+	rootPath, err := resolveWorkingDirectory()
+	if err != nil {
+		return err
+	}
+	asJSON, err := cmd.Flags().GetBool("json")
+	if err != nil {
+		return fmt.Errorf("failed to read --json flag: %w", err)
+	}
+
+	before, err := loadDiffSnapshot(rootPath, args[0])
+	if err != nil {
+		return err
+	}
+	after, err := loadDiffSnapshot(rootPath, args[1])
+	if err != nil {
+		return err
+	}
+	report := snapshot.Changes(before, after)
+
+	if asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	}
+	fmt.Print(snapshot.RenderChanges(report))
+	return nil
+}
+
+// loadDiffSnapshot loads target as a context directory or repo root, or
+// else as a git revision of the repository at rootPath, which is checked
+// out into a temporary worktree and indexed there.
+func loadDiffSnapshot(rootPath, target string) (*snapshot.Snapshot, error) {
+	if info, err := os.Stat(target); err == nil && info.IsDir() {
+		return snapshot.Load(target)
+	}
+	if _, err := gitLines(rootPath, "rev-parse", "--verify", "--quiet", target+"^{commit}"); err != nil {
+		return nil, fmt.Errorf("%q is neither a context directory nor a git revision", target)
+	}
+
+	worktree, err := os.MkdirTemp("", "skelly-diff-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create worktree directory: %w", err)
+	}
+	defer os.RemoveAll(worktree)
+	if _, err := gitLines(rootPath, "worktree", "add", "--detach", "--quiet", worktree, target); err != nil {
+		return nil, err
+	}
+	defer gitLines(rootPath, "worktree", "remove", "--force", worktree)
+
+	if _, err := generateContext(worktree, nil, output.FormatText, output.OrderImportance, true, 0, true); err != nil {
+		return nil, fmt.Errorf("failed to index %s: %w", target, err)
+	}
+	loaded, err := snapshot.Load(worktree)
+	if err != nil {
+		return nil, err
+	}
+	loaded.Path = target
+	return loaded, nil
+}
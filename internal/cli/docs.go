@@ -0,0 +1,220 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/morozRed/skelly/internal/dirdocs"
+	"github.com/morozRed/skelly/internal/enrich"
+	"github.com/morozRed/skelly/internal/fileutil"
+	"github.com/morozRed/skelly/internal/graph"
+	"github.com/morozRed/skelly/internal/output"
+	"github.com/morozRed/skelly/internal/state"
+	"github.com/spf13/cobra"
+)
+
+// DocsDirsSummary reports what `skelly docs dirs` wrote.
+type DocsDirsSummary struct {
+	Mode    string        `json:"mode"`
+	DryRun  bool          `json:"dry_run,omitempty"`
+	Written []string      `json:"written"`
+	Removed []string      `json:"removed,omitempty"`
+	Docs    []dirdocs.Doc `json:"docs"`
+}
+
+// RunDocsDirs writes a README.skelly.md into each significant directory. An
+// optional directory argument limits the run to that directory, and
+// --overview stores agent- or reviewer-written prose in its Overview section.
+func RunDocsDirs(cmd *cobra.Command, args []string) error {
+	rootPath, err := resolveWorkingDirectory()
+	if err != nil {
+		return err
+	}
+	asJSON, err := cmd.Flags().GetBool("json")
+	if err != nil {
+		return fmt.Errorf("failed to read --json flag: %w", err)
+	}
+	dryRun, err := cmd.Flags().GetBool("dry-run")
+	if err != nil {
+		return fmt.Errorf("failed to read --dry-run flag: %w", err)
+	}
+	minFiles, err := cmd.Flags().GetInt("min-files")
+	if err != nil {
+		return fmt.Errorf("failed to read --min-files flag: %w", err)
+	}
+	overview, err := cmd.Flags().GetString("overview")
+	if err != nil {
+		return fmt.Errorf("failed to read --overview flag: %w", err)
+	}
+	overview = strings.TrimSpace(overview)
+	targetDir := ""
+	if len(args) > 0 {
+		targetDir = path.Clean(filepath.ToSlash(strings.TrimSpace(args[0])))
+	}
+	if overview != "" && targetDir == "" {
+		return fmt.Errorf("--overview requires a directory argument")
+	}
+
+	contextDir := filepath.Join(rootPath, output.ContextDir)
+	st, err := state.Load(contextDir)
+	if err != nil {
+		if IsCorruptStateError(err) {
+			return fmt.Errorf("state is corrupt; run `skelly generate` first")
+		}
+		return fmt.Errorf("failed to load state: %w", err)
+	}
+	if len(st.Files) == 0 {
+		return fmt.Errorf("no indexed files found; run `skelly generate` first")
+	}
+
+	hashes := make(map[string]string, len(st.Files))
+	for file, fileState := range st.Files {
+		hashes[file] = fileState.Hash
+	}
+	g := graph.BuildFromParseResult(fileutil.ParseResultFromState(st, rootPath, hashes))
+	records, err := enrich.LoadCache(filepath.Join(contextDir, enrich.OutputFile))
+	if err != nil {
+		return err
+	}
+	enrich.ForwardRecords(records, st.AliasTargets())
+
+	// An explicit directory is documented even when it is below --min-files.
+	analyzeMin := minFiles
+	if targetDir != "" {
+		analyzeMin = 0
+	}
+	all := dirdocs.Analyze(g, enrichSummaries(records), analyzeMin)
+	significant := make(map[string]bool, len(all))
+	docs := make([]dirdocs.Doc, 0, len(all))
+	for _, doc := range all {
+		if targetDir != "" && doc.Dir != targetDir {
+			continue
+		}
+		significant[doc.Dir] = true
+		docs = append(docs, doc)
+	}
+	if targetDir != "" && len(docs) == 0 {
+		return fmt.Errorf("directory %q has no indexed files", targetDir)
+	}
+
+	summary := DocsDirsSummary{Mode: "docs-dirs", DryRun: dryRun, Written: []string{}}
+	for i := range docs {
+		relPath := path.Join(docs[i].Dir, dirdocs.File)
+		docPath := filepath.Join(rootPath, filepath.FromSlash(relPath))
+		if data, err := os.ReadFile(docPath); err == nil {
+			docs[i].Overview = dirdocs.ReadOverview(string(data))
+		} else if !os.IsNotExist(err) {
+			return fmt.Errorf("failed to read %s: %w", relPath, err)
+		}
+		if overview != "" {
+			docs[i].Overview = overview
+		}
+		if dryRun {
+			summary.Written = append(summary.Written, relPath)
+			continue
+		}
+		changed, err := fileutil.WriteIfChangedTracked(docPath, []byte(dirdocs.Render(docs[i])))
+		if err != nil {
+			return err
+		}
+		if changed {
+			summary.Written = append(summary.Written, relPath)
+		}
+	}
+	summary.Docs = docs
+
+	// Directories that dropped below the threshold lose their generated doc
+	// unless someone wrote an overview worth keeping.
+	if targetDir == "" {
+		for _, file := range g.Files() {
+			dir := path.Dir(file)
+			if significant[dir] {
+				continue
+			}
+			significant[dir] = true
+			relPath := path.Join(dir, dirdocs.File)
+			docPath := filepath.Join(rootPath, filepath.FromSlash(relPath))
+			data, err := os.ReadFile(docPath)
+			if err != nil || dirdocs.ReadOverview(string(data)) != "" {
+				continue
+			}
+			if !dryRun {
+				if err := os.Remove(docPath); err != nil {
+					return fmt.Errorf("failed to remove %s: %w", relPath, err)
+				}
+			}
+			summary.Removed = append(summary.Removed, relPath)
+		}
+	}
+
+	if asJSON {
+		return fileutil.PrintJSON(summary)
+	}
+	verb := "wrote"
+	if dryRun {
+		verb = "would write"
+	}
+	fmt.Printf("%s %d of %d directory docs\n", verb, len(summary.Written), len(docs))
+	for _, relPath := range summary.Written {
+		fmt.Printf("  - %s\n", relPath)
+	}
+	for _, relPath := range summary.Removed {
+		fmt.Printf("  - removed %s\n", relPath)
+	}
+	return nil
+}
+
+// enrichSummaries maps symbol IDs to their enrich summary, preferring
+// agent-written records over ones bootstrapped from doc comments.
+func enrichSummaries(records map[string]enrich.Record) map[string]string {
+	summaries := make(map[string]string, len(records))
+	bootstrapped := make(map[string]bool, len(records))
+	for _, record := range records {
+		text := strings.TrimSpace(record.Output.Summary)
+		if text == "" || record.Status == "error" {
+			continue
+		}
+		isBootstrap := record.AgentProfile == enrich.BootstrapProfile
+		if existing, ok := summaries[record.SymbolID]; ok {
+			if isBootstrap && !bootstrapped[record.SymbolID] {
+				continue
+			}
+			if isBootstrap == bootstrapped[record.SymbolID] && existing <= text {
+				continue
+			}
+		}
+		summaries[record.SymbolID] = text
+		bootstrapped[record.SymbolID] = isBootstrap
+	}
+	return summaries
+}
+
+// ApplyEnrichSummaries sets each graph node's Summary from enrich.jsonl, so
+// symbols.jsonl, the module files and the navigation index written next carry
+// it. Records under retired IDs follow aliases. It returns the hash of
+// enrich.jsonl for state.EnrichHash.
+func ApplyEnrichSummaries(contextDir string, g *graph.Graph, aliases map[string]string) (string, error) {
+	records, err := enrich.LoadCache(filepath.Join(contextDir, enrich.OutputFile))
+	if err != nil {
+		return "", err
+	}
+	enrich.ForwardRecords(records, aliases)
+	summaries := enrichSummaries(records)
+	for id, node := range g.Nodes {
+		node.Summary = summaries[id]
+	}
+	return enrichHash(contextDir), nil
+}
+
+// enrichHash returns the hash of enrich.jsonl, or "" when it is missing or
+// unreadable.
+func enrichHash(contextDir string) string {
+	hash, err := fileutil.HashFile(filepath.Join(contextDir, enrich.OutputFile))
+	if err != nil {
+		return ""
+	}
+	return hash
+}
@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/morozRed/skelly/internal/fileutil"
 	"github.com/morozRed/skelly/internal/languages"
@@ -33,6 +34,16 @@ func RunDoctor(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	strict, err := nav.OptionalBoolFlag(cmd, "strict", false)
+	if err != nil {
+		return err
+	}
+	var maxAge time.Duration
+	if flag := cmd.Flags().Lookup("max-age"); flag != nil {
+		if maxAge, err = cmd.Flags().GetDuration("max-age"); err != nil {
+			return fmt.Errorf("failed to read --max-age flag: %w", err)
+		}
+	}
 
 	contextDir := filepath.Join(rootPath, output.ContextDir)
 	summary := DoctorSummary{
@@ -42,11 +53,10 @@ func RunDoctor(cmd *cobra.Command, args []string) error {
 		Format:       llm.DetectContextFormat(contextDir),
 		Integrations: llm.DetectLLMIntegrations(rootPath),
 		Clean:        false,
+		Strict:       strict,
 	}
 
-	statePath := filepath.Join(contextDir, state.StateFile)
-	_, statErr := os.Stat(statePath)
-	hasState := statErr == nil
+	_, hasState := state.Path(contextDir)
 	if !hasState {
 		summary.Missing = append(summary.Missing, state.StateFile)
 	}
@@ -77,6 +87,7 @@ func RunDoctor(cmd *cobra.Command, args []string) error {
 		if err != nil {
 			summary.Missing = append(summary.Missing, "valid state file")
 			summary.Suggestions = append(summary.Suggestions, "run skelly generate")
+			summary.Failures = append(summary.Failures, "state file is invalid")
 		} else {
 			summary.IndexedFiles = len(st.Files)
 			for file := range st.Files {
@@ -102,6 +113,46 @@ func RunDoctor(cmd *cobra.Command, args []string) error {
 			summary.Changed = len(fileutil.DedupeStrings(changed))
 			summary.Deleted = len(deleted)
 			summary.Clean = summary.Changed == 0 && summary.Deleted == 0
+			if st.SearchStale {
+				summary.Missing = append(summary.Missing, "current search index (last update ran with --quick)")
+				summary.Suggestions = append(summary.Suggestions, "run skelly update")
+			}
+
+			if !st.UpdatedAt.IsZero() {
+				generatedAt := st.UpdatedAt.UTC()
+				summary.GeneratedAt = &generatedAt
+				if maxAge > 0 && time.Since(generatedAt) > maxAge {
+					summary.Expired = true
+					summary.Missing = append(summary.Missing, fmt.Sprintf("context generated within %s", maxAge))
+					summary.Suggestions = append(summary.Suggestions, "run skelly generate")
+					summary.Failures = append(summary.Failures, fmt.Sprintf("context was generated %s ago, more than --max-age %s", time.Since(generatedAt).Round(time.Second), maxAge))
+				}
+			}
+			summary.ModifiedArtifacts, summary.MissingArtifacts = verifyOutputHashes(contextDir, st)
+			if len(summary.ModifiedArtifacts) > 0 || len(summary.MissingArtifacts) > 0 {
+				summary.Missing = append(summary.Missing, "artifacts matching the hashes recorded in state")
+				summary.Suggestions = append(summary.Suggestions, "run skelly generate")
+				summary.Failures = append(summary.Failures, fmt.Sprintf("artifacts differ from state: modified=%d missing=%d", len(summary.ModifiedArtifacts), len(summary.MissingArtifacts)))
+			}
+			if !summary.Clean {
+				summary.Failures = append(summary.Failures, fmt.Sprintf("context is stale: changed=%d deleted=%d", summary.Changed, summary.Deleted))
+			}
+
+			for _, issue := range st.ParseIssues {
+				if issue.Severity == "error" {
+					summary.ParseErrors++
+				} else {
+					summary.ParseWarnings++
+				}
+			}
+			if summary.ParseErrors > 0 {
+				summary.Suggestions = append(summary.Suggestions, "run skelly issues")
+			}
+
+			summary.Overviews = overviewCoverage(contextDir, st)
+			if summary.Overviews != nil && summary.Overviews.Stale > 0 {
+				summary.Suggestions = append(summary.Suggestions, "run skelly enrich overview")
+			}
 
 			if summary.SuspiciousIndexed > 0 {
 				summary.Missing = append(summary.Missing, "context scope includes generated workspace artifacts")
@@ -117,6 +168,13 @@ func RunDoctor(cmd *cobra.Command, args []string) error {
 	if !summary.Integrations["context"] {
 		summary.Missing = append(summary.Missing, "CONTEXT.md managed block")
 	}
+	summary.ManagedBlocks = llm.CheckManagedBlocks(rootPath)
+	for _, block := range summary.ManagedBlocks {
+		if block.Status == llm.ManagedBlockOutdated {
+			summary.Missing = append(summary.Missing, "current managed block in "+block.File)
+			summary.Suggestions = append(summary.Suggestions, "run skelly init --refresh")
+		}
+	}
 	hasProviderAdapter := summary.Integrations["codex"] || summary.Integrations["claude"] || summary.Integrations["cursor"]
 	if !hasProviderAdapter {
 		summary.Missing = append(summary.Missing, "LLM adapter file")
@@ -124,6 +182,7 @@ func RunDoctor(cmd *cobra.Command, args []string) error {
 
 	if !hasState || summary.Format == "none" {
 		summary.Suggestions = append(summary.Suggestions, "run skelly init")
+		summary.Failures = append(summary.Failures, "no generated context")
 	}
 	if hasState && !summary.Clean {
 		summary.Suggestions = append(summary.Suggestions, "run skelly update")
@@ -137,11 +196,23 @@ func RunDoctor(cmd *cobra.Command, args []string) error {
 	summary.Suggestions = fileutil.DedupeStrings(summary.Suggestions)
 	sort.Strings(summary.Suggestions)
 	summary.Healthy = summary.Clean && summary.Format != "none" && len(summary.Missing) == 0
+	if strict && !summary.Healthy && len(summary.Failures) == 0 {
+		summary.Failures = append(summary.Failures, "setup incomplete: "+strings.Join(summary.Missing, ", "))
+	}
+	var failure error
+	if len(summary.Failures) > 0 {
+		// The report is the output; a failed check only sets the exit code.
+		cmd.SilenceUsage = true
+		failure = fmt.Errorf("doctor: %s", strings.Join(summary.Failures, "; "))
+	}
 
 	if asJSON {
 		encoder := json.NewEncoder(os.Stdout)
 		encoder.SetIndent("", "  ")
-		return encoder.Encode(summary)
+		if err := encoder.Encode(summary); err != nil {
+			return err
+		}
+		return failure
 	}
 
 	status := "issues"
@@ -153,6 +224,18 @@ func RunDoctor(cmd *cobra.Command, args []string) error {
 	if summary.IndexedFiles > 0 {
 		fmt.Printf("context size: indexed_files=%d\n", summary.IndexedFiles)
 	}
+	if summary.ParseErrors > 0 || summary.ParseWarnings > 0 {
+		fmt.Printf("parse issues: errors=%d warnings=%d\n", summary.ParseErrors, summary.ParseWarnings)
+	}
+	if summary.GeneratedAt != nil {
+		fmt.Printf("context age: %s (generated %s)\n", time.Since(*summary.GeneratedAt).Round(time.Second), summary.GeneratedAt.Format(time.RFC3339))
+	}
+	if len(summary.ModifiedArtifacts) > 0 {
+		fmt.Printf("modified artifacts (%d): %s\n", len(summary.ModifiedArtifacts), SummarizePaths(summary.ModifiedArtifacts, 5))
+	}
+	if len(summary.MissingArtifacts) > 0 {
+		fmt.Printf("missing artifacts (%d): %s\n", len(summary.MissingArtifacts), SummarizePaths(summary.MissingArtifacts, 5))
+	}
 	fmt.Printf("integrations: skills=%t context=%t codex=%t claude=%t cursor=%t\n",
 		summary.Integrations["skills"],
 		summary.Integrations["context"],
@@ -171,12 +254,21 @@ func RunDoctor(cmd *cobra.Command, args []string) error {
 		}
 	}
 	fmt.Printf("lsp: available=%d/%d present languages\n", availableLSP, presentLSP)
+	if coverage := summary.Overviews; coverage != nil {
+		fmt.Printf("overviews: files=%d/%d modules=%d/%d stale=%d\n",
+			coverage.Files, coverage.FilesTotal, coverage.Modules, coverage.ModulesTotal, coverage.Stale)
+	}
 	if summary.SuspiciousIndexed > 0 {
 		fmt.Printf("context scope warning: suspicious_indexed_files=%d (%s)\n",
 			summary.SuspiciousIndexed,
 			SummarizePaths(summary.SuspiciousIndexedList, 5),
 		)
 	}
+	for _, block := range summary.ManagedBlocks {
+		if block.Status != llm.ManagedBlockCurrent {
+			fmt.Printf("managed block: %s %s\n", block.File, block.Status)
+		}
+	}
 	if len(summary.Missing) > 0 {
 		fmt.Printf("missing (%d): %s\n", len(summary.Missing), strings.Join(summary.Missing, ", "))
 	}
@@ -185,5 +277,23 @@ func RunDoctor(cmd *cobra.Command, args []string) error {
 			fmt.Printf("next: %s\n", suggestion)
 		}
 	}
-	return nil
+	return failure
+}
+
+// verifyOutputHashes compares every artifact recorded in state against its
+// on-disk content, returning the ones that changed and the ones that are gone.
+func verifyOutputHashes(contextDir string, st *state.State) ([]string, []string) {
+	var modified, missing []string
+	for rel, recorded := range st.OutputHashes {
+		hash, err := fileutil.HashArtifact(filepath.Join(contextDir, rel))
+		switch {
+		case os.IsNotExist(err):
+			missing = append(missing, filepath.ToSlash(rel))
+		case err != nil || hash != recorded:
+			modified = append(modified, filepath.ToSlash(rel))
+		}
+	}
+	sort.Strings(modified)
+	sort.Strings(missing)
+	return modified, missing
 }
@@ -0,0 +1,111 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/morozRed/skelly/internal/embed"
+	"github.com/morozRed/skelly/internal/enrich"
+	"github.com/morozRed/skelly/internal/nav"
+	"github.com/morozRed/skelly/internal/output"
+	"github.com/morozRed/skelly/internal/parser"
+	"github.com/spf13/cobra"
+)
+
+// EmbedRunSummary reports an `enrich embed` run.
+type EmbedRunSummary struct {
+	Mode       string `json:"mode"`
+	Model      string `json:"model,omitempty"`
+	OutputFile string `json:"output_file"`
+	Symbols    int    `json:"symbols"`
+	Dimensions int    `json:"dimensions"`
+	embed.UpdateStats
+	DurationMS int64 `json:"duration_ms"`
+}
+
+// RunEnrichEmbed embeds every indexed symbol (kind, name, file, signature,
+// doc comment and enrich summary) with the configured provider and writes
+// embeddings.bin. Symbols whose text is unchanged keep their vectors.
+func RunEnrichEmbed(cmd *cobra.Command, args []string) error {
+	start := time.Now()
+	rootPath, err := resolveWorkingDirectory()
+	if err != nil {
+		return err
+	}
+	if _, err := ApplyProjectConfig(cmd, rootPath); err != nil {
+		return err
+	}
+	asJSON, err := cmd.Flags().GetBool("json")
+	if err != nil {
+		return fmt.Errorf("failed to read --json flag: %w", err)
+	}
+	batch, err := cmd.Flags().GetInt("batch")
+	if err != nil {
+		return fmt.Errorf("failed to read --batch flag: %w", err)
+	}
+	embedder, model, err := nav.EmbedderFromFlags(cmd)
+	if err != nil {
+		return err
+	}
+
+	lookup, err := nav.LoadLookup(rootPath)
+	if err != nil {
+		return err
+	}
+	records, err := enrich.LoadCache(filepath.Join(rootPath, output.ContextDir, enrich.OutputFile))
+	if err != nil {
+		return err
+	}
+	summaries := enrichSummaries(records)
+
+	symbols := make([]embed.Symbol, 0, lookup.Len())
+	for _, node := range lookup.AllNodes() {
+		symbols = append(symbols, embed.Symbol{
+			ID:        node.ID,
+			Name:      parser.Symbol{Name: node.Name, Container: node.Container}.QualifiedName(),
+			Kind:      node.Kind,
+			Signature: node.Signature,
+			File:      node.File,
+			Doc:       node.Doc,
+			Summary:   summaries[node.ID],
+		})
+	}
+	sort.Slice(symbols, func(i, j int) bool { return symbols[i].ID < symbols[j].ID })
+
+	previous, err := embed.Load(rootPath)
+	if err != nil {
+		// An unreadable store is rebuilt from scratch.
+		fmt.Fprintf(os.Stderr, "warning: %v; embedding every symbol\n", err)
+		previous = nil
+	}
+	store, stats, err := embed.Update(embedder, model, previous, symbols, batch)
+	if err != nil {
+		return err
+	}
+	if err := embed.Save(rootPath, store); err != nil {
+		return err
+	}
+
+	summary := EmbedRunSummary{
+		Mode:        "enrich-embed",
+		Model:       model,
+		OutputFile:  embed.Path(rootPath),
+		Symbols:     len(store.Entries),
+		Dimensions:  store.Dimensions,
+		UpdateStats: stats,
+		DurationMS:  time.Since(start).Milliseconds(),
+	}
+	if asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(summary)
+	}
+	fmt.Printf("embeddings complete in %dms\n", summary.DurationMS)
+	fmt.Printf("output: %s\n", summary.OutputFile)
+	fmt.Printf("symbols=%d dimensions=%d embedded=%d reused=%d removed=%d\n", summary.Symbols, summary.Dimensions, stats.Embedded, stats.Reused, stats.Removed)
+	return nil
+}
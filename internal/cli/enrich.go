@@ -7,10 +7,12 @@ import (
 	"strings"
 	"time"
 
+	"github.com/morozRed/skelly/internal/config"
 	"github.com/morozRed/skelly/internal/enrich"
 	"github.com/morozRed/skelly/internal/fileutil"
 	"github.com/morozRed/skelly/internal/graph"
 	"github.com/morozRed/skelly/internal/languages"
+	"github.com/morozRed/skelly/internal/nav"
 	"github.com/morozRed/skelly/internal/output"
 	"github.com/morozRed/skelly/internal/state"
 	"github.com/spf13/cobra"
@@ -18,6 +20,7 @@ import (
 
 func RunEnrich(cmd *cobra.Command, args []string) error {
 	start := time.Now()
+	timer := newPhaseTimer("enrich")
 	rootPath, err := resolveWorkingDirectory()
 	if err != nil {
 		return err
@@ -41,6 +44,14 @@ func RunEnrich(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("failed to read --json flag: %w", err)
 	}
+	sources, err := enrichSources(cmd, rootPath)
+	if err != nil {
+		return err
+	}
+	agent, err := enrichAgent(cmd, rootPath)
+	if err != nil {
+		return err
+	}
 
 	contextDir := filepath.Join(rootPath, output.ContextDir)
 	st, err := state.Load(contextDir)
@@ -53,12 +64,16 @@ func RunEnrich(cmd *cobra.Command, args []string) error {
 	if len(st.Files) == 0 {
 		return fmt.Errorf("no indexed files found; run `skelly generate` first")
 	}
+	if forwardedID, ok := st.ResolveAlias(targetSelector); ok {
+		targetSelector = forwardedID
+	}
 
 	registry := languages.NewDefaultRegistry()
 	ignoreRules, err := LoadIgnoreRules(rootPath)
 	if err != nil {
 		return err
 	}
+	endScan := timer.track("scan")
 	currentHashes, err := fileutil.ScanFileHashes(rootPath, registry, ignoreRules)
 	if err != nil {
 		return fmt.Errorf("failed to scan files: %w", err)
@@ -68,10 +83,11 @@ func RunEnrich(cmd *cobra.Command, args []string) error {
 		targetFiles = append(targetFiles, file)
 	}
 	sort.Strings(targetFiles)
+	endScan("files", len(targetFiles))
 	if len(targetFiles) == 0 {
 		return PrintEnrichSummary(EnrichRunSummary{
 			Mode:       "enrich",
-			Agent:      "agent",
+			Agent:      agent,
 			Scope:      string(enrich.ScopeTarget),
 			Target:     targetSelector,
 			RootPath:   rootPath,
@@ -82,16 +98,21 @@ func RunEnrich(cmd *cobra.Command, args []string) error {
 		}, asJSON)
 	}
 
+	endGraph := timer.track("graph")
 	parseResult := fileutil.ParseResultFromState(st, rootPath, currentHashes)
 	g := graph.BuildFromParseResult(parseResult)
+	endGraph("nodes", len(g.Nodes))
 	cachePath := filepath.Join(contextDir, enrich.OutputFile)
 	cacheRecords, err := enrich.LoadCache(cachePath)
 	if err != nil {
 		return err
 	}
+	enrich.ForwardRecords(cacheRecords, st.AliasTargets())
 
+	endSelect := timer.track("select")
 	workItems := enrich.CollectWorkItems(targetFiles, st, g)
 	workItems = enrich.FilterWorkItems(workItems, targetSelector)
+	endSelect("symbols", len(workItems))
 	if len(workItems) == 0 {
 		return fmt.Errorf(
 			"no symbols matched enrich target %q (try file path, file:symbol, file:line, or stable symbol id)",
@@ -108,21 +129,19 @@ func RunEnrich(cmd *cobra.Command, args []string) error {
 	}
 
 	item := workItems[0]
-	lineCache := make(map[string][]string)
 	record, ok := enrich.BuildRecord(
-		rootPath,
+		sources,
 		item.File,
 		item.FileState,
 		item.Symbol,
 		item.Node,
-		lineCache,
-		"agent",
+		agent,
 		enrich.ScopeTarget,
 	)
 	if !ok {
 		return fmt.Errorf("target %q could not be enriched", targetSelector)
 	}
-	record.AgentProfile = "agent"
+	record.AgentProfile = agent
 	record.Model = "manual"
 	record.PromptVersion = "agent-note-v1"
 	record.CacheKey = enrich.CacheKey(record.SymbolID, record.FileHash, record.PromptVersion, record.AgentProfile, record.Model)
@@ -145,13 +164,16 @@ func RunEnrich(cmd *cobra.Command, args []string) error {
 
 	cacheRecords[record.CacheKey] = record
 	enrich.PruneCacheForSymbol(cacheRecords, record.CacheKey, record.SymbolID, record.AgentProfile)
+	endWrite := timer.track("write")
 	if err := enrich.WriteCache(cachePath, cacheRecords); err != nil {
 		return err
 	}
+	endWrite("records", len(cacheRecords))
+	timer.finish("symbols", 1, "cache_hits", cacheHits)
 
 	return PrintEnrichSummary(EnrichRunSummary{
 		Mode:        "enrich",
-		Agent:       "agent",
+		Agent:       agent,
 		Scope:       string(enrich.ScopeTarget),
 		Target:      targetSelector,
 		RootPath:    rootPath,
@@ -166,3 +188,269 @@ func RunEnrich(cmd *cobra.Command, args []string) error {
 		Targets:     []string{item.File},
 	}, asJSON)
 }
+
+// RunEnrichBootstrap converts existing doc comments into enrich records
+// without an agent. Symbols that already carry an agent-written summary are
+// left alone, so bootstrap never overrides reviewed descriptions.
+func RunEnrichBootstrap(cmd *cobra.Command, args []string) error {
+	start := time.Now()
+	timer := newPhaseTimer("enrich-bootstrap")
+	rootPath, err := resolveWorkingDirectory()
+	if err != nil {
+		return err
+	}
+	asJSON, err := cmd.Flags().GetBool("json")
+	if err != nil {
+		return fmt.Errorf("failed to read --json flag: %w", err)
+	}
+	dryRun, err := cmd.Flags().GetBool("dry-run")
+	if err != nil {
+		return fmt.Errorf("failed to read --dry-run flag: %w", err)
+	}
+	minWords, err := cmd.Flags().GetInt("min-words")
+	if err != nil {
+		return fmt.Errorf("failed to read --min-words flag: %w", err)
+	}
+	orderBy, err := cmd.Flags().GetString("order")
+	if err != nil {
+		return fmt.Errorf("failed to read --order flag: %w", err)
+	}
+	var order graph.Metric
+	if strings.TrimSpace(orderBy) != "" && strings.TrimSpace(orderBy) != "path" {
+		if order, err = graph.ParseMetric(orderBy); err != nil {
+			return fmt.Errorf("--order: %w", err)
+		}
+	}
+	limit, err := cmd.Flags().GetInt("limit")
+	if err != nil {
+		return fmt.Errorf("failed to read --limit flag: %w", err)
+	}
+	if !cmd.Flags().Changed("limit") {
+		cfg, err := config.Load(rootPath)
+		if err != nil {
+			return err
+		}
+		limit = cfg.Enrich.MaxSymbols
+	}
+	selector := ""
+	if len(args) > 0 {
+		selector = strings.TrimSpace(args[0])
+	}
+	filter, err := enrichWorkFilter(cmd)
+	if err != nil {
+		return err
+	}
+	sources, err := enrichSources(cmd, rootPath)
+	if err != nil {
+		return err
+	}
+
+	contextDir := filepath.Join(rootPath, output.ContextDir)
+	st, err := state.Load(contextDir)
+	if err != nil {
+		if IsCorruptStateError(err) {
+			return fmt.Errorf("state is corrupt; run `skelly generate` first")
+		}
+		return fmt.Errorf("failed to load state: %w", err)
+	}
+	if len(st.Files) == 0 {
+		return fmt.Errorf("no indexed files found; run `skelly generate` first")
+	}
+
+	registry := languages.NewDefaultRegistry()
+	ignoreRules, err := LoadIgnoreRules(rootPath)
+	if err != nil {
+		return err
+	}
+	endScan := timer.track("scan")
+	currentHashes, err := fileutil.ScanFileHashes(rootPath, registry, ignoreRules)
+	if err != nil {
+		return fmt.Errorf("failed to scan files: %w", err)
+	}
+	targetFiles := make([]string, 0, len(currentHashes))
+	for file := range currentHashes {
+		targetFiles = append(targetFiles, file)
+	}
+	sort.Strings(targetFiles)
+	endScan("files", len(targetFiles))
+
+	endGraph := timer.track("graph")
+	g := graph.BuildFromParseResult(fileutil.ParseResultFromState(st, rootPath, currentHashes))
+	endGraph("nodes", len(g.Nodes))
+	cachePath := filepath.Join(contextDir, enrich.OutputFile)
+	cacheRecords, err := enrich.LoadCache(cachePath)
+	if err != nil {
+		return err
+	}
+	enrich.ForwardRecords(cacheRecords, st.AliasTargets())
+
+	reviewed := make(map[string]bool)
+	for _, record := range cacheRecords {
+		if record.AgentProfile != enrich.BootstrapProfile && strings.TrimSpace(record.Output.Summary) != "" {
+			reviewed[record.SymbolID] = true
+		}
+	}
+
+	for symbol := range filter.Symbols {
+		if forwardedID, ok := st.ResolveAlias(symbol); ok {
+			filter.Symbols[forwardedID] = true
+		}
+	}
+	endSelect := timer.track("select")
+	workItems := filter.Apply(enrich.FilterWorkItems(enrich.CollectWorkItems(targetFiles, st, g), selector))
+	if order != "" {
+		enrich.OrderWorkItems(workItems, order)
+	}
+	if limit > 0 && len(workItems) > limit {
+		workItems = workItems[:limit]
+	}
+	endSelect("symbols", len(workItems))
+	summary := EnrichRunSummary{
+		Mode:       "enrich-bootstrap",
+		Agent:      enrich.BootstrapProfile,
+		Scope:      string(enrich.ScopeTarget),
+		Target:     selector,
+		RootPath:   rootPath,
+		OutputFile: cachePath,
+		Symbols:    len(workItems),
+		DryRun:     dryRun,
+	}
+	endBuild := timer.track("build")
+	timestamp := time.Now().UTC().Format(time.RFC3339)
+	touchedFiles := make(map[string]bool)
+	for _, item := range workItems {
+		if reviewed[item.Symbol.ID] {
+			summary.Skipped++
+			continue
+		}
+		outputPayload, ok := enrich.BootstrapOutput(item.Symbol, minWords)
+		if !ok {
+			summary.Skipped++
+			continue
+		}
+		record, ok := enrich.BuildRecord(sources, item.File, item.FileState, item.Symbol, item.Node, enrich.BootstrapProfile, enrich.ScopeTarget)
+		if !ok {
+			summary.Failed++
+			continue
+		}
+		record.Model = enrich.BootstrapModel
+		record.PromptVersion = enrich.BootstrapPromptVersion
+		record.CacheKey = enrich.CacheKey(record.SymbolID, record.FileHash, record.PromptVersion, record.AgentProfile, record.Model)
+		record.Output = outputPayload
+		record.Status = enrich.BootstrapStatus
+		record.GeneratedAt = timestamp
+		record.UpdatedAt = timestamp
+
+		if existing, exists := cacheRecords[record.CacheKey]; exists {
+			summary.CacheHits++
+			if existing.Output == record.Output {
+				record.UpdatedAt = existing.UpdatedAt
+			}
+			if existing.GeneratedAt != "" {
+				record.GeneratedAt = existing.GeneratedAt
+			}
+		} else {
+			summary.CacheMisses++
+		}
+		cacheRecords[record.CacheKey] = record
+		enrich.PruneCacheForSymbol(cacheRecords, record.CacheKey, record.SymbolID, record.AgentProfile)
+		summary.Succeeded++
+		touchedFiles[item.File] = true
+	}
+
+	endBuild("succeeded", summary.Succeeded, "skipped", summary.Skipped, "failed", summary.Failed)
+
+	summary.Files = len(touchedFiles)
+	for file := range touchedFiles {
+		summary.Targets = append(summary.Targets, file)
+	}
+	sort.Strings(summary.Targets)
+	if !dryRun && summary.Succeeded > 0 {
+		endWrite := timer.track("write")
+		if err := enrich.WriteCache(cachePath, cacheRecords); err != nil {
+			return err
+		}
+		endWrite("records", len(cacheRecords))
+	}
+	summary.DurationMS = time.Since(start).Milliseconds()
+	timer.finish("symbols", summary.Symbols, "cache_hits", summary.CacheHits)
+	return PrintEnrichSummary(summary, asJSON)
+}
+
+// enrichSources reads --max-body-bytes, falling back to enrich.max_body_bytes
+// in .skelly/config.yaml and then enrich.DefaultMaxBodyBytes when the flag
+// is not passed.
+func enrichSources(cmd *cobra.Command, rootPath string) (*enrich.Sources, error) {
+	maxBodyBytes, err := nav.OptionalIntFlag(cmd, "max-body-bytes", enrich.DefaultMaxBodyBytes)
+	if err != nil {
+		return nil, err
+	}
+	if flag := cmd.Flags().Lookup("max-body-bytes"); flag == nil || !flag.Changed {
+		cfg, err := config.Load(rootPath)
+		if err != nil {
+			return nil, err
+		}
+		if cfg.Enrich.MaxBodyBytes > 0 {
+			maxBodyBytes = cfg.Enrich.MaxBodyBytes
+		}
+	}
+	if maxBodyBytes < 0 {
+		return nil, fmt.Errorf("--max-body-bytes must be >= 0")
+	}
+	return enrich.NewSources(rootPath, maxBodyBytes), nil
+}
+
+// defaultEnrichAgent is the agent profile of records written by `skelly
+// enrich` without --agent or enrich.agent.
+const defaultEnrichAgent = "agent"
+
+// enrichAgent reads --agent, falling back to enrich.agent in
+// .skelly/config.yaml when the flag is not passed. The bootstrap profile is
+// reserved for records `enrich bootstrap` seeds from doc comments.
+func enrichAgent(cmd *cobra.Command, rootPath string) (string, error) {
+	agent := defaultEnrichAgent
+	if flag := cmd.Flags().Lookup("agent"); flag == nil || !flag.Changed {
+		cfg, err := config.Load(rootPath)
+		if err != nil {
+			return "", err
+		}
+		if cfg.Enrich.Agent != "" {
+			agent = cfg.Enrich.Agent
+		}
+	} else {
+		value, err := nav.OptionalStringFlag(cmd, "agent")
+		if err != nil {
+			return "", err
+		}
+		agent = value
+	}
+	switch agent {
+	case "":
+		return "", fmt.Errorf("--agent must not be empty")
+	case enrich.BootstrapProfile:
+		return "", fmt.Errorf("agent profile %q is reserved for enrich bootstrap", agent)
+	}
+	return agent, nil
+}
+
+// enrichWorkFilter reads the --path, --symbol, --kind and --visibility
+// filters of a batch enrich run.
+func enrichWorkFilter(cmd *cobra.Command) (enrich.WorkFilter, error) {
+	paths, err := cmd.Flags().GetStringArray("path")
+	if err != nil {
+		return enrich.WorkFilter{}, fmt.Errorf("failed to read --path flag: %w", err)
+	}
+	symbols, err := cmd.Flags().GetStringArray("symbol")
+	if err != nil {
+		return enrich.WorkFilter{}, fmt.Errorf("failed to read --symbol flag: %w", err)
+	}
+	kinds, err := cmd.Flags().GetStringSlice("kind")
+	if err != nil {
+		return enrich.WorkFilter{}, fmt.Errorf("failed to read --kind flag: %w", err)
+	}
+	visibilities, err := cmd.Flags().GetStringSlice("visibility")
+	if err != nil {
+		return enrich.WorkFilter{}, fmt.Errorf("failed to read --visibility flag: %w", err)
+	}
+	return enrich.NewWorkFilter(paths, symbols, kinds, visibilities)
+}
@@ -0,0 +1,193 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/morozRed/skelly/internal/enrich"
+	"github.com/morozRed/skelly/internal/output"
+	"github.com/morozRed/skelly/internal/parser"
+	"github.com/morozRed/skelly/internal/state"
+	"github.com/spf13/cobra"
+)
+
+// EnrichGCSummary reports an `enrich gc` run.
+type EnrichGCSummary struct {
+	Mode       string `json:"mode"`
+	OutputFile string `json:"output_file"`
+	DryRun     bool   `json:"dry_run"`
+	// Forwarded counts records moved from retired symbol IDs to their aliases.
+	Forwarded int `json:"forwarded,omitempty"`
+	enrich.GCResult
+	BytesBefore int64 `json:"bytes_before"`
+	BytesAfter  int64 `json:"bytes_after"`
+	DurationMS  int64 `json:"duration_ms"`
+}
+
+// RunEnrichGC rewrites enrich.jsonl without records of symbols that are no
+// longer indexed and without superseded records, keeping one per symbol and
+// profile. --stale also drops records written against an older file hash.
+func RunEnrichGC(cmd *cobra.Command, args []string) error {
+	start := time.Now()
+	rootPath, err := resolveWorkingDirectory()
+	if err != nil {
+		return err
+	}
+	asJSON, err := cmd.Flags().GetBool("json")
+	if err != nil {
+		return fmt.Errorf("failed to read --json flag: %w", err)
+	}
+	dryRun, err := cmd.Flags().GetBool("dry-run")
+	if err != nil {
+		return fmt.Errorf("failed to read --dry-run flag: %w", err)
+	}
+	dropStale, err := cmd.Flags().GetBool("stale")
+	if err != nil {
+		return fmt.Errorf("failed to read --stale flag: %w", err)
+	}
+
+	contextDir := filepath.Join(rootPath, output.ContextDir)
+	st, records, err := loadEnrichCacheWithState(contextDir)
+	if err != nil {
+		return err
+	}
+	cachePath := filepath.Join(contextDir, enrich.OutputFile)
+	summary := EnrichGCSummary{
+		Mode:        "enrich-gc",
+		OutputFile:  cachePath,
+		DryRun:      dryRun,
+		Forwarded:   enrich.ForwardRecords(records, st.AliasTargets()),
+		BytesBefore: fileSize(cachePath),
+	}
+	summary.GCResult = enrich.CollectGarbage(records, indexedSymbolHashes(st), dropStale)
+	summary.BytesAfter = summary.BytesBefore
+	if !dryRun {
+		if err := enrich.WriteCache(cachePath, records); err != nil {
+			return err
+		}
+		summary.BytesAfter = fileSize(cachePath)
+	}
+	summary.DurationMS = time.Since(start).Milliseconds()
+
+	if asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(summary)
+	}
+	mode := summary.Mode
+	if dryRun {
+		mode += " (dry-run)"
+	}
+	fmt.Printf("%s: kept=%d orphaned=%d superseded=%d stale=%d forwarded=%d duration=%dms\n",
+		mode, summary.Kept, summary.Orphaned, summary.Superseded, summary.Stale, summary.Forwarded, summary.DurationMS)
+	fmt.Printf("output: %s (%d -> %d bytes)\n", summary.OutputFile, summary.BytesBefore, summary.BytesAfter)
+	return nil
+}
+
+// RunEnrichStats prints the size of enrich.jsonl, how many indexed symbols
+// its records cover at their current file hash, and how stale the rest are.
+func RunEnrichStats(cmd *cobra.Command, args []string) error {
+	rootPath, err := resolveWorkingDirectory()
+	if err != nil {
+		return err
+	}
+	asJSON, err := cmd.Flags().GetBool("json")
+	if err != nil {
+		return fmt.Errorf("failed to read --json flag: %w", err)
+	}
+
+	contextDir := filepath.Join(rootPath, output.ContextDir)
+	st, records, err := loadEnrichCacheWithState(contextDir)
+	if err != nil {
+		return err
+	}
+	cachePath := filepath.Join(contextDir, enrich.OutputFile)
+	enrich.ForwardRecords(records, st.AliasTargets())
+	stats := enrich.ComputeCacheStats(records, indexedSymbolHashes(st), time.Now())
+	stats.Bytes = fileSize(cachePath)
+
+	if asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(stats)
+	}
+	fmt.Printf("enrich cache: %s records=%d bytes=%d\n", cachePath, stats.Records, stats.Bytes)
+	fmt.Printf("coverage: symbols=%d covered=%d fresh=%d hit_rate=%.2f\n", stats.Symbols, stats.Covered, stats.Fresh, stats.HitRate)
+	stale := []string{fmt.Sprintf("records=%d", stats.StaleRecords)}
+	for _, bucket := range enrich.StaleAgeBuckets {
+		if count := stats.StaleAge[bucket]; count > 0 {
+			stale = append(stale, fmt.Sprintf("%s=%d", bucket, count))
+		}
+	}
+	fmt.Printf("stale: %s\n", strings.Join(stale, " "))
+	fmt.Printf("reclaimable: orphaned=%d superseded=%d\n", stats.Reclaimable.Orphaned, stats.Reclaimable.Superseded)
+	fmt.Printf("status: %s\n", formatCounts(stats.ByStatus))
+	fmt.Printf("profiles: %s\n", formatCounts(stats.ByProfile))
+	if stats.Reclaimable.Orphaned+stats.Reclaimable.Superseded > 0 {
+		fmt.Println("next: run skelly enrich gc")
+	}
+	return nil
+}
+
+func loadEnrichCacheWithState(contextDir string) (*state.State, map[string]enrich.Record, error) {
+	st, err := state.Load(contextDir)
+	if err != nil {
+		if IsCorruptStateError(err) {
+			return nil, nil, fmt.Errorf("state is corrupt; run `skelly generate` first")
+		}
+		return nil, nil, fmt.Errorf("failed to load state: %w", err)
+	}
+	if len(st.Files) == 0 {
+		return nil, nil, fmt.Errorf("no indexed files found; run `skelly generate` first")
+	}
+	records, err := enrich.LoadCache(filepath.Join(contextDir, enrich.OutputFile))
+	if err != nil {
+		return nil, nil, err
+	}
+	return st, records, nil
+}
+
+// indexedSymbolHashes maps every indexed symbol ID to its file's hash.
+func indexedSymbolHashes(st *state.State) map[string]string {
+	symbols := make(map[string]string)
+	for file, fileState := range st.Files {
+		for _, symbol := range fileState.Symbols {
+			id := symbol.ID
+			if id == "" {
+				id = parser.StableSymbolID(file, symbol)
+			}
+			symbols[id] = fileState.Hash
+		}
+	}
+	return symbols
+}
+
+func fileSize(path string) int64 {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
+
+// formatCounts renders counts as "name=count" pairs sorted by name.
+func formatCounts(counts map[string]int) string {
+	names := make([]string, 0, len(counts))
+	for name := range counts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		parts = append(parts, fmt.Sprintf("%s=%d", name, counts[name]))
+	}
+	if len(parts) == 0 {
+		return "none"
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,66 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Placeholders substituted into --exec commands with shell-quoted, space-separated file lists.
+const (
+	ExecPlaceholderImpacted = "{impacted}"
+	ExecPlaceholderChanged  = "{changed}"
+	ExecPlaceholderDeleted  = "{deleted}"
+)
+
+// RunExecHooks runs post-update commands for a completed run summary.
+// Commands are skipped when the run touched no files so downstream tooling stays incremental.
+func RunExecHooks(rootPath string, commands []string, summary RunSummary, asJSON bool) error {
+	if len(commands) == 0 {
+		return nil
+	}
+	if len(summary.ChangedFiles) == 0 && len(summary.DeletedFiles) == 0 && len(summary.ImpactedFiles) == 0 {
+		return nil
+	}
+
+	for _, command := range commands {
+		expanded := ExpandExecCommand(command, summary)
+		hook := exec.Command("sh", "-c", expanded)
+		hook.Dir = rootPath
+		hook.Env = append(os.Environ(),
+			"SKELLY_MODE="+summary.Mode,
+			"SKELLY_IMPACTED="+strings.Join(summary.ImpactedFiles, "\n"),
+			"SKELLY_CHANGED="+strings.Join(summary.ChangedFiles, "\n"),
+			"SKELLY_DELETED="+strings.Join(summary.DeletedFiles, "\n"),
+		)
+		// Keep stdout reserved for the JSON summary when machine-readable output is requested.
+		hook.Stdout = os.Stdout
+		if asJSON {
+			hook.Stdout = os.Stderr
+		}
+		hook.Stderr = os.Stderr
+		if err := hook.Run(); err != nil {
+			return fmt.Errorf("exec hook %q failed: %w", command, err)
+		}
+	}
+	return nil
+}
+
+// ExpandExecCommand substitutes file-list placeholders in a hook command.
+func ExpandExecCommand(command string, summary RunSummary) string {
+	replacer := strings.NewReplacer(
+		ExecPlaceholderImpacted, shellQuoteList(summary.ImpactedFiles),
+		ExecPlaceholderChanged, shellQuoteList(summary.ChangedFiles),
+		ExecPlaceholderDeleted, shellQuoteList(summary.DeletedFiles),
+	)
+	return replacer.Replace(command)
+}
+
+func shellQuoteList(values []string) string {
+	quoted := make([]string, 0, len(values))
+	for _, value := range values {
+		quoted = append(quoted, "'"+strings.ReplaceAll(value, "'", `'\''`)+"'")
+	}
+	return strings.Join(quoted, " ")
+}
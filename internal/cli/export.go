@@ -0,0 +1,188 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/morozRed/skelly/internal/export"
+	"github.com/morozRed/skelly/internal/fileutil"
+	"github.com/morozRed/skelly/internal/graph"
+	"github.com/morozRed/skelly/internal/nav"
+	"github.com/morozRed/skelly/internal/output"
+	"github.com/morozRed/skelly/internal/state"
+	"github.com/spf13/cobra"
+)
+
+// RunExport renders the indexed dependency graph for Graphviz, as a
+// Mermaid block for Markdown, or as an LSIF index for code-intelligence tools.
+func RunExport(cmd *cobra.Command, args []string) error {
+	rootPath, err := resolveWorkingDirectory()
+	if err != nil {
+		return err
+	}
+	format, err := cmd.Flags().GetString("format")
+	if err != nil {
+		return fmt.Errorf("failed to read --format flag: %w", err)
+	}
+	format = strings.ToLower(strings.TrimSpace(format))
+	if format != "dot" && format != "mermaid" && format != "lsif" {
+		return fmt.Errorf("unsupported export format %q (supported: dot, mermaid, lsif)", format)
+	}
+	rawScope, err := cmd.Flags().GetString("scope")
+	if err != nil {
+		return fmt.Errorf("failed to read --scope flag: %w", err)
+	}
+	scope, err := export.ParseScope(rawScope)
+	if err != nil {
+		return err
+	}
+	focus, err := cmd.Flags().GetString("focus")
+	if err != nil {
+		return fmt.Errorf("failed to read --focus flag: %w", err)
+	}
+	depth, err := cmd.Flags().GetInt("depth")
+	if err != nil {
+		return fmt.Errorf("failed to read --depth flag: %w", err)
+	}
+	if depth < 1 {
+		return fmt.Errorf("--depth must be >= 1")
+	}
+	top, err := cmd.Flags().GetInt("top")
+	if err != nil {
+		return fmt.Errorf("failed to read --top flag: %w", err)
+	}
+	if top < 0 {
+		return fmt.Errorf("--top must be >= 0")
+	}
+	outputPath, err := cmd.Flags().GetString("output")
+	if err != nil {
+		return fmt.Errorf("failed to read --output flag: %w", err)
+	}
+	languageFilter, err := nav.OptionalLanguageFilter(cmd, "lang")
+	if err != nil {
+		return err
+	}
+
+	contextDir := filepath.Join(rootPath, output.ContextDir)
+	st, err := state.Load(contextDir)
+	if err != nil {
+		if IsCorruptStateError(err) {
+			return fmt.Errorf("state is corrupt; run `skelly generate` first")
+		}
+		return fmt.Errorf("failed to load state: %w", err)
+	}
+	if len(st.Files) == 0 {
+		return fmt.Errorf("no indexed files found; run `skelly generate` first")
+	}
+
+	hashes := make(map[string]string, len(st.Files))
+	for file, fileState := range st.Files {
+		hashes[file] = fileState.Hash
+	}
+	parseResult := fileutil.ParseResultFromState(st, rootPath, hashes)
+	g := graph.BuildFromParseResult(parseResult)
+	if len(languageFilter) > 0 {
+		// Like generate --lang, other languages' files are left out of the
+		// graph; the edges into them are reported on stderr.
+		cuts := languageBoundaryCuts(g, languageFilter)
+		total := len(parseResult.Files)
+		parseResult.Files = FilterFilesByLanguage(parseResult.Files, languageFilter)
+		fmt.Fprintf(os.Stderr, "export: --lang kept %d of %d files\n", len(parseResult.Files), total)
+		printExportCuts(cuts)
+		g = graph.BuildFromParseResult(parseResult)
+	}
+	if format == "lsif" {
+		if strings.TrimSpace(focus) != "" || top > 0 || cmd.Flags().Changed("scope") {
+			return fmt.Errorf("--scope, --focus and --top do not apply to --format lsif")
+		}
+		return writeLSIFExport(g, rootPath, outputPath)
+	}
+	view := export.Build(g, scope)
+	if strings.TrimSpace(focus) != "" {
+		focusID, err := view.Resolve(focus)
+		if err != nil {
+			return err
+		}
+		view = view.Focus(focusID, depth)
+	}
+	view = view.Top(top)
+
+	rendered := export.RenderDOT(view)
+	if format == "mermaid" {
+		rendered = export.RenderMermaid(view)
+	}
+	if strings.TrimSpace(outputPath) == "" {
+		fmt.Print(rendered)
+		return nil
+	}
+	if err := os.WriteFile(outputPath, []byte(rendered), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", outputPath, err)
+	}
+	fmt.Printf("wrote %s (%s scope, %d nodes, %d edges)\n", outputPath, view.Scope, len(view.Nodes), len(view.Edges))
+	return nil
+}
+
+// exportCutsShown caps the language boundary cuts export lists on stderr.
+const exportCutsShown = 20
+
+// languageBoundaryCuts returns the edges from symbols of languageFilter to
+// symbols of other languages, as [from, to] pairs sorted by IDs.
+func languageBoundaryCuts(g *graph.Graph, languageFilter map[string]bool) [][2]*graph.Node {
+	cuts := make([][2]*graph.Node, 0)
+	for _, node := range g.Nodes {
+		if !languageFilter[node.Language] {
+			continue
+		}
+		for _, targetID := range node.OutEdges {
+			if target := g.Nodes[targetID]; target != nil && !languageFilter[target.Language] {
+				cuts = append(cuts, [2]*graph.Node{node, target})
+			}
+		}
+	}
+	sort.Slice(cuts, func(i, j int) bool {
+		if cuts[i][0].ID != cuts[j][0].ID {
+			return cuts[i][0].ID < cuts[j][0].ID
+		}
+		return cuts[i][1].ID < cuts[j][1].ID
+	})
+	return cuts
+}
+
+// printExportCuts lists language boundary cuts on stderr, which keeps
+// stdout a valid DOT, Mermaid or LSIF document.
+func printExportCuts(cuts [][2]*graph.Node) {
+	if len(cuts) == 0 {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "language boundary cuts (%d):\n", len(cuts))
+	for i, cut := range cuts {
+		if i == exportCutsShown {
+			fmt.Fprintf(os.Stderr, "... %d more\n", len(cuts)-exportCutsShown)
+			break
+		}
+		fmt.Fprintf(os.Stderr, "- %s -> %s [%s]\n", cut[0].ID, cut[1].ID, cut[1].Language)
+	}
+}
+
+// writeLSIFExport writes the LSIF index to outputPath, or stdout when empty.
+func writeLSIFExport(g *graph.Graph, rootPath, outputPath string) error {
+	if strings.TrimSpace(outputPath) == "" {
+		return export.WriteLSIF(os.Stdout, g, rootPath)
+	}
+	file, err := os.Create(outputPath)
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", outputPath, err)
+	}
+	if err := export.WriteLSIF(file, g, rootPath); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write %s: %w", outputPath, err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", outputPath, err)
+	}
+	fmt.Printf("wrote %s (lsif, %d symbols)\n", outputPath, len(g.Nodes))
+	return nil
+}
@@ -2,9 +2,14 @@ package cli
 
 import (
 	"fmt"
+	"path/filepath"
 	"strings"
 
+	"github.com/morozRed/skelly/internal/fileutil"
+	"github.com/morozRed/skelly/internal/languages"
+	"github.com/morozRed/skelly/internal/llm"
 	"github.com/morozRed/skelly/internal/output"
+	"github.com/morozRed/skelly/internal/state"
 	"github.com/spf13/cobra"
 )
 
@@ -19,38 +24,66 @@ func OptionalStringFlag(cmd *cobra.Command, name string) (string, error) {
 	return strings.TrimSpace(value), nil
 }
 
+func OptionalStringSliceFlag(cmd *cobra.Command, name string) ([]string, error) {
+	if cmd == nil || cmd.Flags().Lookup(name) == nil {
+		return nil, nil
+	}
+	values, err := cmd.Flags().GetStringArray(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read --%s flag: %w", name, err)
+	}
+	out := make([]string, 0, len(values))
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value != "" {
+			out = append(out, value)
+		}
+	}
+	return out, nil
+}
+
+// ParseJobs reads --jobs; 0 (the default) means one parse worker per CPU.
+func ParseJobs(cmd *cobra.Command) (int, error) {
+	if cmd == nil || cmd.Flags().Lookup("jobs") == nil {
+		return 0, nil
+	}
+	jobs, err := cmd.Flags().GetInt("jobs")
+	if err != nil {
+		return 0, fmt.Errorf("failed to read --jobs flag: %w", err)
+	}
+	if jobs < 0 {
+		return 0, fmt.Errorf("--jobs must be >= 0")
+	}
+	return jobs, nil
+}
+
+// ParseStateBackend reads --state-backend; empty keeps the backend already on disk.
+func ParseStateBackend(cmd *cobra.Command) (state.Backend, error) {
+	raw, err := OptionalStringFlag(cmd, "state-backend")
+	if err != nil || raw == "" {
+		return "", err
+	}
+	return state.ParseBackend(raw)
+}
+
 func ParseLanguageFilter(cmd *cobra.Command) (map[string]bool, error) {
 	langs, err := cmd.Flags().GetStringSlice("lang")
 	if err != nil {
 		return nil, fmt.Errorf("failed to read --lang flag: %w", err)
 	}
-	if len(langs) == 0 {
-		return nil, nil
-	}
+	return languages.ParseLanguageList(langs)
+}
 
-	aliases := map[string]string{
-		"go":         "go",
-		"python":     "python",
-		"py":         "python",
-		"ruby":       "ruby",
-		"rb":         "ruby",
-		"typescript": "typescript",
-		"ts":         "typescript",
-		"javascript": "javascript",
-		"js":         "javascript",
+func ParseIndexOrder(cmd *cobra.Command) (output.Order, error) {
+	if cmd == nil || cmd.Flags().Lookup("order") == nil {
+		return output.OrderImportance, nil
 	}
 
-	filter := make(map[string]bool, len(langs))
-	for _, lang := range langs {
-		key := strings.ToLower(strings.TrimSpace(lang))
-		canonical, ok := aliases[key]
-		if !ok {
-			return nil, fmt.Errorf("unsupported language %q (supported: go, python, ruby, typescript, javascript)", lang)
-		}
-		filter[canonical] = true
+	value, err := cmd.Flags().GetString("order")
+	if err != nil {
+		return "", fmt.Errorf("failed to read --order flag: %w", err)
 	}
-
-	return filter, nil
+	return output.ParseOrder(value)
 }
 
 func ParseOutputFormat(cmd *cobra.Command) (output.Format, error) {
@@ -64,3 +97,50 @@ func ParseOutputFormat(cmd *cobra.Command) (output.Format, error) {
 	}
 	return output.ParseFormat(value)
 }
+
+// GitignoreOverride returns the .gitignore handling --no-gitignore asks for,
+// or nil when the flag was not passed (or set in .skelly/config.yaml), so
+// the choice recorded when the context was generated applies.
+func GitignoreOverride(cmd *cobra.Command) (*bool, error) {
+	if cmd == nil || cmd.Flags().Lookup("no-gitignore") == nil || !cmd.Flags().Changed("no-gitignore") {
+		return nil, nil
+	}
+	noGitignore, err := cmd.Flags().GetBool("no-gitignore")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read --no-gitignore flag: %w", err)
+	}
+	gitignore := !noGitignore
+	return &gitignore, nil
+}
+
+// RecordedOutputFormat returns the format of the context already in
+// rootPath when --format was neither passed nor set in .skelly/config.yaml,
+// so update and watch keep a jsonl or ctags context instead of rewriting it
+// as text. Otherwise, or without a context, it returns format.
+func RecordedOutputFormat(cmd *cobra.Command, rootPath string, format output.Format) output.Format {
+	if cmd != nil && cmd.Flags().Changed("format") {
+		return format
+	}
+	if detected, err := output.ParseFormat(llm.DetectContextFormat(filepath.Join(rootPath, output.ContextDir))); err == nil {
+		return detected
+	}
+	return format
+}
+
+// ApplyArtifactCompression sets how this run stores large artifacts from the
+// --compress flag. Unset, each artifact keeps the compression it has on disk.
+func ApplyArtifactCompression(cmd *cobra.Command) error {
+	value := ""
+	if cmd != nil && cmd.Flags().Lookup("compress") != nil {
+		var err error
+		if value, err = cmd.Flags().GetString("compress"); err != nil {
+			return fmt.Errorf("failed to read --compress flag: %w", err)
+		}
+	}
+	compression, err := fileutil.ParseCompression(value)
+	if err != nil {
+		return err
+	}
+	fileutil.SetArtifactCompression(compression)
+	return nil
+}
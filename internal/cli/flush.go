@@ -0,0 +1,111 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/morozRed/skelly/internal/output"
+	"github.com/spf13/cobra"
+)
+
+const flushPollInterval = 25 * time.Millisecond
+
+// queryFlushTimeout bounds how long a query waits for a write-behind watch to
+// flush before it answers from the artifacts on disk.
+const queryFlushTimeout = 5 * time.Second
+
+// RunFlush asks a running `skelly watch --write-behind` to write its
+// in-memory graph to disk and waits for the acknowledgement.
+func RunFlush(cmd *cobra.Command, args []string) error {
+	start := time.Now()
+	rootPath, err := resolveWorkingDirectory()
+	if err != nil {
+		return err
+	}
+	timeout, err := cmd.Flags().GetDuration("timeout")
+	if err != nil {
+		return fmt.Errorf("failed to read --timeout flag: %w", err)
+	}
+	asJSON, err := cmd.Flags().GetBool("json")
+	if err != nil {
+		return fmt.Errorf("failed to read --json flag: %w", err)
+	}
+
+	contextDir := filepath.Join(rootPath, output.ContextDir)
+	if err := RequestFlush(contextDir, timeout); err != nil {
+		return err
+	}
+
+	summary := FlushSummary{
+		Mode:       "flush",
+		RootPath:   rootPath,
+		OutputDir:  contextDir,
+		DurationMS: time.Since(start).Milliseconds(),
+	}
+	if asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(summary)
+	}
+	fmt.Printf("flush complete in %dms\n", summary.DurationMS)
+	return nil
+}
+
+// RequestFlush signals the write-behind watcher through a request file and
+// blocks until the watcher removes it (success) or writes an error into it.
+func RequestFlush(contextDir string, timeout time.Duration) error {
+	if _, err := os.Stat(filepath.Join(contextDir, writeBehindMarkerFile)); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("no write-behind watch is running; start one with `skelly watch --write-behind` or run `skelly update`")
+		}
+		return fmt.Errorf("failed to check write-behind watch: %w", err)
+	}
+
+	requestPath := filepath.Join(contextDir, flushRequestFile)
+	file, err := os.OpenFile(requestPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	switch {
+	case err == nil:
+		_ = file.Close()
+	case os.IsExist(err):
+		// Another flush is already pending; wait for the same acknowledgement.
+	default:
+		return fmt.Errorf("failed to request flush: %w", err)
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		data, err := os.ReadFile(requestPath)
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read flush request: %w", err)
+		}
+		if message := strings.TrimSpace(string(data)); message != "" {
+			_ = os.Remove(requestPath)
+			return fmt.Errorf("watch flush failed: %s", message)
+		}
+		if time.Now().After(deadline) {
+			_ = os.Remove(requestPath)
+			return fmt.Errorf("timed out after %s waiting for the write-behind watch to flush; remove %s if the watcher is no longer running", timeout, filepath.Join(output.ContextDir, writeBehindMarkerFile))
+		}
+		time.Sleep(flushPollInterval)
+	}
+}
+
+// flushBeforeQuery asks a running write-behind watch to flush so a query sees
+// the edits it holds in memory. A failed flush only warns; the query then
+// answers from the artifacts on disk.
+func flushBeforeQuery(rootPath string) {
+	contextDir := filepath.Join(rootPath, output.ContextDir)
+	if _, err := os.Stat(filepath.Join(contextDir, writeBehindMarkerFile)); err != nil {
+		return
+	}
+	if err := RequestFlush(contextDir, queryFlushTimeout); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: results may be stale: %v\n", err)
+	}
+}
@@ -4,8 +4,10 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
 	"time"
 
+	"github.com/morozRed/skelly/internal/config"
 	"github.com/morozRed/skelly/internal/fileutil"
 	"github.com/morozRed/skelly/internal/graph"
 	"github.com/morozRed/skelly/internal/languages"
@@ -24,6 +26,9 @@ func RunSetup(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	if _, err := ApplyProjectConfig(cmd, rootPath); err != nil {
+		return err
+	}
 
 	format, err := ParseOutputFormat(cmd)
 	if err != nil {
@@ -31,7 +36,7 @@ func RunSetup(cmd *cobra.Command, args []string) error {
 	}
 	fmt.Printf("setup: format=%s\n", format)
 	fmt.Println("setup: running generate...")
-	if err := GenerateContext(rootPath, nil, format, false); err != nil {
+	if err := GenerateContext(rootPath, nil, format, output.OrderImportance, false, 0, true); err != nil {
 		return err
 	}
 	fmt.Println(`setup: done. Agents can add descriptions with:
@@ -44,6 +49,33 @@ func RunGenerate(cmd *cobra.Command, args []string) error {
 	if len(args) > 0 {
 		path = args[0]
 	}
+	rootPath, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("failed to resolve path %q: %w", path, err)
+	}
+
+	info, err := os.Stat(rootPath)
+	if err != nil {
+		return fmt.Errorf("failed to access path %q: %w", rootPath, err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("path %q is not a directory", rootPath)
+	}
+	// Each root applies its own config, so the top-level one must not be
+	// applied to the flags first.
+	allRoots, err := nav.OptionalBoolFlag(cmd, "all-roots", false)
+	if err != nil {
+		return err
+	}
+	if allRoots {
+		return generateAllRoots(cmd, rootPath)
+	}
+	if _, err := ApplyProjectConfig(cmd, rootPath); err != nil {
+		return err
+	}
+	if err := ApplyArtifactCompression(cmd); err != nil {
+		return err
+	}
 
 	languageFilter, err := ParseLanguageFilter(cmd)
 	if err != nil {
@@ -53,74 +85,140 @@ func RunGenerate(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	order, err := ParseIndexOrder(cmd)
+	if err != nil {
+		return err
+	}
 	asJSON, err := cmd.Flags().GetBool("json")
 	if err != nil {
 		return fmt.Errorf("failed to read --json flag: %w", err)
 	}
-
-	rootPath, err := filepath.Abs(path)
+	jobs, err := ParseJobs(cmd)
 	if err != nil {
-		return fmt.Errorf("failed to resolve path %q: %w", path, err)
+		return err
 	}
-
-	info, err := os.Stat(rootPath)
+	backend, err := ParseStateBackend(cmd)
 	if err != nil {
-		return fmt.Errorf("failed to access path %q: %w", rootPath, err)
+		return err
 	}
-	if !info.IsDir() {
-		return fmt.Errorf("path %q is not a directory", rootPath)
+	noGitignore, err := nav.OptionalBoolFlag(cmd, "no-gitignore", false)
+	if err != nil {
+		return err
+	}
+	if err := ApplyStateBackend(rootPath, backend, asJSON); err != nil {
+		return err
 	}
 
-	return GenerateContext(rootPath, languageFilter, format, asJSON)
+	return GenerateContext(rootPath, languageFilter, format, order, asJSON, jobs, !noGitignore)
 }
 
-func GenerateContext(rootPath string, languageFilter map[string]bool, format output.Format, asJSON bool) error {
-	start := time.Now()
-	ignoreRules, err := LoadIgnoreRules(rootPath)
+// GenerateContext runs a full generate and prints its summary. jobs <= 0
+// parses with one worker per CPU; gitignore false skips .gitignore rules.
+func GenerateContext(rootPath string, languageFilter map[string]bool, format output.Format, order output.Order, asJSON bool, jobs int, gitignore bool) error {
+	summary, err := generateContext(rootPath, languageFilter, format, order, asJSON, jobs, gitignore)
 	if err != nil {
 		return err
 	}
+	return PrintRunSummary(summary, asJSON)
+}
+
+func generateContext(rootPath string, languageFilter map[string]bool, format output.Format, order output.Order, asJSON bool, jobs int, gitignore bool) (RunSummary, error) {
+	start := time.Now()
+	timer := newPhaseTimer("generate")
+	endScan := timer.track("scan")
+	ignoreRules, err := loadIgnoreRules(rootPath, gitignore)
+	if err != nil {
+		return RunSummary{}, err
+	}
+	cfg, err := config.Load(rootPath)
+	if err != nil {
+		return RunSummary{}, err
+	}
+	timer.sampleHeap = cfg.Metrics != ""
 
 	contextDir := filepath.Join(rootPath, output.ContextDir)
-	previousOutputHashes, _ := LoadOutputHashesFromState(contextDir)
+	// A missing or corrupt previous state only costs output-hash and alias history.
+	previousState, _ := state.Load(contextDir)
+	previousOutputHashes := make(map[string]string)
+	if previousState != nil {
+		previousOutputHashes = CloneOutputHashes(previousState.OutputHashes)
+	}
 
-	registry := languages.NewDefaultRegistry()
+	if jobs <= 0 {
+		jobs = runtime.NumCPU()
+	}
+	registry := NewRegistry(cfg)
 	parsedCount := 0
 	progress := newParseProgressReporter("generate", 0, asJSON)
-	parseResult, err := registry.ParseDirectoryWithProgress(rootPath, ignoreRules, func(step parser.ParseProgress) {
-		parsedCount = step.Count
-		progress.Update(step.File, step.Count)
+	endParse := func(...any) {}
+	parseResult, err := registry.ParseDirectoryWithOptions(rootPath, ignoreRules, parser.ParseOptions{
+		Jobs:      jobs,
+		NewWorker: languages.NewDefaultRegistry,
+		OnProgress: func(step parser.ParseProgress) {
+			parsedCount = step.Count
+			progress.Update(step.File, step.Count)
+		},
+		OnScan: func(files int) {
+			endScan("files", files)
+			endParse = timer.track("parse")
+		},
 	})
 	progress.Done(parsedCount)
 	if err != nil {
-		return fmt.Errorf("failed to parse source files: %w", err)
+		return RunSummary{}, fmt.Errorf("failed to parse source files: %w", err)
 	}
+	endParse("files", len(parseResult.Files), "jobs", jobs, "issues", len(parseResult.Issues))
+	endGraph := timer.track("graph")
+	NormalizeIssueLanguages(registry, parseResult.Issues)
 	ReportParseIssues(parseResult.Issues)
 	parseResult.Files = FilterFilesByLanguage(parseResult.Files, languageFilter)
+	external := NewExternalFiles(cfg)
 	for i := range parseResult.Files {
+		external.Mark(&parseResult.Files[i])
+		SkipFileContents(&parseResult.Files[i], cfg)
 		fileutil.EnsureSymbolIDs(&parseResult.Files[i])
 	}
 
-	g := graph.BuildFromParseResult(parseResult)
+	g := graph.BuildFromParseResultWithRanks(parseResult, priorRanks(previousState))
+	updatedState := NewGeneratedState(parseResult.Files, g, order, previousState)
+	updatedState.NoGitignore = !gitignore
+	updatedState.SetParseIssues(FilterIssuesByLanguage(parseResult.Issues, languageFilter))
+	recordRanks(updatedState, g)
+	endGraph("nodes", len(g.Nodes))
+	endWrite := timer.track("write")
+	if updatedState.EnrichHash, err = ApplyEnrichSummaries(contextDir, g, updatedState.AliasTargets()); err != nil {
+		return RunSummary{}, err
+	}
 	writer := output.NewWriter(rootPath)
+	writer.SetOrder(order)
 	if err := writer.WriteAll(g, parseResult, format); err != nil {
-		return fmt.Errorf("failed to write output files: %w", err)
+		return RunSummary{}, fmt.Errorf("failed to write output files: %w", err)
+	}
+	// A full generate re-encodes every segment, refreshing all ranks.
+	navSegments, err := nav.WriteIndex(contextDir, g, updatedState.AliasTargets(), fileutil.Segments{})
+	if err != nil {
+		return RunSummary{}, fmt.Errorf("failed to write navigation index: %w", err)
 	}
-	if err := nav.WriteIndex(contextDir, g); err != nil {
-		return fmt.Errorf("failed to write navigation index: %w", err)
+	updatedState.SetNavSegments(navSegments)
+	if err := nav.WriteRoutes(contextDir, g); err != nil {
+		return RunSummary{}, fmt.Errorf("failed to write routes index: %w", err)
 	}
-	if err := search.Write(contextDir, g); err != nil {
-		return fmt.Errorf("failed to write search index: %w", err)
+	if err := nav.WriteTests(contextDir, g); err != nil {
+		return RunSummary{}, fmt.Errorf("failed to write tests index: %w", err)
 	}
-
-	if err := PersistState(contextDir, parseResult.Files, g, format); err != nil {
-		return fmt.Errorf("failed to persist state: %w", err)
+	if err := output.WriteIssues(contextDir, updatedState.ParseIssues); err != nil {
+		return RunSummary{}, fmt.Errorf("failed to write parse issues: %w", err)
 	}
-
-	updatedState, err := state.Load(contextDir)
+	searchSegments, err := search.Write(contextDir, g, fileutil.Segments{})
 	if err != nil {
-		return fmt.Errorf("failed to reload state after generate: %w", err)
+		return RunSummary{}, fmt.Errorf("failed to write search index: %w", err)
 	}
+	updatedState.SetSearchSegments(searchSegments)
+
+	if err := PersistState(contextDir, updatedState, format); err != nil {
+		return RunSummary{}, fmt.Errorf("failed to persist state: %w", err)
+	}
+	endWrite("format", format)
 
 	summary := RunSummary{
 		Mode:          "generate",
@@ -138,6 +236,12 @@ func GenerateContext(rootPath string, languageFilter map[string]bool, format out
 		ChangedFiles:  CollectFilePaths(parseResult.Files),
 		ImpactedFiles: CollectFilePaths(parseResult.Files),
 	}
+	summary.SkippedFiles = UnparsedFiles(updatedState.ParseIssues)
+	summary.Skipped = len(summary.SkippedFiles)
+	timer.finish("files", summary.Parsed, "skipped", summary.Skipped)
+	if err := WriteRunMetrics(rootPath, cfg, timer, summary, len(updatedState.OutputHashes)); err != nil {
+		return RunSummary{}, err
+	}
 
-	return PrintRunSummary(summary, asJSON)
+	return summary, nil
 }
@@ -0,0 +1,161 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/morozRed/skelly/internal/grep"
+	"github.com/morozRed/skelly/internal/nav"
+	"github.com/morozRed/skelly/internal/output"
+	"github.com/morozRed/skelly/internal/state"
+	"github.com/spf13/cobra"
+)
+
+// grepMatch is a structural match anchored to the symbol enclosing it.
+type grepMatch struct {
+	grep.Match
+	Symbol *nav.SymbolRecord `json:"symbol,omitempty"`
+}
+
+// RunGrep matches a tree-sitter pattern across the indexed files and anchors
+// every match to the innermost symbol that encloses it.
+func RunGrep(cmd *cobra.Command, args []string) error {
+	rootPath, err := resolveWorkingDirectory()
+	if err != nil {
+		return err
+	}
+	asJSON, err := cmd.Flags().GetBool("json")
+	if err != nil {
+		return fmt.Errorf("failed to read --json flag: %w", err)
+	}
+	pattern := grep.Pattern{}
+	if pattern.Call, err = cmd.Flags().GetString("call"); err != nil {
+		return fmt.Errorf("failed to read --call flag: %w", err)
+	}
+	if pattern.MinArgs, err = cmd.Flags().GetInt("min-args"); err != nil {
+		return fmt.Errorf("failed to read --min-args flag: %w", err)
+	}
+	if pattern.MinParams, err = cmd.Flags().GetInt("min-params"); err != nil {
+		return fmt.Errorf("failed to read --min-params flag: %w", err)
+	}
+	if pattern.Query, err = cmd.Flags().GetString("query"); err != nil {
+		return fmt.Errorf("failed to read --query flag: %w", err)
+	}
+	fileFilter, err := cmd.Flags().GetString("file")
+	if err != nil {
+		return fmt.Errorf("failed to read --file flag: %w", err)
+	}
+	limit, err := cmd.Flags().GetInt("limit")
+	if err != nil {
+		return fmt.Errorf("failed to read --limit flag: %w", err)
+	}
+	languageFilter, err := nav.OptionalLanguageFilter(cmd, "lang")
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(pattern.Query) != "" && len(languageFilter) == 0 {
+		return fmt.Errorf("--query needs --lang, since node types differ between grammars")
+	}
+
+	searcher, err := grep.NewSearcher(pattern)
+	if err != nil {
+		return err
+	}
+	defer searcher.Close()
+
+	st, err := state.Load(filepath.Join(rootPath, output.ContextDir))
+	if err != nil {
+		return fmt.Errorf("failed to load state: %w", err)
+	}
+	if len(st.Files) == 0 {
+		return fmt.Errorf("no indexed files (run skelly generate)")
+	}
+	lookup, err := nav.LoadLookup(rootPath)
+	if err != nil {
+		return err
+	}
+	symbolsByLine := make(map[string]map[int][]*nav.IndexNode)
+	for _, node := range lookup.AllNodes() {
+		if symbolsByLine[node.File] == nil {
+			symbolsByLine[node.File] = make(map[int][]*nav.IndexNode)
+		}
+		symbolsByLine[node.File][node.Line] = append(symbolsByLine[node.File][node.Line], node)
+	}
+
+	prefix := strings.TrimSuffix(filepath.ToSlash(filepath.Clean(fileFilter)), "/")
+	files := make([]string, 0, len(st.Files))
+	for file, fileState := range st.Files {
+		if languageFilter != nil && !languageFilter[fileState.Language] {
+			continue
+		}
+		if fileFilter != "" && prefix != "." && file != prefix && !strings.HasPrefix(file, prefix+"/") {
+			continue
+		}
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	matches := make([]grepMatch, 0)
+	for _, file := range files {
+		content, err := os.ReadFile(filepath.Join(rootPath, filepath.FromSlash(file)))
+		if err != nil {
+			// Deleted since the last update.
+			continue
+		}
+		found, err := searcher.File(file, st.Files[file].Language, content)
+		if err != nil {
+			return err
+		}
+		for _, match := range found {
+			matches = append(matches, grepMatch{Match: match, Symbol: enclosingSymbol(symbolsByLine[file], match.Enclosing)})
+		}
+	}
+	total := len(matches)
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+
+	if asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(map[string]any{
+			"pattern": pattern,
+			"files":   len(files),
+			"total":   total,
+			"matches": matches,
+		})
+	}
+
+	fmt.Printf("grep matches (%d of %d in %d files)\n", len(matches), total, len(files))
+	for _, match := range matches {
+		fmt.Printf("- %s:%d:%d %s\n", match.File, match.Line, match.Column, match.Text)
+		if match.Symbol != nil {
+			fmt.Printf("  in: %s\n", match.Symbol.ID)
+		}
+	}
+	return nil
+}
+
+// enclosingSymbol returns the symbol declared on the innermost enclosing
+// line that has one, preferring the lowest ID when several share a line.
+func enclosingSymbol(byLine map[int][]*nav.IndexNode, lines []int) *nav.SymbolRecord {
+	for _, line := range lines {
+		nodes := byLine[line]
+		if len(nodes) == 0 {
+			continue
+		}
+		best := nodes[0]
+		for _, node := range nodes[1:] {
+			if node.ID < best.ID {
+				best = node
+			}
+		}
+		record := nav.SymbolRecordFromNode(best)
+		return &record
+	}
+	return nil
+}
@@ -8,9 +8,13 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"time"
 
+	"github.com/morozRed/skelly/internal/config"
 	"github.com/morozRed/skelly/internal/fileutil"
 	"github.com/morozRed/skelly/internal/graph"
+	"github.com/morozRed/skelly/internal/ignore"
+	"github.com/morozRed/skelly/internal/languages"
 	"github.com/morozRed/skelly/internal/nav"
 	"github.com/morozRed/skelly/internal/output"
 	"github.com/morozRed/skelly/internal/parser"
@@ -38,16 +42,42 @@ func RecordOutputHashes(st *state.State, contextDir string, format output.Format
 		outputPaths = []string{
 			filepath.Join(contextDir, output.SymbolsFile),
 			filepath.Join(contextDir, output.EdgesFile),
+			filepath.Join(contextDir, output.ModulesFile),
 			filepath.Join(contextDir, output.ManifestFile),
 		}
+
+		// Namespace artifacts are recorded under their uncompressed names.
+		for _, pattern := range fileutil.ArtifactVariants("*.jsonl") {
+			namespaceFiles, err := filepath.Glob(filepath.Join(contextDir, output.NamespacesDir, "*", pattern))
+			if err != nil {
+				return err
+			}
+			for _, namespaceFile := range namespaceFiles {
+				outputPaths = append(outputPaths, fileutil.TrimCompressedSuffix(namespaceFile))
+			}
+		}
+	case output.FormatCtags:
+		outputPaths = []string{filepath.Join(contextDir, output.TagsFile)}
 	default:
 		return fmt.Errorf("unsupported format %q", format)
 	}
 	outputPaths = append(outputPaths, filepath.Join(contextDir, nav.NavigationIndexFile))
+	for _, pattern := range fileutil.ArtifactVariants("*.json") {
+		shardFiles, err := filepath.Glob(filepath.Join(contextDir, nav.NavigationShardDir, pattern))
+		if err != nil {
+			return err
+		}
+		for _, shardFile := range shardFiles {
+			outputPaths = append(outputPaths, fileutil.TrimCompressedSuffix(shardFile))
+		}
+	}
+	outputPaths = append(outputPaths, filepath.Join(contextDir, nav.RoutesFile))
+	outputPaths = append(outputPaths, filepath.Join(contextDir, nav.TestsFile))
+	outputPaths = append(outputPaths, filepath.Join(contextDir, output.IssuesFile))
 	outputPaths = append(outputPaths, filepath.Join(contextDir, search.IndexFile))
 
-	for _, outputPath := range outputPaths {
-		hash, err := fileutil.HashFile(outputPath)
+	for _, outputPath := range fileutil.DedupeStrings(outputPaths) {
+		hash, err := fileutil.HashArtifact(outputPath)
 		if err != nil {
 			if os.IsNotExist(err) {
 				continue
@@ -64,6 +94,40 @@ func RecordOutputHashes(st *state.State, contextDir string, format output.Format
 	return nil
 }
 
+// priorRanks returns the PageRank and betweenness scores recorded in st for
+// seeding the next graph build.
+func priorRanks(st *state.State) graph.Ranks {
+	if st == nil {
+		return graph.Ranks{}
+	}
+	return graph.Ranks{Topology: st.RankTopology, Scores: st.Ranks, Betweenness: st.Betweenness}
+}
+
+// recordRanks stores the PageRank and betweenness scores of g in st.
+func recordRanks(st *state.State, g *graph.Graph) {
+	ranks := g.Ranks()
+	st.RankTopology = ranks.Topology
+	st.Ranks = ranks.Scores
+	st.Betweenness = ranks.Betweenness
+}
+
+// indexSegments returns the segment fingerprints recorded for the index
+// written to the output file rel (the navigation or search index), so
+// writing it again re-encodes only the files that changed.
+func indexSegments(st *state.State, rel string) fileutil.Segments {
+	segments := fileutil.Segments{Hash: st.OutputHashes[rel], Files: make(map[string]string, len(st.Files))}
+	for file, fileState := range st.Files {
+		fingerprint := fileState.SearchSegment
+		if rel == nav.NavigationIndexFile {
+			fingerprint = fileState.NavSegment
+		}
+		if fingerprint != "" {
+			segments.Files[filepath.ToSlash(file)] = fingerprint
+		}
+	}
+	return segments
+}
+
 func IsCorruptStateError(err error) bool {
 	var syntaxErr *json.SyntaxError
 	if errors.As(err, &syntaxErr) {
@@ -73,14 +137,6 @@ func IsCorruptStateError(err error) bool {
 	return errors.As(err, &typeErr)
 }
 
-func LoadOutputHashesFromState(contextDir string) (map[string]string, error) {
-	st, err := state.Load(contextDir)
-	if err != nil {
-		return nil, err
-	}
-	return CloneOutputHashes(st.OutputHashes), nil
-}
-
 func CloneOutputHashes(input map[string]string) map[string]string {
 	out := make(map[string]string, len(input))
 	for k, v := range input {
@@ -120,7 +176,7 @@ func OutputsNeedRefresh(st *state.State, contextDir string, format output.Format
 		if _, ok := st.OutputHashes[file]; !ok {
 			return true
 		}
-		if _, err := os.Stat(filepath.Join(contextDir, file)); err != nil {
+		if _, err := os.Stat(fileutil.ArtifactPath(filepath.Join(contextDir, file))); err != nil {
 			return true
 		}
 	}
@@ -142,9 +198,11 @@ func OutputsNeedRefresh(st *state.State, contextDir string, format output.Format
 func RequiredOutputFiles(format output.Format) []string {
 	switch format {
 	case output.FormatText:
-		return []string{output.IndexFile, output.GraphFile, nav.NavigationIndexFile, search.IndexFile}
+		return []string{output.IndexFile, output.GraphFile, nav.NavigationIndexFile, nav.RoutesFile, nav.TestsFile, search.IndexFile}
 	case output.FormatJSONL:
-		return []string{output.SymbolsFile, output.EdgesFile, output.ManifestFile, nav.NavigationIndexFile, search.IndexFile}
+		return []string{output.SymbolsFile, output.EdgesFile, output.ModulesFile, output.ManifestFile, nav.NavigationIndexFile, nav.RoutesFile, nav.TestsFile, search.IndexFile}
+	case output.FormatCtags:
+		return []string{output.TagsFile, nav.NavigationIndexFile, nav.RoutesFile, nav.TestsFile, search.IndexFile}
 	default:
 		return nil
 	}
@@ -198,6 +256,109 @@ func FilterFilesByLanguage(files []parser.FileSymbols, languageFilter map[string
 	return filtered
 }
 
+// FilterIssuesByLanguage keeps the parse issues of files in languageFilter
+// and those that name no language; a nil filter keeps every issue.
+func FilterIssuesByLanguage(issues []parser.ParseIssue, languageFilter map[string]bool) []parser.ParseIssue {
+	if len(languageFilter) == 0 {
+		return issues
+	}
+	filtered := make([]parser.ParseIssue, 0, len(issues))
+	for _, issue := range issues {
+		if issue.Language == "" || languageFilter[issue.Language] {
+			filtered = append(filtered, issue)
+		}
+	}
+	return filtered
+}
+
+// NewRegistry returns the default parser registry with the project
+// config's file size limit and the extractions it turns off per language.
+func NewRegistry(cfg config.Config) *parser.Registry {
+	registry := languages.NewDefaultRegistry()
+	if cfg.MaxFileBytes > 0 {
+		registry.SetMaxFileBytes(int64(cfg.MaxFileBytes))
+	}
+	if len(cfg.Parsers) > 0 {
+		features := make(map[string]parser.Features, len(cfg.Parsers))
+		for language, parserFeatures := range cfg.Parsers {
+			features[language] = parser.Features(parserFeatures)
+		}
+		registry.SetFeatures(features)
+	}
+	return registry
+}
+
+// NormalizeIssueLanguages names the language of each file issue the way
+// FileSymbols does, so JavaScript files the TypeScript parser failed on
+// report javascript.
+func NormalizeIssueLanguages(registry *parser.Registry, issues []parser.ParseIssue) {
+	for i := range issues {
+		if issues[i].Language == "" {
+			continue
+		}
+		if language, ok := languages.FileLanguage(registry, issues[i].File); ok {
+			issues[i].Language = language
+		}
+	}
+}
+
+// UnparsedFiles returns the sorted files issues leave unparsed: parse
+// errors and files skipped for their size or binary content.
+func UnparsedFiles(issues []parser.ParseIssue) []string {
+	files := make([]string, 0)
+	for _, issue := range issues {
+		if issue.Severity == "error" || issue.Reason != "" {
+			files = append(files, issue.File)
+		}
+	}
+	files = fileutil.DedupeStrings(files)
+	sort.Strings(files)
+	return files
+}
+
+// SkipFileContents drops the symbols and imports of a parsed file the
+// project config skips (skip_generated, skip_build_ignored). The file itself
+// is kept so its hash is tracked and update does not reparse it every run.
+func SkipFileContents(file *parser.FileSymbols, cfg config.Config) {
+	if (cfg.SkipGenerated && file.Generated) || (cfg.SkipBuildIgnored && parser.BuildIgnored(file.BuildConstraint)) {
+		file.Symbols = nil
+		file.Imports = nil
+		file.ImportAliases = nil
+	}
+}
+
+// ExternalFiles classifies parsed files as external code: vendored and
+// generated directories (parser.IsExternalPath) and the files matching the
+// project config's external patterns.
+type ExternalFiles struct {
+	patterns []ignore.Pattern
+}
+
+// NewExternalFiles compiles the external patterns of cfg.
+func NewExternalFiles(cfg config.Config) ExternalFiles {
+	var external ExternalFiles
+	for _, entry := range cfg.External {
+		if pattern, ok := ignore.ParsePattern(strings.TrimPrefix(strings.TrimSpace(entry), "./")); ok {
+			external.patterns = append(external.patterns, pattern)
+		}
+	}
+	return external
+}
+
+// Mark sets whether file is external.
+func (e ExternalFiles) Mark(file *parser.FileSymbols) {
+	file.External = parser.IsExternalPath(file.Path) || e.matches(filepath.ToSlash(file.Path))
+}
+
+func (e ExternalFiles) matches(file string) bool {
+	for _, pattern := range e.patterns {
+		if pattern.Matches(file) {
+			return true
+		}
+	}
+	return false
+}
+
 func ReportParseIssues(issues []parser.ParseIssue) {
 	for _, issue := range issues {
 		if issue.Language != "" {
@@ -208,12 +369,37 @@ func ReportParseIssues(issues []parser.ParseIssue) {
 	}
 }
 
-func PersistState(contextDir string, files []parser.FileSymbols, g *graph.Graph, format output.Format) error {
+// NewGeneratedState builds state for a full generate. When previous is
+// non-nil its forwarding aliases carry over and symbols whose IDs changed
+// since the previous index are forwarded.
+func NewGeneratedState(files []parser.FileSymbols, g *graph.Graph, order output.Order, previous *state.State) *state.State {
 	st := state.NewState()
+	st.IndexOrder = string(order)
+	if previous != nil {
+		st.Backend = previous.Backend
+		st.Calibration = previous.Calibration
+	}
+	touched := make([]string, 0, len(files))
 	for _, file := range files {
 		st.SetFileData(file)
+		touched = append(touched, file.Path)
+	}
+	if previous != nil {
+		// Old-scheme IDs forward as "migrated" rather than as moves.
+		previous.MigrateSymbolIDs(time.Now())
+		st.Aliases = previous.Aliases
+		before := make(map[string][]parser.Symbol, len(previous.Files))
+		for path, fileState := range previous.Files {
+			before[path] = fileState.Symbols
+		}
+		st.ForwardSymbols(before, touched, time.Now())
 	}
 	fileutil.ApplyGraphDependencies(st, g, nil)
+	st.RecordCalibration(CalibrationRunFromGraph(g, time.Now()))
+	return st
+}
+
+func PersistState(contextDir string, st *state.State, format output.Format) error {
 	if err := RecordOutputHashes(st, contextDir, format); err != nil {
 		return err
 	}
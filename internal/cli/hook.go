@@ -40,12 +40,20 @@ func RunInstallHook(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to read existing hook: %w", err)
 	}
 
-	updated := UpsertSkellyHook(existing, repoRoot)
+	stageArtifacts, err := cmd.Flags().GetBool("stage-artifacts")
+	if err != nil {
+		return fmt.Errorf("failed to read --stage-artifacts flag: %w", err)
+	}
+
+	updated := UpsertSkellyHook(existing, repoRoot, stageArtifacts)
 	if err := os.WriteFile(hookPath, []byte(updated), 0755); err != nil {
 		return fmt.Errorf("failed to write hook: %w", err)
 	}
 
 	fmt.Printf("Installed pre-commit hook at %s\n", hookPath)
+	if stageArtifacts {
+		fmt.Println("The hook stages regenerated context artifacts with the commit.")
+	}
 	return nil
 }
 
@@ -68,8 +76,8 @@ func ResolveGitPaths(workingDir string) (repoRoot string, gitDir string, err err
 	return repoRoot, gitDir, nil
 }
 
-func UpsertSkellyHook(existingHook, repoRoot string) string {
-	block := BuildSkellyHookBlock(repoRoot)
+func UpsertSkellyHook(existingHook, repoRoot string, stageArtifacts bool) string {
+	block := BuildSkellyHookBlock(repoRoot, stageArtifacts)
 
 	if existingHook == "" {
 		return "#!/bin/sh\n\n" + block + "\n"
@@ -90,12 +98,30 @@ func UpsertSkellyHook(existingHook, repoRoot string) string {
 	return base + "\n" + block + "\n"
 }
 
-func BuildSkellyHookBlock(repoRoot string) string {
+// BuildSkellyHookBlock returns the managed pre-commit block. With
+// stageArtifacts, hook-verify stages the regenerated artifacts before it
+// checks them, so they land in the commit that changed the sources.
+func BuildSkellyHookBlock(repoRoot string, stageArtifacts bool) string {
+	verify := "skelly hook-verify"
+	if stageArtifacts {
+		verify += " --stage-artifacts"
+	}
+	// JSONL artifacts may be stored compressed (generate --compress gzip|zstd).
+	jsonlArtifact := func(name string) string {
+		checks := make([]string, 0, len(fileutil.CompressedSuffixes)+1)
+		for _, variant := range fileutil.ArtifactVariants(name) {
+			checks = append(checks, fmt.Sprintf("[ -f \"$context_dir/%s\" ]", variant))
+		}
+		return "{ " + strings.Join(checks, " || ") + "; }"
+	}
 	return fmt.Sprintf(
-		"%s\nrepo_root=%q\ncontext_dir=\"$repo_root/%s\"\nif command -v skelly >/dev/null 2>&1; then\n  if [ -f \"$context_dir/manifest.json\" ] && [ -f \"$context_dir/symbols.jsonl\" ] && [ -f \"$context_dir/edges.jsonl\" ]; then\n    (cd \"$repo_root\" && skelly update --format jsonl) || exit 1\n  else\n    (cd \"$repo_root\" && skelly update) || exit 1\n  fi\nfi\n%s",
+		"%s\nrepo_root=%q\ncontext_dir=\"$repo_root/%s\"\nif command -v skelly >/dev/null 2>&1; then\n  if [ -f \"$context_dir/manifest.json\" ] && %s && %s; then\n    (cd \"$repo_root\" && skelly update --quick --format jsonl || skelly update --format jsonl) || exit 1\n  else\n    (cd \"$repo_root\" && skelly update --quick || skelly update) || exit 1\n  fi\n  (cd \"$repo_root\" && %s) || exit 1\nfi\n%s",
 		HookStart,
 		repoRoot,
 		output.ContextDir,
+		jsonlArtifact(output.SymbolsFile),
+		jsonlArtifact(output.EdgesFile),
+		verify,
 		HookEnd,
 	)
 }
@@ -1,14 +1,18 @@
 package cli
 
 import (
+	"os"
+	"os/exec"
+	"path/filepath"
 	"strings"
 	"testing"
 
+	"github.com/morozRed/skelly/internal/fileutil"
 	"github.com/morozRed/skelly/internal/output"
 )
 
 func TestBuildSkellyHookBlockPreservesFormat(t *testing.T) {
-	block := BuildSkellyHookBlock("/repo/path")
+	block := BuildSkellyHookBlock("/repo/path", false)
 
 	if !strings.Contains(block, "context_dir=\"$repo_root/"+output.ContextDir+"\"") {
 		t.Fatalf("expected hook block to include context dir detection, got:\n%s", block)
@@ -24,11 +28,49 @@ func TestBuildSkellyHookBlockPreservesFormat(t *testing.T) {
 			t.Fatalf("expected hook block to contain %q, got:\n%s", expected, block)
 		}
 	}
+	if strings.Contains(block, "--stage-artifacts") {
+		t.Fatalf("expected artifacts to be left unstaged by default, got:\n%s", block)
+	}
+	if staging := BuildSkellyHookBlock("/repo/path", true); !strings.Contains(staging, "skelly hook-verify --stage-artifacts) || exit 1") {
+		t.Fatalf("expected hook block to stage artifacts, got:\n%s", staging)
+	}
+}
+
+func TestSkellyHookKeepsCompressedJSONLContext(t *testing.T) {
+	shell, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("sh not available")
+	}
+	root := t.TempDir()
+	contextDir := filepath.Join(root, output.ContextDir)
+	for _, name := range []string{output.ManifestFile, output.SymbolsFile + fileutil.GzipSuffix, output.EdgesFile + fileutil.ZstdSuffix} {
+		mustWriteFile(t, filepath.Join(contextDir, name), "")
+	}
+	// A stub skelly records the commands the hook runs.
+	bin := t.TempDir()
+	calls := filepath.Join(root, "calls.txt")
+	mustWriteFile(t, filepath.Join(bin, "skelly"), "#!/bin/sh\necho \"$*\" >> \""+calls+"\"\n")
+	if err := os.Chmod(filepath.Join(bin, "skelly"), 0755); err != nil {
+		t.Fatalf("chmod failed: %v", err)
+	}
+
+	hook := exec.Command(shell, "-c", BuildSkellyHookBlock(root, false))
+	hook.Env = append(os.Environ(), "PATH="+bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	if out, err := hook.CombinedOutput(); err != nil {
+		t.Fatalf("hook failed: %v\n%s", err, out)
+	}
+	data, err := os.ReadFile(calls)
+	if err != nil {
+		t.Fatalf("failed to read stub calls: %v", err)
+	}
+	if got := strings.Split(strings.TrimSpace(string(data)), "\n"); len(got) != 2 || got[0] != "update --quick --format jsonl" || got[1] != "hook-verify" {
+		t.Fatalf("expected the hook to update the compressed context as jsonl, got %q", got)
+	}
 }
 
 func TestUpsertSkellyHookReplacesExistingBlock(t *testing.T) {
 	existing := "#!/bin/sh\n\necho before\n" + HookStart + "\nold block\n" + HookEnd + "\n\necho after\n"
-	updated := UpsertSkellyHook(existing, "/repo/path")
+	updated := UpsertSkellyHook(existing, "/repo/path", false)
 
 	if strings.Contains(updated, "old block") {
 		t.Fatalf("expected old hook block to be replaced, got:\n%s", updated)
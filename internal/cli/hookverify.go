@@ -0,0 +1,315 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/morozRed/skelly/internal/daemon"
+	"github.com/morozRed/skelly/internal/fileutil"
+	"github.com/morozRed/skelly/internal/ignore"
+	"github.com/morozRed/skelly/internal/languages"
+	"github.com/morozRed/skelly/internal/llm"
+	"github.com/morozRed/skelly/internal/nav"
+	"github.com/morozRed/skelly/internal/output"
+	"github.com/morozRed/skelly/internal/state"
+	"github.com/spf13/cobra"
+)
+
+// HookVerifySummary reports whether the staged context matches the staged sources.
+type HookVerifySummary struct {
+	Mode              string   `json:"mode"`
+	RootPath          string   `json:"root_path"`
+	OK                bool     `json:"ok"`
+	Tracked           bool     `json:"context_tracked"`
+	StagedSources     []string `json:"staged_sources,omitempty"`
+	StagedArtifacts   []string `json:"staged_artifacts,omitempty"`
+	UnstagedArtifacts []string `json:"unstaged_artifacts,omitempty"`
+	StaleSources      []string `json:"stale_sources,omitempty"`
+	UnstagedSources   []string `json:"unstaged_sources,omitempty"`
+	Problems          []string `json:"problems,omitempty"`
+	// AddedArtifacts are the artifacts --stage-artifacts added to the index.
+	AddedArtifacts []string `json:"added_artifacts,omitempty"`
+}
+
+// RunHookVerify fails when staged source changes and staged context
+// artifacts disagree, so a commit cannot silently drift from its context.
+// Repositories that do not track .skelly/.context pass trivially. With
+// --stage-artifacts it first stages the regenerated artifacts of a commit
+// that stages sources.
+func RunHookVerify(cmd *cobra.Command, args []string) error {
+	rootPath, err := resolveWorkingDirectory()
+	if err != nil {
+		return err
+	}
+	asJSON, err := cmd.Flags().GetBool("json")
+	if err != nil {
+		return fmt.Errorf("failed to read --json flag: %w", err)
+	}
+	stageArtifacts, err := nav.OptionalBoolFlag(cmd, "stage-artifacts", false)
+	if err != nil {
+		return err
+	}
+
+	var added []string
+	if stageArtifacts {
+		if added, err = StageContextArtifacts(rootPath); err != nil {
+			return err
+		}
+	}
+	summary, err := VerifyStagedContext(rootPath)
+	if err != nil {
+		return err
+	}
+	summary.AddedArtifacts = added
+	if asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(summary); err != nil {
+			return err
+		}
+	} else if summary.OK {
+		if len(added) > 0 {
+			fmt.Printf("hook-verify: staged %d context artifacts\n", len(added))
+		}
+		fmt.Printf("hook-verify: ok (staged sources=%d, staged artifacts=%d)\n", len(summary.StagedSources), len(summary.StagedArtifacts))
+	}
+	if !summary.OK {
+		return fmt.Errorf("staged context does not match staged sources:\n  %s", strings.Join(summary.Problems, "\n  "))
+	}
+	return nil
+}
+
+// VerifyStagedContext compares the git index against the context directory:
+//   - staged sources need their context changes staged too (artifacts that
+//     were regenerated but not staged are listed);
+//   - staged sources the recorded state does not reflect need `skelly update`;
+//   - staged state must not describe source edits that are left unstaged.
+func VerifyStagedContext(rootPath string) (HookVerifySummary, error) {
+	summary := HookVerifySummary{Mode: "hook-verify", RootPath: rootPath, OK: true}
+
+	contextPath := filepath.ToSlash(output.ContextDir)
+	tracked, err := gitLines(rootPath, "ls-files", "--", contextPath)
+	if err != nil {
+		return summary, err
+	}
+	if len(tracked) == 0 {
+		return summary, nil
+	}
+	summary.Tracked = true
+
+	staged, err := gitLines(rootPath, "diff", "--cached", "--name-only", "--relative")
+	if err != nil {
+		return summary, err
+	}
+	unstaged, err := gitLines(rootPath, "diff", "--name-only", "--relative")
+	if err != nil {
+		return summary, err
+	}
+	isArtifact, isSource, err := contextFileClassifier(rootPath)
+	if err != nil {
+		return summary, err
+	}
+
+	for _, file := range staged {
+		switch {
+		case isArtifact(file):
+			summary.StagedArtifacts = append(summary.StagedArtifacts, file)
+		case isSource(file):
+			summary.StagedSources = append(summary.StagedSources, file)
+		}
+	}
+	unstagedSources := make([]string, 0)
+	for _, file := range unstaged {
+		switch {
+		case isArtifact(file):
+			summary.UnstagedArtifacts = append(summary.UnstagedArtifacts, file)
+		case isSource(file):
+			unstagedSources = append(unstagedSources, file)
+		}
+	}
+
+	// Only recorded hashes are needed, which a binary state serves without
+	// decoding per-file records.
+	st, err := state.OpenStore(filepath.Join(rootPath, output.ContextDir))
+	if err != nil {
+		return summary, fmt.Errorf("failed to load state: %w", err)
+	}
+	defer st.Close()
+
+	if len(summary.StagedSources) > 0 {
+		if len(summary.UnstagedArtifacts) > 0 {
+			summary.Problems = append(summary.Problems, fmt.Sprintf(
+				"context artifacts were regenerated but not staged: %s (run `git add %s`)",
+				SummarizePaths(summary.UnstagedArtifacts, 8), contextPath))
+		}
+		for _, file := range summary.StagedSources {
+			if !stateMatchesWorkingTree(rootPath, st, file) {
+				summary.StaleSources = append(summary.StaleSources, file)
+			}
+		}
+		if len(summary.StaleSources) > 0 {
+			summary.Problems = append(summary.Problems, fmt.Sprintf(
+				"staged sources are not reflected in the context: %s (run `skelly update && git add %s`)",
+				SummarizePaths(summary.StaleSources, 8), contextPath))
+		}
+	}
+
+	if len(summary.StagedArtifacts) > 0 {
+		// An unstaged edit whose hash the state already records means the
+		// staged context was generated from source that is not being committed.
+		for _, file := range unstagedSources {
+			if _, ok := st.Hash(file); ok && stateMatchesWorkingTree(rootPath, st, file) {
+				summary.UnstagedSources = append(summary.UnstagedSources, file)
+			}
+		}
+		if len(summary.UnstagedSources) > 0 {
+			summary.Problems = append(summary.Problems, fmt.Sprintf(
+				"staged context includes unstaged source changes: %s (stage them, or stash them and rerun `skelly update`)",
+				SummarizePaths(summary.UnstagedSources, 8)))
+		}
+	}
+
+	summary.OK = len(summary.Problems) == 0
+	return summary, nil
+}
+
+// contextRuntimeFiles are written into the context dir by a running daemon or
+// write-behind watch and never belong in the committed context.
+var contextRuntimeFiles = []string{daemon.SocketFile, daemon.LogFile, writeBehindMarkerFile, flushRequestFile}
+
+// StageContextArtifacts git-adds the regenerated context artifacts when the
+// context is tracked and sources are staged, returning the artifacts it
+// staged. Runtime files and in-flight temp files are left out. It refuses when the context on disk is not in the format that is
+// committed (text and JSONL artifacts are different files), since staging it
+// would commit a second artifact set.
+func StageContextArtifacts(rootPath string) ([]string, error) {
+	contextPath := filepath.ToSlash(output.ContextDir)
+	tracked, err := gitLines(rootPath, "ls-files", "--", contextPath)
+	if err != nil || len(tracked) == 0 {
+		return nil, err
+	}
+	staged, err := gitLines(rootPath, "diff", "--cached", "--name-only", "--relative")
+	if err != nil {
+		return nil, err
+	}
+	_, isSource, err := contextFileClassifier(rootPath)
+	if err != nil {
+		return nil, err
+	}
+	stagesSources := false
+	for _, file := range staged {
+		stagesSources = stagesSources || isSource(file)
+	}
+	if !stagesSources {
+		return nil, nil
+	}
+
+	committed := trackedContextFormat(tracked)
+	onDisk := llm.DetectContextFormat(filepath.Join(rootPath, output.ContextDir))
+	if committed != onDisk {
+		return nil, fmt.Errorf("refusing to stage %s: the committed context is %s but the regenerated one is %s (run `skelly generate --format %s`, or set format in .skelly/config.yaml)",
+			contextPath, committed, onDisk, committed)
+	}
+	addArgs := []string{"add", "-A", "--", contextPath, ":(exclude)" + path.Join(contextPath, "*.tmp")}
+	for _, name := range contextRuntimeFiles {
+		addArgs = append(addArgs, ":(exclude)"+path.Join(contextPath, name))
+	}
+	if _, err := gitLines(rootPath, addArgs...); err != nil {
+		return nil, err
+	}
+	stagedAfter, err := gitLines(rootPath, "diff", "--cached", "--name-only", "--relative", "--", contextPath)
+	if err != nil {
+		return nil, err
+	}
+	already := make(map[string]bool, len(staged))
+	for _, file := range staged {
+		already[file] = true
+	}
+	added := make([]string, 0)
+	for _, file := range stagedAfter {
+		if !already[file] {
+			added = append(added, file)
+		}
+	}
+	return added, nil
+}
+
+// trackedContextFormat names the format of the tracked context files the
+// way llm.DetectContextFormat names the one on disk.
+func trackedContextFormat(tracked []string) string {
+	files := make(map[string]bool, len(tracked))
+	for _, file := range tracked {
+		files[fileutil.TrimCompressedSuffix(path.Base(file))] = true
+	}
+	hasText := files[output.IndexFile] && files[output.GraphFile]
+	hasJSONL := files[output.SymbolsFile] && files[output.EdgesFile] && files[output.ManifestFile]
+	switch {
+	case hasText && hasJSONL:
+		return "mixed"
+	case hasJSONL:
+		return string(output.FormatJSONL)
+	case hasText:
+		return string(output.FormatText)
+	case files[output.TagsFile]:
+		return string(output.FormatCtags)
+	default:
+		return "none"
+	}
+}
+
+// contextFileClassifier returns predicates for repository paths that are
+// context artifacts and for sources skelly indexes.
+func contextFileClassifier(rootPath string) (func(string) bool, func(string) bool, error) {
+	ignoreRules, err := LoadIgnoreRules(rootPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	contextPath := filepath.ToSlash(output.ContextDir)
+	registry := languages.NewDefaultRegistry()
+	matcher := ignore.NewMatcher(ignoreRules)
+	isArtifact := func(file string) bool {
+		return strings.HasPrefix(file, contextPath+"/")
+	}
+	isSource := func(file string) bool {
+		if isArtifact(file) || matcher.ShouldIgnore(filepath.FromSlash(file), false) {
+			return false
+		}
+		_, ok := registry.GetParserForFile(file)
+		return ok
+	}
+	return isArtifact, isSource, nil
+}
+
+// stateMatchesWorkingTree reports whether recorded state agrees with the file
+// on disk: same hash, or absent from both.
+func stateMatchesWorkingTree(rootPath string, st *state.Store, file string) bool {
+	recorded, indexed := st.Hash(file)
+	hash, err := fileutil.HashFile(filepath.Join(rootPath, filepath.FromSlash(file)))
+	if err != nil {
+		return os.IsNotExist(err) && !indexed
+	}
+	return indexed && recorded == hash
+}
+
+func gitLines(rootPath string, args ...string) ([]string, error) {
+	gitCmd := exec.Command("git", args...)
+	gitCmd.Dir = rootPath
+	out, err := gitCmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git %s failed: %w", strings.Join(args, " "), err)
+	}
+	lines := make([]string, 0)
+	for _, line := range strings.Split(string(out), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	sort.Strings(lines)
+	return lines, nil
+}
@@ -2,10 +2,10 @@ package cli
 
 import (
 	"fmt"
-	"os"
 	"path/filepath"
 	"strings"
 
+	"github.com/morozRed/skelly/internal/config"
 	"github.com/morozRed/skelly/internal/fileutil"
 	"github.com/morozRed/skelly/internal/languages"
 	"github.com/morozRed/skelly/internal/llm"
@@ -20,6 +20,27 @@ func RunInit(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	cfg, err := ApplyProjectConfig(cmd, rootPath)
+	if err != nil {
+		return err
+	}
+
+	refresh, err := nav.OptionalBoolFlag(cmd, "refresh", false)
+	if err != nil {
+		return err
+	}
+	if refresh {
+		refreshed, err := llm.RefreshManagedBlocks(rootPath)
+		if err != nil {
+			return err
+		}
+		if len(refreshed) == 0 {
+			fmt.Println("Managed blocks are up to date")
+			return nil
+		}
+		fmt.Printf("Refreshed outdated managed blocks: %s\n", strings.Join(refreshed, ", "))
+		return nil
+	}
 
 	writer := output.NewWriter(rootPath)
 	if err := writer.Init(); err != nil {
@@ -27,11 +48,7 @@ func RunInit(cmd *cobra.Command, args []string) error {
 	}
 
 	contextDir := filepath.Join(rootPath, output.ContextDir)
-	statePath := filepath.Join(contextDir, state.StateFile)
-	if _, err := os.Stat(statePath); err != nil {
-		if !os.IsNotExist(err) {
-			return fmt.Errorf("failed to inspect state file: %w", err)
-		}
+	if _, ok := state.Path(contextDir); !ok {
 		if err := state.NewState().Save(contextDir); err != nil {
 			return fmt.Errorf("failed to write initial state: %w", err)
 		}
@@ -78,7 +95,15 @@ func RunInit(cmd *cobra.Command, args []string) error {
 	}
 	if hasSources {
 		fmt.Println("Running initial generate...")
-		if err := GenerateContext(rootPath, nil, format, false); err != nil {
+		languageFilter, err := languages.ParseLanguageList(cfg.Languages)
+		if err != nil {
+			return fmt.Errorf("invalid %s languages: %w", config.File, err)
+		}
+		order, err := output.ParseOrder(cfg.Order)
+		if err != nil {
+			return fmt.Errorf("invalid %s order: %w", config.File, err)
+		}
+		if err := GenerateContext(rootPath, languageFilter, format, order, false, cfg.Jobs, true); err != nil {
 			return err
 		}
 	}
@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/morozRed/skelly/internal/fileutil"
+	"github.com/morozRed/skelly/internal/nav"
+	"github.com/morozRed/skelly/internal/output"
+	"github.com/morozRed/skelly/internal/parser"
+	"github.com/spf13/cobra"
+)
+
+// RunIssues lists the parse warnings and errors recorded by the last
+// generate, narrowed by --severity, --lang and --file.
+func RunIssues(cmd *cobra.Command, args []string) error {
+	rootPath, err := resolveWorkingDirectory()
+	if err != nil {
+		return err
+	}
+	asJSON, err := cmd.Flags().GetBool("json")
+	if err != nil {
+		return fmt.Errorf("failed to read --json flag: %w", err)
+	}
+	severity, err := cmd.Flags().GetString("severity")
+	if err != nil {
+		return fmt.Errorf("failed to read --severity flag: %w", err)
+	}
+	if severity != "" && severity != "error" && severity != "warning" {
+		return fmt.Errorf("--severity must be error or warning")
+	}
+	fileFilter, err := cmd.Flags().GetString("file")
+	if err != nil {
+		return fmt.Errorf("failed to read --file flag: %w", err)
+	}
+	languageFilter, err := nav.OptionalLanguageFilter(cmd, "lang")
+	if err != nil {
+		return err
+	}
+
+	issues, err := output.LoadIssues(rootPath)
+	if err != nil {
+		return err
+	}
+	prefix := strings.TrimSuffix(filepath.ToSlash(filepath.Clean(fileFilter)), "/")
+	matched := make([]parser.ParseIssue, 0, len(issues))
+	counts := map[string]int{"error": 0, "warning": 0}
+	for _, issue := range issues {
+		if severity != "" && issue.Severity != severity {
+			continue
+		}
+		if languageFilter != nil && !languageFilter[issue.Language] {
+			continue
+		}
+		file := filepath.ToSlash(issue.File)
+		if fileFilter != "" && prefix != "." && file != prefix && !strings.HasPrefix(file, prefix+"/") {
+			continue
+		}
+		matched = append(matched, issue)
+		counts[issue.Severity]++
+	}
+
+	if asJSON {
+		return fileutil.PrintJSON(map[string]any{
+			"mode":     "issues",
+			"errors":   counts["error"],
+			"warnings": counts["warning"],
+			"issues":   matched,
+		})
+	}
+	for _, issue := range matched {
+		if issue.Language != "" {
+			fmt.Printf("[%s] %s (%s): %s\n", issue.Severity, issue.File, issue.Language, issue.Message)
+			continue
+		}
+		fmt.Printf("[%s] %s: %s\n", issue.Severity, issue.File, issue.Message)
+	}
+	fmt.Printf("%d issues (%d errors, %d warnings)\n", len(matched), counts["error"], counts["warning"])
+	return nil
+}
@@ -0,0 +1,296 @@
+package cli
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/morozRed/skelly/internal/config"
+	"github.com/morozRed/skelly/internal/fileutil"
+	"github.com/morozRed/skelly/internal/ignore"
+	"github.com/morozRed/skelly/internal/languages"
+	"github.com/morozRed/skelly/internal/output"
+	"github.com/morozRed/skelly/internal/parser"
+	"github.com/morozRed/skelly/internal/state"
+	"github.com/spf13/cobra"
+)
+
+// Reasons a file under the root is not in the index.
+const (
+	SkipIgnored        = "ignored"
+	SkipUnsupported    = "unsupported"
+	SkipFailed         = "failed"
+	SkipTooLarge       = parser.SkipTooLarge
+	SkipBinary         = parser.SkipBinary
+	SkipLanguageFilter = "language_filter"
+	SkipGenerated      = "generated"
+	SkipBuildIgnored   = "build_ignored"
+	SkipNotIndexed     = "not_indexed"
+)
+
+// skipReasonOrder is the order skip reasons are reported in.
+var skipReasonOrder = []string{
+	SkipIgnored,
+	SkipUnsupported,
+	SkipFailed,
+	SkipTooLarge,
+	SkipBinary,
+	SkipLanguageFilter,
+	SkipGenerated,
+	SkipBuildIgnored,
+	SkipNotIndexed,
+}
+
+// LanguageReport is how much of the repository the index covers.
+// SourceFiles are the files a parser exists for; Coverage is the share of
+// them whose symbols are indexed.
+type LanguageReport struct {
+	Files       int                `json:"files"`
+	SourceFiles int                `json:"source_files"`
+	Indexed     int                `json:"indexed"`
+	Coverage    float64            `json:"coverage"`
+	Symbols     int                `json:"symbols"`
+	Languages   []LanguageCoverage `json:"languages"`
+	Skipped     []SkippedFiles     `json:"skipped"`
+}
+
+// LanguageCoverage counts the source files of one language. Skipped files
+// are those left out of the index for any reason other than a parse error.
+type LanguageCoverage struct {
+	Language string `json:"language"`
+	Files    int    `json:"files"`
+	Parsed   int    `json:"parsed"`
+	Failed   int    `json:"failed"`
+	Skipped  int    `json:"skipped"`
+	Symbols  int    `json:"symbols"`
+}
+
+// SkippedFiles groups the files skipped for one reason. Ignored directories
+// count once, with a trailing slash. Extensions breaks unsupported files
+// down by extension.
+type SkippedFiles struct {
+	Reason     string         `json:"reason"`
+	Count      int            `json:"count"`
+	Files      []string       `json:"files"`
+	Extensions map[string]int `json:"extensions,omitempty"`
+}
+
+// RunLanguages walks the repository and reports, per language, how many
+// files were found, parsed, failed and skipped, and why the rest of the
+// tree is not indexed. It reads the last generate's state but works
+// without one, reporting every source file as not indexed.
+func RunLanguages(cmd *cobra.Command, args []string) error {
+	rootPath, err := resolveWorkingDirectory()
+	if err != nil {
+		return err
+	}
+	asJSON, err := cmd.Flags().GetBool("json")
+	if err != nil {
+		return fmt.Errorf("failed to read --json flag: %w", err)
+	}
+	limit, err := cmd.Flags().GetInt("limit")
+	if err != nil {
+		return fmt.Errorf("failed to read --limit flag: %w", err)
+	}
+	if limit < 0 {
+		return fmt.Errorf("--limit must be >= 0")
+	}
+
+	report, err := BuildLanguageReport(rootPath, limit)
+	if err != nil {
+		return err
+	}
+	if asJSON {
+		return fileutil.PrintJSON(map[string]any{
+			"mode":   "languages",
+			"report": report,
+		})
+	}
+
+	fmt.Printf("indexed %d of %d source files (%.1f%%), %d symbols\n",
+		report.Indexed, report.SourceFiles, report.Coverage*100, report.Symbols)
+	for _, language := range report.Languages {
+		fmt.Printf("%s: files=%d parsed=%d failed=%d skipped=%d symbols=%d\n",
+			language.Language, language.Files, language.Parsed, language.Failed, language.Skipped, language.Symbols)
+	}
+	for _, skipped := range report.Skipped {
+		fmt.Printf("skipped %s: %d\n", skipped.Reason, skipped.Count)
+		if len(skipped.Extensions) > 0 {
+			counts := make([]string, 0, len(skipped.Extensions))
+			for extension, count := range skipped.Extensions {
+				counts = append(counts, fmt.Sprintf("%s=%d", extension, count))
+			}
+			sort.Strings(counts)
+			fmt.Printf("  extensions: %s\n", strings.Join(counts, " "))
+		}
+		for _, file := range skipped.Files {
+			fmt.Printf("  - %s\n", file)
+		}
+		if hidden := skipped.Count - len(skipped.Files); hidden > 0 {
+			fmt.Printf("  ... %d more\n", hidden)
+		}
+	}
+	return nil
+}
+
+// BuildLanguageReport classifies every file under rootPath against the
+// saved state. Each skip reason lists up to limit files; 0 lists them all.
+func BuildLanguageReport(rootPath string, limit int) (LanguageReport, error) {
+	cfg, err := config.Load(rootPath)
+	if err != nil {
+		return LanguageReport{}, err
+	}
+	ignoreRules, err := LoadIgnoreRules(rootPath)
+	if err != nil {
+		return LanguageReport{}, err
+	}
+	st, err := state.Load(filepath.Join(rootPath, output.ContextDir))
+	if err != nil {
+		if IsCorruptStateError(err) {
+			return LanguageReport{}, fmt.Errorf("state is corrupt; run `skelly generate` first")
+		}
+		return LanguageReport{}, fmt.Errorf("failed to load state: %w", err)
+	}
+	var languageFilter map[string]bool
+	if len(cfg.Languages) > 0 {
+		if languageFilter, err = languages.ParseLanguageList(cfg.Languages); err != nil {
+			return LanguageReport{}, err
+		}
+	}
+
+	failed := st.ParseFailures()
+	skippedReasons := make(map[string]string)
+	for _, issue := range st.ParseIssues {
+		if issue.Reason != "" {
+			skippedReasons[issue.File] = issue.Reason
+		}
+	}
+	registry := languages.NewDefaultRegistry()
+	matcher := ignore.NewMatcher(ignoreRules)
+	report := LanguageReport{}
+	byLanguage := make(map[string]*LanguageCoverage)
+	skipped := make(map[string]*SkippedFiles)
+	skip := func(reason, file string) {
+		group, ok := skipped[reason]
+		if !ok {
+			group = &SkippedFiles{Reason: reason, Files: make([]string, 0)}
+			skipped[reason] = group
+		}
+		group.Count++
+		if limit == 0 || len(group.Files) < limit {
+			group.Files = append(group.Files, file)
+		}
+	}
+
+	err = filepath.WalkDir(rootPath, func(current string, entry fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		relPath, err := filepath.Rel(rootPath, current)
+		if err != nil {
+			return err
+		}
+		if relPath == "." {
+			return nil
+		}
+		if entry.IsDir() && (entry.Name() == ".git" || entry.Name() == output.SkellyDir) {
+			return filepath.SkipDir
+		}
+		if matcher.ShouldIgnore(relPath, entry.IsDir()) {
+			if entry.IsDir() {
+				skip(SkipIgnored, filepath.ToSlash(relPath)+"/")
+				return filepath.SkipDir
+			}
+			report.Files++
+			skip(SkipIgnored, filepath.ToSlash(relPath))
+			return nil
+		}
+		if entry.IsDir() {
+			return nil
+		}
+
+		report.Files++
+		file := filepath.ToSlash(relPath)
+		language, ok := languages.FileLanguage(registry, file)
+		if !ok {
+			skip(SkipUnsupported, file)
+			extension := strings.ToLower(filepath.Ext(file))
+			if extension == "" {
+				extension = "(none)"
+			}
+			group := skipped[SkipUnsupported]
+			if group.Extensions == nil {
+				group.Extensions = make(map[string]int)
+			}
+			group.Extensions[extension]++
+			return nil
+		}
+
+		report.SourceFiles++
+		coverage, ok := byLanguage[language]
+		if !ok {
+			coverage = &LanguageCoverage{Language: language}
+			byLanguage[language] = coverage
+		}
+		coverage.Files++
+
+		if failed[relPath] {
+			coverage.Failed++
+			skip(SkipFailed, file)
+			return nil
+		}
+		if reason, ok := skippedReasons[relPath]; ok {
+			coverage.Skipped++
+			skip(reason, file)
+			return nil
+		}
+		fileState, indexed := st.Files[relPath]
+		switch {
+		case !indexed && languageFilter != nil && !languageFilter[language]:
+			coverage.Skipped++
+			skip(SkipLanguageFilter, file)
+		case !indexed:
+			coverage.Skipped++
+			skip(SkipNotIndexed, file)
+		case cfg.SkipGenerated && fileState.Generated:
+			coverage.Skipped++
+			skip(SkipGenerated, file)
+		case cfg.SkipBuildIgnored && parser.BuildIgnored(fileState.BuildConstraint):
+			coverage.Skipped++
+			skip(SkipBuildIgnored, file)
+		default:
+			coverage.Parsed++
+			coverage.Symbols += len(fileState.Symbols)
+			report.Indexed++
+			report.Symbols += len(fileState.Symbols)
+		}
+		return nil
+	})
+	if err != nil && !os.IsNotExist(err) {
+		return LanguageReport{}, fmt.Errorf("failed to walk %s: %w", rootPath, err)
+	}
+
+	report.Languages = make([]LanguageCoverage, 0, len(byLanguage))
+	for _, coverage := range byLanguage {
+		report.Languages = append(report.Languages, *coverage)
+	}
+	sort.Slice(report.Languages, func(i, j int) bool {
+		if report.Languages[i].Files != report.Languages[j].Files {
+			return report.Languages[i].Files > report.Languages[j].Files
+		}
+		return report.Languages[i].Language < report.Languages[j].Language
+	})
+	report.Skipped = make([]SkippedFiles, 0, len(skipped))
+	for _, reason := range skipReasonOrder {
+		if group, ok := skipped[reason]; ok {
+			report.Skipped = append(report.Skipped, *group)
+		}
+	}
+	if report.SourceFiles > 0 {
+		report.Coverage = float64(report.Indexed) / float64(report.SourceFiles)
+	}
+	return report, nil
+}
@@ -0,0 +1,124 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/morozRed/skelly/internal/nav"
+	"github.com/spf13/cobra"
+)
+
+// logger receives diagnostics for slow or surprising runs, such as the
+// per-phase timings of generate, update and enrich. Only warnings pass
+// until --verbose or --log-level lowers the level.
+var logger = newLogger(os.Stderr, slog.LevelWarn)
+
+func newLogger(w io.Writer, level slog.Level) *slog.Logger {
+	return slog.New(slog.NewTextHandler(w, &slog.HandlerOptions{Level: level}))
+}
+
+// ParseLogLevel maps a --log-level value to a slog level.
+func ParseLogLevel(value string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info":
+		return slog.LevelInfo, nil
+	case "", "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return 0, fmt.Errorf("unsupported log level %q (supported: debug, info, warn, error)", value)
+	}
+}
+
+// ConfigureLogging sets the logger level from --log-level, or debug for
+// --verbose when no level is given.
+func ConfigureLogging(cmd *cobra.Command) error {
+	value, err := OptionalStringFlag(cmd, "log-level")
+	if err != nil {
+		return err
+	}
+	level, err := ParseLogLevel(value)
+	if err != nil {
+		return fmt.Errorf("--log-level: %w", err)
+	}
+	verbose, err := nav.OptionalBoolFlag(cmd, "verbose", false)
+	if err != nil {
+		return err
+	}
+	if verbose && value == "" {
+		level = slog.LevelDebug
+	}
+	logger = newLogger(os.Stderr, level)
+	return nil
+}
+
+// phaseTimer measures the phases of a run (scan, parse, graph, write),
+// logging each at debug level as it ends and all of them at info level
+// when the run finishes. Phases that repeat, such as the batches of a
+// watch session, add up.
+type phaseTimer struct {
+	run    string
+	start  time.Time
+	phases []phaseDuration
+	// sampleHeap reads the Go heap as each phase ends, keeping the largest
+	// live heap (HeapAlloc) seen in peakHeap.
+	sampleHeap bool
+	peakHeap   uint64
+}
+
+type phaseDuration struct {
+	name     string
+	duration time.Duration
+}
+
+func newPhaseTimer(run string) *phaseTimer {
+	return &phaseTimer{run: run, start: time.Now()}
+}
+
+// track starts phase and returns the function that ends it; attrs are
+// logged with the phase's duration.
+func (t *phaseTimer) track(phase string) func(attrs ...any) {
+	start := time.Now()
+	return func(attrs ...any) {
+		elapsed := time.Since(start)
+		t.add(phase, elapsed)
+		if t.sampleHeap {
+			t.readHeap()
+		}
+		logger.Debug("phase", append([]any{"run", t.run, "phase", phase, "duration", elapsed}, attrs...)...)
+	}
+}
+
+func (t *phaseTimer) add(phase string, elapsed time.Duration) {
+	for i := range t.phases {
+		if t.phases[i].name == phase {
+			t.phases[i].duration += elapsed
+			return
+		}
+	}
+	t.phases = append(t.phases, phaseDuration{name: phase, duration: elapsed})
+}
+
+// readHeap raises peakHeap to the current live heap.
+func (t *phaseTimer) readHeap() {
+	var memory runtime.MemStats
+	runtime.ReadMemStats(&memory)
+	t.peakHeap = max(t.peakHeap, memory.HeapAlloc)
+}
+
+// finish logs the run's total duration and the breakdown by phase.
+func (t *phaseTimer) finish(attrs ...any) {
+	args := []any{"run", t.run, "total", time.Since(t.start)}
+	for _, phase := range t.phases {
+		args = append(args, phase.name, phase.duration)
+	}
+	logger.Info("run complete", append(args, attrs...)...)
+}
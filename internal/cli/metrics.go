@@ -0,0 +1,116 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"github.com/morozRed/skelly/internal/config"
+)
+
+// RunMetrics is what generate and update record about a run when metrics is
+// set in .skelly/config.yaml, so CI can chart index performance over time.
+// Each run replaces the file.
+type RunMetrics struct {
+	Run        string               `json:"run"`
+	FinishedAt string               `json:"finished_at"`
+	DurationMS int64                `json:"duration_ms"`
+	PhasesMS   map[string]int64     `json:"phases_ms"`
+	Files      MetricsFiles         `json:"files"`
+	Memory     MetricsMemory        `json:"memory"`
+	Cache      map[string]CacheRate `json:"cache"`
+}
+
+// MetricsFiles are the file counts of the run summary.
+type MetricsFiles struct {
+	Scanned  int `json:"scanned"`
+	Parsed   int `json:"parsed"`
+	Reused   int `json:"reused"`
+	Skipped  int `json:"skipped"`
+	Changed  int `json:"changed"`
+	Deleted  int `json:"deleted"`
+	Impacted int `json:"impacted"`
+}
+
+// MetricsMemory is read from the Go runtime. PeakHeapBytes is the largest
+// live heap (HeapAlloc) seen as a phase ended or the run finished; SysBytes
+// is all memory obtained from the OS by the end of the run.
+type MetricsMemory struct {
+	PeakHeapBytes uint64 `json:"peak_heap_bytes"`
+	SysBytes      uint64 `json:"sys_bytes"`
+	GCCycles      uint32 `json:"gc_cycles"`
+}
+
+// CacheRate counts the work a run reused (hits) against the work it redid.
+type CacheRate struct {
+	Hits    int     `json:"hits"`
+	Misses  int     `json:"misses"`
+	HitRate float64 `json:"hit_rate"`
+}
+
+func newCacheRate(hits, misses int) CacheRate {
+	rate := CacheRate{Hits: hits, Misses: misses}
+	if total := hits + misses; total > 0 {
+		rate.HitRate = float64(hits) / float64(total)
+	}
+	return rate
+}
+
+// WriteRunMetrics writes the metrics of a finished run to the file cfg.Metrics
+// names, relative to rootPath; it does nothing when metrics are off. outputs
+// is the number of artifacts the run tracks, of which summary.Rewritten
+// changed.
+func WriteRunMetrics(rootPath string, cfg config.Config, timer *phaseTimer, summary RunSummary, outputs int) error {
+	if cfg.Metrics == "" {
+		return nil
+	}
+	var memory runtime.MemStats
+	runtime.ReadMemStats(&memory)
+
+	metrics := RunMetrics{
+		Run:        timer.run,
+		FinishedAt: time.Now().UTC().Format(time.RFC3339),
+		DurationMS: summary.DurationMS,
+		PhasesMS:   make(map[string]int64, len(timer.phases)),
+		Files: MetricsFiles{
+			Scanned:  summary.Scanned,
+			Parsed:   summary.Parsed,
+			Reused:   summary.Reused,
+			Skipped:  summary.Skipped,
+			Changed:  summary.Changed,
+			Deleted:  summary.Deleted,
+			Impacted: summary.Impacted,
+		},
+		Memory: MetricsMemory{
+			PeakHeapBytes: max(timer.peakHeap, memory.HeapAlloc),
+			SysBytes:      memory.Sys,
+			GCCycles:      memory.NumGC,
+		},
+		Cache: map[string]CacheRate{
+			"parse":   newCacheRate(summary.Reused, summary.Parsed),
+			"outputs": newCacheRate(MaxInt(outputs-summary.Rewritten, 0), summary.Rewritten),
+		},
+	}
+	for _, phase := range timer.phases {
+		metrics.PhasesMS[phase.name] = phase.duration.Milliseconds()
+	}
+
+	data, err := json.MarshalIndent(metrics, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode metrics: %w", err)
+	}
+	path := cfg.Metrics
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(rootPath, filepath.FromSlash(path))
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to write metrics: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write metrics: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,261 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/morozRed/skelly/internal/enrich"
+	"github.com/morozRed/skelly/internal/nav"
+	"github.com/morozRed/skelly/internal/output"
+	"github.com/morozRed/skelly/internal/parser"
+	"github.com/morozRed/skelly/internal/state"
+	"github.com/spf13/cobra"
+)
+
+// OverviewRunSummary reports an `enrich overview` run.
+type OverviewRunSummary struct {
+	Mode       string `json:"mode"`
+	Target     string `json:"target,omitempty"`
+	OutputFile string `json:"output_file"`
+	// Files and Modules count the overviews written by this run.
+	Files   int `json:"files"`
+	Modules int `json:"modules"`
+	// Kept counts agent overviews left in place; Stale those of them whose
+	// sources changed since they were written.
+	Kept       int   `json:"kept,omitempty"`
+	Stale      int   `json:"stale,omitempty"`
+	Removed    int   `json:"removed,omitempty"`
+	DurationMS int64 `json:"duration_ms"`
+}
+
+// OverviewCoverage reports how many files and modules have an overview in
+// overview.jsonl, and how many overviews are stale.
+type OverviewCoverage struct {
+	Files        int `json:"files"`
+	FilesTotal   int `json:"files_total"`
+	Modules      int `json:"modules"`
+	ModulesTotal int `json:"modules_total"`
+	Stale        int `json:"stale"`
+}
+
+// RunEnrichOverview writes file and module (directory) overviews to
+// overview.jsonl. Without a description it derives them from each scope's
+// top-ranked symbols, their enrich summaries and its imports, keeping
+// overviews written by an agent; with one it records the description as the
+// overview of the given file or directory.
+func RunEnrichOverview(cmd *cobra.Command, args []string) error {
+	start := time.Now()
+	rootPath, err := resolveWorkingDirectory()
+	if err != nil {
+		return err
+	}
+	asJSON, err := cmd.Flags().GetBool("json")
+	if err != nil {
+		return fmt.Errorf("failed to read --json flag: %w", err)
+	}
+	target := "."
+	if len(args) > 0 {
+		target = filepath.ToSlash(filepath.Clean(strings.TrimSpace(args[0])))
+	}
+	description := ""
+	if len(args) > 1 {
+		description = strings.TrimSpace(strings.Join(args[1:], " "))
+	}
+
+	contextDir := filepath.Join(rootPath, output.ContextDir)
+	st, err := state.Load(contextDir)
+	if err != nil {
+		if IsCorruptStateError(err) {
+			return fmt.Errorf("state is corrupt; run `skelly generate` first")
+		}
+		return fmt.Errorf("failed to load state: %w", err)
+	}
+	if len(st.Files) == 0 {
+		return fmt.Errorf("no indexed files found; run `skelly generate` first")
+	}
+	lookup, err := nav.LoadLookup(rootPath)
+	if err != nil {
+		return err
+	}
+	outputPath := filepath.Join(contextDir, enrich.OverviewFile)
+	overviews, err := enrich.LoadOverviews(outputPath)
+	if err != nil {
+		return err
+	}
+
+	inputs := overviewInputs(st, lookup)
+	hashes := overviewHashes(st)
+	summary := OverviewRunSummary{Mode: "enrich-overview", OutputFile: outputPath}
+	if target != "." {
+		summary.Target = target
+	}
+	timestamp := time.Now().UTC().Format(time.RFC3339)
+
+	if description != "" {
+		key := enrich.OverviewKey(enrich.OverviewScopeFile, target)
+		if _, ok := inputs[key]; !ok {
+			key = enrich.OverviewKey(enrich.OverviewScopeModule, target)
+		}
+		input, ok := inputs[key]
+		if !ok {
+			return fmt.Errorf("no indexed file or directory %q", target)
+		}
+		overview := enrich.DeriveOverview(input)
+		overview.Summary = description
+		overview.Source = enrich.OverviewSourceAgent
+		overview.InputHash = hashes[key]
+		overview.UpdatedAt = timestamp
+		overviews[key] = overview
+		countOverview(&summary, overview)
+	} else {
+		for key := range overviews {
+			if _, ok := inputs[key]; !ok {
+				delete(overviews, key)
+				summary.Removed++
+			}
+		}
+		keys := make([]string, 0, len(inputs))
+		for key, input := range inputs {
+			if target == "." || input.Path == target || strings.HasPrefix(input.Path, target+"/") {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			existing, exists := overviews[key]
+			if exists && existing.Source == enrich.OverviewSourceAgent {
+				summary.Kept++
+				if existing.InputHash != hashes[key] {
+					summary.Stale++
+				}
+				continue
+			}
+			overview := enrich.DeriveOverview(inputs[key])
+			overview.InputHash = hashes[key]
+			overview.UpdatedAt = timestamp
+			if exists && existing.Summary == overview.Summary && existing.InputHash == overview.InputHash {
+				overview.UpdatedAt = existing.UpdatedAt
+			}
+			overviews[key] = overview
+			countOverview(&summary, overview)
+		}
+	}
+
+	if err := enrich.WriteOverviews(outputPath, overviews); err != nil {
+		return err
+	}
+	summary.DurationMS = time.Since(start).Milliseconds()
+	if asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(summary)
+	}
+	fmt.Printf("overviews complete in %dms\n", summary.DurationMS)
+	fmt.Printf("output: %s\n", summary.OutputFile)
+	fmt.Printf("files=%d modules=%d kept=%d stale=%d removed=%d\n", summary.Files, summary.Modules, summary.Kept, summary.Stale, summary.Removed)
+	return nil
+}
+
+func countOverview(summary *OverviewRunSummary, overview enrich.Overview) {
+	if overview.Scope == enrich.OverviewScopeModule {
+		summary.Modules++
+	} else {
+		summary.Files++
+	}
+}
+
+// overviewInputs collects what every indexed file's and directory's overview
+// is composed from, keyed by enrich.OverviewKey. Symbols carry their enrich
+// summary, or their doc comment when they have none.
+func overviewInputs(st *state.State, lookup *nav.Lookup) map[string]enrich.OverviewInput {
+	symbolsByFile := make(map[string][]enrich.OverviewSymbol)
+	for _, node := range lookup.AllNodes() {
+		summary := node.Summary
+		if summary == "" {
+			summary = node.Doc
+		}
+		symbolsByFile[node.File] = append(symbolsByFile[node.File], enrich.OverviewSymbol{
+			Name:    parser.Symbol{Name: node.Name, Container: node.Container}.QualifiedName(),
+			Kind:    node.Kind,
+			Rank:    node.Rank,
+			Summary: summary,
+		})
+	}
+
+	inputs := make(map[string]enrich.OverviewInput)
+	for file, fileState := range st.Files {
+		inputs[enrich.OverviewKey(enrich.OverviewScopeFile, file)] = enrich.OverviewInput{
+			Scope:   enrich.OverviewScopeFile,
+			Path:    file,
+			Files:   1,
+			Symbols: symbolsByFile[file],
+			Imports: fileState.Imports,
+		}
+		module := enrich.ModuleOf(file)
+		key := enrich.OverviewKey(enrich.OverviewScopeModule, module)
+		input := inputs[key]
+		input.Scope = enrich.OverviewScopeModule
+		input.Path = module
+		input.Files++
+		input.Symbols = append(input.Symbols, symbolsByFile[file]...)
+		input.Imports = append(input.Imports, fileState.Imports...)
+		inputs[key] = input
+	}
+	return inputs
+}
+
+// overviewHashes returns the input hash of every file and directory
+// overview, keyed by enrich.OverviewKey: a file's content hash, or the
+// combined hashes of a directory's files.
+func overviewHashes(st *state.State) map[string]string {
+	hashes := make(map[string]string)
+	members := make(map[string]map[string]string)
+	for file, fileState := range st.Files {
+		hashes[enrich.OverviewKey(enrich.OverviewScopeFile, file)] = fileState.Hash
+		module := enrich.ModuleOf(file)
+		if members[module] == nil {
+			members[module] = make(map[string]string)
+		}
+		members[module][file] = fileState.Hash
+	}
+	for module, fileHashes := range members {
+		hashes[enrich.OverviewKey(enrich.OverviewScopeModule, module)] = enrich.ModuleInputHash(fileHashes)
+	}
+	return hashes
+}
+
+// overviewCoverage compares overview.jsonl against the indexed files; nil
+// when it cannot be read.
+func overviewCoverage(contextDir string, st *state.State) *OverviewCoverage {
+	overviews, err := enrich.LoadOverviews(filepath.Join(contextDir, enrich.OverviewFile))
+	if err != nil {
+		return nil
+	}
+	coverage := &OverviewCoverage{}
+	for key, hash := range overviewHashes(st) {
+		isModule := strings.HasPrefix(key, enrich.OverviewScopeModule+":")
+		if isModule {
+			coverage.ModulesTotal++
+		} else {
+			coverage.FilesTotal++
+		}
+		overview, ok := overviews[key]
+		if !ok {
+			continue
+		}
+		if isModule {
+			coverage.Modules++
+		} else {
+			coverage.Files++
+		}
+		if overview.InputHash != hash {
+			coverage.Stale++
+		}
+	}
+	return coverage
+}
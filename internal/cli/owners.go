@@ -0,0 +1,122 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/morozRed/skelly/internal/fileutil"
+	"github.com/morozRed/skelly/internal/nav"
+	"github.com/morozRed/skelly/internal/owners"
+	"github.com/spf13/cobra"
+)
+
+// ownerTarget is a file or symbol passed to skelly owners.
+type ownerTarget struct {
+	Query  string   `json:"query"`
+	Symbol string   `json:"symbol,omitempty"`
+	File   string   `json:"file"`
+	Owners []string `json:"owners"`
+}
+
+// RunOwners resolves the CODEOWNERS owners of files and symbols and groups
+// the files they impact by owner. Without arguments it groups the files
+// impacted by pending working-tree changes, as reported by skelly status.
+func RunOwners(cmd *cobra.Command, args []string) error {
+	rootPath, err := resolveWorkingDirectory()
+	if err != nil {
+		return err
+	}
+	asJSON, err := cmd.Flags().GetBool("json")
+	if err != nil {
+		return fmt.Errorf("failed to read --json flag: %w", err)
+	}
+
+	codeowners, err := owners.Load(rootPath)
+	if err != nil {
+		return err
+	}
+	if codeowners == nil {
+		fmt.Fprintf(os.Stderr, "warning: no CODEOWNERS file found (looked in %s); every file is unowned\n", strings.Join(owners.Locations, ", "))
+	}
+
+	st, _, changed, deleted, err := pendingChanges(rootPath)
+	if err != nil {
+		return err
+	}
+
+	targets := make([]ownerTarget, 0, len(args))
+	if len(args) > 0 {
+		var lookup *nav.Lookup
+		changed, deleted = nil, nil
+		for _, query := range args {
+			target := ownerTarget{Query: query}
+			file := filepath.ToSlash(filepath.Clean(query))
+			if _, ok := st.Files[file]; ok {
+				target.File = file
+			} else {
+				if lookup == nil {
+					if lookup, err = nav.LoadLookup(rootPath); err != nil {
+						return err
+					}
+				}
+				node, err := nav.ResolveSingleSymbol(lookup, query)
+				if err != nil {
+					return fmt.Errorf("%q is neither an indexed file nor a symbol: %w", query, err)
+				}
+				target.Symbol, target.File = node.ID, node.File
+			}
+			target.Owners = codeowners.For(target.File)
+			if target.Owners == nil {
+				target.Owners = []string{}
+			}
+			targets = append(targets, target)
+			changed = append(changed, target.File)
+		}
+		changed = fileutil.DedupeStrings(changed)
+	}
+	impacted, _ := fileutil.ImpactedWithReasons(st, changed, deleted)
+	groups := codeowners.GroupFiles(impacted)
+
+	if asJSON {
+		result := map[string]any{
+			"impacted": impacted,
+			"groups":   groups,
+		}
+		if codeowners != nil {
+			result["codeowners"] = codeowners.Path
+		}
+		if len(args) > 0 {
+			result["targets"] = targets
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(result)
+	}
+
+	for _, target := range targets {
+		owned := strings.Join(target.Owners, " ")
+		if owned == "" {
+			owned = owners.Unowned
+		}
+		if target.Symbol != "" {
+			fmt.Printf("%s (%s): %s\n", target.Symbol, target.File, owned)
+		} else {
+			fmt.Printf("%s: %s\n", target.File, owned)
+		}
+	}
+	if len(impacted) == 0 {
+		fmt.Println("no impacted files")
+		return nil
+	}
+	fmt.Printf("impacted files by owner (%d files, %d owners)\n", len(impacted), len(groups))
+	for _, group := range groups {
+		fmt.Printf("%s (%d)\n", group.Owner, len(group.Files))
+		for _, file := range group.Files {
+			fmt.Printf("  - %s\n", file)
+		}
+	}
+	return nil
+}
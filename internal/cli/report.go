@@ -0,0 +1,244 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/morozRed/skelly/internal/fileutil"
+	"github.com/morozRed/skelly/internal/owners"
+	"github.com/morozRed/skelly/internal/snapshot"
+	"github.com/spf13/cobra"
+)
+
+// prReportMarker opens every PR report so a workflow can find and update
+// the comment it posted earlier instead of adding another.
+const prReportMarker = "<!-- skelly-pr-report -->"
+
+// prReportListLimit caps each Markdown list; the JSON report is complete.
+const prReportListLimit = 25
+
+// PRReport summarizes the structural changes of a branch against the point
+// where it forked from its base, for posting as a pull request comment.
+type PRReport struct {
+	Base       string `json:"base"`
+	BaseCommit string `json:"base_commit"`
+	Head       string `json:"head"`
+	// ChangedFiles and DeletedFiles are indexed files whose content differs
+	// between the two sides; added files count as changed.
+	ChangedFiles []string `json:"changed_files"`
+	DeletedFiles []string `json:"deleted_files"`
+	// Symbols lists added, removed, renamed and re-signed symbols and call
+	// edges; Graph the module-level drift.
+	Symbols snapshot.ChangeReport `json:"symbols"`
+	Graph   snapshot.Report       `json:"graph"`
+	// Impacted is the changed files and their transitive dependents, grouped
+	// by CODEOWNERS owner in Owners.
+	Impacted   []string       `json:"impacted"`
+	Owners     []owners.Group `json:"owners"`
+	Codeowners string         `json:"codeowners,omitempty"`
+}
+
+// RunReport prints a report of the repository. With --pr it indexes the
+// merge base of --since and --head and prints a Markdown summary of the
+// structural changes between them, or the same report as JSON.
+func RunReport(cmd *cobra.Command, args []string) error {
+	rootPath, err := resolveWorkingDirectory()
+	if err != nil {
+		return err
+	}
+	pr, err := cmd.Flags().GetBool("pr")
+	if err != nil {
+		return fmt.Errorf("failed to read --pr flag: %w", err)
+	}
+	since, err := cmd.Flags().GetString("since")
+	if err != nil {
+		return fmt.Errorf("failed to read --since flag: %w", err)
+	}
+	head, err := cmd.Flags().GetString("head")
+	if err != nil {
+		return fmt.Errorf("failed to read --head flag: %w", err)
+	}
+	asJSON, err := cmd.Flags().GetBool("json")
+	if err != nil {
+		return fmt.Errorf("failed to read --json flag: %w", err)
+	}
+	outputPath, err := cmd.Flags().GetString("output")
+	if err != nil {
+		return fmt.Errorf("failed to read --output flag: %w", err)
+	}
+	if !pr {
+		return fmt.Errorf("report requires --pr")
+	}
+
+	// A directory head (such as . for the committed context) is compared
+	// against its HEAD's fork point.
+	headRevision := head
+	if info, err := os.Stat(head); err == nil && info.IsDir() {
+		headRevision = "HEAD"
+	}
+	bases, err := gitLines(rootPath, "merge-base", since, headRevision)
+	if err != nil || len(bases) == 0 {
+		return fmt.Errorf("failed to find the merge base of %s and %s; fetch the base branch with enough history", since, headRevision)
+	}
+	before, err := loadDiffSnapshot(rootPath, bases[0])
+	if err != nil {
+		return err
+	}
+	before.Path = since
+	after, err := loadDiffSnapshot(rootPath, head)
+	if err != nil {
+		return err
+	}
+	after.Path = head
+
+	codeowners, err := owners.Load(rootPath)
+	if err != nil {
+		return err
+	}
+	report := PRReport{
+		Base:       since,
+		BaseCommit: bases[0],
+		Head:       head,
+		Symbols:    snapshot.Changes(before, after),
+		Graph:      snapshot.Diff(before, after),
+	}
+	if codeowners != nil {
+		report.Codeowners = codeowners.Path
+	}
+	report.ChangedFiles, report.DeletedFiles = changedStateFiles(before, after)
+	report.Impacted, _ = fileutil.ImpactedWithReasons(after.State, report.ChangedFiles, report.DeletedFiles)
+	report.Owners = codeowners.GroupFiles(report.Impacted)
+
+	var rendered string
+	if asJSON {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		rendered = string(data) + "\n"
+	} else {
+		rendered = renderPRReport(report)
+	}
+	if outputPath == "" {
+		fmt.Print(rendered)
+		return nil
+	}
+	if err := os.WriteFile(outputPath, []byte(rendered), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", outputPath, err)
+	}
+	return nil
+}
+
+// changedStateFiles compares the indexed file hashes of two snapshots.
+func changedStateFiles(before, after *snapshot.Snapshot) ([]string, []string) {
+	changed := make([]string, 0)
+	deleted := make([]string, 0)
+	for file, fileState := range after.State.Files {
+		if previous, ok := before.State.Files[file]; !ok || previous.Hash != fileState.Hash {
+			changed = append(changed, file)
+		}
+	}
+	for file := range before.State.Files {
+		if _, ok := after.State.Files[file]; !ok {
+			deleted = append(deleted, file)
+		}
+	}
+	sort.Strings(changed)
+	sort.Strings(deleted)
+	return changed, deleted
+}
+
+// renderPRReport renders the report as a Markdown pull request comment.
+func renderPRReport(report PRReport) string {
+	var sb strings.Builder
+	symbols := report.Symbols
+	sb.WriteString(prReportMarker + "\n")
+	sb.WriteString("## Structural Changes\n\n")
+	base := report.BaseCommit
+	if len(base) > 12 {
+		base = base[:12]
+	}
+	fmt.Fprintf(&sb, "`%s` (merge base `%s`) -> `%s`: %d files changed, %d deleted, %d impacted.\n\n",
+		report.Base, base, report.Head, len(report.ChangedFiles), len(report.DeletedFiles), len(report.Impacted))
+	fmt.Fprintf(&sb, "| Symbols | Count |\n| --- | --- |\n| Added | %d |\n| Removed | %d |\n| Renamed or moved | %d |\n| Signature changed | %d |\n| Call edges | +%d / -%d |\n",
+		len(symbols.Added), len(symbols.Removed), len(symbols.Renamed), len(symbols.SignatureChanged),
+		len(symbols.EdgesAdded), len(symbols.EdgesRemoved))
+	if symbols.Empty() && len(report.ChangedFiles) == 0 && len(report.DeletedFiles) == 0 {
+		sb.WriteString("\nNo structural changes.\n")
+		return sb.String()
+	}
+
+	lines := make([]string, 0, len(symbols.Added))
+	for _, sym := range symbols.Added {
+		lines = append(lines, fmt.Sprintf("- `%s` %s (`%s:%d`)", sym.Name, sym.Kind, sym.File, sym.Line))
+	}
+	writePRSection(&sb, "New Symbols", lines)
+
+	lines = make([]string, 0, len(symbols.Renamed)+len(symbols.SignatureChanged))
+	for _, rename := range symbols.Renamed {
+		lines = append(lines, fmt.Sprintf("- `%s:%s` -> `%s:%s` (%s)", rename.From.File, rename.From.Name, rename.To.File, rename.To.Name, rename.Reason))
+	}
+	for _, change := range symbols.SignatureChanged {
+		lines = append(lines, fmt.Sprintf("- `%s` (`%s`): `%s` -> `%s`", change.Name, change.File, change.Before, change.After))
+	}
+	writePRSection(&sb, "Changed Symbols", lines)
+
+	lines = make([]string, 0, len(symbols.Removed))
+	for _, sym := range symbols.Removed {
+		lines = append(lines, fmt.Sprintf("- `%s` %s (`%s`)", sym.Name, sym.Kind, sym.File))
+	}
+	writePRSection(&sb, "Deleted Symbols", lines)
+
+	if len(report.Owners) > 0 {
+		sb.WriteString("\n### Impacted Files by Owner\n\n")
+		for _, group := range report.Owners {
+			files := make([]string, 0, len(group.Files))
+			for _, file := range group.Files {
+				files = append(files, "`"+file+"`")
+			}
+			if len(files) > prReportListLimit {
+				files = append(files[:prReportListLimit], fmt.Sprintf("and %d more", len(group.Files)-prReportListLimit))
+			}
+			fmt.Fprintf(&sb, "- **%s** (%d): %s\n", group.Owner, len(group.Files), strings.Join(files, ", "))
+		}
+	}
+
+	graph := report.Graph
+	lines = make([]string, 0)
+	for _, edge := range symbols.EdgesAdded {
+		lines = append(lines, fmt.Sprintf("- added call `%s` -> `%s`", edge.From, edge.To))
+	}
+	for _, edge := range symbols.EdgesRemoved {
+		lines = append(lines, fmt.Sprintf("- removed call `%s` -> `%s`", edge.From, edge.To))
+	}
+	for _, coupling := range graph.Coupling {
+		lines = append(lines, fmt.Sprintf("- module `%s` -> `%s`: %d -> %d file dependencies", coupling.From, coupling.To, coupling.Links.Before, coupling.Links.After))
+	}
+	for _, cycle := range graph.NewCycles {
+		lines = append(lines, fmt.Sprintf("- new cycle: `%s`", strings.Join(cycle, "` <-> `")))
+	}
+	for _, cycle := range graph.ResolvedCycles {
+		lines = append(lines, fmt.Sprintf("- resolved cycle: `%s`", strings.Join(cycle, "` <-> `")))
+	}
+	writePRSection(&sb, "Graph Changes", lines)
+	return sb.String()
+}
+
+// writePRSection writes a headed list, capped at prReportListLimit lines;
+// empty sections are left out.
+func writePRSection(sb *strings.Builder, title string, lines []string) {
+	if len(lines) == 0 {
+		return
+	}
+	fmt.Fprintf(sb, "\n### %s\n\n", title)
+	for i, line := range lines {
+		if i == prReportListLimit {
+			fmt.Fprintf(sb, "- ... and %d more (see the JSON report)\n", len(lines)-prReportListLimit)
+			break
+		}
+		sb.WriteString(line + "\n")
+	}
+}
@@ -2,9 +2,15 @@ package cli
 
 import (
 	"fmt"
+	"time"
 
+	"github.com/morozRed/skelly/internal/dirdocs"
+	"github.com/morozRed/skelly/internal/enrich"
+	"github.com/morozRed/skelly/internal/export"
+	"github.com/morozRed/skelly/internal/graph"
 	"github.com/morozRed/skelly/internal/nav"
 	"github.com/morozRed/skelly/internal/output"
+	"github.com/morozRed/skelly/internal/state"
 	"github.com/spf13/cobra"
 )
 
@@ -17,7 +23,26 @@ dependencies, and call graphs - into token-efficient text files
 that help LLMs understand your code without reading every line.
 
 Output is written to .skelly/.context/ and can be version-controlled.`,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if err := ConfigureLogging(cmd); err != nil {
+				return err
+			}
+			rootPath, err := resolveWorkingDirectory()
+			if err != nil {
+				return err
+			}
+			if err := applyOutputDir(rootPath); err != nil {
+				return err
+			}
+			// Queries must not miss edits a write-behind watch has not written yet.
+			if cmd.Parent() == cmd.Root() && daemonCommands[cmd.Name()] {
+				flushBeforeQuery(rootPath)
+			}
+			return nil
+		},
 	}
+	rootCmd.PersistentFlags().BoolP("verbose", "v", false, "Log phase timings and other diagnostics to stderr (same as --log-level debug)")
+	rootCmd.PersistentFlags().String("log-level", "", "Diagnostics to log to stderr: debug, info, warn or error (default warn)")
 
 	// Core Commands
 	initCmd := &cobra.Command{
@@ -27,7 +52,8 @@ Output is written to .skelly/.context/ and can be version-controlled.`,
 	}
 	initCmd.Flags().String("llm", "", "Generate LLM integration files (comma-separated: codex,claude,cursor)")
 	initCmd.Flags().Bool("no-generate", false, "Create directory only, skip auto-generate")
-	initCmd.Flags().String("format", string(output.FormatText), "Output format: text|jsonl")
+	initCmd.Flags().Bool("refresh", false, "Only rewrite managed blocks in AGENTS.md/CLAUDE.md/CONTEXT.md that are outdated")
+	initCmd.Flags().String("format", string(output.FormatText), "Output format: text|jsonl|ctags")
 
 	setupCmd := &cobra.Command{
 		Use:    "setup",
@@ -35,7 +61,7 @@ Output is written to .skelly/.context/ and can be version-controlled.`,
 		Hidden: true,
 		RunE:   RunSetup,
 	}
-	setupCmd.Flags().String("format", string(output.FormatText), "Generate output format: text|jsonl")
+	setupCmd.Flags().String("format", string(output.FormatText), "Generate output format: text|jsonl|ctags")
 
 	generateCmd := &cobra.Command{
 		Use:   "generate [path]",
@@ -44,8 +70,14 @@ Output is written to .skelly/.context/ and can be version-controlled.`,
 		RunE:  RunGenerate,
 	}
 	generateCmd.Flags().StringSliceP("lang", "l", []string{}, "Languages to include (default: auto-detect)")
-	generateCmd.Flags().String("format", string(output.FormatText), "Output format: text|jsonl")
+	generateCmd.Flags().String("format", string(output.FormatText), "Output format: text|jsonl|ctags")
+	generateCmd.Flags().String("order", string(output.OrderImportance), "index.txt ordering: importance|path")
+	generateCmd.Flags().String("compress", "", "Store symbols, edges, nav and search indexes compressed: gzip|zstd|none (default: keep the current one)")
 	generateCmd.Flags().Bool("json", false, "Print machine-readable run summary")
+	generateCmd.Flags().Int("jobs", 0, "Files to parse in parallel (0 = one worker per CPU)")
+	generateCmd.Flags().String("state-backend", "", "State store: json|binary (default: keep the current one)")
+	generateCmd.Flags().Bool("all-roots", false, "Generate every project root listed under roots: in .skelly/config.yaml, plus the cross-root graph")
+	generateCmd.Flags().Bool("no-gitignore", false, "Do not apply .gitignore files (update, watch and ci keep the choice)")
 
 	updateCmd := &cobra.Command{
 		Use:   "update",
@@ -53,8 +85,40 @@ Output is written to .skelly/.context/ and can be version-controlled.`,
 		RunE:  RunUpdate,
 	}
 	updateCmd.Flags().Bool("explain", false, "Explain why each impacted file is included")
-	updateCmd.Flags().String("format", string(output.FormatText), "Output format: text|jsonl")
+	updateCmd.Flags().String("format", "", "Output format: text|jsonl|ctags (default: keep the current one, else text)")
+	updateCmd.Flags().String("order", string(output.OrderImportance), "index.txt ordering: importance|path")
+	updateCmd.Flags().String("compress", "", "Store symbols, edges, nav and search indexes compressed: gzip|zstd|none (default: keep the current one)")
 	updateCmd.Flags().Bool("json", false, "Print machine-readable run summary")
+	updateCmd.Flags().Bool("quick", false, fmt.Sprintf("Hook mode: refresh symbols, edges and navigation only, skip the search index, and fail instead of regenerating or reparsing more than %d files", QuickUpdateMaxFiles))
+	updateCmd.Flags().String("state-backend", "", "State store: json|binary (default: keep the current one)")
+	updateCmd.Flags().String("since", "", "Take changed files from git diff against this revision plus untracked files instead of hashing the whole tree (assumes the context was current at that revision)")
+	updateCmd.Flags().Bool("no-gitignore", false, "Do not apply .gitignore files (default: keep the choice the context was generated with)")
+	updateCmd.Flags().StringArray("exec", nil, "Command to run after update with {impacted}, {changed}, {deleted} file lists (repeatable)")
+
+	watchCmd := &cobra.Command{
+		Use:   "watch",
+		Short: "Watch the repository and run incremental updates on file changes",
+		Args:  cobra.NoArgs,
+		RunE:  RunWatch,
+	}
+	watchCmd.Flags().Duration("debounce", 300*time.Millisecond, "Quiet period before a batch of changes triggers an update")
+	watchCmd.Flags().String("format", "", "Output format: text|jsonl|ctags (default: keep the current one, else text)")
+	watchCmd.Flags().String("order", string(output.OrderImportance), "index.txt ordering: importance|path")
+	watchCmd.Flags().String("compress", "", "Store symbols, edges, nav and search indexes compressed: gzip|zstd|none (default: keep the current one)")
+	watchCmd.Flags().Bool("json", false, "Print one machine-readable run summary per batch")
+	watchCmd.Flags().Bool("no-gitignore", false, "Do not apply .gitignore files (default: keep the choice the context was generated with)")
+	watchCmd.Flags().StringArray("exec", nil, "Command to run after each batch with {impacted}, {changed}, {deleted} file lists (repeatable)")
+	watchCmd.Flags().Bool("write-behind", false, "Keep the graph in memory and write artifacts only every --flush-interval or on skelly flush")
+	watchCmd.Flags().Duration("flush-interval", 5*time.Second, "How often --write-behind writes pending changes to disk")
+
+	flushCmd := &cobra.Command{
+		Use:   "flush",
+		Short: "Ask a running `skelly watch --write-behind` to write pending changes to disk",
+		Args:  cobra.NoArgs,
+		RunE:  RunFlush,
+	}
+	flushCmd.Flags().Duration("timeout", 10*time.Second, "How long to wait for the watcher to acknowledge the flush")
+	flushCmd.Flags().Bool("json", false, "Print machine-readable flush summary")
 
 	// Inspect Commands
 	statusCmd := &cobra.Command{
@@ -64,12 +128,45 @@ Output is written to .skelly/.context/ and can be version-controlled.`,
 	}
 	statusCmd.Flags().Bool("json", false, "Print machine-readable status output")
 
+	ownersCmd := &cobra.Command{
+		Use:   "owners [symbol|file...]",
+		Short: "Show CODEOWNERS owners and group impacted files by owner",
+		Long: `Show the CODEOWNERS owners of files and symbols and group the files they
+impact by owner for review routing. Without arguments, groups the files
+impacted by pending changes, as reported by skelly status.`,
+		RunE: RunOwners,
+	}
+	ownersCmd.Flags().Bool("json", false, "Print machine-readable owners output")
+
+	ciCmd := &cobra.Command{
+		Use:   "ci",
+		Short: "Check that committed context matches the working tree without writing it",
+		Long: `Regenerate the context of a copy of the working tree in a temporary
+directory and compare it with the committed .skelly/.context, like gofmt -l.
+Stale artifacts are listed with a diff; ci exits 1 when regenerating would
+change anything and never writes into the repository.`,
+		Args: cobra.NoArgs,
+		RunE: RunCI,
+	}
+	ciCmd.Flags().StringSliceP("lang", "l", []string{}, "Languages to include (default: auto-detect)")
+	ciCmd.Flags().String("format", string(output.FormatText), "Output format the context was generated with: text|jsonl|ctags")
+	ciCmd.Flags().String("order", string(output.OrderImportance), "index.txt ordering: importance|path")
+	ciCmd.Flags().Bool("no-gitignore", false, "Do not apply .gitignore files (default: keep the choice the context was generated with)")
+	ciCmd.Flags().Bool("no-diff", false, "List stale artifacts without printing their diffs")
+	ciCmd.Flags().Bool("json", false, "Print machine-readable check output")
+
 	doctorCmd := &cobra.Command{
 		Use:   "doctor",
 		Short: "Validate skelly setup and context freshness",
-		RunE:  RunDoctor,
+		Long: `Validate skelly setup and context freshness. Doctor exits non-zero when
+the context is missing, stale, older than --max-age, or its artifacts no
+longer match the hashes recorded in state; with --strict, setup gaps such
+as missing LLM integrations fail too.`,
+		RunE: RunDoctor,
 	}
 	doctorCmd.Flags().Bool("json", false, "Print machine-readable doctor output")
+	doctorCmd.Flags().Bool("strict", false, "Also fail on setup gaps (integrations, managed blocks, stale search index)")
+	doctorCmd.Flags().Duration("max-age", 0, "Fail when the context was last generated or updated longer ago than this, e.g. 24h (0 disables)")
 
 	// Navigate Commands
 	symbolCmd := &cobra.Command{
@@ -79,8 +176,10 @@ Output is written to .skelly/.context/ and can be version-controlled.`,
 		RunE:  nav.RunSymbol,
 	}
 	symbolCmd.Flags().Bool("json", false, "Print machine-readable symbol matches")
+	symbolCmd.Flags().Bool("with-summary", false, "Print each symbol's enrich summary from the navigation index")
 	symbolCmd.Flags().Bool("fuzzy", false, "Enable BM25 fuzzy fallback when exact lookup misses")
 	symbolCmd.Flags().Int("limit", 10, "Maximum number of symbol matches to return")
+	symbolCmd.Flags().Bool("include-external", false, "Include symbols of vendored and generated code, hidden by default")
 
 	callersCmd := &cobra.Command{
 		Use:   "callers <name|id>",
@@ -89,7 +188,11 @@ Output is written to .skelly/.context/ and can be version-controlled.`,
 		RunE:  nav.RunCallers,
 	}
 	callersCmd.Flags().Bool("json", false, "Print machine-readable caller results")
+	callersCmd.Flags().Bool("with-summary", false, "Print each symbol's enrich summary from the navigation index")
 	callersCmd.Flags().Bool("lsp", false, "Augment with LSP lookups when available (parser fallback)")
+	callersCmd.Flags().StringSlice("kind", []string{}, "Only follow edges of these kinds: call, inherit, implement, reference, render, generated-from (default: all but reference)")
+	callersCmd.Flags().Bool("include-references", false, "Also list symbols that reference the type without calling it (reference edges)")
+	callersCmd.Flags().Bool("include-external", false, "Include symbols of vendored and generated code, hidden by default")
 
 	calleesCmd := &cobra.Command{
 		Use:   "callees <name|id>",
@@ -98,26 +201,48 @@ Output is written to .skelly/.context/ and can be version-controlled.`,
 		RunE:  nav.RunCallees,
 	}
 	calleesCmd.Flags().Bool("json", false, "Print machine-readable callee results")
+	calleesCmd.Flags().Bool("with-summary", false, "Print each symbol's enrich summary from the navigation index")
 	calleesCmd.Flags().Bool("lsp", false, "Augment with LSP lookups when available (parser fallback)")
+	calleesCmd.Flags().StringSlice("kind", []string{}, "Only follow edges of these kinds: call, inherit, implement, reference, render, generated-from (default: all)")
+	calleesCmd.Flags().Bool("include-external", false, "Include symbols of vendored and generated code, hidden by default")
+
+	implementationsCmd := &cobra.Command{
+		Use:   "implementations <interface|type>",
+		Short: "List types implementing a Go interface (or interfaces a type implements)",
+		Args:  cobra.ExactArgs(1),
+		RunE:  nav.RunImplementations,
+	}
+	implementationsCmd.Flags().Bool("json", false, "Print machine-readable implementation results")
 
 	traceCmd := &cobra.Command{
 		Use:   "trace <name|id>",
-		Short: "Trace outgoing calls from a symbol up to depth N",
+		Short: "Trace calls from (or callers of) a symbol up to depth N",
 		Args:  cobra.ExactArgs(1),
 		RunE:  nav.RunTrace,
 	}
 	traceCmd.Flags().Int("depth", 2, "Traversal depth (>=1)")
+	traceCmd.Flags().String("direction", nav.TraceOut, "Edges to follow: out (callees), in (callers) or both")
 	traceCmd.Flags().Bool("json", false, "Print machine-readable trace results")
+	traceCmd.Flags().Bool("with-summary", false, "Print each symbol's enrich summary from the navigation index")
 	traceCmd.Flags().Bool("lsp", false, "Augment with LSP lookups when available (parser fallback)")
+	traceCmd.Flags().StringSlice("lang", []string{}, "Restrict traversal to symbols of these languages")
+	traceCmd.Flags().StringSlice("kind", []string{}, "Only follow edges of these kinds: call, inherit, implement, reference, render, generated-from (default: all)")
+	traceCmd.Flags().Bool("include-external", false, "Include symbols of vendored and generated code, hidden by default")
 
 	pathCmd := &cobra.Command{
 		Use:   "path <from> <to>",
-		Short: "Find shortest call path between two symbols",
+		Short: "Find the shortest call path (or, with --all, several paths) between two symbols",
 		Args:  cobra.ExactArgs(2),
 		RunE:  nav.RunPath,
 	}
+	pathCmd.Flags().Bool("all", false, "Enumerate distinct simple call paths instead of only the shortest")
+	pathCmd.Flags().Int("max-depth", nav.DefaultPathMaxDepth, "Maximum path length in edges for --all")
+	pathCmd.Flags().Int("limit", nav.DefaultPathLimit, "Maximum number of paths for --all (0 for all)")
 	pathCmd.Flags().Bool("json", false, "Print machine-readable path results")
 	pathCmd.Flags().Bool("lsp", false, "Augment with LSP lookups when available (parser fallback)")
+	pathCmd.Flags().StringSlice("lang", []string{}, "Restrict traversal to symbols of these languages")
+	pathCmd.Flags().StringSlice("kind", []string{}, "Only follow edges of these kinds: call, inherit, implement, reference, render, generated-from (default: all)")
+	pathCmd.Flags().Bool("include-external", false, "Include symbols of vendored and generated code, hidden by default")
 
 	definitionCmd := &cobra.Command{
 		Use:   "definition <symbol|file:line>",
@@ -137,6 +262,186 @@ Output is written to .skelly/.context/ and can be version-controlled.`,
 	referencesCmd.Flags().Bool("json", false, "Print machine-readable references result")
 	referencesCmd.Flags().Bool("lsp", false, "Augment with LSP lookups when available (parser fallback)")
 
+	searchCmd := &cobra.Command{
+		Use:   "search [query]",
+		Short: "Find symbols by name, doc and signature text, by signature pattern (--signature) or by meaning (--semantic)",
+		Args:  cobra.MaximumNArgs(1),
+		RunE:  withProjectConfig(nav.RunSearch),
+	}
+	searchCmd.Flags().String("signature", "", "Signature pattern, e.g. 'func (*Server) Handle*(http.ResponseWriter, *http.Request)'")
+	searchCmd.Flags().Bool("regex", false, "Treat --signature as a regular expression instead of a glob")
+	searchCmd.Flags().String("semantic", "", "Natural-language query ranked by embedding similarity blended with BM25 (needs skelly enrich embed)")
+	searchCmd.Flags().StringSlice("kind", []string{}, "Only match symbols of these kinds, e.g. func,method,struct")
+	searchCmd.Flags().String("file", "", "Only match symbols in this file or directory")
+	searchCmd.Flags().StringSlice("visibility", []string{}, "Only match symbols with these visibilities: public, protected, private")
+	searchCmd.Flags().Bool("include-external", false, "Include symbols of vendored and generated code, hidden by default")
+	searchCmd.Flags().Int("limit", 50, "Maximum number of matches to return (0 for all)")
+	searchCmd.Flags().String("sort", "", "Order matches by an importance metric instead of relevance: pagerank|in-degree|out-degree|betweenness")
+	addEmbedProviderFlags(searchCmd)
+	searchCmd.Flags().Bool("json", false, "Print machine-readable search results")
+
+	grepCmd := &cobra.Command{
+		Use:   "grep",
+		Short: "Match tree-sitter patterns (calls, function arities, raw queries) and anchor matches to symbols",
+		Args:  cobra.NoArgs,
+		RunE:  RunGrep,
+	}
+	grepCmd.Flags().String("call", "", "Calls to this function or method: a name (Save) or the full callee (s.store.Save)")
+	grepCmd.Flags().Int("min-args", 0, "With --call, only calls with at least this many arguments")
+	grepCmd.Flags().Int("min-params", 0, "Function and method definitions with at least this many parameters (receivers and self excluded)")
+	grepCmd.Flags().String("query", "", "Raw tree-sitter query; @match names the reported node (needs --lang)")
+	grepCmd.Flags().StringSlice("lang", []string{}, "Only search files of these languages")
+	grepCmd.Flags().String("file", "", "Only search this file or directory")
+	grepCmd.Flags().Int("limit", 100, "Maximum number of matches to return (0 for all)")
+	grepCmd.Flags().Bool("json", false, "Print machine-readable matches")
+
+	serveCmd := &cobra.Command{
+		Use:   "serve --http [address] | --ui [address]",
+		Short: "Serve symbols, callers, callees, trace, search and enrich records over a local JSON API, with an optional web code map",
+		Args:  cobra.MaximumNArgs(1),
+		RunE:  nav.RunServe,
+	}
+	serveCmd.Flags().String("http", "", "Listen address for the HTTP API (--http alone listens on "+nav.DefaultHTTPAddress+")")
+	serveCmd.Flags().Lookup("http").NoOptDefVal = nav.DefaultHTTPAddress
+	serveCmd.Flags().Bool("ui", false, "Also serve the web graph explorer under /ui/ (listens on "+nav.DefaultHTTPAddress+" unless --http is given)")
+
+	daemonCmd := &cobra.Command{
+		Use:   "daemon",
+		Short: "Keep the indexes in memory so query commands skip loading them",
+		Long: `The daemon holds the navigation and search indexes in memory and answers
+query commands (symbol, callers, callees, trace, search, ...) over a unix
+socket in .skelly/.context/. While it runs, the CLI delegates those commands
+to it transparently; set ` + DaemonDisableEnv + `=1 to run them in-process.
+Indexes are reloaded when update rewrites them.`,
+	}
+	daemonStartCmd := &cobra.Command{
+		Use:   "start",
+		Short: "Start the daemon in the background",
+		Args:  cobra.NoArgs,
+		RunE:  RunDaemonStart,
+	}
+	daemonStartCmd.Flags().Duration("timeout", 10*time.Second, "How long to wait for the daemon to answer")
+	daemonRunCmd := &cobra.Command{
+		Use:   "run",
+		Short: "Run the daemon in the foreground",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return RunDaemonRun(cmd, version)
+		},
+	}
+	daemonStopCmd := &cobra.Command{
+		Use:   "stop",
+		Short: "Stop the running daemon",
+		Args:  cobra.NoArgs,
+		RunE:  RunDaemonStop,
+	}
+	daemonStatusCmd := &cobra.Command{
+		Use:   "status",
+		Short: "Report whether a daemon is running",
+		Args:  cobra.NoArgs,
+		RunE:  RunDaemonStatus,
+	}
+	daemonStatusCmd.Flags().Bool("json", false, "Print machine-readable daemon status")
+	daemonCmd.AddCommand(daemonStartCmd, daemonRunCmd, daemonStopCmd, daemonStatusCmd)
+
+	routesCmd := &cobra.Command{
+		Use:   "routes [path]",
+		Short: "List HTTP routes and their handlers, or the routes matching a request path",
+		Args:  cobra.MaximumNArgs(1),
+		RunE:  nav.RunRoutes,
+	}
+	routesCmd.Flags().String("method", "", "Only list routes for this HTTP method (ANY routes always match)")
+	routesCmd.Flags().Bool("json", false, "Print machine-readable route matches")
+
+	testsForCmd := &cobra.Command{
+		Use:   "tests-for <name|id>",
+		Short: "List the tests that exercise a symbol, directly or through its callers",
+		Args:  cobra.ExactArgs(1),
+		RunE:  nav.RunTestsFor,
+	}
+	testsForCmd.Flags().Int("depth", 2, "Call hops between the symbol and a test (1 = tests calling it directly)")
+	testsForCmd.Flags().Bool("json", false, "Print machine-readable test matches")
+
+	relatedCmd := &cobra.Command{
+		Use:   "related <file>",
+		Short: "Rank files related to a file by calls, shared dependencies, co-callers and co-change",
+		Args:  cobra.ExactArgs(1),
+		RunE:  nav.RunRelated,
+	}
+	relatedCmd.Flags().Int("limit", 10, "Maximum number of related files to return (0 for all)")
+	relatedCmd.Flags().Bool("git", false, "Include co-change counts from recent git history")
+	relatedCmd.Flags().Bool("json", false, "Print machine-readable related files")
+
+	packCmd := &cobra.Command{
+		Use:   "pack",
+		Short: "Pack the symbols most relevant to a focus into one Markdown or JSON bundle within a token budget",
+		Args:  cobra.NoArgs,
+		RunE:  nav.RunPack,
+	}
+	packCmd.Flags().Int("budget", 8000, "Approximate token budget for the bundle")
+	packCmd.Flags().String("focus", "", "Symbol or file to pack context around (default: the whole repository)")
+	packCmd.Flags().Bool("no-git", false, "Do not rank by how recently files changed in git")
+	packCmd.Flags().String("sort", string(graph.MetricPageRank), "Importance metric to rank by: pagerank|in-degree|out-degree|betweenness")
+	packCmd.Flags().Bool("json", false, "Print the bundle as JSON instead of Markdown")
+
+	exportCmd := &cobra.Command{
+		Use:   "export",
+		Short: "Render the dependency graph for Graphviz, Mermaid or LSIF code-intelligence tools",
+		Args:  cobra.NoArgs,
+		RunE:  RunExport,
+	}
+	exportCmd.Flags().String("format", "dot", "Export format: dot|mermaid|lsif")
+	exportCmd.Flags().String("scope", string(export.ScopeModule), "Node granularity: symbol|file|module")
+	exportCmd.Flags().String("focus", "", "Only export the neighborhood of this node (ID, symbol name, file path or directory)")
+	exportCmd.Flags().Int("depth", 2, "Neighborhood depth for --focus (>=1)")
+	exportCmd.Flags().Int("top", 0, "Keep only the N highest-PageRank nodes (0 for all)")
+	exportCmd.Flags().StringP("output", "o", "", "Write to this file instead of stdout")
+	exportCmd.Flags().StringSlice("lang", []string{}, "Only export symbols of these languages, reporting the edges cut to others on stderr")
+
+	snapshotCmd := &cobra.Command{
+		Use:   "snapshot",
+		Short: "Compare context snapshots",
+	}
+	snapshotDiffCmd := &cobra.Command{
+		Use:   "diff <before> <after>",
+		Short: "Report structural drift between two context directories (or repo roots)",
+		Args:  cobra.ExactArgs(2),
+		RunE:  RunSnapshotDiff,
+	}
+	snapshotDiffCmd.Flags().Bool("json", false, "Print the drift report as JSON")
+	snapshotDiffCmd.Flags().Bool("markdown", false, "Print the drift report as Markdown for release notes or reviews")
+	snapshotCmd.AddCommand(snapshotDiffCmd)
+
+	diffCmd := &cobra.Command{
+		Use:   "diff <before> <after>",
+		Short: "Diff symbols and call edges between two context directories or git revisions",
+		Long: `Compare two context directories (or repo roots) or two git revisions and
+list symbols added, removed, renamed or with changed signatures, and call
+edges added or removed. A git revision is checked out into a temporary
+worktree and indexed there.`,
+		Args: cobra.ExactArgs(2),
+		RunE: RunDiff,
+	}
+	diffCmd.Flags().Bool("json", false, "Print the diff as JSON")
+
+	reportCmd := &cobra.Command{
+		Use:   "report --pr",
+		Short: "Summarize the structural changes of a branch as a Markdown PR comment",
+		Long: `Index the merge base of --since and --head and summarize the structural
+changes between them as Markdown for a pull request comment: new, changed
+and deleted symbols, impacted files grouped by CODEOWNERS owner, and call
+edge, module coupling and cycle changes. --json prints the same report as
+JSON. The comment starts with a <!-- skelly-pr-report --> marker so a
+workflow can update it in place.`,
+		Args: cobra.NoArgs,
+		RunE: RunReport,
+	}
+	reportCmd.Flags().Bool("pr", false, "Report the changes of the current branch for a pull request")
+	reportCmd.Flags().String("since", "origin/main", "Base revision the branch is compared against (at their merge base)")
+	reportCmd.Flags().String("head", "HEAD", "Head revision, or a context directory such as .")
+	reportCmd.Flags().Bool("json", false, "Print the report as JSON")
+	reportCmd.Flags().StringP("output", "o", "", "Write to this file instead of stdout")
+
 	// Annotate Commands
 	enrichCmd := &cobra.Command{
 		Use:   "enrich <target> <description>",
@@ -144,7 +449,173 @@ Output is written to .skelly/.context/ and can be version-controlled.`,
 		Args:  cobra.MinimumNArgs(2),
 		RunE:  RunEnrich,
 	}
+	enrichCmd.Flags().Int("max-body-bytes", enrich.DefaultMaxBodyBytes, "Truncate longer symbol bodies in the record to their head, with the signature, and tail (0 keeps them whole)")
+	enrichCmd.Flags().String("agent", defaultEnrichAgent, "Agent profile recorded with the description (default from enrich.agent in .skelly/config.yaml)")
 	enrichCmd.Flags().Bool("json", false, "Print machine-readable summary")
+	enrichBootstrapCmd := &cobra.Command{
+		Use:   "bootstrap [target]",
+		Short: "Seed enrich records from existing doc comments without an agent",
+		Args:  cobra.MaximumNArgs(1),
+		RunE:  RunEnrichBootstrap,
+	}
+	enrichBootstrapCmd.Flags().Int("min-words", enrich.BootstrapMinWords, "Minimum descriptive words (excluding the symbol name) for a doc comment to be used")
+	enrichBootstrapCmd.Flags().Bool("dry-run", false, "Report what would be bootstrapped without writing enrich.jsonl")
+	addEnrichFilterFlags(enrichBootstrapCmd)
+	enrichBootstrapCmd.Flags().String("order", "path", "Symbol order: path, or an importance metric (pagerank|in-degree|out-degree|betweenness), most important first")
+	enrichBootstrapCmd.Flags().Int("limit", 0, "Bootstrap at most this many symbols, in --order (0 for all; default from enrich.max_symbols in .skelly/config.yaml)")
+	enrichBootstrapCmd.Flags().Int("max-body-bytes", enrich.DefaultMaxBodyBytes, "Truncate longer symbol bodies in records to their head, with the signature, and tail (0 keeps them whole)")
+	enrichBootstrapCmd.Flags().Bool("json", false, "Print machine-readable summary")
+	enrichCmd.AddCommand(enrichBootstrapCmd)
+	enrichEmbedCmd := &cobra.Command{
+		Use:   "embed",
+		Short: "Embed every symbol into .skelly/.context/embeddings.bin for search --semantic",
+		Args:  cobra.NoArgs,
+		RunE:  RunEnrichEmbed,
+	}
+	addEmbedProviderFlags(enrichEmbedCmd)
+	enrichEmbedCmd.Flags().Int("batch", 64, "Symbols per embeddings request")
+	enrichEmbedCmd.Flags().Bool("json", false, "Print machine-readable summary")
+	enrichCmd.AddCommand(enrichEmbedCmd)
+	enrichOverviewCmd := &cobra.Command{
+		Use:   "overview [path] [description]",
+		Short: "Write file and directory overviews to .skelly/.context/overview.jsonl",
+		Long: `Derive an overview for every indexed file and directory (or those under
+path) from its top-ranked symbols, their enrich summaries and its imports.
+Overviews written with a description are kept on later runs and reported
+as stale once their sources change.`,
+		RunE: RunEnrichOverview,
+	}
+	enrichOverviewCmd.Flags().Bool("json", false, "Print machine-readable summary")
+	enrichCmd.AddCommand(enrichOverviewCmd)
+	enrichGCCmd := &cobra.Command{
+		Use:   "gc",
+		Short: "Drop enrich records of symbols no longer indexed and superseded records",
+		Args:  cobra.NoArgs,
+		RunE:  RunEnrichGC,
+	}
+	enrichGCCmd.Flags().Bool("stale", false, "Also drop records written against an older file hash, losing their summaries")
+	enrichGCCmd.Flags().Bool("dry-run", false, "Report what would be dropped without rewriting enrich.jsonl")
+	enrichGCCmd.Flags().Bool("json", false, "Print machine-readable summary")
+	enrichCmd.AddCommand(enrichGCCmd)
+	enrichStatsCmd := &cobra.Command{
+		Use:   "stats",
+		Short: "Show enrich cache size, coverage, reuse rate and staleness",
+		Args:  cobra.NoArgs,
+		RunE:  RunEnrichStats,
+	}
+	enrichStatsCmd.Flags().Bool("json", false, "Print machine-readable stats")
+	enrichCmd.AddCommand(enrichStatsCmd)
+
+	conventionsCmd := &cobra.Command{
+		Use:   "conventions",
+		Short: "Derive project conventions from the index into .skelly/conventions.md",
+		Args:  cobra.NoArgs,
+		RunE:  RunConventions,
+	}
+	conventionsCmd.Flags().StringArray("note", nil, "Add an agent-observed convention to the preserved notes section (repeatable)")
+	conventionsCmd.Flags().Bool("json", false, "Print machine-readable conventions report")
+
+	deadcodeCmd := &cobra.Command{
+		Use:   "deadcode",
+		Short: "List public symbols with no callers or references, including from tests",
+		Long: `List exported or public symbols that no edge in the index points at, from
+production code or tests. Entry points are skipped by per-language
+heuristics: main and init, constructors and magic methods, decorated
+symbols, HTTP handlers, CLI commands, and methods that implement or
+override a supertype's method. Keep known exceptions out of the report with
+--allow or the deadcode.allow list in .skelly/config.yaml, and check
+protected or private symbols too with --visibility.`,
+		Args: cobra.NoArgs,
+		RunE: RunDeadcode,
+	}
+	deadcodeCmd.Flags().StringArray("allow", nil, "Skip a symbol ID, name, qualified name or glob, or a path glob such as 'internal/compat/**' (repeatable)")
+	deadcodeCmd.Flags().StringSlice("visibility", []string{}, "Check symbols with these visibilities: public, protected, private (default: public)")
+	deadcodeCmd.Flags().Bool("json", false, "Print machine-readable report")
+
+	languagesCmd := &cobra.Command{
+		Use:   "languages",
+		Short: "Report per-language index coverage and why files are skipped",
+		Long: `Walk the repository and report, per detected language, how many files
+were found, parsed, failed to parse and skipped, with symbol counts, plus
+the files left out of the index grouped by reason: ignored, unsupported
+extension, parse failure, too large, binary, language filter,
+skip_generated, skip_build_ignored, or not indexed yet. Reads the state of the last
+generate; without one every source file is reported as not indexed.`,
+		Args: cobra.NoArgs,
+		RunE: RunLanguages,
+	}
+	languagesCmd.Flags().Int("limit", 10, "Files to list per skip reason (0 for all)")
+	languagesCmd.Flags().Bool("json", false, "Print machine-readable report")
+
+	issuesCmd := &cobra.Command{
+		Use:   "issues",
+		Short: "List the parse warnings and errors of the last generate",
+		Long: `List the parse warnings and errors recorded in .skelly/.context/issues.jsonl
+by the last generate, with their file, language, severity and message.
+update drops the issues of files it reparses or deletes.`,
+		Args: cobra.NoArgs,
+		RunE: RunIssues,
+	}
+	issuesCmd.Flags().String("severity", "", "Only list issues of this severity: error or warning")
+	issuesCmd.Flags().StringSlice("lang", []string{}, "Only list issues of files in these languages")
+	issuesCmd.Flags().String("file", "", "Only list issues of this file or directory")
+	issuesCmd.Flags().Bool("json", false, "Print machine-readable issues")
+
+	docsCmd := &cobra.Command{
+		Use:   "docs",
+		Short: "Generate orientation docs from the index",
+	}
+	docsDirsCmd := &cobra.Command{
+		Use:   "dirs [dir]",
+		Short: "Write README.skelly.md into each significant directory (files, key symbols, dependencies, entrypoints)",
+		Args:  cobra.MaximumNArgs(1),
+		RunE:  RunDocsDirs,
+	}
+	docsDirsCmd.Flags().Int("min-files", dirdocs.DefaultMinFiles, "Minimum indexed files for a directory to get a doc (directories with a main entrypoint always do)")
+	docsDirsCmd.Flags().String("overview", "", "Store an agent- or reviewer-written overview for the given directory")
+	docsDirsCmd.Flags().Bool("dry-run", false, "Report which docs would be written without touching the tree")
+	docsDirsCmd.Flags().Bool("json", false, "Print machine-readable summary")
+	docsCmd.AddCommand(docsDirsCmd)
+
+	aliasesCmd := &cobra.Command{
+		Use:   "aliases",
+		Short: "List forwarding entries from retired symbol IDs to moved or renamed symbols",
+		Args:  cobra.NoArgs,
+		RunE:  RunAliases,
+	}
+	aliasesCmd.Flags().Bool("json", false, "Print machine-readable alias entries")
+	aliasesPruneCmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Retire forwarding entries for old symbol IDs",
+		Args:  cobra.NoArgs,
+		RunE:  RunAliasesPrune,
+	}
+	aliasesPruneCmd.Flags().Duration("older-than", 0, "Only retire forwards older than this duration (default: all); forwards to missing symbols are always retired")
+	aliasesPruneCmd.Flags().Bool("json", false, "Print machine-readable prune summary")
+	aliasesCmd.AddCommand(aliasesPruneCmd)
+
+	calibrationCmd := &cobra.Command{
+		Use:   "calibration",
+		Short: "Report per-language call resolution rates, sampled misses and trends",
+		Args:  cobra.NoArgs,
+		RunE:  RunCalibration,
+	}
+	calibrationCmd.Flags().Int("samples", 5, "Unresolved call sites to show per language")
+	calibrationCmd.Flags().Bool("json", false, "Print machine-readable calibration report")
+
+	stateCmd := &cobra.Command{
+		Use:   "state",
+		Short: "Manage the persisted incremental state",
+	}
+	stateMigrateCmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Convert state between the json and binary backends",
+		Args:  cobra.NoArgs,
+		RunE:  RunStateMigrate,
+	}
+	stateMigrateCmd.Flags().String("to", string(state.BackendBinary), "Target backend: json|binary")
+	stateMigrateCmd.Flags().Bool("json", false, "Print machine-readable migration summary")
+	stateCmd.AddCommand(stateMigrateCmd)
 
 	// Additional Commands
 	installHookCmd := &cobra.Command{
@@ -152,6 +623,26 @@ Output is written to .skelly/.context/ and can be version-controlled.`,
 		Short: "Install git pre-commit hook for auto-updates",
 		RunE:  RunInstallHook,
 	}
+	installHookCmd.Flags().Bool("stage-artifacts", false, "Stage regenerated .skelly/.context artifacts with the commit")
+
+	hookVerifyCmd := &cobra.Command{
+		Use:   "hook-verify",
+		Short: "Fail when staged sources and staged context artifacts disagree",
+		Args:  cobra.NoArgs,
+		RunE:  RunHookVerify,
+	}
+	hookVerifyCmd.Flags().Bool("json", false, "Print machine-readable verification summary")
+	hookVerifyCmd.Flags().Bool("stage-artifacts", false, "First stage regenerated context artifacts when sources are staged")
+
+	capabilitiesCmd := &cobra.Command{
+		Use:   "capabilities",
+		Short: "Describe supported languages, formats, commands, artifacts and features",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return RunCapabilities(cmd, version)
+		},
+	}
+	capabilitiesCmd.Flags().Bool("json", false, "Print machine-readable capabilities")
 
 	versionCmd := &cobra.Command{
 		Use:   "version",
@@ -166,19 +657,63 @@ Output is written to .skelly/.context/ and can be version-controlled.`,
 		setupCmd,
 		generateCmd,
 		updateCmd,
+		watchCmd,
+		flushCmd,
 		statusCmd,
+		ciCmd,
+		ownersCmd,
 		doctorCmd,
 		symbolCmd,
 		callersCmd,
 		calleesCmd,
+		implementationsCmd,
 		traceCmd,
 		pathCmd,
 		definitionCmd,
 		referencesCmd,
+		searchCmd,
+		grepCmd,
+		routesCmd,
+		testsForCmd,
+		relatedCmd,
+		packCmd,
+		serveCmd,
+		daemonCmd,
+		exportCmd,
+		snapshotCmd,
+		diffCmd,
+		reportCmd,
 		enrichCmd,
+		conventionsCmd,
+		deadcodeCmd,
+		languagesCmd,
+		issuesCmd,
+		docsCmd,
+		aliasesCmd,
+		calibrationCmd,
+		stateCmd,
 		installHookCmd,
+		hookVerifyCmd,
+		capabilitiesCmd,
 		versionCmd,
 	)
 
 	return rootCmd
 }
+
+// addEmbedProviderFlags defines the embeddings provider flags; the
+// embeddings section of .skelly/config.yaml supplies their defaults.
+func addEmbedProviderFlags(cmd *cobra.Command) {
+	cmd.Flags().String("embed-command", "", "Shell command reading an OpenAI embeddings request on stdin and printing the response")
+	cmd.Flags().String("embed-endpoint", "", "OpenAI-compatible API base URL, e.g. https://api.openai.com/v1 (key from $SKELLY_EMBED_API_KEY or $OPENAI_API_KEY)")
+	cmd.Flags().String("embed-model", "", "Embedding model to request")
+}
+
+// addEnrichFilterFlags defines the filters that narrow a batch enrich run to
+// a subsystem.
+func addEnrichFilterFlags(cmd *cobra.Command) {
+	cmd.Flags().StringArray("path", nil, "Only enrich files matching this glob, e.g. 'internal/graph/**' (repeatable)")
+	cmd.Flags().StringArray("symbol", nil, "Only enrich the symbol with this name, qualified name or ID (repeatable)")
+	cmd.Flags().StringSlice("kind", []string{}, "Only enrich symbols of these kinds, e.g. function,method")
+	cmd.Flags().StringSlice("visibility", []string{}, "Only enrich symbols with these visibilities: public, protected, private")
+}
@@ -0,0 +1,368 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/morozRed/skelly/internal/config"
+	"github.com/morozRed/skelly/internal/fileutil"
+	"github.com/morozRed/skelly/internal/graph"
+	"github.com/morozRed/skelly/internal/languages"
+	"github.com/morozRed/skelly/internal/llm"
+	"github.com/morozRed/skelly/internal/output"
+	"github.com/morozRed/skelly/internal/parser"
+	"github.com/morozRed/skelly/internal/state"
+	"github.com/spf13/cobra"
+)
+
+// RootsGraphFile is the merged cross-root graph generate --all-roots writes
+// to the top-level context directory.
+const RootsGraphFile = "roots.json"
+
+// RootsGraph holds the edges between the project roots of a monorepo, found
+// by resolving calls and imports over all roots' symbols at once. Symbol IDs
+// and files are relative to their root, as in each root's own context.
+type RootsGraph struct {
+	Roots   []RootSummary    `json:"roots"`
+	Calls   []CrossRootEdge  `json:"calls"`
+	Imports []CrossRootEdge  `json:"imports"`
+	Links   []CrossRootCount `json:"links"`
+}
+
+// RootSummary describes one indexed project root.
+type RootSummary struct {
+	Path    string `json:"path"`
+	Format  string `json:"format"`
+	Files   int    `json:"files"`
+	Symbols int    `json:"symbols"`
+}
+
+// CrossRootEdge is a call between symbols, or an import between files, of
+// two roots.
+type CrossRootEdge struct {
+	FromRoot string `json:"from_root"`
+	From     string `json:"from"`
+	ToRoot   string `json:"to_root"`
+	To       string `json:"to"`
+}
+
+// CrossRootCount counts the calls and imports from one root into another.
+type CrossRootCount struct {
+	From    string `json:"from"`
+	To      string `json:"to"`
+	Calls   int    `json:"calls"`
+	Imports int    `json:"imports"`
+}
+
+// AllRootsSummary reports a generate --all-roots run.
+type AllRootsSummary struct {
+	Mode       string       `json:"mode"`
+	RootPath   string       `json:"root_path"`
+	Roots      []RunSummary `json:"roots"`
+	OutputFile string       `json:"output_file"`
+	Calls      int          `json:"cross_root_calls"`
+	Imports    int          `json:"cross_root_imports"`
+	DurationMS int64        `json:"duration_ms"`
+}
+
+// ExpandRoots resolves the configured root patterns to directories relative
+// to rootPath, sorted and without duplicates. A pattern that matches no
+// directory is an error, so a typo does not silently skip a service.
+func ExpandRoots(rootPath string, patterns []string) ([]string, error) {
+	seen := make(map[string]bool)
+	roots := make([]string, 0, len(patterns))
+	for _, pattern := range patterns {
+		pattern = strings.TrimSuffix(filepath.ToSlash(strings.TrimSpace(pattern)), "/")
+		if pattern == "" {
+			continue
+		}
+		matches, err := filepath.Glob(filepath.Join(rootPath, filepath.FromSlash(pattern)))
+		if err != nil {
+			return nil, fmt.Errorf("invalid root pattern %q: %w", pattern, err)
+		}
+		found := 0
+		for _, match := range matches {
+			if info, err := os.Stat(match); err != nil || !info.IsDir() {
+				continue
+			}
+			rel, err := filepath.Rel(rootPath, match)
+			if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+				continue
+			}
+			found++
+			rel = filepath.ToSlash(rel)
+			if !seen[rel] {
+				seen[rel] = true
+				roots = append(roots, rel)
+			}
+		}
+		if found == 0 {
+			return nil, fmt.Errorf("root pattern %q in %s matches no directory", pattern, config.File)
+		}
+	}
+	sort.Strings(roots)
+	return roots, nil
+}
+
+// generateAllRoots generates the context of every root listed in the
+// top-level config, then writes the cross-root graph. Flags passed on the
+// command line apply to every root; otherwise each root's own config, then
+// the top-level config, supplies format, order, languages and gitignore.
+func generateAllRoots(cmd *cobra.Command, rootPath string) error {
+	start := time.Now()
+	topConfig, err := config.Load(rootPath)
+	if err != nil {
+		return err
+	}
+	if len(topConfig.Roots) == 0 {
+		return fmt.Errorf("--all-roots needs a roots list in %s (e.g. roots: [services/*])", config.File)
+	}
+	roots, err := ExpandRoots(rootPath, topConfig.Roots)
+	if err != nil {
+		return err
+	}
+	if err := ApplyArtifactCompression(cmd); err != nil {
+		return err
+	}
+	asJSON, err := cmd.Flags().GetBool("json")
+	if err != nil {
+		return fmt.Errorf("failed to read --json flag: %w", err)
+	}
+	jobs, err := ParseJobs(cmd)
+	if err != nil {
+		return err
+	}
+
+	summary := AllRootsSummary{Mode: "generate-all-roots", RootPath: rootPath, Roots: make([]RunSummary, 0, len(roots))}
+	for _, root := range roots {
+		rootDir := filepath.Join(rootPath, filepath.FromSlash(root))
+		rootConfig, err := config.Load(rootDir)
+		if err != nil {
+			return fmt.Errorf("root %s: %w", root, err)
+		}
+		languageFilter, format, order, gitignore, err := rootSettings(cmd, rootConfig, topConfig)
+		if err != nil {
+			return fmt.Errorf("root %s: %w", root, err)
+		}
+		if !asJSON {
+			fmt.Printf("root %s:\n", root)
+		}
+		result, err := generateContext(rootDir, languageFilter, format, order, true, jobs, gitignore)
+		if err != nil {
+			return fmt.Errorf("root %s: %w", root, err)
+		}
+		summary.Roots = append(summary.Roots, result)
+		if !asJSON {
+			if err := PrintRunSummary(result, false); err != nil {
+				return err
+			}
+		}
+	}
+
+	rootsGraph, err := BuildRootsGraph(rootPath, roots)
+	if err != nil {
+		return err
+	}
+	contextDir := filepath.Join(rootPath, output.ContextDir)
+	if err := os.MkdirAll(contextDir, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", contextDir, err)
+	}
+	data, err := json.MarshalIndent(rootsGraph, "", "  ")
+	if err != nil {
+		return err
+	}
+	summary.OutputFile = filepath.Join(contextDir, RootsGraphFile)
+	if err := os.WriteFile(summary.OutputFile, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", RootsGraphFile, err)
+	}
+	summary.Calls, summary.Imports = len(rootsGraph.Calls), len(rootsGraph.Imports)
+	summary.DurationMS = time.Since(start).Milliseconds()
+
+	if asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(summary)
+	}
+	fmt.Printf("cross-root graph: %s (roots=%d calls=%d imports=%d, %dms)\n",
+		summary.OutputFile, len(roots), summary.Calls, summary.Imports, summary.DurationMS)
+	for _, link := range rootsGraph.Links {
+		fmt.Printf("  %s -> %s: calls=%d imports=%d\n", link.From, link.To, link.Calls, link.Imports)
+	}
+	return nil
+}
+
+// rootSettings resolves a root's generate options: an explicit flag, else
+// the root's config, else the top-level config, else the flag default.
+func rootSettings(cmd *cobra.Command, rootConfig, topConfig config.Config) (map[string]bool, output.Format, output.Order, bool, error) {
+	pick := func(flag, rootValue, topValue string) (string, error) {
+		if cmd.Flags().Changed(flag) || (rootValue == "" && topValue == "") {
+			return cmd.Flags().GetString(flag)
+		}
+		if rootValue != "" {
+			return rootValue, nil
+		}
+		return topValue, nil
+	}
+
+	languageFilter, err := ParseLanguageFilter(cmd)
+	if err != nil {
+		return nil, "", "", false, err
+	}
+	if !cmd.Flags().Changed("lang") {
+		configured := rootConfig.Languages
+		if len(configured) == 0 {
+			configured = topConfig.Languages
+		}
+		if languageFilter, err = languages.ParseLanguageList(configured); err != nil {
+			return nil, "", "", false, err
+		}
+	}
+	rawFormat, err := pick("format", rootConfig.Format, topConfig.Format)
+	if err != nil {
+		return nil, "", "", false, fmt.Errorf("failed to read --format flag: %w", err)
+	}
+	format, err := output.ParseFormat(rawFormat)
+	if err != nil {
+		return nil, "", "", false, err
+	}
+	rawOrder, err := pick("order", rootConfig.Order, topConfig.Order)
+	if err != nil {
+		return nil, "", "", false, fmt.Errorf("failed to read --order flag: %w", err)
+	}
+	order, err := output.ParseOrder(rawOrder)
+	if err != nil {
+		return nil, "", "", false, err
+	}
+	noGitignore, err := cmd.Flags().GetBool("no-gitignore")
+	if err != nil {
+		return nil, "", "", false, fmt.Errorf("failed to read --no-gitignore flag: %w", err)
+	}
+	gitignore := !noGitignore && !topConfig.DisableGitignore
+	return languageFilter, format, order, gitignore, nil
+}
+
+// BuildRootsGraph merges the recorded state of every root, with paths
+// prefixed by the root, resolves calls and imports over the merged symbols,
+// and keeps the edges that cross from one root into another.
+func BuildRootsGraph(rootPath string, roots []string) (RootsGraph, error) {
+	rootsGraph := RootsGraph{
+		Roots:   make([]RootSummary, 0, len(roots)),
+		Calls:   make([]CrossRootEdge, 0),
+		Imports: make([]CrossRootEdge, 0),
+		Links:   make([]CrossRootCount, 0),
+	}
+	merged := &parser.ParseResult{RootPath: rootPath}
+	// rootOf and localIDs map merged files and node IDs back to their root.
+	rootOf := make(map[string]string)
+	localIDs := make(map[string]string)
+	for _, root := range roots {
+		contextDir := filepath.Join(rootPath, filepath.FromSlash(root), output.ContextDir)
+		st, err := state.Load(contextDir)
+		if err != nil {
+			return RootsGraph{}, fmt.Errorf("failed to load state of root %s: %w", root, err)
+		}
+		summary := RootSummary{Path: root, Format: llm.DetectContextFormat(contextDir), Files: len(st.Files)}
+		files := make([]string, 0, len(st.Files))
+		for file := range st.Files {
+			files = append(files, file)
+		}
+		sort.Strings(files)
+		for _, file := range files {
+			fileState := st.Files[file]
+			local := parser.FileSymbols{Path: file, Symbols: fileState.Symbols}
+			fileutil.EnsureSymbolIDs(&local)
+			prefixed := parser.FileSymbols{
+				Path:            path.Join(root, file),
+				Language:        fileState.Language,
+				Symbols:         make([]parser.Symbol, len(local.Symbols)),
+				Imports:         fileState.Imports,
+				ImportAliases:   fileState.ImportAliases,
+				Hash:            fileState.Hash,
+				Generated:       fileState.Generated,
+				BuildConstraint: fileState.BuildConstraint,
+				External:        fileState.External,
+			}
+			for i, sym := range local.Symbols {
+				sym.File, sym.ID = prefixed.Path, ""
+				prefixed.Symbols[i] = sym
+			}
+			fileutil.EnsureSymbolIDs(&prefixed)
+			for i := range prefixed.Symbols {
+				localIDs[prefixed.Symbols[i].ID] = local.Symbols[i].ID
+			}
+			rootOf[prefixed.Path] = root
+			summary.Symbols += len(prefixed.Symbols)
+			merged.Files = append(merged.Files, prefixed)
+		}
+		rootsGraph.Roots = append(rootsGraph.Roots, summary)
+	}
+
+	g := graph.BuildFromParseResultForSources(merged, nil)
+	links := make(map[[2]string]*CrossRootCount)
+	link := func(from, to string) *CrossRootCount {
+		key := [2]string{from, to}
+		if links[key] == nil {
+			links[key] = &CrossRootCount{From: from, To: to}
+		}
+		return links[key]
+	}
+	for _, node := range g.Nodes {
+		for _, targetID := range node.OutEdgesOfKind(graph.EdgeCall) {
+			target, ok := g.Nodes[targetID]
+			if !ok || rootOf[target.File] == rootOf[node.File] {
+				continue
+			}
+			rootsGraph.Calls = append(rootsGraph.Calls, CrossRootEdge{
+				FromRoot: rootOf[node.File], From: localIDs[node.ID],
+				ToRoot: rootOf[target.File], To: localIDs[target.ID],
+			})
+			link(rootOf[node.File], rootOf[target.File]).Calls++
+		}
+	}
+	for file, targets := range g.FileImports {
+		for _, target := range targets {
+			if rootOf[target] == rootOf[file] {
+				continue
+			}
+			rootsGraph.Imports = append(rootsGraph.Imports, CrossRootEdge{
+				FromRoot: rootOf[file], From: strings.TrimPrefix(file, rootOf[file]+"/"),
+				ToRoot: rootOf[target], To: strings.TrimPrefix(target, rootOf[target]+"/"),
+			})
+			link(rootOf[file], rootOf[target]).Imports++
+		}
+	}
+	sortCrossRootEdges(rootsGraph.Calls)
+	sortCrossRootEdges(rootsGraph.Imports)
+	for _, count := range links {
+		rootsGraph.Links = append(rootsGraph.Links, *count)
+	}
+	sort.Slice(rootsGraph.Links, func(i, j int) bool {
+		if rootsGraph.Links[i].From != rootsGraph.Links[j].From {
+			return rootsGraph.Links[i].From < rootsGraph.Links[j].From
+		}
+		return rootsGraph.Links[i].To < rootsGraph.Links[j].To
+	})
+	return rootsGraph, nil
+}
+
+func sortCrossRootEdges(edges []CrossRootEdge) {
+	sort.Slice(edges, func(i, j int) bool {
+		a, b := edges[i], edges[j]
+		if a.FromRoot != b.FromRoot {
+			return a.FromRoot < b.FromRoot
+		}
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		if a.ToRoot != b.ToRoot {
+			return a.ToRoot < b.ToRoot
+		}
+		return a.To < b.To
+	})
+}
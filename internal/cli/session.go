@@ -0,0 +1,394 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/morozRed/skelly/internal/config"
+	"github.com/morozRed/skelly/internal/fileutil"
+	"github.com/morozRed/skelly/internal/graph"
+	"github.com/morozRed/skelly/internal/ignore"
+	"github.com/morozRed/skelly/internal/languages"
+	"github.com/morozRed/skelly/internal/nav"
+	"github.com/morozRed/skelly/internal/output"
+	"github.com/morozRed/skelly/internal/parser"
+	"github.com/morozRed/skelly/internal/search"
+	"github.com/morozRed/skelly/internal/state"
+)
+
+// contextSession keeps the parsed state and dependency graph in memory so
+// incremental changes can be applied separately from writing artifacts.
+// update applies and flushes in one step; watch --write-behind applies each
+// batch and flushes on an interval or on demand.
+type contextSession struct {
+	rootPath    string
+	contextDir  string
+	format      output.Format
+	order       output.Order
+	registry    *parser.Registry
+	ignoreRules []string
+	config      config.Config
+
+	st          *state.State
+	hashes      map[string]string
+	parseResult *parser.ParseResult
+	graph       *graph.Graph
+	dirty       bool
+
+	// quick skips the search index on flush (update --quick); maxChanges,
+	// when positive, makes apply fail instead of reparsing more files.
+	quick      bool
+	maxChanges int
+	// since, when set, limits the scan to files git reports as changed
+	// relative to that revision; other files keep their recorded hashes.
+	since string
+	// timer adds up the scan, parse, graph and write phases of every batch.
+	timer *phaseTimer
+}
+
+// loadContextSession loads persisted state into a new session. gitignore,
+// when non-nil, switches .gitignore handling and records the new choice;
+// nil keeps the one recorded in state.
+func loadContextSession(rootPath string, format output.Format, order output.Order, gitignore *bool) (*contextSession, error) {
+	cfg, err := config.Load(rootPath)
+	if err != nil {
+		return nil, err
+	}
+	contextDir := filepath.Join(rootPath, output.ContextDir)
+	st, err := state.Load(contextDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load state: %w", err)
+	}
+	switched := gitignore != nil && st.NoGitignore == *gitignore
+	if switched {
+		st.NoGitignore = !*gitignore
+	}
+	ignoreRules, err := loadIgnoreRules(rootPath, !st.NoGitignore)
+	if err != nil {
+		return nil, err
+	}
+
+	// IDs recorded under an older scheme are forwarded in memory, like a
+	// switched .gitignore choice; marking the session dirty makes the next
+	// flush persist them and rewrite artifacts.
+	migrate := st.SymbolIDVersion != parser.SymbolIDVersion
+	if migrate {
+		previous := st.SymbolIDVersion
+		if previous == "" {
+			previous = "1"
+		}
+		forwarded := st.MigrateSymbolIDs(time.Now())
+		fmt.Fprintf(os.Stderr, "warning: symbol ID scheme changed (v%s -> v%s); forwarded %d IDs\n", previous, parser.SymbolIDVersion, forwarded)
+	}
+	timer := newPhaseTimer("update")
+	timer.sampleHeap = cfg.Metrics != ""
+	return &contextSession{
+		rootPath:    rootPath,
+		contextDir:  contextDir,
+		format:      format,
+		order:       order,
+		registry:    NewRegistry(cfg),
+		ignoreRules: ignoreRules,
+		config:      cfg,
+		st:          st,
+		dirty:       migrate || switched,
+		timer:       timer,
+	}, nil
+}
+
+// apply rescans the tree, reparses changed files and rebuilds the in-memory
+// graph. Nothing is written to disk; the session is marked dirty instead.
+func (s *contextSession) apply(asJSON bool) (RunSummary, error) {
+	start := time.Now()
+	endScan := s.timer.track("scan")
+	currentHashes, scanned, err := s.scan()
+	if err != nil {
+		return RunSummary{}, err
+	}
+	s.hashes = currentHashes
+	s.pruneParseIssues()
+
+	currentFiles := make(map[string]bool, len(currentHashes))
+	for file := range currentHashes {
+		currentFiles[file] = true
+	}
+
+	changed := s.st.ChangedFiles(currentHashes)
+	deleted := s.st.DeletedFiles(currentFiles)
+
+	// Backward compatibility: reparse files that have hash state but no cached symbols.
+	for file, fileState := range s.st.Files {
+		if currentFiles[file] && fileState.Language == "" && len(fileState.Symbols) == 0 {
+			changed = append(changed, file)
+		}
+	}
+
+	changed = fileutil.DedupeStrings(changed)
+	sort.Strings(changed)
+	sort.Strings(deleted)
+	endScan("files", scanned, "changed", len(changed), "deleted", len(deleted))
+
+	summary := RunSummary{
+		Mode:      "update",
+		Format:    string(s.format),
+		RootPath:  s.rootPath,
+		OutputDir: s.contextDir,
+		Scanned:   scanned,
+		Reused:    len(currentHashes),
+	}
+	if len(changed) == 0 && len(deleted) == 0 {
+		summary.DurationMS = time.Since(start).Milliseconds()
+		return summary, nil
+	}
+	if s.maxChanges > 0 && len(changed)+len(deleted) > s.maxChanges {
+		return RunSummary{}, fmt.Errorf("%d files changed or deleted; --quick handles at most %d, run `skelly update` without --quick", len(changed)+len(deleted), s.maxChanges)
+	}
+
+	endParse := s.timer.track("parse")
+	before := s.st.SnapshotSymbols(append(append([]string(nil), changed...), deleted...))
+	progress := newParseProgressReporter("update", len(changed), asJSON)
+	parsedCount := 0
+	skipped := make([]string, 0)
+	external := NewExternalFiles(s.config)
+	for _, file := range changed {
+		parsedCount++
+		progress.Update(file, parsedCount)
+		absPath := filepath.Join(s.rootPath, file)
+		parsed, err := s.registry.ParseFile(absPath)
+		if err != nil {
+			var skip *parser.SkipError
+			if errors.As(err, &skip) {
+				// Too large or binary now: drop what was indexed before.
+				s.st.RemoveFile(file)
+			}
+			// Otherwise keep the file's previous symbols; its recorded hash
+			// stays stale, so the next update tries it again.
+			s.recordParseFailure(file, err)
+			skipped = append(skipped, file)
+			continue
+		}
+		if parsed == nil {
+			// No longer supported or ignored by parser rules.
+			s.st.RemoveFile(file)
+			continue
+		}
+
+		parsed.Path = file
+		parsed.Hash = currentHashes[file]
+		external.Mark(parsed)
+		SkipFileContents(parsed, s.config)
+		fileutil.EnsureSymbolIDs(parsed)
+		s.st.SetFileData(*parsed)
+	}
+	progress.Done(parsedCount)
+	endParse("files", len(changed)-len(skipped), "skipped", len(skipped))
+
+	endGraph := s.timer.track("graph")
+	for _, file := range deleted {
+		s.st.RemoveFile(file)
+	}
+	s.st.ForwardSymbols(before, changed, time.Now())
+
+	impacted, reasons := fileutil.ImpactedWithReasons(s.st, changed, deleted)
+	sort.Strings(impacted)
+
+	parseResult := fileutil.ParseResultFromState(s.st, s.rootPath, currentHashes)
+	impactedExisting := fileutil.ExistingFiles(impacted, currentHashes)
+	impactedSet := fileutil.ToSet(impactedExisting)
+
+	// Recompute dependency graph metadata only for impacted sources and preserve unchanged state.
+	impactedGraph := graph.BuildFromParseResultForSources(parseResult, impactedSet)
+	fileutil.ApplyGraphDependencies(s.st, impactedGraph, impactedSet)
+
+	// Build full graph for final outputs from the merged state snapshots.
+	s.parseResult = parseResult
+	s.graph = graph.BuildFromParseResultWithRanks(parseResult, priorRanks(s.st))
+	s.dirty = true
+	endGraph("impacted", len(impacted), "nodes", len(s.graph.Nodes))
+
+	summary.Parsed = len(changed) - len(skipped)
+	summary.Skipped = len(skipped)
+	summary.SkippedFiles = skipped
+	summary.Reused = MaxInt(len(currentHashes)-len(changed), 0)
+	summary.Changed = len(changed)
+	summary.Deleted = len(deleted)
+	summary.Impacted = len(impacted)
+	summary.ChangedFiles = changed
+	summary.DeletedFiles = deleted
+	summary.ImpactedFiles = impacted
+	summary.Reasons = reasons
+	summary.DurationMS = time.Since(start).Milliseconds()
+	return summary, nil
+}
+
+// recordParseFailure reports a file that failed to parse or was skipped
+// and records it as a parse issue.
+func (s *contextSession) recordParseFailure(file string, err error) {
+	issue := parser.ParseIssue{File: file, Severity: "error", Message: err.Error()}
+	var skip *parser.SkipError
+	if errors.As(err, &skip) {
+		issue.Severity = "warning"
+		issue.Reason = skip.Reason
+	}
+	if language, ok := languages.FileLanguage(s.registry, file); ok {
+		issue.Language = language
+	}
+	ReportParseIssues([]parser.ParseIssue{issue})
+	s.st.AddParseIssue(issue)
+}
+
+// pruneParseIssues drops the recorded issues of files deleted since they
+// were recorded. Files that failed to parse are not tracked in state, so
+// their deletion does not show up among the deleted files.
+func (s *contextSession) pruneParseIssues() {
+	gone := make([]string, 0)
+	for _, issue := range s.st.ParseIssues {
+		if _, err := os.Lstat(filepath.Join(s.rootPath, issue.File)); os.IsNotExist(err) {
+			gone = append(gone, issue.File)
+		}
+	}
+	for _, file := range gone {
+		s.st.RemoveParseIssues(file)
+		s.dirty = true
+	}
+}
+
+// scan returns the current hash of every indexed source and how many files
+// were hashed to get there: the whole tree, or with since only the files git
+// reports as modified, added, deleted or untracked relative to that revision.
+func (s *contextSession) scan() (map[string]string, int, error) {
+	if s.since == "" {
+		hashes, err := fileutil.ScanFileHashes(s.rootPath, s.registry, s.ignoreRules)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan files: %w", err)
+		}
+		return hashes, len(hashes), nil
+	}
+
+	if _, err := gitLines(s.rootPath, "rev-parse", "--verify", "--quiet", s.since+"^{commit}"); err != nil {
+		return nil, 0, fmt.Errorf("--since: unknown revision %q", s.since)
+	}
+	diffed, err := gitLines(s.rootPath, "diff", "--name-only", "--relative", "--no-renames", s.since, "--")
+	if err != nil {
+		return nil, 0, err
+	}
+	untracked, err := gitLines(s.rootPath, "ls-files", "--others", "--exclude-standard")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	hashes := make(map[string]string, len(s.st.Files))
+	for file, fileState := range s.st.Files {
+		hashes[file] = fileState.Hash
+	}
+	matcher := ignore.NewMatcher(s.ignoreRules)
+	scanned := 0
+	for _, file := range fileutil.DedupeStrings(append(diffed, untracked...)) {
+		file = filepath.FromSlash(file)
+		if matcher.ShouldIgnore(file, false) {
+			delete(hashes, file)
+			continue
+		}
+		if _, ok := s.registry.GetParserForFile(file); !ok {
+			continue
+		}
+		hash, err := fileutil.HashFile(filepath.Join(s.rootPath, file))
+		if err != nil {
+			if os.IsNotExist(err) {
+				delete(hashes, file)
+				continue
+			}
+			return nil, 0, fmt.Errorf("failed to hash %s: %w", file, err)
+		}
+		hashes[file] = hash
+		scanned++
+	}
+	return hashes, scanned, nil
+}
+
+// needsRefresh reports whether on-disk artifacts are stale even though no
+// sources changed (missing or edited outputs, a different index order, or
+// enrich summaries written since the last flush).
+func (s *contextSession) needsRefresh() bool {
+	return OutputsNeedRefresh(s.st, s.contextDir, s.format) ||
+		(!s.quick && s.st.SearchStale) ||
+		(s.format == output.FormatText && s.st.IndexOrder != string(s.order)) ||
+		enrichHash(s.contextDir) != s.st.EnrichHash
+}
+
+// Graph returns the in-memory graph, building it from state when no batch
+// has been applied yet.
+func (s *contextSession) Graph() *graph.Graph {
+	s.ensureGraph()
+	return s.graph
+}
+
+func (s *contextSession) ensureGraph() {
+	if s.graph != nil {
+		return
+	}
+	s.parseResult = fileutil.ParseResultFromState(s.st, s.rootPath, s.hashes)
+	s.graph = graph.BuildFromParseResultWithRanks(s.parseResult, priorRanks(s.st))
+}
+
+// flush writes all artifacts and state from memory and returns how many
+// output files changed on disk.
+func (s *contextSession) flush() (int, error) {
+	s.ensureGraph()
+	endWrite := s.timer.track("write")
+	beforeOutputHashes := CloneOutputHashes(s.st.OutputHashes)
+
+	enrichHash, err := ApplyEnrichSummaries(s.contextDir, s.graph, s.st.AliasTargets())
+	if err != nil {
+		return 0, err
+	}
+	s.st.EnrichHash = enrichHash
+
+	writer := output.NewWriter(s.rootPath)
+	writer.SetOrder(s.order)
+	if err := writer.WriteAll(s.graph, s.parseResult, s.format); err != nil {
+		return 0, fmt.Errorf("failed to write output files: %w", err)
+	}
+	navSegments, err := nav.WriteIndex(s.contextDir, s.graph, s.st.AliasTargets(), indexSegments(s.st, nav.NavigationIndexFile))
+	if err != nil {
+		return 0, fmt.Errorf("failed to write navigation index: %w", err)
+	}
+	s.st.SetNavSegments(navSegments)
+	recordRanks(s.st, s.graph)
+	if err := nav.WriteRoutes(s.contextDir, s.graph); err != nil {
+		return 0, fmt.Errorf("failed to write routes index: %w", err)
+	}
+	if err := nav.WriteTests(s.contextDir, s.graph); err != nil {
+		return 0, fmt.Errorf("failed to write tests index: %w", err)
+	}
+	if err := output.WriteIssues(s.contextDir, s.st.ParseIssues); err != nil {
+		return 0, fmt.Errorf("failed to write parse issues: %w", err)
+	}
+	if s.quick {
+		s.st.SearchStale = true
+	} else {
+		searchSegments, err := search.Write(s.contextDir, s.graph, indexSegments(s.st, search.IndexFile))
+		if err != nil {
+			return 0, fmt.Errorf("failed to write search index: %w", err)
+		}
+		s.st.SetSearchSegments(searchSegments)
+		s.st.SearchStale = false
+	}
+	s.st.IndexOrder = string(s.order)
+	s.st.RecordCalibration(CalibrationRunFromGraph(s.graph, time.Now()))
+	if err := RecordOutputHashes(s.st, s.contextDir, s.format); err != nil {
+		return 0, fmt.Errorf("failed to update output hashes: %w", err)
+	}
+	if err := s.st.Save(s.contextDir); err != nil {
+		return 0, fmt.Errorf("failed to persist state: %w", err)
+	}
+
+	s.dirty = false
+	rewritten := CountRewrittenOutputs(beforeOutputHashes, s.st.OutputHashes)
+	endWrite("format", s.format, "rewritten", rewritten)
+	return rewritten, nil
+}
@@ -0,0 +1,55 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/morozRed/skelly/internal/snapshot"
+	"github.com/spf13/cobra"
+)
+
+// RunSnapshotDiff reports structural drift between two context snapshots.
+func RunSnapshotDiff(cmd *cobra.Command, args []string) error {
+	asJSON, err := cmd.Flags().GetBool("json")
+	if err != nil {
+		return fmt.Errorf("failed to read --json flag: %w", err)
+	}
+	asMarkdown, err := cmd.Flags().GetBool("markdown")
+	if err != nil {
+		return fmt.Errorf("failed to read --markdown flag: %w", err)
+	}
+	if asJSON && asMarkdown {
+		return fmt.Errorf("--json and --markdown are mutually exclusive")
+	}
+
+	before, err := snapshot.Load(args[0])
+	if err != nil {
+		return err
+	}
+	after, err := snapshot.Load(args[1])
+	if err != nil {
+		return err
+	}
+	report := snapshot.Diff(before, after)
+
+	switch {
+	case asJSON:
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	case asMarkdown:
+		fmt.Print(snapshot.RenderMarkdown(report))
+		return nil
+	}
+
+	fmt.Printf(
+		"snapshot diff: files=%d->%d symbols=%d->%d modules_changed=%d coupling_changed=%d new_cycles=%d resolved_cycles=%d coverage=%.1f%%->%.1f%%\n",
+		report.Files.Before, report.Files.After,
+		report.Symbols.Before, report.Symbols.After,
+		len(report.Modules), len(report.Coupling),
+		len(report.NewCycles), len(report.ResolvedCycles),
+		report.Coverage.Before*100, report.Coverage.After*100,
+	)
+	return nil
+}
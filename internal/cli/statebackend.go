@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/morozRed/skelly/internal/output"
+	"github.com/morozRed/skelly/internal/state"
+	"github.com/spf13/cobra"
+)
+
+// StateMigrateSummary reports a state backend conversion.
+type StateMigrateSummary struct {
+	Mode     string `json:"mode"`
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Files    int    `json:"files"`
+	Path     string `json:"path"`
+	Migrated bool   `json:"migrated"`
+}
+
+// RunStateMigrate converts the persisted state between backends.
+func RunStateMigrate(cmd *cobra.Command, args []string) error {
+	rootPath, err := resolveWorkingDirectory()
+	if err != nil {
+		return err
+	}
+	asJSON, err := cmd.Flags().GetBool("json")
+	if err != nil {
+		return fmt.Errorf("failed to read --json flag: %w", err)
+	}
+	raw, err := cmd.Flags().GetString("to")
+	if err != nil {
+		return fmt.Errorf("failed to read --to flag: %w", err)
+	}
+	backend, err := state.ParseBackend(raw)
+	if err != nil {
+		return err
+	}
+
+	contextDir := filepath.Join(rootPath, output.ContextDir)
+	if _, ok := state.Path(contextDir); !ok {
+		return fmt.Errorf("no state found in %s (run skelly generate first)", contextDir)
+	}
+	st, err := state.Load(contextDir)
+	if err != nil {
+		return fmt.Errorf("failed to load state: %w", err)
+	}
+	summary := StateMigrateSummary{Mode: "state-migrate", From: string(st.Backend), To: string(backend), Files: len(st.Files)}
+	summary.Migrated, err = state.Migrate(contextDir, backend)
+	if err != nil {
+		return fmt.Errorf("failed to migrate state: %w", err)
+	}
+	summary.Path, _ = state.Path(contextDir)
+
+	if asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(summary)
+	}
+	if !summary.Migrated {
+		fmt.Printf("state already uses the %s backend (%s)\n", summary.To, summary.Path)
+		return nil
+	}
+	fmt.Printf("state migrated: %s -> %s (%d files, %s)\n", summary.From, summary.To, summary.Files, summary.Path)
+	return nil
+}
+
+// ApplyStateBackend converts existing state to backend before generate or
+// update runs, so the run reads and writes the requested store. An empty
+// backend keeps whatever is on disk.
+func ApplyStateBackend(rootPath string, backend state.Backend, asJSON bool) error {
+	if backend == "" {
+		return nil
+	}
+	migrated, err := state.Migrate(filepath.Join(rootPath, output.ContextDir), backend)
+	if err != nil {
+		return fmt.Errorf("failed to switch state backend: %w", err)
+	}
+	if migrated && !asJSON {
+		fmt.Fprintf(os.Stderr, "state backend: %s\n", backend)
+	}
+	return nil
+}
@@ -25,10 +25,40 @@ func RunStatus(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to read --json flag: %w", err)
 	}
 
+	st, scanned, changed, deleted, err := pendingChanges(rootPath)
+	if err != nil {
+		return err
+	}
+	impacted, reasons := fileutil.ImpactedWithReasons(st, changed, deleted)
+
+	summary := RunSummary{
+		Mode:          "status",
+		RootPath:      rootPath,
+		Scanned:       scanned,
+		Parsed:        len(changed),
+		Reused:        MaxInt(scanned-len(changed), 0),
+		Rewritten:     0,
+		Changed:       len(changed),
+		Deleted:       len(deleted),
+		Impacted:      len(impacted),
+		DurationMS:    time.Since(start).Milliseconds(),
+		ChangedFiles:  changed,
+		DeletedFiles:  deleted,
+		ImpactedFiles: impacted,
+		Reasons:       reasons,
+	}
+
+	return PrintRunSummary(summary, asJSON)
+}
+
+// pendingChanges compares the working tree with the saved state and returns
+// the state, the number of scanned files and the changed and deleted files,
+// sorted.
+func pendingChanges(rootPath string) (*state.State, int, []string, []string, error) {
 	registry := languages.NewDefaultRegistry()
 	ignoreRules, err := LoadIgnoreRules(rootPath)
 	if err != nil {
-		return err
+		return nil, 0, nil, nil, err
 	}
 
 	contextDir := filepath.Join(rootPath, output.ContextDir)
@@ -38,13 +68,13 @@ func RunStatus(cmd *cobra.Command, args []string) error {
 			fmt.Fprintf(os.Stderr, "warning: corrupt state file detected (%v); treating all files as changed\n", err)
 			st = state.NewState()
 		} else {
-			return fmt.Errorf("failed to load state: %w", err)
+			return nil, 0, nil, nil, fmt.Errorf("failed to load state: %w", err)
 		}
 	}
 
 	currentHashes, err := fileutil.ScanFileHashes(rootPath, registry, ignoreRules)
 	if err != nil {
-		return fmt.Errorf("failed to scan files: %w", err)
+		return nil, 0, nil, nil, fmt.Errorf("failed to scan files: %w", err)
 	}
 
 	currentFiles := make(map[string]bool, len(currentHashes))
@@ -62,24 +92,5 @@ func RunStatus(cmd *cobra.Command, args []string) error {
 	changed = fileutil.DedupeStrings(changed)
 	sort.Strings(changed)
 	sort.Strings(deleted)
-	impacted, reasons := fileutil.ImpactedWithReasons(st, changed, deleted)
-
-	summary := RunSummary{
-		Mode:          "status",
-		RootPath:      rootPath,
-		Scanned:       len(currentHashes),
-		Parsed:        len(changed),
-		Reused:        MaxInt(len(currentHashes)-len(changed), 0),
-		Rewritten:     0,
-		Changed:       len(changed),
-		Deleted:       len(deleted),
-		Impacted:      len(impacted),
-		DurationMS:    time.Since(start).Milliseconds(),
-		ChangedFiles:  changed,
-		DeletedFiles:  deleted,
-		ImpactedFiles: impacted,
-		Reasons:       reasons,
-	}
-
-	return PrintRunSummary(summary, asJSON)
+	return st, len(currentHashes), changed, deleted, nil
 }
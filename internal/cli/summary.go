@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
+	"github.com/morozRed/skelly/internal/llm"
 	"github.com/morozRed/skelly/internal/lsp"
 )
 
@@ -26,6 +28,11 @@ type RunSummary struct {
 	DeletedFiles  []string            `json:"deleted_files,omitempty"`
 	ImpactedFiles []string            `json:"impacted_files,omitempty"`
 	Reasons       map[string][]string `json:"reasons,omitempty"`
+	// Skipped counts the files left unparsed: those that failed to parse,
+	// whose previous symbols update keeps, and those over the size limit or
+	// with binary content. Their issues are in issues.jsonl.
+	Skipped      int      `json:"skipped,omitempty"`
+	SkippedFiles []string `json:"skipped_files,omitempty"`
 }
 
 type EnrichRunSummary struct {
@@ -39,6 +46,7 @@ type EnrichRunSummary struct {
 	Symbols     int      `json:"symbols"`
 	Succeeded   int      `json:"succeeded,omitempty"`
 	Failed      int      `json:"failed,omitempty"`
+	Skipped     int      `json:"skipped,omitempty"`
 	CacheHits   int      `json:"cache_hits,omitempty"`
 	CacheMisses int      `json:"cache_misses,omitempty"`
 	DryRun      bool     `json:"dry_run"`
@@ -46,6 +54,29 @@ type EnrichRunSummary struct {
 	Targets     []string `json:"targets,omitempty"`
 }
 
+type FlushSummary struct {
+	Mode       string `json:"mode"`
+	RootPath   string `json:"root_path"`
+	OutputDir  string `json:"output_dir"`
+	DurationMS int64  `json:"duration_ms"`
+}
+
+// AliasRecord is one forwarding entry from a retired symbol ID.
+type AliasRecord struct {
+	ID        string `json:"id"`
+	Target    string `json:"target"`
+	Reason    string `json:"reason"`
+	CreatedAt string `json:"created_at"`
+}
+
+type AliasesPruneSummary struct {
+	Mode      string   `json:"mode"`
+	RootPath  string   `json:"root_path"`
+	Removed   int      `json:"removed"`
+	Remaining int      `json:"remaining"`
+	IDs       []string `json:"ids,omitempty"`
+}
+
 type DoctorSummary struct {
 	Mode                  string                    `json:"mode"`
 	RootPath              string                    `json:"root_path"`
@@ -62,6 +93,23 @@ type DoctorSummary struct {
 	Suggestions           []string                  `json:"suggestions,omitempty"`
 	Integrations          map[string]bool           `json:"integrations,omitempty"`
 	LSP                   map[string]lsp.Capability `json:"lsp,omitempty"`
+	ManagedBlocks         []llm.ManagedBlockStatus  `json:"managed_blocks,omitempty"`
+	Overviews             *OverviewCoverage         `json:"overviews,omitempty"`
+	// ParseErrors and ParseWarnings count the issues in issues.jsonl.
+	ParseErrors   int `json:"parse_errors,omitempty"`
+	ParseWarnings int `json:"parse_warnings,omitempty"`
+	// GeneratedAt is when state was last written by generate or update;
+	// Expired is set when it is older than --max-age.
+	GeneratedAt *time.Time `json:"generated_at,omitempty"`
+	Expired     bool       `json:"expired,omitempty"`
+	// ModifiedArtifacts and MissingArtifacts list artifacts whose on-disk
+	// content no longer matches the hash recorded in state.
+	ModifiedArtifacts []string `json:"modified_artifacts,omitempty"`
+	MissingArtifacts  []string `json:"missing_artifacts,omitempty"`
+	// Strict and Failures report what made doctor exit non-zero: context
+	// problems always count, setup gaps only with --strict.
+	Strict   bool     `json:"strict,omitempty"`
+	Failures []string `json:"failures,omitempty"`
 }
 
 func PrintRunSummary(summary RunSummary, asJSON bool) error {
@@ -85,6 +133,7 @@ func PrintRunSummary(summary RunSummary, asJSON bool) error {
 		if len(summary.ChangedFiles) > 0 {
 			fmt.Printf("changed files (%d): %s\n", len(summary.ChangedFiles), SummarizePaths(summary.ChangedFiles, 8))
 		}
+		printSkippedFiles(summary)
 		return nil
 	}
 
@@ -119,10 +168,17 @@ func PrintRunSummary(summary RunSummary, asJSON bool) error {
 			fmt.Printf("  %s <- %s\n", file, strings.Join(reasons, "; "))
 		}
 	}
+	printSkippedFiles(summary)
 
 	return nil
 }
 
+func printSkippedFiles(summary RunSummary) {
+	if summary.Skipped > 0 {
+		fmt.Printf("skipped files (%d, see skelly issues): %s\n", summary.Skipped, SummarizePaths(summary.SkippedFiles, 8))
+	}
+}
+
 func PrintEnrichSummary(summary EnrichRunSummary, asJSON bool) error {
 	if asJSON {
 		encoder := json.NewEncoder(os.Stdout)
@@ -130,9 +186,12 @@ func PrintEnrichSummary(summary EnrichRunSummary, asJSON bool) error {
 		return encoder.Encode(summary)
 	}
 
-	mode := "enrich"
+	mode := summary.Mode
+	if mode == "" {
+		mode = "enrich"
+	}
 	if summary.DryRun {
-		mode = "enrich (dry-run)"
+		mode += " (dry-run)"
 	}
 	parts := []string{
 		fmt.Sprintf("%s:", mode),
@@ -147,6 +206,11 @@ func PrintEnrichSummary(summary EnrichRunSummary, asJSON bool) error {
 		fmt.Sprintf("symbols=%d", summary.Symbols),
 		fmt.Sprintf("succeeded=%d", summary.Succeeded),
 		fmt.Sprintf("failed=%d", summary.Failed),
+	)
+	if summary.Skipped > 0 {
+		parts = append(parts, fmt.Sprintf("skipped=%d", summary.Skipped))
+	}
+	parts = append(parts,
 		fmt.Sprintf("cache_hits=%d", summary.CacheHits),
 		fmt.Sprintf("cache_misses=%d", summary.CacheMisses),
 		fmt.Sprintf("duration=%dms", summary.DurationMS),
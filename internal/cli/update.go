@@ -1,28 +1,31 @@
 package cli
 
 import (
+	"errors"
 	"fmt"
 	"os"
-	"path/filepath"
-	"sort"
 	"time"
 
-	"github.com/morozRed/skelly/internal/fileutil"
-	"github.com/morozRed/skelly/internal/graph"
-	"github.com/morozRed/skelly/internal/languages"
-	"github.com/morozRed/skelly/internal/nav"
 	"github.com/morozRed/skelly/internal/output"
-	"github.com/morozRed/skelly/internal/search"
 	"github.com/morozRed/skelly/internal/state"
 	"github.com/spf13/cobra"
 )
 
+// QuickUpdateMaxFiles caps how many changed or deleted files `update --quick`
+// will reparse before failing.
+const QuickUpdateMaxFiles = 200
+
 func RunUpdate(cmd *cobra.Command, args []string) error {
-	start := time.Now()
 	rootPath, err := resolveWorkingDirectory()
 	if err != nil {
 		return err
 	}
+	if _, err := ApplyProjectConfig(cmd, rootPath); err != nil {
+		return err
+	}
+	if err := ApplyArtifactCompression(cmd); err != nil {
+		return err
+	}
 	explain, err := cmd.Flags().GetBool("explain")
 	if err != nil {
 		return fmt.Errorf("failed to read --explain flag: %w", err)
@@ -31,26 +34,96 @@ func RunUpdate(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	format = RecordedOutputFormat(cmd, rootPath, format)
+	order, err := ParseIndexOrder(cmd)
+	if err != nil {
+		return err
+	}
 	asJSON, err := cmd.Flags().GetBool("json")
 	if err != nil {
 		return fmt.Errorf("failed to read --json flag: %w", err)
 	}
+	quick, err := cmd.Flags().GetBool("quick")
+	if err != nil {
+		return fmt.Errorf("failed to read --quick flag: %w", err)
+	}
+	execCommands, err := OptionalStringSliceFlag(cmd, "exec")
+	if err != nil {
+		return err
+	}
+	backend, err := ParseStateBackend(cmd)
+	if err != nil {
+		return err
+	}
+	if err := ApplyStateBackend(rootPath, backend, asJSON); err != nil {
+		return err
+	}
+
+	since, err := OptionalStringFlag(cmd, "since")
+	if err != nil {
+		return err
+	}
+	gitignore, err := GitignoreOverride(cmd)
+	if err != nil {
+		return err
+	}
 
-	registry := languages.NewDefaultRegistry()
-	ignoreRules, err := LoadIgnoreRules(rootPath)
+	summary, err := updateContext(rootPath, format, order, asJSON, quick, since, gitignore)
 	if err != nil {
 		return err
 	}
+	if !explain {
+		summary.Reasons = nil
+	}
+	if err := PrintRunSummary(summary, asJSON); err != nil {
+		return err
+	}
+	return RunExecHooks(rootPath, execCommands, summary, asJSON)
+}
+
+// UpdateContext runs the incremental update pipeline and returns its run summary.
+// Summary reasons are always populated; callers decide whether to surface them.
+func UpdateContext(rootPath string, format output.Format, order output.Order, asJSON bool) (RunSummary, error) {
+	return updateContext(rootPath, format, order, asJSON, false, "", nil)
+}
+
+// QuickUpdateContext is the pre-commit hook variant of UpdateContext. It
+// reparses at most QuickUpdateMaxFiles changed or deleted files, refreshes
+// symbols, edges and the navigation index, and leaves the search index
+// marked stale for the next full update. Instead of falling back to a full
+// regenerate (corrupt state, version changes, too many changes) it fails, so
+// its latency stays bounded by one tree scan plus QuickUpdateMaxFiles parses
+// and one graph build over cached symbols.
+func QuickUpdateContext(rootPath string, format output.Format, order output.Order, asJSON bool) (RunSummary, error) {
+	return updateContext(rootPath, format, order, asJSON, true, "", nil)
+}
 
-	contextDir := filepath.Join(rootPath, output.ContextDir)
-	st, err := state.Load(contextDir)
+// updateContext backs UpdateContext and QuickUpdateContext. A non-empty since
+// takes changed files from git relative to that revision instead of hashing
+// the whole tree; a non-nil gitignore switches .gitignore handling, which is
+// otherwise kept as recorded.
+func updateContext(rootPath string, format output.Format, order output.Order, asJSON bool, quick bool, since string, gitignore *bool) (RunSummary, error) {
+	start := time.Now()
+	session, err := loadContextSession(rootPath, format, order, gitignore)
 	if err != nil {
+		if quick {
+			return RunSummary{}, fmt.Errorf("%w; run `skelly update` without --quick", err)
+		}
 		if IsCorruptStateError(err) {
-			fmt.Fprintf(os.Stderr, "warning: corrupt state file detected (%v); running full regenerate\n", err)
-			return GenerateContext(rootPath, nil, format, asJSON)
+			fmt.Fprintf(os.Stderr, "warning: corrupt state file detected (%v); running full regenerate\n", errors.Unwrap(err))
+			return generateContext(rootPath, nil, format, order, asJSON, 0, gitignore == nil || *gitignore)
 		}
-		return fmt.Errorf("failed to load state: %w", err)
+		return RunSummary{}, err
+	}
+	st := session.st
+	if quick && (st.ParserVersion != state.CurrentParserVersion || st.OutputVersion != state.CurrentOutputVersion) {
+		return RunSummary{}, fmt.Errorf("context needs a full regenerate after a version change; run `skelly update` without --quick")
+	}
+	if quick {
+		session.quick = true
+		session.maxChanges = QuickUpdateMaxFiles
 	}
+	session.since = since
 	if st.ParserVersion != state.CurrentParserVersion {
 		fmt.Fprintf(
 			os.Stderr,
@@ -58,7 +131,7 @@ func RunUpdate(cmd *cobra.Command, args []string) error {
 			st.ParserVersion,
 			state.CurrentParserVersion,
 		)
-		return GenerateContext(rootPath, nil, format, asJSON)
+		return generateContext(rootPath, nil, format, order, asJSON, 0, !st.NoGitignore)
 	}
 	if st.OutputVersion != state.CurrentOutputVersion {
 		fmt.Fprintf(
@@ -67,155 +140,24 @@ func RunUpdate(cmd *cobra.Command, args []string) error {
 			st.OutputVersion,
 			state.CurrentOutputVersion,
 		)
-		return GenerateContext(rootPath, nil, format, asJSON)
+		return generateContext(rootPath, nil, format, order, asJSON, 0, !st.NoGitignore)
 	}
 
-	currentHashes, err := fileutil.ScanFileHashes(rootPath, registry, ignoreRules)
+	summary, err := session.apply(asJSON)
 	if err != nil {
-		return fmt.Errorf("failed to scan files: %w", err)
-	}
-
-	currentFiles := make(map[string]bool, len(currentHashes))
-	for file := range currentHashes {
-		currentFiles[file] = true
-	}
-
-	changed := st.ChangedFiles(currentHashes)
-	deleted := st.DeletedFiles(currentFiles)
-
-	// Backward compatibility: reparse files that have hash state but no cached symbols.
-	for file, fileState := range st.Files {
-		if currentFiles[file] && fileState.Language == "" && len(fileState.Symbols) == 0 {
-			changed = append(changed, file)
-		}
-	}
-
-	changed = fileutil.DedupeStrings(changed)
-	sort.Strings(changed)
-	sort.Strings(deleted)
-
-	if len(changed) == 0 && len(deleted) == 0 {
-		rewritten := 0
-		if OutputsNeedRefresh(st, contextDir, format) {
-			parseResult := fileutil.ParseResultFromState(st, rootPath, currentHashes)
-			g := graph.BuildFromParseResult(parseResult)
-			beforeOutputHashes := CloneOutputHashes(st.OutputHashes)
-
-			writer := output.NewWriter(rootPath)
-			if err := writer.WriteAll(g, parseResult, format); err != nil {
-				return fmt.Errorf("failed to write output files: %w", err)
-			}
-			if err := nav.WriteIndex(contextDir, g); err != nil {
-				return fmt.Errorf("failed to write navigation index: %w", err)
-			}
-			if err := search.Write(contextDir, g); err != nil {
-				return fmt.Errorf("failed to write search index: %w", err)
-			}
-			if err := RecordOutputHashes(st, contextDir, format); err != nil {
-				return fmt.Errorf("failed to update output hashes: %w", err)
-			}
-			if err := st.Save(contextDir); err != nil {
-				return fmt.Errorf("failed to persist state: %w", err)
-			}
-			rewritten = CountRewrittenOutputs(beforeOutputHashes, st.OutputHashes)
-		}
-
-		return PrintRunSummary(RunSummary{
-			Mode:       "update",
-			Format:     string(format),
-			RootPath:   rootPath,
-			OutputDir:  filepath.Join(rootPath, output.ContextDir),
-			Scanned:    len(currentHashes),
-			Parsed:     0,
-			Reused:     len(currentHashes),
-			Rewritten:  rewritten,
-			Changed:    0,
-			Deleted:    0,
-			Impacted:   0,
-			DurationMS: time.Since(start).Milliseconds(),
-		}, asJSON)
+		return RunSummary{}, err
 	}
-
-	progress := newParseProgressReporter("update", len(changed), asJSON)
-	parsedCount := 0
-	for _, file := range changed {
-		parsedCount++
-		progress.Update(file, parsedCount)
-		absPath := filepath.Join(rootPath, file)
-		parsed, err := registry.ParseFile(absPath)
+	if session.dirty || session.needsRefresh() {
+		rewritten, err := session.flush()
 		if err != nil {
-			progress.Done(parsedCount)
-			return fmt.Errorf("failed to parse %s: %w", file, err)
-		}
-		if parsed == nil {
-			// No longer supported or ignored by parser rules.
-			st.RemoveFile(file)
-			continue
+			return RunSummary{}, err
 		}
-
-		parsed.Path = file
-		parsed.Hash = currentHashes[file]
-		fileutil.EnsureSymbolIDs(parsed)
-		st.SetFileData(*parsed)
-	}
-	progress.Done(parsedCount)
-
-	for _, file := range deleted {
-		st.RemoveFile(file)
-	}
-
-	impacted, reasons := fileutil.ImpactedWithReasons(st, changed, deleted)
-	sort.Strings(impacted)
-
-	parseResult := fileutil.ParseResultFromState(st, rootPath, currentHashes)
-	impactedExisting := fileutil.ExistingFiles(impacted, currentHashes)
-	impactedSet := fileutil.ToSet(impactedExisting)
-
-	// Recompute dependency graph metadata only for impacted sources and preserve unchanged state.
-	impactedGraph := graph.BuildFromParseResultForSources(parseResult, impactedSet)
-	fileutil.ApplyGraphDependencies(st, impactedGraph, impactedSet)
-
-	// Build full graph for final outputs from the merged state snapshots.
-	g := graph.BuildFromParseResult(parseResult)
-	beforeOutputHashes := CloneOutputHashes(st.OutputHashes)
-
-	writer := output.NewWriter(rootPath)
-	if err := writer.WriteAll(g, parseResult, format); err != nil {
-		return fmt.Errorf("failed to write output files: %w", err)
-	}
-	if err := nav.WriteIndex(contextDir, g); err != nil {
-		return fmt.Errorf("failed to write navigation index: %w", err)
+		summary.Rewritten = rewritten
 	}
-	if err := search.Write(contextDir, g); err != nil {
-		return fmt.Errorf("failed to write search index: %w", err)
+	summary.DurationMS = time.Since(start).Milliseconds()
+	session.timer.finish("changed", summary.Changed, "deleted", summary.Deleted, "skipped", summary.Skipped)
+	if err := WriteRunMetrics(rootPath, session.config, session.timer, summary, len(st.OutputHashes)); err != nil {
+		return RunSummary{}, err
 	}
-	if err := RecordOutputHashes(st, contextDir, format); err != nil {
-		return fmt.Errorf("failed to update output hashes: %w", err)
-	}
-
-	if err := st.Save(contextDir); err != nil {
-		return fmt.Errorf("failed to persist state: %w", err)
-	}
-
-	summary := RunSummary{
-		Mode:          "update",
-		Format:        string(format),
-		RootPath:      rootPath,
-		OutputDir:     filepath.Join(rootPath, output.ContextDir),
-		Scanned:       len(currentHashes),
-		Parsed:        len(changed),
-		Reused:        MaxInt(len(currentHashes)-len(changed), 0),
-		Rewritten:     CountRewrittenOutputs(beforeOutputHashes, st.OutputHashes),
-		Changed:       len(changed),
-		Deleted:       len(deleted),
-		Impacted:      len(impacted),
-		DurationMS:    time.Since(start).Milliseconds(),
-		ChangedFiles:  changed,
-		DeletedFiles:  deleted,
-		ImpactedFiles: impacted,
-	}
-	if explain {
-		summary.Reasons = reasons
-	}
-	return PrintRunSummary(summary, asJSON)
+	return summary, nil
 }
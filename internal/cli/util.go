@@ -6,6 +6,11 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/morozRed/skelly/internal/config"
+	"github.com/morozRed/skelly/internal/ignore"
+	"github.com/morozRed/skelly/internal/output"
+	"github.com/morozRed/skelly/internal/state"
 )
 
 func resolveWorkingDirectory() (string, error) {
@@ -16,30 +21,60 @@ func resolveWorkingDirectory() (string, error) {
 	return rootPath, nil
 }
 
+// LoadIgnoreRules returns the rules from the repository's .gitignore files
+// (unless .skelly/config.yaml sets gitignore: false), then those from the
+// config, then those in .skellyignore, so later sources can re-include paths.
 func LoadIgnoreRules(rootPath string) ([]string, error) {
+	return loadIgnoreRules(rootPath, true)
+}
+
+// recordedGitignore reports whether the context in rootPath was generated
+// with .gitignore rules; missing or unreadable state counts as yes.
+func recordedGitignore(rootPath string) bool {
+	st, err := state.Load(filepath.Join(rootPath, output.ContextDir))
+	return err != nil || !st.NoGitignore
+}
+
+// loadIgnoreRules is LoadIgnoreRules with .gitignore files skipped when
+// gitignore is false, as for generate --no-gitignore.
+func loadIgnoreRules(rootPath string, gitignore bool) ([]string, error) {
+	cfg, err := config.Load(rootPath)
+	if err != nil {
+		return nil, err
+	}
+	userRules := make([]string, 0, len(cfg.Ignore)+1)
+	// A context moved out of .skelly/ by output_dir is not source either.
+	if output.ContextDir != output.DefaultContextDir {
+		userRules = append(userRules, "/"+output.ContextDir+"/")
+	}
+	userRules = append(userRules, cfg.Ignore...)
+
 	ignorePath := filepath.Join(rootPath, ".skellyignore")
 	f, err := os.Open(ignorePath)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return nil, nil
-		}
+	if err != nil && !os.IsNotExist(err) {
 		return nil, fmt.Errorf("failed to read .skellyignore: %w", err)
 	}
-	defer f.Close()
-
-	rules := make([]string, 0)
-	scanner := bufio.NewScanner(f)
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if line == "" || strings.HasPrefix(line, "#") {
-			continue
+	if err == nil {
+		defer f.Close()
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			userRules = append(userRules, line)
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("failed to parse .skellyignore: %w", err)
 		}
-		rules = append(rules, line)
 	}
 
-	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("failed to parse .skellyignore: %w", err)
+	if !gitignore || cfg.DisableGitignore {
+		return userRules, nil
 	}
-
-	return rules, nil
+	rules, err := ignore.GitignoreRules(rootPath, userRules)
+	if err != nil {
+		return nil, err
+	}
+	return append(rules, userRules...), nil
 }
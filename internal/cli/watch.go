@@ -0,0 +1,315 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/morozRed/skelly/internal/ignore"
+	"github.com/morozRed/skelly/internal/languages"
+	"github.com/morozRed/skelly/internal/output"
+	"github.com/morozRed/skelly/internal/parser"
+	"github.com/spf13/cobra"
+)
+
+const (
+	defaultWatchDebounce = 300 * time.Millisecond
+	defaultFlushInterval = 5 * time.Second
+)
+
+// WatchOptions configures the watch loop.
+type WatchOptions struct {
+	Format       output.Format
+	Order        output.Order
+	Debounce     time.Duration
+	ExecCommands []string
+	AsJSON       bool
+	// WriteBehind keeps the graph in memory and only writes artifacts every
+	// FlushInterval, on `skelly flush`, and on shutdown.
+	WriteBehind   bool
+	FlushInterval time.Duration
+	// Gitignore, when set, switches .gitignore handling; nil keeps the
+	// choice recorded when the context was generated.
+	Gitignore *bool
+	// OnSummary is invoked after each batch; when nil, summaries are printed.
+	OnSummary func(RunSummary) error
+	// Ready is closed once the initial sync finished and watches are installed.
+	Ready chan<- struct{}
+}
+
+func RunWatch(cmd *cobra.Command, args []string) error {
+	rootPath, err := resolveWorkingDirectory()
+	if err != nil {
+		return err
+	}
+	if _, err := ApplyProjectConfig(cmd, rootPath); err != nil {
+		return err
+	}
+	if err := ApplyArtifactCompression(cmd); err != nil {
+		return err
+	}
+	format, err := ParseOutputFormat(cmd)
+	if err != nil {
+		return err
+	}
+	format = RecordedOutputFormat(cmd, rootPath, format)
+	order, err := ParseIndexOrder(cmd)
+	if err != nil {
+		return err
+	}
+	asJSON, err := cmd.Flags().GetBool("json")
+	if err != nil {
+		return fmt.Errorf("failed to read --json flag: %w", err)
+	}
+	debounce, err := cmd.Flags().GetDuration("debounce")
+	if err != nil {
+		return fmt.Errorf("failed to read --debounce flag: %w", err)
+	}
+	execCommands, err := OptionalStringSliceFlag(cmd, "exec")
+	if err != nil {
+		return err
+	}
+	writeBehind, err := cmd.Flags().GetBool("write-behind")
+	if err != nil {
+		return fmt.Errorf("failed to read --write-behind flag: %w", err)
+	}
+	flushInterval, err := cmd.Flags().GetDuration("flush-interval")
+	if err != nil {
+		return fmt.Errorf("failed to read --flush-interval flag: %w", err)
+	}
+	gitignore, err := GitignoreOverride(cmd)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	return WatchContext(ctx, rootPath, WatchOptions{
+		Format:        format,
+		Order:         order,
+		Debounce:      debounce,
+		ExecCommands:  execCommands,
+		AsJSON:        asJSON,
+		WriteBehind:   writeBehind,
+		FlushInterval: flushInterval,
+		Gitignore:     gitignore,
+	})
+}
+
+// WatchContext runs an initial update, then re-runs the incremental update
+// pipeline for each debounced batch of file system events until ctx is done.
+func WatchContext(ctx context.Context, rootPath string, opts WatchOptions) error {
+	if opts.Debounce <= 0 {
+		opts.Debounce = defaultWatchDebounce
+	}
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = defaultFlushInterval
+	}
+	emit := opts.OnSummary
+	if emit == nil {
+		emit = func(summary RunSummary) error {
+			return printWatchSummary(summary, opts.AsJSON)
+		}
+	}
+
+	gitignore := opts.Gitignore
+	if gitignore == nil {
+		recorded := recordedGitignore(rootPath)
+		gitignore = &recorded
+	}
+	ignoreRules, err := loadIgnoreRules(rootPath, *gitignore)
+	if err != nil {
+		return err
+	}
+	matcher := ignore.NewMatcher(ignoreRules)
+	registry := languages.NewDefaultRegistry()
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to start file watcher: %w", err)
+	}
+	defer watcher.Close()
+	if err := addWatchDirs(watcher, rootPath, rootPath, matcher); err != nil {
+		return err
+	}
+
+	runBatch := func() error {
+		summary, err := updateContext(rootPath, opts.Format, opts.Order, true, false, "", gitignore)
+		if err != nil {
+			return err
+		}
+		summary.Mode = "watch"
+		summary.Reasons = nil
+		if summary.Changed == 0 && summary.Deleted == 0 && summary.Rewritten == 0 {
+			return nil
+		}
+		if err := emit(summary); err != nil {
+			return err
+		}
+		return RunExecHooks(rootPath, opts.ExecCommands, summary, opts.AsJSON)
+	}
+
+	if err := runBatch(); err != nil {
+		return err
+	}
+
+	// Write-behind: the initial sync above left artifacts current; later
+	// batches only touch the in-memory session until the next flush.
+	var writeBehind *writeBehindSession
+	var flushTicker <-chan time.Time
+	if opts.WriteBehind {
+		writeBehind, err = startWriteBehind(watcher, rootPath, opts, emit)
+		if err != nil {
+			return err
+		}
+		defer writeBehind.close()
+		runBatch = writeBehind.apply
+		ticker := time.NewTicker(opts.FlushInterval)
+		defer ticker.Stop()
+		flushTicker = ticker.C
+	}
+
+	if !opts.AsJSON {
+		if opts.WriteBehind {
+			fmt.Fprintf(os.Stderr, "watching %s (debounce %s, write-behind flush every %s, Ctrl+C to stop)\n", rootPath, opts.Debounce, opts.FlushInterval)
+		} else {
+			fmt.Fprintf(os.Stderr, "watching %s (debounce %s, Ctrl+C to stop)\n", rootPath, opts.Debounce)
+		}
+	}
+	if opts.Ready != nil {
+		close(opts.Ready)
+	}
+
+	timer := time.NewTimer(opts.Debounce)
+	timer.Stop()
+	pending := false
+
+	for {
+		select {
+		case <-ctx.Done():
+			if writeBehind != nil {
+				// Apply anything still debouncing so shutdown never drops edits.
+				if pending {
+					if err := runBatch(); err != nil {
+						fmt.Fprintf(os.Stderr, "warning: watch update failed: %v\n", err)
+					}
+				}
+				return writeBehind.flush()
+			}
+			return nil
+		case <-flushTicker:
+			if err := writeBehind.flush(); err != nil {
+				fmt.Fprintf(os.Stderr, "warning: watch flush failed: %v\n", err)
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			fmt.Fprintf(os.Stderr, "warning: watch error: %v\n", err)
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if writeBehind != nil && writeBehind.isFlushRequest(event) {
+				// Apply pending edits first so the flush reflects the working tree.
+				if pending {
+					pending = false
+					timer.Stop()
+					if err := runBatch(); err != nil {
+						fmt.Fprintf(os.Stderr, "warning: watch update failed: %v\n", err)
+					}
+				}
+				flushErr := writeBehind.flush()
+				if flushErr != nil {
+					fmt.Fprintf(os.Stderr, "warning: watch flush failed: %v\n", flushErr)
+				}
+				writeBehind.ackFlushRequest(flushErr)
+				continue
+			}
+			if !relevantWatchEvent(watcher, rootPath, event, matcher, registry) {
+				continue
+			}
+			// Each relevant event pushes the deadline out so bursts collapse into one batch.
+			pending = true
+			timer.Reset(opts.Debounce)
+		case <-timer.C:
+			if !pending {
+				continue
+			}
+			pending = false
+			if err := runBatch(); err != nil {
+				fmt.Fprintf(os.Stderr, "warning: watch update failed: %v\n", err)
+			}
+		}
+	}
+}
+
+func relevantWatchEvent(watcher *fsnotify.Watcher, rootPath string, event fsnotify.Event, matcher *ignore.Matcher, registry *parser.Registry) bool {
+	if event.Op == fsnotify.Chmod {
+		return false
+	}
+	relPath, err := filepath.Rel(rootPath, event.Name)
+	if err != nil || strings.HasPrefix(relPath, "..") {
+		return false
+	}
+
+	if event.Op.Has(fsnotify.Create) {
+		if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+			if matcher.ShouldIgnore(relPath, true) {
+				return false
+			}
+			if err := addWatchDirs(watcher, rootPath, event.Name, matcher); err != nil {
+				fmt.Fprintf(os.Stderr, "warning: failed to watch %s: %v\n", relPath, err)
+			}
+			// Files may have been written before the watch was installed.
+			return true
+		}
+	}
+
+	if matcher.ShouldIgnore(relPath, false) {
+		return false
+	}
+	if _, ok := registry.GetParserForFile(event.Name); ok {
+		return true
+	}
+	// Removed or renamed directories do not carry an extension; let update reconcile them.
+	return event.Op.Has(fsnotify.Remove) || event.Op.Has(fsnotify.Rename)
+}
+
+func addWatchDirs(watcher *fsnotify.Watcher, rootPath, start string, matcher *ignore.Matcher) error {
+	return filepath.Walk(start, func(path string, info os.FileInfo, walkErr error) error {
+		if walkErr != nil {
+			return nil
+		}
+		if !info.IsDir() {
+			return nil
+		}
+		relPath, err := filepath.Rel(rootPath, path)
+		if err != nil {
+			return err
+		}
+		if relPath != "." && matcher.ShouldIgnore(relPath, true) {
+			return filepath.SkipDir
+		}
+		if err := watcher.Add(path); err != nil {
+			return fmt.Errorf("failed to watch %s: %w", relPath, err)
+		}
+		return nil
+	})
+}
+
+func printWatchSummary(summary RunSummary, asJSON bool) error {
+	if asJSON {
+		// One compact summary per line so consumers can stream batches.
+		return json.NewEncoder(os.Stdout).Encode(summary)
+	}
+	return PrintRunSummary(summary, false)
+}
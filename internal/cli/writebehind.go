@@ -0,0 +1,153 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/morozRed/skelly/internal/fileutil"
+	"github.com/morozRed/skelly/internal/output"
+)
+
+const (
+	// writeBehindMarkerFile is present in the context dir while a
+	// write-behind watch runs; it holds the watcher's pid.
+	writeBehindMarkerFile = ".write-behind"
+	// flushRequestFile is created by `skelly flush`. The watcher removes it
+	// after a successful flush or writes the failure message into it.
+	flushRequestFile = ".flush-request"
+)
+
+// writeBehindSession applies watch batches to an in-memory contextSession and
+// accumulates the touched files until the next flush writes artifacts.
+type writeBehindSession struct {
+	session     *contextSession
+	rootPath    string
+	opts        WatchOptions
+	emit        func(RunSummary) error
+	markerPath  string
+	requestPath string
+
+	changed  map[string]bool
+	deleted  map[string]bool
+	impacted map[string]bool
+}
+
+func startWriteBehind(watcher *fsnotify.Watcher, rootPath string, opts WatchOptions, emit func(RunSummary) error) (*writeBehindSession, error) {
+	session, err := loadContextSession(rootPath, opts.Format, opts.Order, opts.Gitignore)
+	if err != nil {
+		return nil, err
+	}
+	contextDir := filepath.Join(rootPath, output.ContextDir)
+	wb := &writeBehindSession{
+		session:     session,
+		rootPath:    rootPath,
+		opts:        opts,
+		emit:        emit,
+		markerPath:  filepath.Join(contextDir, writeBehindMarkerFile),
+		requestPath: filepath.Join(contextDir, flushRequestFile),
+		changed:     make(map[string]bool),
+		deleted:     make(map[string]bool),
+		impacted:    make(map[string]bool),
+	}
+
+	// A request left behind by a crashed watcher would never be acknowledged.
+	if err := os.Remove(wb.requestPath); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to clear stale flush request: %w", err)
+	}
+	if err := os.WriteFile(wb.markerPath, []byte(strconv.Itoa(os.Getpid())+"\n"), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write write-behind marker: %w", err)
+	}
+	// The context dir is ignored for source events; watch it for flush requests only.
+	if err := watcher.Add(contextDir); err != nil {
+		_ = os.Remove(wb.markerPath)
+		return nil, fmt.Errorf("failed to watch %s: %w", output.ContextDir, err)
+	}
+	return wb, nil
+}
+
+// apply updates the in-memory graph for one batch without touching artifacts.
+func (wb *writeBehindSession) apply() error {
+	summary, err := wb.session.apply(true)
+	if err != nil {
+		return err
+	}
+	if summary.Changed == 0 && summary.Deleted == 0 {
+		return nil
+	}
+	for _, file := range summary.ChangedFiles {
+		wb.changed[file] = true
+	}
+	for _, file := range summary.DeletedFiles {
+		wb.deleted[file] = true
+	}
+	for _, file := range summary.ImpactedFiles {
+		wb.impacted[file] = true
+	}
+
+	summary.Mode = "watch"
+	summary.Reasons = nil
+	return wb.emit(summary)
+}
+
+// flush writes artifacts when batches were applied since the last flush and
+// runs exec hooks with every file touched in between.
+func (wb *writeBehindSession) flush() error {
+	if !wb.session.dirty {
+		return nil
+	}
+	start := time.Now()
+	rewritten, err := wb.session.flush()
+	if err != nil {
+		return err
+	}
+
+	summary := RunSummary{
+		Mode:          "flush",
+		Format:        string(wb.opts.Format),
+		RootPath:      wb.rootPath,
+		OutputDir:     wb.session.contextDir,
+		Scanned:       len(wb.session.hashes),
+		Reused:        len(wb.session.hashes),
+		Rewritten:     rewritten,
+		Changed:       len(wb.changed),
+		Deleted:       len(wb.deleted),
+		Impacted:      len(wb.impacted),
+		DurationMS:    time.Since(start).Milliseconds(),
+		ChangedFiles:  fileutil.MapKeysSorted(wb.changed),
+		DeletedFiles:  fileutil.MapKeysSorted(wb.deleted),
+		ImpactedFiles: fileutil.MapKeysSorted(wb.impacted),
+	}
+	wb.changed = make(map[string]bool)
+	wb.deleted = make(map[string]bool)
+	wb.impacted = make(map[string]bool)
+
+	if err := wb.emit(summary); err != nil {
+		return err
+	}
+	return RunExecHooks(wb.rootPath, wb.opts.ExecCommands, summary, wb.opts.AsJSON)
+}
+
+func (wb *writeBehindSession) isFlushRequest(event fsnotify.Event) bool {
+	return event.Name == wb.requestPath && event.Op.Has(fsnotify.Create)
+}
+
+// ackFlushRequest reports the flush outcome back to `skelly flush`.
+func (wb *writeBehindSession) ackFlushRequest(flushErr error) {
+	if flushErr != nil {
+		if err := os.WriteFile(wb.requestPath, []byte(flushErr.Error()+"\n"), 0644); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: failed to report flush failure: %v\n", err)
+		}
+		return
+	}
+	if err := os.Remove(wb.requestPath); err != nil && !os.IsNotExist(err) {
+		fmt.Fprintf(os.Stderr, "warning: failed to acknowledge flush request: %v\n", err)
+	}
+}
+
+func (wb *writeBehindSession) close() {
+	_ = os.Remove(wb.markerPath)
+}
@@ -0,0 +1,483 @@
+// Package config loads project defaults from .skelly/config.yaml so flags do
+// not have to be repeated on every invocation or in the git hook.
+package config
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/morozRed/skelly/internal/languages"
+)
+
+// File is the project config file, relative to the repository root.
+const File = ".skelly/config.yaml"
+
+// MetricsFile is where metrics: true has generate and update record their
+// run metrics, relative to the repository root.
+const MetricsFile = ".skelly/metrics.json"
+
+// Config holds project defaults. Empty fields leave the flag defaults alone.
+type Config struct {
+	Format       string   `json:"format,omitempty"`
+	Order        string   `json:"order,omitempty"`
+	Languages    []string `json:"languages,omitempty"`
+	Jobs         int      `json:"jobs,omitempty"`
+	StateBackend string   `json:"state_backend,omitempty"`
+	// Compress stores the large JSONL, navigation and search artifacts
+	// compressed (compress: gzip or zstd), as --compress does.
+	Compress string `json:"compress,omitempty"`
+	// Ignore rules use .skellyignore syntax and are applied before that file,
+	// so .skellyignore can still re-include paths with "!".
+	Ignore []string `json:"ignore,omitempty"`
+	// DisableGitignore stops .gitignore files from adding ignore rules
+	// (gitignore: false), as generate --no-gitignore does for one run.
+	DisableGitignore bool `json:"disable_gitignore,omitempty"`
+	// SkipGenerated drops the symbols and imports of files whose header
+	// marks them as generated (skip_generated: true). The files stay tracked
+	// so update does not reparse them.
+	SkipGenerated bool `json:"skip_generated,omitempty"`
+	// SkipBuildIgnored does the same for Go files constrained by
+	// //go:build ignore (skip_build_ignored: true).
+	SkipBuildIgnored bool `json:"skip_build_ignored,omitempty"`
+	// MaxFileBytes overrides the size above which source files are skipped
+	// unparsed (parser.DefaultMaxFileBytes when 0).
+	MaxFileBytes int `json:"max_file_bytes,omitempty"`
+	// OutputDir moves the context directory (output.DefaultContextDir when
+	// empty), relative to the repository root. It is read from the config of
+	// the directory skelly runs in and applies to every command.
+	OutputDir string `json:"output_dir,omitempty"`
+	// Metrics is the file generate and update write run metrics to, relative
+	// to the repository root: MetricsFile for metrics: true, or the path
+	// given. Empty leaves metrics off.
+	Metrics string `json:"metrics,omitempty"`
+	// External lists gitignore-style patterns of files to classify as
+	// external code, besides vendored and generated directories.
+	External []string `json:"external,omitempty"`
+	// LLM lists the integrations `skelly init` writes (codex, claude, cursor).
+	LLM []string `json:"llm,omitempty"`
+	// Roots lists the project roots of a monorepo as directories or globs
+	// ("services/*"), for generate --all-roots. Each root is indexed into its
+	// own context with its own config and ignore rules.
+	Roots      []string   `json:"roots,omitempty"`
+	Hooks      Hooks      `json:"hooks,omitempty"`
+	Embeddings Embeddings `json:"embeddings,omitempty"`
+	Deadcode   Deadcode   `json:"deadcode,omitempty"`
+	Enrich     Enrich     `json:"enrich,omitempty"`
+	// Parsers turns off optional extractions per language, keyed by
+	// canonical language name (parsers.javascript.calls: false).
+	Parsers map[string]ParserFeatures `json:"parsers,omitempty"`
+}
+
+// ParserFeatures lists the extractions a language's symbols are indexed
+// without, trading fidelity for speed on large repositories. The parsers skip
+// them while walking the syntax tree; it converts to parser.Features.
+type ParserFeatures struct {
+	// SkipCalls drops call sites (calls: false), and with them call edges.
+	SkipCalls bool `json:"skip_calls,omitempty"`
+	// SkipDocs drops doc comments and docstrings (docs: false).
+	SkipDocs bool `json:"skip_docs,omitempty"`
+	// SkipReferences drops referenced types (references: false).
+	SkipReferences bool `json:"skip_references,omitempty"`
+}
+
+// Hooks configures commands run by update and watch.
+type Hooks struct {
+	// Exec commands run after each update, as with --exec.
+	Exec []string `json:"exec,omitempty"`
+}
+
+// Embeddings configures the provider `enrich embed` and `search --semantic`
+// use: a command or an OpenAI-compatible endpoint, and the model to request.
+type Embeddings struct {
+	Command  string `json:"command,omitempty"`
+	Endpoint string `json:"endpoint,omitempty"`
+	Model    string `json:"model,omitempty"`
+}
+
+// Deadcode configures `skelly deadcode`.
+type Deadcode struct {
+	// Allow lists symbols or path globs kept out of the report, as --allow.
+	Allow []string `json:"allow,omitempty"`
+}
+
+// Enrich configures the records `skelly enrich` writes.
+type Enrich struct {
+	// MaxBodyBytes caps the source body in a record when --max-body-bytes
+	// is not passed.
+	MaxBodyBytes int `json:"max_body_bytes,omitempty"`
+	// Agent is the agent profile `skelly enrich` records when --agent is
+	// not passed.
+	Agent string `json:"agent,omitempty"`
+	// MaxSymbols caps the symbols `enrich bootstrap` seeds when --limit is
+	// not passed.
+	MaxSymbols int `json:"max_symbols,omitempty"`
+}
+
+// Path returns the config file path under rootPath.
+func Path(rootPath string) string {
+	return filepath.Join(rootPath, filepath.FromSlash(File))
+}
+
+// Load reads the config under rootPath. A missing file yields an empty config.
+func Load(rootPath string) (Config, error) {
+	data, err := os.ReadFile(Path(rootPath))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return Config{}, nil
+		}
+		return Config{}, fmt.Errorf("failed to read %s: %w", File, err)
+	}
+	cfg, err := Parse(string(data))
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid %s: %w", File, err)
+	}
+	return cfg, nil
+}
+
+// Parse decodes config YAML, rejecting unknown keys and mistyped values.
+func Parse(data string) (Config, error) {
+	values, err := parseYAML(data)
+	if err != nil {
+		return Config{}, err
+	}
+
+	var cfg Config
+	for _, key := range sortedKeys(values) {
+		value := values[key]
+		switch key {
+		case "format":
+			cfg.Format, err = scalarValue(key, value)
+		case "order":
+			cfg.Order, err = scalarValue(key, value)
+		case "languages":
+			cfg.Languages, err = listValue(key, value)
+		case "jobs":
+			var raw string
+			if raw, err = scalarValue(key, value); err == nil && raw != "" {
+				cfg.Jobs, err = strconv.Atoi(raw)
+				if err != nil || cfg.Jobs < 0 {
+					err = fmt.Errorf("jobs must be a non-negative integer, got %q", raw)
+				}
+			}
+		case "state_backend":
+			cfg.StateBackend, err = scalarValue(key, value)
+		case "compress":
+			cfg.Compress, err = scalarValue(key, value)
+		case "ignore":
+			cfg.Ignore, err = listValue(key, value)
+		case "gitignore":
+			var enabled bool
+			if enabled, err = boolValue(key, value); err == nil {
+				cfg.DisableGitignore = !enabled
+			}
+		case "skip_generated":
+			cfg.SkipGenerated, err = boolValue(key, value)
+		case "skip_build_ignored":
+			cfg.SkipBuildIgnored, err = boolValue(key, value)
+		case "max_file_bytes":
+			var raw string
+			if raw, err = scalarValue(key, value); err == nil && raw != "" {
+				cfg.MaxFileBytes, err = strconv.Atoi(raw)
+				if err != nil || cfg.MaxFileBytes <= 0 {
+					err = fmt.Errorf("max_file_bytes must be a positive integer, got %q", raw)
+				}
+			}
+		case "output_dir":
+			cfg.OutputDir, err = outputDirValue(value)
+		case "metrics":
+			cfg.Metrics, err = metricsValue(value)
+		case "external":
+			cfg.External, err = listValue(key, value)
+		case "llm":
+			cfg.LLM, err = listValue(key, value)
+		case "roots":
+			cfg.Roots, err = listValue(key, value)
+		case "hooks":
+			cfg.Hooks, err = hooksValue(value)
+		case "embeddings":
+			cfg.Embeddings, err = embeddingsValue(value)
+		case "deadcode":
+			cfg.Deadcode, err = deadcodeValue(value)
+		case "enrich":
+			cfg.Enrich, err = enrichValue(value)
+		case "parsers":
+			cfg.Parsers, err = parsersValue(value)
+		default:
+			err = fmt.Errorf("unknown key %q", key)
+		}
+		if err != nil {
+			return Config{}, err
+		}
+	}
+	return cfg, nil
+}
+
+func hooksValue(value any) (Hooks, error) {
+	if value == "" {
+		return Hooks{}, nil
+	}
+	fields, ok := value.(map[string]any)
+	if !ok {
+		return Hooks{}, fmt.Errorf("hooks must be a mapping")
+	}
+	var hooks Hooks
+	for _, key := range sortedKeys(fields) {
+		switch key {
+		case "exec":
+			commands, err := listValue("hooks.exec", fields[key])
+			if err != nil {
+				return Hooks{}, err
+			}
+			hooks.Exec = commands
+		default:
+			return Hooks{}, fmt.Errorf("unknown key %q", "hooks."+key)
+		}
+	}
+	return hooks, nil
+}
+
+func embeddingsValue(value any) (Embeddings, error) {
+	if value == "" {
+		return Embeddings{}, nil
+	}
+	fields, ok := value.(map[string]any)
+	if !ok {
+		return Embeddings{}, fmt.Errorf("embeddings must be a mapping")
+	}
+	var embeddings Embeddings
+	for _, key := range sortedKeys(fields) {
+		var err error
+		switch key {
+		case "command":
+			embeddings.Command, err = scalarValue("embeddings.command", fields[key])
+		case "endpoint":
+			embeddings.Endpoint, err = scalarValue("embeddings.endpoint", fields[key])
+		case "model":
+			embeddings.Model, err = scalarValue("embeddings.model", fields[key])
+		default:
+			err = fmt.Errorf("unknown key %q", "embeddings."+key)
+		}
+		if err != nil {
+			return Embeddings{}, err
+		}
+	}
+	return embeddings, nil
+}
+
+func deadcodeValue(value any) (Deadcode, error) {
+	if value == "" {
+		return Deadcode{}, nil
+	}
+	fields, ok := value.(map[string]any)
+	if !ok {
+		return Deadcode{}, fmt.Errorf("deadcode must be a mapping")
+	}
+	var deadcode Deadcode
+	for _, key := range sortedKeys(fields) {
+		switch key {
+		case "allow":
+			allow, err := listValue("deadcode.allow", fields[key])
+			if err != nil {
+				return Deadcode{}, err
+			}
+			deadcode.Allow = allow
+		default:
+			return Deadcode{}, fmt.Errorf("unknown key %q", "deadcode."+key)
+		}
+	}
+	return deadcode, nil
+}
+
+func enrichValue(value any) (Enrich, error) {
+	if value == "" {
+		return Enrich{}, nil
+	}
+	fields, ok := value.(map[string]any)
+	if !ok {
+		return Enrich{}, fmt.Errorf("enrich must be a mapping")
+	}
+	var enrich Enrich
+	for _, key := range sortedKeys(fields) {
+		switch key {
+		case "max_body_bytes":
+			raw, err := scalarValue("enrich.max_body_bytes", fields[key])
+			if err != nil {
+				return Enrich{}, err
+			}
+			enrich.MaxBodyBytes, err = strconv.Atoi(raw)
+			if err != nil || enrich.MaxBodyBytes < 0 {
+				return Enrich{}, fmt.Errorf("enrich.max_body_bytes must be a non-negative integer, got %q", raw)
+			}
+		case "agent":
+			agent, err := scalarValue("enrich.agent", fields[key])
+			if err != nil {
+				return Enrich{}, err
+			}
+			enrich.Agent = strings.TrimSpace(agent)
+		case "max_symbols":
+			raw, err := scalarValue("enrich.max_symbols", fields[key])
+			if err != nil {
+				return Enrich{}, err
+			}
+			enrich.MaxSymbols, err = strconv.Atoi(raw)
+			if err != nil || enrich.MaxSymbols < 0 {
+				return Enrich{}, fmt.Errorf("enrich.max_symbols must be a non-negative integer, got %q", raw)
+			}
+		default:
+			return Enrich{}, fmt.Errorf("unknown key %q", "enrich."+key)
+		}
+	}
+	return enrich, nil
+}
+
+func parsersValue(value any) (map[string]ParserFeatures, error) {
+	if value == "" {
+		return nil, nil
+	}
+	fields, ok := value.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("parsers must be a mapping")
+	}
+	parsers := make(map[string]ParserFeatures, len(fields))
+	for _, name := range sortedKeys(fields) {
+		language, ok := languages.CanonicalLanguage(name)
+		if !ok {
+			return nil, fmt.Errorf("parsers: unsupported language %q (supported: %s)", name, strings.Join(languages.SupportedLanguages(), ", "))
+		}
+		features, ok := fields[name].(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("parsers.%s must be a mapping", name)
+		}
+		var parser ParserFeatures
+		for _, key := range sortedKeys(features) {
+			enabled, err := boolValue("parsers."+name+"."+key, features[key])
+			if err != nil {
+				return nil, err
+			}
+			switch key {
+			case "calls":
+				parser.SkipCalls = !enabled
+			case "docs":
+				parser.SkipDocs = !enabled
+			case "references":
+				parser.SkipReferences = !enabled
+			default:
+				return nil, fmt.Errorf("unknown key %q", "parsers."+name+"."+key)
+			}
+		}
+		parsers[language] = parser
+	}
+	return parsers, nil
+}
+
+func scalarValue(key string, value any) (string, error) {
+	text, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("%s must be a single value", key)
+	}
+	return text, nil
+}
+
+func boolValue(key string, value any) (bool, error) {
+	raw, err := scalarValue(key, value)
+	if err != nil {
+		return false, err
+	}
+	enabled, err := strconv.ParseBool(raw)
+	if err != nil {
+		return false, fmt.Errorf("%s must be true or false, got %q", key, raw)
+	}
+	return enabled, nil
+}
+
+// metricsValue maps metrics: true to MetricsFile and false to "", and
+// takes any other scalar as the file to write.
+func metricsValue(value any) (string, error) {
+	raw, err := scalarValue("metrics", value)
+	if err != nil {
+		return "", err
+	}
+	if enabled, err := strconv.ParseBool(raw); err == nil {
+		if enabled {
+			return MetricsFile, nil
+		}
+		return "", nil
+	}
+	return raw, nil
+}
+
+// outputDirValue accepts a relative directory inside the repository and
+// returns it cleaned, in slash form.
+func outputDirValue(value any) (string, error) {
+	raw, err := scalarValue("output_dir", value)
+	if err != nil || raw == "" {
+		return "", err
+	}
+	dir := path.Clean(filepath.ToSlash(raw))
+	if path.IsAbs(dir) || filepath.IsAbs(raw) || dir == "." || dir == ".." || strings.HasPrefix(dir, "../") {
+		return "", fmt.Errorf("output_dir must be a directory inside the repository, got %q", raw)
+	}
+	if dir == ".git" || strings.HasPrefix(dir, ".git/") {
+		return "", fmt.Errorf("output_dir must not be inside .git, got %q", raw)
+	}
+	return dir, nil
+}
+
+// listValue accepts a sequence, or a scalar as a one-element list.
+func listValue(key string, value any) ([]string, error) {
+	switch typed := value.(type) {
+	case []string:
+		return typed, nil
+	case string:
+		if typed == "" {
+			return nil, nil
+		}
+		return []string{typed}, nil
+	default:
+		return nil, fmt.Errorf("%s must be a list", key)
+	}
+}
+
+func sortedKeys[V any](values map[string]V) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// FlagDefaults maps command flag names to the values this config supplies
+// for them. Commands apply only the flags they define and the user did not set.
+func (c Config) FlagDefaults() map[string][]string {
+	defaults := make(map[string][]string)
+	add := func(flag string, values ...string) {
+		if len(values) > 0 && values[0] != "" {
+			defaults[flag] = values
+		}
+	}
+	add("format", c.Format)
+	add("order", c.Order)
+	add("lang", c.Languages...)
+	if c.Jobs > 0 {
+		add("jobs", strconv.Itoa(c.Jobs))
+	}
+	add("state-backend", c.StateBackend)
+	add("compress", c.Compress)
+	if c.DisableGitignore {
+		add("no-gitignore", "true")
+	}
+	if len(c.LLM) > 0 {
+		add("llm", strings.Join(c.LLM, ","))
+	}
+	add("exec", c.Hooks.Exec...)
+	add("embed-command", c.Embeddings.Command)
+	add("embed-endpoint", c.Embeddings.Endpoint)
+	add("embed-model", c.Embeddings.Model)
+	return defaults
+}
@@ -0,0 +1,180 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseReadsScalarsListsAndHooks(t *testing.T) {
+	cfg, err := Parse(`# project defaults
+format: jsonl
+order: "path"   # quoted scalars are unquoted
+languages: [go, 'python']
+jobs: 4
+compress: gzip
+skip_generated: true
+skip_build_ignored: false
+max_file_bytes: 2097152
+output_dir: ./docs/context/
+metrics: true
+external: [sdk/**, "*.min.js"]
+ignore:
+  - testdata/
+  - "*.gen.go"
+llm: codex
+roots: [services/*, libs/shared]
+hooks:
+  exec:
+    - 'echo "changed: {changed}"' # comments after items are dropped
+embeddings:
+  endpoint: https://api.openai.com/v1
+  model: text-embedding-3-small
+deadcode:
+  allow: [LegacyClient, internal/compat/**]
+enrich:
+  max_body_bytes: 4000
+  agent: codex
+  max_symbols: 200
+parsers:
+  js:
+    calls: false
+    docs: false
+  python:
+    references: false
+`)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	want := Config{
+		Format:        "jsonl",
+		Order:         "path",
+		Languages:     []string{"go", "python"},
+		Jobs:          4,
+		Compress:      "gzip",
+		SkipGenerated: true,
+		MaxFileBytes:  2097152,
+		OutputDir:     "docs/context",
+		Metrics:       MetricsFile,
+		External:      []string{"sdk/**", "*.min.js"},
+		Ignore:        []string{"testdata/", "*.gen.go"},
+		LLM:           []string{"codex"},
+		Roots:         []string{"services/*", "libs/shared"},
+		Hooks:         Hooks{Exec: []string{`echo "changed: {changed}"`}},
+		Embeddings:    Embeddings{Endpoint: "https://api.openai.com/v1", Model: "text-embedding-3-small"},
+		Deadcode:      Deadcode{Allow: []string{"LegacyClient", "internal/compat/**"}},
+		Enrich:        Enrich{MaxBodyBytes: 4000, Agent: "codex", MaxSymbols: 200},
+		Parsers: map[string]ParserFeatures{
+			"javascript": {SkipCalls: true, SkipDocs: true},
+			"python":     {SkipReferences: true},
+		},
+	}
+	if !reflect.DeepEqual(cfg, want) {
+		t.Fatalf("unexpected config:\n got %#v\nwant %#v", cfg, want)
+	}
+
+	defaults := cfg.FlagDefaults()
+	if !reflect.DeepEqual(defaults["lang"], []string{"go", "python"}) || !reflect.DeepEqual(defaults["jobs"], []string{"4"}) || defaults["state-backend"] != nil || !reflect.DeepEqual(defaults["embed-model"], []string{"text-embedding-3-small"}) {
+		t.Fatalf("unexpected flag defaults: %#v", defaults)
+	}
+}
+
+func TestParseHandlesBlockScalarsFlowMappingsAndAliases(t *testing.T) {
+	cfg, err := Parse(`ignore: &shared
+  - vendor/
+  - "*.pb.go"
+external: *shared
+embeddings: {model: text-embedding-3-small, endpoint: "https://example.test/v1"}
+hooks:
+  exec:
+    - |
+      make docs
+      make lint
+    - >-
+      echo
+      done
+parsers:
+  js: &quiet
+    calls: false
+    docs: false
+  python:
+    <<: *quiet
+    docs: true
+languages: [
+  go,
+  python,
+]
+`)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	want := Config{
+		Languages:  []string{"go", "python"},
+		Ignore:     []string{"vendor/", "*.pb.go"},
+		External:   []string{"vendor/", "*.pb.go"},
+		Hooks:      Hooks{Exec: []string{"make docs\nmake lint\n", "echo done"}},
+		Embeddings: Embeddings{Endpoint: "https://example.test/v1", Model: "text-embedding-3-small"},
+		Parsers: map[string]ParserFeatures{
+			"javascript": {SkipCalls: true, SkipDocs: true},
+			"python":     {SkipCalls: true},
+		},
+	}
+	if !reflect.DeepEqual(cfg, want) {
+		t.Fatalf("unexpected config:\n got %#v\nwant %#v", cfg, want)
+	}
+}
+
+func TestParseRejectsUnknownKeysAndBadValues(t *testing.T) {
+	cases := map[string]string{
+		"output: out\n":                          `unknown key "output"`,
+		"output_dir: ../context\n":               "output_dir must be a directory inside the repository",
+		"output_dir: /tmp/context\n":             "output_dir must be a directory inside the repository",
+		"output_dir: .git/context\n":             "output_dir must not be inside .git",
+		"enrich:\n  max_symbols: some\n":         "enrich.max_symbols must be a non-negative integer",
+		"hooks:\n  pre_commit: x\n":              `unknown key "hooks.pre_commit"`,
+		"embeddings:\n  key: x\n":                `unknown key "embeddings.key"`,
+		"deadcode:\n  ignore: x\n":               `unknown key "deadcode.ignore"`,
+		"parsers:\n  go:\n    types: false\n":    `unknown key "parsers.go.types"`,
+		"parsers:\n  cobol:\n    calls: false\n": `parsers: unsupported language "cobol"`,
+		"parsers:\n  go:\n    calls: no\n":       "parsers.go.calls must be true or false",
+		"jobs: many\n":                           "jobs must be a non-negative integer",
+		"max_file_bytes: 0\n":                    "max_file_bytes must be a positive integer",
+		"enrich:\n  max_body_bytes: -1\n":        "enrich.max_body_bytes must be a non-negative integer",
+		"gitignore: maybe\n":                     "gitignore must be true or false",
+		"skip_generated: yes\n":                  "skip_generated must be true or false",
+		"format: [text, jsonl]\n":                "format must be a single value",
+		"metrics: [a, b]\n":                      "metrics must be a single value",
+		"format: text\nformat: jsonl\n":          "line 2: duplicate key",
+		"ignore:\n\t- vendor/\n":                 "line 2: tabs are not allowed",
+		"format: text\n  order: path\n":          "line 2: mapping values are not allowed",
+		"ignore:\n  - path: vendor\n":            "line 2: mappings in lists are not supported",
+		"ignore:\n  - [a, b]\n":                  "line 2: nested lists are not supported",
+		"format: text\n---\norder: path\n":       "line 2: multiple documents are not supported",
+		"format: [text\n":                        "did not find expected",
+	}
+	for input, want := range cases {
+		if _, err := Parse(input); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Parse(%q): expected error containing %q, got %v", input, want, err)
+		}
+	}
+}
+
+func TestLoadMissingFileIsEmpty(t *testing.T) {
+	root := t.TempDir()
+	cfg, err := Load(root)
+	if err != nil || !reflect.DeepEqual(cfg, Config{}) {
+		t.Fatalf("expected empty config without a file, got %#v, %v", cfg, err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(Path(root)), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(Path(root), []byte("formats: text\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(root); err == nil || !strings.Contains(err.Error(), "invalid .skelly/config.yaml") {
+		t.Fatalf("expected invalid config error, got %v", err)
+	}
+}
@@ -0,0 +1,161 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// parseYAML decodes a config document with a YAML parser and converts it to
+// the shapes Parse expects: mappings decode to map[string]any, sequences of
+// scalars to []string and scalars to string (null to ""). Aliases and merge
+// keys are resolved. Values the config has no use for, such as lists of
+// mappings, nested lists or multiple documents, are rejected with the line
+// they appear on.
+func parseYAML(data string) (map[string]any, error) {
+	if err := checkYAMLIndentation(data); err != nil {
+		return nil, err
+	}
+	decoder := yaml.NewDecoder(strings.NewReader(data))
+	var document yaml.Node
+	if err := decoder.Decode(&document); err != nil {
+		if errors.Is(err, io.EOF) {
+			return map[string]any{}, nil
+		}
+		return nil, yamlError(err)
+	}
+	var extra yaml.Node
+	if err := decoder.Decode(&extra); !errors.Is(err, io.EOF) {
+		if err != nil {
+			return nil, yamlError(err)
+		}
+		return nil, fmt.Errorf("line %d: multiple documents are not supported", extra.Line)
+	}
+	if len(document.Content) == 0 {
+		return map[string]any{}, nil
+	}
+	root := resolveYAMLAlias(document.Content[0])
+	if root.Kind == yaml.ScalarNode && isYAMLNull(root) {
+		return map[string]any{}, nil
+	}
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("line %d: expected \"key: value\" pairs", root.Line)
+	}
+	return yamlMapping(root)
+}
+
+// checkYAMLIndentation reports tab indentation up front: YAML forbids it,
+// and the parser's own message for it does not say so.
+func checkYAMLIndentation(data string) error {
+	for i, line := range strings.Split(data, "\n") {
+		if strings.HasPrefix(strings.TrimLeft(line, " "), "\t") {
+			return fmt.Errorf("line %d: tabs are not allowed for indentation", i+1)
+		}
+	}
+	return nil
+}
+
+// yamlError strips the parser's "yaml: " prefix so errors read like the
+// rest of Parse's ("line 3: ...").
+func yamlError(err error) error {
+	return errors.New(strings.TrimPrefix(err.Error(), "yaml: "))
+}
+
+func yamlMapping(node *yaml.Node) (map[string]any, error) {
+	out := make(map[string]any, len(node.Content)/2)
+	merged := make(map[string]bool)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		keyNode, valueNode := node.Content[i], node.Content[i+1]
+		if keyNode.Kind != yaml.ScalarNode {
+			return nil, fmt.Errorf("line %d: mapping keys must be plain values", keyNode.Line)
+		}
+		if keyNode.ShortTag() == "!!merge" {
+			if err := mergeYAMLMapping(out, merged, valueNode); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		key := keyNode.Value
+		if _, exists := out[key]; exists && !merged[key] {
+			return nil, fmt.Errorf("line %d: duplicate key %q", keyNode.Line, key)
+		}
+		value, err := yamlValue(valueNode)
+		if err != nil {
+			return nil, err
+		}
+		out[key] = value
+		delete(merged, key)
+	}
+	return out, nil
+}
+
+// mergeYAMLMapping applies a "<<: *anchor" merge key. Merged keys fill in
+// what the mapping does not set itself, so explicit keys may override them.
+func mergeYAMLMapping(out map[string]any, merged map[string]bool, node *yaml.Node) error {
+	sources := []*yaml.Node{node}
+	if node.Kind == yaml.SequenceNode {
+		sources = node.Content
+	}
+	for _, source := range sources {
+		source = resolveYAMLAlias(source)
+		if source.Kind != yaml.MappingNode {
+			return fmt.Errorf("line %d: merge keys must refer to mappings", source.Line)
+		}
+		values, err := yamlMapping(source)
+		if err != nil {
+			return err
+		}
+		for key, value := range values {
+			if _, exists := out[key]; !exists {
+				out[key] = value
+				merged[key] = true
+			}
+		}
+	}
+	return nil
+}
+
+func yamlValue(node *yaml.Node) (any, error) {
+	node = resolveYAMLAlias(node)
+	switch node.Kind {
+	case yaml.MappingNode:
+		return yamlMapping(node)
+	case yaml.SequenceNode:
+		out := make([]string, 0, len(node.Content))
+		for _, item := range node.Content {
+			item = resolveYAMLAlias(item)
+			switch item.Kind {
+			case yaml.ScalarNode:
+				out = append(out, yamlScalar(item))
+			case yaml.MappingNode:
+				return nil, fmt.Errorf("line %d: mappings in lists are not supported", item.Line)
+			default:
+				return nil, fmt.Errorf("line %d: nested lists are not supported", item.Line)
+			}
+		}
+		return out, nil
+	default:
+		return yamlScalar(node), nil
+	}
+}
+
+func yamlScalar(node *yaml.Node) string {
+	if isYAMLNull(node) {
+		return ""
+	}
+	return node.Value
+}
+
+func isYAMLNull(node *yaml.Node) bool {
+	return node.ShortTag() == "!!null"
+}
+
+func resolveYAMLAlias(node *yaml.Node) *yaml.Node {
+	for node.Kind == yaml.AliasNode && node.Alias != nil {
+		node = node.Alias
+	}
+	return node
+}
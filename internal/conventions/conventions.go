@@ -0,0 +1,621 @@
+package conventions
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/morozRed/skelly/internal/parser"
+	"github.com/morozRed/skelly/internal/state"
+)
+
+// File is the conventions document written under .skelly/ for agent adapters.
+const File = "conventions.md"
+
+const (
+	notesStart = "<!-- skelly:conventions:notes:start -->"
+	notesEnd   = "<!-- skelly:conventions:notes:end -->"
+
+	maxDirectories = 20
+	maxPrefixes    = 5
+)
+
+// Report captures conventions observed in the indexed codebase.
+type Report struct {
+	Files         int             `json:"files"`
+	Symbols       int             `json:"symbols"`
+	Naming        []NamingStat    `json:"naming"`
+	Directories   []DirectoryRole `json:"directories"`
+	ErrorHandling []ErrorIdiom    `json:"error_handling"`
+	Tests         []TestLayout    `json:"tests"`
+	Notes         []string        `json:"notes,omitempty"`
+}
+
+// NamingStat summarizes identifier styles for one language and category.
+type NamingStat struct {
+	Language string       `json:"language"`
+	Category string       `json:"category"`
+	Total    int          `json:"total"`
+	Styles   []StyleShare `json:"styles"`
+	Prefixes []string     `json:"prefixes,omitempty"`
+}
+
+// StyleShare is the fraction of names in a category that use a style.
+type StyleShare struct {
+	Style string  `json:"style"`
+	Count int     `json:"count"`
+	Share float64 `json:"share"`
+}
+
+// DirectoryRole describes how a directory participates in the dependency graph.
+type DirectoryRole struct {
+	Dir        string   `json:"dir"`
+	Role       string   `json:"role"`
+	Files      int      `json:"files"`
+	Symbols    int      `json:"symbols"`
+	UsedBy     int      `json:"used_by"`
+	DependsOn  int      `json:"depends_on"`
+	Languages  []string `json:"languages"`
+	TestFiles  int      `json:"test_files,omitempty"`
+	Entrypoint bool     `json:"entrypoint,omitempty"`
+}
+
+// ErrorIdiom counts occurrences of an error-handling idiom for one language.
+type ErrorIdiom struct {
+	Language string `json:"language"`
+	Idiom    string `json:"idiom"`
+	Count    int    `json:"count"`
+	Files    int    `json:"files"`
+}
+
+// TestLayout describes where tests live for one language.
+type TestLayout struct {
+	Language  string   `json:"language"`
+	TestFiles int      `json:"test_files"`
+	Colocated int      `json:"colocated"`
+	TestDirs  []string `json:"test_dirs,omitempty"`
+	Pattern   string   `json:"pattern,omitempty"`
+}
+
+type idiomPattern struct {
+	idiom string
+	re    *regexp.Regexp
+}
+
+var errorIdioms = map[string][]idiomPattern{
+	"go": {
+		{"`err != nil` checks", regexp.MustCompile(`\berr != nil`)},
+		{"fmt.Errorf wrapping with %w", regexp.MustCompile(`fmt\.Errorf\([^\n]*%w`)},
+		{"errors.New sentinels", regexp.MustCompile(`errors\.New\(`)},
+		{"errors.Is/errors.As checks", regexp.MustCompile(`errors\.(Is|As)\(`)},
+		{"panic", regexp.MustCompile(`\bpanic\(`)},
+	},
+	"python": {
+		{"raise", regexp.MustCompile(`\braise\b`)},
+		{"typed except", regexp.MustCompile(`\bexcept\s+[A-Za-z(]`)},
+		{"bare except", regexp.MustCompile(`\bexcept\s*:`)},
+	},
+	"typescript": {
+		{"throw new Error", regexp.MustCompile(`throw new \w*Error\b`)},
+		{"try/catch", regexp.MustCompile(`\bcatch\s*[({]`)},
+		{"promise .catch", regexp.MustCompile(`\.catch\(`)},
+	},
+	"javascript": {
+		{"throw new Error", regexp.MustCompile(`throw new \w*Error\b`)},
+		{"try/catch", regexp.MustCompile(`\bcatch\s*[({]`)},
+		{"promise .catch", regexp.MustCompile(`\.catch\(`)},
+	},
+	"ruby": {
+		{"raise", regexp.MustCompile(`\braise\b`)},
+		{"rescue", regexp.MustCompile(`\brescue\b`)},
+	},
+	"rust": {
+		{"? propagation", regexp.MustCompile(`\)\?`)},
+		{"Result return types", regexp.MustCompile(`->\s*(\w+::)*Result<`)},
+		{"unwrap", regexp.MustCompile(`\.unwrap\(\)`)},
+		{"expect", regexp.MustCompile(`\.expect\(`)},
+	},
+	"java": {
+		{"throw new", regexp.MustCompile(`throw new \w+`)},
+		{"try/catch", regexp.MustCompile(`\bcatch\s*\(`)},
+		{"checked exceptions (throws)", regexp.MustCompile(`\)\s*throws\s+\w+`)},
+	},
+}
+
+// Analyze derives conventions from indexed state. Error-handling idioms are
+// counted from source files under rootPath; unreadable files are skipped.
+func Analyze(rootPath string, st *state.State) Report {
+	files := make([]string, 0, len(st.Files))
+	for file := range st.Files {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	report := Report{Files: len(files)}
+	for _, file := range files {
+		report.Symbols += len(st.Files[file].Symbols)
+	}
+
+	report.Naming = analyzeNaming(files, st)
+	report.Directories = analyzeDirectories(files, st)
+	report.ErrorHandling = analyzeErrorHandling(rootPath, files, st)
+	report.Tests = analyzeTests(files, st)
+	return report
+}
+
+func analyzeNaming(files []string, st *state.State) []NamingStat {
+	type bucket struct {
+		styles   map[string]int
+		prefixes map[string]int
+		display  map[string]string
+		total    int
+	}
+	buckets := make(map[string]*bucket)
+	add := func(language, category, name string, trackPrefix bool) {
+		if name == "" || language == "" {
+			return
+		}
+		key := language + "\x00" + category
+		b := buckets[key]
+		if b == nil {
+			b = &bucket{styles: make(map[string]int), prefixes: make(map[string]int), display: make(map[string]string)}
+			buckets[key] = b
+		}
+		b.total++
+		b.styles[ClassifyCase(name)]++
+		if trackPrefix {
+			if word := firstWord(name); word != "" && word != name {
+				lower := strings.ToLower(word)
+				b.prefixes[lower]++
+				if _, ok := b.display[lower]; !ok {
+					b.display[lower] = word
+				}
+			}
+		}
+	}
+
+	for _, file := range files {
+		fileState := st.Files[file]
+		if parser.IsTestFile(fileState.Language, file) {
+			continue
+		}
+		base := path.Base(filepath.ToSlash(file))
+		add(fileState.Language, "files", strings.TrimSuffix(base, path.Ext(base)), false)
+		for _, symbol := range fileState.Symbols {
+			switch symbol.Kind {
+			case parser.SymbolFunction, parser.SymbolMethod:
+				add(fileState.Language, "functions", symbol.Name, true)
+			case parser.SymbolClass, parser.SymbolStruct, parser.SymbolInterface, parser.SymbolComponent:
+				add(fileState.Language, "types", symbol.Name, false)
+			}
+		}
+	}
+
+	stats := make([]NamingStat, 0, len(buckets))
+	for key, b := range buckets {
+		parts := strings.SplitN(key, "\x00", 2)
+		stat := NamingStat{Language: parts[0], Category: parts[1], Total: b.total}
+		for style, count := range b.styles {
+			stat.Styles = append(stat.Styles, StyleShare{Style: style, Count: count, Share: float64(count) / float64(b.total)})
+		}
+		sort.Slice(stat.Styles, func(i, j int) bool {
+			if stat.Styles[i].Count == stat.Styles[j].Count {
+				return stat.Styles[i].Style < stat.Styles[j].Style
+			}
+			return stat.Styles[i].Count > stat.Styles[j].Count
+		})
+		stat.Prefixes = topPrefixes(b.prefixes, b.display)
+		stats = append(stats, stat)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Language == stats[j].Language {
+			return categoryRank(stats[i].Category) < categoryRank(stats[j].Category)
+		}
+		return stats[i].Language < stats[j].Language
+	})
+	return stats
+}
+
+func topPrefixes(counts map[string]int, display map[string]string) []string {
+	type prefixCount struct {
+		word  string
+		count int
+	}
+	candidates := make([]prefixCount, 0, len(counts))
+	for word, count := range counts {
+		// Only prefixes repeated across several names say anything about convention.
+		if count >= 3 {
+			candidates = append(candidates, prefixCount{word: word, count: count})
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].count == candidates[j].count {
+			return candidates[i].word < candidates[j].word
+		}
+		return candidates[i].count > candidates[j].count
+	})
+	if len(candidates) > maxPrefixes {
+		candidates = candidates[:maxPrefixes]
+	}
+	out := make([]string, 0, len(candidates))
+	for _, candidate := range candidates {
+		out = append(out, fmt.Sprintf("%s (%d)", display[candidate.word], candidate.count))
+	}
+	return out
+}
+
+func categoryRank(category string) int {
+	switch category {
+	case "functions":
+		return 0
+	case "types":
+		return 1
+	default:
+		return 2
+	}
+}
+
+// ClassifyCase reports the identifier style of name: camelCase, PascalCase,
+// snake_case, SCREAMING_SNAKE, kebab-case, or lowercase for single words.
+func ClassifyCase(name string) string {
+	name = strings.Trim(name, "_")
+	if name == "" {
+		return "lowercase"
+	}
+	hasUpper := strings.IndexFunc(name, unicode.IsUpper) != -1
+	hasLower := strings.IndexFunc(name, unicode.IsLower) != -1
+	switch {
+	case strings.Contains(name, "-"):
+		return "kebab-case"
+	case strings.Contains(name, "_") && !hasLower:
+		return "SCREAMING_SNAKE"
+	case strings.Contains(name, "_"):
+		return "snake_case"
+	case !hasUpper:
+		return "lowercase"
+	case unicode.IsUpper(rune(name[0])):
+		return "PascalCase"
+	default:
+		return "camelCase"
+	}
+}
+
+func firstWord(name string) string {
+	name = strings.TrimLeft(name, "_")
+	for i, r := range name {
+		if i == 0 {
+			continue
+		}
+		if r == '_' || r == '-' || unicode.IsUpper(r) {
+			return name[:i]
+		}
+	}
+	return name
+}
+
+func analyzeDirectories(files []string, st *state.State) []DirectoryRole {
+	roles := make(map[string]*DirectoryRole)
+	languages := make(map[string]map[string]bool)
+	usedBy := make(map[string]map[string]bool)
+	dependsOn := make(map[string]map[string]bool)
+
+	for _, file := range files {
+		fileState := st.Files[file]
+		dir := fileDir(file)
+		role := roles[dir]
+		if role == nil {
+			role = &DirectoryRole{Dir: dir}
+			roles[dir] = role
+			languages[dir] = make(map[string]bool)
+			usedBy[dir] = make(map[string]bool)
+			dependsOn[dir] = make(map[string]bool)
+		}
+		role.Files++
+		role.Symbols += len(fileState.Symbols)
+		if fileState.Language != "" {
+			languages[dir][fileState.Language] = true
+		}
+		if parser.IsTestFile(fileState.Language, file) {
+			role.TestFiles++
+		}
+		if isEntrypoint(file, fileState) {
+			role.Entrypoint = true
+		}
+	}
+
+	for _, file := range files {
+		dir := fileDir(file)
+		for _, dep := range st.Files[file].Dependencies {
+			depDir := fileDir(dep)
+			if depDir == dir || roles[depDir] == nil {
+				continue
+			}
+			dependsOn[dir][depDir] = true
+			usedBy[depDir][dir] = true
+		}
+	}
+
+	out := make([]DirectoryRole, 0, len(roles))
+	for dir, role := range roles {
+		role.UsedBy = len(usedBy[dir])
+		role.DependsOn = len(dependsOn[dir])
+		for language := range languages[dir] {
+			role.Languages = append(role.Languages, language)
+		}
+		sort.Strings(role.Languages)
+		role.Role = classifyDirectory(*role)
+		out = append(out, *role)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].UsedBy == out[j].UsedBy {
+			if out[i].Files == out[j].Files {
+				return out[i].Dir < out[j].Dir
+			}
+			return out[i].Files > out[j].Files
+		}
+		return out[i].UsedBy > out[j].UsedBy
+	})
+	if len(out) > maxDirectories {
+		out = out[:maxDirectories]
+	}
+	return out
+}
+
+func classifyDirectory(role DirectoryRole) string {
+	switch {
+	case role.TestFiles == role.Files:
+		return "tests"
+	case role.Entrypoint:
+		return "entrypoint"
+	case role.UsedBy == 0 && role.DependsOn == 0:
+		return "isolated"
+	case role.DependsOn == 0:
+		return "foundation"
+	case role.UsedBy >= 2*role.DependsOn:
+		return "core library"
+	case role.DependsOn >= 2*role.UsedBy:
+		return "orchestration"
+	default:
+		return "mixed"
+	}
+}
+
+func isEntrypoint(file string, fileState state.FileState) bool {
+	slashed := filepath.ToSlash(file)
+	if strings.HasPrefix(slashed, "cmd/") || strings.Contains(slashed, "/cmd/") {
+		return true
+	}
+	for _, symbol := range fileState.Symbols {
+		if symbol.Name == "main" && symbol.Kind == parser.SymbolFunction {
+			return true
+		}
+	}
+	return false
+}
+
+func analyzeErrorHandling(rootPath string, files []string, st *state.State) []ErrorIdiom {
+	type tally struct {
+		count int
+		files int
+	}
+	counts := make(map[string]map[string]*tally)
+	customErrors := make(map[string]int)
+
+	for _, file := range files {
+		fileState := st.Files[file]
+		for _, symbol := range fileState.Symbols {
+			switch symbol.Kind {
+			case parser.SymbolClass, parser.SymbolStruct:
+				if strings.HasSuffix(symbol.Name, "Error") || strings.HasSuffix(symbol.Name, "Exception") {
+					customErrors[fileState.Language]++
+				}
+			}
+		}
+
+		patterns := errorIdioms[fileState.Language]
+		if len(patterns) == 0 {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(rootPath, file))
+		if err != nil {
+			continue
+		}
+		for _, pattern := range patterns {
+			matches := len(pattern.re.FindAllIndex(content, -1))
+			if matches == 0 {
+				continue
+			}
+			if counts[fileState.Language] == nil {
+				counts[fileState.Language] = make(map[string]*tally)
+			}
+			entry := counts[fileState.Language][pattern.idiom]
+			if entry == nil {
+				entry = &tally{}
+				counts[fileState.Language][pattern.idiom] = entry
+			}
+			entry.count += matches
+			entry.files++
+		}
+	}
+
+	out := make([]ErrorIdiom, 0)
+	for language, idioms := range counts {
+		for idiom, entry := range idioms {
+			out = append(out, ErrorIdiom{Language: language, Idiom: idiom, Count: entry.count, Files: entry.files})
+		}
+	}
+	for language, count := range customErrors {
+		out = append(out, ErrorIdiom{Language: language, Idiom: "custom error types", Count: count})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Language == out[j].Language {
+			if out[i].Count == out[j].Count {
+				return out[i].Idiom < out[j].Idiom
+			}
+			return out[i].Count > out[j].Count
+		}
+		return out[i].Language < out[j].Language
+	})
+	return out
+}
+
+func analyzeTests(files []string, st *state.State) []TestLayout {
+	sourceDirs := make(map[string]map[string]bool)
+	testFiles := make(map[string][]string)
+	for _, file := range files {
+		language := st.Files[file].Language
+		if parser.IsTestFile(language, file) {
+			testFiles[language] = append(testFiles[language], file)
+			continue
+		}
+		if sourceDirs[language] == nil {
+			sourceDirs[language] = make(map[string]bool)
+		}
+		sourceDirs[language][fileDir(file)] = true
+	}
+
+	out := make([]TestLayout, 0, len(testFiles))
+	for language, tests := range testFiles {
+		layout := TestLayout{Language: language, TestFiles: len(tests)}
+		dirs := make(map[string]bool)
+		patterns := make(map[string]int)
+		for _, file := range tests {
+			patterns[parser.TestPattern(language, file)]++
+			if sourceDirs[language][fileDir(file)] {
+				layout.Colocated++
+				continue
+			}
+			dirs[testRootDir(file)] = true
+		}
+		for dir := range dirs {
+			layout.TestDirs = append(layout.TestDirs, dir)
+		}
+		sort.Strings(layout.TestDirs)
+		bestCount := 0
+		for pattern, count := range patterns {
+			if count > bestCount || (count == bestCount && pattern < layout.Pattern) {
+				layout.Pattern = pattern
+				bestCount = count
+			}
+		}
+		out = append(out, layout)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Language < out[j].Language
+	})
+	return out
+}
+
+func testRootDir(file string) string {
+	parts := strings.Split(fileDir(file), "/")
+	for i, part := range parts {
+		switch part {
+		case "test", "tests", "spec", "__tests__":
+			return strings.Join(parts[:i+1], "/")
+		}
+	}
+	return fileDir(file)
+}
+
+func fileDir(file string) string {
+	return path.Dir(filepath.ToSlash(file))
+}
+
+// Render builds the conventions markdown, appending agent notes in a section
+// that survives regeneration.
+func Render(report Report) string {
+	var sb strings.Builder
+	sb.WriteString("# Project Conventions\n\n")
+	fmt.Fprintf(&sb, "Observed by `skelly conventions` from %d files and %d symbols. ", report.Files, report.Symbols)
+	sb.WriteString("Follow these when adding code; regenerate after large refactors.\n")
+
+	sb.WriteString("\n## Naming\n\n")
+	if len(report.Naming) == 0 {
+		sb.WriteString("- (no symbols indexed)\n")
+	}
+	for _, stat := range report.Naming {
+		styles := make([]string, 0, 2)
+		for i, style := range stat.Styles {
+			if i == 2 {
+				break
+			}
+			styles = append(styles, fmt.Sprintf("%s %.0f%%", style.Style, style.Share*100))
+		}
+		fmt.Fprintf(&sb, "- %s %s: %s (%d names)", stat.Language, stat.Category, strings.Join(styles, ", "), stat.Total)
+		if len(stat.Prefixes) > 0 {
+			fmt.Fprintf(&sb, "; common prefixes: %s", strings.Join(stat.Prefixes, ", "))
+		}
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString("\n## Directory Roles\n\n")
+	if len(report.Directories) == 0 {
+		sb.WriteString("- (no directories indexed)\n")
+	}
+	for _, dir := range report.Directories {
+		fmt.Fprintf(&sb, "- `%s` — %s (%d files, %d symbols, used by %d dirs, depends on %d dirs; %s)\n",
+			dir.Dir, dir.Role, dir.Files, dir.Symbols, dir.UsedBy, dir.DependsOn, strings.Join(dir.Languages, ", "))
+	}
+
+	sb.WriteString("\n## Error Handling\n\n")
+	if len(report.ErrorHandling) == 0 {
+		sb.WriteString("- (no error-handling idioms detected)\n")
+	}
+	byLanguage := make(map[string][]string)
+	languageOrder := make([]string, 0)
+	for _, idiom := range report.ErrorHandling {
+		if _, ok := byLanguage[idiom.Language]; !ok {
+			languageOrder = append(languageOrder, idiom.Language)
+		}
+		entry := fmt.Sprintf("%s (%d)", idiom.Idiom, idiom.Count)
+		if idiom.Files > 0 {
+			entry = fmt.Sprintf("%s (%d in %d files)", idiom.Idiom, idiom.Count, idiom.Files)
+		}
+		byLanguage[idiom.Language] = append(byLanguage[idiom.Language], entry)
+	}
+	for _, language := range languageOrder {
+		fmt.Fprintf(&sb, "- %s: %s\n", language, strings.Join(byLanguage[language], ", "))
+	}
+
+	sb.WriteString("\n## Test Layout\n\n")
+	if len(report.Tests) == 0 {
+		sb.WriteString("- (no test files indexed)\n")
+	}
+	for _, layout := range report.Tests {
+		placement := fmt.Sprintf("%d colocated with sources", layout.Colocated)
+		if len(layout.TestDirs) > 0 {
+			placement += fmt.Sprintf(", %d in %s", layout.TestFiles-layout.Colocated, strings.Join(layout.TestDirs, ", "))
+		}
+		fmt.Fprintf(&sb, "- %s: %d test files named `%s`; %s\n", layout.Language, layout.TestFiles, layout.Pattern, placement)
+	}
+
+	sb.WriteString("\n## Agent Notes\n\n")
+	sb.WriteString(notesStart + "\n")
+	for _, note := range report.Notes {
+		sb.WriteString("- " + note + "\n")
+	}
+	sb.WriteString(notesEnd + "\n")
+	return sb.String()
+}
+
+// ReadNotes returns agent notes preserved in an existing conventions document.
+func ReadNotes(document string) []string {
+	start := strings.Index(document, notesStart)
+	end := strings.Index(document, notesEnd)
+	if start == -1 || end == -1 || end < start {
+		return nil
+	}
+	notes := make([]string, 0)
+	for _, line := range strings.Split(document[start+len(notesStart):end], "\n") {
+		line = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "- "))
+		if line != "" {
+			notes = append(notes, line)
+		}
+	}
+	return notes
+}
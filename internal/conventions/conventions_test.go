@@ -0,0 +1,28 @@
+package conventions
+
+import "testing"
+
+func TestClassifyCase(t *testing.T) {
+	for name, want := range map[string]string{
+		"parseFile":      "camelCase",
+		"ParseFile":      "PascalCase",
+		"parse_file":     "snake_case",
+		"MAX_DEPTH":      "SCREAMING_SNAKE",
+		"user-store":     "kebab-case",
+		"parse":          "lowercase",
+		"__init__":       "lowercase",
+		"_private_thing": "snake_case",
+	} {
+		if got := ClassifyCase(name); got != want {
+			t.Fatalf("ClassifyCase(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestReadNotesRoundTripsRenderedNotes(t *testing.T) {
+	document := Render(Report{Notes: []string{"Prefer table-driven tests.", "No globals."}})
+	notes := ReadNotes(document)
+	if len(notes) != 2 || notes[0] != "Prefer table-driven tests." || notes[1] != "No globals." {
+		t.Fatalf("unexpected notes: %#v", notes)
+	}
+}
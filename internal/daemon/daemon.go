@@ -0,0 +1,244 @@
+// Package daemon serves CLI commands from a long-running process over a unix
+// socket, so the indexes it keeps in memory are decoded once instead of on
+// every invocation.
+package daemon
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	// SocketFile is the daemon's socket in the context dir.
+	SocketFile = "daemon.sock"
+	// LogFile receives the output of a daemon started in the background.
+	LogFile = "daemon.log"
+
+	// maxSocketPath leaves room under the smallest sun_path limit (104 bytes
+	// on macOS and the BSDs).
+	maxSocketPath = 100
+	dialTimeout   = 500 * time.Millisecond
+)
+
+// Request operations.
+const (
+	OpRun    = "run"
+	OpStatus = "status"
+	OpStop   = "stop"
+)
+
+// Request is one message from a client. Run requests carry the CLI
+// arguments and the directory they were given in.
+type Request struct {
+	Op   string   `json:"op"`
+	Args []string `json:"args,omitempty"`
+	Dir  string   `json:"dir,omitempty"`
+}
+
+// Response answers a request. Run responses carry the command's output and
+// exit code; status responses describe the daemon.
+type Response struct {
+	Stdout   string `json:"stdout,omitempty"`
+	Stderr   string `json:"stderr,omitempty"`
+	ExitCode int    `json:"exit_code"`
+	Error    string `json:"error,omitempty"`
+	Status   Status `json:"status"`
+}
+
+// Status describes a running daemon.
+type Status struct {
+	PID       int    `json:"pid"`
+	Root      string `json:"root"`
+	StartedAt string `json:"started_at"`
+	Served    int    `json:"served"`
+}
+
+// Runner executes CLI arguments in the daemon process, writing to os.Stdout
+// and os.Stderr like a normal invocation.
+type Runner func(args []string) error
+
+// Allow returns an error for CLI arguments the daemon must not run. Clients
+// choose which commands to delegate, but any process that can reach the
+// socket may send a request, so the server checks again before running.
+type Allow func(args []string) error
+
+// SocketPath returns the socket of the daemon serving contextDir. Paths too
+// long for a unix socket move to the temp dir under a name derived from
+// contextDir, so clients and the daemon agree on it.
+func SocketPath(contextDir string) string {
+	path := filepath.Join(contextDir, SocketFile)
+	if len(path) <= maxSocketPath {
+		return path
+	}
+	sum := sha256.Sum256([]byte(contextDir))
+	return filepath.Join(os.TempDir(), "skelly-"+hex.EncodeToString(sum[:8])+".sock")
+}
+
+// Call sends one request to the daemon serving contextDir. It returns
+// ErrNotRunning when no daemon answers.
+func Call(contextDir string, request Request) (Response, error) {
+	conn, err := net.DialTimeout("unix", SocketPath(contextDir), dialTimeout)
+	if err != nil {
+		return Response{}, ErrNotRunning
+	}
+	defer conn.Close()
+	if err := json.NewEncoder(conn).Encode(request); err != nil {
+		return Response{}, fmt.Errorf("failed to send daemon request: %w", err)
+	}
+	var response Response
+	if err := json.NewDecoder(conn).Decode(&response); err != nil {
+		return Response{}, fmt.Errorf("failed to read daemon response: %w", err)
+	}
+	if response.Error != "" {
+		return response, errors.New(response.Error)
+	}
+	return response, nil
+}
+
+// ErrNotRunning reports that no daemon listens on the socket.
+var ErrNotRunning = errors.New("no skelly daemon is running (start one with skelly daemon start)")
+
+// Server runs commands for one repository, one at a time, since commands
+// write to the process-wide os.Stdout and os.Stderr.
+type Server struct {
+	rootPath   string
+	contextDir string
+	allow      Allow
+	run        Runner
+	started    time.Time
+
+	mu     sync.Mutex
+	served int
+}
+
+// NewServer returns a daemon for the repository at rootPath that runs the
+// commands allow accepts; a nil allow refuses every command.
+func NewServer(rootPath, contextDir string, allow Allow, run Runner) *Server {
+	return &Server{rootPath: rootPath, contextDir: contextDir, allow: allow, run: run, started: time.Now()}
+}
+
+// Serve listens on the socket until ctx is done or a stop request arrives,
+// and removes the socket on return. The socket is accessible to its owner
+// only. A socket left by a crashed daemon is replaced; a live one is an error.
+func (s *Server) Serve(ctx context.Context) error {
+	path := SocketPath(s.contextDir)
+	if _, err := Call(s.contextDir, Request{Op: OpStatus}); err == nil {
+		return fmt.Errorf("a skelly daemon is already running on %s", path)
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove stale daemon socket: %w", err)
+	}
+	listener, err := listen(path)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", path, err)
+	}
+	defer os.Remove(path)
+
+	ctx, stop := context.WithCancel(ctx)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		_ = listener.Close()
+	}()
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("daemon accept failed: %w", err)
+		}
+		s.handle(conn, stop)
+	}
+}
+
+func (s *Server) handle(conn net.Conn, stop context.CancelFunc) {
+	defer conn.Close()
+	var request Request
+	response := Response{}
+	if err := json.NewDecoder(conn).Decode(&request); err != nil {
+		response.Error = fmt.Sprintf("invalid daemon request: %v", err)
+	} else {
+		switch request.Op {
+		case OpRun:
+			if filepath.Clean(request.Dir) != s.rootPath {
+				response.Error = fmt.Sprintf("daemon serves %s, not %s", s.rootPath, request.Dir)
+				break
+			}
+			if s.allow == nil {
+				response.Error = "daemon runs no commands"
+				break
+			}
+			if err := s.allow(request.Args); err != nil {
+				response.Error = err.Error()
+				break
+			}
+			response = s.runCommand(request.Args)
+		case OpStatus:
+		case OpStop:
+			defer stop()
+		default:
+			response.Error = fmt.Sprintf("unknown daemon operation %q", request.Op)
+		}
+	}
+	s.mu.Lock()
+	response.Status = Status{PID: os.Getpid(), Root: s.rootPath, StartedAt: s.started.UTC().Format(time.RFC3339), Served: s.served}
+	s.mu.Unlock()
+	_ = json.NewEncoder(conn).Encode(response)
+}
+
+func (s *Server) runCommand(args []string) Response {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.served++
+
+	response := Response{}
+	stdout, stderr, err := capture(func() error { return s.run(args) })
+	if err != nil {
+		response.ExitCode = 1
+	}
+	response.Stdout, response.Stderr = stdout, stderr
+	return response
+}
+
+// capture runs fn with os.Stdout and os.Stderr redirected into buffers.
+func capture(fn func() error) (string, string, error) {
+	stdoutReader, stdoutWriter, err := os.Pipe()
+	if err != nil {
+		return "", fmt.Sprintf("daemon failed to capture output: %v\n", err), err
+	}
+	stderrReader, stderrWriter, err := os.Pipe()
+	if err != nil {
+		stdoutReader.Close()
+		stdoutWriter.Close()
+		return "", fmt.Sprintf("daemon failed to capture output: %v\n", err), err
+	}
+	var stdout, stderr bytes.Buffer
+	var copies sync.WaitGroup
+	copies.Add(2)
+	go func() { defer copies.Done(); _, _ = io.Copy(&stdout, stdoutReader) }()
+	go func() { defer copies.Done(); _, _ = io.Copy(&stderr, stderrReader) }()
+
+	originalStdout, originalStderr := os.Stdout, os.Stderr
+	os.Stdout, os.Stderr = stdoutWriter, stderrWriter
+	runErr := fn()
+	os.Stdout, os.Stderr = originalStdout, originalStderr
+
+	stdoutWriter.Close()
+	stderrWriter.Close()
+	copies.Wait()
+	stdoutReader.Close()
+	stderrReader.Close()
+	return stdout.String(), stderr.String(), runErr
+}
@@ -0,0 +1,113 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestServerRunsCommandsAndStops(t *testing.T) {
+	root := t.TempDir()
+	contextDir := filepath.Join(root, ".skelly", ".context")
+	if err := os.MkdirAll(contextDir, 0755); err != nil {
+		t.Fatalf("failed to create context dir: %v", err)
+	}
+	allow := func(args []string) error {
+		if args[0] == "watch" {
+			return errors.New("watch is not served")
+		}
+		return nil
+	}
+	server := NewServer(root, contextDir, allow, func(args []string) error {
+		fmt.Printf("ran %s\n", strings.Join(args, " "))
+		if args[0] == "fail" {
+			fmt.Fprintln(os.Stderr, "Error: failed")
+			return errors.New("failed")
+		}
+		return nil
+	})
+	done := make(chan error, 1)
+	go func() { done <- server.Serve(context.Background()) }()
+	waitForDaemon(t, contextDir)
+
+	if info, err := os.Stat(SocketPath(contextDir)); err != nil || info.Mode().Perm() != 0600 {
+		t.Fatalf("expected an owner-only socket, got %v (%v)", info.Mode(), err)
+	}
+	if err := NewServer(root, contextDir, nil, nil).Serve(context.Background()); err == nil || !strings.Contains(err.Error(), "already running") {
+		t.Fatalf("expected a second daemon to be refused, got %v", err)
+	}
+
+	response, err := Call(contextDir, Request{Op: OpRun, Args: []string{"callers", "Save"}, Dir: root})
+	if err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	if response.Stdout != "ran callers Save\n" || response.ExitCode != 0 {
+		t.Fatalf("unexpected run response: %+v", response)
+	}
+	response, err = Call(contextDir, Request{Op: OpRun, Args: []string{"fail"}, Dir: root})
+	if err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	if response.ExitCode != 1 || response.Stderr != "Error: failed\n" {
+		t.Fatalf("expected the failure on stderr with exit code 1, got %+v", response)
+	}
+	if _, err := Call(contextDir, Request{Op: OpRun, Args: []string{"callers"}, Dir: t.TempDir()}); err == nil {
+		t.Fatalf("expected a run from another directory to be refused")
+	}
+	if response, err := Call(contextDir, Request{Op: OpRun, Args: []string{"watch"}, Dir: root}); err == nil || response.Stdout != "" {
+		t.Fatalf("expected a disallowed command to be refused without running, got %+v (%v)", response, err)
+	}
+
+	response, err = Call(contextDir, Request{Op: OpStop})
+	if err != nil {
+		t.Fatalf("stop failed: %v", err)
+	}
+	if response.Status.Served != 2 || response.Status.PID != os.Getpid() {
+		t.Fatalf("unexpected status: %+v", response.Status)
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Serve failed: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("daemon did not stop")
+	}
+	if _, err := Call(contextDir, Request{Op: OpStatus}); !errors.Is(err, ErrNotRunning) {
+		t.Fatalf("expected no daemon after stop, got %v", err)
+	}
+	if _, err := os.Stat(SocketPath(contextDir)); !os.IsNotExist(err) {
+		t.Fatalf("expected the socket to be removed, got %v", err)
+	}
+}
+
+func TestSocketPathMovesLongPathsToTempDir(t *testing.T) {
+	short := "/repo/.skelly/.context"
+	if got := SocketPath(short); got != "/repo/.skelly/.context/daemon.sock" {
+		t.Fatalf("expected the socket in the context dir, got %s", got)
+	}
+	long := "/" + strings.Repeat("nested/", 20) + ".skelly/.context"
+	got := SocketPath(long)
+	if filepath.Dir(got) != filepath.Clean(os.TempDir()) || got != SocketPath(long) {
+		t.Fatalf("expected a stable temp-dir socket, got %s", got)
+	}
+}
+
+func waitForDaemon(t *testing.T, contextDir string) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, err := Call(contextDir, Request{Op: OpStatus}); err == nil {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("daemon did not start")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
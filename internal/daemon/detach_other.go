@@ -0,0 +1,8 @@
+//go:build !unix
+
+package daemon
+
+import "os/exec"
+
+// Detach is a no-op where processes have no sessions to leave.
+func Detach(cmd *exec.Cmd) {}
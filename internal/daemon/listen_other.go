@@ -0,0 +1,21 @@
+//go:build !unix
+
+package daemon
+
+import (
+	"net"
+	"os"
+)
+
+// listen creates the socket at path and restricts it to its owner.
+func listen(path string) (net.Listener, error) {
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		_ = listener.Close()
+		return nil, err
+	}
+	return listener, nil
+}
@@ -0,0 +1,17 @@
+//go:build unix
+
+package daemon
+
+import (
+	"net"
+	"syscall"
+)
+
+// listen creates the socket at path readable and writable by its owner only.
+// The umask is tightened while the socket is created, so it is never open to
+// other users, not even briefly.
+func listen(path string) (net.Listener, error) {
+	previous := syscall.Umask(0177)
+	defer syscall.Umask(previous)
+	return net.Listen("unix", path)
+}
@@ -0,0 +1,363 @@
+// Package deadcode finds public symbols that nothing in the graph uses: no
+// calls, references, renders or other edges point at them, from production
+// code or tests, and no language convention marks them as an entry point.
+package deadcode
+
+import (
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/morozRed/skelly/internal/graph"
+	"github.com/morozRed/skelly/internal/ignore"
+	"github.com/morozRed/skelly/internal/output"
+	"github.com/morozRed/skelly/internal/parser"
+)
+
+// Symbol is one unused symbol in a report.
+type Symbol struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Kind       string `json:"kind"`
+	Signature  string `json:"signature,omitempty"`
+	File       string `json:"file"`
+	Line       int    `json:"line"`
+	Language   string `json:"language"`
+	Visibility string `json:"visibility"`
+}
+
+// Report lists the unused symbols, sorted by file and line, with counts of
+// the unused public symbols kept as entry points or by the allowlist.
+type Report struct {
+	Checked     int      `json:"checked"`
+	EntryPoints int      `json:"entry_points"`
+	Allowlisted int      `json:"allowlisted"`
+	Symbols     []Symbol `json:"symbols"`
+}
+
+// Allowlist keeps symbols out of the report. An entry matches a symbol ID, a
+// name or qualified name (User.save), a glob over either (Legacy*), or, when
+// it contains a "/", a gitignore-style pattern over file paths
+// (internal/compat/**).
+type Allowlist struct {
+	names []string
+	paths []ignore.Pattern
+}
+
+// NewAllowlist parses allowlist entries; blank entries are skipped.
+func NewAllowlist(entries []string) Allowlist {
+	var allow Allowlist
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if strings.Contains(entry, "/") && !strings.Contains(entry, "|") {
+			if pattern, ok := ignore.ParsePattern(strings.TrimPrefix(entry, "./")); ok {
+				allow.paths = append(allow.paths, pattern)
+			}
+			continue
+		}
+		allow.names = append(allow.names, entry)
+	}
+	return allow
+}
+
+// Match reports whether the allowlist keeps node.
+func (a Allowlist) Match(node *graph.Node) bool {
+	for _, pattern := range a.paths {
+		if pattern.Matches(node.File) {
+			return true
+		}
+	}
+	qualified := node.Symbol.QualifiedName()
+	for _, name := range a.names {
+		if name == node.ID || name == node.Symbol.Name || name == qualified {
+			return true
+		}
+		if matched, _ := path.Match(name, node.Symbol.Name); matched {
+			return true
+		}
+		if matched, _ := path.Match(name, qualified); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// Find reports the symbols of g's handwritten, non-test, non-external files
+// that have no in-edges and are not entry points or allowlisted. Only symbols
+// with one of visibilities are checked, public ones when it is empty.
+func Find(g *graph.Graph, allow Allowlist, visibilities map[string]bool) Report {
+	if len(visibilities) == 0 {
+		visibilities = map[string]bool{parser.VisibilityPublic: true}
+	}
+	report := Report{Symbols: make([]Symbol, 0)}
+	usedTypes := usedMethodOwners(g)
+	for _, file := range g.Files() {
+		if output.NamespaceForFile(file) != output.NamespacePrimary {
+			continue
+		}
+		for _, node := range g.NodesForFile(file) {
+			if node.External || parser.IsTestFile(node.Language, node.File) || !checked(node) || !visibilities[Visibility(node)] {
+				continue
+			}
+			report.Checked++
+			if len(node.InEdges) > 0 || (node.Symbol.Kind != parser.SymbolMethod && usedTypes[typeKey(node, node.Symbol.Name)]) {
+				continue
+			}
+			if IsEntryPoint(g, node) {
+				report.EntryPoints++
+				continue
+			}
+			if allow.Match(node) {
+				report.Allowlisted++
+				continue
+			}
+			report.Symbols = append(report.Symbols, Symbol{
+				ID:         node.ID,
+				Name:       node.Symbol.QualifiedName(),
+				Kind:       node.Symbol.Kind.String(),
+				Signature:  node.Symbol.Signature,
+				File:       node.File,
+				Line:       node.Symbol.Line,
+				Language:   node.Language,
+				Visibility: Visibility(node),
+			})
+		}
+	}
+	sort.SliceStable(report.Symbols, func(i, j int) bool {
+		if report.Symbols[i].File != report.Symbols[j].File {
+			return report.Symbols[i].File < report.Symbols[j].File
+		}
+		return report.Symbols[i].Line < report.Symbols[j].Line
+	})
+	return report
+}
+
+// checked reports whether node is a kind whose uses the graph records.
+// Constants and variables get no reference edges, routes and modules are
+// never targets, schema languages (.proto) describe rather than run, and
+// calls reach the definitions of prototypes rather than the prototypes.
+func checked(node *graph.Node) bool {
+	if node.Language == "proto" || node.Symbol.Prototype {
+		return false
+	}
+	switch node.Symbol.Kind {
+	case parser.SymbolFunction, parser.SymbolMethod, parser.SymbolClass, parser.SymbolStruct,
+		parser.SymbolInterface, parser.SymbolComponent:
+		return true
+	}
+	return false
+}
+
+// Visibility returns the visibility the parser recorded for node or, for
+// languages it does not record, public or private as IsPublic tells.
+func Visibility(node *graph.Node) string {
+	if node.Symbol.Visibility != "" {
+		return node.Symbol.Visibility
+	}
+	if IsPublic(node) {
+		return parser.VisibilityPublic
+	}
+	return parser.VisibilityPrivate
+}
+
+// IsPublic reports whether node is visible outside its file or package by
+// its language's rules: the visibility the parser recorded or, failing that,
+// the modifiers in its signature. Languages whose signatures do not record
+// visibility (Rust pub) treat every symbol as public.
+func IsPublic(node *graph.Node) bool {
+	if node.Symbol.Visibility != "" {
+		return node.Symbol.Visibility == parser.VisibilityPublic
+	}
+	name := node.Symbol.Name
+	signature := strings.TrimSpace(node.Symbol.Signature)
+	switch node.Language {
+	case "go":
+		return name != "" && strings.ToUpper(name[:1]) == name[:1] && strings.ToLower(name[:1]) != name[:1]
+	case "python":
+		return !strings.HasPrefix(name, "_") || isDunder(name)
+	case "java", "csharp":
+		return hasModifier(signature, "public")
+	case "php":
+		return !hasModifier(signature, "private") && !hasModifier(signature, "protected")
+	case "c", "cpp":
+		return !hasModifier(signature, "static")
+	case "typescript", "javascript":
+		return !strings.HasPrefix(name, "_") && !strings.HasPrefix(name, "#")
+	}
+	return true
+}
+
+// hasModifier reports whether the signature's leading keywords include
+// modifier.
+func hasModifier(signature, modifier string) bool {
+	for _, field := range strings.Fields(signature) {
+		if field == modifier {
+			return true
+		}
+		if strings.ContainsAny(field, "(") {
+			return false
+		}
+	}
+	return false
+}
+
+func isDunder(name string) bool {
+	return len(name) > 4 && strings.HasPrefix(name, "__") && strings.HasSuffix(name, "__")
+}
+
+// goInterfaceMethods are methods that standard library interfaces call
+// (fmt.Stringer, error, http.Handler, json.Marshaler, sort.Interface, ...),
+// so Go code rarely calls them by name.
+var goInterfaceMethods = map[string]bool{
+	"String": true, "GoString": true, "Format": true, "Error": true, "Unwrap": true, "Is": true, "As": true,
+	"ServeHTTP": true, "MarshalJSON": true, "UnmarshalJSON": true, "MarshalText": true, "UnmarshalText": true,
+	"MarshalYAML": true, "UnmarshalYAML": true, "MarshalBinary": true, "UnmarshalBinary": true,
+	"Len": true, "Less": true, "Swap": true, "Read": true, "Write": true, "Close": true,
+	"Scan": true, "Value": true,
+}
+
+// httpVerbs name route handlers exported by file-based routers (Next.js
+// route.ts, SvelteKit +server.ts).
+var httpVerbs = map[string]bool{"GET": true, "POST": true, "PUT": true, "PATCH": true, "DELETE": true, "HEAD": true, "OPTIONS": true}
+
+// IsEntryPoint reports whether node is called from outside the code the
+// graph sees: program entry points, constructors and magic methods,
+// framework-registered handlers and commands, decorated symbols, and methods
+// that implement or override a method of a supertype.
+func IsEntryPoint(g *graph.Graph, node *graph.Node) bool {
+	symbol := node.Symbol
+	name := symbol.Name
+	if name == "main" || len(symbol.Decorators) > 0 || overridesSupertype(g, node) {
+		return true
+	}
+	// Handlers registered with a router or dispatcher by value.
+	if strings.HasPrefix(name, "Handle") || strings.HasPrefix(name, "handle") || strings.HasSuffix(name, "Handler") {
+		return true
+	}
+	isMethod := symbol.Kind == parser.SymbolMethod
+	switch node.Language {
+	case "go":
+		if name == "init" || (isMethod && goInterfaceMethods[name]) {
+			return true
+		}
+		// HTTP handlers and cobra or urfave/cli commands.
+		for _, marker := range []string{"http.ResponseWriter", "*cobra.Command", "*cli.Context"} {
+			if strings.Contains(symbol.Signature, marker) {
+				return true
+			}
+		}
+	case "python":
+		return isDunder(name)
+	case "ruby":
+		if name == "initialize" || name == "method_missing" || name == "respond_to_missing?" || name == "to_s" {
+			return true
+		}
+		// Rails controller actions are dispatched by the router.
+		return isMethod && strings.HasPrefix(node.File, "app/controllers/")
+	case "php":
+		return strings.HasPrefix(name, "__") || (isMethod && strings.HasSuffix(symbol.Container, "Controller"))
+	case "java", "csharp":
+		// Constructors are invoked with new, which the graph does not record.
+		return isMethod && name == symbol.Container
+	case "typescript", "javascript":
+		if httpVerbs[name] || name == "default" {
+			return true
+		}
+		base := path.Base(node.File)
+		for _, prefix := range []string{"page.", "layout.", "route.", "middleware.", "+page.", "+server.", "+layout."} {
+			if strings.HasPrefix(base, prefix) {
+				return true
+			}
+		}
+		return strings.HasPrefix(node.File, "pages/") || strings.Contains(node.File, "/pages/")
+	case "rust":
+		// Methods of trait impls (impl Display for S) are called through the
+		// trait.
+		return isMethod && strings.Contains(symbol.Signature, " for ")
+	}
+	return false
+}
+
+// usedMethodOwners returns the types with a method that is called or is an
+// entry point, keyed by typeKey. Method receivers record no edge to their
+// type, so such a type is in use even without in-edges.
+func usedMethodOwners(g *graph.Graph) map[string]bool {
+	used := make(map[string]bool)
+	for _, node := range g.Nodes {
+		if node.Symbol.Kind != parser.SymbolMethod || node.Symbol.Container == "" {
+			continue
+		}
+		if len(node.InEdges) > 0 || IsEntryPoint(g, node) {
+			used[typeKey(node, node.Symbol.Container)] = true
+		}
+	}
+	return used
+}
+
+// typeKey scopes a type name to where its methods can be declared: the
+// package directory in Go, the file elsewhere.
+func typeKey(node *graph.Node, name string) string {
+	if node.Language == "go" {
+		return path.Dir(node.File) + "|" + name
+	}
+	return node.File + "|" + name
+}
+
+// overridesSupertype reports whether a method's type implements or extends a
+// type that declares a method of the same name, so calls through the
+// supertype reach it without an edge.
+func overridesSupertype(g *graph.Graph, node *graph.Node) bool {
+	if node.Symbol.Kind != parser.SymbolMethod || node.Symbol.Container == "" {
+		return false
+	}
+	for _, owner := range typeNodes(g, node, node.Symbol.Container) {
+		for _, supertypeID := range owner.OutEdges {
+			kind := owner.EdgeKindTo(supertypeID)
+			if kind != graph.EdgeImplement && kind != graph.EdgeInherit {
+				continue
+			}
+			supertype := g.Nodes[supertypeID]
+			if supertype == nil {
+				continue
+			}
+			// Go interfaces list their methods on the symbol.
+			for _, method := range supertype.Symbol.Methods {
+				if method == node.Symbol.Name {
+					return true
+				}
+			}
+			for _, member := range g.NodesForFile(supertype.File) {
+				if member.Symbol.Container == supertype.Symbol.Name && member.Symbol.Name == node.Symbol.Name {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// typeNodes returns the types named name that a method of node's could
+// belong to: in node's package directory for Go, in its file elsewhere.
+func typeNodes(g *graph.Graph, node *graph.Node, name string) []*graph.Node {
+	files := []string{node.File}
+	if node.Language == "go" {
+		files = files[:0]
+		for file := range g.FileNodes {
+			if path.Dir(file) == path.Dir(node.File) {
+				files = append(files, file)
+			}
+		}
+	}
+	var types []*graph.Node
+	for _, file := range files {
+		for _, candidate := range g.NodesForFile(file) {
+			if candidate.Symbol.Name == name && candidate.Symbol.Kind != parser.SymbolMethod && candidate.Symbol.Kind != parser.SymbolFunction {
+				types = append(types, candidate)
+			}
+		}
+	}
+	return types
+}
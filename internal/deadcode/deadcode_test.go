@@ -0,0 +1,75 @@
+package deadcode
+
+import (
+	"testing"
+
+	"github.com/morozRed/skelly/internal/graph"
+	"github.com/morozRed/skelly/internal/parser"
+)
+
+func node(language, file string, symbol parser.Symbol) *graph.Node {
+	return &graph.Node{ID: file + "|" + symbol.Name, Symbol: symbol, File: file, Language: language}
+}
+
+func TestIsPublicFollowsLanguageVisibility(t *testing.T) {
+	cases := []struct {
+		node *graph.Node
+		want bool
+	}{
+		{node("go", "a.go", parser.Symbol{Name: "Load", Kind: parser.SymbolFunction}), true},
+		{node("go", "a.go", parser.Symbol{Name: "load", Kind: parser.SymbolFunction}), false},
+		{node("python", "a.py", parser.Symbol{Name: "_load", Kind: parser.SymbolFunction}), false},
+		{node("python", "a.py", parser.Symbol{Name: "__eq__", Kind: parser.SymbolMethod}), true},
+		{node("java", "A.java", parser.Symbol{Name: "load", Kind: parser.SymbolMethod, Signature: "public static void load(String id)"}), true},
+		{node("java", "A.java", parser.Symbol{Name: "load", Kind: parser.SymbolMethod, Signature: "private void load(String id)"}), false},
+		{node("php", "a.php", parser.Symbol{Name: "load", Kind: parser.SymbolMethod, Signature: "protected function load($id)"}), false},
+		{node("php", "a.php", parser.Symbol{Name: "load", Kind: parser.SymbolMethod, Signature: "function load($id)"}), true},
+		{node("c", "a.c", parser.Symbol{Name: "load", Kind: parser.SymbolFunction, Signature: "static int load(void)"}), false},
+	}
+	for _, tc := range cases {
+		if got := IsPublic(tc.node); got != tc.want {
+			t.Errorf("IsPublic(%s %q %q) = %v, want %v", tc.node.Language, tc.node.Symbol.Name, tc.node.Symbol.Signature, got, tc.want)
+		}
+	}
+}
+
+func TestIsEntryPointRecognizesConventions(t *testing.T) {
+	g := graph.NewGraph()
+	entryPoints := []*graph.Node{
+		node("go", "main.go", parser.Symbol{Name: "main", Kind: parser.SymbolFunction}),
+		node("go", "api.go", parser.Symbol{Name: "Users", Kind: parser.SymbolFunction, Signature: "func Users(w http.ResponseWriter, r *http.Request)"}),
+		node("go", "cmd.go", parser.Symbol{Name: "RunServe", Kind: parser.SymbolFunction, Signature: "func RunServe(cmd *cobra.Command, args []string) error"}),
+		node("go", "id.go", parser.Symbol{Name: "String", Kind: parser.SymbolMethod, Container: "ID"}),
+		node("python", "app.py", parser.Symbol{Name: "index", Kind: parser.SymbolFunction, Decorators: []string{"app.get"}}),
+		node("java", "User.java", parser.Symbol{Name: "User", Kind: parser.SymbolMethod, Container: "User"}),
+		node("ruby", "app/controllers/users_controller.rb", parser.Symbol{Name: "show", Kind: parser.SymbolMethod, Container: "UsersController"}),
+		node("typescript", "app/api/users/route.ts", parser.Symbol{Name: "GET", Kind: parser.SymbolFunction}),
+		node("rust", "id.rs", parser.Symbol{Name: "fmt", Kind: parser.SymbolMethod, Signature: "impl fmt::Display for Id { fn fmt(&self, f: &mut fmt::Formatter) }"}),
+	}
+	for _, n := range entryPoints {
+		if !IsEntryPoint(g, n) {
+			t.Errorf("expected %s %q in %s to be an entry point", n.Language, n.Symbol.Name, n.File)
+		}
+	}
+	if n := node("go", "store.go", parser.Symbol{Name: "LoadUser", Kind: parser.SymbolFunction, Signature: "func LoadUser(id string) error"}); IsEntryPoint(g, n) {
+		t.Fatalf("expected plain function not to be an entry point")
+	}
+}
+
+func TestAllowlistMatchesNamesGlobsAndPaths(t *testing.T) {
+	allow := NewAllowlist([]string{"User.save", "Legacy*", "internal/compat/**", " "})
+	cases := []struct {
+		node *graph.Node
+		want bool
+	}{
+		{node("go", "store.go", parser.Symbol{Name: "save", Kind: parser.SymbolMethod, Container: "User"}), true},
+		{node("go", "store.go", parser.Symbol{Name: "LegacyClient", Kind: parser.SymbolStruct}), true},
+		{node("go", "internal/compat/v1/api.go", parser.Symbol{Name: "Fetch", Kind: parser.SymbolFunction}), true},
+		{node("go", "internal/api/api.go", parser.Symbol{Name: "Fetch", Kind: parser.SymbolFunction}), false},
+	}
+	for _, tc := range cases {
+		if got := allow.Match(tc.node); got != tc.want {
+			t.Errorf("Match(%s %q) = %v, want %v", tc.node.File, tc.node.Symbol.QualifiedName(), got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,283 @@
+// Package dirdocs renders per-directory orientation docs (README.skelly.md)
+// from the dependency graph.
+package dirdocs
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/morozRed/skelly/internal/graph"
+	"github.com/morozRed/skelly/internal/parser"
+)
+
+// File is the generated document written into each significant directory.
+const File = "README.skelly.md"
+
+const (
+	overviewStart = "<!-- skelly:dir:overview:start -->"
+	overviewEnd   = "<!-- skelly:dir:overview:end -->"
+
+	// DefaultMinFiles is how many indexed files make a directory significant
+	// on its own; directories with entrypoints always qualify.
+	DefaultMinFiles = 2
+
+	maxKeySymbols  = 8
+	maxEntrypoints = 8
+	maxLinks       = 10
+)
+
+// Doc describes one directory.
+type Doc struct {
+	Dir         string      `json:"dir"`
+	Files       []FileEntry `json:"files"`
+	KeySymbols  []SymbolRef `json:"key_symbols"`
+	Entrypoints []SymbolRef `json:"entrypoints,omitempty"`
+	Inbound     []DirLink   `json:"inbound,omitempty"`
+	Outbound    []DirLink   `json:"outbound,omitempty"`
+	Overview    string      `json:"overview,omitempty"`
+}
+
+// FileEntry is one indexed file in the directory.
+type FileEntry struct {
+	Path     string `json:"path"`
+	Language string `json:"language,omitempty"`
+	Symbols  int    `json:"symbols"`
+}
+
+// SymbolRef points at a symbol, with its enrich summary when one exists.
+type SymbolRef struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Kind      string `json:"kind"`
+	File      string `json:"file"`
+	Line      int    `json:"line"`
+	Signature string `json:"signature,omitempty"`
+	Summary   string `json:"summary,omitempty"`
+	Callers   int    `json:"external_callers,omitempty"`
+}
+
+// DirLink counts call edges between this directory and another.
+type DirLink struct {
+	Dir   string `json:"dir"`
+	Calls int    `json:"calls"`
+}
+
+// Analyze builds a Doc for every directory with at least minFiles indexed
+// files or an entrypoint. summaries maps symbol IDs to enrich summaries.
+func Analyze(g *graph.Graph, summaries map[string]string, minFiles int) []Doc {
+	filesByDir := make(map[string][]string)
+	for _, file := range g.Files() {
+		dir := path.Dir(file)
+		filesByDir[dir] = append(filesByDir[dir], file)
+	}
+
+	outbound := make(map[string]map[string]int)
+	inbound := make(map[string]map[string]int)
+	externalCallers := make(map[string]int)
+	for _, node := range g.Nodes {
+		from := path.Dir(node.File)
+		for _, targetID := range node.OutEdges {
+			target, ok := g.Nodes[targetID]
+			if !ok {
+				continue
+			}
+			to := path.Dir(target.File)
+			if to == from {
+				continue
+			}
+			addLink(outbound, from, to)
+			addLink(inbound, to, from)
+			externalCallers[targetID]++
+		}
+	}
+
+	docs := make([]Doc, 0)
+	for dir, files := range filesByDir {
+		doc := Doc{Dir: dir}
+		nodes := make([]*graph.Node, 0)
+		for _, file := range files {
+			fileNodes := g.NodesForFile(file)
+			language := ""
+			if len(fileNodes) > 0 {
+				language = fileNodes[0].Language
+			}
+			doc.Files = append(doc.Files, FileEntry{Path: file, Language: language, Symbols: len(fileNodes)})
+			nodes = append(nodes, fileNodes...)
+		}
+
+		entrypoints := make([]*graph.Node, 0)
+		for _, node := range nodes {
+			isMain := node.Symbol.Name == "main" && node.Symbol.Kind == parser.SymbolFunction
+			if isMain || externalCallers[node.ID] > 0 {
+				entrypoints = append(entrypoints, node)
+			}
+		}
+		if len(files) < minFiles && !hasMain(entrypoints) {
+			continue
+		}
+
+		sort.Slice(entrypoints, func(i, j int) bool {
+			left, right := entrypointRank(entrypoints[i], externalCallers), entrypointRank(entrypoints[j], externalCallers)
+			if left != right {
+				return left > right
+			}
+			return entrypoints[i].ID < entrypoints[j].ID
+		})
+		for _, node := range entrypoints[:min(maxEntrypoints, len(entrypoints))] {
+			doc.Entrypoints = append(doc.Entrypoints, symbolRef(node, summaries, externalCallers[node.ID]))
+		}
+
+		sort.Slice(nodes, func(i, j int) bool {
+			if nodes[i].PageRank != nodes[j].PageRank {
+				return nodes[i].PageRank > nodes[j].PageRank
+			}
+			return nodes[i].ID < nodes[j].ID
+		})
+		for _, node := range nodes[:min(maxKeySymbols, len(nodes))] {
+			doc.KeySymbols = append(doc.KeySymbols, symbolRef(node, summaries, externalCallers[node.ID]))
+		}
+
+		doc.Inbound = sortedLinks(inbound[dir])
+		doc.Outbound = sortedLinks(outbound[dir])
+		docs = append(docs, doc)
+	}
+
+	sort.Slice(docs, func(i, j int) bool { return docs[i].Dir < docs[j].Dir })
+	return docs
+}
+
+func addLink(links map[string]map[string]int, from, to string) {
+	if links[from] == nil {
+		links[from] = make(map[string]int)
+	}
+	links[from][to]++
+}
+
+func hasMain(nodes []*graph.Node) bool {
+	for _, node := range nodes {
+		if node.Symbol.Name == "main" && node.Symbol.Kind == parser.SymbolFunction {
+			return true
+		}
+	}
+	return false
+}
+
+// entrypointRank puts main first, then symbols by external caller count.
+func entrypointRank(node *graph.Node, externalCallers map[string]int) int {
+	if node.Symbol.Name == "main" && node.Symbol.Kind == parser.SymbolFunction {
+		return 1 << 30
+	}
+	return externalCallers[node.ID]
+}
+
+func symbolRef(node *graph.Node, summaries map[string]string, callers int) SymbolRef {
+	return SymbolRef{
+		ID:        node.ID,
+		Name:      node.Symbol.Name,
+		Kind:      node.Symbol.Kind.String(),
+		File:      node.File,
+		Line:      node.Symbol.Line,
+		Signature: node.Symbol.Signature,
+		Summary:   summaries[node.ID],
+		Callers:   callers,
+	}
+}
+
+func sortedLinks(counts map[string]int) []DirLink {
+	links := make([]DirLink, 0, len(counts))
+	for dir, calls := range counts {
+		links = append(links, DirLink{Dir: dir, Calls: calls})
+	}
+	sort.Slice(links, func(i, j int) bool {
+		if links[i].Calls != links[j].Calls {
+			return links[i].Calls > links[j].Calls
+		}
+		return links[i].Dir < links[j].Dir
+	})
+	if len(links) > maxLinks {
+		links = links[:maxLinks]
+	}
+	return links
+}
+
+// Render builds the directory document. The overview section between the
+// markers is left for a human or agent to write and survives regeneration.
+func Render(doc Doc) string {
+	var sb strings.Builder
+	title := doc.Dir
+	if title == "." {
+		title = "(repository root)"
+	}
+	fmt.Fprintf(&sb, "# %s\n\n", title)
+	sb.WriteString("Generated by `skelly docs dirs` from the dependency graph; edit only the Overview section.\n")
+
+	sb.WriteString("\n## Overview\n\n")
+	sb.WriteString(overviewStart + "\n")
+	if doc.Overview != "" {
+		sb.WriteString(doc.Overview + "\n")
+	}
+	sb.WriteString(overviewEnd + "\n")
+
+	sb.WriteString("\n## Files\n\n")
+	for _, file := range doc.Files {
+		fmt.Fprintf(&sb, "- `%s` (%s, %d symbols)\n", path.Base(file.Path), languageLabel(file.Language), file.Symbols)
+	}
+
+	sb.WriteString("\n## Entrypoints\n\n")
+	if len(doc.Entrypoints) == 0 {
+		sb.WriteString("- (nothing here is called from other directories)\n")
+	}
+	for _, symbol := range doc.Entrypoints {
+		writeSymbol(&sb, symbol, true)
+	}
+
+	sb.WriteString("\n## Key Symbols\n\n")
+	if len(doc.KeySymbols) == 0 {
+		sb.WriteString("- (no symbols indexed)\n")
+	}
+	for _, symbol := range doc.KeySymbols {
+		writeSymbol(&sb, symbol, false)
+	}
+
+	sb.WriteString("\n## Dependencies\n\n")
+	if len(doc.Inbound) == 0 && len(doc.Outbound) == 0 {
+		sb.WriteString("- (no cross-directory calls)\n")
+	}
+	for _, link := range doc.Inbound {
+		fmt.Fprintf(&sb, "- used by `%s` (%d calls)\n", link.Dir, link.Calls)
+	}
+	for _, link := range doc.Outbound {
+		fmt.Fprintf(&sb, "- depends on `%s` (%d calls)\n", link.Dir, link.Calls)
+	}
+	return sb.String()
+}
+
+func writeSymbol(sb *strings.Builder, symbol SymbolRef, withCallers bool) {
+	fmt.Fprintf(sb, "- `%s` %s (`%s:%d`)", symbol.Name, symbol.Kind, path.Base(symbol.File), symbol.Line)
+	if withCallers && symbol.Callers > 0 {
+		fmt.Fprintf(sb, ", %d external calls", symbol.Callers)
+	}
+	if symbol.Summary != "" {
+		sb.WriteString(" — " + symbol.Summary)
+	}
+	sb.WriteString("\n")
+}
+
+func languageLabel(language string) string {
+	if language == "" {
+		return "no symbols"
+	}
+	return language
+}
+
+// ReadOverview returns the preserved overview from an existing document.
+func ReadOverview(document string) string {
+	start := strings.Index(document, overviewStart)
+	end := strings.Index(document, overviewEnd)
+	if start == -1 || end == -1 || end < start {
+		return ""
+	}
+	return strings.TrimSpace(document[start+len(overviewStart) : end])
+}
@@ -0,0 +1,19 @@
+package dirdocs
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReadOverviewRoundTripsRenderedOverview(t *testing.T) {
+	document := Render(Doc{Dir: "store", Overview: "Persistence for user records.\n\nOwned by the data team."})
+	if got := ReadOverview(document); got != "Persistence for user records.\n\nOwned by the data team." {
+		t.Fatalf("unexpected overview: %q", got)
+	}
+	if got := ReadOverview(Render(Doc{Dir: "."})); got != "" {
+		t.Fatalf("expected empty overview, got %q", got)
+	}
+	if !strings.HasPrefix(Render(Doc{Dir: "."}), "# (repository root)\n") {
+		t.Fatalf("expected root directory title")
+	}
+}
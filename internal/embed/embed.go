@@ -0,0 +1,210 @@
+// Package embed stores symbol embeddings and ranks symbols by cosine
+// similarity to a query embedding.
+package embed
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/morozRed/skelly/internal/fileutil"
+	"github.com/morozRed/skelly/internal/output"
+)
+
+const (
+	// File holds the embeddings under the context directory.
+	File = "embeddings.bin"
+	// Version is the embeddings.bin schema version.
+	Version = "embeddings-v1"
+)
+
+// Store is the content of embeddings.bin: one vector per symbol, all from
+// the same model.
+type Store struct {
+	Version    string
+	Model      string
+	Dimensions int
+	Entries    []Entry
+}
+
+// Entry is one symbol's embedding. TextHash identifies the text that was
+// embedded, so unchanged symbols are not embedded again.
+type Entry struct {
+	ID       string
+	TextHash string
+	Vector   []float32
+}
+
+// Symbol is the symbol metadata embedded for a symbol.
+type Symbol struct {
+	ID        string
+	Name      string
+	Kind      string
+	Signature string
+	File      string
+	Doc       string
+	// Summary is the symbol's enrich description, if any.
+	Summary string
+}
+
+// Text renders the text embedded for a symbol: its kind and name, file,
+// signature, doc comment and enrich summary.
+func Text(symbol Symbol) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s %s in %s", symbol.Kind, symbol.Name, symbol.File)
+	for _, part := range []string{symbol.Signature, symbol.Doc, symbol.Summary} {
+		if part = strings.TrimSpace(part); part != "" {
+			sb.WriteString("\n")
+			sb.WriteString(part)
+		}
+	}
+	return sb.String()
+}
+
+// TextHash returns the short content hash stored with an embedding.
+func TextHash(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:8])
+}
+
+// Path returns the embeddings file path under rootPath.
+func Path(rootPath string) string {
+	return filepath.Join(rootPath, output.ContextDir, File)
+}
+
+// Load reads embeddings.bin. A missing file yields nil without an error.
+func Load(rootPath string) (*Store, error) {
+	data, err := os.ReadFile(Path(rootPath))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read embeddings: %w", err)
+	}
+	var store Store
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&store); err != nil {
+		return nil, fmt.Errorf("failed to decode embeddings (run skelly enrich embed): %w", err)
+	}
+	if store.Version != Version {
+		return nil, fmt.Errorf("embeddings version %q is not supported (run skelly enrich embed)", store.Version)
+	}
+	return &store, nil
+}
+
+// Save writes embeddings.bin, entries sorted by ID.
+func Save(rootPath string, store *Store) error {
+	sort.Slice(store.Entries, func(i, j int) bool { return store.Entries[i].ID < store.Entries[j].ID })
+	store.Version = Version
+	var data bytes.Buffer
+	if err := gob.NewEncoder(&data).Encode(store); err != nil {
+		return fmt.Errorf("failed to encode embeddings: %w", err)
+	}
+	return fileutil.WriteIfChanged(Path(rootPath), data.Bytes())
+}
+
+// Similarities returns the cosine similarity of every stored vector to query,
+// by symbol ID.
+func (s *Store) Similarities(query []float32) (map[string]float64, error) {
+	if len(query) != s.Dimensions {
+		return nil, fmt.Errorf("query embedding has %d dimensions, stored embeddings have %d (was the model changed?)", len(query), s.Dimensions)
+	}
+	similarities := make(map[string]float64, len(s.Entries))
+	for _, entry := range s.Entries {
+		similarities[entry.ID] = Cosine(query, entry.Vector)
+	}
+	return similarities, nil
+}
+
+// Cosine returns the cosine similarity of two vectors, or 0 when either is
+// zero or their lengths differ.
+func Cosine(a, b []float32) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// UpdateStats counts what Update embedded, reused and dropped.
+type UpdateStats struct {
+	Embedded int `json:"embedded"`
+	Reused   int `json:"reused"`
+	Removed  int `json:"removed"`
+}
+
+// Update embeds the symbols whose text changed since previous (all of them
+// when the model changed), batch texts per provider call, and returns a
+// store holding exactly the given symbols.
+func Update(embedder Embedder, model string, previous *Store, symbols []Symbol, batch int) (*Store, UpdateStats, error) {
+	if batch <= 0 {
+		batch = 64
+	}
+	store := &Store{Model: model, Entries: make([]Entry, 0, len(symbols))}
+	reusable := make(map[string]Entry)
+	if previous != nil && previous.Model == model {
+		store.Dimensions = previous.Dimensions
+		for _, entry := range previous.Entries {
+			reusable[entry.ID] = entry
+		}
+	}
+
+	var stats UpdateStats
+	current := make(map[string]bool, len(symbols))
+	pending := make([]Entry, 0)
+	texts := make([]string, 0)
+	for _, symbol := range symbols {
+		current[symbol.ID] = true
+		text := Text(symbol)
+		hash := TextHash(text)
+		if entry, ok := reusable[symbol.ID]; ok && entry.TextHash == hash {
+			store.Entries = append(store.Entries, entry)
+			stats.Reused++
+			continue
+		}
+		pending = append(pending, Entry{ID: symbol.ID, TextHash: hash})
+		texts = append(texts, text)
+	}
+	if previous != nil {
+		for _, entry := range previous.Entries {
+			if !current[entry.ID] {
+				stats.Removed++
+			}
+		}
+	}
+
+	for start := 0; start < len(texts); start += batch {
+		end := min(start+batch, len(texts))
+		vectors, err := embedder.Embed(texts[start:end])
+		if err != nil {
+			return nil, stats, err
+		}
+		for i, vector := range vectors {
+			if store.Dimensions == 0 {
+				store.Dimensions = len(vector)
+			}
+			if len(vector) != store.Dimensions {
+				return nil, stats, fmt.Errorf("embedding for %s has %d dimensions, expected %d", pending[start+i].ID, len(vector), store.Dimensions)
+			}
+			entry := pending[start+i]
+			entry.Vector = vector
+			store.Entries = append(store.Entries, entry)
+			stats.Embedded++
+		}
+	}
+	return store, stats, nil
+}
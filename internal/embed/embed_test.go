@@ -0,0 +1,69 @@
+package embed
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+type countingEmbedder struct {
+	calls int
+	texts []string
+}
+
+func (e *countingEmbedder) Embed(texts []string) ([][]float32, error) {
+	e.calls++
+	e.texts = append(e.texts, texts...)
+	vectors := make([][]float32, 0, len(texts))
+	for _, text := range texts {
+		vectors = append(vectors, []float32{float32(len(text)), 1})
+	}
+	return vectors, nil
+}
+
+func TestUpdateReusesUnchangedSymbolsAndBatches(t *testing.T) {
+	symbols := []Symbol{
+		{ID: "a", Name: "Charge", Kind: "func", File: "billing.go", Doc: "Charge bills a customer."},
+		{ID: "b", Name: "Refund", Kind: "func", File: "billing.go"},
+		{ID: "c", Name: "Welcome", Kind: "func", File: "notify.go"},
+	}
+	embedder := &countingEmbedder{}
+	store, stats, err := Update(embedder, "m1", nil, symbols, 2)
+	if err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if stats.Embedded != 3 || embedder.calls != 2 || store.Dimensions != 2 || len(store.Entries) != 3 {
+		t.Fatalf("expected three symbols embedded in two batches, got stats=%+v calls=%d store=%+v", stats, embedder.calls, store)
+	}
+	if !strings.Contains(embedder.texts[0], "Charge bills a customer.") {
+		t.Fatalf("expected the doc comment in the embedded text, got %q", embedder.texts[0])
+	}
+
+	symbols[1].Doc = "Refund returns a payment."
+	symbols = symbols[:2]
+	embedder = &countingEmbedder{}
+	updated, stats, err := Update(embedder, "m1", store, symbols, 64)
+	if err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if stats != (UpdateStats{Embedded: 1, Reused: 1, Removed: 1}) || len(updated.Entries) != 2 || len(embedder.texts) != 1 {
+		t.Fatalf("expected only the changed symbol re-embedded, got stats=%+v texts=%v", stats, embedder.texts)
+	}
+
+	embedder = &countingEmbedder{}
+	if _, stats, err = Update(embedder, "m2", updated, symbols, 64); err != nil || stats.Embedded != 2 || stats.Reused != 0 {
+		t.Fatalf("expected a model change to re-embed everything, got stats=%+v err=%v", stats, err)
+	}
+}
+
+func TestCosine(t *testing.T) {
+	if got := Cosine([]float32{1, 0}, []float32{1, 0}); math.Abs(got-1) > 1e-9 {
+		t.Fatalf("expected identical vectors to score 1, got %f", got)
+	}
+	if got := Cosine([]float32{1, 0}, []float32{0, 1}); got != 0 {
+		t.Fatalf("expected orthogonal vectors to score 0, got %f", got)
+	}
+	if got := Cosine([]float32{1, 0}, []float32{1}); got != 0 {
+		t.Fatalf("expected mismatched lengths to score 0, got %f", got)
+	}
+}
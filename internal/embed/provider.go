@@ -0,0 +1,136 @@
+package embed
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+)
+
+// APIKeyEnv names the environment variables holding the endpoint's API key,
+// in lookup order.
+var APIKeyEnv = []string{"SKELLY_EMBED_API_KEY", "OPENAI_API_KEY"}
+
+// Embedder turns texts into vectors, one per text and in the same order.
+type Embedder interface {
+	Embed(texts []string) ([][]float32, error)
+}
+
+// request and response are the OpenAI embeddings API bodies, which both
+// providers speak.
+type request struct {
+	Model string   `json:"model,omitempty"`
+	Input []string `json:"input"`
+}
+
+type response struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float32 `json:"embedding"`
+	} `json:"data"`
+}
+
+// NewEmbedder returns the provider for a configured command or endpoint; a
+// command takes precedence.
+func NewEmbedder(command, endpoint, model string) (Embedder, error) {
+	switch {
+	case strings.TrimSpace(command) != "":
+		return &CommandEmbedder{Command: command, Model: model}, nil
+	case strings.TrimSpace(endpoint) != "":
+		return &HTTPEmbedder{Endpoint: endpoint, Model: model, Client: &http.Client{Timeout: 2 * time.Minute}}, nil
+	default:
+		return nil, fmt.Errorf("no embeddings provider configured (set --embed-command or --embed-endpoint, or embeddings.command or embeddings.endpoint in .skelly/config.yaml)")
+	}
+}
+
+// CommandEmbedder runs a shell command that reads an OpenAI embeddings
+// request on stdin and prints the response on stdout, such as a curl call
+// or an agent CLI wrapper.
+type CommandEmbedder struct {
+	Command string
+	Model   string
+}
+
+func (e *CommandEmbedder) Embed(texts []string) ([][]float32, error) {
+	body, err := json.Marshal(request{Model: e.Model, Input: texts})
+	if err != nil {
+		return nil, err
+	}
+	cmd := exec.Command("sh", "-c", e.Command)
+	cmd.Stdin = bytes.NewReader(body)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("embeddings command failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return decodeResponse(out, len(texts))
+}
+
+// HTTPEmbedder posts to an OpenAI-compatible embeddings endpoint, given as
+// the API base URL (https://api.openai.com/v1) or the full /embeddings URL.
+type HTTPEmbedder struct {
+	Endpoint string
+	Model    string
+	Client   *http.Client
+}
+
+func (e *HTTPEmbedder) Embed(texts []string) ([][]float32, error) {
+	body, err := json.Marshal(request{Model: e.Model, Input: texts})
+	if err != nil {
+		return nil, err
+	}
+	url := strings.TrimSuffix(e.Endpoint, "/")
+	if !strings.HasSuffix(url, "/embeddings") {
+		url += "/embeddings"
+	}
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("invalid embeddings endpoint: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for _, name := range APIKeyEnv {
+		if key := os.Getenv(name); key != "" {
+			req.Header.Set("Authorization", "Bearer "+key)
+			break
+		}
+	}
+	resp, err := e.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("embeddings request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read embeddings response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("embeddings endpoint returned %s: %s", resp.Status, strings.TrimSpace(string(data)))
+	}
+	return decodeResponse(data, len(texts))
+}
+
+func decodeResponse(data []byte, want int) ([][]float32, error) {
+	var decoded response
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil, fmt.Errorf("failed to decode embeddings response: %w", err)
+	}
+	if len(decoded.Data) != want {
+		return nil, fmt.Errorf("embeddings response has %d vectors for %d inputs", len(decoded.Data), want)
+	}
+	sort.SliceStable(decoded.Data, func(i, j int) bool { return decoded.Data[i].Index < decoded.Data[j].Index })
+	vectors := make([][]float32, 0, want)
+	for _, item := range decoded.Data {
+		if len(item.Embedding) == 0 {
+			return nil, fmt.Errorf("embeddings response has an empty vector")
+		}
+		vectors = append(vectors, item.Embedding)
+	}
+	return vectors, nil
+}
@@ -0,0 +1,113 @@
+package enrich
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/morozRed/skelly/internal/parser"
+)
+
+// Bootstrap records are derived from existing doc comments, not an agent.
+const (
+	BootstrapProfile       = "bootstrap"
+	BootstrapModel         = "docstring"
+	BootstrapPromptVersion = "bootstrap-v1"
+	BootstrapStatus        = "bootstrapped"
+
+	// BootstrapMinWords is the default minimum doc length, excluding the
+	// symbol's own name, for a doc comment to count as a description.
+	BootstrapMinWords = 5
+
+	bootstrapPurposeLimit = 400
+)
+
+// boilerplateDocMarkers mark doc comments that describe tooling or pending
+// work rather than the symbol.
+var boilerplateDocMarkers = []string{
+	"todo", "fixme", "xxx", "hack", "deprecated",
+	"code generated", "do not edit", "auto-generated", "autogenerated",
+}
+
+// BootstrapOutput turns a symbol's doc comment into enrich output when it
+// looks like a real description: long enough once the symbol name is
+// removed, and not boilerplate. Confidence grows with detail.
+func BootstrapOutput(sym parser.Symbol, minWords int) (Output, bool) {
+	doc := strings.Join(strings.Fields(sym.Doc), " ")
+	if doc == "" {
+		return Output{}, false
+	}
+	lower := strings.ToLower(doc)
+	firstWord := strings.TrimRight(strings.Fields(lower)[0], ":,.")
+	for _, marker := range boilerplateDocMarkers {
+		if firstWord == marker || (strings.Contains(marker, " ") && strings.Contains(lower, marker)) {
+			return Output{}, false
+		}
+	}
+
+	words := descriptiveWords(doc, sym.Name)
+	if words < minWords {
+		return Output{}, false
+	}
+
+	sentences := countSentences(doc)
+	confidence := "low"
+	switch {
+	case words >= 15 || sentences >= 2:
+		confidence = "high"
+	case words >= 8:
+		confidence = "medium"
+	}
+
+	purpose := doc
+	if len(purpose) > bootstrapPurposeLimit {
+		purpose = strings.TrimSpace(purpose[:bootstrapPurposeLimit]) + "..."
+	}
+	return Output{
+		Summary:     firstSentence(doc),
+		Purpose:     purpose,
+		SideEffects: "Unknown from static analysis.",
+		Confidence:  confidence,
+	}, true
+}
+
+// descriptiveWords counts words other than the symbol name, so a comment
+// like "Foo is a foo" is not mistaken for a description.
+func descriptiveWords(doc, name string) int {
+	name = strings.ToLower(name)
+	count := 0
+	for _, word := range strings.Fields(doc) {
+		word = strings.ToLower(strings.TrimFunc(word, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+		}))
+		if word == "" || word == name {
+			continue
+		}
+		count++
+	}
+	return count
+}
+
+func firstSentence(doc string) string {
+	for i := 0; i < len(doc); i++ {
+		if doc[i] != '.' && doc[i] != '!' && doc[i] != '?' {
+			continue
+		}
+		if i == len(doc)-1 || doc[i+1] == ' ' {
+			return doc[:i+1]
+		}
+	}
+	return doc
+}
+
+func countSentences(doc string) int {
+	count := 0
+	for i := 0; i < len(doc); i++ {
+		if (doc[i] == '.' || doc[i] == '!' || doc[i] == '?') && (i == len(doc)-1 || doc[i+1] == ' ') {
+			count++
+		}
+	}
+	if count == 0 {
+		return 1
+	}
+	return count
+}
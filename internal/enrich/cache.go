@@ -128,3 +128,36 @@ func PruneCacheForSymbol(cache map[string]Record, keepKey, symbolID, agentProfil
 		delete(cache, key)
 	}
 }
+
+// ForwardRecords moves records of retired symbol IDs onto their forwarded IDs
+// so annotations follow moved and renamed symbols. Records already present
+// for the new ID win. It returns the number of records forwarded.
+func ForwardRecords(cache map[string]Record, aliases map[string]string) int {
+	if len(aliases) == 0 {
+		return 0
+	}
+	keys := make([]string, 0, len(cache))
+	for key := range cache {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	forwarded := 0
+	for _, key := range keys {
+		record := cache[key]
+		target, ok := aliases[record.SymbolID]
+		if !ok || target == record.SymbolID {
+			continue
+		}
+		delete(cache, key)
+		record.SymbolID = target
+		record.Input.Symbol.ID = target
+		record.CacheKey = CacheKey(record.SymbolID, record.FileHash, record.PromptVersion, record.AgentProfile, record.Model)
+		if _, exists := cache[record.CacheKey]; exists {
+			continue
+		}
+		cache[record.CacheKey] = record
+		forwarded++
+	}
+	return forwarded
+}
@@ -0,0 +1,163 @@
+package enrich
+
+import "time"
+
+// GCResult counts the records CollectGarbage kept and dropped.
+type GCResult struct {
+	Kept int `json:"kept"`
+	// Orphaned records belong to symbols that are no longer indexed.
+	Orphaned int `json:"orphaned"`
+	// Superseded records have a preferred record for the same symbol and
+	// profile: one written against the current file hash, else a newer one.
+	Superseded int `json:"superseded"`
+	// Stale records were written against an older file hash and had no
+	// replacement; they are only dropped when asked to.
+	Stale int `json:"stale"`
+}
+
+// CollectGarbage drops records of symbols missing from symbols (symbol ID to
+// current file hash) and keeps one record per symbol and profile. With
+// dropStale it also drops the records left that do not match the current
+// file hash, at the cost of their summaries.
+func CollectGarbage(cache map[string]Record, symbols map[string]string, dropStale bool) GCResult {
+	result := GCResult{}
+	best := make(map[string]Record)
+	for key, record := range cache {
+		hash, ok := symbols[record.SymbolID]
+		if !ok {
+			delete(cache, key)
+			result.Orphaned++
+			continue
+		}
+		group := record.SymbolID + "\x00" + record.AgentProfile
+		current, exists := best[group]
+		if !exists {
+			best[group] = record
+			continue
+		}
+		if preferRecord(record, current, hash) {
+			best[group] = record
+			current, record = record, current
+		}
+		delete(cache, record.CacheKey)
+		result.Superseded++
+	}
+	for _, record := range best {
+		if dropStale && record.FileHash != symbols[record.SymbolID] {
+			delete(cache, record.CacheKey)
+			result.Stale++
+		}
+	}
+	result.Kept = len(cache)
+	return result
+}
+
+// preferRecord reports whether record should be kept over current for a
+// symbol whose file hash is hash.
+func preferRecord(record, current Record, hash string) bool {
+	if fresh, currentFresh := record.FileHash == hash, current.FileHash == hash; fresh != currentFresh {
+		return fresh
+	}
+	if recordTS, currentTS := recordTime(record), recordTime(current); recordTS != currentTS {
+		return recordTS > currentTS
+	}
+	return record.CacheKey > current.CacheKey
+}
+
+func recordTime(record Record) string {
+	if record.UpdatedAt != "" {
+		return record.UpdatedAt
+	}
+	return record.GeneratedAt
+}
+
+// CacheStats describes enrich.jsonl against the indexed symbols.
+type CacheStats struct {
+	Records int   `json:"records"`
+	Bytes   int64 `json:"bytes"`
+	// Symbols counts indexed symbols; Covered those with any record and Fresh
+	// those with a record at their current file hash, which the next run
+	// reuses. HitRate is Fresh over Symbols.
+	Symbols int     `json:"symbols"`
+	Covered int     `json:"covered"`
+	Fresh   int     `json:"fresh"`
+	HitRate float64 `json:"hit_rate"`
+	// StaleRecords were written against an older hash of an indexed symbol.
+	StaleRecords int `json:"stale_records"`
+	// Reclaimable is what `enrich gc` would drop without --stale.
+	Reclaimable GCResult       `json:"reclaimable"`
+	ByStatus    map[string]int `json:"by_status"`
+	ByProfile   map[string]int `json:"by_profile"`
+	// StaleAge buckets stale records by how long ago they were written.
+	StaleAge map[string]int `json:"stale_age"`
+}
+
+// staleAgeLimits bounds the age buckets of CacheStats.StaleAge.
+var staleAgeLimits = []struct {
+	name string
+	max  time.Duration
+}{
+	{"under_1d", 24 * time.Hour},
+	{"under_7d", 7 * 24 * time.Hour},
+	{"under_30d", 30 * 24 * time.Hour},
+}
+
+// StaleAgeBuckets lists the StaleAge keys, youngest first; "unknown" holds
+// records without a readable timestamp.
+var StaleAgeBuckets = []string{"under_1d", "under_7d", "under_30d", "older", "unknown"}
+
+// ComputeCacheStats compares the records of cache against symbols (symbol ID
+// to current file hash).
+func ComputeCacheStats(cache map[string]Record, symbols map[string]string, now time.Time) CacheStats {
+	stats := CacheStats{
+		Records:   len(cache),
+		Symbols:   len(symbols),
+		ByStatus:  make(map[string]int),
+		ByProfile: make(map[string]int),
+		StaleAge:  make(map[string]int),
+	}
+	covered := make(map[string]bool)
+	fresh := make(map[string]bool)
+	copied := make(map[string]Record, len(cache))
+	for key, record := range cache {
+		copied[key] = record
+		status := record.Status
+		if status == "" {
+			status = "unknown"
+		}
+		stats.ByStatus[status]++
+		stats.ByProfile[record.AgentProfile]++
+		hash, ok := symbols[record.SymbolID]
+		if !ok {
+			continue
+		}
+		covered[record.SymbolID] = true
+		if record.FileHash == hash {
+			fresh[record.SymbolID] = true
+			continue
+		}
+		stats.StaleRecords++
+		stats.StaleAge[staleAge(record, now)]++
+	}
+	stats.Covered = len(covered)
+	stats.Fresh = len(fresh)
+	if stats.Symbols > 0 {
+		stats.HitRate = float64(stats.Fresh) / float64(stats.Symbols)
+	}
+	stats.Reclaimable = CollectGarbage(copied, symbols, false)
+	return stats
+}
+
+func staleAge(record Record, now time.Time) string {
+	written, err := time.Parse(time.RFC3339, recordTime(record))
+	if err != nil {
+		return "unknown"
+	}
+	age := now.Sub(written)
+	for _, bucket := range staleAgeLimits {
+		if age < bucket.max {
+			return bucket.name
+		}
+	}
+	return "older"
+}
@@ -0,0 +1,212 @@
+package enrich
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/morozRed/skelly/internal/fileutil"
+)
+
+// OverviewFile holds file and directory overviews, next to enrich.jsonl.
+const OverviewFile = "overview.jsonl"
+
+// Overview scopes and sources. Derived overviews are composed from the index
+// by `enrich overview`; agent overviews are written by hand and kept until
+// replaced.
+const (
+	OverviewScopeFile   = "file"
+	OverviewScopeModule = "module"
+
+	OverviewSourceDerived = "derived"
+	OverviewSourceAgent   = "agent"
+
+	// overviewTopSymbols bounds the key symbols an overview lists.
+	overviewTopSymbols = 5
+	// overviewImports bounds the imports a derived summary names.
+	overviewImports = 6
+)
+
+// Overview summarizes a file or a directory (module). InputHash identifies
+// the sources it was written against, so doctor can report stale ones.
+type Overview struct {
+	Scope      string   `json:"scope"`
+	Path       string   `json:"path"`
+	Summary    string   `json:"summary"`
+	Source     string   `json:"source"`
+	InputHash  string   `json:"input_hash"`
+	Files      int      `json:"files,omitempty"`
+	Symbols    int      `json:"symbols"`
+	TopSymbols []string `json:"top_symbols,omitempty"`
+	Imports    []string `json:"imports,omitempty"`
+	UpdatedAt  string   `json:"updated_at,omitempty"`
+}
+
+// OverviewSymbol is a symbol considered for an overview's key symbols.
+type OverviewSymbol struct {
+	Name    string
+	Kind    string
+	Rank    float64
+	Summary string
+}
+
+// OverviewInput is what a derived overview is composed from.
+type OverviewInput struct {
+	Scope   string
+	Path    string
+	Files   int
+	Symbols []OverviewSymbol
+	Imports []string
+}
+
+// OverviewKey identifies an overview in LoadOverviews' map.
+func OverviewKey(scope, path string) string {
+	return scope + ":" + path
+}
+
+// ModuleOf returns the directory (module) a file's overview rolls up into;
+// "." is the repository root.
+func ModuleOf(file string) string {
+	return path.Dir(file)
+}
+
+// ModuleInputHash hashes the hashes of a module's files, so the module's
+// overview goes stale when any of them changes.
+func ModuleInputHash(fileHashes map[string]string) string {
+	files := make([]string, 0, len(fileHashes))
+	for file := range fileHashes {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	h := sha256.New()
+	for _, file := range files {
+		fmt.Fprintf(h, "%s\x00%s\n", file, fileHashes[file])
+	}
+	return hex.EncodeToString(h.Sum(nil)[:8])
+}
+
+// DeriveOverview composes an overview from its input: the highest-ranked
+// symbols (with their enrich summaries when present) and the most common
+// imports.
+func DeriveOverview(input OverviewInput) Overview {
+	symbols := append([]OverviewSymbol(nil), input.Symbols...)
+	sort.SliceStable(symbols, func(i, j int) bool {
+		if symbols[i].Rank != symbols[j].Rank {
+			return symbols[i].Rank > symbols[j].Rank
+		}
+		return symbols[i].Name < symbols[j].Name
+	})
+	if len(symbols) > overviewTopSymbols {
+		symbols = symbols[:overviewTopSymbols]
+	}
+	imports := fileutil.DedupeStrings(input.Imports)
+	sort.Strings(imports)
+
+	overview := Overview{
+		Scope:   input.Scope,
+		Path:    input.Path,
+		Source:  OverviewSourceDerived,
+		Symbols: len(input.Symbols),
+		Imports: imports,
+	}
+	if input.Scope == OverviewScopeModule {
+		overview.Files = input.Files
+	}
+
+	var sb strings.Builder
+	if input.Scope == OverviewScopeModule {
+		sb.WriteString(countNoun(input.Files, "file") + ", ")
+	}
+	sb.WriteString(countNoun(len(input.Symbols), "symbol") + ".")
+	if len(symbols) > 0 {
+		parts := make([]string, 0, len(symbols))
+		for _, symbol := range symbols {
+			overview.TopSymbols = append(overview.TopSymbols, symbol.Name)
+			part := fmt.Sprintf("%s (%s)", symbol.Name, symbol.Kind)
+			if summary := strings.TrimRight(firstSentence(strings.Join(strings.Fields(symbol.Summary), " ")), ".!?"); summary != "" {
+				part += ": " + summary
+			}
+			parts = append(parts, part)
+		}
+		sb.WriteString(" Key: " + strings.Join(parts, "; ") + ".")
+	}
+	if len(imports) > 0 {
+		named := imports
+		if len(named) > overviewImports {
+			named = named[:overviewImports]
+		}
+		sb.WriteString(" Imports: " + strings.Join(named, ", "))
+		if len(imports) > len(named) {
+			fmt.Fprintf(&sb, " (+%d more)", len(imports)-len(named))
+		}
+		sb.WriteString(".")
+	}
+	overview.Summary = sb.String()
+	return overview
+}
+
+func countNoun(count int, noun string) string {
+	if count == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", count, noun)
+}
+
+// LoadOverviews reads overview.jsonl keyed by OverviewKey; a missing file is
+// empty.
+func LoadOverviews(path string) (map[string]Overview, error) {
+	overviews := make(map[string]Overview)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return overviews, nil
+		}
+		return nil, fmt.Errorf("failed to read overviews: %w", err)
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var overview Overview
+		if err := json.Unmarshal(line, &overview); err != nil {
+			continue
+		}
+		overviews[OverviewKey(overview.Scope, overview.Path)] = overview
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to parse overviews: %w", err)
+	}
+	return overviews, nil
+}
+
+// WriteOverviews writes overviews sorted by scope and path.
+func WriteOverviews(path string, overviews map[string]Overview) error {
+	records := make([]Overview, 0, len(overviews))
+	for _, overview := range overviews {
+		records = append(records, overview)
+	}
+	sort.Slice(records, func(i, j int) bool {
+		if records[i].Scope != records[j].Scope {
+			return records[i].Scope < records[j].Scope
+		}
+		return records[i].Path < records[j].Path
+	})
+	data, err := fileutil.EncodeJSONL(records)
+	if err != nil {
+		return fmt.Errorf("failed to encode overviews: %w", err)
+	}
+	if err := fileutil.WriteIfChanged(path, data); err != nil {
+		return fmt.Errorf("failed to write overviews: %w", err)
+	}
+	return nil
+}
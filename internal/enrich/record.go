@@ -1,23 +1,77 @@
 package enrich
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"sort"
 	"strings"
 
+	"github.com/morozRed/skelly/internal/fileutil"
 	"github.com/morozRed/skelly/internal/graph"
 	"github.com/morozRed/skelly/internal/parser"
 	"github.com/morozRed/skelly/internal/state"
 )
 
+// DefaultMaxBodyBytes is the default limit on the source body sent in an
+// enrich record; see TruncateBody.
+const DefaultMaxBodyBytes = 6000
+
+// Sources reads symbol bodies for enrich records, caching each file's
+// content and whether it still matches the hash it was indexed with.
+type Sources struct {
+	// MaxBodyBytes truncates longer bodies with TruncateBody; 0 keeps them
+	// whole.
+	MaxBodyBytes int
+
+	rootPath string
+	files    map[string]sourceFile
+}
+
+type sourceFile struct {
+	content []byte
+	hash    string
+}
+
+// NewSources reads files under rootPath.
+func NewSources(rootPath string, maxBodyBytes int) *Sources {
+	return &Sources{MaxBodyBytes: maxBodyBytes, rootPath: rootPath, files: make(map[string]sourceFile)}
+}
+
+func (s *Sources) read(file string) sourceFile {
+	source, ok := s.files[file]
+	if !ok {
+		// Unreadable files yield no content.
+		source.content, _ = os.ReadFile(filepath.Join(s.rootPath, file))
+		source.hash = fileutil.HashBytes(source.content)
+		s.files[file] = source
+	}
+	return source
+}
+
+// span returns the source of sym: its whole declaration when the parser
+// recorded its span and the file is unchanged since it was indexed, else its
+// first line.
+func (s *Sources) span(file string, fileState state.FileState, sym parser.Symbol) SourceSpan {
+	source := s.read(file)
+	span := SourceSpan{StartLine: sym.Line, EndLine: sym.EndLine}
+	if source.hash == fileState.Hash {
+		span.Body = sym.Body(source.content)
+	}
+	if span.Body == "" || span.EndLine < span.StartLine {
+		span.EndLine = sym.Line
+		span.Body = sourceLine(source.content, sym.Line)
+	}
+	span.Body, span.Truncated = TruncateBody(span.Body, s.MaxBodyBytes)
+	return span
+}
+
 func BuildRecord(
-	rootPath string,
+	sources *Sources,
 	file string,
 	fileState state.FileState,
 	sym parser.Symbol,
 	node *graph.Node,
-	lineCache map[string][]string,
 	agent string,
 	scope Scope,
 ) (Record, bool) {
@@ -25,7 +79,6 @@ func BuildRecord(
 		sym.ID = parser.StableSymbolID(file, sym)
 	}
 
-	sourceLine := ReadSourceLine(rootPath, file, sym.Line, lineCache)
 	calls := make([]string, 0)
 	calledBy := make([]string, 0)
 	if node != nil {
@@ -52,11 +105,7 @@ func BuildRecord(
 				Language:  fileState.Language,
 				Line:      sym.Line,
 			},
-			Source: SourceSpan{
-				StartLine: sym.Line,
-				EndLine:   sym.Line,
-				Body:      sourceLine,
-			},
+			Source:   sources.span(file, fileState, sym),
 			Imports:  append([]string(nil), fileState.Imports...),
 			Calls:    calls,
 			CalledBy: calledBy,
@@ -65,22 +114,46 @@ func BuildRecord(
 	return record, true
 }
 
-func ReadSourceLine(rootPath, file string, line int, lineCache map[string][]string) string {
+// sourceLine returns the trimmed text of a 1-based line of content.
+func sourceLine(content []byte, line int) string {
 	if line <= 0 {
 		return ""
 	}
-	lines, ok := lineCache[file]
-	if !ok {
-		data, err := os.ReadFile(filepath.Join(rootPath, file))
-		if err != nil {
-			lineCache[file] = nil
-			return ""
-		}
-		lines = strings.Split(string(data), "\n")
-		lineCache[file] = lines
-	}
+	lines := strings.Split(string(content), "\n")
 	if line > len(lines) {
 		return ""
 	}
 	return strings.TrimSpace(lines[line-1])
 }
+
+// TruncateBody shortens a body longer than maxBytes to its first lines, with
+// the signature, and its last lines, about two thirds of the budget going to
+// the head, separated by a line counting the omitted bytes. Lines are kept
+// whole; the first line is kept even when it alone exceeds the budget. It
+// reports whether the body was shortened; maxBytes <= 0 keeps it whole.
+func TruncateBody(body string, maxBytes int) (string, bool) {
+	if maxBytes <= 0 || len(body) <= maxBytes {
+		return body, false
+	}
+	lines := strings.SplitAfter(body, "\n")
+	headEnd, headBytes := 1, len(lines[0])
+	for headEnd < len(lines) && headBytes+len(lines[headEnd]) <= maxBytes*2/3 {
+		headBytes += len(lines[headEnd])
+		headEnd++
+	}
+	if headEnd == len(lines) {
+		// A single line longer than the budget.
+		return body, false
+	}
+	tailStart, tailBytes := len(lines), 0
+	for tailStart > headEnd && headBytes+tailBytes+len(lines[tailStart-1]) <= maxBytes {
+		tailStart--
+		tailBytes += len(lines[tailStart])
+	}
+	head := strings.Join(lines[:headEnd], "")
+	if !strings.HasSuffix(head, "\n") {
+		head += "\n"
+	}
+	omitted := len(body) - headBytes - tailBytes
+	return head + fmt.Sprintf("... (%d bytes omitted) ...\n", omitted) + strings.Join(lines[tailStart:], ""), true
+}
@@ -51,6 +51,9 @@ type SourceSpan struct {
 	StartLine int    `json:"start_line"`
 	EndLine   int    `json:"end_line"`
 	Body      string `json:"body,omitempty"`
+	// Truncated is set when Body was shortened to its head and tail (see
+	// TruncateBody).
+	Truncated bool `json:"truncated,omitempty"`
 }
 
 type Output struct {
@@ -7,7 +7,9 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/morozRed/skelly/internal/fileutil"
 	"github.com/morozRed/skelly/internal/graph"
+	"github.com/morozRed/skelly/internal/ignore"
 	"github.com/morozRed/skelly/internal/parser"
 	"github.com/morozRed/skelly/internal/state"
 )
@@ -120,3 +122,122 @@ func SummarizeMatches(items []WorkItem, limit int) string {
 	}
 	return strings.Join(names[:limit], ", ") + fmt.Sprintf(", ... (+%d more)", len(names)-limit)
 }
+
+// OrderWorkItems stably orders items by an importance metric of their graph
+// node, highest first, so a limited run covers the most central symbols.
+func OrderWorkItems(items []WorkItem, metric graph.Metric) {
+	value := func(item WorkItem) float64 {
+		if item.Node == nil {
+			return 0
+		}
+		return item.Node.MetricValue(metric)
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return value(items[i]) > value(items[j])
+	})
+}
+
+// WorkFilter narrows a batch run to a subsystem. Each set field must match;
+// within a field any value may.
+type WorkFilter struct {
+	// Paths are gitignore-style patterns over file paths, where ** spans
+	// directories (internal/graph/**).
+	Paths []ignore.Pattern
+	// Symbols are symbol IDs, names or qualified names.
+	Symbols map[string]bool
+	// Kinds are symbol kinds as the index prints them (func, method, struct).
+	Kinds map[string]bool
+	// Visibilities are symbol visibilities (public, protected, private);
+	// symbols whose language records none never match.
+	Visibilities map[string]bool
+}
+
+// kindAliases maps spelled-out kind names to the ones the index uses.
+var kindAliases = map[string]string{
+	"function": "func",
+	"constant": "const",
+	"variable": "var",
+}
+
+// NewWorkFilter parses --path, --symbol, --kind and --visibility values.
+func NewWorkFilter(paths, symbols, kinds, visibilities []string) (WorkFilter, error) {
+	filter := WorkFilter{}
+	for _, value := range paths {
+		value = strings.TrimPrefix(filepath.ToSlash(strings.TrimSpace(value)), "./")
+		if value == "" {
+			continue
+		}
+		pattern, ok := ignore.ParsePattern(value)
+		if !ok {
+			return filter, fmt.Errorf("invalid --path pattern %q", value)
+		}
+		filter.Paths = append(filter.Paths, pattern)
+	}
+	for _, value := range symbols {
+		if value = strings.TrimSpace(value); value != "" {
+			if filter.Symbols == nil {
+				filter.Symbols = make(map[string]bool)
+			}
+			filter.Symbols[value] = true
+		}
+	}
+	known := make(map[string]bool)
+	for kind := parser.SymbolFunction; kind <= parser.SymbolRoute; kind++ {
+		known[kind.String()] = true
+	}
+	for _, value := range kinds {
+		value = strings.ToLower(strings.TrimSpace(value))
+		if alias, ok := kindAliases[value]; ok {
+			value = alias
+		}
+		if value == "" {
+			continue
+		}
+		if !known[value] {
+			return filter, fmt.Errorf("unknown --kind %q (use %s)", value, strings.Join(fileutil.MapKeysSorted(known), ", "))
+		}
+		if filter.Kinds == nil {
+			filter.Kinds = make(map[string]bool)
+		}
+		filter.Kinds[value] = true
+	}
+	var err error
+	if filter.Visibilities, err = parser.ParseVisibilities(visibilities); err != nil {
+		return filter, fmt.Errorf("--visibility: %w", err)
+	}
+	return filter, nil
+}
+
+// Match reports whether a work item passes the filter.
+func (f WorkFilter) Match(item WorkItem) bool {
+	if len(f.Paths) > 0 {
+		matched := false
+		for _, pattern := range f.Paths {
+			if pattern.Matches(item.File) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	if f.Symbols != nil && !f.Symbols[item.Symbol.ID] && !f.Symbols[item.Symbol.Name] && !f.Symbols[item.Symbol.QualifiedName()] {
+		return false
+	}
+	if f.Kinds != nil && !f.Kinds[item.Symbol.Kind.String()] {
+		return false
+	}
+	return f.Visibilities == nil || f.Visibilities[item.Symbol.Visibility]
+}
+
+// Apply returns the items passing the filter, in order.
+func (f WorkFilter) Apply(items []WorkItem) []WorkItem {
+	filtered := make([]WorkItem, 0, len(items))
+	for _, item := range items {
+		if f.Match(item) {
+			filtered = append(filtered, item)
+		}
+	}
+	return filtered
+}
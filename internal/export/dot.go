@@ -0,0 +1,81 @@
+package export
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// dotEdgeStyles maps edge confidence to a Graphviz line style.
+var dotEdgeStyles = map[string]string{
+	"resolved":  `style=solid`,
+	"heuristic": `style=dashed`,
+	"ambiguous": `style=dotted, color="gray50"`,
+}
+
+// RenderDOT renders v as a Graphviz digraph. Node font size and width grow
+// with PageRank relative to the highest-ranked node in the view, edge pen
+// width grows with the number of aggregated symbol edges, and edge style
+// follows confidence. Symbol views cluster nodes by file.
+func RenderDOT(v View) string {
+	var sb strings.Builder
+	sb.WriteString("digraph skelly {\n")
+	sb.WriteString("  rankdir=LR;\n")
+	sb.WriteString(`  node [shape=box, style="rounded,filled", fillcolor="#eef3fb", fontname="Helvetica"];` + "\n")
+	sb.WriteString(`  edge [fontname="Helvetica", fontsize=9];` + "\n")
+
+	maxRank := v.maxRank()
+	writeNode := func(indent string, node Node) {
+		scale := 0.0
+		if maxRank > 0 {
+			scale = node.Rank / maxRank
+		}
+		fmt.Fprintf(&sb, "%s%s [label=%s, fontsize=%.1f, width=%.2f, tooltip=%s];\n",
+			indent, dotQuote(node.ID), dotQuote(node.Label), 10+8*scale, 0.75+1.5*scale, dotQuote(fmt.Sprintf("rank=%.4f", node.Rank)))
+	}
+
+	if v.Scope == ScopeSymbol {
+		groups := make([]string, 0)
+		byGroup := make(map[string][]Node)
+		for _, node := range v.Nodes {
+			if _, ok := byGroup[node.Group]; !ok {
+				groups = append(groups, node.Group)
+			}
+			byGroup[node.Group] = append(byGroup[node.Group], node)
+		}
+		for i, group := range groups {
+			fmt.Fprintf(&sb, "  subgraph cluster_%d {\n", i)
+			fmt.Fprintf(&sb, "    label=%s;\n", dotQuote(group))
+			for _, node := range byGroup[group] {
+				writeNode("    ", node)
+			}
+			sb.WriteString("  }\n")
+		}
+	} else {
+		for _, node := range v.Nodes {
+			writeNode("  ", node)
+		}
+	}
+
+	for _, edge := range v.Edges {
+		style, ok := dotEdgeStyles[edge.Confidence]
+		if !ok {
+			style = dotEdgeStyles["heuristic"]
+		}
+		attrs := []string{style, fmt.Sprintf("penwidth=%.2f", 1+math.Log2(float64(edge.Weight)))}
+		if edge.Weight > 1 {
+			attrs = append(attrs, fmt.Sprintf("label=%q", fmt.Sprint(edge.Weight)))
+		}
+		fmt.Fprintf(&sb, "  %s -> %s [%s];\n", dotQuote(edge.From), dotQuote(edge.To), strings.Join(attrs, ", "))
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// dotQuote returns value as a DOT double-quoted ID.
+func dotQuote(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `"`, `\"`)
+	value = strings.ReplaceAll(value, "\n", `\n`)
+	return `"` + value + `"`
+}
@@ -0,0 +1,185 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/morozRed/skelly/internal/graph"
+	"github.com/morozRed/skelly/internal/parser"
+)
+
+func testGraph() *graph.Graph {
+	return graph.BuildFromParseResult(&parser.ParseResult{
+		Files: []parser.FileSymbols{
+			{
+				Path:     "cmd/app/main.go",
+				Language: "go",
+				Symbols: []parser.Symbol{
+					{Name: "main", Kind: parser.SymbolFunction, Line: 1, Calls: []parser.CallSite{{Name: "Load"}, {Name: "Save"}}},
+				},
+			},
+			{
+				Path:     "store/store.go",
+				Language: "go",
+				Symbols: []parser.Symbol{
+					{Name: "Load", Kind: parser.SymbolFunction, Line: 1, Calls: []parser.CallSite{{Name: "Save"}}},
+					{Name: "Save", Kind: parser.SymbolFunction, Line: 5},
+				},
+			},
+			{
+				Path:     "util/log.go",
+				Language: "go",
+				Symbols: []parser.Symbol{
+					{Name: "Log", Kind: parser.SymbolFunction, Line: 1},
+				},
+			},
+		},
+	})
+}
+
+func TestBuildAggregatesEdgesAtModuleScope(t *testing.T) {
+	view := Build(testGraph(), ScopeModule)
+	if len(view.Nodes) != 3 || view.Nodes[0].ID != "cmd/app" || view.Nodes[1].ID != "store" {
+		t.Fatalf("unexpected module nodes: %#v", view.Nodes)
+	}
+	if len(view.Edges) != 1 || view.Edges[0].From != "cmd/app" || view.Edges[0].To != "store" || view.Edges[0].Weight != 2 {
+		t.Fatalf("expected one cmd/app -> store edge of weight 2 (intra-module edge dropped), got %#v", view.Edges)
+	}
+
+	focused := view.Focus("store", 1)
+	if len(focused.Nodes) != 2 || len(focused.Edges) != 1 {
+		t.Fatalf("expected focus to drop the unconnected util module, got %#v", focused)
+	}
+}
+
+func TestResolveMatchesLabelsAndRejectsAmbiguity(t *testing.T) {
+	view := Build(testGraph(), ScopeSymbol)
+	id, err := view.Resolve("Load")
+	if err != nil || !strings.HasPrefix(id, "store/store.go|") {
+		t.Fatalf("expected Load to resolve to its symbol ID, got %q, %v", id, err)
+	}
+	if _, err := view.Resolve("Missing"); err == nil {
+		t.Fatalf("expected unknown label to fail")
+	}
+}
+
+func TestRenderDOTStylesEdgesAndClustersSymbols(t *testing.T) {
+	dot := RenderDOT(Build(testGraph(), ScopeSymbol))
+	for _, want := range []string{
+		"digraph skelly {",
+		"subgraph cluster_1 {",
+		`label="store/store.go";`,
+		`[label="Save", fontsize=`,
+		"penwidth=1.00",
+		"style=solid", // Load -> Save resolves within its file
+	} {
+		if !strings.Contains(dot, want) {
+			t.Fatalf("expected DOT output to contain %q, got:\n%s", want, dot)
+		}
+	}
+	if got := dotQuote(`a "b"\c`); got != `"a \"b\"\\c"` {
+		t.Fatalf("unexpected quoting: %s", got)
+	}
+}
+
+func TestRenderMermaidKeepsTopRankedNodes(t *testing.T) {
+	view := Build(testGraph(), ScopeSymbol).Top(2)
+	if len(view.Nodes) != 2 {
+		t.Fatalf("expected two nodes after Top(2), got %#v", view.Nodes)
+	}
+	mermaid := RenderMermaid(view)
+	for _, want := range []string{
+		"```mermaid\nflowchart LR\n",
+		`subgraph g0["store/store.go"]`,
+		`n0["Load"]`,
+		`n1["Save"]`,
+		"n0 --> n1",
+	} {
+		if !strings.Contains(mermaid, want) {
+			t.Fatalf("expected Mermaid output to contain %q, got:\n%s", want, mermaid)
+		}
+	}
+	if strings.Contains(mermaid, "main") {
+		t.Fatalf("expected lower-ranked main to be dropped, got:\n%s", mermaid)
+	}
+	if got := mermaidQuote(`say "hi"`); got != `"say #quot;hi#quot;"` {
+		t.Fatalf("unexpected Mermaid quoting: %s", got)
+	}
+}
+
+func TestWriteLSIFEmitsDefinitionsReferencesAndMonikers(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "main.go"), []byte("package main\n\nfunc main() {\n\tmsg := \"héllo\"; Load(msg)\n}\n\nfunc Load(s string) {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	g := graph.BuildFromParseResult(&parser.ParseResult{
+		Files: []parser.FileSymbols{{
+			Path:     "main.go",
+			Language: "go",
+			Symbols: []parser.Symbol{
+				{Name: "main", Kind: parser.SymbolFunction, Line: 3, Calls: []parser.CallSite{{Name: "Load", Line: 4}}},
+				{Name: "Load", Kind: parser.SymbolFunction, Line: 7, Signature: "func Load(s string)"},
+			},
+		}},
+	})
+
+	var buf bytes.Buffer
+	if err := WriteLSIF(&buf, g, root); err != nil {
+		t.Fatalf("WriteLSIF failed: %v", err)
+	}
+	elements := make([]map[string]any, 0)
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var element map[string]any
+		if err := json.Unmarshal([]byte(line), &element); err != nil {
+			t.Fatalf("invalid LSIF line %q: %v", line, err)
+		}
+		elements = append(elements, element)
+	}
+	if elements[0]["label"] != "metaData" || elements[1]["label"] != "project" || elements[1]["kind"] != "go" {
+		t.Fatalf("expected metaData and project first, got %v %v", elements[0], elements[1])
+	}
+
+	seen := make(map[float64]bool)
+	definitions, references, monikers := 0, 0, 0
+	for _, element := range elements {
+		for _, key := range []string{"outV", "inV"} {
+			if id, ok := element[key].(float64); ok && !seen[id] {
+				t.Fatalf("edge %v references vertex %v before it is emitted", element, id)
+			}
+		}
+		seen[element["id"].(float64)] = true
+		if element["type"] != "vertex" {
+			continue
+		}
+		switch element["label"] {
+		case "moniker":
+			monikers++
+			if element["scheme"] != LSIFMonikerScheme || !strings.HasPrefix(element["identifier"].(string), "main.go|") {
+				t.Fatalf("unexpected moniker %v", element)
+			}
+		case "range":
+			tag := element["tag"].(map[string]any)
+			start := element["start"].(map[string]any)
+			switch tag["type"] {
+			case "definition":
+				definitions++
+				if tag["text"] == "Load" && (start["line"] != 6.0 || start["character"] != 5.0) {
+					t.Fatalf("expected Load definition at 6:5, got %v", start)
+				}
+			case "reference":
+				references++
+				// "\tmsg := \"héllo\"; " is 17 UTF-16 units (18 bytes).
+				if start["line"] != 3.0 || start["character"] != 17.0 {
+					t.Fatalf("expected Load reference at 3:17, got %v", start)
+				}
+			}
+		}
+	}
+	if definitions != 2 || references != 1 || monikers != 2 {
+		t.Fatalf("expected 2 definitions, 1 reference and 2 monikers, got %d, %d, %d", definitions, references, monikers)
+	}
+}
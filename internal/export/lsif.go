@@ -0,0 +1,350 @@
+package export
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/morozRed/skelly/internal/graph"
+	"github.com/morozRed/skelly/internal/parser"
+)
+
+// LSIFVersion is the LSIF protocol version WriteLSIF emits.
+const LSIFVersion = "0.4.3"
+
+// LSIFMonikerScheme namespaces skelly symbol IDs when used as LSIF monikers.
+const LSIFMonikerScheme = "skelly"
+
+// lsifNameSearchLines is how many lines past a symbol's recorded line are
+// searched for its name (decorators and attributes can precede it).
+const lsifNameSearchLines = 3
+
+type lsifPosition struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+type lsifRange struct {
+	start, end lsifPosition
+}
+
+// lsifEmitter assigns ids and writes one LSIF element per line.
+type lsifEmitter struct {
+	encoder *json.Encoder
+	nextID  int
+	err     error
+}
+
+func (e *lsifEmitter) emit(element map[string]any) int {
+	e.nextID++
+	element["id"] = e.nextID
+	if e.err == nil {
+		e.err = e.encoder.Encode(element)
+	}
+	return e.nextID
+}
+
+func (e *lsifEmitter) vertex(label string, fields map[string]any) int {
+	fields["type"] = "vertex"
+	fields["label"] = label
+	return e.emit(fields)
+}
+
+func (e *lsifEmitter) edge(label string, outV int, inVs []int, fields map[string]any) {
+	if fields == nil {
+		fields = make(map[string]any)
+	}
+	fields["type"] = "edge"
+	fields["label"] = label
+	fields["outV"] = outV
+	if len(inVs) == 1 && label != "contains" && label != "item" {
+		fields["inV"] = inVs[0]
+	} else {
+		fields["inVs"] = inVs
+	}
+	e.emit(fields)
+}
+
+// WriteLSIF writes an LSIF dump of g: one document per indexed file, a
+// definition range, hover (signature and doc) and moniker per symbol, and
+// reference ranges at call sites whose edge resolved to a symbol. Monikers
+// use the skelly scheme with the stable symbol ID as identifier. Columns are
+// found by locating names in the source under rootPath, in UTF-16 units.
+func WriteLSIF(w io.Writer, g *graph.Graph, rootPath string) error {
+	buffer := bufio.NewWriter(w)
+	encoder := json.NewEncoder(buffer)
+	encoder.SetEscapeHTML(false)
+	e := &lsifEmitter{encoder: encoder}
+
+	e.vertex("metaData", map[string]any{
+		"version":          LSIFVersion,
+		"projectRoot":      fileURI(rootPath),
+		"positionEncoding": "utf-16",
+		"toolInfo":         map[string]any{"name": "skelly"},
+	})
+	project := e.vertex("project", map[string]any{"kind": dominantLanguage(g)})
+
+	files := g.Files()
+	sources := make(map[string][]string, len(files))
+	for _, file := range files {
+		sources[file] = readSourceLines(filepath.Join(rootPath, filepath.FromSlash(file)))
+	}
+
+	// Definitions first, so every reference can point at an emitted result set.
+	resultSets := make(map[string]int, len(g.Nodes))
+	definitionRanges := make(map[string]int, len(g.Nodes))
+	documents := make(map[string]int, len(files))
+	documentIDs := make([]int, 0, len(files))
+	for _, file := range files {
+		nodes := g.NodesForFile(file)
+		language := ""
+		if len(nodes) > 0 {
+			language = nodes[0].Language
+		}
+		document := e.vertex("document", map[string]any{
+			"uri":        fileURI(filepath.Join(rootPath, filepath.FromSlash(file))),
+			"languageId": language,
+		})
+		documents[file] = document
+		documentIDs = append(documentIDs, document)
+
+		ranges := make([]int, 0, len(nodes))
+		for _, node := range nodes {
+			span := locateName(sources[file], node.Symbol.Line, node.Symbol.Name, lsifNameSearchLines)
+			resultSet := e.vertex("resultSet", map[string]any{})
+			rangeID := e.vertex("range", map[string]any{
+				"start": span.start,
+				"end":   span.end,
+				"tag": map[string]any{
+					"type": "definition",
+					"text": node.Symbol.Name,
+					"kind": lsifSymbolKind(node.Symbol.Kind),
+					"fullRange": map[string]any{
+						"start": span.start,
+						"end":   span.end,
+					},
+				},
+			})
+			e.edge("next", rangeID, []int{resultSet}, nil)
+
+			moniker := e.vertex("moniker", map[string]any{
+				"scheme":     LSIFMonikerScheme,
+				"identifier": node.ID,
+				"kind":       "export",
+			})
+			e.edge("moniker", resultSet, []int{moniker}, nil)
+
+			hover := e.vertex("hoverResult", map[string]any{"result": map[string]any{"contents": hoverContents(node)}})
+			e.edge("textDocument/hover", resultSet, []int{hover}, nil)
+
+			definition := e.vertex("definitionResult", map[string]any{})
+			e.edge("textDocument/definition", resultSet, []int{definition}, nil)
+			e.edge("item", definition, []int{rangeID}, map[string]any{"document": document})
+
+			resultSets[node.ID] = resultSet
+			definitionRanges[node.ID] = rangeID
+			ranges = append(ranges, rangeID)
+		}
+		if len(ranges) > 0 {
+			e.edge("contains", document, ranges, nil)
+		}
+	}
+	if len(documentIDs) > 0 {
+		e.edge("contains", project, documentIDs, nil)
+	}
+
+	// References: one range per call site whose name matches a resolved edge target.
+	referencesByTarget := make(map[string]map[string][]int)
+	for _, file := range files {
+		referenceRanges := make([]int, 0)
+		for _, node := range g.NodesForFile(file) {
+			targetsByName := make(map[string][]string)
+			for _, targetID := range node.OutEdgesOfKind(graph.EdgeCall) {
+				if target, ok := g.Nodes[targetID]; ok {
+					targetsByName[target.Symbol.Name] = append(targetsByName[target.Symbol.Name], targetID)
+				}
+			}
+			for _, call := range node.Symbol.Calls {
+				if call.Line == 0 {
+					continue
+				}
+				for _, targetID := range targetsByName[call.Name] {
+					span := locateName(sources[file], call.Line, call.Name, 0)
+					rangeID := e.vertex("range", map[string]any{
+						"start": span.start,
+						"end":   span.end,
+						"tag":   map[string]any{"type": "reference", "text": call.Name},
+					})
+					e.edge("next", rangeID, []int{resultSets[targetID]}, nil)
+					referenceRanges = append(referenceRanges, rangeID)
+					if referencesByTarget[targetID] == nil {
+						referencesByTarget[targetID] = make(map[string][]int)
+					}
+					referencesByTarget[targetID][file] = append(referencesByTarget[targetID][file], rangeID)
+				}
+			}
+		}
+		if len(referenceRanges) > 0 {
+			e.edge("contains", documents[file], referenceRanges, nil)
+		}
+	}
+
+	targetIDs := make([]string, 0, len(resultSets))
+	for id := range resultSets {
+		targetIDs = append(targetIDs, id)
+	}
+	sort.Strings(targetIDs)
+	for _, targetID := range targetIDs {
+		target := g.Nodes[targetID]
+		referenceResult := e.vertex("referenceResult", map[string]any{})
+		e.edge("textDocument/references", resultSets[targetID], []int{referenceResult}, nil)
+		e.edge("item", referenceResult, []int{definitionRanges[targetID]}, map[string]any{
+			"document": documents[target.File],
+			"property": "definitions",
+		})
+		byFile := referencesByTarget[targetID]
+		referenceFiles := make([]string, 0, len(byFile))
+		for file := range byFile {
+			referenceFiles = append(referenceFiles, file)
+		}
+		sort.Strings(referenceFiles)
+		for _, file := range referenceFiles {
+			e.edge("item", referenceResult, byFile[file], map[string]any{
+				"document": documents[file],
+				"property": "references",
+			})
+		}
+	}
+
+	if e.err != nil {
+		return e.err
+	}
+	return buffer.Flush()
+}
+
+func hoverContents(node *graph.Node) []any {
+	contents := make([]any, 0, 2)
+	signature := node.Symbol.Signature
+	if signature == "" {
+		signature = node.Symbol.Name
+	}
+	contents = append(contents, map[string]any{"language": node.Language, "value": signature})
+	if node.Symbol.Doc != "" {
+		contents = append(contents, node.Symbol.Doc)
+	}
+	return contents
+}
+
+// lsifSymbolKind maps skelly kinds to LSP SymbolKind numbers.
+func lsifSymbolKind(kind parser.SymbolKind) int {
+	switch kind {
+	case parser.SymbolModule:
+		return 2
+	case parser.SymbolClass:
+		return 5
+	case parser.SymbolMethod:
+		return 6
+	case parser.SymbolInterface:
+		return 11
+	case parser.SymbolVariable:
+		return 13
+	case parser.SymbolConstant:
+		return 14
+	case parser.SymbolStruct:
+		return 23
+	default:
+		return 12 // function
+	}
+}
+
+func dominantLanguage(g *graph.Graph) string {
+	counts := make(map[string]int)
+	for _, file := range g.Files() {
+		if nodes := g.NodesForFile(file); len(nodes) > 0 {
+			counts[nodes[0].Language]++
+		}
+	}
+	best, bestCount := "", 0
+	for language, count := range counts {
+		if count > bestCount || (count == bestCount && language < best) {
+			best, bestCount = language, count
+		}
+	}
+	return best
+}
+
+func fileURI(path string) string {
+	absolute, err := filepath.Abs(path)
+	if err != nil {
+		absolute = path
+	}
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(absolute)}).String()
+}
+
+func readSourceLines(path string) []string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	return strings.Split(string(data), "\n")
+}
+
+// locateName finds name as a whole identifier on the 1-based line or up to
+// extraLines after it. When it cannot be found the range is empty at the
+// start of the recorded line.
+func locateName(lines []string, line int, name string, extraLines int) lsifRange {
+	for offset := 0; offset <= extraLines; offset++ {
+		index := line - 1 + offset
+		if index < 0 || index >= len(lines) {
+			break
+		}
+		if column, ok := identifierColumn(lines[index], name); ok {
+			start := lsifPosition{Line: index, Character: utf16Length(lines[index][:column])}
+			end := lsifPosition{Line: index, Character: start.Character + utf16Length(name)}
+			return lsifRange{start: start, end: end}
+		}
+	}
+	position := lsifPosition{Line: max(line-1, 0)}
+	return lsifRange{start: position, end: position}
+}
+
+func identifierColumn(text, name string) (int, bool) {
+	if name == "" {
+		return 0, false
+	}
+	for from := 0; from < len(text); {
+		index := strings.Index(text[from:], name)
+		if index < 0 {
+			return 0, false
+		}
+		start := from + index
+		end := start + len(name)
+		before, _ := utf8.DecodeLastRuneInString(text[:start])
+		after, _ := utf8.DecodeRuneInString(text[end:])
+		if (start == 0 || !isIdentifierRune(before)) && (end == len(text) || !isIdentifierRune(after)) {
+			return start, true
+		}
+		from = start + 1
+	}
+	return 0, false
+}
+
+func isIdentifierRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func utf16Length(text string) int {
+	length := 0
+	for _, r := range text {
+		length += utf16.RuneLen(r)
+	}
+	return length
+}
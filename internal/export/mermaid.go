@@ -0,0 +1,67 @@
+package export
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RenderMermaid renders v as a fenced Mermaid flowchart for Markdown.
+// Resolved edges are solid and heuristic or ambiguous edges dotted; edges
+// that aggregate several symbol calls are labeled with the count. Symbol
+// views group nodes into one subgraph per file.
+func RenderMermaid(v View) string {
+	var sb strings.Builder
+	sb.WriteString("```mermaid\n")
+	sb.WriteString("flowchart LR\n")
+
+	ids := make(map[string]string, len(v.Nodes))
+	for i, node := range v.Nodes {
+		ids[node.ID] = fmt.Sprintf("n%d", i)
+	}
+	writeNode := func(indent string, node Node) {
+		fmt.Fprintf(&sb, "%s%s[%s]\n", indent, ids[node.ID], mermaidQuote(node.Label))
+	}
+
+	if v.Scope == ScopeSymbol {
+		groups := make([]string, 0)
+		byGroup := make(map[string][]Node)
+		for _, node := range v.Nodes {
+			if _, ok := byGroup[node.Group]; !ok {
+				groups = append(groups, node.Group)
+			}
+			byGroup[node.Group] = append(byGroup[node.Group], node)
+		}
+		for i, group := range groups {
+			fmt.Fprintf(&sb, "  subgraph g%d[%s]\n", i, mermaidQuote(group))
+			for _, node := range byGroup[group] {
+				writeNode("    ", node)
+			}
+			sb.WriteString("  end\n")
+		}
+	} else {
+		for _, node := range v.Nodes {
+			writeNode("  ", node)
+		}
+	}
+
+	for _, edge := range v.Edges {
+		arrow := "-->"
+		if edge.Confidence != "resolved" {
+			arrow = "-.->"
+		}
+		if edge.Weight > 1 {
+			arrow += fmt.Sprintf("|%d|", edge.Weight)
+		}
+		fmt.Fprintf(&sb, "  %s %s %s\n", ids[edge.From], arrow, ids[edge.To])
+	}
+	sb.WriteString("```\n")
+	return sb.String()
+}
+
+// mermaidQuote returns label as a quoted Mermaid string, using entity codes
+// for characters Mermaid would otherwise parse.
+func mermaidQuote(label string) string {
+	label = strings.ReplaceAll(label, `"`, "#quot;")
+	label = strings.ReplaceAll(label, "\n", " ")
+	return `"` + label + `"`
+}
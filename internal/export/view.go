@@ -0,0 +1,251 @@
+// Package export renders the dependency graph for external viewers, as
+// Graphviz DOT or Mermaid at symbol, file or module granularity, or as an
+// LSIF index for code-intelligence tooling.
+package export
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/morozRed/skelly/internal/graph"
+)
+
+// Scope is the granularity of an exported view.
+type Scope string
+
+const (
+	ScopeSymbol Scope = "symbol"
+	ScopeFile   Scope = "file"
+	ScopeModule Scope = "module"
+)
+
+// ParseScope validates a --scope value; empty selects the module scope.
+func ParseScope(raw string) (Scope, error) {
+	scope := Scope(strings.ToLower(strings.TrimSpace(raw)))
+	switch scope {
+	case "":
+		return ScopeModule, nil
+	case ScopeSymbol, ScopeFile, ScopeModule:
+		return scope, nil
+	default:
+		return "", fmt.Errorf("unsupported scope %q (supported: symbol, file, module)", raw)
+	}
+}
+
+// Node is one vertex of a view. Rank is the summed PageRank of the symbols
+// it covers; Group is the enclosing file (symbol scope) or directory.
+type Node struct {
+	ID    string
+	Label string
+	Group string
+	Rank  float64
+}
+
+// Edge is a call dependency between two nodes. Weight counts the symbol
+// edges it aggregates and Confidence is their most common confidence.
+type Edge struct {
+	From       string
+	To         string
+	Weight     int
+	Confidence string
+}
+
+// View is a rendered subset of the graph at one scope.
+type View struct {
+	Scope Scope
+	Nodes []Node
+	Edges []Edge
+}
+
+// confidenceOrder breaks ties between equally common confidences toward the stronger one.
+var confidenceOrder = map[string]int{"resolved": 0, "heuristic": 1, "ambiguous": 2}
+
+// Build aggregates g at scope. Self-edges of aggregated nodes are dropped.
+func Build(g *graph.Graph, scope Scope) View {
+	keyOf := func(node *graph.Node) string {
+		switch scope {
+		case ScopeSymbol:
+			return node.ID
+		case ScopeFile:
+			return node.File
+		default:
+			return path.Dir(node.File)
+		}
+	}
+
+	nodes := make(map[string]*Node)
+	counts := make(map[[2]string]map[string]int)
+	for _, file := range g.Files() {
+		for _, node := range g.NodesForFile(file) {
+			key := keyOf(node)
+			entry, ok := nodes[key]
+			if !ok {
+				entry = &Node{ID: key}
+				switch scope {
+				case ScopeSymbol:
+					entry.Label = node.Symbol.Name
+					entry.Group = node.File
+				case ScopeFile:
+					entry.Label = node.File
+					entry.Group = path.Dir(node.File)
+				default:
+					entry.Label = key
+				}
+				nodes[key] = entry
+			}
+			entry.Rank += node.PageRank
+
+			for _, targetID := range node.OutEdges {
+				target, ok := g.Nodes[targetID]
+				if !ok {
+					continue
+				}
+				targetKey := keyOf(target)
+				if targetKey == key && scope != ScopeSymbol {
+					continue
+				}
+				pair := [2]string{key, targetKey}
+				if counts[pair] == nil {
+					counts[pair] = make(map[string]int)
+				}
+				confidence := node.OutEdgeConfidence[targetID]
+				if confidence == "" {
+					confidence = "heuristic"
+				}
+				counts[pair][confidence]++
+			}
+		}
+	}
+
+	view := View{Scope: scope, Nodes: make([]Node, 0, len(nodes)), Edges: make([]Edge, 0, len(counts))}
+	for _, node := range nodes {
+		view.Nodes = append(view.Nodes, *node)
+	}
+	for pair, byConfidence := range counts {
+		edge := Edge{From: pair[0], To: pair[1]}
+		best := 0
+		for confidence, count := range byConfidence {
+			edge.Weight += count
+			if count > best || (count == best && confidenceOrder[confidence] < confidenceOrder[edge.Confidence]) {
+				best = count
+				edge.Confidence = confidence
+			}
+		}
+		view.Edges = append(view.Edges, edge)
+	}
+	view.sort()
+	return view
+}
+
+// Resolve finds the node for a --focus query: an exact node ID, or a label
+// (symbol name, file path, directory) that matches exactly one node.
+func (v View) Resolve(query string) (string, error) {
+	query = strings.TrimSpace(query)
+	matches := make([]string, 0)
+	for _, node := range v.Nodes {
+		if node.ID == query {
+			return node.ID, nil
+		}
+		if node.Label == query {
+			matches = append(matches, node.ID)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("no %s node matches %q", v.Scope, query)
+	case 1:
+		return matches[0], nil
+	default:
+		count := len(matches)
+		if count > 5 {
+			matches = append(matches[:5], "...")
+		}
+		return "", fmt.Errorf("%q matches %d %s nodes; use an ID: %s", query, count, v.Scope, strings.Join(matches, ", "))
+	}
+}
+
+// Focus keeps the nodes within depth edges of id, following edges in both
+// directions, and the edges among them.
+func (v View) Focus(id string, depth int) View {
+	neighbors := make(map[string][]string)
+	for _, edge := range v.Edges {
+		neighbors[edge.From] = append(neighbors[edge.From], edge.To)
+		neighbors[edge.To] = append(neighbors[edge.To], edge.From)
+	}
+	keep := map[string]int{id: 0}
+	queue := []string{id}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if keep[current] >= depth {
+			continue
+		}
+		for _, next := range neighbors[current] {
+			if _, seen := keep[next]; !seen {
+				keep[next] = keep[current] + 1
+				queue = append(queue, next)
+			}
+		}
+	}
+	return v.filter(func(id string) bool {
+		_, ok := keep[id]
+		return ok
+	})
+}
+
+// Top keeps the n highest-ranked nodes (ties by ID) and the edges among
+// them; n <= 0 keeps everything.
+func (v View) Top(n int) View {
+	if n <= 0 || n >= len(v.Nodes) {
+		return v
+	}
+	ranked := append([]Node(nil), v.Nodes...)
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].Rank != ranked[j].Rank {
+			return ranked[i].Rank > ranked[j].Rank
+		}
+		return ranked[i].ID < ranked[j].ID
+	})
+	keep := make(map[string]bool, n)
+	for _, node := range ranked[:n] {
+		keep[node.ID] = true
+	}
+	return v.filter(func(id string) bool { return keep[id] })
+}
+
+func (v View) filter(keep func(id string) bool) View {
+	out := View{Scope: v.Scope, Nodes: make([]Node, 0), Edges: make([]Edge, 0)}
+	for _, node := range v.Nodes {
+		if keep(node.ID) {
+			out.Nodes = append(out.Nodes, node)
+		}
+	}
+	for _, edge := range v.Edges {
+		if keep(edge.From) && keep(edge.To) {
+			out.Edges = append(out.Edges, edge)
+		}
+	}
+	return out
+}
+
+func (v View) sort() {
+	sort.Slice(v.Nodes, func(i, j int) bool { return v.Nodes[i].ID < v.Nodes[j].ID })
+	sort.Slice(v.Edges, func(i, j int) bool {
+		if v.Edges[i].From != v.Edges[j].From {
+			return v.Edges[i].From < v.Edges[j].From
+		}
+		return v.Edges[i].To < v.Edges[j].To
+	})
+}
+
+func (v View) maxRank() float64 {
+	maxRank := 0.0
+	for _, node := range v.Nodes {
+		if node.Rank > maxRank {
+			maxRank = node.Rank
+		}
+	}
+	return maxRank
+}
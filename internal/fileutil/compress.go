@@ -0,0 +1,295 @@
+package fileutil
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Compressed artifacts are stored next to their plain name with the suffix
+// of their compression (symbols.jsonl.gz, symbols.jsonl.zst).
+const (
+	GzipSuffix = ".gz"
+	ZstdSuffix = ".zst"
+)
+
+// CompressedSuffixes lists the suffixes of every compressed variant.
+var CompressedSuffixes = []string{GzipSuffix, ZstdSuffix}
+
+// Compression selects how large context artifacts are stored.
+type Compression string
+
+const (
+	CompressionNone Compression = "none"
+	CompressionGzip Compression = "gzip"
+	CompressionZstd Compression = "zstd"
+)
+
+// Suffix returns the file name suffix of an artifact stored with the
+// compression, "" for none.
+func (c Compression) Suffix() string {
+	switch c {
+	case CompressionGzip:
+		return GzipSuffix
+	case CompressionZstd:
+		return ZstdSuffix
+	}
+	return ""
+}
+
+// artifactCompression is the compression requested for this run; empty
+// keeps each artifact the way it is on disk.
+var artifactCompression Compression
+
+// ParseCompression reads a --compress value; empty keeps what is on disk.
+func ParseCompression(raw string) (Compression, error) {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "":
+		return "", nil
+	case "none", "off":
+		return CompressionNone, nil
+	case "gzip", "gz":
+		return CompressionGzip, nil
+	case "zstd", "zst":
+		return CompressionZstd, nil
+	default:
+		return "", fmt.Errorf("unsupported compression %q (supported: gzip, zstd, none)", raw)
+	}
+}
+
+// SetArtifactCompression sets the compression WriteArtifact uses for the
+// rest of the run; empty keeps each artifact the way it is on disk.
+func SetArtifactCompression(compression Compression) {
+	artifactCompression = compression
+}
+
+// ArtifactCompression returns the compression the artifact at path is
+// written with: as requested for this run, else as it is stored now.
+func ArtifactCompression(path string) Compression {
+	if artifactCompression != "" {
+		return artifactCompression
+	}
+	for _, compression := range []Compression{CompressionGzip, CompressionZstd} {
+		if _, err := os.Stat(path + compression.Suffix()); err == nil {
+			return compression
+		}
+	}
+	return CompressionNone
+}
+
+// ArtifactVariants returns path and each of its compressed variants.
+func ArtifactVariants(path string) []string {
+	variants := []string{path}
+	for _, suffix := range CompressedSuffixes {
+		variants = append(variants, path+suffix)
+	}
+	return variants
+}
+
+// TrimCompressedSuffix returns the plain name of a compressed variant, and
+// other names unchanged.
+func TrimCompressedSuffix(name string) string {
+	return strings.TrimSuffix(name, compressionOf(name).Suffix())
+}
+
+// compressionOf returns the compression of the file named name by its suffix.
+func compressionOf(name string) Compression {
+	switch {
+	case strings.HasSuffix(name, GzipSuffix):
+		return CompressionGzip
+	case strings.HasSuffix(name, ZstdSuffix):
+		return CompressionZstd
+	}
+	return CompressionNone
+}
+
+// ArtifactPath returns the file that holds the artifact at path: path
+// itself, or its compressed variant when only that exists.
+func ArtifactPath(path string) string {
+	if _, err := os.Stat(path); err != nil {
+		for _, suffix := range CompressedSuffixes {
+			if _, err := os.Stat(path + suffix); err == nil {
+				return path + suffix
+			}
+		}
+	}
+	return path
+}
+
+// OpenArtifact opens the artifact at path, or its compressed variant,
+// decompressing transparently.
+func OpenArtifact(path string) (io.ReadCloser, error) {
+	file, err := os.Open(ArtifactPath(path))
+	if err != nil {
+		return nil, err
+	}
+	if compressionOf(file.Name()) == CompressionNone {
+		return file, nil
+	}
+	reader, err := NewArtifactReader(file, file.Name())
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to decompress %s: %w", file.Name(), err)
+	}
+	return compressedArtifact{ReadCloser: reader, file: file}, nil
+}
+
+// NewArtifactReader returns a reader of r, the content of the file named
+// name, decompressed according to name's suffix.
+func NewArtifactReader(r io.Reader, name string) (io.ReadCloser, error) {
+	switch compressionOf(name) {
+	case CompressionGzip:
+		return gzip.NewReader(r)
+	case CompressionZstd:
+		decoder, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, err
+		}
+		return decoder.IOReadCloser(), nil
+	}
+	return io.NopCloser(r), nil
+}
+
+type compressedArtifact struct {
+	io.ReadCloser
+	file *os.File
+}
+
+func (a compressedArtifact) Close() error {
+	a.ReadCloser.Close()
+	return a.file.Close()
+}
+
+// ReadArtifact reads the artifact at path, or its compressed variant.
+func ReadArtifact(path string) ([]byte, error) {
+	reader, err := OpenArtifact(path)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
+
+// HashArtifact hashes the artifact's uncompressed content like HashFile, so
+// recorded output hashes do not change with the compression.
+func HashArtifact(path string) (string, error) {
+	reader, err := OpenArtifact(path)
+	if err != nil {
+		return "", err
+	}
+	defer reader.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, reader); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16], nil
+}
+
+// WriteArtifact writes data to path, or compressed to its compressed
+// variant when ArtifactCompression says so, and removes the other variants.
+// Like WriteIfChanged it leaves an unchanged artifact untouched.
+func WriteArtifact(path string, data []byte) error {
+	compression := ArtifactCompression(path)
+	target := path + compression.Suffix()
+	if compression == CompressionNone {
+		if err := WriteIfChanged(path, data); err != nil {
+			return err
+		}
+		return removeVariants(path, target)
+	}
+	var compressed bytes.Buffer
+	writer, err := newCompressor(&compressed, compression)
+	if err != nil {
+		return err
+	}
+	if _, err := writer.Write(data); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	if err := WriteIfChanged(target, compressed.Bytes()); err != nil {
+		return err
+	}
+	return removeVariants(path, target)
+}
+
+// CompressFile compresses the file at src into dst, which is replaced
+// atomically.
+func CompressFile(src, dst string, compression Compression) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	tmp, err := os.CreateTemp(filepath.Dir(dst), "."+filepath.Base(dst)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	writer, err := newCompressor(tmp, compression)
+	if err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if _, err := io.Copy(writer, in); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), dst)
+}
+
+// newCompressor returns a writer compressing into w. The zstd encoder runs
+// single-threaded so the same content always compresses to the same bytes,
+// which keeps unchanged artifacts untouched.
+func newCompressor(w io.Writer, compression Compression) (io.WriteCloser, error) {
+	switch compression {
+	case CompressionGzip:
+		return gzip.NewWriter(w), nil
+	case CompressionZstd:
+		return zstd.NewWriter(w, zstd.WithEncoderConcurrency(1))
+	}
+	return nil, fmt.Errorf("unsupported compression %q", compression)
+}
+
+// removeVariants removes the variants of the artifact at path other than keep.
+func removeVariants(path, keep string) error {
+	for _, variant := range ArtifactVariants(path) {
+		if variant == keep {
+			continue
+		}
+		if err := removeIfExists(variant); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func removeIfExists(path string) error {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
@@ -26,6 +26,12 @@ func HashFile(path string) (string, error) {
 	return hex.EncodeToString(h.Sum(nil))[:16], nil
 }
 
+// HashBytes hashes content the way HashFile hashes a file.
+func HashBytes(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])[:16]
+}
+
 func ScanFileHashes(rootPath string, registry *parser.Registry, ignoreRules []string) (map[string]string, error) {
 	hashes := make(map[string]string)
 	ignoreMatcher := ignore.NewMatcher(ignoreRules)
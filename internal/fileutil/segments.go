@@ -0,0 +1,210 @@
+package fileutil
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"hash"
+	"strconv"
+	"strings"
+)
+
+// segmentIndent prefixes the elements of a top-level array in JSON written
+// with json.MarshalIndent(v, "", "  ").
+const segmentIndent = "\n    "
+
+// Segments describes an index on disk that holds one segment per source
+// file: a run of array elements whose IDs start with the file's path.
+// Writers re-encode the segments whose fingerprint changed and copy the
+// others from disk.
+type Segments struct {
+	// Hash is the recorded output hash of the index file, so segments are
+	// only reused from the exact file they were recorded for.
+	Hash string
+	// Files maps each file to the fingerprint of its segment on disk.
+	Files map[string]string
+}
+
+// Load reads the index at path and returns its content and segments, or
+// nil when it is not the file Hash was recorded for.
+func (s Segments) Load(path, key string) ([]byte, map[string][]byte) {
+	if s.Hash == "" || len(s.Files) == 0 {
+		return nil, nil
+	}
+	data, err := ReadArtifact(path)
+	if err != nil {
+		return nil, nil
+	}
+	sum := sha256.Sum256(data)
+	if hex.EncodeToString(sum[:])[:16] != s.Hash {
+		return nil, nil
+	}
+	segments, ok := ReadSegments(data, key)
+	if !ok {
+		return nil, nil
+	}
+	return data, segments
+}
+
+// Reuse returns the segment of file on disk when its fingerprint is
+// unchanged.
+func (s Segments) Reuse(segments map[string][]byte, file, fingerprint string) ([]byte, bool) {
+	if fingerprint == "" || s.Files[file] != fingerprint {
+		return nil, false
+	}
+	segment, ok := segments[file]
+	return segment, ok
+}
+
+// SegmentFile returns the file an element ID belongs to.
+func SegmentFile(id string) string {
+	file, _, _ := strings.Cut(id, "|")
+	return file
+}
+
+// Fingerprint hashes the fields a segment is encoded from.
+type Fingerprint struct {
+	h hash.Hash
+}
+
+func NewFingerprint() *Fingerprint {
+	return &Fingerprint{h: sha256.New()}
+}
+
+func (f *Fingerprint) String(values ...string) {
+	for _, value := range values {
+		f.h.Write([]byte(value))
+		f.h.Write([]byte{0})
+	}
+}
+
+func (f *Fingerprint) Int(value int) {
+	f.String(strconv.Itoa(value))
+}
+
+func (f *Fingerprint) Sum() string {
+	return hex.EncodeToString(f.h.Sum(nil))[:16]
+}
+
+// EncodeSegment encodes elements as they appear inside a top-level array
+// written with json.MarshalIndent(v, "", "  ").
+func EncodeSegment[T any](elements []T) ([]byte, error) {
+	var buf bytes.Buffer
+	for i := range elements {
+		data, err := json.MarshalIndent(elements[i], "    ", "  ")
+		if err != nil {
+			return nil, err
+		}
+		if i > 0 {
+			buf.WriteString("," + segmentIndent)
+		}
+		buf.Write(data)
+	}
+	return buf.Bytes(), nil
+}
+
+// ReadSegments splits the array under key in data, written with
+// json.MarshalIndent(v, "", "  "), into the segment of every file. It
+// reports false when the array is missing or its elements are not grouped
+// by file.
+func ReadSegments(data []byte, key string) (map[string][]byte, bool) {
+	open := []byte("\n  \"" + key + "\": [")
+	start := bytes.Index(data, open)
+	if start < 0 {
+		return nil, false
+	}
+	start += len(open)
+	segments := make(map[string][]byte)
+	if bytes.HasPrefix(data[start:], []byte("]")) {
+		return segments, true
+	}
+	end := bytes.Index(data[start:], []byte("\n  ]"))
+	if end < 0 {
+		return nil, false
+	}
+	elements := data[start : start+end]
+
+	// Elements start on a line with exactly four spaces of indent; nested
+	// objects are indented further and strings cannot hold raw newlines.
+	marker := []byte(segmentIndent + "{\n      \"id\": ")
+	segmentStart, current := -1, ""
+	for offset := 0; offset < len(elements); {
+		next := bytes.Index(elements[offset:], marker)
+		if next < 0 {
+			break
+		}
+		at := offset + next
+		idStart := at + len(marker)
+		idEnd := bytes.IndexByte(elements[idStart:], '\n')
+		if idEnd < 0 {
+			return nil, false
+		}
+		var id string
+		if err := json.Unmarshal(bytes.TrimSuffix(elements[idStart:idStart+idEnd], []byte(",")), &id); err != nil {
+			return nil, false
+		}
+		if file := SegmentFile(id); file != current || segmentStart < 0 {
+			if segmentStart >= 0 {
+				if _, seen := segments[current]; seen {
+					return nil, false
+				}
+				// The previous segment ends before the separating ",".
+				segments[current] = elements[segmentStart+len(segmentIndent) : at-1]
+			}
+			segmentStart, current = at, file
+		}
+		offset = idStart
+	}
+	if segmentStart < 0 {
+		return nil, false
+	}
+	if _, seen := segments[current]; seen {
+		return nil, false
+	}
+	segments[current] = elements[segmentStart+len(segmentIndent):]
+	return segments, true
+}
+
+// SpliceSegments fills the empty array under key in data, written with
+// json.MarshalIndent(v, "", "  "), with the segments in order, giving the
+// bytes json.MarshalIndent would have written for the full array.
+func SpliceSegments(data []byte, key string, segments [][]byte) []byte {
+	empty := []byte("\n  \"" + key + "\": []")
+	at := bytes.LastIndex(data, empty)
+	nonEmpty := make([][]byte, 0, len(segments))
+	for _, segment := range segments {
+		if len(segment) > 0 {
+			nonEmpty = append(nonEmpty, segment)
+		}
+	}
+	if at < 0 || len(nonEmpty) == 0 {
+		return data
+	}
+	var buf bytes.Buffer
+	buf.Write(data[:at+len(empty)-1])
+	buf.WriteString(segmentIndent)
+	buf.Write(bytes.Join(nonEmpty, []byte(","+segmentIndent)))
+	buf.WriteString("\n  ]")
+	buf.Write(data[at+len(empty):])
+	return buf.Bytes()
+}
+
+// EmptySegments returns data with the array under key emptied, for decoding
+// the rest of an index without its elements.
+func EmptySegments(data []byte, key string) []byte {
+	open := []byte("\n  \"" + key + "\": [")
+	start := bytes.Index(data, open)
+	if start < 0 {
+		return data
+	}
+	start += len(open)
+	end := bytes.Index(data[start:], []byte("\n  ]"))
+	if end < 0 {
+		return data
+	}
+	out := make([]byte, 0, start+len(data)-start-end)
+	out = append(out, data[:start]...)
+	out = append(out, ']')
+	return append(out, data[start+end+len("\n  ]"):]...)
+}
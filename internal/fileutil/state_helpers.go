@@ -18,12 +18,16 @@ func ParseResultFromState(st *state.State, rootPath string, currentHashes map[st
 		}
 
 		files = append(files, parser.FileSymbols{
-			Path:          path,
-			Language:      fileState.Language,
-			Symbols:       fileState.Symbols,
-			Imports:       fileState.Imports,
-			ImportAliases: fileState.ImportAliases,
-			Hash:          hash,
+			Path:            path,
+			Language:        fileState.Language,
+			Symbols:         fileState.Symbols,
+			Imports:         fileState.Imports,
+			ImportAliases:   fileState.ImportAliases,
+			Hash:            hash,
+			Generated:       fileState.Generated,
+			BuildConstraint: fileState.BuildConstraint,
+			External:        fileState.External,
+			Lines:           fileState.Lines,
 		})
 		EnsureSymbolIDs(&files[len(files)-1])
 	}
@@ -39,11 +43,15 @@ func ParseResultFromState(st *state.State, rootPath string, currentHashes map[st
 }
 
 func EnsureSymbolIDs(file *parser.FileSymbols) {
+	var ids []string
 	for i := range file.Symbols {
 		if file.Symbols[i].ID != "" {
 			continue
 		}
-		file.Symbols[i].ID = parser.StableSymbolID(file.Path, file.Symbols[i])
+		if ids == nil {
+			ids = parser.SymbolIDs(file.Path, file.Symbols)
+		}
+		file.Symbols[i].ID = ids[i]
 	}
 }
 
@@ -82,6 +90,9 @@ func ApplyGraphDependencies(st *state.State, g *graph.Graph, files map[string]bo
 				deps[targetFile] = true
 			}
 		}
+		for _, include := range g.FileIncludes[file] {
+			deps[include] = true
+		}
 
 		fileState.Dependencies = MapKeysSorted(deps)
 		st.Files[file] = fileState
@@ -0,0 +1,147 @@
+package graph
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/morozRed/skelly/internal/parser"
+)
+
+// generatedStubSuffixes are the file name endings protoc plugins give their
+// output; stripping one yields the stem of the .proto file it came from.
+var generatedStubSuffixes = []string{
+	"_grpc.pb.go", ".pb.gw.go", ".pb.go",
+	"_pb2_grpc.py", "_pb2.pyi", "_pb2.py",
+	"_grpc_pb.d.ts", "_grpc_pb.js", "_pb.d.ts", "_pb.ts", "_pb.js", "_connect.ts", ".pb.ts",
+}
+
+// protoServiceStubs derive the generated type names of a service S
+// (SClient, UnimplementedSServer, SStub, ...) across protoc plugins.
+var protoServiceStubs = []func(service string) string{
+	func(s string) string { return s },
+	func(s string) string { return s + "Client" },
+	func(s string) string { return s + "Server" },
+	func(s string) string { return "Unimplemented" + s + "Server" },
+	func(s string) string { return "Unsafe" + s + "Server" },
+	func(s string) string { return strings.ToLower(s[:1]) + s[1:] + "Client" },
+	func(s string) string { return s + "ClientImpl" },
+	func(s string) string { return s + "Servicer" },
+	func(s string) string { return s + "Stub" },
+	func(s string) string { return s + "Handler" },
+}
+
+// protoStubIndex maps generated names to the .proto declarations they come
+// from.
+type protoStubIndex struct {
+	types map[string][]string            // generated type name -> message, enum or service IDs
+	rpcs  map[string]map[string][]string // service ID -> lower-cased rpc name -> rpc IDs
+}
+
+// resolveGeneratedFrom links symbols in generated stubs (api.pb.go,
+// api_pb2.py, api_pb.ts, or any file tagged generated) to the .proto
+// declarations they were generated from with heuristic EdgeGeneratedFrom
+// edges: messages and enums by name (Invoice_LineItem for nested ones),
+// service stubs by the plugins' naming conventions and their methods by rpc
+// name. Declarations in the .proto file sharing the stub's stem win over
+// same-named ones elsewhere.
+func (g *Graph) resolveGeneratedFrom(result *parser.ParseResult, sourceFiles map[string]bool) {
+	index := buildProtoStubIndex(result)
+	if len(index.types) == 0 {
+		return
+	}
+	for _, file := range result.Files {
+		if sourceFiles != nil && !sourceFiles[file.Path] {
+			continue
+		}
+		stem, isStub := generatedStubStem(file.Path)
+		if schemaLanguages[file.Language] || (!isStub && !file.Generated) {
+			continue
+		}
+		for _, sym := range file.Symbols {
+			var candidates []string
+			switch sym.Kind {
+			case parser.SymbolMethod, parser.SymbolFunction:
+				for _, serviceID := range index.types[parser.InnermostName(sym.Container)] {
+					candidates = append(candidates, index.rpcs[serviceID][strings.ToLower(sym.Name)]...)
+				}
+			default:
+				candidates = index.types[sym.Name]
+			}
+			if len(candidates) == 0 {
+				continue
+			}
+			if stem != "" {
+				if sameStem := protoFilesWithStem(candidates, stem); len(sameStem) > 0 {
+					candidates = sameStem
+				}
+			}
+			targetIDs, confidence, ok := chooseUnique(candidates, "heuristic")
+			if !ok {
+				continue
+			}
+			g.addEdge(g.Nodes[makeNodeID(file.Path, sym)], targetIDs[0], EdgeGeneratedFrom, confidence)
+		}
+	}
+}
+
+func buildProtoStubIndex(result *parser.ParseResult) protoStubIndex {
+	index := protoStubIndex{
+		types: make(map[string][]string),
+		rpcs:  make(map[string]map[string][]string),
+	}
+	services := make(map[string]string) // service qualified name -> ID, per file
+	for _, file := range result.Files {
+		if file.Language != "proto" {
+			continue
+		}
+		clear(services)
+		for _, sym := range file.Symbols {
+			id := makeNodeID(file.Path, sym)
+			switch sym.Kind {
+			case parser.SymbolStruct, parser.SymbolClass:
+				name := strings.ReplaceAll(sym.QualifiedName(), ".", "_")
+				index.types[name] = append(index.types[name], id)
+			case parser.SymbolInterface:
+				services[sym.QualifiedName()] = id
+				for _, stub := range protoServiceStubs {
+					name := stub(sym.Name)
+					index.types[name] = append(index.types[name], id)
+				}
+			case parser.SymbolMethod:
+				serviceID, ok := services[sym.Container]
+				if !ok {
+					continue
+				}
+				if index.rpcs[serviceID] == nil {
+					index.rpcs[serviceID] = make(map[string][]string)
+				}
+				name := strings.ToLower(sym.Name)
+				index.rpcs[serviceID][name] = append(index.rpcs[serviceID][name], id)
+			}
+		}
+	}
+	return index
+}
+
+// generatedStubStem returns the proto stem of a protoc output file
+// ("invoice" for billing/invoice_grpc.pb.go) and whether the name matched a
+// known plugin suffix.
+func generatedStubStem(path string) (string, bool) {
+	for _, suffix := range generatedStubSuffixes {
+		if stem, ok := strings.CutSuffix(path, suffix); ok {
+			return filepath.Base(stem), true
+		}
+	}
+	return "", false
+}
+
+func protoFilesWithStem(ids []string, stem string) []string {
+	out := make([]string, 0, len(ids))
+	for _, id := range ids {
+		file, _ := ParseNodeID(id)
+		if strings.TrimSuffix(filepath.Base(file), ".proto") == stem {
+			out = append(out, id)
+		}
+	}
+	return out
+}
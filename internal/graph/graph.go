@@ -1,36 +1,145 @@
 package graph
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"math"
+	"path"
 	"path/filepath"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/morozRed/skelly/internal/parser"
 )
 
+// EdgeKind classifies an edge of the graph.
+type EdgeKind string
+
+const (
+	EdgeCall EdgeKind = "call"
+	// EdgeImport links a file to the repository files its imports and
+	// includes resolve to; see Graph.FileImports.
+	EdgeImport EdgeKind = "import"
+	// EdgeInherit links a type to a superclass, extended interface or mixin.
+	EdgeInherit EdgeKind = "inherit"
+	// EdgeImplement links a type to an interface it implements or satisfies.
+	EdgeImplement EdgeKind = "implement"
+	// EdgeReference links a symbol to a type it mentions without calling it.
+	EdgeReference EdgeKind = "reference"
+	// EdgeRender links a symbol to a component it renders as a JSX element.
+	EdgeRender EdgeKind = "render"
+	// EdgeGeneratedFrom links generated code to the schema declaration
+	// (.proto message, enum, service or rpc) it was generated from.
+	EdgeGeneratedFrom EdgeKind = "generated-from"
+)
+
 // Node represents a symbol in the dependency graph
 type Node struct {
-	ID                string // stable symbol ID (file|line|kind|name|sig-hash)
+	ID                string // stable symbol ID (file|kind|qualified-name|sig-hash)
 	Symbol            parser.Symbol
 	File              string
-	OutEdges          []string          // symbols this node calls/references
-	OutEdgeConfidence map[string]string // target ID -> resolved|ambiguous|heuristic
-	InEdges           []string          // symbols that call/reference this node
-	PageRank          float64           // importance score
+	Language          string
+	OutEdges          []string            // symbols this node calls/references
+	OutEdgeConfidence map[string]string   // target ID -> resolved|ambiguous|heuristic
+	OutEdgeKinds      map[string]EdgeKind // target ID -> kind; missing means EdgeCall
+	InEdges           []string            // symbols that call/reference this node
+	PageRank          float64             // importance score
+	Betweenness       float64             // approximate betweenness centrality, see calculateBetweenness
+	Summary           string              // enrich summary, merged in before artifacts are written
+	External          bool                // symbol of vendored or generated code, see parser.FileSymbols
+}
+
+// EdgeKindTo returns the kind of the edge from n to targetID.
+func (n *Node) EdgeKindTo(targetID string) EdgeKind {
+	if kind, ok := n.OutEdgeKinds[targetID]; ok {
+		return kind
+	}
+	return EdgeCall
+}
+
+// OutEdgesOfKind returns the targets of n's edges of the given kind.
+func (n *Node) OutEdgesOfKind(kind EdgeKind) []string {
+	out := make([]string, 0, len(n.OutEdges))
+	for _, targetID := range n.OutEdges {
+		if n.EdgeKindTo(targetID) == kind {
+			out = append(out, targetID)
+		}
+	}
+	return out
+}
+
+// InEdgesOfKind returns the sources of edges of the given kind into node.
+func (g *Graph) InEdgesOfKind(node *Node, kind EdgeKind) []string {
+	out := make([]string, 0, len(node.InEdges))
+	for _, sourceID := range node.InEdges {
+		if source := g.Nodes[sourceID]; source != nil && source.EdgeKindTo(node.ID) == kind {
+			out = append(out, sourceID)
+		}
+	}
+	return out
+}
+
+// addEdge records an edge of kind from source to targetID. A pair of
+// symbols keeps the kind it was first linked with.
+func (g *Graph) addEdge(source *Node, targetID string, kind EdgeKind, confidence string) {
+	if targetID == source.ID { // Don't self-reference
+		return
+	}
+	if _, exists := source.OutEdgeConfidence[targetID]; !exists {
+		source.OutEdges = append(source.OutEdges, targetID)
+		if kind != EdgeCall {
+			source.OutEdgeKinds[targetID] = kind
+		}
+		if target, ok := g.Nodes[targetID]; ok {
+			target.InEdges = append(target.InEdges, source.ID)
+		}
+	}
+	source.OutEdgeConfidence[targetID] = mergeConfidence(source.OutEdgeConfidence[targetID], confidence)
 }
 
 // Graph represents the codebase dependency graph
 type Graph struct {
-	Nodes     map[string]*Node    // ID -> Node
-	FileNodes map[string][]string // file -> list of node IDs in that file
+	Nodes        map[string]*Node    // ID -> Node
+	FileNodes    map[string][]string // file -> list of node IDs in that file
+	FileIncludes map[string][]string // file -> repository files it #includes (C/C++) or imports (proto)
+	// FileImports maps a file to the repository files its imports and
+	// includes resolve to (EdgeImport).
+	FileImports map[string][]string
+	// Resolution counts call-site outcomes per language for the files whose
+	// edges were computed in this build.
+	Resolution map[string]*ResolutionStats
 }
 
 type symbolLookups struct {
-	global                map[string][]string
+	global                map[string]map[string][]string // language family -> name -> IDs
+	qualified             map[string][]string            // Type.name (see parser.Symbol.QualifiedNames) -> IDs
+	types                 map[string][]string            // type name -> class, struct, interface and module IDs
+	embeds                map[string][]string            // Go struct or interface ID -> embedded type names
+	languages             map[string]string              // file -> language
 	byFile                map[string]map[string][]string
 	byFileMethods         map[string]map[string][]string
-	byModule              map[string]map[string][]string
+	byModule              map[moduleScope]map[string][]string
 	importAliasCandidates map[string]map[string]importAliasCandidate
+	namespaces            namespaceLookups
+	prototypes            map[string]bool // C and C++ prototype IDs, see parser.Symbol.Prototype
+}
+
+// namespaceLookups index declared namespaces (C#, PHP) so Type.Method and
+// Namespace.Type.Method calls can be resolved to the file declaring Type.
+type namespaceLookups struct {
+	files       map[string][]string            // namespace path -> declaring files
+	scopes      map[string][]string            // file -> namespaces visible without qualification
+	typeImports map[string]map[string]string   // file -> alias -> imported type path
+	types       map[string]map[string][]string // file -> type name -> type symbol IDs
+}
+
+// moduleScope keys the module lookup by language family, so a module's
+// Python and Rust files do not resolve each other's calls.
+type moduleScope struct {
+	module string
+	family string
 }
 
 type importAliasCandidate struct {
@@ -41,8 +150,11 @@ type importAliasCandidate struct {
 // NewGraph creates a new empty graph
 func NewGraph() *Graph {
 	return &Graph{
-		Nodes:     make(map[string]*Node),
-		FileNodes: make(map[string][]string),
+		Nodes:        make(map[string]*Node),
+		FileNodes:    make(map[string][]string),
+		FileIncludes: make(map[string][]string),
+		FileImports:  make(map[string][]string),
+		Resolution:   make(map[string]*ResolutionStats),
 	}
 }
 
@@ -51,6 +163,15 @@ func BuildFromParseResult(result *parser.ParseResult) *Graph {
 	return buildFromParseResult(result, nil, true)
 }
 
+// BuildFromParseResultWithRanks constructs the graph like
+// BuildFromParseResult, scoring it from the scores of an earlier build (see
+// RankFrom) instead of from scratch.
+func BuildFromParseResultWithRanks(result *parser.ParseResult, prior Ranks) *Graph {
+	g := buildFromParseResult(result, nil, false)
+	g.RankFrom(prior)
+	return g
+}
+
 // BuildFromParseResultForSources constructs a graph by recomputing edges only for source files.
 // Nodes for every file are still materialized so edges can target unchanged symbols.
 func BuildFromParseResultForSources(result *parser.ParseResult, sourceFiles map[string]bool) *Graph {
@@ -68,9 +189,12 @@ func buildFromParseResult(result *parser.ParseResult, sourceFiles map[string]boo
 				ID:                id,
 				Symbol:            sym,
 				File:              file.Path,
+				Language:          file.Language,
 				OutEdges:          make([]string, 0),
 				OutEdgeConfidence: make(map[string]string),
+				OutEdgeKinds:      make(map[string]EdgeKind),
 				InEdges:           make([]string, 0),
+				External:          file.External,
 			}
 			g.Nodes[id] = node
 			g.FileNodes[file.Path] = append(g.FileNodes[file.Path], id)
@@ -91,45 +215,73 @@ func buildFromParseResult(result *parser.ParseResult, sourceFiles map[string]boo
 
 			for _, call := range sym.Calls {
 				// Try to resolve the call to a node
-				if targetIDs, confidence, ok := lookups.resolve(file.Path, sym, call); ok {
+				targetIDs, confidence, ok := lookups.resolve(file.Path, sym, call)
+				g.recordResolution(file, sym, call, confidence, ok)
+				if ok {
 					for _, targetID := range targetIDs {
-						if targetID != srcID { // Don't self-reference
-							srcNode.OutEdges = append(srcNode.OutEdges, targetID)
-							srcNode.OutEdgeConfidence[targetID] = mergeConfidence(
-								srcNode.OutEdgeConfidence[targetID],
-								confidence,
-							)
-							if targetNode, ok := g.Nodes[targetID]; ok {
-								targetNode.InEdges = append(targetNode.InEdges, srcID)
-							}
-						}
+						g.addEdge(srcNode, targetID, EdgeCall, confidence)
 					}
 				}
 			}
 		}
 	}
 
+	g.resolveImplementations(result)
+	g.resolveSupertypes(result, lookups)
+	g.resolveRenders(result, lookups, sourceFiles)
+	g.resolveGeneratedFrom(result, sourceFiles)
+	g.resolveReferences(result, lookups, sourceFiles)
 	g.normalizeEdges()
+	g.resolveIncludes(result, sourceFiles)
+	g.resolveImports(result, lookups, sourceFiles)
 
-	// Calculate PageRank
+	// Calculate PageRank and betweenness
 	if withPageRank {
-		g.calculatePageRank(20, 0.85)
+		g.calculatePageRank(pageRankIterations, pageRankDamping)
+		g.calculateBetweenness()
 	}
 
 	return g
 }
 
+// schemaLanguages describe interfaces rather than implement them (.proto).
+// Their types only resolve from files of the same language.
+var schemaLanguages = map[string]bool{
+	"proto": true,
+}
+
+// languageFamilies groups languages whose code calls into each other by
+// name: JavaScript and TypeScript modules import one another, and C++ calls
+// C functions. Name-based lookups only match within a family.
+var languageFamilies = map[string]string{
+	"javascript": "typescript",
+	"cpp":        "c",
+}
+
+func languageFamily(language string) string {
+	if family, ok := languageFamilies[language]; ok {
+		return family
+	}
+	return language
+}
+
 // buildSymbolLookup indexes symbols by name with file/module scopes for resolution.
 func buildSymbolLookup(result *parser.ParseResult) symbolLookups {
 	lookup := symbolLookups{
-		global:                make(map[string][]string),
+		global:                make(map[string]map[string][]string),
+		qualified:             make(map[string][]string),
+		types:                 make(map[string][]string),
+		embeds:                make(map[string][]string),
+		languages:             make(map[string]string),
 		byFile:                make(map[string]map[string][]string),
 		byFileMethods:         make(map[string]map[string][]string),
-		byModule:              make(map[string]map[string][]string),
+		byModule:              make(map[moduleScope]map[string][]string),
 		importAliasCandidates: make(map[string]map[string]importAliasCandidate),
+		prototypes:            make(map[string]bool),
 	}
 
 	for _, file := range result.Files {
+		lookup.languages[file.Path] = file.Language
 		if _, ok := lookup.byFile[file.Path]; !ok {
 			lookup.byFile[file.Path] = make(map[string][]string)
 		}
@@ -137,24 +289,55 @@ func buildSymbolLookup(result *parser.ParseResult) symbolLookups {
 			lookup.byFileMethods[file.Path] = make(map[string][]string)
 		}
 
-		module := moduleName(file.Path)
+		family := languageFamily(file.Language)
+		if _, ok := lookup.global[family]; !ok {
+			lookup.global[family] = make(map[string][]string)
+		}
+		module := moduleScope{module: moduleName(file.Path), family: family}
 		if _, ok := lookup.byModule[module]; !ok {
 			lookup.byModule[module] = make(map[string][]string)
 		}
 
 		for _, sym := range file.Symbols {
 			id := makeNodeID(file.Path, sym)
-			lookup.global[sym.Name] = append(lookup.global[sym.Name], id)
+			if sym.Prototype {
+				lookup.prototypes[id] = true
+			}
+			// Schema declarations are never called, and generated code reuses
+			// their names; keep them out of the name-based call lookups.
+			if !schemaLanguages[file.Language] {
+				lookup.global[family][sym.Name] = append(lookup.global[family][sym.Name], id)
+				lookup.byModule[module][sym.Name] = append(lookup.byModule[module][sym.Name], id)
+			}
+			for _, name := range sym.QualifiedNames() {
+				lookup.qualified[name] = append(lookup.qualified[name], id)
+			}
 			lookup.byFile[file.Path][sym.Name] = append(lookup.byFile[file.Path][sym.Name], id)
-			lookup.byModule[module][sym.Name] = append(lookup.byModule[module][sym.Name], id)
+			switch sym.Kind {
+			case parser.SymbolClass, parser.SymbolStruct, parser.SymbolInterface, parser.SymbolModule, parser.SymbolComponent:
+				lookup.types[sym.Name] = append(lookup.types[sym.Name], id)
+			}
+			if file.Language == "go" {
+				for _, base := range sym.Bases {
+					lookup.embeds[id] = append(lookup.embeds[id], base.Name)
+				}
+			}
 			if sym.Kind == parser.SymbolMethod {
 				lookup.byFileMethods[file.Path][sym.Name] = append(lookup.byFileMethods[file.Path][sym.Name], id)
 			}
 		}
 	}
 
-	for name, ids := range lookup.global {
-		lookup.global[name] = dedupeAndSort(ids)
+	for family, byName := range lookup.global {
+		for name, ids := range byName {
+			lookup.global[family][name] = dedupeAndSort(ids)
+		}
+	}
+	for name, ids := range lookup.qualified {
+		lookup.qualified[name] = dedupeAndSort(ids)
+	}
+	for name, ids := range lookup.types {
+		lookup.types[name] = dedupeAndSort(ids)
 	}
 	for file, byName := range lookup.byFile {
 		for name, ids := range byName {
@@ -173,23 +356,114 @@ func buildSymbolLookup(result *parser.ParseResult) symbolLookups {
 	}
 
 	lookup.importAliasCandidates = buildImportAliasCandidates(result)
+	lookup.namespaces = buildNamespaceLookups(result)
+
+	return lookup
+}
+
+func buildNamespaceLookups(result *parser.ParseResult) namespaceLookups {
+	lookup := namespaceLookups{
+		files:       make(map[string][]string),
+		scopes:      make(map[string][]string),
+		typeImports: make(map[string]map[string]string),
+		types:       make(map[string]map[string][]string),
+	}
+
+	for _, file := range result.Files {
+		scopes := make([]string, 0)
+		for _, sym := range file.Symbols {
+			switch sym.Kind {
+			case parser.SymbolModule:
+				if !strings.HasPrefix(sym.Signature, "namespace ") {
+					continue
+				}
+				namespace := namespacePath(sym.Name)
+				lookup.files[namespace] = append(lookup.files[namespace], file.Path)
+				// Code inside A.B.C sees the types of A.B.C, A.B and A unqualified.
+				for scope := namespace; scope != "" && scope != "."; scope = path.Dir(scope) {
+					scopes = append(scopes, scope)
+				}
+			case parser.SymbolClass, parser.SymbolStruct, parser.SymbolInterface:
+				if _, ok := lookup.types[file.Path]; !ok {
+					lookup.types[file.Path] = make(map[string][]string)
+				}
+				lookup.types[file.Path][sym.Name] = append(lookup.types[file.Path][sym.Name], makeNodeID(file.Path, sym))
+			}
+		}
+		if len(scopes) == 0 {
+			continue
+		}
 
+		typeImports := make(map[string]string)
+		for _, importPath := range file.Imports {
+			scopes = append(scopes, namespacePath(importPath))
+			if alias := defaultAliasFromImport(importPath); alias != "" {
+				typeImports[alias] = namespacePath(importPath)
+			}
+		}
+		for alias, target := range file.ImportAliases {
+			importPath, symbolName := parseImportAliasTarget(target)
+			if symbolName == "" {
+				typeImports[strings.TrimSpace(alias)] = namespacePath(importPath)
+			}
+		}
+		lookup.scopes[file.Path] = dedupeAndSort(scopes)
+		lookup.typeImports[file.Path] = typeImports
+	}
+
+	for namespace, files := range lookup.files {
+		lookup.files[namespace] = dedupeAndSort(files)
+	}
 	return lookup
 }
 
+// namespacePath normalizes Acme.Billing, Acme\Billing and Acme/Billing to Acme/Billing.
+func namespacePath(value string) string {
+	value = strings.TrimPrefix(strings.TrimSpace(value), "global::")
+	value = strings.NewReplacer(".", "/", `\`, "/").Replace(value)
+	return strings.Trim(value, "/")
+}
+
 // calculatePageRank computes importance scores for all nodes
 func (g *Graph) calculatePageRank(iterations int, dampingFactor float64) {
-	n := float64(len(g.Nodes))
-	if n == 0 {
+	if len(g.Nodes) == 0 {
 		return
 	}
 
-	// Initialize all nodes with equal rank
+	// Initialize the nodes random jumps land on with equal rank
+	teleports, n := g.pageRankTeleports()
+	for _, node := range g.Nodes {
+		node.PageRank = 0
+		if teleports(node) {
+			node.PageRank = 1.0 / n
+		}
+	}
+	g.iteratePageRank(iterations, dampingFactor, 0)
+}
+
+// pageRankTeleports returns which nodes random jumps and dangling mass land
+// on, and how many there are. External nodes are left out, so vendored and
+// generated code only ranks by what the repository's own code sends it and
+// does not dilute the scores of that code; a graph of external nodes alone
+// teleports to all of them.
+func (g *Graph) pageRankTeleports() (func(*Node) bool, float64) {
+	internal := 0
 	for _, node := range g.Nodes {
-		node.PageRank = 1.0 / n
+		if !node.External {
+			internal++
+		}
 	}
+	if internal == 0 {
+		return func(*Node) bool { return true }, float64(len(g.Nodes))
+	}
+	return func(node *Node) bool { return !node.External }, float64(internal)
+}
 
-	// Iterate
+// iteratePageRank runs power iterations from the current scores, stopping
+// early once an iteration moves the scores by less than tolerance in total.
+// It returns the number of iterations run.
+func (g *Graph) iteratePageRank(iterations int, dampingFactor, tolerance float64) int {
+	teleports, n := g.pageRankTeleports()
 	for i := 0; i < iterations; i++ {
 		newRanks := make(map[string]float64)
 		danglingMass := 0.0
@@ -202,7 +476,10 @@ func (g *Graph) calculatePageRank(iterations int, dampingFactor float64) {
 		danglingContribution := dampingFactor * danglingMass / n
 
 		for id, node := range g.Nodes {
-			rank := (1-dampingFactor)/n + danglingContribution
+			rank := 0.0
+			if teleports(node) {
+				rank = (1-dampingFactor)/n + danglingContribution
+			}
 
 			// Sum contributions from incoming edges
 			for _, inID := range node.InEdges {
@@ -218,10 +495,127 @@ func (g *Graph) calculatePageRank(iterations int, dampingFactor float64) {
 		}
 
 		// Update ranks
+		delta := 0.0
 		for id, rank := range newRanks {
+			delta += math.Abs(rank - g.Nodes[id].PageRank)
 			g.Nodes[id].PageRank = rank
 		}
+		if delta < tolerance {
+			return i + 1
+		}
+	}
+	return iterations
+}
+
+// Ranks are the PageRank and betweenness scores of a graph by node ID, with
+// the Topology hash of the graph they were computed for.
+type Ranks struct {
+	Topology    string
+	Scores      map[string]float64
+	Betweenness map[string]float64
+}
+
+const (
+	pageRankIterations = 20
+	pageRankDamping    = 0.85
+	// pageRankTolerance stops a seeded run once scores move by less than
+	// output precision (ranks are written rounded to 1e-6).
+	pageRankTolerance = 1e-7
+	// pageRankSeedShare is the share of nodes that need a prior score for a
+	// run to start from the prior scores rather than from scratch.
+	pageRankSeedShare = 0.9
+)
+
+// RankFrom computes PageRank and betweenness reusing the scores of an
+// earlier build. When the topology is unchanged, the prior scores are copied
+// without iterating. When it changed but most nodes have a prior PageRank,
+// iteration starts from the prior scores and stops once they settle, which
+// takes a few iterations for small edits; the result is an approximation of
+// the same fixed point. Otherwise PageRank is computed from scratch.
+// Betweenness is recomputed whenever the topology changed.
+func (g *Graph) RankFrom(prior Ranks) {
+	n := len(g.Nodes)
+	if n == 0 {
+		return
+	}
+	seeded, measured := 0, 0
+	for id := range g.Nodes {
+		if _, ok := prior.Scores[id]; ok {
+			seeded++
+		}
+		if _, ok := prior.Betweenness[id]; ok {
+			measured++
+		}
+	}
+	if seeded == n && measured == n && prior.Topology != "" && prior.Topology == g.Topology() {
+		for id, node := range g.Nodes {
+			node.PageRank = prior.Scores[id]
+			node.Betweenness = prior.Betweenness[id]
+		}
+		return
+	}
+	g.calculateBetweenness()
+	if float64(seeded) < pageRankSeedShare*float64(n) {
+		g.calculatePageRank(pageRankIterations, pageRankDamping)
+		return
+	}
+
+	// New nodes start at the uniform score; the total is renormalized to one
+	// since removed nodes took their share with them.
+	total := 0.0
+	for id, node := range g.Nodes {
+		score, ok := prior.Scores[id]
+		if !ok || score <= 0 {
+			score = 1.0 / float64(n)
+		}
+		node.PageRank = score
+		total += score
+	}
+	for _, node := range g.Nodes {
+		node.PageRank /= total
+	}
+	g.iteratePageRank(pageRankIterations, pageRankDamping, pageRankTolerance)
+}
+
+// Ranks returns the current scores for seeding a later build.
+func (g *Graph) Ranks() Ranks {
+	ranks := Ranks{
+		Topology:    g.Topology(),
+		Scores:      make(map[string]float64, len(g.Nodes)),
+		Betweenness: make(map[string]float64, len(g.Nodes)),
+	}
+	for id, node := range g.Nodes {
+		ranks.Scores[id] = node.PageRank
+		ranks.Betweenness[id] = node.Betweenness
+	}
+	return ranks
+}
+
+// Topology hashes the node IDs, which of them are external, and the edges
+// PageRank is computed from; edge kinds and confidences do not affect it.
+func (g *Graph) Topology() string {
+	ids := make([]string, 0, len(g.Nodes))
+	for id := range g.Nodes {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	h := sha256.New()
+	for _, id := range ids {
+		h.Write([]byte(id))
+		h.Write([]byte{0})
+		// External nodes take no random jumps, which changes every score.
+		if g.Nodes[id].External {
+			h.Write([]byte{3})
+		}
+		targets := slices.Clone(g.Nodes[id].OutEdges)
+		sort.Strings(targets)
+		for _, target := range targets {
+			h.Write([]byte(target))
+			h.Write([]byte{1})
+		}
+		h.Write([]byte{2})
 	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
 }
 
 // TopNodes returns the most important nodes by PageRank
@@ -249,12 +643,17 @@ func (g *Graph) normalizeEdges() {
 		node.OutEdges = dedupeAndSort(node.OutEdges)
 		node.InEdges = dedupeAndSort(node.InEdges)
 
-		// Keep confidence metadata aligned with normalized edge list.
+		// Keep confidence and kind metadata aligned with normalized edge list.
 		cleanedConfidence := make(map[string]string, len(node.OutEdges))
+		cleanedKinds := make(map[string]EdgeKind)
 		for _, edge := range node.OutEdges {
 			cleanedConfidence[edge] = node.OutEdgeConfidence[edge]
+			if kind, ok := node.OutEdgeKinds[edge]; ok {
+				cleanedKinds[edge] = kind
+			}
 		}
 		node.OutEdgeConfidence = cleanedConfidence
+		node.OutEdgeKinds = cleanedKinds
 	}
 }
 
@@ -317,11 +716,19 @@ func makeNodeID(file string, symbol parser.Symbol) string {
 	return parser.StableSymbolID(file, symbol)
 }
 
-// ParseNodeID extracts file and symbol from a node ID
+// ParseNodeID extracts file and qualified symbol name from a node ID. It accepts both the
+// current file|kind|name[|sig-hash][#n] scheme and the older
+// file|line|kind|name[|sig-hash] one still found in aliases.
 func ParseNodeID(id string) (file, symbol string) {
 	parts := strings.Split(id, "|")
 	if len(parts) >= 4 {
-		return parts[0], parts[3]
+		if _, err := strconv.Atoi(parts[1]); err == nil {
+			return parts[0], parts[3]
+		}
+	}
+	if len(parts) >= 3 {
+		name, _, _ := strings.Cut(parts[2], "#")
+		return parts[0], name
 	}
 	return id, ""
 }
@@ -332,48 +739,231 @@ func (l symbolLookups) resolve(sourceFile string, sourceSymbol parser.Symbol, ca
 		return nil, "", false
 	}
 
+	if ids := l.resolveTyped(sourceFile, call); len(ids) > 0 {
+		if targetIDs, confidence, ok := l.chooseCall(ids, "resolved"); ok {
+			return targetIDs, confidence, ok
+		}
+	}
+
 	if callIsReceiverScoped(call) {
+		// self.save() inside User resolves to User.save, even when other
+		// classes in the file also define save.
+		if ids := l.resolveQualified(sourceFile, sourceSymbol.Container, callName); len(ids) > 0 {
+			if targetIDs, confidence, ok := l.chooseCall(ids, "resolved"); ok {
+				return targetIDs, confidence, ok
+			}
+		}
 		if ids := l.byFileMethods[sourceFile][callName]; len(ids) > 0 {
-			return chooseUnique(ids, "resolved")
+			return l.chooseCall(ids, "resolved")
 		}
 		if sourceSymbol.Kind == parser.SymbolMethod {
 			if ids := l.byFile[sourceFile][callName]; len(ids) > 0 {
-				return chooseUnique(ids, "resolved")
+				return l.chooseCall(ids, "resolved")
+			}
+		}
+	}
+
+	if qualifier := strings.TrimSpace(call.Qualifier); qualifier != "" && !callIsReceiverScoped(call) {
+		// User.save() names its class; Ambiguous matches fall through to the
+		// import-aware lookups below.
+		if ids := l.resolveQualified(sourceFile, qualifier, callName); len(ids) > 0 {
+			if targetIDs, confidence, ok := l.chooseCall(ids, "resolved"); ok {
+				return targetIDs, confidence, ok
 			}
 		}
 	}
 
 	if byName, exists := l.byFile[sourceFile]; exists {
 		if ids := byName[callName]; len(ids) > 0 {
-			return chooseUnique(ids, "resolved")
+			return l.chooseCall(ids, "resolved")
+		}
+	}
+
+	if ids := l.resolveNamespaceQualified(sourceFile, call); len(ids) > 0 {
+		if targetIDs, confidence, ok := l.chooseCall(ids, "resolved"); ok {
+			return targetIDs, confidence, ok
 		}
 	}
 
 	qualifier := primaryQualifier(call.Qualifier)
 	if qualifier != "" {
 		if ids := l.resolveImportAlias(sourceFile, qualifier, callName); len(ids) > 0 {
-			return chooseUnique(ids, "heuristic")
+			return l.chooseCall(ids, "heuristic")
 		}
 	} else {
 		if ids := l.resolveImportAlias(sourceFile, callName, callName); len(ids) > 0 {
-			return chooseUnique(ids, "heuristic")
+			return l.chooseCall(ids, "heuristic")
 		}
 	}
 
-	module := moduleName(sourceFile)
-	if byName, exists := l.byModule[module]; exists {
+	family := languageFamily(l.languages[sourceFile])
+	if byName, exists := l.byModule[moduleScope{module: moduleName(sourceFile), family: family}]; exists {
 		if ids := byName[callName]; len(ids) > 0 {
-			return chooseUnique(ids, "heuristic")
+			return l.chooseCall(ids, "heuristic")
 		}
 	}
 
-	if ids := l.global[callName]; len(ids) > 0 {
-		return chooseUnique(ids, "heuristic")
+	if ids := l.global[family][callName]; len(ids) > 0 {
+		return l.chooseCall(ids, "heuristic")
 	}
 
 	return nil, "", false
 }
 
+// resolveQualified returns the symbols named container.callName, trying the
+// container as written and then its innermost type, and preferring
+// candidates declared in sourceFile.
+func (l symbolLookups) resolveQualified(sourceFile, container, callName string) []string {
+	if container == "" {
+		return nil
+	}
+	ids := l.qualified[container+"."+callName]
+	if len(ids) == 0 {
+		ids = l.qualified[parser.InnermostName(container)+"."+callName]
+	}
+	local := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if file, _ := ParseNodeID(id); file == sourceFile {
+			local = append(local, id)
+		}
+	}
+	if len(local) > 0 {
+		return local
+	}
+	return ids
+}
+
+// maxEmbedDepth bounds how many levels of embedded Go fields are searched
+// for a promoted method.
+const maxEmbedDepth = 3
+
+// resolveTyped resolves a method call whose receiver type the parser
+// inferred (w.WriteAll() with w a *Writer) to that type's method in the
+// package declaring the type: the caller's directory for "Writer", the
+// imported package for "csv.Writer". Python annotations are looked up like
+// supertypes and only methods of the class they resolve to are returned.
+func (l symbolLookups) resolveTyped(sourceFile string, call parser.CallSite) []string {
+	receiverType, method := strings.TrimSpace(call.ReceiverType), strings.TrimSpace(call.Name)
+	if l.languages[sourceFile] == "python" {
+		return l.resolveClassMethod(sourceFile, receiverType, method)
+	}
+	return l.resolveTypeMethod(sourceFile, receiverType, method, 0)
+}
+
+// resolveClassMethod finds method on the class named receiverType, as
+// annotated in sourceFile (user: User, repo: models.Repo).
+func (l symbolLookups) resolveClassMethod(sourceFile, receiverType, method string) []string {
+	if receiverType == "" {
+		return nil
+	}
+	typeIDs, _, ok := l.resolveType(sourceFile, receiverType)
+	if !ok {
+		return nil
+	}
+	out := make([]string, 0)
+	for _, typeID := range typeIDs {
+		typeFile, typeName := ParseNodeID(typeID)
+		for _, id := range l.qualified[typeName+"."+method] {
+			if file, _ := ParseNodeID(id); file == typeFile {
+				out = append(out, id)
+			}
+		}
+	}
+	return out
+}
+
+// resolveTypeMethod finds method on receiverType as named from sourceFile.
+// When the type declares no such method, the types it embeds are searched
+// level by level as Go promotes their methods; several matches at the same
+// depth are returned together and end up ambiguous.
+func (l symbolLookups) resolveTypeMethod(sourceFile, receiverType, method string, depth int) []string {
+	if receiverType == "" {
+		return nil
+	}
+	packageAlias, typeName := "", receiverType
+	if idx := strings.LastIndex(receiverType, "."); idx != -1 {
+		packageAlias, typeName = receiverType[:idx], receiverType[idx+1:]
+	}
+
+	var inPackage func(file string) bool
+	if packageAlias == "" {
+		sourceDir := filepath.Dir(sourceFile)
+		inPackage = func(file string) bool { return filepath.Dir(file) == sourceDir }
+	} else {
+		candidate, ok := l.importAliasCandidates[sourceFile][packageAlias]
+		if !ok {
+			return nil
+		}
+		inPackage = func(file string) bool {
+			_, found := slices.BinarySearch(candidate.Files, file)
+			return found
+		}
+	}
+
+	out := make([]string, 0)
+	for _, id := range l.qualified[typeName+"."+method] {
+		if file, _ := ParseNodeID(id); inPackage(file) {
+			out = append(out, id)
+		}
+	}
+	if len(out) > 0 || depth >= maxEmbedDepth {
+		return out
+	}
+
+	for _, typeID := range l.types[typeName] {
+		file, _ := ParseNodeID(typeID)
+		if !inPackage(file) {
+			continue
+		}
+		for _, embedded := range l.embeds[typeID] {
+			out = append(out, l.resolveTypeMethod(file, embedded, method, depth+1)...)
+		}
+	}
+	return out
+}
+
+// resolveNamespaceQualified resolves calls qualified by a class name
+// (Invoice.Create), a namespace and class (Acme.Billing.Invoice.Create) or a
+// namespace alone (new Acme.Billing.Invoice()) to files that declare the
+// class in that namespace, or in one the source file can see. Symbols are
+// flat per file, so a method matches any class declared in the same file.
+func (l symbolLookups) resolveNamespaceQualified(sourceFile string, call parser.CallSite) []string {
+	qualifier := namespacePath(call.Qualifier)
+	if qualifier == "" || callIsReceiverScoped(call) {
+		return nil
+	}
+	callName := strings.TrimSpace(call.Name)
+
+	out := make([]string, 0)
+	for _, file := range l.namespaces.files[qualifier] {
+		out = append(out, l.namespaces.types[file][callName]...)
+	}
+	if len(out) > 0 {
+		return dedupeAndSort(out)
+	}
+
+	namespace, typeName := path.Split(qualifier)
+	namespace = strings.TrimSuffix(namespace, "/")
+	scopes := []string{namespace}
+	if namespace == "" {
+		if imported, ok := l.namespaces.typeImports[sourceFile][typeName]; ok {
+			namespace, typeName = path.Split(imported)
+			scopes = []string{strings.TrimSuffix(namespace, "/")}
+		} else {
+			scopes = l.namespaces.scopes[sourceFile]
+		}
+	}
+	for _, scope := range scopes {
+		for _, file := range l.namespaces.files[scope] {
+			if len(l.namespaces.types[file][typeName]) == 0 {
+				continue
+			}
+			out = append(out, l.byFileMethods[file][callName]...)
+		}
+	}
+	return dedupeAndSort(out)
+}
+
 func callIsReceiverScoped(call parser.CallSite) bool {
 	switch strings.TrimSpace(call.Receiver) {
 	case "self", "this", "cls":
@@ -399,11 +989,32 @@ func primaryQualifier(value string) string {
 	return strings.TrimSpace(value)
 }
 
+// chooseCall is chooseUnique for call targets: a header prototype and the
+// definition it declares are one function, so when both match the
+// definition wins.
+func (l symbolLookups) chooseCall(targetIDs []string, confidence string) ([]string, string, bool) {
+	definitions := make([]string, 0, len(targetIDs))
+	for _, id := range targetIDs {
+		if !l.prototypes[id] {
+			definitions = append(definitions, id)
+		}
+	}
+	if len(definitions) > 0 {
+		targetIDs = definitions
+	}
+	return chooseUnique(targetIDs, confidence)
+}
+
+// chooseUnique accepts a single candidate. Several candidates are reported
+// as "ambiguous" with ok=false so callers can tell them from no match.
 func chooseUnique(targetIDs []string, confidence string) ([]string, string, bool) {
 	targetIDs = dedupeAndSort(targetIDs)
 	if len(targetIDs) == 1 {
 		return targetIDs, confidence, true
 	}
+	if len(targetIDs) > 1 {
+		return nil, "ambiguous", false
+	}
 	return nil, "", false
 }
 
@@ -447,6 +1058,10 @@ func buildImportAliasCandidates(result *parser.ParseResult) map[string]map[strin
 	}
 
 	for _, source := range result.Files {
+		// Proto imports name files, not modules; resolveIncludes handles them.
+		if source.Language == "proto" {
+			continue
+		}
 		aliases := make(map[string]string, len(source.ImportAliases)+len(source.Imports))
 		for alias, target := range source.ImportAliases {
 			aliases[strings.TrimSpace(alias)] = strings.TrimSpace(target)
@@ -504,14 +1119,36 @@ func matchImportCandidates(sourceFile, importPath string, allFiles []string) []s
 	}
 
 	matches := make([]string, 0)
+	packageMatches := make([]string, 0)
 	for _, target := range allFiles {
 		if importMatchesFile(sourceFile, importPath, target) {
 			matches = append(matches, target)
 		}
+		if importMatchesPackagePath(importPath, target) {
+			packageMatches = append(packageMatches, target)
+		}
+	}
+	// A full package path match (e.g. com/acme/util/Strings under src/main/java)
+	// is more specific than a base-name match, so prefer it when present.
+	if len(packageMatches) > 0 {
+		return packageMatches
 	}
 	return matches
 }
 
+func importMatchesPackagePath(importPath, targetFile string) bool {
+	normalizedImport := strings.Trim(filepath.ToSlash(importPath), "/")
+	if !strings.Contains(normalizedImport, "/") || strings.HasPrefix(normalizedImport, ".") {
+		return false
+	}
+	targetNoExt := filepath.ToSlash(strings.TrimSuffix(targetFile, filepath.Ext(targetFile)))
+	targetDir := filepath.ToSlash(filepath.Dir(targetFile))
+	return targetNoExt == normalizedImport ||
+		targetDir == normalizedImport ||
+		strings.HasSuffix(targetNoExt, "/"+normalizedImport) ||
+		strings.HasSuffix(targetDir, "/"+normalizedImport)
+}
+
 func importMatchesFile(sourceFile, importPath, targetFile string) bool {
 	targetNoExt := strings.TrimSuffix(targetFile, filepath.Ext(targetFile))
 	targetDir := filepath.Dir(targetFile)
@@ -535,6 +1172,83 @@ func importMatchesFile(sourceFile, importPath, targetFile string) bool {
 		strings.HasSuffix(normalizedImport, "/"+normalizedBase)
 }
 
+// includeLanguages use textual #include directives, so a file depends on the
+// headers it includes even when no call crosses into them. Proto imports are
+// file paths too ("google/protobuf/empty.proto") and resolve the same way, to
+// that exact file only.
+var includeLanguages = map[string]bool{
+	"c":     true,
+	"cpp":   true,
+	"proto": true,
+}
+
+// resolveIncludes maps #include paths to repository files. Quoted includes are
+// tried relative to the including file first; otherwise every file whose path
+// ends with the include path matches, since include search paths are unknown.
+func (g *Graph) resolveIncludes(result *parser.ParseResult, sourceFiles map[string]bool) {
+	allFiles := make(map[string]string, len(result.Files))
+	for _, file := range result.Files {
+		allFiles[filepath.ToSlash(file.Path)] = file.Path
+	}
+
+	for _, file := range result.Files {
+		if !includeLanguages[file.Language] || (sourceFiles != nil && !sourceFiles[file.Path]) {
+			continue
+		}
+		includes := make([]string, 0)
+		for _, include := range file.Imports {
+			include = filepath.ToSlash(strings.TrimSpace(include))
+			if include == "" {
+				continue
+			}
+			relative := path.Clean(path.Join(path.Dir(filepath.ToSlash(file.Path)), include))
+			if target, ok := allFiles[relative]; ok {
+				includes = append(includes, target)
+				continue
+			}
+			for candidate, target := range allFiles {
+				if candidate == include || strings.HasSuffix(candidate, "/"+include) {
+					includes = append(includes, target)
+				}
+			}
+		}
+
+		deps := make([]string, 0, len(includes))
+		for _, include := range dedupeAndSort(includes) {
+			if include != file.Path {
+				deps = append(deps, include)
+			}
+		}
+		if len(deps) > 0 {
+			g.FileIncludes[file.Path] = deps
+		}
+	}
+}
+
+// resolveImports records, for every source file, the repository files its
+// imports resolve to (the candidates used for import-aware call resolution)
+// together with its #includes.
+func (g *Graph) resolveImports(result *parser.ParseResult, lookups symbolLookups, sourceFiles map[string]bool) {
+	for _, file := range result.Files {
+		if sourceFiles != nil && !sourceFiles[file.Path] {
+			continue
+		}
+		targets := append([]string(nil), g.FileIncludes[file.Path]...)
+		for _, candidate := range lookups.importAliasCandidates[file.Path] {
+			targets = append(targets, candidate.Files...)
+		}
+		imports := make([]string, 0, len(targets))
+		for _, target := range dedupeAndSort(targets) {
+			if target != file.Path {
+				imports = append(imports, target)
+			}
+		}
+		if len(imports) > 0 {
+			g.FileImports[file.Path] = imports
+		}
+	}
+}
+
 func defaultAliasFromImport(importPath string) string {
 	importPath = strings.TrimSpace(strings.Trim(importPath, `"'`))
 	if importPath == "" {
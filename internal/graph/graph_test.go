@@ -2,6 +2,8 @@ package graph
 
 import (
 	"math"
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/morozRed/skelly/internal/parser"
@@ -197,6 +199,753 @@ func TestBuildGraphFallsBackForQualifiedCallsWithoutAliasMatch(t *testing.T) {
 	}
 }
 
+func TestBuildGraphKeepsNameLookupsWithinLanguageFamily(t *testing.T) {
+	result := &parser.ParseResult{
+		Files: []parser.FileSymbols{
+			{
+				Path:     "src/render.php",
+				Language: "php",
+				Symbols: []parser.Symbol{
+					{Name: "show", Kind: parser.SymbolFunction, Line: 1, Calls: []parser.CallSite{{Name: "format"}}},
+				},
+			},
+			{
+				Path:     "src/format.rs",
+				Language: "rust",
+				Symbols: []parser.Symbol{
+					{Name: "format", Kind: parser.SymbolFunction, Line: 1},
+				},
+			},
+			{
+				Path:     "web/app.ts",
+				Language: "typescript",
+				Symbols: []parser.Symbol{
+					{Name: "main", Kind: parser.SymbolFunction, Line: 1, Calls: []parser.CallSite{{Name: "mount"}}},
+				},
+			},
+			{
+				Path:     "lib/mount.js",
+				Language: "javascript",
+				Symbols: []parser.Symbol{
+					{Name: "mount", Kind: parser.SymbolFunction, Line: 1},
+				},
+			},
+			{
+				Path:     "native/engine.cpp",
+				Language: "cpp",
+				Symbols: []parser.Symbol{
+					{Name: "start", Kind: parser.SymbolFunction, Line: 1, Calls: []parser.CallSite{{Name: "init_table"}}},
+				},
+			},
+			{
+				Path:     "native/table.c",
+				Language: "c",
+				Symbols: []parser.Symbol{
+					{Name: "init_table", Kind: parser.SymbolFunction, Line: 1},
+				},
+			},
+		},
+	}
+
+	g := BuildFromParseResult(result)
+	if show := findNodeByName(t, g, "src/render.php", "show"); len(show.OutEdges) != 0 {
+		t.Fatalf("expected a PHP call not to resolve to a Rust function, got %v", show.OutEdges)
+	}
+	main := findNodeByName(t, g, "web/app.ts", "main")
+	mount := findNodeByName(t, g, "lib/mount.js", "mount")
+	if main.OutEdgeConfidence[mount.ID] != "heuristic" {
+		t.Fatalf("expected TypeScript to resolve calls into JavaScript, got %v", main.OutEdges)
+	}
+	start := findNodeByName(t, g, "native/engine.cpp", "start")
+	initTable := findNodeByName(t, g, "native/table.c", "init_table")
+	if start.OutEdgeConfidence[initTable.ID] != "heuristic" {
+		t.Fatalf("expected C++ to resolve calls into C, got %v", start.OutEdges)
+	}
+}
+
+func TestBuildGraphResolvesCallsToDefinitionsOverPrototypes(t *testing.T) {
+	result := &parser.ParseResult{
+		Files: []parser.FileSymbols{
+			{
+				Path:     "src/main.c",
+				Language: "c",
+				Symbols: []parser.Symbol{
+					{Name: "main", Kind: parser.SymbolFunction, Line: 1, Calls: []parser.CallSite{{Name: "parse_args"}, {Name: "log_line"}}},
+				},
+			},
+			{
+				Path:     "include/args.h",
+				Language: "c",
+				Symbols: []parser.Symbol{
+					{Name: "parse_args", Kind: parser.SymbolFunction, Line: 3, Signature: "int parse_args(int argc)", Prototype: true},
+				},
+			},
+			{
+				Path:     "lib/args.c",
+				Language: "c",
+				Symbols: []parser.Symbol{
+					{Name: "parse_args", Kind: parser.SymbolFunction, Line: 5, Signature: "int parse_args(int argc)"},
+				},
+			},
+			{
+				Path:     "include/log.h",
+				Language: "c",
+				Symbols: []parser.Symbol{
+					{Name: "log_line", Kind: parser.SymbolFunction, Line: 2, Signature: "void log_line(const char *msg)", Prototype: true},
+				},
+			},
+		},
+	}
+
+	g := BuildFromParseResult(result)
+	main := findNodeByName(t, g, "src/main.c", "main")
+	definition := findNodeByName(t, g, "lib/args.c", "parse_args")
+	if main.OutEdgeConfidence[definition.ID] != "heuristic" {
+		t.Fatalf("expected parse_args to resolve to its definition, got %v", main.OutEdges)
+	}
+	logLine := findNodeByName(t, g, "include/log.h", "log_line")
+	if main.OutEdgeConfidence[logLine.ID] != "heuristic" {
+		t.Fatalf("expected a prototype without a definition to be the call target, got %v", main.OutEdges)
+	}
+	if len(main.OutEdges) != 2 {
+		t.Fatalf("expected two call edges, got %v", main.OutEdges)
+	}
+}
+
+func TestBuildGraphResolvesPackageImportsUnderSourceRoots(t *testing.T) {
+	result := &parser.ParseResult{
+		Files: []parser.FileSymbols{
+			{
+				Path:          "src/main/java/com/acme/app/Service.java",
+				Imports:       []string{"com/acme/util/Strings"},
+				ImportAliases: map[string]string{"Strings": "com/acme/util/Strings"},
+				Symbols: []parser.Symbol{
+					{
+						Name: "run",
+						Kind: parser.SymbolMethod,
+						Line: 5,
+						Calls: []parser.CallSite{
+							{Name: "trim", Qualifier: "Strings"},
+						},
+					},
+				},
+			},
+			{
+				Path: "src/main/java/com/acme/util/Strings.java",
+				Symbols: []parser.Symbol{
+					{Name: "trim", Kind: parser.SymbolMethod, Line: 3},
+				},
+			},
+			{
+				Path: "src/main/java/com/other/Strings.java",
+				Symbols: []parser.Symbol{
+					{Name: "trim", Kind: parser.SymbolMethod, Line: 3},
+				},
+			},
+		},
+	}
+
+	g := BuildFromParseResult(result)
+	runNode := findNodeByName(t, g, "src/main/java/com/acme/app/Service.java", "run")
+	trimNode := findNodeByName(t, g, "src/main/java/com/acme/util/Strings.java", "trim")
+
+	if runNode.OutEdgeConfidence[trimNode.ID] != "heuristic" {
+		t.Fatalf("expected package import to resolve through the source root, got %#v", runNode.OutEdgeConfidence)
+	}
+}
+
+func TestBuildGraphResolvesNamespaceQualifiedCalls(t *testing.T) {
+	namespace := func(name string) parser.Symbol {
+		return parser.Symbol{Name: name, Kind: parser.SymbolModule, Signature: "namespace " + name, Line: 1}
+	}
+	result := &parser.ParseResult{
+		Files: []parser.FileSymbols{
+			{
+				Path:    "Web/OrderController.cs",
+				Imports: []string{"Acme/Billing"},
+				Symbols: []parser.Symbol{
+					namespace("Acme.Web"),
+					{Name: "OrderController", Kind: parser.SymbolClass, Line: 3},
+					{
+						Name: "Submit",
+						Kind: parser.SymbolMethod,
+						Line: 5,
+						Calls: []parser.CallSite{
+							{Name: "Create", Qualifier: "Invoice"},
+							{Name: "Void", Qualifier: "Acme.Legacy.Invoice"},
+							{Name: "Invoice", Qualifier: "Acme.Legacy"},
+						},
+					},
+				},
+			},
+			{
+				Path: "Billing/Invoice.cs",
+				Symbols: []parser.Symbol{
+					namespace("Acme.Billing"),
+					{Name: "Invoice", Kind: parser.SymbolClass, Line: 3},
+					{Name: "Create", Kind: parser.SymbolMethod, Line: 5},
+					{Name: "Void", Kind: parser.SymbolMethod, Line: 7},
+				},
+			},
+			{
+				Path: "Legacy/Invoice.cs",
+				Symbols: []parser.Symbol{
+					namespace("Acme.Legacy"),
+					{Name: "Invoice", Kind: parser.SymbolClass, Line: 3},
+					{Name: "Create", Kind: parser.SymbolMethod, Line: 5},
+					{Name: "Void", Kind: parser.SymbolMethod, Line: 7},
+				},
+			},
+		},
+	}
+
+	g := BuildFromParseResult(result)
+	submitNode := findNodeByName(t, g, "Web/OrderController.cs", "Submit")
+	for _, target := range []*Node{
+		findNodeByName(t, g, "Billing/Invoice.cs", "Create"),
+		findNodeByName(t, g, "Legacy/Invoice.cs", "Void"),
+		findNodeByName(t, g, "Legacy/Invoice.cs", "Invoice"),
+	} {
+		if submitNode.OutEdgeConfidence[target.ID] != "resolved" {
+			t.Fatalf("expected namespace-qualified call to %s to resolve, got %#v", target.ID, submitNode.OutEdgeConfidence)
+		}
+	}
+	if len(submitNode.OutEdges) != 3 {
+		t.Fatalf("expected exactly three edges from Submit, got %#v", submitNode.OutEdges)
+	}
+}
+
+func TestBuildGraphResolvesCallsByContainer(t *testing.T) {
+	result := &parser.ParseResult{
+		Files: []parser.FileSymbols{
+			{
+				Path: "models.py",
+				Symbols: []parser.Symbol{
+					{Name: "User", Kind: parser.SymbolClass, Line: 1},
+					{
+						Name:      "save",
+						Kind:      parser.SymbolMethod,
+						Container: "User",
+						Line:      2,
+						Calls:     []parser.CallSite{{Name: "validate", Receiver: "self"}},
+					},
+					{Name: "validate", Kind: parser.SymbolMethod, Container: "User", Line: 4},
+					{Name: "Order", Kind: parser.SymbolClass, Line: 6},
+					{Name: "save", Kind: parser.SymbolMethod, Container: "Order", Line: 7},
+					{Name: "validate", Kind: parser.SymbolMethod, Container: "Order", Line: 9},
+				},
+			},
+			{
+				Path: "jobs.py",
+				Symbols: []parser.Symbol{
+					{
+						Name:  "checkout",
+						Kind:  parser.SymbolFunction,
+						Line:  1,
+						Calls: []parser.CallSite{{Name: "save", Qualifier: "Order"}},
+					},
+				},
+			},
+		},
+	}
+
+	g := BuildFromParseResult(result)
+	byQualifiedName := make(map[string]*Node)
+	for _, node := range g.NodesForFile("models.py") {
+		byQualifiedName[node.Symbol.QualifiedName()] = node
+	}
+	userSave := byQualifiedName["User.save"]
+	if userSave.OutEdgeConfidence[byQualifiedName["User.validate"].ID] != "resolved" {
+		t.Fatalf("expected self.validate() to resolve to User.validate, got %#v", userSave.OutEdgeConfidence)
+	}
+	if len(userSave.OutEdges) != 1 {
+		t.Fatalf("expected a single edge from User.save, got %#v", userSave.OutEdges)
+	}
+
+	checkout := findNodeByName(t, g, "jobs.py", "checkout")
+	if checkout.OutEdgeConfidence[byQualifiedName["Order.save"].ID] != "resolved" {
+		t.Fatalf("expected Order.save() to resolve, got %#v", checkout.OutEdgeConfidence)
+	}
+	if len(checkout.OutEdges) != 1 {
+		t.Fatalf("expected a single edge from checkout, got %#v", checkout.OutEdges)
+	}
+}
+
+func TestBuildGraphResolvesGoMethodsByReceiverType(t *testing.T) {
+	method := func(container, name string) parser.Symbol {
+		return parser.Symbol{Name: name, Kind: parser.SymbolMethod, Container: container, Line: 3}
+	}
+	result := &parser.ParseResult{
+		Files: []parser.FileSymbols{
+			{
+				Path:    "export/export.go",
+				Imports: []string{"acme/csvx"},
+				Symbols: []parser.Symbol{
+					{
+						Name: "Run",
+						Kind: parser.SymbolFunction,
+						Line: 5,
+						Calls: []parser.CallSite{
+							{Name: "WriteAll", Qualifier: "w", Receiver: "w", ReceiverType: "Writer"},
+							{Name: "Flush", Qualifier: "out", Receiver: "out", ReceiverType: "csvx.Writer"},
+						},
+					},
+				},
+			},
+			{Path: "export/writer.go", Symbols: []parser.Symbol{method("Writer", "WriteAll")}},
+			{Path: "export/buffer.go", Symbols: []parser.Symbol{method("Buffer", "Flush")}},
+			{Path: "acme/csvx/writer.go", Symbols: []parser.Symbol{method("Writer", "WriteAll"), method("Writer", "Flush")}},
+			{Path: "store/file.go", Symbols: []parser.Symbol{method("File", "WriteAll")}},
+		},
+	}
+
+	g := BuildFromParseResult(result)
+	run := findNodeByName(t, g, "export/export.go", "Run")
+	for _, target := range []*Node{
+		findNodeByName(t, g, "export/writer.go", "WriteAll"),
+		findNodeByName(t, g, "acme/csvx/writer.go", "Flush"),
+	} {
+		if run.OutEdgeConfidence[target.ID] != "resolved" {
+			t.Fatalf("expected typed call to %s to resolve, got %#v", target.ID, run.OutEdgeConfidence)
+		}
+	}
+	if len(run.OutEdges) != 2 {
+		t.Fatalf("expected exactly two edges from Run, got %#v", run.OutEdges)
+	}
+}
+
+func TestBuildGraphResolvesPythonCallsByAnnotatedType(t *testing.T) {
+	method := func(container, name string) parser.Symbol {
+		return parser.Symbol{Name: name, Kind: parser.SymbolMethod, Container: container, Line: 3}
+	}
+	class := func(name string) parser.Symbol {
+		return parser.Symbol{Name: name, Kind: parser.SymbolClass, Line: 1}
+	}
+	result := &parser.ParseResult{
+		Files: []parser.FileSymbols{
+			{
+				Path:     "app/checkout.py",
+				Language: "python",
+				Imports:  []string{"models"},
+				Symbols: []parser.Symbol{
+					{
+						Name: "checkout",
+						Kind: parser.SymbolFunction,
+						Line: 5,
+						Calls: []parser.CallSite{
+							{Name: "save", Qualifier: "user", ReceiverType: "User"},
+							{Name: "save", Qualifier: "order", ReceiverType: "models.Order"},
+						},
+					},
+					class("User"),
+					method("User", "save"),
+				},
+			},
+			{Path: "app/models.py", Language: "python", Symbols: []parser.Symbol{class("Order"), method("Order", "save")}},
+			{Path: "app/audit.py", Language: "python", Symbols: []parser.Symbol{class("Log"), method("Log", "save")}},
+		},
+	}
+
+	g := BuildFromParseResult(result)
+	checkout := findNodeByName(t, g, "app/checkout.py", "checkout")
+	for _, target := range []*Node{
+		findNodeByName(t, g, "app/checkout.py", "save"),
+		findNodeByName(t, g, "app/models.py", "save"),
+	} {
+		if checkout.OutEdgeConfidence[target.ID] != "resolved" {
+			t.Fatalf("expected annotated call to %s to resolve, got %#v", target.ID, checkout.OutEdgeConfidence)
+		}
+	}
+	if len(checkout.OutEdges) != 2 {
+		t.Fatalf("expected exactly two edges from checkout, got %#v", checkout.OutEdges)
+	}
+}
+
+func TestBuildGraphLinksGoTypesToSatisfiedInterfaces(t *testing.T) {
+	method := func(container, name string) parser.Symbol {
+		return parser.Symbol{Name: name, Kind: parser.SymbolMethod, Container: container, Line: 5}
+	}
+	result := &parser.ParseResult{
+		Files: []parser.FileSymbols{
+			{
+				Path:     "store/store.go",
+				Language: "go",
+				Symbols: []parser.Symbol{
+					{Name: "Store", Kind: parser.SymbolInterface, Methods: []string{"Get", "Put"}, Line: 1},
+					{Name: "Any", Kind: parser.SymbolInterface, Line: 3},
+				},
+			},
+			{
+				Path:     "store/memory.go",
+				Language: "go",
+				Symbols: []parser.Symbol{
+					{Name: "Memory", Kind: parser.SymbolStruct, Line: 1},
+					method("Memory", "Get"),
+				},
+			},
+			{Path: "store/memory_put.go", Language: "go", Symbols: []parser.Symbol{method("Memory", "Put")}},
+			{
+				Path:     "cache/cache.go",
+				Language: "go",
+				Symbols: []parser.Symbol{
+					{Name: "ReadOnly", Kind: parser.SymbolStruct, Line: 1},
+					method("ReadOnly", "Get"),
+				},
+			},
+			{Path: "other/readonly.go", Language: "go", Symbols: []parser.Symbol{method("ReadOnly", "Put")}},
+		},
+	}
+
+	g := BuildFromParseResult(result)
+	store := findNodeByName(t, g, "store/store.go", "Store")
+	memory := findNodeByName(t, g, "store/memory.go", "Memory")
+	if len(g.InEdgesOfKind(store, EdgeImplement)) != 1 || g.InEdgesOfKind(store, EdgeImplement)[0] != memory.ID {
+		t.Fatalf("expected Memory to implement Store, got %#v", g.InEdgesOfKind(store, EdgeImplement))
+	}
+	if len(memory.OutEdgesOfKind(EdgeImplement)) != 1 || memory.OutEdgesOfKind(EdgeImplement)[0] != store.ID {
+		t.Fatalf("expected Memory to list Store, got %#v", memory.OutEdgesOfKind(EdgeImplement))
+	}
+	if empty := findNodeByName(t, g, "store/store.go", "Any"); len(g.InEdgesOfKind(empty, EdgeImplement)) != 0 {
+		t.Fatalf("expected interfaces without methods to be skipped, got %#v", g.InEdgesOfKind(empty, EdgeImplement))
+	}
+	if readOnly := findNodeByName(t, g, "cache/cache.go", "ReadOnly"); len(readOnly.OutEdgesOfKind(EdgeImplement)) != 0 {
+		t.Fatalf("expected partial method sets not to implement Store, got %#v", readOnly.OutEdgesOfKind(EdgeImplement))
+	}
+}
+
+func TestBuildGraphPromotesGoEmbeddedMethods(t *testing.T) {
+	method := func(container, name string) parser.Symbol {
+		return parser.Symbol{Name: name, Kind: parser.SymbolMethod, Container: container, Line: 5}
+	}
+	embed := func(name string) parser.TypeRelation {
+		return parser.TypeRelation{Name: name, Relation: parser.RelationInherit}
+	}
+	result := &parser.ParseResult{
+		Files: []parser.FileSymbols{
+			{
+				Path:     "app/server.go",
+				Language: "go",
+				Imports:  []string{"acme/csvx"},
+				Symbols: []parser.Symbol{
+					{Name: "Server", Kind: parser.SymbolStruct, Line: 1, Bases: []parser.TypeRelation{embed("Logger"), embed("csvx.Writer")}},
+					{
+						Name: "Run",
+						Kind: parser.SymbolFunction,
+						Line: 3,
+						Calls: []parser.CallSite{
+							{Name: "Log", Qualifier: "s", Receiver: "s", ReceiverType: "Server"},
+							{Name: "Flush", Qualifier: "s", Receiver: "s", ReceiverType: "Server"},
+						},
+					},
+				},
+			},
+			{
+				Path:     "app/logger.go",
+				Language: "go",
+				Symbols: []parser.Symbol{
+					{Name: "Logger", Kind: parser.SymbolStruct, Line: 1},
+					method("Logger", "Log"),
+					{Name: "Reader", Kind: parser.SymbolInterface, Methods: []string{"Log"}, Line: 7},
+					{Name: "ReadFlusher", Kind: parser.SymbolInterface, Methods: []string{"Close"}, Bases: []parser.TypeRelation{embed("Reader")}, Line: 9},
+				},
+			},
+			{Path: "app/close.go", Language: "go", Symbols: []parser.Symbol{method("Server", "Close")}},
+			{Path: "acme/csvx/writer.go", Language: "go", Symbols: []parser.Symbol{{Name: "Writer", Kind: parser.SymbolStruct, Line: 1}, method("Writer", "Flush")}},
+		},
+	}
+
+	g := BuildFromParseResult(result)
+	run := findNodeByName(t, g, "app/server.go", "Run")
+	for _, target := range []*Node{
+		findNodeByName(t, g, "app/logger.go", "Log"),
+		findNodeByName(t, g, "acme/csvx/writer.go", "Flush"),
+	} {
+		if run.OutEdgeConfidence[target.ID] != "resolved" {
+			t.Fatalf("expected promoted call to %s to resolve, got %#v", target.ID, run.OutEdgeConfidence)
+		}
+	}
+
+	server := findNodeByName(t, g, "app/server.go", "Server")
+	logger := findNodeByName(t, g, "app/logger.go", "Logger")
+	if !slices.Contains(server.OutEdgesOfKind(EdgeInherit), logger.ID) {
+		t.Fatalf("expected Server to record its embedded Logger, got %#v", server.OutEdgesOfKind(EdgeInherit))
+	}
+	want := []string{
+		findNodeByName(t, g, "app/logger.go", "ReadFlusher").ID,
+		findNodeByName(t, g, "app/logger.go", "Reader").ID,
+	}
+	if got := server.OutEdgesOfKind(EdgeImplement); !slices.Equal(got, dedupeAndSort(want)) {
+		t.Fatalf("expected Server to implement Reader and ReadFlusher through promotion, got %#v", got)
+	}
+	if got := logger.OutEdgesOfKind(EdgeImplement); len(got) != 1 {
+		t.Fatalf("expected Logger to implement Reader only, got %#v", got)
+	}
+}
+
+func TestBuildGraphLinksDeclaredSupertypes(t *testing.T) {
+	class := func(name, container string, bases ...parser.TypeRelation) parser.Symbol {
+		return parser.Symbol{Name: name, Kind: parser.SymbolClass, Container: container, Bases: bases, Line: 1}
+	}
+	inherit := func(name string) parser.TypeRelation {
+		return parser.TypeRelation{Name: name, Relation: parser.RelationInherit}
+	}
+	result := &parser.ParseResult{
+		Files: []parser.FileSymbols{
+			{
+				Path:          "app/user.py",
+				ImportAliases: map[string]string{"Base": "app/base#Base"},
+				Symbols:       []parser.Symbol{class("User", "", inherit("Base"), inherit("models.Model"))},
+			},
+			{Path: "app/base.py", Symbols: []parser.Symbol{class("Base", "")}},
+			{Path: "legacy/base.py", Symbols: []parser.Symbol{class("Base", ""), class("Model", "")}},
+			{Path: "admin/base.rb", Symbols: []parser.Symbol{{Name: "Admin", Kind: parser.SymbolModule}, class("Base", "Admin")}},
+			{Path: "admin/user.rb", Symbols: []parser.Symbol{class("User", "", inherit("Admin::Base"))}},
+			{
+				Path: "web/repo.ts",
+				Symbols: []parser.Symbol{
+					{Name: "Repo", Kind: parser.SymbolInterface, Line: 1},
+					class("UserRepo", "", parser.TypeRelation{Name: "Repo", Relation: parser.RelationImplement}),
+				},
+			},
+		},
+	}
+
+	g := BuildFromParseResult(result)
+	pyUser := findNodeByName(t, g, "app/user.py", "User")
+	pyBase := findNodeByName(t, g, "app/base.py", "Base")
+	if len(pyUser.OutEdgesOfKind(EdgeInherit)) != 1 || pyUser.OutEdgesOfKind(EdgeInherit)[0] != pyBase.ID {
+		t.Fatalf("expected User to inherit the imported Base only, got %#v", pyUser.OutEdgesOfKind(EdgeInherit))
+	}
+	if pyUser.OutEdgeConfidence[pyBase.ID] != "heuristic" {
+		t.Fatalf("expected import-matched base to be heuristic, got %#v", pyUser.OutEdgeConfidence)
+	}
+	if len(g.InEdgesOfKind(pyBase, EdgeInherit)) != 1 || g.InEdgesOfKind(pyBase, EdgeInherit)[0] != pyUser.ID {
+		t.Fatalf("expected Base to list User, got %#v", g.InEdgesOfKind(pyBase, EdgeInherit))
+	}
+	if len(pyUser.OutEdgesOfKind(EdgeCall)) != 0 || len(g.InEdgesOfKind(pyBase, EdgeCall)) != 0 {
+		t.Fatalf("expected inherit edges to stay out of the call edges, got %#v", pyUser.OutEdgeKinds)
+	}
+	if !slices.Equal(g.FileImports["app/user.py"], []string{"app/base.py"}) {
+		t.Fatalf("expected app/user.py to import app/base.py, got %#v", g.FileImports)
+	}
+
+	rbUser := findNodeByName(t, g, "admin/user.rb", "User")
+	rbBase := findNodeByName(t, g, "admin/base.rb", "Base")
+	if len(rbUser.OutEdgesOfKind(EdgeInherit)) != 1 || rbUser.OutEdgesOfKind(EdgeInherit)[0] != rbBase.ID || rbUser.OutEdgeConfidence[rbBase.ID] != "resolved" {
+		t.Fatalf("expected Admin::Base to resolve, got %#v %#v", rbUser.OutEdgesOfKind(EdgeInherit), rbUser.OutEdgeConfidence)
+	}
+
+	repo := findNodeByName(t, g, "web/repo.ts", "Repo")
+	userRepo := findNodeByName(t, g, "web/repo.ts", "UserRepo")
+	if len(userRepo.OutEdgesOfKind(EdgeImplement)) != 1 || userRepo.OutEdgesOfKind(EdgeImplement)[0] != repo.ID || len(userRepo.OutEdgesOfKind(EdgeInherit)) != 0 {
+		t.Fatalf("expected UserRepo to implement Repo, got %#v / %#v", userRepo.OutEdgesOfKind(EdgeImplement), userRepo.OutEdgesOfKind(EdgeInherit))
+	}
+	if len(g.InEdgesOfKind(repo, EdgeImplement)) != 1 || g.InEdgesOfKind(repo, EdgeImplement)[0] != userRepo.ID {
+		t.Fatalf("expected Repo to list UserRepo, got %#v", g.InEdgesOfKind(repo, EdgeImplement))
+	}
+}
+
+func TestBuildGraphLinksTypeReferences(t *testing.T) {
+	result := &parser.ParseResult{
+		Files: []parser.FileSymbols{
+			{
+				Path: "store/store.go",
+				Symbols: []parser.Symbol{
+					{Name: "User", Kind: parser.SymbolStruct, Line: 1},
+					{Name: "Order", Kind: parser.SymbolStruct, Line: 2, References: []string{"User", "time.Time"}},
+					{
+						Name:       "Save",
+						Kind:       parser.SymbolFunction,
+						Line:       3,
+						References: []string{"User", "Order"},
+						Calls:      []parser.CallSite{{Name: "Order"}},
+					},
+				},
+			},
+		},
+	}
+
+	g := BuildFromParseResult(result)
+	user := findNodeByName(t, g, "store/store.go", "User")
+	order := findNodeByName(t, g, "store/store.go", "Order")
+	save := findNodeByName(t, g, "store/store.go", "Save")
+
+	if refs := order.OutEdgesOfKind(EdgeReference); len(refs) != 1 || refs[0] != user.ID {
+		t.Fatalf("expected Order to reference User only, got %#v", refs)
+	}
+	if order.OutEdgeConfidence[user.ID] != "heuristic" {
+		t.Fatalf("expected reference edges to be heuristic, got %#v", order.OutEdgeConfidence)
+	}
+	if refs := save.OutEdgesOfKind(EdgeReference); len(refs) != 1 || refs[0] != user.ID {
+		t.Fatalf("expected Save to reference User, got %#v", refs)
+	}
+	if save.EdgeKindTo(order.ID) != EdgeCall || save.OutEdgeConfidence[order.ID] != "resolved" {
+		t.Fatalf("expected the call edge to Order to win over its reference, got %s/%s", save.EdgeKindTo(order.ID), save.OutEdgeConfidence[order.ID])
+	}
+	if users := g.InEdgesOfKind(user, EdgeReference); !slices.Equal(users, dedupeAndSort([]string{order.ID, save.ID})) {
+		t.Fatalf("expected User to be referenced by Order and Save, got %#v", users)
+	}
+}
+
+func TestBuildGraphLinksGeneratedStubsToProtoDeclarations(t *testing.T) {
+	protoFile := func(path string, symbols ...parser.Symbol) parser.FileSymbols {
+		return parser.FileSymbols{Path: path, Language: "proto", Symbols: symbols}
+	}
+	result := &parser.ParseResult{
+		Files: []parser.FileSymbols{
+			protoFile("proto/invoice.proto",
+				parser.Symbol{Name: "Invoice", Kind: parser.SymbolStruct, Line: 1},
+				parser.Symbol{Name: "LineItem", Kind: parser.SymbolStruct, Container: "Invoice", Line: 2},
+				parser.Symbol{Name: "InvoiceService", Kind: parser.SymbolInterface, Line: 5},
+				parser.Symbol{Name: "GetInvoice", Kind: parser.SymbolMethod, Container: "InvoiceService", Line: 6},
+			),
+			protoFile("proto/legacy.proto", parser.Symbol{Name: "Invoice", Kind: parser.SymbolStruct, Line: 1}),
+			{
+				Path:     "gen/invoice.pb.go",
+				Language: "go",
+				Symbols: []parser.Symbol{
+					{Name: "Invoice", Kind: parser.SymbolStruct, Line: 1},
+					{Name: "Invoice_LineItem", Kind: parser.SymbolStruct, Line: 2},
+					{Name: "GetId", Kind: parser.SymbolMethod, Container: "Invoice", Line: 3},
+				},
+			},
+			{
+				Path:     "gen/invoice_grpc.pb.go",
+				Language: "go",
+				Symbols: []parser.Symbol{
+					{Name: "InvoiceServiceClient", Kind: parser.SymbolInterface, Line: 1},
+					{Name: "GetInvoice", Kind: parser.SymbolMethod, Container: "invoiceServiceClient", Line: 2},
+				},
+			},
+			{
+				Path:     "app/billing.go",
+				Language: "go",
+				Symbols: []parser.Symbol{
+					{Name: "Invoice", Kind: parser.SymbolStruct, Line: 1},
+					{Name: "Bill", Kind: parser.SymbolFunction, Line: 3, References: []string{"Invoice"}},
+				},
+			},
+		},
+	}
+
+	g := BuildFromParseResult(result)
+	pairs := [][2]*Node{
+		{findNodeByName(t, g, "gen/invoice.pb.go", "Invoice"), findNodeByName(t, g, "proto/invoice.proto", "Invoice")},
+		{findNodeByName(t, g, "gen/invoice.pb.go", "Invoice_LineItem"), findNodeByName(t, g, "proto/invoice.proto", "LineItem")},
+		{findNodeByName(t, g, "gen/invoice_grpc.pb.go", "InvoiceServiceClient"), findNodeByName(t, g, "proto/invoice.proto", "InvoiceService")},
+		{findNodeByName(t, g, "gen/invoice_grpc.pb.go", "GetInvoice"), findNodeByName(t, g, "proto/invoice.proto", "GetInvoice")},
+	}
+	for _, pair := range pairs {
+		generated, declaration := pair[0], pair[1]
+		if generated.EdgeKindTo(declaration.ID) != EdgeGeneratedFrom || generated.OutEdgeConfidence[declaration.ID] != "heuristic" {
+			t.Fatalf("expected %s to be generated from %s, got %#v", generated.ID, declaration.ID, generated.OutEdgeKinds)
+		}
+	}
+	if getID := findNodeByName(t, g, "gen/invoice.pb.go", "GetId"); len(getID.OutEdges) != 0 {
+		t.Fatalf("expected message getters to stay unlinked, got %#v", getID.OutEdges)
+	}
+	if app := findNodeByName(t, g, "app/billing.go", "Invoice"); len(app.OutEdges) != 0 {
+		t.Fatalf("expected hand-written code not to be linked, got %#v", app.OutEdges)
+	}
+	bill := findNodeByName(t, g, "app/billing.go", "Bill")
+	if target := findNodeByName(t, g, "app/billing.go", "Invoice"); bill.EdgeKindTo(target.ID) != EdgeReference {
+		t.Fatalf("expected Go reference to stay on the Go type, got %#v", bill.OutEdgeKinds)
+	}
+}
+
+func TestBuildGraphResolvesProtoImportsToProtoFilesOnly(t *testing.T) {
+	result := &parser.ParseResult{
+		Files: []parser.FileSymbols{
+			{
+				Path:     "proto/billing/v1/invoice.proto",
+				Language: "proto",
+				Imports:  []string{"google/protobuf/empty.proto", "billing/v1/customer.proto"},
+				Symbols:  []parser.Symbol{{Name: "Invoice", Kind: parser.SymbolStruct, Line: 1}},
+			},
+			{Path: "proto/billing/v1/customer.proto", Language: "proto", Symbols: []parser.Symbol{{Name: "Customer", Kind: parser.SymbolStruct, Line: 1}}},
+			{Path: "proto/billing/v1/customer.go", Language: "go", Symbols: []parser.Symbol{{Name: "Load", Kind: parser.SymbolFunction, Line: 1}}},
+			{Path: "src/empty.go", Language: "go", Symbols: []parser.Symbol{{Name: "Empty", Kind: parser.SymbolFunction, Line: 1}}},
+		},
+	}
+
+	g := BuildFromParseResult(result)
+	if imports := g.FileImports["proto/billing/v1/invoice.proto"]; !slices.Equal(imports, []string{"proto/billing/v1/customer.proto"}) {
+		t.Fatalf("expected the proto import to resolve to the .proto file only, got %#v", imports)
+	}
+}
+
+func TestBuildGraphCountsResolutionOutcomesPerLanguage(t *testing.T) {
+	result := &parser.ParseResult{
+		Files: []parser.FileSymbols{
+			{
+				Path:     "a.go",
+				Language: "go",
+				Symbols: []parser.Symbol{
+					{Name: "helper", Kind: parser.SymbolFunction, Line: 1},
+					{
+						Name: "run",
+						Kind: parser.SymbolFunction,
+						Line: 10,
+						Calls: []parser.CallSite{
+							{Name: "helper"},
+							{Name: "onlyB"},
+							{Name: "dup", Line: 12},
+							{Name: "missing", Qualifier: "pkg", Line: 13},
+						},
+					},
+				},
+			},
+			{
+				Path:     "b.go",
+				Language: "go",
+				Symbols: []parser.Symbol{
+					{Name: "onlyB", Kind: parser.SymbolFunction, Line: 3},
+					{Name: "dup", Kind: parser.SymbolFunction, Line: 4},
+				},
+			},
+			{
+				Path:     "c.go",
+				Language: "go",
+				Symbols: []parser.Symbol{
+					{Name: "dup", Kind: parser.SymbolFunction, Line: 5},
+				},
+			},
+			{
+				Path:     "d.py",
+				Language: "python",
+				Symbols: []parser.Symbol{
+					{Name: "shared", Kind: parser.SymbolFunction, Line: 1},
+				},
+			},
+			{
+				Path:     "e.py",
+				Language: "python",
+				Symbols: []parser.Symbol{
+					{Name: "dup", Kind: parser.SymbolFunction, Line: 5, Calls: []parser.CallSite{{Name: "shared"}, {Name: "helper"}}},
+				},
+			},
+		},
+	}
+
+	g := BuildFromParseResult(result)
+	stats := g.Resolution["go"]
+	if stats == nil || stats.Resolved != 1 || stats.Heuristic != 1 || stats.Ambiguous != 1 || stats.Unresolved != 1 {
+		t.Fatalf("unexpected go resolution stats: %#v", stats)
+	}
+	if len(stats.Samples) != 2 {
+		t.Fatalf("expected ambiguous and unresolved samples, got %#v", stats.Samples)
+	}
+	if sample := stats.Samples[0]; sample.Call != "dup" || !sample.Ambiguous || sample.Line != 12 || sample.Caller != "run" {
+		t.Fatalf("unexpected ambiguous sample: %#v", sample)
+	}
+	if sample := stats.Samples[1]; sample.Call != "pkg.missing" || sample.Ambiguous || sample.File != "a.go" {
+		t.Fatalf("unexpected unresolved sample: %#v", sample)
+	}
+	if python := g.Resolution["python"]; python == nil || python.Total() != 2 || python.Heuristic != 1 || python.Unresolved != 1 {
+		t.Fatalf("unexpected python resolution stats: %#v", python)
+	}
+}
+
 func TestPageRankRedistributesDanglingNodes(t *testing.T) {
 	result := &parser.ParseResult{
 		Files: []parser.FileSymbols{
@@ -239,6 +988,132 @@ func TestPageRankRedistributesDanglingNodes(t *testing.T) {
 	}
 }
 
+func TestPageRankLeavesExternalNodesOutOfTeleports(t *testing.T) {
+	files := []parser.FileSymbols{
+		{
+			Path: "a.go",
+			Symbols: []parser.Symbol{
+				{Name: "A", Kind: parser.SymbolFunction, Line: 1, Calls: []parser.CallSite{{Name: "B"}}},
+			},
+		},
+		{
+			Path:    "b.go",
+			Symbols: []parser.Symbol{{Name: "B", Kind: parser.SymbolFunction, Line: 1}},
+		},
+	}
+	firstParty := BuildFromParseResult(&parser.ParseResult{Files: files})
+
+	vendored := parser.FileSymbols{Path: "vendor/lib/lib.go", External: true}
+	for _, name := range []string{"V1", "V2", "V3", "V4"} {
+		vendored.Symbols = append(vendored.Symbols, parser.Symbol{Name: name, Kind: parser.SymbolFunction, Line: 1})
+	}
+	g := BuildFromParseResult(&parser.ParseResult{Files: append(slices.Clone(files), vendored)})
+
+	for _, name := range []string{"A", "B"} {
+		file := strings.ToLower(name) + ".go"
+		got, want := findNodeByName(t, g, file, name).PageRank, findNodeByName(t, firstParty, file, name).PageRank
+		if math.Abs(got-want) > 1e-9 {
+			t.Fatalf("expected uncalled vendored code not to dilute %s, got %f want %f", name, got, want)
+		}
+	}
+	if rank := findNodeByName(t, g, "vendor/lib/lib.go", "V1").PageRank; rank != 0 {
+		t.Fatalf("expected uncalled external node to rank zero, got %f", rank)
+	}
+
+	vendored.External = false
+	if BuildFromParseResult(&parser.ParseResult{Files: append(slices.Clone(files), vendored)}).Topology() == g.Topology() {
+		t.Fatalf("expected external classification to change the topology hash")
+	}
+}
+
+func TestRankFromReusesOrSeedsPriorScores(t *testing.T) {
+	chain := func(names ...string) *parser.ParseResult {
+		result := &parser.ParseResult{}
+		for i, name := range names {
+			symbol := parser.Symbol{Name: name, Kind: parser.SymbolFunction, Line: 1}
+			if i+1 < len(names) {
+				symbol.Calls = []parser.CallSite{{Name: names[i+1]}}
+			}
+			result.Files = append(result.Files, parser.FileSymbols{Path: name + ".go", Symbols: []parser.Symbol{symbol}})
+		}
+		return result
+	}
+
+	full := BuildFromParseResult(chain("A", "B", "C"))
+	prior := full.Ranks()
+	for id := range prior.Scores {
+		prior.Scores[id] = 0.5
+	}
+	reused := BuildFromParseResultWithRanks(chain("A", "B", "C"), prior)
+	for _, node := range reused.Nodes {
+		if node.PageRank != 0.5 {
+			t.Fatalf("expected unchanged topology to reuse prior scores, got %s=%f", node.ID, node.PageRank)
+		}
+	}
+
+	// A new edge changes the topology; iteration seeded from the prior
+	// scores ends closer to the fixed point than a run from scratch.
+	names := []string{"A", "B", "C", "D", "E", "F", "G", "H"}
+	before := BuildFromParseResult(chain(names...))
+	edited := chain(names...)
+	edited.Files[7].Symbols[0].Calls = []parser.CallSite{{Name: "F"}}
+	seeded := BuildFromParseResultWithRanks(edited, before.Ranks())
+	if seeded.Topology() == before.Topology() {
+		t.Fatalf("expected the new edge to change the topology")
+	}
+	scratch := BuildFromParseResult(edited)
+	converged := BuildFromParseResultForSources(edited, nil)
+	converged.calculatePageRank(500, pageRankDamping)
+	seededError, scratchError := 0.0, 0.0
+	for id, node := range converged.Nodes {
+		seededError += math.Abs(seeded.Nodes[id].PageRank - node.PageRank)
+		scratchError += math.Abs(scratch.Nodes[id].PageRank - node.PageRank)
+	}
+	if seededError > scratchError {
+		t.Fatalf("expected seeded ranks to be at least as converged as a run from scratch, got %g > %g", seededError, scratchError)
+	}
+
+	// Without enough prior scores, ranks are computed from scratch.
+	fresh := BuildFromParseResultWithRanks(chain("A", "B", "C"), Ranks{Topology: prior.Topology, Scores: map[string]float64{}})
+	for id, node := range fresh.Nodes {
+		if math.Abs(node.PageRank-full.Nodes[id].PageRank) > 1e-12 {
+			t.Fatalf("expected from-scratch rank of %s to be %f, got %f", id, full.Nodes[id].PageRank, node.PageRank)
+		}
+	}
+}
+
+func TestBetweennessCountsShortestPathsThroughNode(t *testing.T) {
+	call := func(name string, calls ...string) parser.FileSymbols {
+		symbol := parser.Symbol{Name: name, Kind: parser.SymbolFunction, Line: 1}
+		for _, callee := range calls {
+			symbol.Calls = append(symbol.Calls, parser.CallSite{Name: callee})
+		}
+		return parser.FileSymbols{Path: name + ".go", Symbols: []parser.Symbol{symbol}}
+	}
+	// A -> B -> C -> D: B lies on A->C and A->D, C on A->D and B->D, out of
+	// the 3*2 ordered pairs of other nodes each.
+	g := BuildFromParseResult(&parser.ParseResult{Files: []parser.FileSymbols{
+		call("A", "B"), call("B", "C"), call("C", "D"), call("D"),
+	}})
+	want := map[string]float64{"A": 0, "B": 2.0 / 6, "C": 2.0 / 6, "D": 0}
+	for name, expected := range want {
+		node := findNodeByName(t, g, name+".go", name)
+		if math.Abs(node.Betweenness-expected) > 1e-9 {
+			t.Fatalf("expected betweenness of %s to be %f, got %f", name, expected, node.Betweenness)
+		}
+	}
+	if value := findNodeByName(t, g, "B.go", "B").MetricValue(MetricInDegree); value != 1 {
+		t.Fatalf("expected B to have in-degree 1, got %f", value)
+	}
+
+	if metric, err := ParseMetric(" Rank "); err != nil || metric != MetricPageRank {
+		t.Fatalf("expected rank to parse as pagerank, got %q %v", metric, err)
+	}
+	if _, err := ParseMetric("closeness"); err == nil {
+		t.Fatalf("expected an unknown metric to fail")
+	}
+}
+
 func findNodeByName(t *testing.T, g *Graph, file, name string) *Node {
 	t.Helper()
 	for _, node := range g.NodesForFile(file) {
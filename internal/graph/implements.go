@@ -0,0 +1,119 @@
+package graph
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/morozRed/skelly/internal/parser"
+)
+
+// resolveImplementations links Go types to the interfaces of the repository
+// whose method sets they satisfy. Methods are matched by name, against the
+// methods declared on the type in its own package plus those promoted from
+// types it embeds from that package; interfaces likewise include the
+// methods of same-package interfaces they embed. Interfaces without any
+// methods are not considered.
+func (g *Graph) resolveImplementations(result *parser.ParseResult) {
+	methodSets := make(map[goTypeKey]map[string]bool)
+	embeds := make(map[goTypeKey][]string)
+	typeIDs := make(map[goTypeKey][]string)
+	interfaceKeys := make(map[string]goTypeKey)
+	for _, file := range result.Files {
+		if file.Language != "go" {
+			continue
+		}
+		dir := filepath.Dir(file.Path)
+		for _, sym := range file.Symbols {
+			id := makeNodeID(file.Path, sym)
+			switch sym.Kind {
+			case parser.SymbolMethod:
+				key := goTypeKey{dir, sym.Container}
+				if methodSets[key] == nil {
+					methodSets[key] = make(map[string]bool)
+				}
+				methodSets[key][sym.Name] = true
+			case parser.SymbolStruct, parser.SymbolInterface:
+				key := goTypeKey{dir, sym.Name}
+				for _, base := range sym.Bases {
+					if !strings.Contains(base.Name, ".") {
+						embeds[key] = append(embeds[key], base.Name)
+					}
+				}
+				if sym.Kind == parser.SymbolStruct {
+					typeIDs[key] = append(typeIDs[key], id)
+					continue
+				}
+				interfaceKeys[id] = key
+				if methodSets[key] == nil {
+					methodSets[key] = make(map[string]bool)
+				}
+				for _, method := range sym.Methods {
+					methodSets[key][method] = true
+				}
+			}
+		}
+	}
+
+	promoted := make(map[goTypeKey]map[string]bool, len(methodSets))
+	for key := range methodSets {
+		promoted[key] = promotedMethods(key, methodSets, embeds, make(map[goTypeKey]bool))
+	}
+	for key := range typeIDs {
+		if _, ok := promoted[key]; !ok {
+			promoted[key] = promotedMethods(key, methodSets, embeds, make(map[goTypeKey]bool))
+		}
+	}
+
+	interfaceIDs := make([]string, 0, len(interfaceKeys))
+	for id := range interfaceKeys {
+		interfaceIDs = append(interfaceIDs, id)
+	}
+	sort.Strings(interfaceIDs)
+	for _, ifaceID := range interfaceIDs {
+		required := promoted[interfaceKeys[ifaceID]]
+		if len(required) == 0 {
+			continue
+		}
+		for key, ids := range typeIDs {
+			if !hasAllMethods(promoted[key], required) {
+				continue
+			}
+			for _, typeID := range ids {
+				// Matched by method names only.
+				g.addEdge(g.Nodes[typeID], ifaceID, EdgeImplement, "heuristic")
+			}
+		}
+	}
+}
+
+// goTypeKey names a Go type by package directory and type name.
+type goTypeKey struct{ dir, name string }
+
+// promotedMethods returns the methods of key together with those of the
+// same-package types it embeds, recursively.
+func promotedMethods(key goTypeKey, methodSets map[goTypeKey]map[string]bool, embeds map[goTypeKey][]string, visiting map[goTypeKey]bool) map[string]bool {
+	methods := make(map[string]bool, len(methodSets[key]))
+	if visiting[key] {
+		return methods
+	}
+	visiting[key] = true
+	for method := range methodSets[key] {
+		methods[method] = true
+	}
+	for _, embedded := range embeds[key] {
+		for method := range promotedMethods(goTypeKey{key.dir, embedded}, methodSets, embeds, visiting) {
+			methods[method] = true
+		}
+	}
+	return methods
+}
+
+func hasAllMethods(methods map[string]bool, required map[string]bool) bool {
+	for name := range required {
+		if !methods[name] {
+			return false
+		}
+	}
+	return true
+}
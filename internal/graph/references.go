@@ -0,0 +1,33 @@
+package graph
+
+import "github.com/morozRed/skelly/internal/parser"
+
+// resolveReferences links symbols to the types they mention without calling
+// them (parser.Symbol.References) with EdgeReference edges. A reference is a
+// name match in a type position, so its edges are always heuristic, and a
+// pair already linked by a call or supertype edge keeps that edge.
+func (g *Graph) resolveReferences(result *parser.ParseResult, lookups symbolLookups, sourceFiles map[string]bool) {
+	for _, file := range result.Files {
+		if sourceFiles != nil && !sourceFiles[file.Path] {
+			continue
+		}
+		for _, sym := range file.Symbols {
+			if len(sym.References) == 0 {
+				continue
+			}
+			node := g.Nodes[makeNodeID(file.Path, sym)]
+			for _, ref := range sym.References {
+				targetIDs, _, ok := lookups.resolveType(file.Path, ref)
+				if !ok {
+					continue
+				}
+				for _, targetID := range targetIDs {
+					if _, linked := node.OutEdgeConfidence[targetID]; linked {
+						continue
+					}
+					g.addEdge(node, targetID, EdgeReference, "heuristic")
+				}
+			}
+		}
+	}
+}
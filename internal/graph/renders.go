@@ -0,0 +1,38 @@
+package graph
+
+import (
+	"strings"
+
+	"github.com/morozRed/skelly/internal/parser"
+)
+
+// resolveRenders links symbols to the components they render as JSX
+// elements (parser.Symbol.Renders) with EdgeRender edges. Element names are
+// resolved like calls, so <Button/> follows the Button import and <UI.Card/>
+// the UI namespace import.
+func (g *Graph) resolveRenders(result *parser.ParseResult, lookups symbolLookups, sourceFiles map[string]bool) {
+	for _, file := range result.Files {
+		if sourceFiles != nil && !sourceFiles[file.Path] {
+			continue
+		}
+		for _, sym := range file.Symbols {
+			if len(sym.Renders) == 0 {
+				continue
+			}
+			node := g.Nodes[makeNodeID(file.Path, sym)]
+			for _, element := range sym.Renders {
+				qualifier, name := "", element
+				if idx := strings.LastIndex(element, "."); idx != -1 {
+					qualifier, name = element[:idx], element[idx+1:]
+				}
+				targetIDs, confidence, ok := lookups.resolve(file.Path, sym, parser.CallSite{Name: name, Qualifier: qualifier})
+				if !ok {
+					continue
+				}
+				for _, targetID := range targetIDs {
+					g.addEdge(node, targetID, EdgeRender, confidence)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package graph
+
+import (
+	"strings"
+
+	"github.com/morozRed/skelly/internal/parser"
+)
+
+// ResolutionSampleLimit caps the unresolved call sites kept per language.
+const ResolutionSampleLimit = 20
+
+// ResolutionStats counts how call sites of one language were resolved.
+// Resolved and Heuristic calls produced an edge; Ambiguous calls matched
+// several candidates and Unresolved calls matched none.
+type ResolutionStats struct {
+	Resolved   int
+	Heuristic  int
+	Ambiguous  int
+	Unresolved int
+	Samples    []UnresolvedCall // first ResolutionSampleLimit misses, in file order
+}
+
+// UnresolvedCall is a call site that did not produce an edge.
+type UnresolvedCall struct {
+	File      string `json:"file"`
+	Line      int    `json:"line,omitempty"`
+	Caller    string `json:"caller"`
+	Call      string `json:"call"`
+	Ambiguous bool   `json:"ambiguous,omitempty"`
+}
+
+// Total is the number of call sites counted.
+func (s *ResolutionStats) Total() int {
+	return s.Resolved + s.Heuristic + s.Ambiguous + s.Unresolved
+}
+
+func (g *Graph) recordResolution(file parser.FileSymbols, caller parser.Symbol, call parser.CallSite, confidence string, ok bool) {
+	if strings.TrimSpace(call.Name) == "" {
+		return
+	}
+	stats, exists := g.Resolution[file.Language]
+	if !exists {
+		stats = &ResolutionStats{}
+		g.Resolution[file.Language] = stats
+	}
+
+	switch {
+	case ok && confidence == "resolved":
+		stats.Resolved++
+		return
+	case ok:
+		stats.Heuristic++
+		return
+	case confidence == "ambiguous":
+		stats.Ambiguous++
+	default:
+		stats.Unresolved++
+	}
+
+	if len(stats.Samples) >= ResolutionSampleLimit {
+		return
+	}
+	name := call.Name
+	if call.Qualifier != "" {
+		name = call.Qualifier + "." + call.Name
+	}
+	line := call.Line
+	if line == 0 {
+		line = caller.Line
+	}
+	stats.Samples = append(stats.Samples, UnresolvedCall{
+		File:      file.Path,
+		Line:      line,
+		Caller:    caller.Name,
+		Call:      name,
+		Ambiguous: confidence == "ambiguous",
+	})
+}
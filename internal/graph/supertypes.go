@@ -0,0 +1,106 @@
+package graph
+
+import (
+	"strings"
+
+	"github.com/morozRed/skelly/internal/parser"
+)
+
+// resolveSupertypes links classes, interfaces and modules to the supertypes
+// their declarations name (parser.Symbol.Bases): inheritance and mixins become
+// EdgeInherit edges, implemented interfaces EdgeImplement edges.
+func (g *Graph) resolveSupertypes(result *parser.ParseResult, lookups symbolLookups) {
+	for _, file := range result.Files {
+		for _, sym := range file.Symbols {
+			if len(sym.Bases) == 0 {
+				continue
+			}
+			node := g.Nodes[makeNodeID(file.Path, sym)]
+			for _, base := range sym.Bases {
+				targetIDs, confidence, ok := lookups.resolveType(file.Path, base.Name)
+				if !ok {
+					continue
+				}
+				kind := EdgeInherit
+				if base.Relation == parser.RelationImplement {
+					kind = EdgeImplement
+				}
+				for _, targetID := range targetIDs {
+					g.addEdge(node, targetID, kind, confidence)
+				}
+			}
+		}
+	}
+}
+
+// resolveType resolves a supertype reference as written in a declaration
+// ("Base", "models.Model", "Admin::Base") to type symbols: one declared in
+// the same file or under that qualified name, then one reached through an
+// import. Unqualified names fall back to a repository-wide unique match;
+// qualified names that match nothing are taken to be external.
+func (l symbolLookups) resolveType(sourceFile, ref string) ([]string, string, bool) {
+	ref = strings.TrimSpace(ref)
+	name := parser.InnermostName(ref)
+	qualifier := strings.TrimRight(strings.TrimSuffix(ref, name), ".:\\/")
+	candidates := l.sameSchemaKind(sourceFile, l.types[name])
+	if name == "" || len(candidates) == 0 {
+		return nil, "", false
+	}
+	isType := make(map[string]bool, len(candidates))
+	for _, id := range candidates {
+		isType[id] = true
+	}
+	typesOnly := func(ids []string) []string {
+		out := make([]string, 0, len(ids))
+		for _, id := range ids {
+			if isType[id] {
+				out = append(out, id)
+			}
+		}
+		return out
+	}
+
+	if qualifier == "" {
+		local := make([]string, 0)
+		for _, id := range candidates {
+			if file, _ := ParseNodeID(id); file == sourceFile {
+				local = append(local, id)
+			}
+		}
+		if len(local) > 0 {
+			return chooseUnique(local, "resolved")
+		}
+	} else {
+		dotted := strings.NewReplacer("::", ".", "\\", ".", "/", ".").Replace(ref)
+		if ids := typesOnly(l.qualified[dotted]); len(ids) > 0 {
+			return chooseUnique(ids, "resolved")
+		}
+	}
+
+	alias := primaryQualifier(qualifier)
+	if alias == "" {
+		alias = name
+	}
+	if ids := typesOnly(l.resolveImportAlias(sourceFile, alias, name)); len(ids) > 0 {
+		return chooseUnique(ids, "heuristic")
+	}
+
+	if qualifier == "" {
+		return chooseUnique(candidates, "heuristic")
+	}
+	return nil, "", false
+}
+
+// sameSchemaKind keeps the type IDs declared in schema files when sourceFile
+// is one, and the others otherwise, so a Go Invoice never resolves to the
+// .proto message it was generated from.
+func (l symbolLookups) sameSchemaKind(sourceFile string, ids []string) []string {
+	schema := schemaLanguages[l.languages[sourceFile]]
+	out := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if file, _ := ParseNodeID(id); schemaLanguages[l.languages[file]] == schema {
+			out = append(out, id)
+		}
+	}
+	return out
+}
@@ -0,0 +1,308 @@
+// Package grep matches tree-sitter patterns in source files: calls to a
+// function with a minimum number of arguments, functions with a minimum
+// number of parameters, or any tree-sitter query.
+package grep
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/morozRed/skelly/internal/languages"
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// maxTextLength caps the source excerpt reported for a match.
+const maxTextLength = 200
+
+// Pattern is a structural search. Exactly one of Call, MinParams and Query
+// is set.
+type Pattern struct {
+	// Call matches calls whose callee is this name, either the whole callee
+	// expression (strings.Split, s.store.Save) or its last segment (Save).
+	Call string `json:"call,omitempty"`
+	// MinArgs is the minimum number of arguments of a Call match.
+	MinArgs int `json:"min_args,omitempty"`
+	// MinParams matches function and method definitions with at least this
+	// many parameters, not counting receivers, self and cls.
+	MinParams int `json:"min_params,omitempty"`
+	// Query is a tree-sitter query. The node captured as @match is reported,
+	// otherwise the first capture of each match.
+	Query string `json:"query,omitempty"`
+}
+
+// Match is one matched node.
+type Match struct {
+	File     string `json:"file"`
+	Language string `json:"language"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+	EndLine  int    `json:"end_line"`
+	// Text is the first line of the matched node.
+	Text string `json:"text"`
+	// Count is the argument count of a call or parameter count of a function.
+	Count    int               `json:"count,omitempty"`
+	Captures map[string]string `json:"captures,omitempty"`
+	// Enclosing lists the start lines of the matched node and its ancestors,
+	// innermost first, for anchoring the match to a symbol.
+	Enclosing []int `json:"-"`
+}
+
+// Searcher runs a pattern over files, compiling its query once per grammar.
+type Searcher struct {
+	pattern Pattern
+	parser  *sitter.Parser
+	queries map[string]*sitter.Query
+}
+
+// NewSearcher validates a pattern.
+func NewSearcher(pattern Pattern) (*Searcher, error) {
+	pattern.Call = strings.TrimSpace(pattern.Call)
+	pattern.Query = strings.TrimSpace(pattern.Query)
+	set := 0
+	if pattern.Call != "" {
+		set++
+	}
+	if pattern.MinParams > 0 {
+		set++
+	}
+	if pattern.Query != "" {
+		set++
+	}
+	switch {
+	case set == 0:
+		return nil, fmt.Errorf("grep requires --call <name>, --min-params <n> or --query <pattern>")
+	case set > 1:
+		return nil, fmt.Errorf("use only one of --call, --min-params and --query")
+	case pattern.MinArgs > 0 && pattern.Call == "":
+		return nil, fmt.Errorf("--min-args requires --call")
+	}
+	return &Searcher{pattern: pattern, parser: sitter.NewParser(), queries: make(map[string]*sitter.Query)}, nil
+}
+
+// Close releases the compiled queries and parser.
+func (s *Searcher) Close() {
+	for _, query := range s.queries {
+		if query != nil {
+			query.Close()
+		}
+	}
+	s.parser.Close()
+}
+
+// File returns the matches in one file, in source order. Files in languages
+// the pattern has no built-in query for yield no matches.
+func (s *Searcher) File(path, language string, content []byte) ([]Match, error) {
+	grammar, name := languages.Grammar(language, path)
+	if grammar == nil {
+		return nil, nil
+	}
+	query, err := s.query(grammar, name)
+	if err != nil || query == nil {
+		return nil, err
+	}
+
+	s.parser.SetLanguage(grammar)
+	tree, err := s.parser.ParseCtx(context.Background(), nil, content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	defer tree.Close()
+
+	cursor := sitter.NewQueryCursor()
+	defer cursor.Close()
+	cursor.Exec(query, tree.RootNode())
+
+	matches := make([]Match, 0)
+	for {
+		found, ok := cursor.NextMatch()
+		if !ok {
+			break
+		}
+		found = cursor.FilterPredicates(found, content)
+		captures := make(map[string]*sitter.Node, len(found.Captures))
+		var first *sitter.Node
+		for _, capture := range found.Captures {
+			captureName := query.CaptureNameForId(capture.Index)
+			if _, seen := captures[captureName]; !seen {
+				captures[captureName] = capture.Node
+			}
+			if first == nil {
+				first = capture.Node
+			}
+		}
+		if first == nil {
+			continue
+		}
+
+		match, ok := s.match(name, captures, first, content)
+		if !ok {
+			continue
+		}
+		match.File = path
+		match.Language = language
+		matches = append(matches, match)
+	}
+	return matches, nil
+}
+
+// query compiles the pattern's query for a grammar; nil means the pattern
+// has no built-in query there.
+func (s *Searcher) query(grammar *sitter.Language, name string) (*sitter.Query, error) {
+	if query, ok := s.queries[name]; ok {
+		return query, nil
+	}
+	source := s.pattern.Query
+	if source == "" {
+		spec, ok := grammarSpecs[name]
+		if s.pattern.Call != "" {
+			source = spec.calls
+		} else {
+			source = spec.functions
+		}
+		if !ok || source == "" {
+			s.queries[name] = nil
+			return nil, nil
+		}
+	}
+	query, err := sitter.NewQuery([]byte(source), grammar)
+	if err != nil {
+		if s.pattern.Query != "" {
+			return nil, fmt.Errorf("invalid query for %s: %w", name, err)
+		}
+		return nil, fmt.Errorf("built-in %s query failed to compile: %w", name, err)
+	}
+	s.queries[name] = query
+	return query, nil
+}
+
+func (s *Searcher) match(grammar string, captures map[string]*sitter.Node, first *sitter.Node, content []byte) (Match, bool) {
+	switch {
+	case s.pattern.Call != "":
+		call := captures["call"]
+		if call == nil {
+			return Match{}, false
+		}
+		args := captures["args"]
+		if !calleeMatches(callee(call, args, content), s.pattern.Call) {
+			return Match{}, false
+		}
+		count := countArguments(args)
+		if count < s.pattern.MinArgs {
+			return Match{}, false
+		}
+		match := newMatch(call, content)
+		match.Count = count
+		return match, true
+	case s.pattern.MinParams > 0:
+		function, params := captures["function"], captures["params"]
+		if function == nil || params == nil {
+			return Match{}, false
+		}
+		count := countParameters(grammar, params, content)
+		if count < s.pattern.MinParams {
+			return Match{}, false
+		}
+		match := newMatch(function, content)
+		match.Count = count
+		return match, true
+	default:
+		node := captures["match"]
+		if node == nil {
+			node = first
+		}
+		match := newMatch(node, content)
+		match.Captures = make(map[string]string, len(captures))
+		for name, captured := range captures {
+			match.Captures[name] = firstLine(captured.Content(content))
+		}
+		return match, true
+	}
+}
+
+func newMatch(node *sitter.Node, content []byte) Match {
+	match := Match{
+		Line:    int(node.StartPoint().Row) + 1,
+		Column:  int(node.StartPoint().Column) + 1,
+		EndLine: int(node.EndPoint().Row) + 1,
+		Text:    firstLine(node.Content(content)),
+	}
+	for current := node; current != nil; current = current.Parent() {
+		line := int(current.StartPoint().Row) + 1
+		if len(match.Enclosing) == 0 || match.Enclosing[len(match.Enclosing)-1] != line {
+			match.Enclosing = append(match.Enclosing, line)
+		}
+	}
+	return match
+}
+
+func firstLine(text string) string {
+	if idx := strings.IndexByte(text, '\n'); idx != -1 {
+		text = text[:idx]
+	}
+	text = strings.TrimSpace(text)
+	if len(text) > maxTextLength {
+		text = text[:maxTextLength] + "..."
+	}
+	return text
+}
+
+// callee returns the call's source before its argument list, without
+// whitespace: "s.store.Save", "Bar", "Foo::bar".
+func callee(call, args *sitter.Node, content []byte) string {
+	end := call.EndByte()
+	if args != nil {
+		end = args.StartByte()
+	}
+	text := string(content[call.StartByte():end])
+	return strings.TrimSuffix(strings.Join(strings.Fields(text), ""), "(")
+}
+
+func calleeMatches(callee, name string) bool {
+	if callee == name {
+		return true
+	}
+	last := callee
+	for _, separator := range []string{".", "->", "::"} {
+		if idx := strings.LastIndex(last, separator); idx != -1 {
+			last = last[idx+len(separator):]
+		}
+	}
+	return last == name
+}
+
+func countArguments(args *sitter.Node) int {
+	if args == nil {
+		return 0
+	}
+	count := 0
+	for i := 0; i < int(args.NamedChildCount()); i++ {
+		if !isComment(args.NamedChild(i)) {
+			count++
+		}
+	}
+	return count
+}
+
+func countParameters(grammar string, params *sitter.Node, content []byte) int {
+	count := 0
+	for i := 0; i < int(params.NamedChildCount()); i++ {
+		param := params.NamedChild(i)
+		switch {
+		case isComment(param):
+		case param.Type() == "self_parameter", param.Type() == "keyword_separator", param.Type() == "positional_separator":
+		case grammar == "python" && i == 0 && (param.Content(content) == "self" || param.Content(content) == "cls"):
+		case (grammar == "c" || grammar == "cpp") && params.NamedChildCount() == 1 && param.Content(content) == "void":
+		case grammar == "go" && param.Type() == "parameter_declaration":
+			// a, b int declares two parameters: every named child but the type.
+			count += max(1, int(param.NamedChildCount())-1)
+		default:
+			count++
+		}
+	}
+	return count
+}
+
+func isComment(node *sitter.Node) bool {
+	return strings.Contains(node.Type(), "comment")
+}
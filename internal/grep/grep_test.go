@@ -0,0 +1,112 @@
+package grep
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/morozRed/skelly/internal/languages"
+)
+
+func TestBuiltInQueriesCompileForEveryGrammar(t *testing.T) {
+	for name := range grammarSpecs {
+		language := name
+		path := "file"
+		if name == "tsx" {
+			language, path = "typescript", "file.tsx"
+		}
+		grammar, _ := languages.Grammar(language, path)
+		for _, pattern := range []Pattern{{Call: "f"}, {MinParams: 1}} {
+			searcher, err := NewSearcher(pattern)
+			if err != nil {
+				t.Fatalf("NewSearcher failed: %v", err)
+			}
+			if _, err := searcher.query(grammar, name); err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			searcher.Close()
+		}
+	}
+}
+
+func TestCallsAndParametersAcrossLanguages(t *testing.T) {
+	cases := []struct {
+		language, path, call, source string
+		calls, functions             []int
+	}{
+		{"go", "a.go", "Save", "package a\n\nfunc (s *S) F(a, b int, c ...string) {\n\ts.store.Save(1, 2, 3)\n\tSave(1)\n}\n", []int{3}, []int{3}},
+		{"python", "a.py", "save", "class A:\n    def f(self, a, b, *rest):\n        self.save(1, 2, key=3)\n", []int{3}, []int{3}},
+		{"typescript", "a.ts", "save", "function f(a: number, b?: string, c = 1) {\n  repo.save(a, b, c)\n}\n", []int{3}, []int{3}},
+		{"ruby", "a.rb", "save", "def f(a, b = 1, *c)\n  repo.save 1, 2, 3\n  save\nend\n", []int{3}, []int{3}},
+		{"rust", "a.rs", "save", "impl S {\n    fn f(&self, a: i32, b: i32, c: i32) {\n        save(1, 2, 3);\n    }\n}\n", []int{3}, []int{3}},
+		{"java", "A.java", "save", "class A {\n  void f(int a, int b, int c) {\n    repo.save(1, 2, 3);\n  }\n}\n", []int{3}, []int{3}},
+		{"c", "a.c", "save", "int f(int a, int b, int c) {\n  save(1, 2, 3);\n}\nint g(void) { return 0; }\n", []int{3}, []int{3}},
+		{"php", "a.php", "save", "<?php\nfunction f($a, $b, $c) {\n  $repo->save(1, 2, 3);\n}\n", []int{3}, []int{3}},
+		{"csharp", "A.cs", "Save", "class A {\n  void F(int a, int b, int c) {\n    repo.Save(1, 2, 3);\n  }\n}\n", []int{3}, []int{3}},
+	}
+	for _, tc := range cases {
+		calls, err := NewSearcher(Pattern{Call: tc.call, MinArgs: 3})
+		if err != nil {
+			t.Fatalf("NewSearcher failed: %v", err)
+		}
+		functions, err := NewSearcher(Pattern{MinParams: 3})
+		if err != nil {
+			t.Fatalf("NewSearcher failed: %v", err)
+		}
+
+		callMatches, err := calls.File(tc.path, tc.language, []byte(tc.source))
+		if err != nil {
+			t.Fatalf("%s calls: %v", tc.language, err)
+		}
+		if got := counts(callMatches); !reflect.DeepEqual(got, tc.calls) {
+			t.Fatalf("%s: expected calls with argument counts %v, got %#v", tc.language, tc.calls, callMatches)
+		}
+		functionMatches, err := functions.File(tc.path, tc.language, []byte(tc.source))
+		if err != nil {
+			t.Fatalf("%s functions: %v", tc.language, err)
+		}
+		if got := counts(functionMatches); !reflect.DeepEqual(got, tc.functions) {
+			t.Fatalf("%s: expected functions with parameter counts %v, got %#v", tc.language, tc.functions, functionMatches)
+		}
+		calls.Close()
+		functions.Close()
+	}
+}
+
+func TestQueryReportsMatchCaptureAndEnclosingLines(t *testing.T) {
+	searcher, err := NewSearcher(Pattern{Query: `((call_expression function: (identifier) @fn) @match (#eq? @fn "panic"))`})
+	if err != nil {
+		t.Fatalf("NewSearcher failed: %v", err)
+	}
+	defer searcher.Close()
+	source := "package a\n\nfunc Load() {\n\tif bad {\n\t\tpanic(\"bad\")\n\t}\n\tprintln(1)\n}\n"
+	matches, err := searcher.File("a.go", "go", []byte(source))
+	if err != nil {
+		t.Fatalf("File failed: %v", err)
+	}
+	if len(matches) != 1 || matches[0].Line != 5 || matches[0].Text != `panic("bad")` || matches[0].Captures["fn"] != "panic" {
+		t.Fatalf("expected the panic call, got %#v", matches)
+	}
+	if !reflect.DeepEqual(matches[0].Enclosing, []int{5, 4, 3, 1}) {
+		t.Fatalf("expected enclosing lines innermost first, got %v", matches[0].Enclosing)
+	}
+
+	if _, err := searcher.File("a.py", "python", []byte("x = 1\n")); err == nil {
+		t.Fatalf("expected a query with Go node types to be invalid for Python")
+	}
+}
+
+func TestNewSearcherRejectsAmbiguousPatterns(t *testing.T) {
+	for _, pattern := range []Pattern{{}, {Call: "f", MinParams: 2}, {MinArgs: 2}} {
+		if _, err := NewSearcher(pattern); err == nil {
+			t.Fatalf("expected %+v to be rejected", pattern)
+		}
+	}
+}
+
+func counts(matches []Match) []int {
+	values := make([]int, 0, len(matches))
+	for _, match := range matches {
+		values = append(values, match.Count)
+	}
+	return values
+}
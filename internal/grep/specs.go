@@ -0,0 +1,76 @@
+package grep
+
+// grammarSpec holds the built-in queries of one grammar. calls captures
+// @call and its argument list as @args; functions captures @function and its
+// parameter list as @params.
+type grammarSpec struct {
+	calls     string
+	functions string
+}
+
+var (
+	typeScriptSpec = grammarSpec{
+		calls: `(call_expression arguments: (arguments) @args) @call`,
+		functions: `
+(function_declaration parameters: (formal_parameters) @params) @function
+(method_definition parameters: (formal_parameters) @params) @function
+(arrow_function parameters: (formal_parameters) @params) @function`,
+	}
+	cSpec = grammarSpec{
+		calls: `(call_expression arguments: (argument_list) @args) @call`,
+		functions: `
+(function_definition declarator: (function_declarator parameters: (parameter_list) @params)) @function
+(function_definition declarator: (pointer_declarator declarator: (function_declarator parameters: (parameter_list) @params))) @function`,
+	}
+)
+
+// grammarSpecs maps grammar names, as languages.Grammar returns them, to
+// their built-in queries. Proto has neither calls nor functions.
+var grammarSpecs = map[string]grammarSpec{
+	"go": {
+		calls: `(call_expression arguments: (argument_list) @args) @call`,
+		functions: `
+(function_declaration parameters: (parameter_list) @params) @function
+(method_declaration parameters: (parameter_list) @params) @function`,
+	},
+	"python": {
+		calls:     `(call arguments: (argument_list) @args) @call`,
+		functions: `(function_definition parameters: (parameters) @params) @function`,
+	},
+	"ruby": {
+		calls: `(call method: (_) arguments: (argument_list)? @args) @call`,
+		functions: `
+(method parameters: (method_parameters) @params) @function
+(singleton_method parameters: (method_parameters) @params) @function`,
+	},
+	"typescript": typeScriptSpec,
+	"tsx":        typeScriptSpec,
+	"javascript": typeScriptSpec,
+	"rust": {
+		calls:     `(call_expression arguments: (arguments) @args) @call`,
+		functions: `(function_item parameters: (parameters) @params) @function`,
+	},
+	"java": {
+		calls: `(method_invocation arguments: (argument_list) @args) @call`,
+		functions: `
+(method_declaration parameters: (formal_parameters) @params) @function
+(constructor_declaration parameters: (formal_parameters) @params) @function`,
+	},
+	"c":   cSpec,
+	"cpp": cSpec,
+	"php": {
+		calls: `
+(function_call_expression arguments: (arguments) @args) @call
+(member_call_expression arguments: (arguments) @args) @call
+(scoped_call_expression arguments: (arguments) @args) @call`,
+		functions: `
+(function_definition parameters: (formal_parameters) @params) @function
+(method_declaration parameters: (formal_parameters) @params) @function`,
+	},
+	"csharp": {
+		calls: `(invocation_expression arguments: (argument_list) @args) @call`,
+		functions: `
+(method_declaration parameters: (parameter_list) @params) @function
+(constructor_declaration parameters: (parameter_list) @params) @function`,
+	},
+}
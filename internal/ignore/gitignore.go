@@ -0,0 +1,141 @@
+package ignore
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// GitignoreFile is the per-directory ignore file honored by GitignoreRules.
+const GitignoreFile = ".gitignore"
+
+// GitignoreRules collects .git/info/exclude and every .gitignore under root
+// and rewrites their lines as matcher rules relative to root, parents before
+// children so deeper files take precedence. Directories excluded by the
+// default rules, by rules collected so far, or by userRules are not
+// descended into. Rules returned here belong before userRules, so
+// .skellyignore can re-include paths with "!".
+func GitignoreRules(root string, userRules []string) ([]string, error) {
+	rules := make([]string, 0)
+	// In linked worktrees .git is a file and there is no info/exclude.
+	if info, err := os.Stat(filepath.Join(root, ".git")); err == nil && info.IsDir() {
+		excludeRules, err := readGitignore(filepath.Join(root, ".git", "info", "exclude"), "")
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, excludeRules...)
+	}
+
+	matcher := newGitignoreMatcher(rules, userRules)
+	err := filepath.WalkDir(root, func(current string, entry fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			if entry != nil && entry.IsDir() && current != root {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !entry.IsDir() {
+			return nil
+		}
+		relPath, err := filepath.Rel(root, current)
+		if err != nil {
+			return err
+		}
+		relPath = filepath.ToSlash(relPath)
+		if relPath == "." {
+			relPath = ""
+		} else if matcher.ShouldIgnore(relPath, true) {
+			return filepath.SkipDir
+		}
+
+		dirRules, err := readGitignore(filepath.Join(current, GitignoreFile), relPath)
+		if err != nil {
+			return err
+		}
+		if len(dirRules) > 0 {
+			rules = append(rules, dirRules...)
+			matcher = newGitignoreMatcher(rules, userRules)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+func newGitignoreMatcher(gitignoreRules, userRules []string) *Matcher {
+	all := make([]string, 0, len(gitignoreRules)+len(userRules))
+	all = append(all, gitignoreRules...)
+	all = append(all, userRules...)
+	return NewMatcher(all)
+}
+
+func readGitignore(filePath, dir string) ([]string, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", filePath, err)
+	}
+	defer f.Close()
+
+	rules := make([]string, 0)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if rule, ok := GitignoreRule(dir, scanner.Text()); ok {
+			rules = append(rules, rule)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filePath, err)
+	}
+	return rules, nil
+}
+
+// GitignoreRule rewrites one line of the .gitignore in dir ("" for the
+// repository root) as a rule relative to the root. Patterns containing a
+// slash are anchored to dir; other patterns match at any depth below it.
+func GitignoreRule(dir, line string) (string, bool) {
+	line = strings.TrimRight(line, " \r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return "", false
+	}
+
+	prefix := ""
+	if strings.HasPrefix(line, "!") {
+		prefix = "!"
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\!`) || strings.HasPrefix(line, `\#`) {
+		line = line[1:]
+	}
+	suffix := ""
+	if strings.HasSuffix(line, "/") {
+		suffix = "/"
+		line = strings.TrimSuffix(line, "/")
+	}
+	anchored := strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+	if line == "" {
+		return "", false
+	}
+
+	var pattern string
+	switch {
+	case anchored:
+		pattern = "/" + path.Join(dir, line)
+	case dir != "":
+		pattern = "/" + dir + "/**/" + line
+	case strings.HasPrefix(line, "#"):
+		// Keep escaped "#" patterns from reading as comments.
+		pattern = "**/" + line
+	default:
+		pattern = line
+	}
+	return prefix + pattern + suffix, true
+}
@@ -1,9 +1,11 @@
 package ignore
 
 import (
+	"path"
 	"path/filepath"
 	"regexp"
 	"strings"
+	"sync"
 )
 
 type rule struct {
@@ -100,17 +102,15 @@ func ruleMatches(rule rule, relPath string, isDir bool) bool {
 		return false
 	}
 
+	// A pattern that matches a directory also covers everything below it.
 	if rule.anchored {
-		return matchPathPattern(rule.pattern, relPath)
+		return matchPathOrAncestor(rule.pattern, relPath)
 	}
 
 	if strings.Contains(rule.pattern, "/") {
-		if matchPathPattern(rule.pattern, relPath) {
-			return true
-		}
 		parts := strings.Split(relPath, "/")
-		for i := 1; i < len(parts); i++ {
-			if matchPathPattern(rule.pattern, strings.Join(parts[i:], "/")) {
+		for i := 0; i < len(parts); i++ {
+			if matchPathOrAncestor(rule.pattern, strings.Join(parts[i:], "/")) {
 				return true
 			}
 		}
@@ -132,7 +132,7 @@ func ruleMatches(rule rule, relPath string, isDir bool) bool {
 
 func matchDirectoryPattern(rule rule, relPath string) bool {
 	if rule.anchored {
-		return relPath == rule.pattern || strings.HasPrefix(relPath, rule.pattern+"/")
+		return matchPathOrAncestor(rule.pattern, relPath)
 	}
 
 	if relPath == rule.pattern || strings.HasPrefix(relPath, rule.pattern+"/") {
@@ -142,17 +142,48 @@ func matchDirectoryPattern(rule rule, relPath string) bool {
 	parts := strings.Split(relPath, "/")
 	for i := range parts {
 		candidate := strings.Join(parts[:i+1], "/")
-		if candidate == rule.pattern {
+		if matchPathPattern(rule.pattern, candidate) {
+			return true
+		}
+	}
+	// Without a slash the pattern names a directory at any depth, so a file
+	// checked on its own (not reached by a walk) is ignored when any parent
+	// directory matches.
+	if !strings.Contains(rule.pattern, "/") {
+		for _, segment := range parts[:len(parts)-1] {
+			if matchPathPattern(rule.pattern, segment) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// matchPathOrAncestor reports whether pattern matches relPath or one of its
+// parent directories.
+func matchPathOrAncestor(pattern, relPath string) bool {
+	for candidate := relPath; candidate != "" && candidate != "."; candidate = path.Dir(candidate) {
+		if matchPathPattern(pattern, candidate) {
 			return true
 		}
 	}
 	return false
 }
 
+// patternCache holds compiled glob patterns; rules are matched against every
+// scanned path, so compiling once per pattern keeps walks cheap.
+var patternCache sync.Map
+
 func matchPathPattern(pattern, value string) bool {
-	re := globToRegex(pattern)
-	ok, err := regexp.MatchString("^"+re+"$", value)
-	return err == nil && ok
+	compiled, ok := patternCache.Load(pattern)
+	if !ok {
+		re, err := regexp.Compile("^" + globToRegex(pattern) + "$")
+		if err != nil {
+			return false
+		}
+		compiled, _ = patternCache.LoadOrStore(pattern, re)
+	}
+	return compiled.(*regexp.Regexp).MatchString(value)
 }
 
 func globToRegex(pattern string) string {
@@ -162,6 +193,12 @@ func globToRegex(pattern string) string {
 
 		if ch == '*' {
 			if i+1 < len(pattern) && pattern[i+1] == '*' {
+				// "**/" also matches zero directories, as in .gitignore.
+				if i+2 < len(pattern) && pattern[i+2] == '/' {
+					b.WriteString("(.*/)?")
+					i += 2
+					continue
+				}
 				b.WriteString(".*")
 				i++
 				continue
@@ -189,3 +226,24 @@ func normalizePath(path string) string {
 	path = strings.TrimPrefix(path, "/")
 	return path
 }
+
+// Pattern is a single gitignore-style path pattern, for files such as
+// CODEOWNERS that reuse the syntax without negation or default excludes.
+type Pattern struct {
+	rule rule
+}
+
+// ParsePattern parses one pattern; blank lines, comments and negated
+// patterns are rejected.
+func ParsePattern(line string) (Pattern, bool) {
+	parsed, ok := parseRule(line)
+	if !ok || parsed.negated {
+		return Pattern{}, false
+	}
+	return Pattern{rule: parsed}, true
+}
+
+// Matches reports whether the pattern covers a file path.
+func (p Pattern) Matches(relPath string) bool {
+	return ruleMatches(p.rule, relPath, false)
+}
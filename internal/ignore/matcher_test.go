@@ -44,3 +44,62 @@ func TestMatcher_NegatedDirectoryRule(t *testing.T) {
 		t.Fatalf("expected build/include/file.go to be included")
 	}
 }
+
+func TestMatcher_DirectoryPatternCoversNestedFilesCheckedDirectly(t *testing.T) {
+	m := NewMatcher([]string{"generated/"})
+
+	cases := []struct {
+		path    string
+		isDir   bool
+		ignored bool
+	}{
+		{path: "pkg/generated", isDir: true, ignored: true},
+		{path: "pkg/generated/api.go", isDir: false, ignored: true},
+		{path: "web/node_modules/pkg/index.js", isDir: false, ignored: true},
+		{path: "pkg/generated.go", isDir: false, ignored: false},
+		{path: "pkg/api/generated", isDir: false, ignored: false},
+	}
+	for _, tc := range cases {
+		if got := m.ShouldIgnore(tc.path, tc.isDir); got != tc.ignored {
+			t.Errorf("path %s: expected ignored=%v, got %v", tc.path, tc.ignored, got)
+		}
+	}
+}
+
+func TestGitignoreRuleScopesNestedPatterns(t *testing.T) {
+	cases := []struct {
+		dir, line, want string
+	}{
+		{dir: "", line: "*.log", want: "*.log"},
+		{dir: "", line: "/build/", want: "/build/"},
+		{dir: "web", line: "dist/", want: "/web/**/dist/"},
+		{dir: "web", line: "src/*.gen.ts", want: "/web/src/*.gen.ts"},
+		{dir: "web", line: "!keep.js", want: "!/web/**/keep.js"},
+		{dir: "web", line: "**/cache", want: "/web/**/cache"},
+		{dir: "", line: `\#notes`, want: "**/#notes"},
+	}
+	for _, tc := range cases {
+		got, ok := GitignoreRule(tc.dir, tc.line)
+		if !ok || got != tc.want {
+			t.Errorf("GitignoreRule(%q, %q) = %q, %v; want %q", tc.dir, tc.line, got, ok, tc.want)
+		}
+	}
+	if _, ok := GitignoreRule("web", "# comment"); ok {
+		t.Errorf("expected comments to be skipped")
+	}
+
+	// dist/ is a default rule, so the scoped pattern uses a name of its own.
+	m := NewMatcher([]string{"/web/**/out/", "/web/src/*.gen.ts"})
+	ignored := []string{"web/out/app.js", "web/pkg/out/app.js", "web/src/api.gen.ts"}
+	kept := []string{"web/out.js", "web/src/nested/api.gen.ts", "other/web/out/app.js"}
+	for _, path := range ignored {
+		if !m.ShouldIgnore(path, false) {
+			t.Errorf("expected %s to be ignored", path)
+		}
+	}
+	for _, path := range kept {
+		if m.ShouldIgnore(path, false) {
+			t.Errorf("expected %s to be kept", path)
+		}
+	}
+}
@@ -0,0 +1,605 @@
+package languages
+
+import (
+	"context"
+	"strings"
+
+	"github.com/morozRed/skelly/internal/parser"
+	sitter "github.com/smacker/go-tree-sitter"
+	"github.com/smacker/go-tree-sitter/c"
+	"github.com/smacker/go-tree-sitter/cpp"
+)
+
+// CParser implements parsing for C and C++ source and header files. The C++
+// grammar is a superset of the C one, so both share extraction logic.
+type CParser struct {
+	extractions
+	parser     *sitter.Parser
+	language   string
+	extensions []string
+}
+
+// NewCParser creates a new C parser (.c and .h files)
+func NewCParser() *CParser {
+	p := sitter.NewParser()
+	p.SetLanguage(c.GetLanguage())
+	return &CParser{parser: p, language: "c", extensions: []string{".c", ".h"}}
+}
+
+// NewCppParser creates a new C++ parser
+func NewCppParser() *CParser {
+	p := sitter.NewParser()
+	p.SetLanguage(cpp.GetLanguage())
+	return &CParser{parser: p, language: "cpp", extensions: []string{".cc", ".cpp", ".cxx", ".c++", ".hh", ".hpp", ".hxx", ".h++"}}
+}
+
+func (c *CParser) Language() string {
+	return c.language
+}
+
+func (c *CParser) Extensions() []string {
+	return c.extensions
+}
+
+func (c *CParser) Parse(filename string, content []byte) (*parser.FileSymbols, error) {
+	c.begin(c.language)
+	tree, err := c.parser.ParseCtx(context.Background(), nil, content)
+	if err != nil {
+		return nil, err
+	}
+	defer tree.Close()
+
+	result := &parser.FileSymbols{
+		Path:          filename,
+		Language:      c.language,
+		Symbols:       make([]parser.Symbol, 0),
+		Imports:       make([]string, 0),
+		ImportAliases: make(map[string]string),
+	}
+
+	root := tree.RootNode()
+	c.extractSymbols(root, content, result, "")
+	result.Symbols = dropDefinedPrototypes(result.Symbols)
+
+	return result, nil
+}
+
+func (c *CParser) extractSymbols(node *sitter.Node, content []byte, result *parser.FileSymbols, className string) {
+	switch node.Type() {
+	case "preproc_include":
+		if include := c.extractInclude(node, content); include != "" {
+			result.Imports = append(result.Imports, include)
+		}
+		return
+
+	case "function_definition":
+		sym := c.extractFunction(node, content, className)
+		if sym != nil {
+			result.Symbols = append(result.Symbols, *sym)
+		}
+		// Don't recurse into function bodies (local structs and lambdas are skipped)
+		return
+
+	case "struct_specifier", "union_specifier", "enum_specifier", "class_specifier":
+		bodyNode := node.ChildByFieldName("body")
+		if bodyNode == nil {
+			// Forward declarations and type references carry no members.
+			return
+		}
+		sym := c.extractType(node, content)
+		if sym != nil {
+			sym.Container = className
+			result.Symbols = append(result.Symbols, *sym)
+			for i := 0; i < int(bodyNode.ChildCount()); i++ {
+				c.extractSymbols(bodyNode.Child(i), content, result, cQualifiedName(className, sym.Name))
+			}
+		}
+		return
+
+	case "type_definition":
+		c.extractTypedef(node, content, result)
+		return
+
+	case "preproc_def":
+		if sym := c.extractDefine(node, content); sym != nil {
+			result.Symbols = append(result.Symbols, *sym)
+		}
+		return
+
+	case "declaration":
+		// Function bodies are not walked, so declarations here are globals
+		// and prototypes.
+		result.Symbols = append(result.Symbols, c.extractGlobals(node, content, className)...)
+		result.Symbols = append(result.Symbols, c.extractPrototypes(node, content, className)...)
+		if typeNode := node.ChildByFieldName("type"); typeNode != nil {
+			c.extractSymbols(typeNode, content, result, className)
+		}
+		return
+
+	case "field_declaration":
+		// Member function declarations (void close();) in a class body.
+		if className != "" {
+			result.Symbols = append(result.Symbols, c.extractPrototypes(node, content, className)...)
+		}
+		if typeNode := node.ChildByFieldName("type"); typeNode != nil {
+			c.extractSymbols(typeNode, content, result, className)
+		}
+		return
+	}
+
+	// Recurse into children (covers namespaces, extern "C", templates and #if blocks)
+	for i := 0; i < int(node.ChildCount()); i++ {
+		c.extractSymbols(node.Child(i), content, result, className)
+	}
+}
+
+// extractInclude returns the include path without quotes or angle brackets.
+func (c *CParser) extractInclude(node *sitter.Node, content []byte) string {
+	pathNode := node.ChildByFieldName("path")
+	if pathNode == nil {
+		return ""
+	}
+	include := strings.TrimSpace(pathNode.Content(content))
+	include = strings.Trim(include, `"<>`)
+	return strings.TrimSpace(include)
+}
+
+func (c *CParser) extractFunction(node *sitter.Node, content []byte, className string) *parser.Symbol {
+	declarator := functionDeclarator(node.ChildByFieldName("declarator"))
+	if declarator == nil {
+		return nil
+	}
+	nameNode := declarator.ChildByFieldName("declarator")
+	if nameNode == nil {
+		return nil
+	}
+
+	kind := parser.SymbolFunction
+	name := strings.TrimSpace(nameNode.Content(content))
+	container := className
+	if nameNode.Type() == "qualified_identifier" {
+		// Out-of-line member definitions (void Client::close()) are methods.
+		if inner := nameNode.ChildByFieldName("name"); inner != nil {
+			name = strings.TrimSpace(inner.Content(content))
+		}
+		if scope := nameNode.ChildByFieldName("scope"); scope != nil {
+			container = cQualifiedName(className, strings.TrimSpace(scope.Content(content)))
+		}
+		kind = parser.SymbolMethod
+	}
+	if className != "" {
+		kind = parser.SymbolMethod
+	}
+
+	// The signature runs from the return type through the declarator, which
+	// skips constructor initializer lists and the body.
+	signature := strings.Join(strings.Fields(string(content[node.StartByte():declarator.EndByte()])), " ")
+
+	return &parser.Symbol{
+		Name:      name,
+		Kind:      kind,
+		Signature: signature,
+		Line:      int(node.StartPoint().Row) + 1,
+		Span:      symbolSpan(node),
+		Doc:       c.doc(cDocComment, node, content),
+		Container: container,
+		Calls:     c.extractCalls(node.ChildByFieldName("body"), content),
+	}
+}
+
+// cQualifiedName joins a type name onto its enclosing class with "::".
+func cQualifiedName(className, name string) string {
+	if className == "" {
+		return name
+	}
+	return className + "::" + name
+}
+
+// functionDeclarator unwraps pointer/reference declarators around a function declarator.
+func functionDeclarator(node *sitter.Node) *sitter.Node {
+	for node != nil {
+		switch node.Type() {
+		case "function_declarator":
+			return node
+		case "pointer_declarator", "reference_declarator", "parenthesized_declarator":
+			inner := node.ChildByFieldName("declarator")
+			if inner == nil && node.NamedChildCount() > 0 {
+				inner = node.NamedChild(int(node.NamedChildCount()) - 1)
+			}
+			node = inner
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+func (c *CParser) extractType(node *sitter.Node, content []byte) *parser.Symbol {
+	nameNode := node.ChildByFieldName("name")
+	if nameNode == nil {
+		return nil
+	}
+
+	keyword := strings.TrimSuffix(node.Type(), "_specifier")
+	name := strings.TrimSpace(nameNode.Content(content))
+	sig := keyword + " " + name
+	kind := parser.SymbolStruct
+	if keyword == "class" {
+		kind = parser.SymbolClass
+	}
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		if child := node.NamedChild(i); child.Type() == "base_class_clause" {
+			sig += " " + strings.Join(strings.Fields(child.Content(content)), " ")
+		}
+	}
+
+	return &parser.Symbol{
+		Name:      name,
+		Kind:      kind,
+		Signature: sig,
+		Line:      int(node.StartPoint().Row) + 1,
+		Span:      symbolSpan(node),
+		Doc:       c.doc(cDocComment, node, content),
+	}
+}
+
+// extractTypedef records typedef aliases; a named struct/union/enum defined
+// inline (typedef struct node {...} node_t) is recorded as well.
+func (c *CParser) extractTypedef(node *sitter.Node, content []byte, result *parser.FileSymbols) {
+	typeNode := node.ChildByFieldName("type")
+	hasBody := typeNode != nil && typeNode.ChildByFieldName("body") != nil
+	if hasBody {
+		c.extractSymbols(typeNode, content, result, "")
+	}
+
+	for i := 0; i < int(node.ChildCount()); i++ {
+		if node.FieldNameForChild(i) != "declarator" {
+			continue
+		}
+		name := cDeclaratorName(node.Child(i), content)
+		if name == "" {
+			continue
+		}
+
+		sig := strings.TrimSuffix(strings.Join(strings.Fields(node.Content(content)), " "), ";")
+		if hasBody {
+			sig = "typedef " + strings.TrimSuffix(typeNode.Type(), "_specifier") + " " + name
+		}
+		result.Symbols = append(result.Symbols, parser.Symbol{
+			Name:      name,
+			Kind:      parser.SymbolStruct,
+			Signature: sig,
+			Line:      int(node.StartPoint().Row) + 1,
+			Span:      symbolSpan(node),
+			Doc:       c.doc(cDocComment, node, content),
+		})
+	}
+}
+
+// extractDefine returns the constant of an object-like macro with a value
+// (#define MAX_RETRIES 3). Empty macros such as include guards and
+// function-like macros are left out.
+func (c *CParser) extractDefine(node *sitter.Node, content []byte) *parser.Symbol {
+	nameNode := node.ChildByFieldName("name")
+	valueNode := node.ChildByFieldName("value")
+	if nameNode == nil || valueNode == nil {
+		return nil
+	}
+	value := strings.TrimSpace(valueNode.Content(content))
+	if value == "" {
+		return nil
+	}
+
+	name := nameNode.Content(content)
+	signature := "#define " + name
+	if !strings.Contains(value, "\n") && len(value) <= maxValueSignature {
+		signature += " " + value
+	}
+	return &parser.Symbol{
+		Name:      name,
+		Kind:      parser.SymbolConstant,
+		Signature: signature,
+		Line:      int(node.StartPoint().Row) + 1,
+		Span:      symbolSpan(node),
+		Doc:       c.doc(cDocComment, node, content),
+	}
+}
+
+// extractGlobals returns a symbol per variable a file- or namespace-level
+// declaration declares: constants when declared const or constexpr,
+// variables otherwise. Function declarators are left out.
+func (c *CParser) extractGlobals(node *sitter.Node, content []byte, className string) []parser.Symbol {
+	typeNode := node.ChildByFieldName("type")
+	if typeNode == nil {
+		return nil
+	}
+	kind := parser.SymbolVariable
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		child := node.NamedChild(i)
+		if child.Type() == "type_qualifier" && (child.Content(content) == "const" || child.Content(content) == "constexpr") {
+			kind = parser.SymbolConstant
+		}
+	}
+	// The type as written, with a struct body shortened to its name.
+	prefix := string(content[node.StartByte():typeNode.EndByte()])
+	if typeNode.ChildByFieldName("body") != nil {
+		prefix = string(content[node.StartByte():typeNode.StartByte()]) + strings.TrimSuffix(typeNode.Type(), "_specifier")
+		if nameNode := typeNode.ChildByFieldName("name"); nameNode != nil {
+			prefix += " " + nameNode.Content(content)
+		}
+	}
+	prefix = strings.Join(strings.Fields(prefix), " ")
+
+	symbols := make([]parser.Symbol, 0)
+	for i := 0; i < int(node.ChildCount()); i++ {
+		if node.FieldNameForChild(i) != "declarator" {
+			continue
+		}
+		declarator := node.Child(i)
+		var valueNode *sitter.Node
+		if declarator.Type() == "init_declarator" {
+			valueNode = declarator.ChildByFieldName("value")
+			declarator = declarator.ChildByFieldName("declarator")
+		}
+		name := cVariableName(declarator, content)
+		if name == "" {
+			continue
+		}
+		signature := prefix + " " + strings.Join(strings.Fields(declarator.Content(content)), " ")
+		if valueNode != nil {
+			if strings.Contains(string(content[declarator.EndByte():valueNode.StartByte()]), "=") {
+				signature = valueSignature(signature, valueNode.Content(content))
+			} else if direct := valueSignature(signature, valueNode.Content(content)); direct != signature {
+				// C++ direct initialization: Registry registry{load()};
+				signature += valueNode.Content(content)
+			}
+		}
+		symbols = append(symbols, parser.Symbol{
+			Name:      name,
+			Kind:      kind,
+			Signature: signature,
+			Line:      int(declarator.StartPoint().Row) + 1,
+			Span:      symbolSpan(node),
+			Doc:       c.doc(cDocComment, node, content),
+			Container: className,
+			Calls:     c.extractCalls(valueNode, content),
+		})
+	}
+	return symbols
+}
+
+// extractPrototypes returns a symbol per function a declaration declares
+// without defining it (int add(int a, int b);), marked Prototype. Function
+// pointer variables and qualified names (friend declarations) are left out.
+func (c *CParser) extractPrototypes(node *sitter.Node, content []byte, className string) []parser.Symbol {
+	typeNode := node.ChildByFieldName("type")
+	symbols := make([]parser.Symbol, 0)
+	for i := 0; i < int(node.ChildCount()); i++ {
+		if node.FieldNameForChild(i) != "declarator" {
+			continue
+		}
+		declarator := node.Child(i)
+		function := functionDeclarator(declarator)
+		if function == nil {
+			continue
+		}
+		nameNode := function.ChildByFieldName("declarator")
+		if nameNode == nil {
+			continue
+		}
+		switch nameNode.Type() {
+		case "identifier", "field_identifier", "destructor_name", "operator_name":
+		default:
+			continue
+		}
+		kind := parser.SymbolFunction
+		if className != "" {
+			kind = parser.SymbolMethod
+		}
+		// Specifiers and type, then the declarator, as for definitions.
+		signature := string(content[node.StartByte():declarator.EndByte()])
+		if typeNode != nil {
+			signature = string(content[node.StartByte():typeNode.EndByte()]) + " " + declarator.Content(content)
+		}
+		symbols = append(symbols, parser.Symbol{
+			Name:      strings.TrimSpace(nameNode.Content(content)),
+			Kind:      kind,
+			Signature: strings.Join(strings.Fields(signature), " "),
+			Line:      int(declarator.StartPoint().Row) + 1,
+			Span:      symbolSpan(node),
+			Doc:       c.doc(cDocComment, node, content),
+			Container: className,
+			Prototype: true,
+		})
+	}
+	return symbols
+}
+
+// dropDefinedPrototypes leaves out the prototypes of functions the same
+// file also defines, such as forward declarations of static helpers.
+func dropDefinedPrototypes(symbols []parser.Symbol) []parser.Symbol {
+	defined := make(map[string]bool)
+	for _, symbol := range symbols {
+		if !symbol.Prototype && (symbol.Kind == parser.SymbolFunction || symbol.Kind == parser.SymbolMethod) {
+			defined[symbol.QualifiedName()] = true
+		}
+	}
+	kept := symbols[:0]
+	for _, symbol := range symbols {
+		if symbol.Prototype && defined[symbol.QualifiedName()] {
+			continue
+		}
+		kept = append(kept, symbol)
+	}
+	return kept
+}
+
+// cVariableName returns the identifier a variable declarator declares, or ""
+// for function declarators and qualified names (out-of-line static member
+// definitions).
+func cVariableName(node *sitter.Node, content []byte) string {
+	for node != nil {
+		switch node.Type() {
+		case "identifier":
+			return node.Content(content)
+		case "pointer_declarator", "reference_declarator", "array_declarator", "parenthesized_declarator":
+			inner := node.ChildByFieldName("declarator")
+			if inner == nil && node.NamedChildCount() > 0 {
+				inner = node.NamedChild(int(node.NamedChildCount()) - 1)
+			}
+			node = inner
+		default:
+			return ""
+		}
+	}
+	return ""
+}
+
+// cDeclaratorName finds the declared identifier inside pointer, array and function declarators.
+func cDeclaratorName(node *sitter.Node, content []byte) string {
+	for node != nil {
+		switch node.Type() {
+		case "type_identifier", "identifier", "field_identifier", "primitive_type":
+			return strings.TrimSpace(node.Content(content))
+		}
+		inner := node.ChildByFieldName("declarator")
+		if inner == nil {
+			for i := 0; i < int(node.NamedChildCount()); i++ {
+				child := node.NamedChild(i)
+				if child.Type() != "parameter_list" && child.Type() != "type_qualifier" {
+					inner = child
+					break
+				}
+			}
+		}
+		node = inner
+	}
+	return ""
+}
+
+func (c *CParser) extractCalls(bodyNode *sitter.Node, content []byte) []parser.CallSite {
+	if c.skip.SkipCalls {
+		return nil
+	}
+	if bodyNode == nil {
+		return nil
+	}
+
+	calls := make([]parser.CallSite, 0)
+	c.collectCalls(bodyNode, content, &calls)
+	return calls
+}
+
+func (c *CParser) collectCalls(node *sitter.Node, content []byte, calls *[]parser.CallSite) {
+	if node == nil {
+		return
+	}
+
+	if node.Type() == "call_expression" {
+		callSite := c.extractCallSite(node, content)
+		if callSite.Name != "" {
+			*calls = append(*calls, callSite)
+		}
+	}
+
+	for i := 0; i < int(node.ChildCount()); i++ {
+		c.collectCalls(node.Child(i), content, calls)
+	}
+}
+
+func (c *CParser) extractCallSite(callNode *sitter.Node, content []byte) parser.CallSite {
+	fnNode := callNode.ChildByFieldName("function")
+	name, qualifier := c.extractCallName(fnNode, content)
+	callSite := parser.CallSite{
+		Name:      name,
+		Qualifier: qualifier,
+		Raw:       "",
+		Line:      int(callNode.StartPoint().Row) + 1,
+	}
+	if argsNode := callNode.ChildByFieldName("arguments"); argsNode != nil {
+		callSite.Arity = int(argsNode.NamedChildCount())
+	}
+	if fnNode != nil {
+		callSite.Raw = strings.TrimSpace(fnNode.Content(content))
+	}
+	if qualifier == "this" {
+		callSite.Receiver = qualifier
+	}
+	return callSite
+}
+
+// extractCallName returns the called name and a dot-separated qualifier
+// (obj->run and ns::run both yield qualifier "obj"/"ns").
+func (c *CParser) extractCallName(node *sitter.Node, content []byte) (name, qualifier string) {
+	if node == nil {
+		return "", ""
+	}
+
+	switch node.Type() {
+	case "identifier", "field_identifier":
+		return node.Content(content), ""
+	case "field_expression":
+		fieldNode := node.ChildByFieldName("field")
+		if fieldNode == nil {
+			break
+		}
+		qualifierValue := ""
+		if argumentNode := node.ChildByFieldName("argument"); argumentNode != nil {
+			qualifierValue = strings.TrimSpace(argumentNode.Content(content))
+		}
+		return fieldNode.Content(content), qualifierValue
+	case "qualified_identifier":
+		nameNode := node.ChildByFieldName("name")
+		if nameNode == nil {
+			break
+		}
+		qualifierValue := ""
+		if scopeNode := node.ChildByFieldName("scope"); scopeNode != nil {
+			qualifierValue = strings.ReplaceAll(strings.TrimSpace(scopeNode.Content(content)), "::", ".")
+		}
+		innerName, innerQualifier := c.extractCallName(nameNode, content)
+		if innerQualifier != "" {
+			qualifierValue += "." + innerQualifier
+		}
+		return innerName, qualifierValue
+	case "template_function":
+		return c.extractCallName(node.ChildByFieldName("name"), content)
+	case "parenthesized_expression":
+		if node.NamedChildCount() > 0 {
+			return c.extractCallName(node.NamedChild(0), content)
+		}
+	}
+
+	return "", ""
+}
+
+// cDocComment collects contiguous comments directly above a declaration.
+func cDocComment(node *sitter.Node, content []byte) string {
+	lines := make([]string, 0)
+	expectedRow := node.StartPoint().Row
+	for sibling := node.PrevSibling(); sibling != nil && sibling.Type() == "comment"; sibling = sibling.PrevSibling() {
+		if sibling.EndPoint().Row+1 < expectedRow {
+			break
+		}
+		expectedRow = sibling.StartPoint().Row
+		text := strings.TrimSpace(sibling.Content(content))
+		switch {
+		case strings.HasPrefix(text, "/*"):
+			text = strings.TrimSuffix(strings.TrimLeft(text, "/*!"), "*/")
+			parts := make([]string, 0)
+			for _, line := range strings.Split(text, "\n") {
+				line = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "*"))
+				if line != "" {
+					parts = append(parts, line)
+				}
+			}
+			text = strings.Join(parts, " ")
+		default:
+			text = strings.TrimSpace(strings.TrimLeft(text, "/!"))
+		}
+		if text != "" {
+			lines = append([]string{text}, lines...)
+		}
+	}
+	return strings.TrimSpace(strings.Join(lines, " "))
+}
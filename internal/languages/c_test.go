@@ -0,0 +1,262 @@
+package languages
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/morozRed/skelly/internal/parser"
+)
+
+func TestCParserExtractsFunctionsTypesAndIncludes(t *testing.T) {
+	parser := NewCParser()
+	file, err := parser.Parse("src/str.c", []byte(`#include "util/str.h"
+#include <stdio.h>
+
+/* Duplicates a string. */
+char *str_dup(const char *s) { log_call(1); return copy(s, len(s)); }
+
+static int count(void) { return 0; }
+
+struct buffer { char *data; };
+typedef struct node { int v; } node_t;
+typedef unsigned long size_type;
+int prototype_only(int);
+`))
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	symbols := make(map[string]string)
+	for _, symbol := range file.Symbols {
+		symbols[symbol.Name+"/"+symbol.Kind.String()] = symbol.Signature
+	}
+	for key, want := range map[string]string{
+		"str_dup/func":     "char *str_dup(const char *s)",
+		"count/func":       "static int count(void)",
+		"buffer/struct":    "struct buffer",
+		"node/struct":      "struct node",
+		"node_t/struct":    "typedef struct node_t",
+		"size_type/struct": "typedef unsigned long size_type",
+	} {
+		if got := symbols[key]; got != want {
+			t.Fatalf("expected %s signature %q, got %q (all: %#v)", key, want, got, symbols)
+		}
+	}
+	if got := symbols["prototype_only/func"]; got != "int prototype_only(int)" {
+		t.Fatalf("expected the prototype to be indexed, got %q", got)
+	}
+	if file.Symbols[0].Doc != "Duplicates a string." {
+		t.Fatalf("expected doc comment on str_dup, got %q", file.Symbols[0].Doc)
+	}
+	if len(file.Imports) != 2 || file.Imports[0] != "util/str.h" || file.Imports[1] != "stdio.h" {
+		t.Fatalf("unexpected includes: %#v", file.Imports)
+	}
+	if calls := file.Symbols[0].Calls; len(calls) != 3 || calls[1].Name != "copy" || calls[1].Arity != 2 {
+		t.Fatalf("unexpected calls in str_dup: %#v", calls)
+	}
+}
+
+func TestCppParserExtractsClassesAndMethods(t *testing.T) {
+	parser := NewCppParser()
+	file, err := parser.Parse("net/client.cpp", []byte(`#include "net/client.hpp"
+
+namespace net {
+/// A network client.
+class Client : public Base {
+public:
+  Client(int port) : port_(port) {}
+  int send(const std::string& s) const { return write(s); }
+private:
+  int port_;
+};
+
+void Client::close() { this->flush(); util::log("closing"); sock_.shutdown(); }
+}
+`))
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	symbols := make(map[string]string)
+	for _, symbol := range file.Symbols {
+		symbols[symbol.Name+"/"+symbol.Kind.String()] = symbol.Signature
+	}
+	for key, want := range map[string]string{
+		"Client/class":  "class Client : public Base",
+		"Client/method": "Client(int port)",
+		"send/method":   "int send(const std::string& s) const",
+		"close/method":  "void Client::close()",
+	} {
+		if got := symbols[key]; got != want {
+			t.Fatalf("expected %s signature %q, got %q (all: %#v)", key, want, got, symbols)
+		}
+	}
+	if file.Symbols[0].Doc != "A network client." {
+		t.Fatalf("expected doc comment on Client, got %q", file.Symbols[0].Doc)
+	}
+
+	var closeCalls []string
+	for _, symbol := range file.Symbols {
+		if symbol.Name == "close" {
+			for _, call := range symbol.Calls {
+				closeCalls = append(closeCalls, call.Qualifier+"|"+call.Name+"|"+call.Receiver)
+			}
+		}
+	}
+	want := []string{"this|flush|this", "util|log|", "sock_|shutdown|"}
+	if len(closeCalls) != len(want) {
+		t.Fatalf("unexpected calls in close: %#v", closeCalls)
+	}
+	for i := range want {
+		if closeCalls[i] != want[i] {
+			t.Fatalf("unexpected calls in close: %#v", closeCalls)
+		}
+	}
+}
+
+func TestCParserExtractsDefinesAndGlobals(t *testing.T) {
+	file, err := NewCParser().Parse("src/config.c", []byte(`#ifndef CONFIG_H
+#define CONFIG_H
+#define MAX_RETRIES 3
+#define SQUARE(x) ((x) * (x))
+
+/* Names of the known modes. */
+static const char *const names[] = {"fast", "safe"};
+int counter = 0, *cursor;
+extern int shared;
+struct config defaults = make_config();
+struct point { int x; } origin;
+int run(void);
+
+int main(void) {
+    int local = 1;
+    return local;
+}
+#endif
+`))
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	got := make([]string, 0)
+	for _, symbol := range file.Symbols {
+		if symbol.Kind == parser.SymbolConstant || symbol.Kind == parser.SymbolVariable {
+			got = append(got, symbol.Kind.String()+" "+symbol.Signature)
+		}
+		if symbol.Name == "names" && symbol.Doc != "Names of the known modes." {
+			t.Fatalf("expected doc comment on names, got %q", symbol.Doc)
+		}
+		if symbol.Name == "defaults" && (len(symbol.Calls) != 1 || symbol.Calls[0].Name != "make_config") {
+			t.Fatalf("expected defaults to call make_config, got %+v", symbol.Calls)
+		}
+		if symbol.Name == "point" && symbol.Kind != parser.SymbolStruct {
+			t.Fatalf("expected struct point to stay indexed, got %+v", symbol)
+		}
+	}
+	want := []string{
+		"const #define MAX_RETRIES 3",
+		`const static const char *const names[] = {"fast", "safe"}`,
+		"var int counter = 0",
+		"var int *cursor",
+		"var extern int shared",
+		"var struct config defaults = make_config()",
+		"var struct point origin",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("unexpected defines and globals:\n%s", strings.Join(got, "\n"))
+	}
+}
+
+func TestCppParserExtractsNamespaceConstants(t *testing.T) {
+	file, err := NewCppParser().Parse("src/limits.cpp", []byte(`namespace acme {
+constexpr int kLimit = 10;
+Registry registry{load_registry()};
+int Widget::count = 0;
+}
+`))
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	got := make([]string, 0)
+	for _, symbol := range file.Symbols {
+		if symbol.Kind == parser.SymbolConstant || symbol.Kind == parser.SymbolVariable {
+			got = append(got, symbol.Kind.String()+" "+symbol.Signature)
+		}
+	}
+	want := []string{
+		"const constexpr int kLimit = 10",
+		"var Registry registry{load_registry()}",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("unexpected namespace constants:\n%s", strings.Join(got, "\n"))
+	}
+}
+
+func TestCParserIndexesPrototypes(t *testing.T) {
+	file, err := NewCParser().Parse("include/point.h", []byte(`#ifndef POINT_H
+#define POINT_H
+
+/** Adds two points. */
+struct point point_add(struct point a, struct point b);
+extern const char *point_name(int id), *point_label(int id);
+static void helper(void);
+int (*on_change)(int);
+
+static void helper(void) {}
+#endif
+`))
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	got := make([]string, 0)
+	for _, symbol := range file.Symbols {
+		if symbol.Kind != parser.SymbolFunction {
+			continue
+		}
+		got = append(got, fmt.Sprintf("%s prototype=%t", symbol.Signature, symbol.Prototype))
+		if symbol.Name == "point_add" && symbol.Doc != "Adds two points." {
+			t.Fatalf("expected doc comment on point_add, got %q", symbol.Doc)
+		}
+	}
+	want := []string{
+		"struct point point_add(struct point a, struct point b) prototype=true",
+		"extern const char *point_name(int id) prototype=true",
+		"extern const char *point_label(int id) prototype=true",
+		"static void helper(void) prototype=false",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("unexpected functions:\n%s", strings.Join(got, "\n"))
+	}
+
+	cpp, err := NewCppParser().Parse("net/client.hpp", []byte(`namespace net {
+class Client {
+public:
+  Client(int port);
+  virtual void close() = 0;
+  int send(const std::string& s) const;
+  int (*callback)(int);
+private:
+  int port_;
+};
+}
+`))
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	got = got[:0]
+	for _, symbol := range cpp.Symbols {
+		if symbol.Kind == parser.SymbolMethod {
+			got = append(got, symbol.Container+" "+symbol.Signature)
+		}
+	}
+	want = []string{
+		"Client Client(int port)",
+		"Client virtual void close()",
+		"Client int send(const std::string& s) const",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("unexpected member prototypes:\n%s", strings.Join(got, "\n"))
+	}
+}
@@ -3,6 +3,9 @@ package languages
 import (
 	"path/filepath"
 	"strings"
+
+	"github.com/morozRed/skelly/internal/parser"
+	sitter "github.com/smacker/go-tree-sitter"
 )
 
 func splitQualifiedName(raw string) (qualifier, name string) {
@@ -18,6 +21,50 @@ func splitQualifiedName(raw string) (qualifier, name string) {
 	return "", raw
 }
 
+// extractions holds the optional extractions a parser was configured to
+// leave out (parser.FeatureParser). Parsers embed it and call begin with the
+// language of each file they parse.
+type extractions struct {
+	features map[string]parser.Features
+	skip     parser.Features
+}
+
+// SetFeatures implements parser.FeatureParser.
+func (e *extractions) SetFeatures(features map[string]parser.Features) {
+	e.features = features
+}
+
+// begin selects the features of language for the file about to be parsed.
+func (e *extractions) begin(language string) {
+	e.skip = e.features[language]
+}
+
+// doc returns what extract finds for node, or "" when docs are turned off.
+func (e *extractions) doc(extract func(*sitter.Node, []byte) string, node *sitter.Node, content []byte) string {
+	if e.skip.SkipDocs {
+		return ""
+	}
+	return extract(node, content)
+}
+
+// references returns extract(), or nil without calling it when references
+// are turned off.
+func (e *extractions) references(extract func() []string) []string {
+	if e.skip.SkipReferences {
+		return nil
+	}
+	return extract()
+}
+
+// symbolSpan returns the span of the declaration node.
+func symbolSpan(node *sitter.Node) parser.Span {
+	return parser.Span{
+		EndLine:   int(node.EndPoint().Row) + 1,
+		StartByte: int(node.StartByte()),
+		EndByte:   int(node.EndByte()),
+	}
+}
+
 func defaultImportAlias(path string) string {
 	base := filepath.Base(strings.TrimSpace(path))
 	if base == "." || base == "/" {
@@ -57,3 +104,91 @@ func splitAliasByAs(raw string) (base string, alias string) {
 	alias = strings.TrimSpace(parts[len(parts)-1])
 	return base, alias
 }
+
+// docBlockSummary returns the first line of a /** ... */ doc block (Javadoc,
+// PHPDoc) directly above a declaration, skipping @tag lines.
+func docBlockSummary(node *sitter.Node, content []byte) string {
+	sibling := node.PrevSibling()
+	if sibling == nil || (sibling.Type() != "block_comment" && sibling.Type() != "comment") {
+		return ""
+	}
+	text := strings.TrimSpace(sibling.Content(content))
+	if !strings.HasPrefix(text, "/**") {
+		return ""
+	}
+	text = strings.TrimSuffix(strings.TrimPrefix(text, "/**"), "*/")
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "*"))
+		if line != "" && !strings.HasPrefix(line, "@") {
+			return line
+		}
+	}
+	return ""
+}
+
+// maxValueSignature caps the initializer shown in a constant or variable
+// signature.
+const maxValueSignature = 80
+
+// valueSignature returns the signature of a constant or variable: its
+// declaration up to the initializer, plus the initializer as written when it
+// fits on one short line.
+func valueSignature(declaration, value string) string {
+	value = strings.TrimSpace(value)
+	if value == "" || strings.Contains(value, "\n") || len(value) > maxValueSignature {
+		return declaration
+	}
+	return declaration + " = " + value
+}
+
+// appendReference adds a referenced type name to refs unless it is empty or
+// already listed.
+func appendReference(refs []string, name string) []string {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return refs
+	}
+	for _, existing := range refs {
+		if existing == name {
+			return refs
+		}
+	}
+	return append(refs, name)
+}
+
+// stringLiteral returns the value of a plain string literal, or false for
+// other nodes and for interpolated strings (f-strings, `${}` templates).
+func stringLiteral(node *sitter.Node, content []byte) (string, bool) {
+	if node == nil {
+		return "", false
+	}
+	switch node.Type() {
+	case "interpreted_string_literal", "raw_string_literal", "string":
+	default:
+		return "", false
+	}
+	raw := strings.TrimLeft(node.Content(content), "rRuU")
+	if len(raw) < 2 || !strings.ContainsRune(`"'`+"`", rune(raw[0])) || raw[len(raw)-1] != raw[0] {
+		return "", false
+	}
+	value := raw[1 : len(raw)-1]
+	if strings.Contains(value, "${") {
+		return "", false
+	}
+	return value, true
+}
+
+// namedArguments returns the named children of an argument list, skipping
+// comments.
+func namedArguments(args *sitter.Node) []*sitter.Node {
+	if args == nil {
+		return nil
+	}
+	out := make([]*sitter.Node, 0, args.NamedChildCount())
+	for i := 0; i < int(args.NamedChildCount()); i++ {
+		if arg := args.NamedChild(i); arg.Type() != "comment" {
+			out = append(out, arg)
+		}
+	}
+	return out
+}
@@ -0,0 +1,491 @@
+package languages
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/morozRed/skelly/internal/parser"
+	sitter "github.com/smacker/go-tree-sitter"
+	"github.com/smacker/go-tree-sitter/csharp"
+)
+
+// CSharpParser implements parsing for C# source files
+type CSharpParser struct {
+	extractions
+	parser *sitter.Parser
+}
+
+// NewCSharpParser creates a new C# parser
+func NewCSharpParser() *CSharpParser {
+	p := sitter.NewParser()
+	p.SetLanguage(csharp.GetLanguage())
+	return &CSharpParser{parser: p}
+}
+
+func (p *CSharpParser) Language() string {
+	return "csharp"
+}
+
+func (p *CSharpParser) Extensions() []string {
+	return []string{".cs"}
+}
+
+func (p *CSharpParser) Parse(filename string, content []byte) (*parser.FileSymbols, error) {
+	p.begin("csharp")
+	tree, err := p.parser.ParseCtx(context.Background(), nil, content)
+	if err != nil {
+		return nil, err
+	}
+	defer tree.Close()
+
+	result := &parser.FileSymbols{
+		Path:          filename,
+		Language:      "csharp",
+		Symbols:       make([]parser.Symbol, 0),
+		Imports:       make([]string, 0),
+		ImportAliases: make(map[string]string),
+	}
+
+	root := tree.RootNode()
+	p.extractSymbols(root, content, result, "")
+
+	return result, nil
+}
+
+func (p *CSharpParser) extractSymbols(node *sitter.Node, content []byte, result *parser.FileSymbols, className string) {
+	switch node.Type() {
+	case "namespace_declaration", "file_scoped_namespace_declaration":
+		if nameNode := node.ChildByFieldName("name"); nameNode != nil {
+			name := strings.Join(strings.Fields(nameNode.Content(content)), "")
+			result.Symbols = append(result.Symbols, parser.Symbol{
+				Name:      name,
+				Kind:      parser.SymbolModule,
+				Signature: "namespace " + name,
+				Line:      int(node.StartPoint().Row) + 1,
+				Span:      symbolSpan(node),
+				Doc:       p.doc(csharpDocComment, node, content),
+			})
+		}
+		// Block namespaces carry their declarations in a body; file-scoped
+		// namespaces are followed by their declarations as siblings.
+		for i := 0; i < int(node.ChildCount()); i++ {
+			p.extractSymbols(node.Child(i), content, result, "")
+		}
+		return
+
+	case "using_directive":
+		imports, aliases := p.extractUsing(node, content)
+		result.Imports = append(result.Imports, imports...)
+		result.ImportAliases = mergeImportAliases(result.ImportAliases, aliases)
+		return
+
+	case "class_declaration", "record_declaration", "record_struct_declaration", "struct_declaration", "interface_declaration", "enum_declaration":
+		sym := p.extractType(node, content)
+		if sym != nil {
+			sym.Container = className
+			result.Symbols = append(result.Symbols, *sym)
+			// Recurse into the declaration list to get members and nested types
+			if bodyNode := node.ChildByFieldName("body"); bodyNode != nil {
+				for i := 0; i < int(bodyNode.ChildCount()); i++ {
+					p.extractSymbols(bodyNode.Child(i), content, result, sym.QualifiedName())
+				}
+			}
+		}
+		return
+
+	case "method_declaration", "constructor_declaration", "property_declaration":
+		sym := p.extractMember(node, content, className)
+		if sym != nil {
+			result.Symbols = append(result.Symbols, *sym)
+		}
+		// Don't recurse into member bodies for local functions and lambdas (for now)
+		return
+
+	case "field_declaration":
+		result.Symbols = append(result.Symbols, p.extractFields(node, content, className)...)
+		return
+	}
+
+	// Recurse into children (covers compilation_unit and declaration lists)
+	for i := 0; i < int(node.ChildCount()); i++ {
+		p.extractSymbols(node.Child(i), content, result, className)
+	}
+}
+
+// extractUsing maps `using` directives onto slash-separated namespace paths
+// (Acme.Billing -> Acme/Billing) so the graph can match them to namespaces.
+func (p *CSharpParser) extractUsing(node *sitter.Node, content []byte) ([]string, map[string]string) {
+	aliases := make(map[string]string)
+	isStatic := false
+	alias := ""
+	name := ""
+	for i := 0; i < int(node.ChildCount()); i++ {
+		child := node.Child(i)
+		if node.FieldNameForChild(i) == "name" {
+			alias = strings.TrimSpace(child.Content(content))
+			continue
+		}
+		switch child.Type() {
+		case "static":
+			isStatic = true
+		case "qualified_name", "identifier", "generic_name":
+			name = strings.Join(strings.Fields(child.Content(content)), "")
+		}
+	}
+	if name == "" {
+		return nil, aliases
+	}
+
+	path := strings.ReplaceAll(csharpStripTypeArguments(name), ".", "/")
+	if alias != "" {
+		// `using Pay = Acme.Payments.Gateway;` binds Pay to a type or namespace.
+		aliases[alias] = path
+	} else if isStatic {
+		// `using static System.Math;` exposes the members of a class unqualified.
+		aliases[defaultImportAlias(path)] = path
+	}
+	return []string{path}, aliases
+}
+
+func (p *CSharpParser) extractType(node *sitter.Node, content []byte) *parser.Symbol {
+	nameNode := node.ChildByFieldName("name")
+	if nameNode == nil {
+		return nil
+	}
+
+	kind := parser.SymbolClass
+	switch node.Type() {
+	case "interface_declaration":
+		kind = parser.SymbolInterface
+	case "struct_declaration", "record_struct_declaration":
+		kind = parser.SymbolStruct
+	}
+
+	return &parser.Symbol{
+		Name:      nameNode.Content(content),
+		Kind:      kind,
+		Signature: p.buildTypeSignature(node, content),
+		Line:      int(node.StartPoint().Row) + 1,
+		Span:      symbolSpan(node),
+		Doc:       p.doc(csharpDocComment, node, content),
+	}
+}
+
+func (p *CSharpParser) buildTypeSignature(node *sitter.Node, content []byte) string {
+	parts := make([]string, 0)
+	if modifiers := csharpModifiers(node, content); modifiers != "" {
+		parts = append(parts, modifiers)
+	}
+	parts = append(parts, strings.TrimSuffix(node.Type(), "_declaration"))
+	if node.Type() == "record_struct_declaration" {
+		parts[len(parts)-1] = "record struct"
+	}
+
+	sig := strings.Join(parts, " ")
+	if nameNode := node.ChildByFieldName("name"); nameNode != nil {
+		sig += " " + nameNode.Content(content)
+	}
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		child := node.NamedChild(i)
+		switch child.Type() {
+		case "type_parameter_list", "parameter_list":
+			sig += strings.Join(strings.Fields(child.Content(content)), " ")
+		case "base_list":
+			sig += " " + strings.Join(strings.Fields(child.Content(content)), " ")
+		}
+	}
+	return sig
+}
+
+func (p *CSharpParser) extractMember(node *sitter.Node, content []byte, className string) *parser.Symbol {
+	nameNode := node.ChildByFieldName("name")
+	if nameNode == nil {
+		return nil
+	}
+
+	kind := parser.SymbolMethod
+	if className == "" {
+		kind = parser.SymbolFunction
+	}
+
+	var calls []parser.CallSite
+	if node.Type() == "property_declaration" {
+		// Accessor bodies, expression bodies and initializers can all call out.
+		calls = p.extractCalls(node, content)
+	} else if bodyNode := node.ChildByFieldName("body"); bodyNode != nil {
+		calls = p.extractCalls(bodyNode, content)
+	} else {
+		for i := 0; i < int(node.NamedChildCount()); i++ {
+			if child := node.NamedChild(i); child.Type() == "arrow_expression_clause" {
+				calls = p.extractCalls(child, content)
+			}
+		}
+	}
+
+	return &parser.Symbol{
+		Name:      nameNode.Content(content),
+		Kind:      kind,
+		Signature: p.buildMemberSignature(node, content),
+		Line:      int(node.StartPoint().Row) + 1,
+		Span:      symbolSpan(node),
+		Doc:       p.doc(csharpDocComment, node, content),
+		Container: className,
+		Calls:     calls,
+	}
+}
+
+// extractFields returns a symbol per declarator of a const or static field:
+// const and static readonly fields are constants, other static fields
+// variables. Instance fields are left out.
+func (p *CSharpParser) extractFields(node *sitter.Node, content []byte, className string) []parser.Symbol {
+	modifiers := csharpModifiers(node, content)
+	words := strings.Fields(modifiers)
+	kind := parser.SymbolConstant
+	switch {
+	case slices.Contains(words, "const"):
+	case slices.Contains(words, "static"):
+		if !slices.Contains(words, "readonly") {
+			kind = parser.SymbolVariable
+		}
+	default:
+		return nil
+	}
+
+	var declaration *sitter.Node
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		if child := node.NamedChild(i); child.Type() == "variable_declaration" {
+			declaration = child
+		}
+	}
+	if declaration == nil {
+		return nil
+	}
+	prefix := modifiers
+	if typeNode := declaration.ChildByFieldName("type"); typeNode != nil {
+		prefix = strings.TrimSpace(prefix + " " + strings.Join(strings.Fields(typeNode.Content(content)), " "))
+	}
+
+	symbols := make([]parser.Symbol, 0)
+	for i := 0; i < int(declaration.NamedChildCount()); i++ {
+		declarator := declaration.NamedChild(i)
+		if declarator.Type() != "variable_declarator" {
+			continue
+		}
+		nameNode := declarator.ChildByFieldName("name")
+		if nameNode == nil {
+			continue
+		}
+		name := nameNode.Content(content)
+		signature := prefix + " " + name
+		// The initializer is the node after `=`; it has no field name.
+		var valueNode *sitter.Node
+		for k := 0; k+1 < int(declarator.ChildCount()); k++ {
+			if declarator.Child(k).Type() == "=" {
+				valueNode = declarator.Child(k + 1)
+				signature = valueSignature(signature, valueNode.Content(content))
+				break
+			}
+		}
+		symbols = append(symbols, parser.Symbol{
+			Name:      name,
+			Kind:      kind,
+			Signature: signature,
+			Line:      int(declarator.StartPoint().Row) + 1,
+			Span:      symbolSpan(node),
+			Doc:       p.doc(csharpDocComment, node, content),
+			Container: className,
+			Calls:     p.extractCalls(valueNode, content),
+		})
+	}
+	return symbols
+}
+
+func (p *CSharpParser) buildMemberSignature(node *sitter.Node, content []byte) string {
+	sig := csharpModifiers(node, content)
+	appendPart := func(part string) {
+		if part == "" {
+			return
+		}
+		if sig != "" {
+			sig += " "
+		}
+		sig += strings.Join(strings.Fields(part), " ")
+	}
+
+	if returnsNode := node.ChildByFieldName("returns"); returnsNode != nil {
+		appendPart(returnsNode.Content(content))
+	}
+	if typeNode := node.ChildByFieldName("type"); typeNode != nil {
+		appendPart(typeNode.Content(content))
+	}
+	if nameNode := node.ChildByFieldName("name"); nameNode != nil {
+		appendPart(nameNode.Content(content))
+	}
+	if typeParams := node.ChildByFieldName("type_parameters"); typeParams != nil {
+		sig += typeParams.Content(content)
+	}
+	if paramsNode := node.ChildByFieldName("parameters"); paramsNode != nil {
+		sig += strings.Join(strings.Fields(paramsNode.Content(content)), " ")
+	}
+	if accessors := node.ChildByFieldName("accessors"); accessors != nil {
+		// Keep `{ get; set; }` but drop accessor bodies.
+		keywords := make([]string, 0)
+		for i := 0; i < int(accessors.NamedChildCount()); i++ {
+			accessor := accessors.NamedChild(i)
+			for k := 0; k < int(accessor.ChildCount()); k++ {
+				switch keyword := accessor.Child(k); keyword.Type() {
+				case "get", "set", "init":
+					keywords = append(keywords, keyword.Type()+";")
+				}
+			}
+		}
+		if len(keywords) > 0 {
+			sig += " { " + strings.Join(keywords, " ") + " }"
+		}
+	}
+	return sig
+}
+
+// csharpModifiers returns keyword modifiers (public, static, async, ...) in source order.
+func csharpModifiers(node *sitter.Node, content []byte) string {
+	parts := make([]string, 0)
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		if child := node.NamedChild(i); child.Type() == "modifier" {
+			parts = append(parts, strings.TrimSpace(child.Content(content)))
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+func (p *CSharpParser) extractCalls(bodyNode *sitter.Node, content []byte) []parser.CallSite {
+	if p.skip.SkipCalls {
+		return nil
+	}
+	if bodyNode == nil {
+		return nil
+	}
+
+	calls := make([]parser.CallSite, 0)
+	p.collectCalls(bodyNode, content, &calls)
+	return calls
+}
+
+func (p *CSharpParser) collectCalls(node *sitter.Node, content []byte, calls *[]parser.CallSite) {
+	if node == nil {
+		return
+	}
+
+	switch node.Type() {
+	case "invocation_expression", "object_creation_expression":
+		callSite := p.extractCallSite(node, content)
+		if callSite.Name != "" {
+			*calls = append(*calls, callSite)
+		}
+	}
+
+	for i := 0; i < int(node.ChildCount()); i++ {
+		p.collectCalls(node.Child(i), content, calls)
+	}
+}
+
+func (p *CSharpParser) extractCallSite(node *sitter.Node, content []byte) parser.CallSite {
+	callSite := parser.CallSite{
+		Line: int(node.StartPoint().Row) + 1,
+	}
+
+	switch node.Type() {
+	case "invocation_expression":
+		fnNode := node.ChildByFieldName("function")
+		if fnNode == nil {
+			return parser.CallSite{}
+		}
+		callSite.Raw = strings.Join(strings.Fields(fnNode.Content(content)), "")
+		if fnNode.Type() == "member_access_expression" {
+			if nameNode := fnNode.ChildByFieldName("name"); nameNode != nil {
+				callSite.Name = csharpStripTypeArguments(nameNode.Content(content))
+			}
+			if exprNode := fnNode.ChildByFieldName("expression"); exprNode != nil {
+				// Namespace- and class-qualified calls keep the full dotted
+				// qualifier (Acme.Billing.Invoice) for the graph to resolve.
+				callSite.Qualifier = csharpStripTypeArguments(strings.Join(strings.Fields(exprNode.Content(content)), ""))
+			}
+		} else {
+			callSite.Qualifier, callSite.Name = splitQualifiedName(csharpStripTypeArguments(callSite.Raw))
+		}
+		switch callSite.Qualifier {
+		case "this", "base":
+			callSite.Receiver = "this"
+		}
+	case "object_creation_expression":
+		// `new Foo()` resolves to the Foo class symbol
+		if typeNode := node.ChildByFieldName("type"); typeNode != nil {
+			name := csharpStripTypeArguments(strings.Join(strings.Fields(typeNode.Content(content)), ""))
+			callSite.Raw = "new " + name
+			callSite.Qualifier, callSite.Name = splitQualifiedName(name)
+		}
+	}
+
+	if argsNode := node.ChildByFieldName("arguments"); argsNode != nil {
+		callSite.Arity = int(argsNode.NamedChildCount())
+	}
+	return callSite
+}
+
+// csharpStripTypeArguments drops generic arguments: Repo<User>.Find -> Repo.Find.
+func csharpStripTypeArguments(name string) string {
+	var b strings.Builder
+	depth := 0
+	for _, r := range name {
+		switch {
+		case r == '<':
+			depth++
+		case r == '>':
+			if depth > 0 {
+				depth--
+			}
+		case depth == 0:
+			b.WriteRune(r)
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// csharpDocComment collects contiguous `///` XML doc comments directly above
+// a declaration and returns their text without the XML tags.
+func csharpDocComment(node *sitter.Node, content []byte) string {
+	lines := make([]string, 0)
+	for sibling := node.PrevSibling(); sibling != nil; sibling = sibling.PrevSibling() {
+		if sibling.Type() == "attribute_list" {
+			continue
+		}
+		if sibling.Type() != "comment" {
+			break
+		}
+		text := strings.TrimSpace(sibling.Content(content))
+		if !strings.HasPrefix(text, "///") {
+			break
+		}
+		lines = append([]string{strings.TrimSpace(strings.TrimPrefix(text, "///"))}, lines...)
+	}
+
+	parts := make([]string, 0, len(lines))
+	for _, line := range lines {
+		var b strings.Builder
+		inTag := false
+		for _, r := range line {
+			switch {
+			case r == '<':
+				inTag = true
+			case r == '>':
+				inTag = false
+			case !inTag:
+				b.WriteRune(r)
+			}
+		}
+		if text := strings.TrimSpace(b.String()); text != "" {
+			parts = append(parts, text)
+		}
+	}
+	return strings.Join(parts, " ")
+}
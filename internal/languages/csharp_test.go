@@ -0,0 +1,177 @@
+package languages
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/morozRed/skelly/internal/parser"
+)
+
+func TestCSharpParserExtractsNamespacesTypesUsingsAndCalls(t *testing.T) {
+	parser := NewCSharpParser()
+	file, err := parser.Parse("src/Web/OrderController.cs", []byte(`using System;
+using Acme.Billing;
+using Pay = Acme.Payments.Gateway;
+using static System.Math;
+
+namespace Acme.Web.Controllers
+{
+    /// <summary>
+    /// Handles orders.
+    /// </summary>
+    public sealed class OrderController : Controller, IDisposable
+    {
+        public string Name { get; set; }
+
+        public OrderController(IService svc) : base(svc) { }
+
+        public async Task<int> Submit(Order order, int count = 1)
+        {
+            this.Validate(order);
+            Invoice.Create(order);
+            Acme.Billing.Invoice.Create(order);
+            var g = new Pay();
+            Helper<int>(count);
+            return Max(1, 2);
+        }
+    }
+
+    interface IRepo<T> { T Find(int id); }
+    struct Point { }
+    record Person(string Name);
+}
+`))
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	symbols := make(map[string]string)
+	for _, symbol := range file.Symbols {
+		symbols[symbol.Name+"/"+symbol.Kind.String()] = symbol.Signature
+	}
+	for key, want := range map[string]string{
+		"Acme.Web.Controllers/module": "namespace Acme.Web.Controllers",
+		"OrderController/class":       "public sealed class OrderController : Controller, IDisposable",
+		"Name/method":                 "public string Name { get; set; }",
+		"OrderController/method":      "public OrderController(IService svc)",
+		"Submit/method":               "public async Task<int> Submit(Order order, int count = 1)",
+		"IRepo/interface":             "interface IRepo<T>",
+		"Find/method":                 "T Find(int id)",
+		"Point/struct":                "struct Point",
+		"Person/class":                "record Person(string Name)",
+	} {
+		if got := symbols[key]; got != want {
+			t.Fatalf("expected %s signature %q, got %q (all: %#v)", key, want, got, symbols)
+		}
+	}
+	for _, symbol := range file.Symbols {
+		if symbol.Name == "OrderController" && symbol.Kind.String() == "class" && symbol.Doc != "Handles orders." {
+			t.Fatalf("expected XML doc summary on OrderController, got %q", symbol.Doc)
+		}
+	}
+
+	wantImports := []string{"System", "Acme/Billing", "Acme/Payments/Gateway", "System/Math"}
+	if len(file.Imports) != len(wantImports) {
+		t.Fatalf("unexpected imports: %#v", file.Imports)
+	}
+	for i := range wantImports {
+		if file.Imports[i] != wantImports[i] {
+			t.Fatalf("unexpected imports: %#v", file.Imports)
+		}
+	}
+	for alias, want := range map[string]string{
+		"Pay":  "Acme/Payments/Gateway",
+		"Math": "System/Math",
+	} {
+		if got := file.ImportAliases[alias]; got != want {
+			t.Fatalf("expected alias %s -> %q, got %q (all: %#v)", alias, want, got, file.ImportAliases)
+		}
+	}
+
+	var submitCalls []string
+	for _, symbol := range file.Symbols {
+		if symbol.Name == "Submit" {
+			for _, call := range symbol.Calls {
+				submitCalls = append(submitCalls, call.Qualifier+"|"+call.Name+"|"+call.Receiver)
+			}
+		}
+	}
+	want := []string{"this|Validate|this", "Invoice|Create|", "Acme.Billing.Invoice|Create|", "|Pay|", "|Helper|", "|Max|"}
+	if len(submitCalls) != len(want) {
+		t.Fatalf("unexpected calls in Submit: %#v", submitCalls)
+	}
+	for i := range want {
+		if submitCalls[i] != want[i] {
+			t.Fatalf("unexpected calls in Submit: %#v", submitCalls)
+		}
+	}
+}
+
+func TestCSharpParserHandlesFileScopedNamespaces(t *testing.T) {
+	file, err := NewCSharpParser().Parse("Billing/Invoice.cs", []byte(`namespace Acme.Billing;
+
+public static class Invoice
+{
+    public static Invoice Create(Order order) => Build(order);
+}
+`))
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	kinds := make(map[string]string)
+	for _, symbol := range file.Symbols {
+		kinds[symbol.Name] = symbol.Kind.String()
+		if symbol.Name == "Create" && (len(symbol.Calls) != 1 || symbol.Calls[0].Name != "Build") {
+			t.Fatalf("expected expression-bodied Create to call Build, got %#v", symbol.Calls)
+		}
+	}
+	if kinds["Acme.Billing"] != "module" || kinds["Invoice"] != "class" || kinds["Create"] != "method" {
+		t.Fatalf("unexpected symbols for file-scoped namespace: %#v", kinds)
+	}
+}
+
+func TestCSharpParserExtractsConstAndStaticFields(t *testing.T) {
+	file, err := NewCSharpParser().Parse("src/Config.cs", []byte(`namespace Acme;
+
+public class Config
+{
+    /// <summary>Attempts before giving up.</summary>
+    public const int MaxRetries = 3, MinRetries = 1;
+    private static readonly Dictionary<string, int> Cache = BuildCache();
+    static int counter;
+    private int count = 0;
+
+    public int Retries()
+    {
+        const int local = 2;
+        return local;
+    }
+}
+`))
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	got := make([]string, 0)
+	for _, symbol := range file.Symbols {
+		if symbol.Kind == parser.SymbolConstant || symbol.Kind == parser.SymbolVariable {
+			got = append(got, symbol.Kind.String()+" "+symbol.Signature+" "+symbol.Container)
+		}
+		if symbol.Name == "MaxRetries" && symbol.Doc != "Attempts before giving up." {
+			t.Fatalf("expected doc comment on MaxRetries, got %q", symbol.Doc)
+		}
+		if symbol.Name == "Cache" && (len(symbol.Calls) != 1 || symbol.Calls[0].Name != "BuildCache") {
+			t.Fatalf("expected Cache to call BuildCache, got %+v", symbol.Calls)
+		}
+	}
+	want := []string{
+		"const public const int MaxRetries = 3 Config",
+		"const public const int MinRetries = 1 Config",
+		"const private static readonly Dictionary<string, int> Cache = BuildCache() Config",
+		"var static int counter Config",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("unexpected fields:\n%s", strings.Join(got, "\n"))
+	}
+}
@@ -2,7 +2,10 @@ package languages
 
 import (
 	"context"
+	"go/build/constraint"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/morozRed/skelly/internal/parser"
 	sitter "github.com/smacker/go-tree-sitter"
@@ -11,6 +14,7 @@ import (
 
 // GoParser implements parsing for Go source files
 type GoParser struct {
+	extractions
 	parser *sitter.Parser
 }
 
@@ -30,6 +34,7 @@ func (g *GoParser) Extensions() []string {
 }
 
 func (g *GoParser) Parse(filename string, content []byte) (*parser.FileSymbols, error) {
+	g.begin("go")
 	tree, err := g.parser.ParseCtx(context.Background(), nil, content)
 	if err != nil {
 		return nil, err
@@ -45,21 +50,59 @@ func (g *GoParser) Parse(filename string, content []byte) (*parser.FileSymbols,
 	}
 
 	root := tree.RootNode()
-	g.extractSymbols(root, content, result)
+	result.BuildConstraint = goBuildConstraint(root, content)
+	resultTypes := goResultTypes(root, content)
+	g.extractSymbols(root, content, resultTypes, result)
+	result.Symbols = append(result.Symbols, g.extractRoutes(root, content, resultTypes, result.Imports)...)
 
 	return result, nil
 }
 
-func (g *GoParser) extractSymbols(node *sitter.Node, content []byte, result *parser.FileSymbols) {
+// goBuildConstraint returns the build constraint in the comments above the
+// package clause: the //go:build expression or, in older files, the
+// conjunction of the // +build lines.
+func goBuildConstraint(root *sitter.Node, content []byte) string {
+	var plusBuild constraint.Expr
+	for i := 0; i < int(root.NamedChildCount()); i++ {
+		node := root.NamedChild(i)
+		if node.Type() != "comment" {
+			break
+		}
+		for _, line := range strings.Split(node.Content(content), "\n") {
+			line = strings.TrimSpace(line)
+			if !constraint.IsGoBuild(line) && !constraint.IsPlusBuild(line) {
+				continue
+			}
+			expr, err := constraint.Parse(line)
+			if err != nil {
+				continue
+			}
+			if constraint.IsGoBuild(line) {
+				return expr.String()
+			}
+			if plusBuild == nil {
+				plusBuild = expr
+			} else {
+				plusBuild = &constraint.AndExpr{X: plusBuild, Y: expr}
+			}
+		}
+	}
+	if plusBuild == nil {
+		return ""
+	}
+	return plusBuild.String()
+}
+
+func (g *GoParser) extractSymbols(node *sitter.Node, content []byte, resultTypes map[string]string, result *parser.FileSymbols) {
 	switch node.Type() {
 	case "function_declaration":
-		sym := g.extractFunction(node, content)
+		sym := g.extractFunction(node, content, resultTypes)
 		if sym != nil {
 			result.Symbols = append(result.Symbols, *sym)
 		}
 
 	case "method_declaration":
-		sym := g.extractMethod(node, content)
+		sym := g.extractMethod(node, content, resultTypes)
 		if sym != nil {
 			result.Symbols = append(result.Symbols, *sym)
 		}
@@ -68,6 +111,12 @@ func (g *GoParser) extractSymbols(node *sitter.Node, content []byte, result *par
 		syms := g.extractTypeDecl(node, content)
 		result.Symbols = append(result.Symbols, syms...)
 
+	case "const_declaration", "var_declaration":
+		// Package-level only; locals inside functions are not symbols.
+		if parent := node.Parent(); parent != nil && parent.Type() == "source_file" {
+			result.Symbols = append(result.Symbols, g.extractValueDecl(node, content)...)
+		}
+
 	case "import_declaration":
 		imports, aliases := g.extractImports(node, content)
 		result.Imports = append(result.Imports, imports...)
@@ -77,11 +126,11 @@ func (g *GoParser) extractSymbols(node *sitter.Node, content []byte, result *par
 	// Recurse into children
 	for i := 0; i < int(node.ChildCount()); i++ {
 		child := node.Child(i)
-		g.extractSymbols(child, content, result)
+		g.extractSymbols(child, content, resultTypes, result)
 	}
 }
 
-func (g *GoParser) extractFunction(node *sitter.Node, content []byte) *parser.Symbol {
+func (g *GoParser) extractFunction(node *sitter.Node, content []byte, resultTypes map[string]string) *parser.Symbol {
 	nameNode := node.ChildByFieldName("name")
 	if nameNode == nil {
 		return nil
@@ -91,15 +140,20 @@ func (g *GoParser) extractFunction(node *sitter.Node, content []byte) *parser.Sy
 	sig := g.buildFunctionSignature(node, content)
 
 	return &parser.Symbol{
-		Name:      name,
-		Kind:      parser.SymbolFunction,
-		Signature: sig,
-		Line:      int(node.StartPoint().Row) + 1,
-		Calls:     g.extractCalls(node.ChildByFieldName("body"), content),
+		Name:       name,
+		Kind:       parser.SymbolFunction,
+		Signature:  sig,
+		Shape:      g.declarationShape(node, content),
+		Line:       int(node.StartPoint().Row) + 1,
+		Span:       symbolSpan(node),
+		Doc:        g.doc(goDocComment, node, content),
+		Visibility: goVisibility(name),
+		References: g.references(func() []string { return goFunctionReferences(node, content) }),
+		Calls:      g.functionCalls(node, content, resultTypes),
 	}
 }
 
-func (g *GoParser) extractMethod(node *sitter.Node, content []byte) *parser.Symbol {
+func (g *GoParser) extractMethod(node *sitter.Node, content []byte, resultTypes map[string]string) *parser.Symbol {
 	nameNode := node.ChildByFieldName("name")
 	if nameNode == nil {
 		return nil
@@ -117,12 +171,42 @@ func (g *GoParser) extractMethod(node *sitter.Node, content []byte) *parser.Symb
 	sig := g.buildFunctionSignature(node, content)
 
 	return &parser.Symbol{
-		Name:      name,
-		Kind:      parser.SymbolMethod,
-		Signature: receiver + " " + sig,
-		Line:      int(node.StartPoint().Row) + 1,
-		Calls:     g.extractCalls(node.ChildByFieldName("body"), content),
+		Name:       name,
+		Kind:       parser.SymbolMethod,
+		Signature:  receiver + " " + sig,
+		Shape:      g.declarationShape(node, content),
+		Line:       int(node.StartPoint().Row) + 1,
+		Span:       symbolSpan(node),
+		Doc:        g.doc(goDocComment, node, content),
+		Container:  goReceiverType(receiver),
+		Visibility: goVisibility(name),
+		References: g.references(func() []string { return goFunctionReferences(node, content) }),
+		Calls:      g.functionCalls(node, content, resultTypes),
+	}
+}
+
+// goVisibility reports a Go identifier as public when it is exported, that
+// is, when it starts with an upper-case letter.
+func goVisibility(name string) string {
+	r, _ := utf8.DecodeRuneInString(name)
+	if unicode.IsUpper(r) {
+		return parser.VisibilityPublic
+	}
+	return parser.VisibilityPrivate
+}
+
+// goReceiverType returns the type name of a method receiver such as
+// "(c *Client[T])".
+func goReceiverType(receiver string) string {
+	receiver = strings.Trim(receiver, "()")
+	if idx := strings.Index(receiver, "["); idx != -1 {
+		receiver = receiver[:idx]
 	}
+	fields := strings.Fields(receiver)
+	if len(fields) == 0 {
+		return ""
+	}
+	return strings.TrimLeft(fields[len(fields)-1], "*")
 }
 
 func (g *GoParser) extractTypeDecl(node *sitter.Node, content []byte) []parser.Symbol {
@@ -141,21 +225,129 @@ func (g *GoParser) extractTypeDecl(node *sitter.Node, content []byte) []parser.S
 
 			name := nameNode.Content(content)
 			kind := parser.SymbolStruct
+			var methods []string
+			var bases []parser.TypeRelation
 
 			if typeNode != nil {
 				switch typeNode.Type() {
 				case "struct_type":
 					kind = parser.SymbolStruct
+					bases = goEmbeddedFields(typeNode, content)
 				case "interface_type":
 					kind = parser.SymbolInterface
+					methods = goInterfaceMethods(typeNode, content)
+					bases = goEmbeddedInterfaces(typeNode, content)
 				}
 			}
 
+			// A lone `type X ...` carries its doc on the declaration;
+			// grouped specs carry it on the spec inside the parentheses.
+			// Its span likewise starts at the type keyword.
+			doc := g.doc(goDocComment, child, content)
+			if doc == "" {
+				doc = g.doc(goDocComment, node, content)
+			}
+			span := symbolSpan(child)
+			if node.NamedChildCount() == 1 {
+				span = symbolSpan(node)
+			}
+			symbols = append(symbols, parser.Symbol{
+				Name:       name,
+				Kind:       kind,
+				Signature:  g.buildTypeSignature(child, content),
+				Line:       int(child.StartPoint().Row) + 1,
+				Span:       span,
+				Doc:        doc,
+				Visibility: goVisibility(name),
+				Methods:    methods,
+				Bases:      bases,
+				References: g.references(func() []string { return goTypeReferences(nil, typeNode, content, goTypeParameters(child, content)) }),
+			})
+		}
+	}
+
+	return symbols
+}
+
+// extractValueDecl returns a constant or variable symbol for each name a
+// const or var declaration binds, skipping the blank identifier. Each calls
+// what its initializer calls.
+func (g *GoParser) extractValueDecl(node *sitter.Node, content []byte) []parser.Symbol {
+	keyword, kind := "var", parser.SymbolVariable
+	if node.Type() == "const_declaration" {
+		keyword, kind = "const", parser.SymbolConstant
+	}
+
+	specs := make([]*sitter.Node, 0)
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		child := node.NamedChild(i)
+		switch child.Type() {
+		case "const_spec", "var_spec":
+			specs = append(specs, child)
+		case "var_spec_list":
+			for j := 0; j < int(child.NamedChildCount()); j++ {
+				if spec := child.NamedChild(j); spec.Type() == "var_spec" {
+					specs = append(specs, spec)
+				}
+			}
+		}
+	}
+
+	symbols := make([]parser.Symbol, 0)
+	for _, spec := range specs {
+		typeNode := spec.ChildByFieldName("type")
+		valueNode := spec.ChildByFieldName("value")
+		var values []*sitter.Node
+		if valueNode != nil {
+			values = namedArguments(valueNode)
+		}
+		// As with types, a lone spec carries its doc and span on the
+		// declaration.
+		doc := g.doc(goDocComment, spec, content)
+		span := symbolSpan(spec)
+		if len(specs) == 1 {
+			if doc == "" {
+				doc = g.doc(goDocComment, node, content)
+			}
+			span = symbolSpan(node)
+		}
+
+		index := 0
+		for i := 0; i < int(spec.ChildCount()); i++ {
+			if spec.FieldNameForChild(i) != "name" {
+				continue
+			}
+			name := spec.Child(i).Content(content)
+			var value *sitter.Node
+			if index < len(values) {
+				value = values[index]
+			}
+			index++
+			if name == "_" {
+				continue
+			}
+
+			declaration := keyword + " " + name
+			if typeNode != nil {
+				declaration += " " + typeNode.Content(content)
+			}
+			signature := declaration
+			if value != nil {
+				signature = valueSignature(declaration, value.Content(content))
+			}
+			references := g.references(func() []string {
+				return goTypeReferences(goTypeReferences(nil, typeNode, content, nil), value, content, nil)
+			})
 			symbols = append(symbols, parser.Symbol{
-				Name:      name,
-				Kind:      kind,
-				Signature: g.buildTypeSignature(child, content),
-				Line:      int(child.StartPoint().Row) + 1,
+				Name:       name,
+				Kind:       kind,
+				Signature:  signature,
+				Line:       int(spec.StartPoint().Row) + 1,
+				Span:       span,
+				Doc:        doc,
+				Visibility: goVisibility(name),
+				References: references,
+				Calls:      g.extractCalls(value, content, nil),
 			})
 		}
 	}
@@ -163,6 +355,123 @@ func (g *GoParser) extractTypeDecl(node *sitter.Node, content []byte) []parser.S
 	return symbols
 }
 
+// goPredeclaredTypes are the builtin type names, which never resolve to a
+// repository type.
+var goPredeclaredTypes = map[string]bool{
+	"any": true, "bool": true, "byte": true, "comparable": true, "complex64": true,
+	"complex128": true, "error": true, "float32": true, "float64": true, "int": true,
+	"int8": true, "int16": true, "int32": true, "int64": true, "rune": true,
+	"string": true, "uint": true, "uint8": true, "uint16": true, "uint32": true,
+	"uint64": true, "uintptr": true,
+}
+
+// goFunctionReferences returns the named types used by a function or method
+// in its parameters, results and body (variable types, composite literals,
+// conversions and assertions). The receiver is left out; it is already the
+// method's container.
+func goFunctionReferences(node *sitter.Node, content []byte) []string {
+	typeParams := goTypeParameters(node, content)
+	var refs []string
+	refs = goTypeReferences(refs, node.ChildByFieldName("parameters"), content, typeParams)
+	refs = goTypeReferences(refs, node.ChildByFieldName("result"), content, typeParams)
+	refs = goTypeReferences(refs, node.ChildByFieldName("body"), content, typeParams)
+	return refs
+}
+
+// goTypeReferences appends the type names found under node: type
+// identifiers other than predeclared types and type parameters, and
+// package-qualified types ("csv.Writer").
+func goTypeReferences(refs []string, node *sitter.Node, content []byte, typeParams map[string]bool) []string {
+	if node == nil {
+		return refs
+	}
+	switch node.Type() {
+	case "type_identifier":
+		name := node.Content(content)
+		if !goPredeclaredTypes[name] && !typeParams[name] {
+			refs = appendReference(refs, name)
+		}
+		return refs
+	case "qualified_type":
+		return appendReference(refs, strings.Join(strings.Fields(node.Content(content)), ""))
+	}
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		refs = goTypeReferences(refs, node.NamedChild(i), content, typeParams)
+	}
+	return refs
+}
+
+// goTypeParameters returns the type parameter names a generic function or
+// type declares.
+func goTypeParameters(node *sitter.Node, content []byte) map[string]bool {
+	list := node.ChildByFieldName("type_parameters")
+	if list == nil {
+		return nil
+	}
+	names := make(map[string]bool)
+	for i := 0; i < int(list.NamedChildCount()); i++ {
+		for _, nameNode := range goFieldChildren(list.NamedChild(i), "name") {
+			names[nameNode.Content(content)] = true
+		}
+	}
+	return names
+}
+
+// goEmbeddedFields returns the types embedded in a struct as inherit
+// relations, without pointers or type arguments ("*Logger" -> "Logger",
+// "sync.Mutex" stays qualified).
+func goEmbeddedFields(structNode *sitter.Node, content []byte) []parser.TypeRelation {
+	var bases []parser.TypeRelation
+	for i := 0; i < int(structNode.NamedChildCount()); i++ {
+		list := structNode.NamedChild(i)
+		if list.Type() != "field_declaration_list" {
+			continue
+		}
+		for j := 0; j < int(list.NamedChildCount()); j++ {
+			field := list.NamedChild(j)
+			if field.Type() != "field_declaration" || field.ChildByFieldName("name") != nil {
+				continue
+			}
+			if name := goNamedType(field.ChildByFieldName("type"), content); name != "" {
+				bases = append(bases, parser.TypeRelation{Name: name, Relation: parser.RelationInherit})
+			}
+		}
+	}
+	return bases
+}
+
+// goEmbeddedInterfaces returns the interfaces an interface type embeds;
+// constraint unions such as ~int | ~string are skipped.
+func goEmbeddedInterfaces(typeNode *sitter.Node, content []byte) []parser.TypeRelation {
+	var bases []parser.TypeRelation
+	for i := 0; i < int(typeNode.NamedChildCount()); i++ {
+		elem := typeNode.NamedChild(i)
+		if (elem.Type() != "type_elem" && elem.Type() != "constraint_elem") || elem.NamedChildCount() != 1 {
+			continue
+		}
+		if name := goNamedType(elem.NamedChild(0), content); name != "" {
+			bases = append(bases, parser.TypeRelation{Name: name, Relation: parser.RelationInherit})
+		}
+	}
+	return bases
+}
+
+// goInterfaceMethods returns the names of the methods an interface type
+// declares itself; embedded interfaces and type constraints are skipped.
+func goInterfaceMethods(typeNode *sitter.Node, content []byte) []string {
+	var methods []string
+	for i := 0; i < int(typeNode.NamedChildCount()); i++ {
+		elem := typeNode.NamedChild(i)
+		if elem.Type() != "method_elem" && elem.Type() != "method_spec" {
+			continue
+		}
+		if nameNode := elem.ChildByFieldName("name"); nameNode != nil {
+			methods = append(methods, nameNode.Content(content))
+		}
+	}
+	return methods
+}
+
 func (g *GoParser) extractImports(node *sitter.Node, content []byte) ([]string, map[string]string) {
 	imports := make([]string, 0)
 	aliases := make(map[string]string)
@@ -208,6 +517,9 @@ func (g *GoParser) buildFunctionSignature(node *sitter.Node, content []byte) str
 	if nameNode != nil {
 		sig += " " + nameNode.Content(content)
 	}
+	if typeParams := node.ChildByFieldName("type_parameters"); typeParams != nil {
+		sig += typeParams.Content(content)
+	}
 	if paramsNode != nil {
 		sig += paramsNode.Content(content)
 	}
@@ -227,6 +539,9 @@ func (g *GoParser) buildTypeSignature(node *sitter.Node, content []byte) string
 	}
 
 	sig := "type " + nameNode.Content(content)
+	if typeParams := node.ChildByFieldName("type_parameters"); typeParams != nil {
+		sig += typeParams.Content(content)
+	}
 	if typeNode != nil {
 		switch typeNode.Type() {
 		case "struct_type":
@@ -241,30 +556,36 @@ func (g *GoParser) buildTypeSignature(node *sitter.Node, content []byte) string
 	return sig
 }
 
-func (g *GoParser) extractCalls(bodyNode *sitter.Node, content []byte) []parser.CallSite {
+func (g *GoParser) extractCalls(bodyNode *sitter.Node, content []byte, localTypes map[string]string) []parser.CallSite {
+	if g.skip.SkipCalls {
+		return nil
+	}
 	if bodyNode == nil {
 		return nil
 	}
 
 	calls := make([]parser.CallSite, 0)
-	g.collectCalls(bodyNode, content, &calls)
+	g.collectCalls(bodyNode, content, localTypes, &calls)
 	return calls
 }
 
-func (g *GoParser) collectCalls(node *sitter.Node, content []byte, calls *[]parser.CallSite) {
+func (g *GoParser) collectCalls(node *sitter.Node, content []byte, localTypes map[string]string, calls *[]parser.CallSite) {
 	if node == nil {
 		return
 	}
 
 	if node.Type() == "call_expression" {
 		callSite := g.extractCallSite(node, content)
+		if callSite.Receiver != "" {
+			callSite.ReceiverType = localTypes[callSite.Receiver]
+		}
 		if callSite.Name != "" {
 			*calls = append(*calls, callSite)
 		}
 	}
 
 	for i := 0; i < int(node.ChildCount()); i++ {
-		g.collectCalls(node.Child(i), content, calls)
+		g.collectCalls(node.Child(i), content, localTypes, calls)
 	}
 }
 
@@ -321,6 +642,187 @@ func (g *GoParser) extractCallName(node *sitter.Node, content []byte) (name, qua
 	return strings.TrimSpace(node.Content(content)), ""
 }
 
+// goResultTypes maps each top-level function in the file to the named type
+// of its first result, so `w := NewWriter(f)` types w as Writer.
+func goResultTypes(root *sitter.Node, content []byte) map[string]string {
+	types := make(map[string]string)
+	for i := 0; i < int(root.NamedChildCount()); i++ {
+		decl := root.NamedChild(i)
+		if decl.Type() != "function_declaration" {
+			continue
+		}
+		nameNode := decl.ChildByFieldName("name")
+		resultNode := decl.ChildByFieldName("result")
+		if nameNode == nil || resultNode == nil {
+			continue
+		}
+		if resultNode.Type() == "parameter_list" {
+			if resultNode.NamedChildCount() == 0 {
+				continue
+			}
+			resultNode = resultNode.NamedChild(0).ChildByFieldName("type")
+		}
+		if typeName := goNamedType(resultNode, content); typeName != "" {
+			types[nameNode.Content(content)] = typeName
+		}
+	}
+	return types
+}
+
+// goLocalTypes maps the receiver, parameters and local variables of a
+// function to their declared named types. Names declared with conflicting
+// types (shadowing, reuse) are left untyped.
+// functionCalls returns the calls in a function or method body, typing
+// receivers by the declaration's parameters and locals.
+func (g *GoParser) functionCalls(node *sitter.Node, content []byte, resultTypes map[string]string) []parser.CallSite {
+	if g.skip.SkipCalls {
+		return nil
+	}
+	return g.extractCalls(node.ChildByFieldName("body"), content, goLocalTypes(node, content, resultTypes))
+}
+
+func goLocalTypes(node *sitter.Node, content []byte, resultTypes map[string]string) map[string]string {
+	types := make(map[string]string)
+	declare := func(nameNode *sitter.Node, typeName string) {
+		if nameNode == nil || nameNode.Type() != "identifier" {
+			return
+		}
+		name := nameNode.Content(content)
+		if existing, ok := types[name]; ok && existing != typeName {
+			typeName = ""
+		}
+		types[name] = typeName
+	}
+
+	var walk func(current *sitter.Node)
+	walk = func(current *sitter.Node) {
+		if current == nil {
+			return
+		}
+		switch current.Type() {
+		case "parameter_declaration", "variadic_parameter_declaration":
+			typeName := ""
+			if current.Type() == "parameter_declaration" {
+				typeName = goNamedType(current.ChildByFieldName("type"), content)
+			}
+			for _, nameNode := range goFieldChildren(current, "name") {
+				declare(nameNode, typeName)
+			}
+		case "var_spec":
+			names := goFieldChildren(current, "name")
+			if typeNode := current.ChildByFieldName("type"); typeNode != nil {
+				typeName := goNamedType(typeNode, content)
+				for _, nameNode := range names {
+					declare(nameNode, typeName)
+				}
+			} else {
+				goDeclareValues(names, current.ChildByFieldName("value"), content, resultTypes, declare)
+			}
+		case "short_var_declaration":
+			left := current.ChildByFieldName("left")
+			if left != nil {
+				names := make([]*sitter.Node, 0, left.NamedChildCount())
+				for i := 0; i < int(left.NamedChildCount()); i++ {
+					names = append(names, left.NamedChild(i))
+				}
+				goDeclareValues(names, current.ChildByFieldName("right"), content, resultTypes, declare)
+			}
+		}
+		for i := 0; i < int(current.NamedChildCount()); i++ {
+			walk(current.NamedChild(i))
+		}
+	}
+	walk(node.ChildByFieldName("receiver"))
+	walk(node.ChildByFieldName("parameters"))
+	walk(node.ChildByFieldName("body"))
+	return types
+}
+
+// goDeclareValues types names from the expressions assigned to them. A
+// single call on the right (`w, err := NewWriter(f)`) types the first name.
+func goDeclareValues(names []*sitter.Node, values *sitter.Node, content []byte, resultTypes map[string]string, declare func(*sitter.Node, string)) {
+	if values == nil || len(names) == 0 {
+		return
+	}
+	if int(values.NamedChildCount()) == 1 && len(names) > 1 {
+		declare(names[0], goExprType(values.NamedChild(0), content, resultTypes))
+		return
+	}
+	for i := 0; i < len(names) && i < int(values.NamedChildCount()); i++ {
+		declare(names[i], goExprType(values.NamedChild(i), content, resultTypes))
+	}
+}
+
+// goExprType returns the named type an expression evaluates to when that is
+// evident without type checking: T{...}, &T{...}, new(T) and calls to
+// functions of this file.
+func goExprType(expr *sitter.Node, content []byte, resultTypes map[string]string) string {
+	if expr == nil {
+		return ""
+	}
+	switch expr.Type() {
+	case "composite_literal":
+		return goNamedType(expr.ChildByFieldName("type"), content)
+	case "unary_expression":
+		if operator := expr.ChildByFieldName("operator"); operator != nil && operator.Content(content) == "&" {
+			return goExprType(expr.ChildByFieldName("operand"), content, resultTypes)
+		}
+	case "parenthesized_expression":
+		if expr.NamedChildCount() == 1 {
+			return goExprType(expr.NamedChild(0), content, resultTypes)
+		}
+	case "call_expression":
+		fn := expr.ChildByFieldName("function")
+		if fn == nil || fn.Type() != "identifier" {
+			return ""
+		}
+		name := fn.Content(content)
+		if name == "new" {
+			args := expr.ChildByFieldName("arguments")
+			if args != nil && args.NamedChildCount() == 1 {
+				return goNamedType(args.NamedChild(0), content)
+			}
+			return ""
+		}
+		return resultTypes[name]
+	}
+	return ""
+}
+
+// goNamedType returns the name of a declared type with pointers and type
+// arguments stripped ("*Store[T]" -> "Store", "*csv.Writer" -> "csv.Writer"),
+// or "" for unnamed types such as slices, maps and funcs.
+func goNamedType(typeNode *sitter.Node, content []byte) string {
+	if typeNode == nil {
+		return ""
+	}
+	switch typeNode.Type() {
+	case "type_identifier", "identifier":
+		return typeNode.Content(content)
+	case "qualified_type":
+		return strings.Join(strings.Fields(typeNode.Content(content)), "")
+	case "pointer_type", "parenthesized_type":
+		if typeNode.NamedChildCount() == 1 {
+			return goNamedType(typeNode.NamedChild(0), content)
+		}
+	case "generic_type":
+		return goNamedType(typeNode.ChildByFieldName("type"), content)
+	}
+	return ""
+}
+
+// goFieldChildren returns every child stored under field, which tree-sitter
+// repeats for declarations such as `a, b int`.
+func goFieldChildren(node *sitter.Node, field string) []*sitter.Node {
+	children := make([]*sitter.Node, 0, 1)
+	for i := 0; i < int(node.ChildCount()); i++ {
+		if node.FieldNameForChild(i) == field {
+			children = append(children, node.Child(i))
+		}
+	}
+	return children
+}
+
 func (g *GoParser) countCallArguments(argsNode *sitter.Node) int {
 	if argsNode == nil {
 		return 0
@@ -359,3 +861,94 @@ func (g *GoParser) readImportSpec(spec *sitter.Node, content []byte) (importPath
 	}
 	return importPath, alias
 }
+
+// declarationShape returns the type-only form of a function or method
+// declaration, e.g. "func (s *Server) Handle(w http.ResponseWriter) error"
+// becomes "func (*Server) Handle(http.ResponseWriter) error"; it is "" when
+// the declaration has no name.
+func (g *GoParser) declarationShape(decl *sitter.Node, content []byte) string {
+	nameNode := decl.ChildByFieldName("name")
+	if nameNode == nil {
+		return ""
+	}
+
+	shape := "func "
+	if receiver := decl.ChildByFieldName("receiver"); receiver != nil {
+		shape += "(" + strings.Join(g.parameterTypes(receiver, content), ", ") + ") "
+	}
+	shape += nameNode.Content(content)
+	if typeParams := decl.ChildByFieldName("type_parameters"); typeParams != nil {
+		shape += strings.Join(strings.Fields(typeParams.Content(content)), " ")
+	}
+	shape += "(" + strings.Join(g.parameterTypes(decl.ChildByFieldName("parameters"), content), ", ") + ")"
+
+	if result := decl.ChildByFieldName("result"); result != nil {
+		if result.Type() == "parameter_list" {
+			types := g.parameterTypes(result, content)
+			if len(types) == 1 {
+				shape += " " + types[0]
+			} else {
+				shape += " (" + strings.Join(types, ", ") + ")"
+			}
+		} else {
+			shape += " " + result.Content(content)
+		}
+	}
+	return shape
+}
+
+// parameterTypes expands a parameter list into one type per declared name.
+func (g *GoParser) parameterTypes(list *sitter.Node, content []byte) []string {
+	types := make([]string, 0)
+	if list == nil {
+		return types
+	}
+
+	for i := 0; i < int(list.NamedChildCount()); i++ {
+		param := list.NamedChild(i)
+		typeNode := param.ChildByFieldName("type")
+		if typeNode == nil {
+			continue
+		}
+		typeText := typeNode.Content(content)
+		if param.Type() == "variadic_parameter_declaration" {
+			typeText = "..." + typeText
+		}
+
+		names := 0
+		for j := 0; j < int(param.ChildCount()); j++ {
+			if param.FieldNameForChild(j) == "name" {
+				names++
+			}
+		}
+		if names == 0 {
+			names = 1
+		}
+		for ; names > 0; names-- {
+			types = append(types, typeText)
+		}
+	}
+	return types
+}
+
+// goDocComment collects the `//` comment lines directly above a declaration,
+// stopping at a blank line as go/doc does.
+func goDocComment(node *sitter.Node, content []byte) string {
+	lines := make([]string, 0)
+	nextRow := node.StartPoint().Row
+	for sibling := node.PrevSibling(); sibling != nil; sibling = sibling.PrevSibling() {
+		if sibling.Type() != "comment" || sibling.EndPoint().Row+1 != nextRow {
+			break
+		}
+		text := strings.TrimSpace(sibling.Content(content))
+		if !strings.HasPrefix(text, "//") {
+			break
+		}
+		nextRow = sibling.StartPoint().Row
+		if strings.HasPrefix(text, "//go:") {
+			continue // compiler directives are not part of the doc
+		}
+		lines = append([]string{strings.TrimSpace(strings.TrimPrefix(text, "//"))}, lines...)
+	}
+	return strings.Join(strings.Fields(strings.Join(lines, " ")), " ")
+}
@@ -0,0 +1,264 @@
+package languages
+
+import (
+	"strings"
+
+	"github.com/morozRed/skelly/internal/parser"
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// goRouteVerbs maps router methods named after an HTTP verb to the verb:
+// gin and echo use GET, chi and fiber use Get.
+var goRouteVerbs = map[string]string{
+	"GET": "GET", "POST": "POST", "PUT": "PUT", "PATCH": "PATCH", "DELETE": "DELETE",
+	"HEAD": "HEAD", "OPTIONS": "OPTIONS", "Any": "ANY",
+	"Get": "GET", "Post": "POST", "Put": "PUT", "Patch": "PATCH", "Delete": "DELETE",
+	"Head": "HEAD", "Options": "OPTIONS", "Connect": "CONNECT", "Trace": "TRACE", "All": "ANY",
+}
+
+// goRouter describes the routing packages a file imports.
+type goRouter struct {
+	// echo routers take the handler right after the path and middleware
+	// after it; gin takes middleware first and the handler last.
+	echo bool
+	// mixedCase enables chi and fiber's Get/Post methods, which are too
+	// common a name to treat as routes in files that import neither.
+	mixedCase bool
+}
+
+// extractRoutes returns the route symbols registered in the file's functions:
+// net/http ServeMux patterns (HandleFunc("GET /users/{id}", h), with gorilla's
+// .Methods(...)), gin, echo, chi and fiber verb methods, and the prefixes of
+// gin/echo Group and chi Route blocks. Handler method values (s.getUser) are
+// typed through the enclosing function's locals.
+func (g *GoParser) extractRoutes(root *sitter.Node, content []byte, resultTypes map[string]string, imports []string) []parser.Symbol {
+	router := goRouter{}
+	for _, imp := range imports {
+		switch {
+		case strings.HasPrefix(imp, "github.com/labstack/echo"):
+			router.echo = true
+		case strings.HasPrefix(imp, "github.com/go-chi/chi"), strings.HasPrefix(imp, "github.com/gofiber/fiber"):
+			router.mixedCase = true
+		}
+	}
+
+	var routes []parser.Symbol
+	for i := 0; i < int(root.NamedChildCount()); i++ {
+		decl := root.NamedChild(i)
+		if decl.Type() != "function_declaration" && decl.Type() != "method_declaration" {
+			continue
+		}
+		var found []goRoute
+		router.collect(decl.ChildByFieldName("body"), content, make(map[string]string), &found)
+		if len(found) == 0 {
+			continue
+		}
+		localTypes := goLocalTypes(decl, content, resultTypes)
+		for _, route := range found {
+			var handler *parser.CallSite
+			if route.handler != nil {
+				handler = g.routeHandler(route.handler, content, localTypes)
+			}
+			for _, method := range route.methods {
+				routes = append(routes, routeSymbol(method, route.path, route.call, content, handler))
+			}
+		}
+	}
+	return routes
+}
+
+type goRoute struct {
+	call    *sitter.Node
+	methods []string
+	path    string
+	handler *sitter.Node
+}
+
+// collect walks a function body in order, tracking the path prefix of each
+// router group variable.
+func (r goRouter) collect(node *sitter.Node, content []byte, prefixes map[string]string, routes *[]goRoute) {
+	if node == nil {
+		return
+	}
+	switch node.Type() {
+	case "short_var_declaration", "assignment_statement":
+		left, right := node.ChildByFieldName("left"), node.ChildByFieldName("right")
+		if left != nil && right != nil && left.NamedChildCount() == 1 && right.NamedChildCount() == 1 {
+			if prefix, ok := goGroupPrefix(right.NamedChild(0), content, prefixes); ok {
+				prefixes[left.NamedChild(0).Content(content)] = prefix
+			}
+		}
+
+	case "call_expression":
+		if r.collectCall(node, content, prefixes, routes) {
+			return
+		}
+	}
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		r.collect(node.NamedChild(i), content, prefixes, routes)
+	}
+}
+
+// collectCall records a route registration, or walks a chi Route/Group block
+// with its router parameter prefixed, and reports whether it handled the
+// call's children itself.
+func (r goRouter) collectCall(call *sitter.Node, content []byte, prefixes map[string]string, routes *[]goRoute) bool {
+	fn := call.ChildByFieldName("function")
+	if fn == nil || fn.Type() != "selector_expression" {
+		return false
+	}
+	operand, field := fn.ChildByFieldName("operand"), fn.ChildByFieldName("field")
+	if operand == nil || field == nil {
+		return false
+	}
+	receiver := operand.Content(content)
+	method := field.Content(content)
+	args := namedArguments(call.ChildByFieldName("arguments"))
+
+	switch method {
+	case "Route", "Group":
+		// chi: r.Route("/admin", func(r chi.Router) { ... }) and
+		// r.Group(func(r chi.Router) { ... }).
+		prefix := prefixes[receiver]
+		if method == "Route" {
+			if len(args) != 2 {
+				return false
+			}
+			path, ok := stringLiteral(args[0], content)
+			if !ok {
+				return false
+			}
+			prefix = prefixRoutePath(prefix, path)
+		}
+		if len(args) == 0 || args[len(args)-1].Type() != "func_literal" {
+			return false
+		}
+		block := args[len(args)-1]
+		nested := make(map[string]string, len(prefixes)+1)
+		for name, value := range prefixes {
+			nested[name] = value
+		}
+		if params := block.ChildByFieldName("parameters"); params != nil && params.NamedChildCount() == 1 {
+			for _, name := range goFieldChildren(params.NamedChild(0), "name") {
+				nested[name.Content(content)] = prefix
+			}
+		}
+		r.collect(block.ChildByFieldName("body"), content, nested, routes)
+		return true
+
+	case "HandleFunc", "Handle":
+		if len(args) != 2 {
+			return false
+		}
+		pattern, ok := stringLiteral(args[0], content)
+		if !ok || !strings.Contains(pattern, "/") {
+			return false
+		}
+		methods := []string{"ANY"}
+		if verb, path, ok := strings.Cut(pattern, " "); ok && verb == strings.ToUpper(verb) {
+			methods, pattern = []string{verb}, strings.TrimSpace(path)
+		} else if chained := goChainedMethods(call, content); len(chained) > 0 {
+			methods = chained
+		}
+		*routes = append(*routes, goRoute{
+			call:    call,
+			methods: methods,
+			path:    prefixRoutePath(prefixes[receiver], pattern),
+			handler: args[1],
+		})
+		return false
+	}
+
+	verb, ok := goRouteVerbs[method]
+	if !ok || len(args) < 2 || (method != strings.ToUpper(method) && method != "Any" && !r.mixedCase) {
+		return false
+	}
+	path, ok := stringLiteral(args[0], content)
+	if !ok || !strings.HasPrefix(path, "/") {
+		return false
+	}
+	handler := args[len(args)-1]
+	if r.echo {
+		handler = args[1]
+	}
+	*routes = append(*routes, goRoute{
+		call:    call,
+		methods: []string{verb},
+		path:    prefixRoutePath(prefixes[receiver], path),
+		handler: handler,
+	})
+	return false
+}
+
+// goGroupPrefix returns the prefix of `v1 := r.Group("/v1")` (gin, echo).
+func goGroupPrefix(value *sitter.Node, content []byte, prefixes map[string]string) (string, bool) {
+	if value.Type() != "call_expression" {
+		return "", false
+	}
+	fn := value.ChildByFieldName("function")
+	if fn == nil || fn.Type() != "selector_expression" {
+		return "", false
+	}
+	operand, field := fn.ChildByFieldName("operand"), fn.ChildByFieldName("field")
+	if operand == nil || field == nil || field.Content(content) != "Group" {
+		return "", false
+	}
+	args := namedArguments(value.ChildByFieldName("arguments"))
+	if len(args) == 0 {
+		return "", false
+	}
+	path, ok := stringLiteral(args[0], content)
+	if !ok {
+		return "", false
+	}
+	return prefixRoutePath(prefixes[operand.Content(content)], path), true
+}
+
+// goChainedMethods returns the verbs of gorilla/mux's
+// r.HandleFunc("/users", h).Methods("GET", "POST").
+func goChainedMethods(call *sitter.Node, content []byte) []string {
+	selector := call.Parent()
+	if selector == nil || selector.Type() != "selector_expression" {
+		return nil
+	}
+	field := selector.ChildByFieldName("field")
+	chained := selector.Parent()
+	if field == nil || field.Content(content) != "Methods" || chained == nil || chained.Type() != "call_expression" {
+		return nil
+	}
+	var methods []string
+	for _, arg := range namedArguments(chained.ChildByFieldName("arguments")) {
+		if method, ok := stringLiteral(arg, content); ok {
+			methods = append(methods, strings.ToUpper(method))
+		}
+	}
+	return methods
+}
+
+// routeHandler returns the call to a named handler (getUser, h.getUser,
+// handlers.GetUser), unwrapping http.HandlerFunc conversions, or nil for
+// function literals and handlers built by other calls.
+func (g *GoParser) routeHandler(node *sitter.Node, content []byte, localTypes map[string]string) *parser.CallSite {
+	if node.Type() == "call_expression" {
+		fn := node.ChildByFieldName("function")
+		args := namedArguments(node.ChildByFieldName("arguments"))
+		if fn == nil || len(args) != 1 || !strings.HasSuffix(fn.Content(content), "HandlerFunc") {
+			return nil
+		}
+		node = args[0]
+	}
+	if node.Type() != "identifier" && node.Type() != "selector_expression" {
+		return nil
+	}
+	name, qualifier := g.extractCallName(node, content)
+	handler := &parser.CallSite{
+		Name:      name,
+		Qualifier: qualifier,
+		Raw:       strings.TrimSpace(node.Content(content)),
+	}
+	if qualifier != "" {
+		handler.Receiver = qualifier
+		handler.ReceiverType = localTypes[qualifier]
+	}
+	return handler
+}
@@ -0,0 +1,473 @@
+package languages
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/morozRed/skelly/internal/parser"
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+func TestGoParserRecordsSignatureShapes(t *testing.T) {
+	file, err := NewGoParser().Parse("demo.go", []byte(`package demo
+
+func (s *Server) HandleUser(w http.ResponseWriter, r *http.Request) {}
+
+func Load(a, b string, opts ...Option) (cfg *Config, err error) { return nil, nil }
+
+func Close() error { return nil }
+
+type Config struct{}
+`))
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	want := map[string]string{
+		"HandleUser": "func (*Server) HandleUser(http.ResponseWriter, *http.Request)",
+		"Load":       "func Load(string, string, ...Option) (*Config, error)",
+		"Close":      "func Close() error",
+		"Config":     "",
+	}
+	for _, symbol := range file.Symbols {
+		if expected, ok := want[symbol.Name]; ok && symbol.Shape != expected {
+			t.Fatalf("expected shape %q for %s, got %q", expected, symbol.Name, symbol.Shape)
+		}
+	}
+}
+
+func TestGoParserSkipsTurnedOffExtractions(t *testing.T) {
+	source := []byte(`package demo
+
+// Run starts the app.
+func Run(cfg Config) { start() }
+
+func start() {}
+
+type Config struct{}
+`)
+	goParser := NewGoParser()
+	goParser.SetFeatures(map[string]parser.Features{"go": {SkipCalls: true, SkipDocs: true, SkipReferences: true}})
+	file, err := goParser.Parse("demo.go", source)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	for _, symbol := range file.Symbols {
+		if symbol.Doc != "" || len(symbol.Calls) != 0 || len(symbol.References) != 0 {
+			t.Fatalf("expected extractions to be skipped, got %#v", symbol)
+		}
+	}
+
+	goParser.SetFeatures(nil)
+	file, err = goParser.Parse("demo.go", source)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	if run := file.Symbols[0]; run.Doc == "" || len(run.Calls) != 1 || len(run.References) != 1 {
+		t.Fatalf("expected extractions back once features are cleared, got %#v", run)
+	}
+
+	// Turned-off extractors are not run at all.
+	skipping := extractions{skip: parser.Features{SkipDocs: true, SkipReferences: true}}
+	skipping.doc(func(*sitter.Node, []byte) string {
+		t.Fatalf("doc extractor ran with docs off")
+		return ""
+	}, nil, nil)
+	skipping.references(func() []string {
+		t.Fatalf("reference extractor ran with references off")
+		return nil
+	})
+}
+
+func TestGoParserExtractsDocComments(t *testing.T) {
+	file, err := NewGoParser().Parse("demo.go", []byte(`package demo
+
+// Load reads the config.
+// It never touches the network.
+//
+//go:noinline
+func Load() {}
+
+// Detached comment.
+
+func Bare() {}
+
+// Server handles requests.
+type Server struct{}
+
+type (
+	// ID names a record.
+	ID string
+)
+
+// Close stops the server.
+func (s *Server) Close() {}
+`))
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	want := map[string]string{
+		"Load":   "Load reads the config. It never touches the network.",
+		"Bare":   "",
+		"Server": "Server handles requests.",
+		"ID":     "ID names a record.",
+		"Close":  "Close stops the server.",
+	}
+	for _, symbol := range file.Symbols {
+		if doc, ok := want[symbol.Name]; ok && symbol.Doc != doc {
+			t.Fatalf("expected doc %q for %s, got %q", doc, symbol.Name, symbol.Doc)
+		}
+	}
+}
+
+func TestGoParserRecordsReceiverContainers(t *testing.T) {
+	file, err := NewGoParser().Parse("store.go", []byte(`package store
+
+type Store[T any] struct{}
+
+func (s *Store[T]) Save() {}
+
+func (Store[T]) Load() {}
+
+func Save() {}
+`))
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	var qualified []string
+	for _, symbol := range file.Symbols {
+		qualified = append(qualified, symbol.QualifiedName())
+	}
+	want := []string{"Store", "Store.Save", "Store.Load", "Save"}
+	if len(qualified) != len(want) {
+		t.Fatalf("expected %v, got %v", want, qualified)
+	}
+	for i := range want {
+		if qualified[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, qualified)
+		}
+	}
+}
+
+func TestGoParserInfersReceiverTypesFromDeclarations(t *testing.T) {
+	file, err := NewGoParser().Parse("export.go", []byte(`package export
+
+import "encoding/csv"
+
+func NewWriter() (*Writer, error) { return nil, nil }
+
+func (e *Exporter) Run(out *csv.Writer, rows [][]string) {
+	e.flush()
+	out.WriteAll(rows)
+	w, _ := NewWriter()
+	w.WriteAll(rows)
+	var buf Buffer
+	buf.Reset()
+	s := &Store[int]{}
+	s.Save()
+	p := new(Pool)
+	p.Get()
+	x := rows
+	x.Len()
+	if true {
+		x := Other{}
+		x.Len()
+	}
+}
+`))
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	var run *parser.Symbol
+	for i := range file.Symbols {
+		if file.Symbols[i].Name == "Run" {
+			run = &file.Symbols[i]
+		}
+	}
+	if run == nil {
+		t.Fatalf("expected Run method, got %#v", file.Symbols)
+	}
+
+	want := []string{"Exporter", "csv.Writer", "Writer", "Buffer", "Store", "Pool", "", ""}
+	calls := make([]parser.CallSite, 0, len(run.Calls))
+	for _, call := range run.Calls {
+		if call.Receiver != "" {
+			calls = append(calls, call)
+		}
+	}
+	if len(calls) != len(want) {
+		t.Fatalf("expected %d method calls, got %#v", len(want), calls)
+	}
+	for i, call := range calls {
+		if call.ReceiverType != want[i] {
+			t.Fatalf("expected %s.%s receiver type %q, got %q", call.Receiver, call.Name, want[i], call.ReceiverType)
+		}
+	}
+}
+
+func TestGoParserRecordsTypeReferences(t *testing.T) {
+	file, err := NewGoParser().Parse("store.go", []byte(`package store
+
+type Order struct {
+	Owner *User
+	Items []Item
+	When  time.Time
+	Count int
+}
+
+func Save[T any](u *User, item T) (*Order, error) {
+	var r Receipt
+	_ = r
+	return &Order{}, nil
+}
+
+func (s *Store) Load() error { return nil }
+`))
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	want := map[string][]string{
+		"Order": {"User", "Item", "time.Time"},
+		"Save":  {"User", "Order", "Receipt"},
+		"Load":  nil,
+	}
+	for _, symbol := range file.Symbols {
+		refs, ok := want[symbol.Name]
+		if !ok {
+			continue
+		}
+		if !slices.Equal(symbol.References, refs) {
+			t.Fatalf("expected %s to reference %v, got %v", symbol.Name, refs, symbol.References)
+		}
+	}
+}
+
+func TestGoParserRecordsTypeParametersAndEmbeddedTypes(t *testing.T) {
+	file, err := NewGoParser().Parse("store.go", []byte(`package store
+
+type Store[K comparable, V any] struct {
+	*Logger
+	sync.Mutex
+	Base[K]
+	items map[K]V
+}
+
+type ReadCloser interface {
+	io.Reader
+	Closer
+	~int | ~string
+	Close() error
+}
+
+func Map[T, U any](items []T, fn func(T) U) []U { return nil }
+`))
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	symbols := make(map[string]parser.Symbol)
+	for _, symbol := range file.Symbols {
+		symbols[symbol.Name] = symbol
+	}
+	if got := symbols["Store"].Signature; got != "type Store[K comparable, V any] struct" {
+		t.Fatalf("unexpected Store signature %q", got)
+	}
+	if got := symbols["Map"].Signature; got != "func Map[T, U any](items []T, fn func(T) U) []U" {
+		t.Fatalf("unexpected Map signature %q", got)
+	}
+
+	names := func(bases []parser.TypeRelation) []string {
+		out := make([]string, 0, len(bases))
+		for _, base := range bases {
+			out = append(out, base.Name+":"+base.Relation)
+		}
+		return out
+	}
+	if got := names(symbols["Store"].Bases); !slices.Equal(got, []string{"Logger:inherit", "sync.Mutex:inherit", "Base:inherit"}) {
+		t.Fatalf("unexpected embedded fields %v", got)
+	}
+	if got := names(symbols["ReadCloser"].Bases); !slices.Equal(got, []string{"io.Reader:inherit", "Closer:inherit"}) {
+		t.Fatalf("unexpected embedded interfaces %v", got)
+	}
+
+	if got := symbols["Map"].Shape; got != "func Map[T, U any]([]T, func(T) U) []U" {
+		t.Fatalf("unexpected Map shape %q", got)
+	}
+}
+
+func TestGoParserRecordsBuildConstraints(t *testing.T) {
+	cases := map[string]string{
+		"//go:build linux && !cgo\n\npackage sys\n":                     "linux && !cgo",
+		"// +build linux darwin\n// +build amd64\n\npackage sys\n":      "(linux || darwin) && amd64",
+		"//go:build ignore\n// +build ignore\n\npackage main\n":         "ignore",
+		"// Package sys wraps syscalls.\npackage sys\n\n//go:build x\n": "",
+	}
+	for content, want := range cases {
+		file, err := NewGoParser().Parse("sys.go", []byte(content))
+		if err != nil {
+			t.Fatalf("parse failed: %v", err)
+		}
+		if file.BuildConstraint != want {
+			t.Errorf("expected build constraint %q for %q, got %q", want, content, file.BuildConstraint)
+		}
+	}
+}
+
+func TestGoParserExtractsHTTPRoutes(t *testing.T) {
+	file, err := NewGoParser().Parse("server.go", []byte(`package server
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/mux"
+	"example.com/app/handlers"
+)
+
+func (s *Server) routes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /users/{id}", s.getUser)
+	mux.Handle("/static/", http.StripPrefix("/static/", files))
+	http.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {})
+}
+
+func ginRoutes(r *gin.Engine, m *mux.Router) {
+	api := r.Group("/api")
+	v1 := api.Group("/v1")
+	v1.GET("/invoices/:id", auth, handlers.GetInvoice)
+	r.POST("/login", http.HandlerFunc(login))
+	m.HandleFunc("/orders", listOrders).Methods("GET", "HEAD")
+	cache.Get("/key", &value)
+}
+`))
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	var got []string
+	for _, symbol := range file.Symbols {
+		if symbol.Kind != parser.SymbolRoute {
+			continue
+		}
+		target := ""
+		if len(symbol.Calls) == 1 {
+			call := symbol.Calls[0]
+			target = call.Qualifier + "." + call.Name + ":" + call.ReceiverType
+		}
+		got = append(got, symbol.Name+" -> "+target)
+	}
+	want := []string{
+		"GET /users/{id} -> s.getUser:Server",
+		"ANY /static/ -> ",
+		"ANY /healthz -> ",
+		"GET /api/v1/invoices/:id -> handlers.GetInvoice:",
+		"POST /login -> .login:",
+		"GET /orders -> .listOrders:",
+		"HEAD /orders -> .listOrders:",
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("unexpected routes:\n%v", got)
+	}
+}
+
+func TestGoParserRecordsSymbolSpans(t *testing.T) {
+	content := []byte(`package demo
+
+// Server handles requests.
+type Server struct {
+	addr string
+}
+
+func (s *Server) Close() error {
+	return nil
+}
+`)
+	file, err := NewGoParser().Parse("demo.go", content)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	want := map[string]struct {
+		line, endLine int
+		body          string
+	}{
+		"Server":       {4, 6, "type Server struct {\n\taddr string\n}"},
+		"Server.Close": {8, 10, "func (s *Server) Close() error {\n\treturn nil\n}"},
+	}
+	for _, symbol := range file.Symbols {
+		expected, ok := want[symbol.QualifiedName()]
+		if !ok {
+			continue
+		}
+		if symbol.Line != expected.line || symbol.EndLine != expected.endLine || symbol.Body(content) != expected.body {
+			t.Fatalf("unexpected span for %s: lines %d-%d, body %q", symbol.QualifiedName(), symbol.Line, symbol.EndLine, symbol.Body(content))
+		}
+		delete(want, symbol.QualifiedName())
+	}
+	if len(want) > 0 {
+		t.Fatalf("missing symbols %v", want)
+	}
+}
+
+func TestGoParserExtractsPackageConstantsAndVariables(t *testing.T) {
+	file, err := NewGoParser().Parse("config.go", []byte(`package config
+
+// MaxRetries bounds retries.
+const MaxRetries = 3
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+)
+
+var (
+	defaultConfig = newConfig()
+	host, port    string
+)
+
+var _ io.Reader = (*Source)(nil)
+
+func load() {
+	const local = 1
+	var scratch []byte
+}
+`))
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	got := make([]string, 0)
+	for _, symbol := range file.Symbols {
+		if symbol.Kind == parser.SymbolConstant || symbol.Kind == parser.SymbolVariable {
+			got = append(got, symbol.Kind.String()+" "+symbol.Signature)
+		}
+		switch symbol.Name {
+		case "MaxRetries":
+			if symbol.Doc != "MaxRetries bounds retries." || symbol.Visibility != parser.VisibilityPublic {
+				t.Fatalf("expected MaxRetries doc and visibility, got %+v", symbol)
+			}
+		case "LevelDebug":
+			if len(symbol.References) != 1 || symbol.References[0] != "Level" {
+				t.Fatalf("expected LevelDebug to reference Level, got %v", symbol.References)
+			}
+		case "defaultConfig":
+			if len(symbol.Calls) != 1 || symbol.Calls[0].Name != "newConfig" {
+				t.Fatalf("expected defaultConfig to call newConfig, got %+v", symbol.Calls)
+			}
+		}
+	}
+	want := []string{
+		"const const MaxRetries = 3",
+		"const const LevelDebug Level = iota",
+		"const const LevelInfo",
+		"var var defaultConfig = newConfig()",
+		"var var host string",
+		"var var port string",
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("unexpected constants and variables %q", got)
+	}
+}
@@ -0,0 +1,58 @@
+package languages
+
+import (
+	"path/filepath"
+	"strings"
+
+	sitter "github.com/smacker/go-tree-sitter"
+	"github.com/smacker/go-tree-sitter/c"
+	"github.com/smacker/go-tree-sitter/cpp"
+	"github.com/smacker/go-tree-sitter/csharp"
+	"github.com/smacker/go-tree-sitter/golang"
+	"github.com/smacker/go-tree-sitter/java"
+	"github.com/smacker/go-tree-sitter/javascript"
+	"github.com/smacker/go-tree-sitter/php"
+	"github.com/smacker/go-tree-sitter/protobuf"
+	"github.com/smacker/go-tree-sitter/python"
+	"github.com/smacker/go-tree-sitter/ruby"
+	"github.com/smacker/go-tree-sitter/rust"
+	"github.com/smacker/go-tree-sitter/typescript/tsx"
+	"github.com/smacker/go-tree-sitter/typescript/typescript"
+)
+
+// Grammar returns the tree-sitter grammar the parsers use for a file of the
+// given canonical language, and the grammar's name: "tsx" for .tsx files,
+// otherwise the language. It returns nil for unknown languages.
+func Grammar(language, path string) (*sitter.Language, string) {
+	switch language {
+	case "go":
+		return golang.GetLanguage(), language
+	case "python":
+		return python.GetLanguage(), language
+	case "ruby":
+		return ruby.GetLanguage(), language
+	case "typescript":
+		if strings.EqualFold(filepath.Ext(path), ".tsx") {
+			return tsx.GetLanguage(), "tsx"
+		}
+		return typescript.GetLanguage(), language
+	case "javascript":
+		return javascript.GetLanguage(), language
+	case "rust":
+		return rust.GetLanguage(), language
+	case "java":
+		return java.GetLanguage(), language
+	case "c":
+		return c.GetLanguage(), language
+	case "cpp":
+		return cpp.GetLanguage(), language
+	case "php":
+		return php.GetLanguage(), language
+	case "csharp":
+		return csharp.GetLanguage(), language
+	case "proto":
+		return protobuf.GetLanguage(), language
+	default:
+		return nil, ""
+	}
+}
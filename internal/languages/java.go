@@ -0,0 +1,437 @@
+package languages
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/morozRed/skelly/internal/parser"
+	sitter "github.com/smacker/go-tree-sitter"
+	"github.com/smacker/go-tree-sitter/java"
+)
+
+// JavaParser implements parsing for Java source files
+type JavaParser struct {
+	extractions
+	parser *sitter.Parser
+}
+
+// NewJavaParser creates a new Java parser
+func NewJavaParser() *JavaParser {
+	p := sitter.NewParser()
+	p.SetLanguage(java.GetLanguage())
+	return &JavaParser{parser: p}
+}
+
+func (j *JavaParser) Language() string {
+	return "java"
+}
+
+func (j *JavaParser) Extensions() []string {
+	return []string{".java"}
+}
+
+func (j *JavaParser) Parse(filename string, content []byte) (*parser.FileSymbols, error) {
+	j.begin("java")
+	tree, err := j.parser.ParseCtx(context.Background(), nil, content)
+	if err != nil {
+		return nil, err
+	}
+	defer tree.Close()
+
+	result := &parser.FileSymbols{
+		Path:          filename,
+		Language:      "java",
+		Symbols:       make([]parser.Symbol, 0),
+		Imports:       make([]string, 0),
+		ImportAliases: make(map[string]string),
+	}
+
+	root := tree.RootNode()
+	j.extractSymbols(root, content, result, "")
+
+	return result, nil
+}
+
+func (j *JavaParser) extractSymbols(node *sitter.Node, content []byte, result *parser.FileSymbols, className string) {
+	switch node.Type() {
+	case "package_declaration":
+		if sym := j.extractPackage(node, content); sym != nil {
+			result.Symbols = append(result.Symbols, *sym)
+		}
+		return
+
+	case "import_declaration":
+		imports, aliases := j.extractImport(node, content)
+		result.Imports = append(result.Imports, imports...)
+		result.ImportAliases = mergeImportAliases(result.ImportAliases, aliases)
+		return
+
+	case "class_declaration", "interface_declaration", "enum_declaration", "record_declaration", "annotation_type_declaration":
+		sym := j.extractType(node, content)
+		if sym != nil {
+			sym.Container = className
+			result.Symbols = append(result.Symbols, *sym)
+			// Recurse into the type body to get methods and nested types
+			if bodyNode := node.ChildByFieldName("body"); bodyNode != nil {
+				for i := 0; i < int(bodyNode.ChildCount()); i++ {
+					j.extractSymbols(bodyNode.Child(i), content, result, sym.QualifiedName())
+				}
+			}
+		}
+		return
+
+	case "method_declaration", "constructor_declaration", "compact_constructor_declaration":
+		sym := j.extractMethod(node, content, className)
+		if sym != nil {
+			result.Symbols = append(result.Symbols, *sym)
+		}
+		// Don't recurse into method bodies for local and anonymous classes (for now)
+		return
+
+	case "field_declaration", "constant_declaration":
+		result.Symbols = append(result.Symbols, j.extractFields(node, content, className)...)
+		return
+	}
+
+	// Recurse into children (covers program and enum_body_declarations)
+	for i := 0; i < int(node.ChildCount()); i++ {
+		j.extractSymbols(node.Child(i), content, result, className)
+	}
+}
+
+func (j *JavaParser) extractPackage(node *sitter.Node, content []byte) *parser.Symbol {
+	name := ""
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		child := node.NamedChild(i)
+		if child.Type() == "scoped_identifier" || child.Type() == "identifier" {
+			name = strings.TrimSpace(child.Content(content))
+			break
+		}
+	}
+	if name == "" {
+		return nil
+	}
+
+	return &parser.Symbol{
+		Name:      name,
+		Kind:      parser.SymbolModule,
+		Signature: "package " + name,
+		Line:      int(node.StartPoint().Row) + 1,
+		Span:      symbolSpan(node),
+	}
+}
+
+// extractImport maps Java imports onto slash-separated package paths so the
+// graph resolver can match them against source files (e.g. com/acme/Util.java).
+func (j *JavaParser) extractImport(node *sitter.Node, content []byte) ([]string, map[string]string) {
+	aliases := make(map[string]string)
+	isStatic := false
+	isWildcard := false
+	name := ""
+	for i := 0; i < int(node.ChildCount()); i++ {
+		child := node.Child(i)
+		switch child.Type() {
+		case "static":
+			isStatic = true
+		case "asterisk":
+			isWildcard = true
+		case "scoped_identifier", "identifier":
+			name = strings.TrimSpace(child.Content(content))
+		}
+	}
+	if name == "" {
+		return nil, aliases
+	}
+
+	path := strings.ReplaceAll(name, ".", "/")
+	if isWildcard {
+		// `import a.b.*` and `import static a.b.C.*` expose a package or class without aliases.
+		return []string{path}, aliases
+	}
+
+	qualifier, member := splitQualifiedName(name)
+	if isStatic && qualifier != "" {
+		// `import static a.b.C.member` binds member to a symbol inside class C.
+		classPath := strings.ReplaceAll(qualifier, ".", "/")
+		aliases[member] = fromImportAliasTarget(classPath, member)
+		return []string{classPath}, aliases
+	}
+
+	aliases[member] = path
+	return []string{path}, aliases
+}
+
+func (j *JavaParser) extractType(node *sitter.Node, content []byte) *parser.Symbol {
+	nameNode := node.ChildByFieldName("name")
+	if nameNode == nil {
+		return nil
+	}
+
+	kind := parser.SymbolClass
+	if node.Type() == "interface_declaration" || node.Type() == "annotation_type_declaration" {
+		kind = parser.SymbolInterface
+	}
+
+	return &parser.Symbol{
+		Name:      nameNode.Content(content),
+		Kind:      kind,
+		Signature: j.buildTypeSignature(node, content),
+		Line:      int(node.StartPoint().Row) + 1,
+		Span:      symbolSpan(node),
+		Doc:       j.doc(docBlockSummary, node, content),
+	}
+}
+
+func (j *JavaParser) extractMethod(node *sitter.Node, content []byte, className string) *parser.Symbol {
+	nameNode := node.ChildByFieldName("name")
+	if nameNode == nil {
+		return nil
+	}
+
+	kind := parser.SymbolMethod
+	if className == "" {
+		kind = parser.SymbolFunction
+	}
+
+	return &parser.Symbol{
+		Name:      nameNode.Content(content),
+		Kind:      kind,
+		Signature: j.buildMethodSignature(node, content),
+		Line:      int(node.StartPoint().Row) + 1,
+		Span:      symbolSpan(node),
+		Doc:       j.doc(docBlockSummary, node, content),
+		Container: className,
+		Calls:     j.extractCalls(node.ChildByFieldName("body"), content),
+	}
+}
+
+// extractFields returns a symbol per declarator of a static field: static
+// final fields are constants, other static fields variables. Interface fields
+// are implicitly static final; instance fields are left out.
+func (j *JavaParser) extractFields(node *sitter.Node, content []byte, className string) []parser.Symbol {
+	modifiers := javaModifiers(node, content)
+	kind := parser.SymbolConstant
+	if node.Type() == "field_declaration" {
+		words := strings.Fields(modifiers)
+		if !slices.Contains(words, "static") {
+			return nil
+		}
+		if !slices.Contains(words, "final") {
+			kind = parser.SymbolVariable
+		}
+	}
+
+	declaration := modifiers
+	if typeNode := node.ChildByFieldName("type"); typeNode != nil {
+		declaration = strings.TrimSpace(declaration + " " + typeNode.Content(content))
+	}
+	symbols := make([]parser.Symbol, 0)
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		declarator := node.NamedChild(i)
+		if declarator.Type() != "variable_declarator" {
+			continue
+		}
+		nameNode := declarator.ChildByFieldName("name")
+		if nameNode == nil {
+			continue
+		}
+		name := nameNode.Content(content)
+		signature := declaration + " " + name
+		valueNode := declarator.ChildByFieldName("value")
+		if valueNode != nil {
+			signature = valueSignature(signature, valueNode.Content(content))
+		}
+		symbols = append(symbols, parser.Symbol{
+			Name:      name,
+			Kind:      kind,
+			Signature: signature,
+			Line:      int(declarator.StartPoint().Row) + 1,
+			Span:      symbolSpan(node),
+			Doc:       j.doc(docBlockSummary, node, content),
+			Container: className,
+			Calls:     j.extractCalls(valueNode, content),
+		})
+	}
+	return symbols
+}
+
+func (j *JavaParser) buildTypeSignature(node *sitter.Node, content []byte) string {
+	keyword := strings.TrimSuffix(node.Type(), "_declaration")
+	switch keyword {
+	case "annotation_type":
+		keyword = "@interface"
+	}
+
+	sig := javaModifiers(node, content)
+	if sig != "" {
+		sig += " "
+	}
+	sig += keyword
+	if nameNode := node.ChildByFieldName("name"); nameNode != nil {
+		sig += " " + nameNode.Content(content)
+	}
+	if typeParams := node.ChildByFieldName("type_parameters"); typeParams != nil {
+		sig += typeParams.Content(content)
+	}
+	if paramsNode := node.ChildByFieldName("parameters"); paramsNode != nil {
+		sig += paramsNode.Content(content)
+	}
+	if superclass := node.ChildByFieldName("superclass"); superclass != nil {
+		sig += " " + superclass.Content(content)
+	}
+	if interfaces := node.ChildByFieldName("interfaces"); interfaces != nil {
+		sig += " " + interfaces.Content(content)
+	}
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		if child := node.NamedChild(i); child.Type() == "extends_interfaces" {
+			sig += " " + child.Content(content)
+		}
+	}
+
+	return sig
+}
+
+func (j *JavaParser) buildMethodSignature(node *sitter.Node, content []byte) string {
+	sig := javaModifiers(node, content)
+	appendPart := func(part string) {
+		if part == "" {
+			return
+		}
+		if sig != "" {
+			sig += " "
+		}
+		sig += part
+	}
+
+	if typeParams := node.ChildByFieldName("type_parameters"); typeParams != nil {
+		appendPart(typeParams.Content(content))
+	}
+	if typeNode := node.ChildByFieldName("type"); typeNode != nil {
+		appendPart(typeNode.Content(content))
+	}
+	if nameNode := node.ChildByFieldName("name"); nameNode != nil {
+		appendPart(nameNode.Content(content))
+	}
+	if paramsNode := node.ChildByFieldName("parameters"); paramsNode != nil {
+		sig += paramsNode.Content(content)
+	}
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		if child := node.NamedChild(i); child.Type() == "throws" {
+			sig += " " + child.Content(content)
+		}
+	}
+
+	return sig
+}
+
+// javaModifiers returns keyword modifiers (public, static, ...) without annotations.
+func javaModifiers(node *sitter.Node, content []byte) string {
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		child := node.NamedChild(i)
+		if child.Type() != "modifiers" {
+			continue
+		}
+		parts := make([]string, 0, child.ChildCount())
+		for k := 0; k < int(child.ChildCount()); k++ {
+			modifier := child.Child(k)
+			switch modifier.Type() {
+			case "marker_annotation", "annotation", "line_comment", "block_comment":
+				continue
+			}
+			parts = append(parts, strings.TrimSpace(modifier.Content(content)))
+		}
+		return strings.Join(parts, " ")
+	}
+	return ""
+}
+
+func (j *JavaParser) extractCalls(bodyNode *sitter.Node, content []byte) []parser.CallSite {
+	if j.skip.SkipCalls {
+		return nil
+	}
+	if bodyNode == nil {
+		return nil
+	}
+
+	calls := make([]parser.CallSite, 0)
+	j.collectCalls(bodyNode, content, &calls)
+	return calls
+}
+
+func (j *JavaParser) collectCalls(node *sitter.Node, content []byte, calls *[]parser.CallSite) {
+	if node == nil {
+		return
+	}
+
+	switch node.Type() {
+	case "method_invocation":
+		callSite := j.extractInvocation(node, content)
+		if callSite.Name != "" {
+			*calls = append(*calls, callSite)
+		}
+	case "object_creation_expression":
+		// `new Foo(...)` calls the Foo constructor, which is indexed under the class name.
+		callSite := j.extractCreation(node, content)
+		if callSite.Name != "" {
+			*calls = append(*calls, callSite)
+		}
+	}
+
+	for i := 0; i < int(node.ChildCount()); i++ {
+		j.collectCalls(node.Child(i), content, calls)
+	}
+}
+
+func (j *JavaParser) extractInvocation(node *sitter.Node, content []byte) parser.CallSite {
+	nameNode := node.ChildByFieldName("name")
+	if nameNode == nil {
+		return parser.CallSite{}
+	}
+
+	qualifier := ""
+	if objectNode := node.ChildByFieldName("object"); objectNode != nil {
+		qualifier = strings.TrimSpace(objectNode.Content(content))
+	}
+	callSite := parser.CallSite{
+		Name:      nameNode.Content(content),
+		Qualifier: qualifier,
+		Line:      int(node.StartPoint().Row) + 1,
+		Arity:     javaArgumentCount(node.ChildByFieldName("arguments")),
+	}
+	callSite.Raw = callSite.Name
+	if qualifier != "" {
+		callSite.Raw = qualifier + "." + callSite.Name
+	}
+	if qualifier == "this" {
+		callSite.Receiver = qualifier
+	}
+	return callSite
+}
+
+func (j *JavaParser) extractCreation(node *sitter.Node, content []byte) parser.CallSite {
+	typeNode := node.ChildByFieldName("type")
+	if typeNode == nil {
+		return parser.CallSite{}
+	}
+
+	raw := strings.TrimSpace(typeNode.Content(content))
+	if idx := strings.Index(raw, "<"); idx != -1 {
+		raw = raw[:idx]
+	}
+	qualifier, name := splitQualifiedName(raw)
+	return parser.CallSite{
+		Name:      name,
+		Qualifier: qualifier,
+		Raw:       "new " + raw,
+		Line:      int(node.StartPoint().Row) + 1,
+		Arity:     javaArgumentCount(node.ChildByFieldName("arguments")),
+	}
+}
+
+func javaArgumentCount(argsNode *sitter.Node) int {
+	if argsNode == nil {
+		return 0
+	}
+	return int(argsNode.NamedChildCount())
+}
@@ -0,0 +1,136 @@
+package languages
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/morozRed/skelly/internal/parser"
+)
+
+func TestJavaParserExtractsTypesImportsAndCalls(t *testing.T) {
+	parser := NewJavaParser()
+	file, err := parser.Parse("src/main/java/com/acme/app/Service.java", []byte(`package com.acme.app;
+
+import com.acme.util.Strings;
+import static com.acme.util.Checks.require;
+import java.util.*;
+
+/** Coordinates repository access. */
+@Component
+public class Service extends Base implements Runner {
+    private final Repo repo;
+
+    public Service(Repo repo) { this.repo = repo; }
+
+    @Override
+    public List<String> run(int limit) throws IOException {
+        this.reset();
+        require(limit);
+        Strings.trim("x");
+        return new ArrayList<>();
+    }
+
+    interface Listener { void onEvent(String name); }
+}
+`))
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	symbols := make(map[string]string)
+	for _, symbol := range file.Symbols {
+		symbols[symbol.Name+"/"+symbol.Kind.String()] = symbol.Signature
+	}
+	for key, want := range map[string]string{
+		"com.acme.app/module": "package com.acme.app",
+		"Service/class":       "public class Service extends Base implements Runner",
+		"Service/method":      "public Service(Repo repo)",
+		"run/method":          "public List<String> run(int limit) throws IOException",
+		"Listener/interface":  "interface Listener",
+		"onEvent/method":      "void onEvent(String name)",
+	} {
+		if got := symbols[key]; got != want {
+			t.Fatalf("expected %s signature %q, got %q (all: %#v)", key, want, got, symbols)
+		}
+	}
+	for _, symbol := range file.Symbols {
+		if symbol.Kind.String() == "class" && symbol.Doc != "Coordinates repository access." {
+			t.Fatalf("expected Javadoc on Service, got %q", symbol.Doc)
+		}
+	}
+
+	if got := file.ImportAliases["Strings"]; got != "com/acme/util/Strings" {
+		t.Fatalf("expected Strings alias to com/acme/util/Strings, got %q", got)
+	}
+	if got := file.ImportAliases["require"]; got != "com/acme/util/Checks#require" {
+		t.Fatalf("expected static import alias to com/acme/util/Checks#require, got %q", got)
+	}
+	if len(file.Imports) != 3 || file.Imports[2] != "java/util" {
+		t.Fatalf("unexpected imports: %#v", file.Imports)
+	}
+
+	var runCalls []string
+	for _, symbol := range file.Symbols {
+		if symbol.Name == "run" {
+			for _, call := range symbol.Calls {
+				runCalls = append(runCalls, call.Qualifier+"|"+call.Name+"|"+call.Receiver)
+			}
+		}
+	}
+	want := []string{"this|reset|this", "|require|", "Strings|trim|", "|ArrayList|"}
+	if len(runCalls) != len(want) {
+		t.Fatalf("unexpected calls in run: %#v", runCalls)
+	}
+	for i := range want {
+		if runCalls[i] != want[i] {
+			t.Fatalf("unexpected calls in run: %#v", runCalls)
+		}
+	}
+}
+
+func TestJavaParserExtractsStaticFields(t *testing.T) {
+	file, err := NewJavaParser().Parse("src/main/java/com/acme/Config.java", []byte(`package com.acme;
+
+public class Config {
+    /** Attempts before giving up. */
+    public static final int MAX_RETRIES = 3, MIN_RETRIES = 1;
+    private static Map<String, Integer> cache = buildCache();
+    private final String name;
+    private int count = 0;
+
+    public int retries() {
+        final int local = 2;
+        return local;
+    }
+}
+
+interface Defaults {
+    String HOST = "localhost";
+}
+`))
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	got := make([]string, 0)
+	for _, symbol := range file.Symbols {
+		if symbol.Kind == parser.SymbolConstant || symbol.Kind == parser.SymbolVariable {
+			got = append(got, symbol.Kind.String()+" "+symbol.Signature+" "+symbol.Container)
+		}
+		if symbol.Name == "MAX_RETRIES" && symbol.Doc != "Attempts before giving up." {
+			t.Fatalf("expected doc comment on MAX_RETRIES, got %q", symbol.Doc)
+		}
+		if symbol.Name == "cache" && (len(symbol.Calls) != 1 || symbol.Calls[0].Name != "buildCache") {
+			t.Fatalf("expected cache to call buildCache, got %+v", symbol.Calls)
+		}
+	}
+	want := []string{
+		"const public static final int MAX_RETRIES = 3 Config",
+		"const public static final int MIN_RETRIES = 1 Config",
+		"var private static Map<String, Integer> cache = buildCache() Config",
+		`const String HOST = "localhost" Defaults`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("unexpected static fields:\n%s", strings.Join(got, "\n"))
+	}
+}
@@ -0,0 +1,88 @@
+package languages
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/morozRed/skelly/internal/parser"
+)
+
+// supportedLanguages lists canonical language names in display order.
+var supportedLanguages = []string{"go", "python", "ruby", "typescript", "javascript", "rust", "java", "c", "cpp", "php", "csharp", "proto"}
+
+var languageAliases = map[string]string{
+	"go":         "go",
+	"python":     "python",
+	"py":         "python",
+	"ruby":       "ruby",
+	"rb":         "ruby",
+	"typescript": "typescript",
+	"ts":         "typescript",
+	"javascript": "javascript",
+	"js":         "javascript",
+	"rust":       "rust",
+	"rs":         "rust",
+	"java":       "java",
+	"c":          "c",
+	"cpp":        "cpp",
+	"c++":        "cpp",
+	"cxx":        "cpp",
+	"php":        "php",
+	"csharp":     "csharp",
+	"cs":         "csharp",
+	"c#":         "csharp",
+	"proto":      "proto",
+	"protobuf":   "proto",
+}
+
+// javaScriptExtensions are the extensions the TypeScript parser reads with
+// the JavaScript grammar.
+var javaScriptExtensions = []string{".js", ".jsx", ".mjs", ".cjs"}
+
+func isJavaScriptFile(filename string) bool {
+	return slices.Contains(javaScriptExtensions, filepath.Ext(filename))
+}
+
+// FileLanguage returns the canonical language a file parses as, the one its
+// FileSymbols record, without parsing it.
+func FileLanguage(registry *parser.Registry, filename string) (string, bool) {
+	languageParser, ok := registry.GetParserForFile(filename)
+	if !ok {
+		return "", false
+	}
+	if _, isTypeScript := languageParser.(*TypeScriptParser); isTypeScript && isJavaScriptFile(filename) {
+		return "javascript", true
+	}
+	return languageParser.Language(), true
+}
+
+// SupportedLanguages returns canonical language names accepted by --lang filters.
+func SupportedLanguages() []string {
+	return append([]string(nil), supportedLanguages...)
+}
+
+// CanonicalLanguage maps a language name or alias (e.g. "py") to its canonical name.
+func CanonicalLanguage(raw string) (string, bool) {
+	canonical, ok := languageAliases[strings.ToLower(strings.TrimSpace(raw))]
+	return canonical, ok
+}
+
+// ParseLanguageList converts user-supplied language names into a canonical filter set.
+// An empty input returns a nil filter, which callers treat as "all languages".
+func ParseLanguageList(values []string) (map[string]bool, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	filter := make(map[string]bool, len(values))
+	for _, value := range values {
+		canonical, ok := CanonicalLanguage(value)
+		if !ok {
+			return nil, fmt.Errorf("unsupported language %q (supported: %s)", value, strings.Join(supportedLanguages, ", "))
+		}
+		filter[canonical] = true
+	}
+	return filter, nil
+}
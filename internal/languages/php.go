@@ -0,0 +1,470 @@
+package languages
+
+import (
+	"context"
+	"strings"
+
+	"github.com/morozRed/skelly/internal/parser"
+	sitter "github.com/smacker/go-tree-sitter"
+	"github.com/smacker/go-tree-sitter/php"
+)
+
+// PHPParser implements parsing for PHP source files
+type PHPParser struct {
+	extractions
+	parser *sitter.Parser
+}
+
+// NewPHPParser creates a new PHP parser
+func NewPHPParser() *PHPParser {
+	p := sitter.NewParser()
+	p.SetLanguage(php.GetLanguage())
+	return &PHPParser{parser: p}
+}
+
+func (p *PHPParser) Language() string {
+	return "php"
+}
+
+func (p *PHPParser) Extensions() []string {
+	return []string{".php"}
+}
+
+func (p *PHPParser) Parse(filename string, content []byte) (*parser.FileSymbols, error) {
+	p.begin("php")
+	tree, err := p.parser.ParseCtx(context.Background(), nil, content)
+	if err != nil {
+		return nil, err
+	}
+	defer tree.Close()
+
+	result := &parser.FileSymbols{
+		Path:          filename,
+		Language:      "php",
+		Symbols:       make([]parser.Symbol, 0),
+		Imports:       make([]string, 0),
+		ImportAliases: make(map[string]string),
+	}
+
+	root := tree.RootNode()
+	p.extractSymbols(root, content, result, "")
+
+	return result, nil
+}
+
+func (p *PHPParser) extractSymbols(node *sitter.Node, content []byte, result *parser.FileSymbols, className string) {
+	switch node.Type() {
+	case "namespace_definition":
+		if nameNode := node.ChildByFieldName("name"); nameNode != nil {
+			name := strings.TrimSpace(nameNode.Content(content))
+			result.Symbols = append(result.Symbols, parser.Symbol{
+				Name:      name,
+				Kind:      parser.SymbolModule,
+				Signature: "namespace " + name,
+				Line:      int(node.StartPoint().Row) + 1,
+				Span:      symbolSpan(node),
+			})
+		}
+		// Braced namespaces carry their declarations in a body
+		if bodyNode := node.ChildByFieldName("body"); bodyNode != nil {
+			for i := 0; i < int(bodyNode.ChildCount()); i++ {
+				p.extractSymbols(bodyNode.Child(i), content, result, "")
+			}
+		}
+		return
+
+	case "namespace_use_declaration":
+		imports, aliases := p.extractUse(node, content)
+		result.Imports = append(result.Imports, imports...)
+		result.ImportAliases = mergeImportAliases(result.ImportAliases, aliases)
+		return
+
+	case "class_declaration", "interface_declaration", "trait_declaration", "enum_declaration":
+		sym := p.extractType(node, content)
+		if sym != nil {
+			result.Symbols = append(result.Symbols, *sym)
+			// Recurse into the declaration list to get methods
+			if bodyNode := node.ChildByFieldName("body"); bodyNode != nil {
+				for i := 0; i < int(bodyNode.ChildCount()); i++ {
+					p.extractSymbols(bodyNode.Child(i), content, result, sym.QualifiedName())
+				}
+			}
+		}
+		return
+
+	case "function_definition", "method_declaration":
+		sym := p.extractFunction(node, content, className)
+		if sym != nil {
+			result.Symbols = append(result.Symbols, *sym)
+		}
+		// Don't recurse into function bodies for closures (for now)
+		return
+
+	case "const_declaration":
+		result.Symbols = append(result.Symbols, p.extractConstants(node, content, className)...)
+		return
+
+	case "function_call_expression":
+		if sym := p.extractDefine(node, content); sym != nil {
+			result.Symbols = append(result.Symbols, *sym)
+			return
+		}
+	}
+
+	// Recurse into children
+	for i := 0; i < int(node.ChildCount()); i++ {
+		p.extractSymbols(node.Child(i), content, result, className)
+	}
+}
+
+// extractUse maps `use` clauses onto slash-separated namespace paths
+// (App\Models\User -> App/Models/User) keyed by their local alias.
+func (p *PHPParser) extractUse(node *sitter.Node, content []byte) ([]string, map[string]string) {
+	imports := make([]string, 0)
+	aliases := make(map[string]string)
+
+	isFunction := false
+	prefix := ""
+	for i := 0; i < int(node.ChildCount()); i++ {
+		child := node.Child(i)
+		switch child.Type() {
+		case "function", "const":
+			isFunction = true
+		case "namespace_name":
+			// Group uses: use App\Services\{Mailer, Billing as Pay};
+			prefix = strings.TrimSpace(child.Content(content))
+		}
+	}
+
+	addClause := func(clause *sitter.Node) {
+		name := ""
+		alias := ""
+		for i := 0; i < int(clause.NamedChildCount()); i++ {
+			child := clause.NamedChild(i)
+			switch child.Type() {
+			case "qualified_name", "namespace_name", "name":
+				if name == "" {
+					name = strings.TrimSpace(child.Content(content))
+				}
+			case "namespace_aliasing_clause":
+				alias = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(child.Content(content)), "as"))
+			}
+		}
+		if name == "" {
+			return
+		}
+		if prefix != "" {
+			name = prefix + `\` + name
+		}
+		path := strings.ReplaceAll(strings.TrimPrefix(name, `\`), `\`, "/")
+		if alias == "" {
+			alias = defaultImportAlias(path)
+		}
+		if isFunction {
+			// `use function A\b\helper` binds a function defined somewhere under namespace A\b.
+			qualifier := path
+			member := defaultImportAlias(path)
+			if idx := strings.LastIndex(path, "/"); idx != -1 {
+				qualifier = path[:idx]
+			}
+			imports = append(imports, qualifier)
+			aliases[alias] = fromImportAliasTarget(qualifier, member)
+			return
+		}
+		imports = append(imports, path)
+		aliases[alias] = path
+	}
+
+	var walk func(n *sitter.Node)
+	walk = func(n *sitter.Node) {
+		for i := 0; i < int(n.NamedChildCount()); i++ {
+			child := n.NamedChild(i)
+			switch child.Type() {
+			case "namespace_use_clause", "namespace_use_group_clause":
+				addClause(child)
+			case "namespace_use_group":
+				walk(child)
+			}
+		}
+	}
+	walk(node)
+
+	return imports, aliases
+}
+
+func (p *PHPParser) extractType(node *sitter.Node, content []byte) *parser.Symbol {
+	nameNode := node.ChildByFieldName("name")
+	if nameNode == nil {
+		return nil
+	}
+
+	kind := parser.SymbolClass
+	switch node.Type() {
+	case "interface_declaration":
+		kind = parser.SymbolInterface
+	case "trait_declaration":
+		// Traits are mixins, like Ruby modules
+		kind = parser.SymbolModule
+	}
+
+	return &parser.Symbol{
+		Name:      nameNode.Content(content),
+		Kind:      kind,
+		Signature: p.buildTypeSignature(node, content),
+		Line:      int(node.StartPoint().Row) + 1,
+		Span:      symbolSpan(node),
+		Doc:       p.doc(docBlockSummary, node, content),
+	}
+}
+
+func (p *PHPParser) buildTypeSignature(node *sitter.Node, content []byte) string {
+	parts := make([]string, 0)
+	for i := 0; i < int(node.ChildCount()); i++ {
+		child := node.Child(i)
+		switch child.Type() {
+		case "final_modifier", "abstract_modifier", "readonly_modifier":
+			parts = append(parts, child.Content(content))
+		case "class", "interface", "trait", "enum":
+			parts = append(parts, child.Type())
+		case "name":
+			parts = append(parts, child.Content(content))
+		case "base_clause", "class_interface_clause":
+			parts = append(parts, strings.Join(strings.Fields(child.Content(content)), " "))
+		}
+	}
+	sig := strings.Join(parts, " ")
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		// Backed enums: enum Status: string
+		if child := node.NamedChild(i); child.Type() == "primitive_type" && node.Type() == "enum_declaration" {
+			sig += ": " + child.Content(content)
+		}
+	}
+	return sig
+}
+
+// extractConstants returns a symbol per element of a namespace- or
+// class-level const declaration.
+func (p *PHPParser) extractConstants(node *sitter.Node, content []byte, className string) []parser.Symbol {
+	prefix := "const"
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		if child := node.NamedChild(i); child.Type() == "visibility_modifier" || child.Type() == "final_modifier" {
+			prefix = child.Content(content) + " " + prefix
+		}
+	}
+
+	symbols := make([]parser.Symbol, 0)
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		element := node.NamedChild(i)
+		if element.Type() != "const_element" {
+			continue
+		}
+		var nameNode, valueNode *sitter.Node
+		for k := 0; k < int(element.NamedChildCount()); k++ {
+			if child := element.NamedChild(k); child.Type() == "name" && nameNode == nil {
+				nameNode = child
+			} else {
+				valueNode = child
+			}
+		}
+		if nameNode == nil {
+			continue
+		}
+		name := nameNode.Content(content)
+		signature := prefix + " " + name
+		if valueNode != nil {
+			signature = valueSignature(signature, valueNode.Content(content))
+		}
+		symbols = append(symbols, parser.Symbol{
+			Name:      name,
+			Kind:      parser.SymbolConstant,
+			Signature: signature,
+			Line:      int(element.StartPoint().Row) + 1,
+			Span:      symbolSpan(node),
+			Doc:       p.doc(docBlockSummary, node, content),
+			Container: className,
+			Calls:     p.extractCalls(valueNode, content),
+		})
+	}
+	return symbols
+}
+
+// extractDefine returns the constant a define('NAME', value) call outside
+// functions declares, or nil for other calls.
+func (p *PHPParser) extractDefine(node *sitter.Node, content []byte) *parser.Symbol {
+	functionNode := node.ChildByFieldName("function")
+	args := node.ChildByFieldName("arguments")
+	if functionNode == nil || args == nil || !strings.EqualFold(strings.TrimPrefix(functionNode.Content(content), `\`), "define") {
+		return nil
+	}
+	arguments := make([]*sitter.Node, 0, 2)
+	for i := 0; i < int(args.NamedChildCount()); i++ {
+		if arg := args.NamedChild(i); arg.Type() == "argument" && arg.NamedChildCount() > 0 {
+			arguments = append(arguments, arg.NamedChild(0))
+		}
+	}
+	if len(arguments) < 2 || (arguments[0].Type() != "string" && arguments[0].Type() != "encapsed_string") {
+		return nil
+	}
+	quoted := arguments[0].Content(content)
+	name := strings.Trim(quoted, `'"`)
+	if name == "" {
+		return nil
+	}
+
+	signature := "define(" + quoted + ")"
+	if value := strings.TrimSpace(arguments[1].Content(content)); !strings.Contains(value, "\n") && len(value) <= maxValueSignature {
+		signature = "define(" + quoted + ", " + value + ")"
+	}
+	return &parser.Symbol{
+		Name:      name,
+		Kind:      parser.SymbolConstant,
+		Signature: signature,
+		Line:      int(node.StartPoint().Row) + 1,
+		Span:      symbolSpan(node),
+		Calls:     p.extractCalls(arguments[1], content),
+	}
+}
+
+func (p *PHPParser) extractFunction(node *sitter.Node, content []byte, className string) *parser.Symbol {
+	nameNode := node.ChildByFieldName("name")
+	if nameNode == nil {
+		return nil
+	}
+
+	kind := parser.SymbolFunction
+	if className != "" {
+		kind = parser.SymbolMethod
+	}
+
+	return &parser.Symbol{
+		Name:      nameNode.Content(content),
+		Kind:      kind,
+		Signature: p.buildFunctionSignature(node, content),
+		Line:      int(node.StartPoint().Row) + 1,
+		Span:      symbolSpan(node),
+		Doc:       p.doc(docBlockSummary, node, content),
+		Container: className,
+		Calls:     p.extractCalls(node.ChildByFieldName("body"), content),
+	}
+}
+
+func (p *PHPParser) buildFunctionSignature(node *sitter.Node, content []byte) string {
+	parts := make([]string, 0)
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		child := node.NamedChild(i)
+		switch child.Type() {
+		case "visibility_modifier", "static_modifier", "final_modifier", "abstract_modifier", "readonly_modifier":
+			parts = append(parts, child.Content(content))
+		}
+	}
+	parts = append(parts, "function")
+
+	sig := strings.Join(parts, " ")
+	if nameNode := node.ChildByFieldName("name"); nameNode != nil {
+		sig += " " + nameNode.Content(content)
+	}
+	if paramsNode := node.ChildByFieldName("parameters"); paramsNode != nil {
+		sig += strings.Join(strings.Fields(paramsNode.Content(content)), " ")
+	}
+	if returnNode := node.ChildByFieldName("return_type"); returnNode != nil {
+		sig += ": " + returnNode.Content(content)
+	}
+	return sig
+}
+
+func (p *PHPParser) extractCalls(bodyNode *sitter.Node, content []byte) []parser.CallSite {
+	if p.skip.SkipCalls {
+		return nil
+	}
+	if bodyNode == nil {
+		return nil
+	}
+
+	calls := make([]parser.CallSite, 0)
+	p.collectCalls(bodyNode, content, &calls)
+	return calls
+}
+
+func (p *PHPParser) collectCalls(node *sitter.Node, content []byte, calls *[]parser.CallSite) {
+	if node == nil {
+		return
+	}
+
+	switch node.Type() {
+	case "function_call_expression", "member_call_expression", "nullsafe_member_call_expression", "scoped_call_expression", "object_creation_expression":
+		callSite := p.extractCallSite(node, content)
+		if callSite.Name != "" {
+			*calls = append(*calls, callSite)
+		}
+	}
+
+	for i := 0; i < int(node.ChildCount()); i++ {
+		p.collectCalls(node.Child(i), content, calls)
+	}
+}
+
+func (p *PHPParser) extractCallSite(node *sitter.Node, content []byte) parser.CallSite {
+	callSite := parser.CallSite{
+		Line: int(node.StartPoint().Row) + 1,
+	}
+
+	switch node.Type() {
+	case "function_call_expression":
+		if fnNode := node.ChildByFieldName("function"); fnNode != nil {
+			callSite.Raw = strings.TrimSpace(fnNode.Content(content))
+			callSite.Name = phpLastSegment(callSite.Raw)
+		}
+	case "member_call_expression", "nullsafe_member_call_expression":
+		if nameNode := node.ChildByFieldName("name"); nameNode != nil {
+			callSite.Name = strings.TrimSpace(nameNode.Content(content))
+		}
+		if objectNode := node.ChildByFieldName("object"); objectNode != nil {
+			callSite.Qualifier = strings.TrimPrefix(strings.TrimSpace(objectNode.Content(content)), "$")
+		}
+		if callSite.Qualifier == "this" {
+			callSite.Receiver = "this"
+		}
+		callSite.Raw = "$" + callSite.Qualifier + "->" + callSite.Name
+	case "scoped_call_expression":
+		if nameNode := node.ChildByFieldName("name"); nameNode != nil {
+			callSite.Name = strings.TrimSpace(nameNode.Content(content))
+		}
+		if scopeNode := node.ChildByFieldName("scope"); scopeNode != nil {
+			callSite.Qualifier = phpLastSegment(strings.TrimSpace(scopeNode.Content(content)))
+		}
+		switch callSite.Qualifier {
+		case "self", "static":
+			callSite.Receiver = "self"
+		}
+		callSite.Raw = callSite.Qualifier + "::" + callSite.Name
+	case "object_creation_expression":
+		// `new Foo()` resolves to the Foo class symbol
+		for i := 0; i < int(node.NamedChildCount()); i++ {
+			child := node.NamedChild(i)
+			if child.Type() == "name" || child.Type() == "qualified_name" {
+				callSite.Raw = "new " + strings.TrimSpace(child.Content(content))
+				callSite.Name = phpLastSegment(child.Content(content))
+				break
+			}
+		}
+	}
+
+	if argsNode := node.ChildByFieldName("arguments"); argsNode != nil {
+		callSite.Arity = int(argsNode.NamedChildCount())
+	} else {
+		for i := 0; i < int(node.NamedChildCount()); i++ {
+			if child := node.NamedChild(i); child.Type() == "arguments" {
+				callSite.Arity = int(child.NamedChildCount())
+			}
+		}
+	}
+	return callSite
+}
+
+func phpLastSegment(name string) string {
+	name = strings.TrimSpace(name)
+	if idx := strings.LastIndex(name, `\`); idx != -1 {
+		return name[idx+1:]
+	}
+	return name
+}
@@ -0,0 +1,143 @@
+package languages
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/morozRed/skelly/internal/parser"
+)
+
+func TestPHPParserExtractsTypesUsesAndCalls(t *testing.T) {
+	parser := NewPHPParser()
+	file, err := parser.Parse("app/Http/Controllers/UserController.php", []byte(`<?php
+namespace App\Http\Controllers;
+
+use App\Models\User;
+use App\Services\{Mailer, Billing as Pay};
+use function App\Support\helper;
+
+/**
+ * Handles user pages.
+ */
+final class UserController extends Controller implements Auth {
+    use Loggable;
+
+    public function show(int $id): User {
+        $this->authorize();
+        self::check($id);
+        User::find($id);
+        helper($id);
+        new Mailer();
+        return $user;
+    }
+}
+
+interface Auth { public function authorize(): void; }
+
+trait Loggable { protected static function log(string $m) { error_log($m); } }
+
+function top_level($a) { return strlen($a); }
+`))
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	symbols := make(map[string]string)
+	for _, symbol := range file.Symbols {
+		symbols[symbol.Name+"/"+symbol.Kind.String()] = symbol.Signature
+	}
+	for key, want := range map[string]string{
+		`App\Http\Controllers/module`: `namespace App\Http\Controllers`,
+		"UserController/class":        "final class UserController extends Controller implements Auth",
+		"show/method":                 "public function show(int $id): User",
+		"Auth/interface":              "interface Auth",
+		"authorize/method":            "public function authorize(): void",
+		"Loggable/module":             "trait Loggable",
+		"log/method":                  "protected static function log(string $m)",
+		"top_level/func":              "function top_level($a)",
+	} {
+		if got := symbols[key]; got != want {
+			t.Fatalf("expected %s signature %q, got %q (all: %#v)", key, want, got, symbols)
+		}
+	}
+	for _, symbol := range file.Symbols {
+		if symbol.Name == "UserController" && symbol.Doc != "Handles user pages." {
+			t.Fatalf("expected PHPDoc summary on UserController, got %q", symbol.Doc)
+		}
+	}
+
+	for alias, want := range map[string]string{
+		"User":   "App/Models/User",
+		"Mailer": "App/Services/Mailer",
+		"Pay":    "App/Services/Billing",
+		"helper": "App/Support#helper",
+	} {
+		if got := file.ImportAliases[alias]; got != want {
+			t.Fatalf("expected alias %s -> %q, got %q (all: %#v)", alias, want, got, file.ImportAliases)
+		}
+	}
+
+	var showCalls []string
+	for _, symbol := range file.Symbols {
+		if symbol.Name == "show" {
+			for _, call := range symbol.Calls {
+				showCalls = append(showCalls, call.Qualifier+"|"+call.Name+"|"+call.Receiver)
+			}
+		}
+	}
+	want := []string{"this|authorize|this", "self|check|self", "User|find|", "|helper|", "|Mailer|"}
+	if len(showCalls) != len(want) {
+		t.Fatalf("unexpected calls in show: %#v", showCalls)
+	}
+	for i := range want {
+		if showCalls[i] != want[i] {
+			t.Fatalf("unexpected calls in show: %#v", showCalls)
+		}
+	}
+}
+
+func TestPHPParserExtractsConstantsAndDefines(t *testing.T) {
+	file, err := NewPHPParser().Parse("app/config.php", []byte(`<?php
+namespace App;
+
+const MAX_RETRIES = 3, MIN_RETRIES = 1;
+define('APP_ENV', env('APP_ENV', 'local'));
+
+class Invoice
+{
+    /** The default status. */
+    public const STATUS = 'draft';
+    private static $cache = [];
+
+    public function total()
+    {
+        define('LOCAL_ONLY', 1);
+    }
+}
+`))
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	got := make([]string, 0)
+	for _, symbol := range file.Symbols {
+		if symbol.Kind == parser.SymbolConstant || symbol.Kind == parser.SymbolVariable {
+			got = append(got, symbol.Kind.String()+" "+symbol.Signature+" "+symbol.Container)
+		}
+		if symbol.Name == "APP_ENV" && (len(symbol.Calls) != 1 || symbol.Calls[0].Name != "env") {
+			t.Fatalf("expected APP_ENV to call env, got %+v", symbol.Calls)
+		}
+		if symbol.Name == "STATUS" && symbol.Doc != "The default status." {
+			t.Fatalf("expected doc comment on STATUS, got %q", symbol.Doc)
+		}
+	}
+	want := []string{
+		"const const MAX_RETRIES = 3 ",
+		"const const MIN_RETRIES = 1 ",
+		"const define('APP_ENV', env('APP_ENV', 'local')) ",
+		"const public const STATUS = 'draft' Invoice",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("unexpected constants:\n%s", strings.Join(got, "\n"))
+	}
+}
@@ -0,0 +1,204 @@
+package languages
+
+import (
+	"context"
+	"strings"
+
+	"github.com/morozRed/skelly/internal/parser"
+	sitter "github.com/smacker/go-tree-sitter"
+	"github.com/smacker/go-tree-sitter/protobuf"
+)
+
+// ProtoParser implements parsing for Protocol Buffers definitions
+type ProtoParser struct {
+	extractions
+	parser *sitter.Parser
+}
+
+// NewProtoParser creates a new Protocol Buffers parser
+func NewProtoParser() *ProtoParser {
+	p := sitter.NewParser()
+	p.SetLanguage(protobuf.GetLanguage())
+	return &ProtoParser{parser: p}
+}
+
+func (p *ProtoParser) Language() string {
+	return "proto"
+}
+
+func (p *ProtoParser) Extensions() []string {
+	return []string{".proto"}
+}
+
+func (p *ProtoParser) Parse(filename string, content []byte) (*parser.FileSymbols, error) {
+	p.begin("proto")
+	tree, err := p.parser.ParseCtx(context.Background(), nil, content)
+	if err != nil {
+		return nil, err
+	}
+	defer tree.Close()
+
+	result := &parser.FileSymbols{
+		Path:          filename,
+		Language:      "proto",
+		Symbols:       make([]parser.Symbol, 0),
+		Imports:       make([]string, 0),
+		ImportAliases: make(map[string]string),
+	}
+
+	root := tree.RootNode()
+	p.extractSymbols(root, content, result, "")
+
+	return result, nil
+}
+
+// extractSymbols indexes messages and services as structs and interfaces,
+// enums as classes and rpcs as methods of their service. Nested messages and
+// enums are contained by their parent message (Invoice.LineItem).
+func (p *ProtoParser) extractSymbols(node *sitter.Node, content []byte, result *parser.FileSymbols, container string) {
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		child := node.NamedChild(i)
+		switch child.Type() {
+		case "import":
+			if path := child.ChildByFieldName("path"); path != nil {
+				// Imports keep their .proto path; the graph resolves them to
+				// .proto files only, like C includes.
+				result.Imports = append(result.Imports, strings.Trim(path.Content(content), `"'`))
+			}
+
+		case "message":
+			sym := p.declaration(child, "message_name", "message", parser.SymbolStruct, content, container)
+			if sym == nil {
+				continue
+			}
+			if body := protoChild(child, "message_body"); body != nil {
+				sym.References = p.references(func() []string { return protoFieldReferences(body, content) })
+				result.Symbols = append(result.Symbols, *sym)
+				p.extractSymbols(body, content, result, sym.QualifiedName())
+				continue
+			}
+			result.Symbols = append(result.Symbols, *sym)
+
+		case "enum":
+			if sym := p.declaration(child, "enum_name", "enum", parser.SymbolClass, content, container); sym != nil {
+				result.Symbols = append(result.Symbols, *sym)
+			}
+
+		case "service":
+			sym := p.declaration(child, "service_name", "service", parser.SymbolInterface, content, container)
+			if sym == nil {
+				continue
+			}
+			result.Symbols = append(result.Symbols, *sym)
+			for j := 0; j < int(child.NamedChildCount()); j++ {
+				if rpc := child.NamedChild(j); rpc.Type() == "rpc" {
+					if method := p.rpc(rpc, content, sym.Name); method != nil {
+						result.Symbols = append(result.Symbols, *method)
+					}
+				}
+			}
+		}
+	}
+}
+
+// declaration builds the symbol for a message, enum or service node.
+func (p *ProtoParser) declaration(node *sitter.Node, nameType, keyword string, kind parser.SymbolKind, content []byte, container string) *parser.Symbol {
+	nameNode := protoChild(node, nameType)
+	if nameNode == nil {
+		return nil
+	}
+	name := nameNode.Content(content)
+	return &parser.Symbol{
+		Name:      name,
+		Kind:      kind,
+		Signature: keyword + " " + name,
+		Line:      int(node.StartPoint().Row) + 1,
+		Span:      symbolSpan(node),
+		Doc:       p.doc(protoDocComment, node, content),
+		Container: container,
+	}
+}
+
+// rpc builds the method symbol for an rpc, referencing its request and
+// response messages.
+func (p *ProtoParser) rpc(node *sitter.Node, content []byte, service string) *parser.Symbol {
+	nameNode := protoChild(node, "rpc_name")
+	if nameNode == nil {
+		return nil
+	}
+	signature, _, _ := strings.Cut(node.Content(content), "{")
+	signature = strings.TrimSuffix(strings.TrimSpace(signature), ";")
+
+	return &parser.Symbol{
+		Name:       nameNode.Content(content),
+		Kind:       parser.SymbolMethod,
+		Signature:  strings.Join(strings.Fields(signature), " "),
+		Line:       int(node.StartPoint().Row) + 1,
+		Span:       symbolSpan(node),
+		Doc:        p.doc(protoDocComment, node, content),
+		Container:  service,
+		References: p.references(func() []string { return protoRPCReferences(node, content) }),
+	}
+}
+
+// protoRPCReferences returns the request and response types of an rpc.
+func protoRPCReferences(node *sitter.Node, content []byte) []string {
+	var refs []string
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		if child := node.NamedChild(i); child.Type() == "message_or_enum_type" {
+			refs = appendReference(refs, child.Content(content))
+		}
+	}
+	return refs
+}
+
+// protoFieldReferences returns the message and enum types of a message's
+// own fields, including map values and oneof members; nested messages record
+// their own.
+func protoFieldReferences(body *sitter.Node, content []byte) []string {
+	var refs []string
+	var walk func(node *sitter.Node)
+	walk = func(node *sitter.Node) {
+		for i := 0; i < int(node.NamedChildCount()); i++ {
+			child := node.NamedChild(i)
+			switch child.Type() {
+			case "message", "enum":
+				continue
+			case "message_or_enum_type":
+				refs = appendReference(refs, strings.TrimPrefix(child.Content(content), "."))
+				continue
+			}
+			walk(child)
+		}
+	}
+	walk(body)
+	return refs
+}
+
+func protoChild(node *sitter.Node, nodeType string) *sitter.Node {
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		if child := node.NamedChild(i); child.Type() == nodeType {
+			return child
+		}
+	}
+	return nil
+}
+
+// protoDocComment collects the `//` comment lines directly above a
+// declaration.
+func protoDocComment(node *sitter.Node, content []byte) string {
+	lines := make([]string, 0)
+	nextRow := node.StartPoint().Row
+	for sibling := node.PrevNamedSibling(); sibling != nil; sibling = sibling.PrevNamedSibling() {
+		if sibling.Type() != "comment" || sibling.EndPoint().Row+1 != nextRow {
+			break
+		}
+		text := strings.TrimSpace(sibling.Content(content))
+		if !strings.HasPrefix(text, "//") {
+			break
+		}
+		nextRow = sibling.StartPoint().Row
+		lines = append([]string{strings.TrimSpace(strings.TrimPrefix(text, "//"))}, lines...)
+	}
+	return strings.Join(strings.Fields(strings.Join(lines, " ")), " ")
+}
@@ -0,0 +1,71 @@
+package languages
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/morozRed/skelly/internal/parser"
+)
+
+func TestProtoParserExtractsMessagesEnumsServicesAndRPCs(t *testing.T) {
+	file, err := NewProtoParser().Parse("billing/v1/invoice.proto", []byte(`syntax = "proto3";
+package acme.billing.v1;
+import "billing/v1/customer.proto";
+
+// Invoice is a bill.
+message Invoice {
+  string id = 1;
+  Customer customer = 2;
+  repeated LineItem items = 3;
+  map<string, Money> totals = 4;
+  google.protobuf.Timestamp created = 5;
+  message LineItem { Money amount = 1; }
+}
+
+enum Currency { CURRENCY_UNSPECIFIED = 0; }
+
+service InvoiceService {
+  // GetInvoice fetches one invoice.
+  rpc GetInvoice(GetInvoiceRequest) returns (Invoice);
+  rpc Watch(stream WatchRequest) returns (stream Invoice) {}
+}
+`))
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	if strings.Join(file.Imports, ",") != "billing/v1/customer.proto" {
+		t.Fatalf("unexpected imports %#v", file.Imports)
+	}
+
+	type want struct {
+		kind       parser.SymbolKind
+		signature  string
+		doc        string
+		references string
+	}
+	expected := map[string]want{
+		"Invoice":                   {parser.SymbolStruct, "message Invoice", "Invoice is a bill.", "Customer,LineItem,Money,google.protobuf.Timestamp"},
+		"Invoice.LineItem":          {parser.SymbolStruct, "message LineItem", "", "Money"},
+		"Currency":                  {parser.SymbolClass, "enum Currency", "", ""},
+		"InvoiceService":            {parser.SymbolInterface, "service InvoiceService", "", ""},
+		"InvoiceService.GetInvoice": {parser.SymbolMethod, "rpc GetInvoice(GetInvoiceRequest) returns (Invoice)", "GetInvoice fetches one invoice.", "GetInvoiceRequest,Invoice"},
+		"InvoiceService.Watch":      {parser.SymbolMethod, "rpc Watch(stream WatchRequest) returns (stream Invoice)", "", "WatchRequest,Invoice"},
+	}
+	for _, symbol := range file.Symbols {
+		w, ok := expected[symbol.QualifiedName()]
+		if !ok {
+			t.Fatalf("unexpected symbol %s", symbol.QualifiedName())
+		}
+		if symbol.Kind != w.kind || symbol.Signature != w.signature || symbol.Doc != w.doc {
+			t.Fatalf("unexpected %s: %#v", symbol.QualifiedName(), symbol)
+		}
+		if got := strings.Join(symbol.References, ","); got != w.references {
+			t.Fatalf("expected %s to reference %q, got %q", symbol.QualifiedName(), w.references, got)
+		}
+		delete(expected, symbol.QualifiedName())
+	}
+	if len(expected) != 0 {
+		t.Fatalf("missing symbols %v", expected)
+	}
+}
@@ -11,6 +11,7 @@ import (
 
 // PythonParser implements parsing for Python source files
 type PythonParser struct {
+	extractions
 	parser *sitter.Parser
 }
 
@@ -30,6 +31,7 @@ func (p *PythonParser) Extensions() []string {
 }
 
 func (p *PythonParser) Parse(filename string, content []byte) (*parser.FileSymbols, error) {
+	p.begin("python")
 	tree, err := p.parser.ParseCtx(context.Background(), nil, content)
 	if err != nil {
 		return nil, err
@@ -45,15 +47,16 @@ func (p *PythonParser) Parse(filename string, content []byte) (*parser.FileSymbo
 	}
 
 	root := tree.RootNode()
-	p.extractSymbols(root, content, result, "")
+	p.extractSymbols(root, content, pythonResultTypes(root, content), result, "")
+	result.Symbols = append(result.Symbols, pythonRoutes(root, content)...)
 
 	return result, nil
 }
 
-func (p *PythonParser) extractSymbols(node *sitter.Node, content []byte, result *parser.FileSymbols, className string) {
+func (p *PythonParser) extractSymbols(node *sitter.Node, content []byte, resultTypes map[string]string, result *parser.FileSymbols, className string) {
 	switch node.Type() {
 	case "function_definition":
-		sym := p.extractFunction(node, content, className)
+		sym := p.extractFunction(node, content, resultTypes, className)
 		if sym != nil {
 			result.Symbols = append(result.Symbols, *sym)
 		}
@@ -63,17 +66,25 @@ func (p *PythonParser) extractSymbols(node *sitter.Node, content []byte, result
 	case "class_definition":
 		sym := p.extractClass(node, content)
 		if sym != nil {
+			sym.Container = className
 			result.Symbols = append(result.Symbols, *sym)
 			// Recurse into class body to get methods
 			bodyNode := node.ChildByFieldName("body")
 			if bodyNode != nil {
 				for i := 0; i < int(bodyNode.ChildCount()); i++ {
-					p.extractSymbols(bodyNode.Child(i), content, result, sym.Name)
+					p.extractSymbols(bodyNode.Child(i), content, resultTypes, result, sym.QualifiedName())
 				}
 			}
 		}
 		return
 
+	case "assignment":
+		// Outside classes and functions an assignment binds a module-level
+		// name, also under if and try blocks.
+		if className == "" {
+			p.extractAssignment(node, content, result)
+		}
+
 	case "import_statement":
 		imports, aliases := p.extractImport(node, content)
 		result.Imports = append(result.Imports, imports...)
@@ -88,11 +99,11 @@ func (p *PythonParser) extractSymbols(node *sitter.Node, content []byte, result
 	// Recurse into children
 	for i := 0; i < int(node.ChildCount()); i++ {
 		child := node.Child(i)
-		p.extractSymbols(child, content, result, className)
+		p.extractSymbols(child, content, resultTypes, result, className)
 	}
 }
 
-func (p *PythonParser) extractFunction(node *sitter.Node, content []byte, className string) *parser.Symbol {
+func (p *PythonParser) extractFunction(node *sitter.Node, content []byte, resultTypes map[string]string, className string) *parser.Symbol {
 	nameNode := node.ChildByFieldName("name")
 	if nameNode == nil {
 		return nil
@@ -106,26 +117,19 @@ func (p *PythonParser) extractFunction(node *sitter.Node, content []byte, classN
 
 	sig := p.buildFunctionSignature(node, content)
 
-	// Get docstring if present
-	doc := ""
 	bodyNode := node.ChildByFieldName("body")
-	if bodyNode != nil && bodyNode.ChildCount() > 0 {
-		firstStmt := bodyNode.Child(0)
-		if firstStmt.Type() == "expression_statement" && firstStmt.ChildCount() > 0 {
-			expr := firstStmt.Child(0)
-			if expr.Type() == "string" {
-				doc = extractDocstring(expr.Content(content))
-			}
-		}
-	}
 
 	return &parser.Symbol{
-		Name:      name,
-		Kind:      kind,
-		Signature: sig,
-		Line:      int(node.StartPoint().Row) + 1,
-		Doc:       doc,
-		Calls:     p.extractCalls(bodyNode, content),
+		Name:       name,
+		Kind:       kind,
+		Signature:  sig,
+		Line:       int(node.StartPoint().Row) + 1,
+		Span:       symbolSpan(node),
+		Doc:        p.doc(pythonDocstring, bodyNode, content),
+		Container:  className,
+		Visibility: pythonVisibility(name),
+		References: p.references(func() []string { return pythonFunctionReferences(node, content) }),
+		Calls:      p.functionCalls(node, bodyNode, content, resultTypes),
 	}
 }
 
@@ -138,26 +142,331 @@ func (p *PythonParser) extractClass(node *sitter.Node, content []byte) *parser.S
 	name := nameNode.Content(content)
 	sig := p.buildClassSignature(node, content)
 
-	// Get docstring if present
-	doc := ""
 	bodyNode := node.ChildByFieldName("body")
-	if bodyNode != nil && bodyNode.ChildCount() > 0 {
-		firstStmt := bodyNode.Child(0)
-		if firstStmt.Type() == "expression_statement" && firstStmt.ChildCount() > 0 {
-			expr := firstStmt.Child(0)
-			if expr.Type() == "string" {
-				doc = extractDocstring(expr.Content(content))
+
+	return &parser.Symbol{
+		Name:       name,
+		Kind:       parser.SymbolClass,
+		Signature:  sig,
+		Line:       int(node.StartPoint().Row) + 1,
+		Span:       symbolSpan(node),
+		Doc:        p.doc(pythonDocstring, bodyNode, content),
+		Visibility: pythonVisibility(name),
+		Bases:      pythonClassBases(node.ChildByFieldName("superclasses"), content),
+		References: p.references(func() []string { return pythonFieldReferences(bodyNode, content) }),
+	}
+}
+
+// extractAssignment adds a constant or variable symbol for each name a
+// module-level assignment binds, unless an earlier assignment in the file
+// already did. UPPER_CASE names are constants by convention; dunder names
+// such as __all__ are left out.
+func (p *PythonParser) extractAssignment(node *sitter.Node, content []byte, result *parser.FileSymbols) {
+	left := node.ChildByFieldName("left")
+	right := node.ChildByFieldName("right")
+	typeNode := node.ChildByFieldName("type")
+	if left == nil {
+		return
+	}
+
+	targets := []*sitter.Node{left}
+	values := []*sitter.Node{right}
+	if left.Type() == "pattern_list" || left.Type() == "tuple_pattern" {
+		targets = namedArguments(left)
+		values = make([]*sitter.Node, len(targets))
+		if right != nil && (right.Type() == "expression_list" || right.Type() == "tuple") {
+			for i, value := range namedArguments(right) {
+				if i < len(values) {
+					values[i] = value
+				}
 			}
 		}
 	}
 
-	return &parser.Symbol{
-		Name:      name,
-		Kind:      parser.SymbolClass,
-		Signature: sig,
-		Line:      int(node.StartPoint().Row) + 1,
-		Doc:       doc,
+	for i, target := range targets {
+		if target.Type() != "identifier" {
+			continue
+		}
+		name := target.Content(content)
+		if len(name) > 4 && strings.HasPrefix(name, "__") && strings.HasSuffix(name, "__") {
+			continue
+		}
+		if pythonModuleValueDeclared(result.Symbols, name) {
+			continue
+		}
+
+		kind := parser.SymbolVariable
+		if pythonConstantName(name) {
+			kind = parser.SymbolConstant
+		}
+		declaration := name
+		if typeNode != nil {
+			declaration += ": " + typeNode.Content(content)
+		}
+		signature := declaration
+		if values[i] != nil {
+			signature = valueSignature(declaration, values[i].Content(content))
+		}
+		result.Symbols = append(result.Symbols, parser.Symbol{
+			Name:       name,
+			Kind:       kind,
+			Signature:  signature,
+			Line:       int(node.StartPoint().Row) + 1,
+			Span:       symbolSpan(node),
+			Visibility: pythonVisibility(name),
+			References: p.references(func() []string { return pythonTypeReferences(nil, typeNode, content, false) }),
+			Calls:      p.extractCalls(values[i], content, nil),
+		})
+	}
+}
+
+func pythonModuleValueDeclared(symbols []parser.Symbol, name string) bool {
+	for _, sym := range symbols {
+		if sym.Name == name && sym.Container == "" && (sym.Kind == parser.SymbolConstant || sym.Kind == parser.SymbolVariable) {
+			return true
+		}
+	}
+	return false
+}
+
+// pythonConstantName reports whether name is written in UPPER_CASE.
+func pythonConstantName(name string) bool {
+	hasLetter := false
+	for _, r := range name {
+		switch {
+		case r >= 'A' && r <= 'Z':
+			hasLetter = true
+		case r == '_' || (r >= '0' && r <= '9'):
+		default:
+			return false
+		}
+	}
+	return hasLetter
+}
+
+// pythonVisibility treats names with a leading underscore as private by
+// convention; dunder names such as __init__ are public.
+func pythonVisibility(name string) string {
+	if strings.HasPrefix(name, "_") && !(len(name) > 4 && strings.HasPrefix(name, "__") && strings.HasSuffix(name, "__")) {
+		return parser.VisibilityPrivate
+	}
+	return parser.VisibilityPublic
+}
+
+// pythonBuiltinTypes are builtin and typing names that never resolve to a
+// repository class.
+var pythonBuiltinTypes = map[string]bool{
+	"None": true, "bool": true, "bytes": true, "complex": true, "dict": true,
+	"float": true, "frozenset": true, "int": true, "list": true, "object": true,
+	"set": true, "str": true, "tuple": true, "type": true,
+	"Any": true, "Callable": true, "ClassVar": true, "Dict": true, "Iterable": true,
+	"Iterator": true, "List": true, "Literal": true, "Mapping": true, "Optional": true,
+	"Sequence": true, "Set": true, "Tuple": true, "Type": true, "Union": true,
+}
+
+// pythonFunctionReferences returns the classes named in a function's type
+// hints: annotated parameters, the return type and annotated assignments in
+// its body.
+func pythonFunctionReferences(node *sitter.Node, content []byte) []string {
+	var refs []string
+	refs = pythonTypeReferences(refs, node.ChildByFieldName("parameters"), content, false)
+	refs = pythonTypeReferences(refs, node.ChildByFieldName("return_type"), content, false)
+	refs = pythonTypeReferences(refs, node.ChildByFieldName("body"), content, false)
+	return refs
+}
+
+// pythonFieldReferences returns the classes named by annotated class
+// attributes (dataclass fields, `name: User`); methods record their own.
+func pythonFieldReferences(body *sitter.Node, content []byte) []string {
+	if body == nil {
+		return nil
+	}
+	var refs []string
+	for i := 0; i < int(body.NamedChildCount()); i++ {
+		if stmt := body.NamedChild(i); stmt.Type() == "expression_statement" {
+			refs = pythonTypeReferences(refs, stmt, content, false)
+		}
+	}
+	return refs
+}
+
+// pythonTypeReferences appends the names and dotted names inside type
+// annotations under node; inAnnotation is set once a `type` node is entered.
+// String forward references are skipped.
+func pythonTypeReferences(refs []string, node *sitter.Node, content []byte, inAnnotation bool) []string {
+	if node == nil {
+		return refs
+	}
+	switch node.Type() {
+	case "type":
+		inAnnotation = true
+	case "identifier", "attribute":
+		if inAnnotation {
+			if name := node.Content(content); !pythonBuiltinTypes[name] {
+				refs = appendReference(refs, name)
+			}
+			return refs
+		}
+	case "string":
+		return refs
+	}
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		refs = pythonTypeReferences(refs, node.NamedChild(i), content, inAnnotation)
+	}
+	return refs
+}
+
+// pythonResultTypes maps each module-level function in the file to the class
+// named by its return annotation, so `user = load_user()` types user as User.
+func pythonResultTypes(root *sitter.Node, content []byte) map[string]string {
+	types := make(map[string]string)
+	for i := 0; i < int(root.NamedChildCount()); i++ {
+		decl := root.NamedChild(i)
+		if decl.Type() == "decorated_definition" {
+			decl = decl.ChildByFieldName("definition")
+		}
+		if decl == nil || decl.Type() != "function_definition" {
+			continue
+		}
+		nameNode := decl.ChildByFieldName("name")
+		if nameNode == nil {
+			continue
+		}
+		if typeName := pythonAnnotatedType(decl.ChildByFieldName("return_type"), content); typeName != "" {
+			types[nameNode.Content(content)] = typeName
+		}
+	}
+	return types
+}
+
+// functionCalls returns the calls in a function body, typing receivers by
+// the function's annotated parameters and locals.
+func (p *PythonParser) functionCalls(node, bodyNode *sitter.Node, content []byte, resultTypes map[string]string) []parser.CallSite {
+	if p.skip.SkipCalls {
+		return nil
+	}
+	return p.extractCalls(bodyNode, content, pythonLocalTypes(node, content, resultTypes))
+}
+
+// pythonLocalTypes maps the annotated parameters and local variables of a
+// function to the class their annotation names. Plain assignments from a
+// function in resultTypes are typed by its return annotation; any other
+// assignment, or a conflicting annotation, leaves the name untyped.
+func pythonLocalTypes(node *sitter.Node, content []byte, resultTypes map[string]string) map[string]string {
+	types := make(map[string]string)
+	declare := func(nameNode *sitter.Node, typeName string) {
+		if nameNode == nil || nameNode.Type() != "identifier" {
+			return
+		}
+		name := nameNode.Content(content)
+		if existing, ok := types[name]; ok && existing != typeName {
+			typeName = ""
+		}
+		types[name] = typeName
+	}
+
+	if params := node.ChildByFieldName("parameters"); params != nil {
+		for i := 0; i < int(params.NamedChildCount()); i++ {
+			param := params.NamedChild(i)
+			switch param.Type() {
+			case "typed_parameter":
+				// *args: T and **kwargs: T are tuples and dicts, not T.
+				declare(param.NamedChild(0), pythonAnnotatedType(param.ChildByFieldName("type"), content))
+			case "typed_default_parameter":
+				declare(param.ChildByFieldName("name"), pythonAnnotatedType(param.ChildByFieldName("type"), content))
+			}
+		}
+	}
+
+	var walk func(current *sitter.Node)
+	walk = func(current *sitter.Node) {
+		if current == nil {
+			return
+		}
+		switch current.Type() {
+		case "function_definition", "class_definition", "lambda":
+			return
+		case "assignment":
+			typeName := ""
+			if typeNode := current.ChildByFieldName("type"); typeNode != nil {
+				typeName = pythonAnnotatedType(typeNode, content)
+			} else if right := current.ChildByFieldName("right"); right != nil && right.Type() == "call" {
+				if fn := right.ChildByFieldName("function"); fn != nil && fn.Type() == "identifier" {
+					typeName = resultTypes[fn.Content(content)]
+				}
+			}
+			declare(current.ChildByFieldName("left"), typeName)
+		}
+		for i := 0; i < int(current.NamedChildCount()); i++ {
+			walk(current.NamedChild(i))
+		}
+	}
+	walk(node.ChildByFieldName("body"))
+	return types
+}
+
+// pythonAnnotatedType returns the class named by a type annotation: User,
+// models.User, "User", Optional[User] or User | None. Builtins, unions of
+// several classes and other generics yield "".
+func pythonAnnotatedType(node *sitter.Node, content []byte) string {
+	if node == nil {
+		return ""
 	}
+	switch node.Type() {
+	case "type":
+		if node.NamedChildCount() != 1 {
+			return ""
+		}
+		return pythonAnnotatedType(node.NamedChild(0), content)
+	case "identifier", "attribute":
+		if name := node.Content(content); !pythonBuiltinTypes[name] {
+			return name
+		}
+	case "string":
+		name := strings.Trim(node.Content(content), "\"'")
+		if name != "" && !strings.ContainsAny(name, "[]|, ") && !pythonBuiltinTypes[name] {
+			return name
+		}
+	case "generic_type":
+		if node.NamedChildCount() != 2 || node.NamedChild(0).Content(content) != "Optional" {
+			return ""
+		}
+		if params := node.NamedChild(1); params.NamedChildCount() == 1 {
+			return pythonAnnotatedType(params.NamedChild(0), content)
+		}
+	case "binary_operator":
+		left, right := node.ChildByFieldName("left"), node.ChildByFieldName("right")
+		if left == nil || right == nil {
+			return ""
+		}
+		if right.Type() == "none" {
+			return pythonAnnotatedType(left, content)
+		}
+		if left.Type() == "none" {
+			return pythonAnnotatedType(right, content)
+		}
+	}
+	return ""
+}
+
+// pythonClassBases returns the base classes in a class argument list,
+// skipping keyword arguments such as metaclass=.
+func pythonClassBases(superclasses *sitter.Node, content []byte) []parser.TypeRelation {
+	if superclasses == nil {
+		return nil
+	}
+	var bases []parser.TypeRelation
+	for i := 0; i < int(superclasses.NamedChildCount()); i++ {
+		base := superclasses.NamedChild(i)
+		if base.Type() == "subscript" { // Generic[T]
+			base = base.ChildByFieldName("value")
+		}
+		if base == nil || (base.Type() != "identifier" && base.Type() != "attribute") {
+			continue
+		}
+		bases = append(bases, parser.TypeRelation{Name: base.Content(content), Relation: parser.RelationInherit})
+	}
+	return bases
 }
 
 func (p *PythonParser) extractImport(node *sitter.Node, content []byte) ([]string, map[string]string) {
@@ -268,30 +577,36 @@ func (p *PythonParser) buildClassSignature(node *sitter.Node, content []byte) st
 	return sig
 }
 
-func (p *PythonParser) extractCalls(bodyNode *sitter.Node, content []byte) []parser.CallSite {
+func (p *PythonParser) extractCalls(bodyNode *sitter.Node, content []byte, localTypes map[string]string) []parser.CallSite {
+	if p.skip.SkipCalls {
+		return nil
+	}
 	if bodyNode == nil {
 		return nil
 	}
 
 	calls := make([]parser.CallSite, 0)
-	p.collectCalls(bodyNode, content, &calls)
+	p.collectCalls(bodyNode, content, localTypes, &calls)
 	return calls
 }
 
-func (p *PythonParser) collectCalls(node *sitter.Node, content []byte, calls *[]parser.CallSite) {
+func (p *PythonParser) collectCalls(node *sitter.Node, content []byte, localTypes map[string]string, calls *[]parser.CallSite) {
 	if node == nil {
 		return
 	}
 
 	if node.Type() == "call" {
 		callSite := p.extractCallSite(node, content)
+		if callSite.Receiver == "" && callSite.Qualifier != "" {
+			callSite.ReceiverType = localTypes[callSite.Qualifier]
+		}
 		if callSite.Name != "" {
 			*calls = append(*calls, callSite)
 		}
 	}
 
 	for i := 0; i < int(node.ChildCount()); i++ {
-		p.collectCalls(node.Child(i), content, calls)
+		p.collectCalls(node.Child(i), content, localTypes, calls)
 	}
 }
 
@@ -378,6 +693,20 @@ func fromImportAliasTarget(moduleName, symbolName string) string {
 	return moduleName + "#" + symbolName
 }
 
+// pythonDocstring returns the docstring opening a function or class body.
+func pythonDocstring(bodyNode *sitter.Node, content []byte) string {
+	if bodyNode == nil || bodyNode.ChildCount() == 0 {
+		return ""
+	}
+	firstStmt := bodyNode.Child(0)
+	if firstStmt.Type() == "expression_statement" && firstStmt.ChildCount() > 0 {
+		if expr := firstStmt.Child(0); expr.Type() == "string" {
+			return extractDocstring(expr.Content(content))
+		}
+	}
+	return ""
+}
+
 func extractDocstring(s string) string {
 	// Remove triple quotes and clean up
 	s = strings.TrimSpace(s)
@@ -0,0 +1,142 @@
+package languages
+
+import (
+	"strings"
+
+	"github.com/morozRed/skelly/internal/parser"
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// pythonRouteDecorators maps Flask and FastAPI route decorator methods to
+// their HTTP verb; route and api_route take theirs from methods= and
+// default to GET.
+var pythonRouteDecorators = map[string]string{
+	"route": "GET", "api_route": "GET",
+	"get": "GET", "post": "POST", "put": "PUT", "patch": "PATCH", "delete": "DELETE",
+	"head": "HEAD", "options": "OPTIONS",
+}
+
+// pythonRoutes returns the route symbols of the module's decorated view
+// functions (@app.route("/users/<int:id>", methods=["GET", "POST"]),
+// @router.get("/items/{id}")), prefixed by the url_prefix of a module-level
+// Blueprint or the prefix of an APIRouter they are registered on.
+func pythonRoutes(root *sitter.Node, content []byte) []parser.Symbol {
+	prefixes := make(map[string]string)
+	var routes []parser.Symbol
+	for i := 0; i < int(root.NamedChildCount()); i++ {
+		stmt := root.NamedChild(i)
+		switch stmt.Type() {
+		case "expression_statement":
+			if stmt.NamedChildCount() == 1 {
+				pythonRoutePrefix(stmt.NamedChild(0), content, prefixes)
+			}
+		case "decorated_definition":
+			definition := stmt.ChildByFieldName("definition")
+			if definition == nil || definition.Type() != "function_definition" {
+				continue
+			}
+			nameNode := definition.ChildByFieldName("name")
+			if nameNode == nil {
+				continue
+			}
+			for j := 0; j < int(stmt.NamedChildCount()); j++ {
+				if decorator := stmt.NamedChild(j); decorator.Type() == "decorator" {
+					routes = append(routes, pythonDecoratorRoutes(decorator, nameNode.Content(content), content, prefixes)...)
+				}
+			}
+		}
+	}
+	return routes
+}
+
+// pythonRoutePrefix records `bp = Blueprint("users", __name__,
+// url_prefix="/users")` and `router = APIRouter(prefix="/items")`.
+func pythonRoutePrefix(node *sitter.Node, content []byte, prefixes map[string]string) {
+	if node.Type() != "assignment" {
+		return
+	}
+	left, right := node.ChildByFieldName("left"), node.ChildByFieldName("right")
+	if left == nil || right == nil || left.Type() != "identifier" || right.Type() != "call" {
+		return
+	}
+	fn := right.ChildByFieldName("function")
+	if fn == nil {
+		return
+	}
+	keyword := ""
+	switch parser.InnermostName(fn.Content(content)) {
+	case "Blueprint":
+		keyword = "url_prefix"
+	case "APIRouter":
+		keyword = "prefix"
+	default:
+		return
+	}
+	if prefix, ok := stringLiteral(pythonKeywordArgument(right, keyword, content), content); ok {
+		prefixes[left.Content(content)] = prefix
+	}
+}
+
+// pythonDecoratorRoutes returns one route per method of a route decorator
+// on function name.
+func pythonDecoratorRoutes(decorator *sitter.Node, name string, content []byte, prefixes map[string]string) []parser.Symbol {
+	if decorator.NamedChildCount() != 1 || decorator.NamedChild(0).Type() != "call" {
+		return nil
+	}
+	call := decorator.NamedChild(0)
+	fn := call.ChildByFieldName("function")
+	if fn == nil || fn.Type() != "attribute" {
+		return nil
+	}
+	object, attribute := fn.ChildByFieldName("object"), fn.ChildByFieldName("attribute")
+	if object == nil || attribute == nil || object.Type() != "identifier" {
+		return nil
+	}
+	verb, ok := pythonRouteDecorators[attribute.Content(content)]
+	if !ok {
+		return nil
+	}
+	args := namedArguments(call.ChildByFieldName("arguments"))
+	if len(args) == 0 {
+		return nil
+	}
+	path, ok := stringLiteral(args[0], content)
+	if !ok || !strings.HasPrefix(path, "/") {
+		return nil
+	}
+	path = prefixRoutePath(prefixes[object.Content(content)], path)
+
+	var methods []string
+	if strings.HasSuffix(attribute.Content(content), "route") {
+		for _, element := range namedArguments(pythonKeywordArgument(call, "methods", content)) {
+			if method, ok := stringLiteral(element, content); ok {
+				methods = append(methods, strings.ToUpper(method))
+			}
+		}
+	}
+	if len(methods) == 0 {
+		methods = []string{verb}
+	}
+
+	routes := make([]parser.Symbol, 0, len(methods))
+	for _, method := range methods {
+		routes = append(routes, routeSymbol(method, path, decorator, content, &parser.CallSite{
+			Name: name,
+			Raw:  name,
+		}))
+	}
+	return routes
+}
+
+// pythonKeywordArgument returns the value of keyword argument name in a call.
+func pythonKeywordArgument(call *sitter.Node, name string, content []byte) *sitter.Node {
+	for _, arg := range namedArguments(call.ChildByFieldName("arguments")) {
+		if arg.Type() != "keyword_argument" {
+			continue
+		}
+		if key := arg.ChildByFieldName("name"); key != nil && key.Content(content) == name {
+			return arg.ChildByFieldName("value")
+		}
+	}
+	return nil
+}
@@ -1,6 +1,11 @@
 package languages
 
-import "testing"
+import (
+	"strings"
+	"testing"
+
+	"github.com/morozRed/skelly/internal/parser"
+)
 
 func TestPythonFromImportCapturesAliasedMembers(t *testing.T) {
 	parser := NewPythonParser()
@@ -30,3 +35,234 @@ def run():
 		t.Fatalf("did not expect original name foo for aliased import")
 	}
 }
+
+func TestPythonParserRecordsMethodContainers(t *testing.T) {
+	file, err := NewPythonParser().Parse("models.py", []byte(`class User:
+    def save(self):
+        self.validate()
+
+    def validate(self):
+        pass
+
+    class Meta:
+        def table(self):
+            pass
+
+def save():
+    pass
+`))
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	got := make(map[string]bool)
+	for _, symbol := range file.Symbols {
+		got[symbol.QualifiedName()] = true
+	}
+	for _, name := range []string{"User", "User.save", "User.validate", "User.Meta", "User.Meta.table", "save"} {
+		if !got[name] {
+			t.Fatalf("expected qualified symbol %q, got %#v", name, got)
+		}
+	}
+}
+
+func TestPythonParserRecordsClassBases(t *testing.T) {
+	file, err := NewPythonParser().Parse("models.py", []byte(`class User(models.Model, Generic[T], metaclass=Meta):
+    pass
+`))
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	bases := file.Symbols[0].Bases
+	if len(bases) != 2 || bases[0].Name != "models.Model" || bases[1].Name != "Generic" {
+		t.Fatalf("expected bases models.Model and Generic, got %#v", bases)
+	}
+}
+
+func TestPythonParserRecordsTypeHintReferences(t *testing.T) {
+	file, err := NewPythonParser().Parse("orders.py", []byte(`class Order:
+    owner: User
+    items: list[models.Item] = []
+
+    def total(self, discount: Optional[Discount], note: "Later" = None) -> Money:
+        receipt: Receipt = build()
+        return receipt.sum
+`))
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	want := map[string]string{
+		"Order": "User,models.Item",
+		"total": "Discount,Money,Receipt",
+	}
+	for _, symbol := range file.Symbols {
+		refs, ok := want[symbol.Name]
+		if !ok {
+			continue
+		}
+		if got := strings.Join(symbol.References, ","); got != refs {
+			t.Fatalf("expected %s to reference %s, got %s", symbol.Name, refs, got)
+		}
+	}
+}
+
+func TestPythonParserInfersReceiverTypesFromAnnotations(t *testing.T) {
+	file, err := NewPythonParser().Parse("checkout.py", []byte(`def load_order(order_id) -> "Order":
+    return Order(order_id)
+
+def checkout(user: User, coupon: Optional[Coupon] = None, repo: models.Repo | None = None, *carts: Cart):
+    order = load_order(1)
+    receipt: Receipt = order.close()
+    user.notify()
+    coupon.apply()
+    repo.save()
+    receipt.print()
+    carts.clear()
+    helper = make()
+    helper.run()
+`))
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	var checkout *parser.Symbol
+	for i := range file.Symbols {
+		if file.Symbols[i].Name == "checkout" {
+			checkout = &file.Symbols[i]
+		}
+	}
+	if checkout == nil {
+		t.Fatalf("expected checkout symbol, got %#v", file.Symbols)
+	}
+
+	want := map[string]string{
+		"close":  "Order",
+		"notify": "User",
+		"apply":  "Coupon",
+		"save":   "models.Repo",
+		"print":  "Receipt",
+		"clear":  "",
+		"run":    "",
+	}
+	for _, call := range checkout.Calls {
+		expected, ok := want[call.Name]
+		if !ok {
+			continue
+		}
+		if call.ReceiverType != expected {
+			t.Fatalf("expected %s.%s receiver type %q, got %q", call.Qualifier, call.Name, expected, call.ReceiverType)
+		}
+		delete(want, call.Name)
+	}
+	if len(want) != 0 {
+		t.Fatalf("expected calls %v to be recorded", want)
+	}
+}
+
+func TestPythonParserExtractsFlaskAndFastAPIRoutes(t *testing.T) {
+	file, err := NewPythonParser().Parse("views.py", []byte(`from flask import Blueprint
+from fastapi import APIRouter
+
+bp = Blueprint("users", __name__, url_prefix="/users")
+router = APIRouter(prefix="/items")
+
+@bp.route("/<int:user_id>", methods=["GET", "POST"])
+def user(user_id):
+    return load(user_id)
+
+@bp.route("/")
+def index():
+    pass
+
+@router.delete("/{item_id}")
+async def remove_item(item_id: int):
+    pass
+
+@pytest.mark.parametrize("x", [1])
+def test_x(x):
+    pass
+`))
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	var got []string
+	for _, symbol := range file.Symbols {
+		if symbol.Kind == parser.SymbolRoute && len(symbol.Calls) == 1 {
+			got = append(got, symbol.Name+" -> "+symbol.Calls[0].Name)
+		}
+	}
+	want := []string{
+		"GET /users/<int:user_id> -> user",
+		"POST /users/<int:user_id> -> user",
+		"GET /users -> index",
+		"DELETE /items/{item_id} -> remove_item",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("unexpected routes:\n%s", strings.Join(got, "\n"))
+	}
+}
+
+func TestPythonParserRecordsSymbolSpans(t *testing.T) {
+	content := []byte(`class User:
+    def save(self):
+        self.validate()
+        return True
+`)
+	file, err := NewPythonParser().Parse("models.py", content)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	for _, symbol := range file.Symbols {
+		if symbol.QualifiedName() != "User.save" {
+			continue
+		}
+		if symbol.Line != 2 || symbol.EndLine != 4 || symbol.Body(content) != "def save(self):\n        self.validate()\n        return True" {
+			t.Fatalf("unexpected span: lines %d-%d, body %q", symbol.Line, symbol.EndLine, symbol.Body(content))
+		}
+		return
+	}
+	t.Fatalf("expected User.save in %#v", file.Symbols)
+}
+
+func TestPythonParserExtractsModuleConstantsAndVariables(t *testing.T) {
+	file, err := NewPythonParser().Parse("settings.py", []byte(`__all__ = ["MAX_RETRIES"]
+MAX_RETRIES = 3
+timeout: float = 2.5
+host, port = "localhost", 8080
+_cache = build_cache()
+if DEBUG:
+    MAX_RETRIES = 10
+
+class Client:
+    retries = 1
+
+    def connect(self):
+        attempt = 0
+`))
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	got := make([]string, 0)
+	for _, symbol := range file.Symbols {
+		if symbol.Kind == parser.SymbolConstant || symbol.Kind == parser.SymbolVariable {
+			got = append(got, symbol.Kind.String()+" "+symbol.Signature+" "+symbol.Visibility)
+		}
+		if symbol.Name == "_cache" && (len(symbol.Calls) != 1 || symbol.Calls[0].Name != "build_cache") {
+			t.Fatalf("expected _cache to call build_cache, got %+v", symbol.Calls)
+		}
+	}
+	want := []string{
+		"const MAX_RETRIES = 3 public",
+		"var timeout: float = 2.5 public",
+		`var host = "localhost" public`,
+		"var port = 8080 public",
+		"var _cache = build_cache() private",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("unexpected constants and variables:\n%s", strings.Join(got, "\n"))
+	}
+}
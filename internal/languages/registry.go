@@ -10,6 +10,13 @@ func NewDefaultRegistry() *parser.Registry {
 	r.Register(NewPythonParser())
 	r.Register(NewRubyParser())
 	r.Register(NewTypeScriptParser())
+	r.Register(NewRustParser())
+	r.Register(NewJavaParser())
+	r.Register(NewCParser())
+	r.Register(NewCppParser())
+	r.Register(NewPHPParser())
+	r.Register(NewCSharpParser())
+	r.Register(NewProtoParser())
 
 	return r
 }
@@ -0,0 +1,55 @@
+package languages
+
+import (
+	"strings"
+
+	"github.com/morozRed/skelly/internal/parser"
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// HTTP route registrations (net/http, gin, echo, chi, Express, Flask,
+// FastAPI and Rails routes) become SymbolRoute symbols named by method and
+// path. A route calls its handler when the handler is a named function or
+// method; inline handlers leave the route without calls.
+
+// routeSymbol builds the route symbol for a registration call. Its signature
+// is the first line of the registration.
+func routeSymbol(method, path string, call *sitter.Node, content []byte, handler *parser.CallSite) parser.Symbol {
+	signature, _, _ := strings.Cut(strings.TrimSpace(call.Content(content)), "\n")
+	route := parser.Symbol{
+		Name:      method + " " + path,
+		Kind:      parser.SymbolRoute,
+		Signature: strings.TrimSpace(strings.TrimSuffix(signature, " do")),
+		Line:      int(call.StartPoint().Row) + 1,
+		Span:      symbolSpan(call),
+	}
+	if handler != nil {
+		handler.Line = route.Line
+		route.Calls = []parser.CallSite{*handler}
+	}
+	return route
+}
+
+// prefixRoutePath joins a router group or blueprint prefix to a route path,
+// keeping the path as written when there is no prefix.
+func prefixRoutePath(prefix, path string) string {
+	if prefix == "" {
+		if path == "" {
+			return "/"
+		}
+		return path
+	}
+	return joinRoutePath(prefix, path)
+}
+
+func joinRoutePath(prefix, path string) string {
+	prefix = strings.TrimSuffix(prefix, "/")
+	path = strings.Trim(path, "/")
+	if path == "" {
+		if prefix == "" {
+			return "/"
+		}
+		return prefix
+	}
+	return prefix + "/" + path
+}
@@ -11,6 +11,7 @@ import (
 
 // RubyParser implements parsing for Ruby source files
 type RubyParser struct {
+	extractions
 	parser *sitter.Parser
 }
 
@@ -30,6 +31,7 @@ func (r *RubyParser) Extensions() []string {
 }
 
 func (r *RubyParser) Parse(filename string, content []byte) (*parser.FileSymbols, error) {
+	r.begin("ruby")
 	tree, err := r.parser.ParseCtx(context.Background(), nil, content)
 	if err != nil {
 		return nil, err
@@ -47,14 +49,136 @@ func (r *RubyParser) Parse(filename string, content []byte) (*parser.FileSymbols
 	root := tree.RootNode()
 	r.extractSymbols(root, content, result, "", "")
 
+	visibilities := make(map[int]string)
+	rubyVisibilities(root, content, visibilities)
+	for i := range result.Symbols {
+		sym := &result.Symbols[i]
+		switch sym.Kind {
+		case parser.SymbolFunction, parser.SymbolMethod, parser.SymbolClass, parser.SymbolModule, parser.SymbolConstant:
+			sym.Visibility = parser.VisibilityPublic
+			if visibility, ok := visibilities[sym.StartByte]; ok {
+				sym.Visibility = visibility
+			}
+		}
+	}
+
 	return result, nil
 }
 
+// rubyVisibilities records, by start byte, the visibility of each method or
+// constant defined in a class or module body that is not public.
+func rubyVisibilities(node *sitter.Node, content []byte, visibilities map[int]string) {
+	switch node.Type() {
+	case "class", "module", "singleton_class":
+		if bodyNode := node.ChildByFieldName("body"); bodyNode != nil {
+			rubyBodyVisibilities(bodyNode, content, visibilities)
+		}
+	}
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		rubyVisibilities(node.NamedChild(i), content, visibilities)
+	}
+}
+
+// rubyBodyVisibilities applies the visibility rules of one body: a bare
+// private, protected or public covers the methods defined after it, `private
+// def name` covers the method it wraps, and `private :name` covers the method
+// it names wherever it is defined. Singleton methods stay public unless named
+// by private_class_method. Constants are public unless named by
+// private_constant.
+func rubyBodyVisibilities(bodyNode *sitter.Node, content []byte, visibilities map[int]string) {
+	section := parser.VisibilityPublic
+	named := make(map[string]string)
+	var methods []*sitter.Node
+	constants := make(map[string]int)
+	privateConstants := make([]string, 0)
+	for i := 0; i < int(bodyNode.NamedChildCount()); i++ {
+		child := bodyNode.NamedChild(i)
+		switch child.Type() {
+		case "identifier":
+			if visibility, ok := rubyVisibilityKeyword(child.Content(content)); ok {
+				section = visibility
+			}
+		case "method":
+			methods = append(methods, child)
+			visibilities[int(child.StartByte())] = section
+		case "singleton_method":
+			methods = append(methods, child)
+		case "assignment":
+			if left := child.ChildByFieldName("left"); left != nil && left.Type() == "constant" {
+				constants[left.Content(content)] = int(child.StartByte())
+			}
+		case "call":
+			methodNode := child.ChildByFieldName("method")
+			args := child.ChildByFieldName("arguments")
+			if child.ChildByFieldName("receiver") != nil || methodNode == nil || args == nil {
+				continue
+			}
+			keyword := methodNode.Content(content)
+			if keyword == "private_constant" {
+				for j := 0; j < int(args.NamedChildCount()); j++ {
+					if arg := args.NamedChild(j); arg.Type() == "simple_symbol" {
+						privateConstants = append(privateConstants, strings.TrimPrefix(arg.Content(content), ":"))
+					}
+				}
+				continue
+			}
+			visibility, ok := rubyVisibilityKeyword(keyword)
+			prefix := ""
+			if keyword == "private_class_method" {
+				visibility, ok, prefix = parser.VisibilityPrivate, true, "self."
+			}
+			if !ok {
+				continue
+			}
+			for j := 0; j < int(args.NamedChildCount()); j++ {
+				arg := args.NamedChild(j)
+				switch arg.Type() {
+				case "simple_symbol":
+					named[prefix+strings.TrimPrefix(arg.Content(content), ":")] = visibility
+				case "method", "singleton_method":
+					visibilities[int(arg.StartByte())] = visibility
+				}
+			}
+		}
+	}
+	for _, method := range methods {
+		nameNode := method.ChildByFieldName("name")
+		if nameNode == nil {
+			continue
+		}
+		name := nameNode.Content(content)
+		if method.Type() == "singleton_method" {
+			name = "self." + name
+		}
+		if visibility, ok := named[name]; ok {
+			visibilities[int(method.StartByte())] = visibility
+		}
+	}
+	for _, name := range privateConstants {
+		if start, ok := constants[name]; ok {
+			visibilities[start] = parser.VisibilityPrivate
+		}
+	}
+}
+
+func rubyVisibilityKeyword(keyword string) (string, bool) {
+	switch keyword {
+	case "public":
+		return parser.VisibilityPublic, true
+	case "protected":
+		return parser.VisibilityProtected, true
+	case "private":
+		return parser.VisibilityPrivate, true
+	}
+	return "", false
+}
+
 func (r *RubyParser) extractSymbols(node *sitter.Node, content []byte, result *parser.FileSymbols, modulePath string, className string) {
 	switch node.Type() {
 	case "method":
 		sym := r.extractMethod(node, content, className)
 		if sym != nil {
+			sym.Container = rubyContainer(modulePath, className)
 			result.Symbols = append(result.Symbols, *sym)
 		}
 		return
@@ -62,6 +186,7 @@ func (r *RubyParser) extractSymbols(node *sitter.Node, content []byte, result *p
 	case "singleton_method":
 		sym := r.extractSingletonMethod(node, content, className)
 		if sym != nil {
+			sym.Container = rubyContainer(modulePath, className)
 			result.Symbols = append(result.Symbols, *sym)
 		}
 		return
@@ -69,14 +194,21 @@ func (r *RubyParser) extractSymbols(node *sitter.Node, content []byte, result *p
 	case "class":
 		sym := r.extractClass(node, content, modulePath)
 		if sym != nil {
+			sym.Container = className
+			if sym.Container == "" {
+				sym.Container = modulePath
+			}
+			newClassName := sym.Name
+			if sym.Container != "" {
+				newClassName = sym.Container + "::" + sym.Name
+			}
+			bodyNode := node.ChildByFieldName("body")
+			if !r.skip.SkipCalls {
+				sym.Calls = rubyCallbacks(bodyNode, content, newClassName)
+			}
 			result.Symbols = append(result.Symbols, *sym)
 			// Recurse into class body
-			bodyNode := node.ChildByFieldName("body")
 			if bodyNode != nil {
-				newClassName := sym.Name
-				if modulePath != "" {
-					newClassName = modulePath + "::" + sym.Name
-				}
 				for i := 0; i < int(bodyNode.ChildCount()); i++ {
 					r.extractSymbols(bodyNode.Child(i), content, result, modulePath, newClassName)
 				}
@@ -87,6 +219,7 @@ func (r *RubyParser) extractSymbols(node *sitter.Node, content []byte, result *p
 	case "module":
 		sym := r.extractModule(node, content, modulePath)
 		if sym != nil {
+			sym.Container = modulePath
 			result.Symbols = append(result.Symbols, *sym)
 			// Recurse into module body
 			bodyNode := node.ChildByFieldName("body")
@@ -102,7 +235,24 @@ func (r *RubyParser) extractSymbols(node *sitter.Node, content []byte, result *p
 		}
 		return
 
+	case "assignment":
+		if sym := r.extractConstant(node, content); sym != nil {
+			sym.Container = rubyContainer(modulePath, className)
+			result.Symbols = append(result.Symbols, *sym)
+		}
+
 	case "call":
+		if className != "" {
+			if sym := r.extractRailsMacro(node, content); sym != nil {
+				sym.Container = className
+				result.Symbols = append(result.Symbols, *sym)
+				return
+			}
+		}
+		if isRailsRoutesDraw(node, content) {
+			result.Symbols = append(result.Symbols, extractRailsRoutes(node.ChildByFieldName("block"), content)...)
+			return
+		}
 		// Check for require/require_relative
 		methodNode := node.ChildByFieldName("method")
 		if methodNode != nil {
@@ -135,6 +285,39 @@ func (r *RubyParser) extractSymbols(node *sitter.Node, content []byte, result *p
 	}
 }
 
+// rubyContainer is the class a method is defined in, or the module for
+// module functions.
+func rubyContainer(modulePath, className string) string {
+	if className != "" {
+		return className
+	}
+	return modulePath
+}
+
+// extractConstant returns the constant symbol of an assignment to a bare
+// constant (MAX_RETRIES = 3); other assignments bind no symbol.
+func (r *RubyParser) extractConstant(node *sitter.Node, content []byte) *parser.Symbol {
+	left := node.ChildByFieldName("left")
+	if left == nil || left.Type() != "constant" {
+		return nil
+	}
+
+	name := left.Content(content)
+	signature := name
+	right := node.ChildByFieldName("right")
+	if right != nil {
+		signature = valueSignature(name, right.Content(content))
+	}
+	return &parser.Symbol{
+		Name:      name,
+		Kind:      parser.SymbolConstant,
+		Signature: signature,
+		Line:      int(node.StartPoint().Row) + 1,
+		Span:      symbolSpan(node),
+		Calls:     r.extractCalls(right, content),
+	}
+}
+
 func (r *RubyParser) extractMethod(node *sitter.Node, content []byte, className string) *parser.Symbol {
 	nameNode := node.ChildByFieldName("name")
 	if nameNode == nil {
@@ -155,6 +338,7 @@ func (r *RubyParser) extractMethod(node *sitter.Node, content []byte, className
 		Kind:      kind,
 		Signature: sig,
 		Line:      int(node.StartPoint().Row) + 1,
+		Span:      symbolSpan(node),
 		Calls:     r.extractCalls(bodyNode, content),
 	}
 }
@@ -174,6 +358,7 @@ func (r *RubyParser) extractSingletonMethod(node *sitter.Node, content []byte, c
 		Kind:      parser.SymbolMethod,
 		Signature: sig,
 		Line:      int(node.StartPoint().Row) + 1,
+		Span:      symbolSpan(node),
 		Calls:     r.extractCalls(bodyNode, content),
 	}
 }
@@ -187,12 +372,54 @@ func (r *RubyParser) extractClass(node *sitter.Node, content []byte, modulePath
 	name := nameNode.Content(content)
 	sig := r.buildClassSignature(node, content)
 
+	var bases []parser.TypeRelation
+	if superclassNode := node.ChildByFieldName("superclass"); superclassNode != nil && superclassNode.NamedChildCount() > 0 {
+		bases = append(bases, parser.TypeRelation{
+			Name:     superclassNode.NamedChild(0).Content(content),
+			Relation: parser.RelationInherit,
+		})
+	}
+
 	return &parser.Symbol{
 		Name:      name,
 		Kind:      parser.SymbolClass,
 		Signature: sig,
 		Line:      int(node.StartPoint().Row) + 1,
+		Span:      symbolSpan(node),
+		Bases:     append(bases, rubyMixins(node.ChildByFieldName("body"), content)...),
+	}
+}
+
+// rubyMixins returns the modules a class or module body mixes in with
+// include, extend or prepend.
+func rubyMixins(bodyNode *sitter.Node, content []byte) []parser.TypeRelation {
+	if bodyNode == nil {
+		return nil
 	}
+	var mixins []parser.TypeRelation
+	for i := 0; i < int(bodyNode.NamedChildCount()); i++ {
+		call := bodyNode.NamedChild(i)
+		if call.Type() != "call" || call.ChildByFieldName("receiver") != nil {
+			continue
+		}
+		methodNode := call.ChildByFieldName("method")
+		args := call.ChildByFieldName("arguments")
+		if methodNode == nil || args == nil {
+			continue
+		}
+		switch methodNode.Content(content) {
+		case "include", "extend", "prepend":
+		default:
+			continue
+		}
+		for j := 0; j < int(args.NamedChildCount()); j++ {
+			arg := args.NamedChild(j)
+			if arg.Type() == "constant" || arg.Type() == "scope_resolution" {
+				mixins = append(mixins, parser.TypeRelation{Name: arg.Content(content), Relation: parser.RelationInherit})
+			}
+		}
+	}
+	return mixins
 }
 
 func (r *RubyParser) extractModule(node *sitter.Node, content []byte, modulePath string) *parser.Symbol {
@@ -208,6 +435,8 @@ func (r *RubyParser) extractModule(node *sitter.Node, content []byte, modulePath
 		Kind:      parser.SymbolModule,
 		Signature: "module " + name,
 		Line:      int(node.StartPoint().Row) + 1,
+		Span:      symbolSpan(node),
+		Bases:     rubyMixins(node.ChildByFieldName("body"), content),
 	}
 }
 
@@ -254,6 +483,9 @@ func extractRubyString(s string) string {
 }
 
 func (r *RubyParser) extractCalls(bodyNode *sitter.Node, content []byte) []parser.CallSite {
+	if r.skip.SkipCalls {
+		return nil
+	}
 	if bodyNode == nil {
 		return nil
 	}
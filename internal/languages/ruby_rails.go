@@ -0,0 +1,458 @@
+package languages
+
+import (
+	"strings"
+
+	"github.com/morozRed/skelly/internal/parser"
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// Rails declares much of an application through class-level macros and a
+// routes DSL rather than method definitions. The helpers here turn the common
+// ones into symbols and calls so associations, scopes, callbacks and routed
+// controller actions show up in the graph.
+
+// railsAssociations maps association macros to whether their name is plural.
+var railsAssociations = map[string]bool{
+	"has_many":                true,
+	"has_and_belongs_to_many": true,
+	"has_one":                 false,
+	"belongs_to":              false,
+}
+
+// railsResourceActions lists the actions `resources` and `resource` route,
+// with the HTTP verb and the path suffix after the collection or member path.
+var railsResourceActions = []struct {
+	action string
+	verb   string
+	member bool
+	suffix string
+}{
+	{"index", "GET", false, ""},
+	{"create", "POST", false, ""},
+	{"new", "GET", false, "/new"},
+	{"edit", "GET", true, "/edit"},
+	{"show", "GET", true, ""},
+	{"update", "PATCH", true, ""},
+	{"destroy", "DELETE", true, ""},
+}
+
+var railsRouteVerbs = map[string]bool{
+	"get": true, "post": true, "put": true, "patch": true, "delete": true,
+}
+
+// extractRailsMacro returns the symbol declared by an association
+// (`has_many :orders` becomes an `orders` method referencing Order) or a
+// scope (`scope :active, -> { ... }` becomes `self.active`), or nil.
+func (r *RubyParser) extractRailsMacro(node *sitter.Node, content []byte) *parser.Symbol {
+	methodNode := node.ChildByFieldName("method")
+	args := node.ChildByFieldName("arguments")
+	if methodNode == nil || args == nil || args.NamedChildCount() == 0 || node.ChildByFieldName("receiver") != nil {
+		return nil
+	}
+	macro := methodNode.Content(content)
+	first := args.NamedChild(0)
+	if first.Type() != "simple_symbol" {
+		return nil
+	}
+	name := strings.TrimPrefix(first.Content(content), ":")
+	line := int(node.StartPoint().Row) + 1
+
+	if macro == "scope" {
+		return &parser.Symbol{
+			Name:      "self." + name,
+			Kind:      parser.SymbolMethod,
+			Signature: "scope :" + name,
+			Line:      line,
+			Span:      symbolSpan(node),
+			Calls:     r.extractCalls(args, content),
+		}
+	}
+
+	plural, ok := railsAssociations[macro]
+	if !ok {
+		return nil
+	}
+	target := name
+	if plural {
+		target = singularize(target)
+	}
+	target = camelize(target)
+	if className := rubyOption(args, "class_name", content); className != nil {
+		target = extractRubyString(className.Content(content))
+	}
+	if rubyOption(args, "polymorphic", content) != nil {
+		target = ""
+	}
+	sym := &parser.Symbol{
+		Name:      name,
+		Kind:      parser.SymbolMethod,
+		Signature: macro + " :" + name,
+		Line:      line,
+		Span:      symbolSpan(node),
+	}
+	if target != "" && !r.skip.SkipReferences {
+		sym.References = []string{target}
+	}
+	return sym
+}
+
+// rubyCallbacks returns the methods a class body registers as callbacks
+// (`before_action :authenticate_user!`, `after_save :reindex`,
+// `validate :check_total`), as calls qualified by the class.
+func rubyCallbacks(bodyNode *sitter.Node, content []byte, className string) []parser.CallSite {
+	if bodyNode == nil {
+		return nil
+	}
+	var calls []parser.CallSite
+	for i := 0; i < int(bodyNode.NamedChildCount()); i++ {
+		call := bodyNode.NamedChild(i)
+		if call.Type() != "call" || call.ChildByFieldName("receiver") != nil {
+			continue
+		}
+		methodNode := call.ChildByFieldName("method")
+		args := call.ChildByFieldName("arguments")
+		if methodNode == nil || args == nil || !isRailsCallback(methodNode.Content(content)) {
+			continue
+		}
+		macro := methodNode.Content(content)
+		for j := 0; j < int(args.NamedChildCount()); j++ {
+			arg := args.NamedChild(j)
+			if arg.Type() != "simple_symbol" {
+				continue
+			}
+			name := strings.TrimPrefix(arg.Content(content), ":")
+			calls = append(calls, parser.CallSite{
+				Name:      name,
+				Qualifier: className,
+				Raw:       macro + " :" + name,
+				Line:      int(call.StartPoint().Row) + 1,
+			})
+		}
+	}
+	return calls
+}
+
+func isRailsCallback(macro string) bool {
+	if macro == "validate" {
+		return true
+	}
+	for _, prefix := range []string{"before_", "after_", "around_", "prepend_before_", "append_before_"} {
+		if strings.HasPrefix(macro, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// railsRouteScope is the routing context of a block in routes.rb.
+type railsRouteScope struct {
+	path           string // path prefix, "/admin/users/:user_id"
+	module         string // controller namespace, "admin/"
+	controller     string // enclosing resource's controller, "admin/users"
+	actionPath     string // path `get :preview` is added to
+	memberPath     string // enclosing resource's member path, "/admin/users/:id"
+	collectionPath string // enclosing resource's collection path, "/admin/users"
+}
+
+// isRailsRoutesDraw reports whether a call is `Rails.application.routes.draw`.
+func isRailsRoutesDraw(node *sitter.Node, content []byte) bool {
+	methodNode := node.ChildByFieldName("method")
+	receiver := node.ChildByFieldName("receiver")
+	return methodNode != nil && receiver != nil && methodNode.Content(content) == "draw" &&
+		strings.HasSuffix(receiver.Content(content), "routes")
+}
+
+// extractRailsRoutes returns one route symbol per route declared in a
+// routes.draw block, named by verb and path ("GET /users/:id") and calling
+// the controller action it dispatches to (UsersController.show).
+func extractRailsRoutes(block *sitter.Node, content []byte) []parser.Symbol {
+	var routes []parser.Symbol
+	collectRailsRoutes(block, content, railsRouteScope{}, &routes)
+	return routes
+}
+
+func collectRailsRoutes(node *sitter.Node, content []byte, scope railsRouteScope, routes *[]parser.Symbol) {
+	body := railsBlockBody(node)
+	if body == nil {
+		return
+	}
+	for i := 0; i < int(body.NamedChildCount()); i++ {
+		call := body.NamedChild(i)
+		if call.Type() != "call" || call.ChildByFieldName("receiver") != nil {
+			continue
+		}
+		methodNode := call.ChildByFieldName("method")
+		if methodNode == nil {
+			continue
+		}
+		args := call.ChildByFieldName("arguments")
+		block := call.ChildByFieldName("block")
+
+		switch method := methodNode.Content(content); method {
+		case "namespace":
+			name := railsFirstName(args, content)
+			if name == "" {
+				continue
+			}
+			collectRailsRoutes(block, content, railsRouteScope{
+				path:   scope.path + "/" + name,
+				module: scope.module + name + "/",
+			}, routes)
+
+		case "scope":
+			path := railsFirstName(args, content)
+			if path == "" {
+				continue
+			}
+			nested := scope
+			nested.path = joinRoutePath(scope.path, path)
+			collectRailsRoutes(block, content, nested, routes)
+
+		case "member", "collection":
+			if scope.controller == "" {
+				continue
+			}
+			nested := scope
+			nested.actionPath = scope.memberPath
+			if method == "collection" {
+				nested.actionPath = scope.collectionPath
+			}
+			collectRailsRoutes(block, content, nested, routes)
+
+		case "resources", "resource":
+			for _, name := range railsNames(args, content) {
+				appendRailsResource(call, content, scope, name, method == "resource", routes)
+			}
+
+		case "root":
+			target := railsFirstName(args, content)
+			if to := rubyOption(args, "to", content); to != nil {
+				target = extractRubyString(to.Content(content))
+			}
+			appendRailsRoute(call, content, scope, "GET", joinRoutePath(scope.path, "/"), target, routes)
+
+		default:
+			if railsRouteVerbs[method] {
+				appendRailsVerbRoute(call, content, scope, strings.ToUpper(method), routes)
+			}
+		}
+	}
+}
+
+// appendRailsResource adds the routes of `resources :users` (or the singular
+// `resource :profile`), restricted by only:/except:, then those declared in
+// its block.
+func appendRailsResource(call *sitter.Node, content []byte, scope railsRouteScope, name string, singular bool, routes *[]parser.Symbol) {
+	args := call.ChildByFieldName("arguments")
+	controller := scope.module + name
+	collectionPath := scope.path + "/" + name
+	memberPath := collectionPath + "/:id"
+	nestedPath := collectionPath + "/:" + singularize(name) + "_id"
+	if singular {
+		controller = scope.module + pluralize(name)
+		memberPath = collectionPath
+		nestedPath = collectionPath
+	}
+
+	only := railsNames(rubyOption(args, "only", content), content)
+	except := railsNames(rubyOption(args, "except", content), content)
+	for _, route := range railsResourceActions {
+		if singular && route.action == "index" {
+			continue
+		}
+		if (len(only) > 0 && !containsString(only, route.action)) || containsString(except, route.action) {
+			continue
+		}
+		path := collectionPath
+		if route.member {
+			path = memberPath
+		}
+		appendRailsRoute(call, content, scope, route.verb, path+route.suffix, controller+"#"+route.action, routes)
+	}
+
+	collectRailsRoutes(call.ChildByFieldName("block"), content, railsRouteScope{
+		path:           nestedPath,
+		module:         scope.module,
+		controller:     controller,
+		actionPath:     nestedPath,
+		memberPath:     memberPath,
+		collectionPath: collectionPath,
+	}, routes)
+}
+
+// appendRailsVerbRoute adds `get "/about", to: "pages#about"`,
+// `post "login" => "sessions#create"`, `get "photos/search"` or, inside a
+// resource, `get :preview`.
+func appendRailsVerbRoute(call *sitter.Node, content []byte, scope railsRouteScope, verb string, routes *[]parser.Symbol) {
+	args := call.ChildByFieldName("arguments")
+	if args == nil || args.NamedChildCount() == 0 {
+		return
+	}
+	first := args.NamedChild(0)
+	path, target := "", ""
+	switch first.Type() {
+	case "pair": // "login" => "sessions#create"
+		if key, value := first.ChildByFieldName("key"), first.ChildByFieldName("value"); key != nil && value != nil {
+			path, target = extractRubyString(key.Content(content)), extractRubyString(value.Content(content))
+		}
+	case "simple_symbol":
+		action := strings.TrimPrefix(first.Content(content), ":")
+		if scope.controller != "" {
+			path, target = scope.actionPath+"/"+action, scope.controller+"#"+action
+		} else {
+			path = action
+		}
+	default:
+		path = extractRubyString(first.Content(content))
+	}
+	if path == "" {
+		return
+	}
+	if to := rubyOption(args, "to", content); to != nil {
+		target = extractRubyString(to.Content(content))
+	} else if target == "" {
+		// get "photos/search" routes to photos#search.
+		segments := strings.Split(strings.Trim(path, "/"), "/")
+		if len(segments) >= 2 && !strings.HasPrefix(segments[len(segments)-1], ":") {
+			target = segments[len(segments)-2] + "#" + segments[len(segments)-1]
+		}
+	}
+	if first.Type() != "simple_symbol" || scope.controller == "" {
+		path = joinRoutePath(scope.path, path)
+	}
+	appendRailsRoute(call, content, scope, verb, path, target, routes)
+}
+
+// appendRailsRoute adds a route symbol calling the controller#action in
+// target, resolved against the scope's controller namespace.
+func appendRailsRoute(call *sitter.Node, content []byte, scope railsRouteScope, verb, path, target string, routes *[]parser.Symbol) {
+	var handler *parser.CallSite
+	if controller, action, ok := strings.Cut(target, "#"); ok && controller != "" && action != "" {
+		if !strings.HasPrefix(controller, scope.module) {
+			controller = scope.module + controller
+		}
+		handler = &parser.CallSite{
+			Name:      action,
+			Qualifier: camelize(controller) + "Controller",
+			Raw:       target,
+		}
+	}
+	*routes = append(*routes, routeSymbol(verb, path, call, content, handler))
+}
+
+// railsBlockBody returns the statements of a do/brace block.
+func railsBlockBody(block *sitter.Node) *sitter.Node {
+	if block == nil {
+		return nil
+	}
+	for i := 0; i < int(block.NamedChildCount()); i++ {
+		if child := block.NamedChild(i); child.Type() == "body_statement" || child.Type() == "block_body" {
+			return child
+		}
+	}
+	return nil
+}
+
+// rubyOption returns the value of a keyword option (only: [...]) in an
+// argument list, written with either hash syntax.
+func rubyOption(args *sitter.Node, key string, content []byte) *sitter.Node {
+	if args == nil {
+		return nil
+	}
+	for i := 0; i < int(args.NamedChildCount()); i++ {
+		pair := args.NamedChild(i)
+		if pair.Type() != "pair" {
+			continue
+		}
+		keyNode := pair.ChildByFieldName("key")
+		if keyNode != nil && strings.Trim(keyNode.Content(content), `:"'`) == key {
+			return pair.ChildByFieldName("value")
+		}
+	}
+	return nil
+}
+
+// railsNames returns the symbol and string names in an argument list, an
+// array or a single value.
+func railsNames(node *sitter.Node, content []byte) []string {
+	if node == nil {
+		return nil
+	}
+	switch node.Type() {
+	case "simple_symbol":
+		return []string{strings.TrimPrefix(node.Content(content), ":")}
+	case "string":
+		return []string{extractRubyString(node.Content(content))}
+	case "argument_list", "array":
+		var names []string
+		for i := 0; i < int(node.NamedChildCount()); i++ {
+			child := node.NamedChild(i)
+			if child.Type() == "simple_symbol" || child.Type() == "string" {
+				names = append(names, railsNames(child, content)...)
+			}
+		}
+		return names
+	}
+	return nil
+}
+
+func railsFirstName(args *sitter.Node, content []byte) string {
+	if names := railsNames(args, content); len(names) > 0 {
+		return names[0]
+	}
+	return ""
+}
+
+// camelize turns a snake_case path into a Ruby constant:
+// "admin/user_sessions" becomes "Admin::UserSessions".
+func camelize(name string) string {
+	segments := strings.Split(name, "/")
+	for i, segment := range segments {
+		words := strings.Split(segment, "_")
+		for j, word := range words {
+			if word != "" {
+				words[j] = strings.ToUpper(word[:1]) + word[1:]
+			}
+		}
+		segments[i] = strings.Join(words, "")
+	}
+	return strings.Join(segments, "::")
+}
+
+// singularize covers the regular English plurals Rails names use.
+func singularize(name string) string {
+	switch {
+	case strings.HasSuffix(name, "ies"):
+		return strings.TrimSuffix(name, "ies") + "y"
+	case strings.HasSuffix(name, "sses"), strings.HasSuffix(name, "xes"),
+		strings.HasSuffix(name, "ches"), strings.HasSuffix(name, "shes"):
+		return strings.TrimSuffix(name, "es")
+	case strings.HasSuffix(name, "ss"):
+		return name
+	case strings.HasSuffix(name, "s"):
+		return strings.TrimSuffix(name, "s")
+	}
+	return name
+}
+
+// pluralize is the inverse of singularize for regular nouns.
+func pluralize(name string) string {
+	switch {
+	case strings.HasSuffix(name, "y") && !strings.HasSuffix(name, "ay") && !strings.HasSuffix(name, "ey") && !strings.HasSuffix(name, "oy"):
+		return strings.TrimSuffix(name, "y") + "ies"
+	case strings.HasSuffix(name, "s"), strings.HasSuffix(name, "x"),
+		strings.HasSuffix(name, "ch"), strings.HasSuffix(name, "sh"):
+		return name + "es"
+	}
+	return name + "s"
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
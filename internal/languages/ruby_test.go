@@ -0,0 +1,247 @@
+package languages
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/morozRed/skelly/internal/parser"
+)
+
+func TestRubyParserRecordsSuperclassAndMixins(t *testing.T) {
+	file, err := NewRubyParser().Parse("user.rb", []byte(`class User < Admin::Base
+  include Comparable
+  extend Forwardable, Auditing::Trail
+  validates :name
+end
+`))
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	want := []parser.TypeRelation{
+		{Name: "Admin::Base", Relation: parser.RelationInherit},
+		{Name: "Comparable", Relation: parser.RelationInherit},
+		{Name: "Forwardable", Relation: parser.RelationInherit},
+		{Name: "Auditing::Trail", Relation: parser.RelationInherit},
+	}
+	bases := file.Symbols[0].Bases
+	if len(bases) != len(want) {
+		t.Fatalf("expected bases %#v, got %#v", want, bases)
+	}
+	for i := range want {
+		if bases[i] != want[i] {
+			t.Fatalf("expected bases %#v, got %#v", want, bases)
+		}
+	}
+}
+
+func TestRubyParserExtractsRailsModelAndControllerMacros(t *testing.T) {
+	file, err := NewRubyParser().Parse("app/models/user.rb", []byte(`module Shop
+  class User < ApplicationRecord
+    has_many :orders, dependent: :destroy
+    has_many :categories
+    belongs_to :account
+    has_one :avatar, class_name: "Media::Image"
+    belongs_to :owner, polymorphic: true
+    scope :active, -> { where(active: true).order(:name) }
+    before_save :normalize_email, :touch_account
+    validate :check_quota
+    skip_before_action :verify
+
+    def normalize_email
+    end
+  end
+end
+`))
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	type want struct {
+		signature  string
+		references string
+	}
+	expected := map[string]want{
+		"orders":      {"has_many :orders", "Order"},
+		"categories":  {"has_many :categories", "Category"},
+		"account":     {"belongs_to :account", "Account"},
+		"avatar":      {"has_one :avatar", "Media::Image"},
+		"owner":       {"belongs_to :owner", ""},
+		"self.active": {"scope :active", ""},
+	}
+	var user parser.Symbol
+	for _, symbol := range file.Symbols {
+		if symbol.Name == "User" {
+			user = symbol
+		}
+		w, ok := expected[symbol.Name]
+		if !ok {
+			continue
+		}
+		if symbol.Kind != parser.SymbolMethod || symbol.Container != "Shop::User" || symbol.Signature != w.signature {
+			t.Fatalf("unexpected macro symbol %#v", symbol)
+		}
+		if got := strings.Join(symbol.References, ","); got != w.references {
+			t.Fatalf("expected %s to reference %q, got %q", symbol.Name, w.references, got)
+		}
+		if symbol.Name == "self.active" && len(symbol.Calls) != 2 {
+			t.Fatalf("expected scope body calls, got %#v", symbol.Calls)
+		}
+		delete(expected, symbol.Name)
+	}
+	if len(expected) != 0 {
+		t.Fatalf("missing macro symbols %v", expected)
+	}
+
+	var callbacks []string
+	for _, call := range user.Calls {
+		if call.Qualifier != "Shop::User" {
+			t.Fatalf("expected callbacks qualified by the class, got %#v", call)
+		}
+		callbacks = append(callbacks, call.Name)
+	}
+	if got := strings.Join(callbacks, ","); got != "normalize_email,touch_account,check_quota" {
+		t.Fatalf("unexpected callbacks %q", got)
+	}
+}
+
+func TestRubyParserExtractsRailsRoutes(t *testing.T) {
+	file, err := NewRubyParser().Parse("config/routes.rb", []byte(`Rails.application.routes.draw do
+  root "home#index"
+  get "/about", to: "pages#about"
+  post "login" => "sessions#create"
+  get "photos/search"
+  namespace :admin do
+    resources :users, only: [:index, :show] do
+      member do
+        get :preview
+      end
+      collection do
+        get :export
+      end
+      resources :notes, except: [:new, :edit, :update, :destroy, :create]
+    end
+  end
+  resource :profile, only: :show
+end
+`))
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	var got []string
+	for _, symbol := range file.Symbols {
+		target := ""
+		if len(symbol.Calls) == 1 {
+			target = symbol.Calls[0].Qualifier + "." + symbol.Calls[0].Name
+		}
+		got = append(got, symbol.Name+" -> "+target)
+	}
+	want := []string{
+		"GET / -> HomeController.index",
+		"GET /about -> PagesController.about",
+		"POST /login -> SessionsController.create",
+		"GET /photos/search -> PhotosController.search",
+		"GET /admin/users -> Admin::UsersController.index",
+		"GET /admin/users/:id -> Admin::UsersController.show",
+		"GET /admin/users/:id/preview -> Admin::UsersController.preview",
+		"GET /admin/users/export -> Admin::UsersController.export",
+		"GET /admin/users/:user_id/notes -> Admin::NotesController.index",
+		"GET /admin/users/:user_id/notes/:id -> Admin::NotesController.show",
+		"GET /profile -> ProfilesController.show",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("unexpected routes:\n%s", strings.Join(got, "\n"))
+	}
+	if file.Symbols[4].Signature != "resources :users, only: [:index, :show]" {
+		t.Fatalf("expected route signature from its declaration, got %q", file.Symbols[4].Signature)
+	}
+}
+
+func TestRubyParserRecordsPrivateAndProtectedSections(t *testing.T) {
+	file, err := NewRubyParser().Parse("account.rb", []byte(`class Account
+  def balance; end
+
+  protected
+
+  def ledger; end
+
+  private
+
+  def recalculate; end
+  public def refresh; end
+
+  def self.build; end
+  def self.internal; end
+  private_class_method :internal
+
+  def audit; end
+  public :audit
+end
+`))
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	want := map[string]string{
+		"Account":               parser.VisibilityPublic,
+		"Account.balance":       parser.VisibilityPublic,
+		"Account.ledger":        parser.VisibilityProtected,
+		"Account.recalculate":   parser.VisibilityPrivate,
+		"Account.refresh":       parser.VisibilityPublic,
+		"Account.self.build":    parser.VisibilityPublic,
+		"Account.self.internal": parser.VisibilityPrivate,
+		"Account.audit":         parser.VisibilityPublic,
+	}
+	for _, symbol := range file.Symbols {
+		if got := symbol.Visibility; got != want[symbol.QualifiedName()] {
+			t.Errorf("%s: expected visibility %q, got %q", symbol.QualifiedName(), want[symbol.QualifiedName()], got)
+		}
+		delete(want, symbol.QualifiedName())
+	}
+	if len(want) > 0 {
+		t.Fatalf("missing symbols %v", want)
+	}
+}
+
+func TestRubyParserExtractsConstants(t *testing.T) {
+	file, err := NewRubyParser().Parse("billing.rb", []byte(`MAX_RETRIES = 3
+
+module Billing
+  TAX_RATE = compute_rate(0.2)
+  SECRET = "s3cret"
+  private_constant :SECRET
+
+  class Invoice
+    STATES = %w[draft paid].freeze
+
+    def total
+      LOCAL = 1
+      rate = TAX_RATE
+    end
+  end
+end
+`))
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	got := make([]string, 0)
+	for _, symbol := range file.Symbols {
+		if symbol.Kind == parser.SymbolConstant || symbol.Kind == parser.SymbolVariable {
+			got = append(got, symbol.Kind.String()+" "+symbol.Signature+" "+symbol.Container+" "+symbol.Visibility)
+		}
+		if symbol.Name == "TAX_RATE" && (len(symbol.Calls) != 1 || symbol.Calls[0].Name != "compute_rate") {
+			t.Fatalf("expected TAX_RATE to call compute_rate, got %+v", symbol.Calls)
+		}
+	}
+	want := []string{
+		"const MAX_RETRIES = 3  public",
+		"const TAX_RATE = compute_rate(0.2) Billing public",
+		`const SECRET = "s3cret" Billing private`,
+		"const STATES = %w[draft paid].freeze Billing::Invoice public",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("unexpected constants:\n%s", strings.Join(got, "\n"))
+	}
+}
@@ -0,0 +1,576 @@
+package languages
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+
+	"github.com/morozRed/skelly/internal/parser"
+	sitter "github.com/smacker/go-tree-sitter"
+	"github.com/smacker/go-tree-sitter/rust"
+)
+
+// RustParser implements parsing for Rust source files
+type RustParser struct {
+	extractions
+	parser *sitter.Parser
+}
+
+// NewRustParser creates a new Rust parser
+func NewRustParser() *RustParser {
+	p := sitter.NewParser()
+	p.SetLanguage(rust.GetLanguage())
+	return &RustParser{parser: p}
+}
+
+func (r *RustParser) Language() string {
+	return "rust"
+}
+
+func (r *RustParser) Extensions() []string {
+	return []string{".rs"}
+}
+
+func (r *RustParser) Parse(filename string, content []byte) (*parser.FileSymbols, error) {
+	r.begin("rust")
+	tree, err := r.parser.ParseCtx(context.Background(), nil, content)
+	if err != nil {
+		return nil, err
+	}
+	defer tree.Close()
+
+	result := &parser.FileSymbols{
+		Path:          filename,
+		Language:      "rust",
+		Symbols:       make([]parser.Symbol, 0),
+		Imports:       make([]string, 0),
+		ImportAliases: make(map[string]string),
+	}
+
+	root := tree.RootNode()
+	r.extractSymbols(root, content, result, filename, "", "")
+
+	return result, nil
+}
+
+// extractSymbols walks items, threading the path of the inline modules
+// (net::tls) they are declared in and the header of the impl or trait block
+// whose body they are in.
+func (r *RustParser) extractSymbols(node *sitter.Node, content []byte, result *parser.FileSymbols, filename, modulePath, implHeader string) {
+	switch node.Type() {
+	case "function_item", "function_signature_item":
+		// Signature items are trait methods without a default body.
+		sym := r.extractFunction(node, content, modulePath, implHeader)
+		if sym != nil {
+			result.Symbols = append(result.Symbols, *sym)
+		}
+		// Don't recurse into function bodies for nested items (for now)
+		return
+
+	case "struct_item", "enum_item", "union_item":
+		sym := r.extractTypeItem(node, content, modulePath)
+		if sym != nil {
+			result.Symbols = append(result.Symbols, *sym)
+		}
+		return
+
+	case "trait_item":
+		sym := r.extractTrait(node, content, modulePath)
+		if sym != nil {
+			result.Symbols = append(result.Symbols, *sym)
+			r.extractBody(node, content, result, filename, modulePath, "trait "+sym.Name)
+		}
+		return
+
+	case "impl_item":
+		r.extractBody(node, content, result, filename, modulePath, r.buildImplHeader(node, content))
+		return
+
+	case "mod_item":
+		if nameNode := node.ChildByFieldName("name"); nameNode != nil {
+			r.extractBody(node, content, result, filename, rustJoinPath(modulePath, nameNode.Content(content)), "")
+		}
+		return
+
+	case "const_item", "static_item":
+		if sym := r.extractValueItem(node, content, modulePath, implHeader); sym != nil {
+			result.Symbols = append(result.Symbols, *sym)
+		}
+		return
+
+	case "use_declaration":
+		imports, aliases := r.extractUse(node, content, filename)
+		result.Imports = append(result.Imports, imports...)
+		result.ImportAliases = mergeImportAliases(result.ImportAliases, aliases)
+		return
+	}
+
+	// Recurse into children (covers source_file)
+	for i := 0; i < int(node.ChildCount()); i++ {
+		r.extractSymbols(node.Child(i), content, result, filename, modulePath, implHeader)
+	}
+}
+
+func (r *RustParser) extractBody(node *sitter.Node, content []byte, result *parser.FileSymbols, filename, modulePath, implHeader string) {
+	bodyNode := node.ChildByFieldName("body")
+	if bodyNode == nil {
+		return
+	}
+	for i := 0; i < int(bodyNode.ChildCount()); i++ {
+		r.extractSymbols(bodyNode.Child(i), content, result, filename, modulePath, implHeader)
+	}
+}
+
+func (r *RustParser) extractFunction(node *sitter.Node, content []byte, modulePath, implHeader string) *parser.Symbol {
+	nameNode := node.ChildByFieldName("name")
+	if nameNode == nil {
+		return nil
+	}
+
+	kind := parser.SymbolFunction
+	sig := r.buildFunctionSignature(node, content)
+	if implHeader != "" {
+		kind = parser.SymbolMethod
+		sig = implHeader + " { " + sig + " }"
+	}
+
+	return &parser.Symbol{
+		Name:      nameNode.Content(content),
+		Kind:      kind,
+		Signature: sig,
+		Line:      int(node.StartPoint().Row) + 1,
+		Span:      symbolSpan(node),
+		Doc:       r.doc(rustDocComment, node, content),
+		Container: rustContainer(modulePath, implHeader),
+		Calls:     r.extractCalls(node.ChildByFieldName("body"), content),
+	}
+}
+
+func (r *RustParser) extractTypeItem(node *sitter.Node, content []byte, modulePath string) *parser.Symbol {
+	nameNode := node.ChildByFieldName("name")
+	if nameNode == nil {
+		return nil
+	}
+
+	keyword := strings.TrimSuffix(node.Type(), "_item")
+	name := nameNode.Content(content)
+	sig := keyword + " " + name
+	if typeParams := node.ChildByFieldName("type_parameters"); typeParams != nil {
+		sig += typeParams.Content(content)
+	}
+
+	return &parser.Symbol{
+		Name:      name,
+		Kind:      parser.SymbolStruct,
+		Signature: sig,
+		Line:      int(node.StartPoint().Row) + 1,
+		Span:      symbolSpan(node),
+		Doc:       r.doc(rustDocComment, node, content),
+		Container: modulePath,
+	}
+}
+
+// extractValueItem returns the symbol of a const or static item. Statics are
+// constants unless declared mut; items in impl and trait blocks belong to
+// their type.
+func (r *RustParser) extractValueItem(node *sitter.Node, content []byte, modulePath, implHeader string) *parser.Symbol {
+	nameNode := node.ChildByFieldName("name")
+	if nameNode == nil {
+		return nil
+	}
+
+	name := nameNode.Content(content)
+	kind := parser.SymbolConstant
+	declaration := "const " + name
+	if node.Type() == "static_item" {
+		declaration = "static " + name
+		for i := 0; i < int(node.NamedChildCount()); i++ {
+			if node.NamedChild(i).Type() == "mutable_specifier" {
+				kind = parser.SymbolVariable
+				declaration = "static mut " + name
+			}
+		}
+	}
+	if typeNode := node.ChildByFieldName("type"); typeNode != nil {
+		declaration += ": " + typeNode.Content(content)
+	}
+	signature := declaration
+	valueNode := node.ChildByFieldName("value")
+	if valueNode != nil {
+		signature = valueSignature(declaration, valueNode.Content(content))
+	}
+
+	return &parser.Symbol{
+		Name:      name,
+		Kind:      kind,
+		Signature: signature,
+		Line:      int(node.StartPoint().Row) + 1,
+		Span:      symbolSpan(node),
+		Doc:       r.doc(rustDocComment, node, content),
+		Container: rustContainer(modulePath, implHeader),
+		Calls:     r.extractCalls(valueNode, content),
+	}
+}
+
+func (r *RustParser) extractTrait(node *sitter.Node, content []byte, modulePath string) *parser.Symbol {
+	nameNode := node.ChildByFieldName("name")
+	if nameNode == nil {
+		return nil
+	}
+
+	name := nameNode.Content(content)
+	sig := "trait " + name
+	if bounds := node.ChildByFieldName("bounds"); bounds != nil {
+		sig += bounds.Content(content)
+	}
+
+	return &parser.Symbol{
+		Name:      name,
+		Kind:      parser.SymbolInterface,
+		Signature: sig,
+		Line:      int(node.StartPoint().Row) + 1,
+		Span:      symbolSpan(node),
+		Doc:       r.doc(rustDocComment, node, content),
+		Container: modulePath,
+	}
+}
+
+// rustContainer returns the container of an item in modulePath and, when
+// implHeader is set, in that impl or trait block: net::Client for a method
+// of Client in mod net.
+func rustContainer(modulePath, implHeader string) string {
+	return rustJoinPath(modulePath, rustImplContainer(implHeader))
+}
+
+func rustJoinPath(prefix, name string) string {
+	if prefix == "" || name == "" {
+		return prefix + name
+	}
+	return prefix + "::" + name
+}
+
+// rustImplContainer returns the type (or trait) that an impl or trait header
+// from buildImplHeader attaches methods to: Client for "impl Transport for
+// client::Client<T>", Transport for "trait Transport".
+func rustImplContainer(header string) string {
+	if header == "" {
+		return ""
+	}
+	if name, ok := strings.CutPrefix(header, "trait "); ok {
+		return name
+	}
+	target := strings.TrimPrefix(header, "impl")
+	if idx := strings.LastIndex(target, " for "); idx != -1 {
+		target = target[idx+len(" for "):]
+	}
+	target = strings.TrimLeft(strings.TrimSpace(target), "&*")
+	if idx := strings.Index(target, "<"); idx != -1 {
+		target = target[:idx]
+	}
+	if idx := strings.LastIndex(target, "::"); idx != -1 {
+		target = target[idx+2:]
+	}
+	return strings.TrimSpace(target)
+}
+
+func (r *RustParser) buildImplHeader(node *sitter.Node, content []byte) string {
+	header := "impl"
+	if traitNode := node.ChildByFieldName("trait"); traitNode != nil {
+		header += " " + traitNode.Content(content) + " for"
+	}
+	if typeNode := node.ChildByFieldName("type"); typeNode != nil {
+		header += " " + typeNode.Content(content)
+	}
+	return header
+}
+
+func (r *RustParser) buildFunctionSignature(node *sitter.Node, content []byte) string {
+	sig := ""
+	for i := 0; i < int(node.ChildCount()); i++ {
+		if child := node.Child(i); child.Type() == "function_modifiers" {
+			sig = child.Content(content) + " "
+			break
+		}
+	}
+
+	sig += "fn"
+	if nameNode := node.ChildByFieldName("name"); nameNode != nil {
+		sig += " " + nameNode.Content(content)
+	}
+	if typeParams := node.ChildByFieldName("type_parameters"); typeParams != nil {
+		sig += typeParams.Content(content)
+	}
+	if paramsNode := node.ChildByFieldName("parameters"); paramsNode != nil {
+		sig += paramsNode.Content(content)
+	}
+	if returnNode := node.ChildByFieldName("return_type"); returnNode != nil {
+		sig += " -> " + returnNode.Content(content)
+	}
+
+	return sig
+}
+
+// extractUse flattens a use tree into module imports and alias -> module#symbol targets.
+func (r *RustParser) extractUse(node *sitter.Node, content []byte, filename string) ([]string, map[string]string) {
+	imports := make([]string, 0)
+	aliases := make(map[string]string)
+	argument := node.ChildByFieldName("argument")
+	if argument == nil {
+		return imports, aliases
+	}
+
+	var walk func(n *sitter.Node, prefix []string)
+	walk = func(n *sitter.Node, prefix []string) {
+		if n == nil {
+			return
+		}
+		switch n.Type() {
+		case "scoped_use_list":
+			path := append(append([]string(nil), prefix...), rustPathSegments(n.ChildByFieldName("path"), content)...)
+			walk(n.ChildByFieldName("list"), path)
+		case "use_list":
+			for i := 0; i < int(n.NamedChildCount()); i++ {
+				walk(n.NamedChild(i), prefix)
+			}
+		case "use_as_clause":
+			segments := append(append([]string(nil), prefix...), rustPathSegments(n.ChildByFieldName("path"), content)...)
+			alias := ""
+			if aliasNode := n.ChildByFieldName("alias"); aliasNode != nil {
+				alias = strings.TrimSpace(aliasNode.Content(content))
+			}
+			r.addUseTarget(segments, alias, filename, &imports, aliases)
+		case "use_wildcard":
+			segments := append([]string(nil), prefix...)
+			for i := 0; i < int(n.NamedChildCount()); i++ {
+				segments = append(segments, rustPathSegments(n.NamedChild(i), content)...)
+			}
+			if modulePath := rustModulePath(segments, filename); modulePath != "" {
+				imports = append(imports, modulePath)
+			}
+		default:
+			segments := append(append([]string(nil), prefix...), rustPathSegments(n, content)...)
+			r.addUseTarget(segments, "", filename, &imports, aliases)
+		}
+	}
+	walk(argument, nil)
+
+	return imports, aliases
+}
+
+func (r *RustParser) addUseTarget(segments []string, alias, filename string, imports *[]string, aliases map[string]string) {
+	if len(segments) == 0 {
+		return
+	}
+	name := segments[len(segments)-1]
+	if name == "self" {
+		// `use crate::net::{self}` imports the module itself.
+		segments = segments[:len(segments)-1]
+		if len(segments) == 0 {
+			return
+		}
+		name = segments[len(segments)-1]
+		modulePath := rustModulePath(segments, filename)
+		if modulePath == "" {
+			return
+		}
+		*imports = append(*imports, modulePath)
+		if alias == "" {
+			alias = name
+		}
+		aliases[alias] = modulePath
+		return
+	}
+
+	if alias == "" {
+		alias = name
+	}
+	if alias == "_" {
+		return
+	}
+
+	// Idiomatic Rust imports types directly but functions through their module,
+	// so lowercase names are treated as modules and capitalized names as items.
+	if !startsUpper(name) {
+		modulePath := rustModulePath(segments, filename)
+		*imports = append(*imports, modulePath)
+		aliases[alias] = modulePath
+		return
+	}
+	modulePath := rustModulePath(segments[:len(segments)-1], filename)
+	if modulePath == "" {
+		*imports = append(*imports, name)
+		aliases[alias] = name
+		return
+	}
+	*imports = append(*imports, modulePath)
+	aliases[alias] = modulePath + "#" + name
+}
+
+func startsUpper(value string) bool {
+	return value != "" && value[0] >= 'A' && value[0] <= 'Z'
+}
+
+func rustPathSegments(node *sitter.Node, content []byte) []string {
+	if node == nil {
+		return nil
+	}
+	raw := strings.TrimSpace(node.Content(content))
+	if raw == "" {
+		return nil
+	}
+	segments := make([]string, 0)
+	for _, segment := range strings.Split(raw, "::") {
+		segment = strings.TrimSpace(segment)
+		if segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+	return segments
+}
+
+// rustModulePath converts a use path into a slash-separated import path the
+// graph resolver can match against files. `self::` and `super::` become
+// relative paths anchored at the source file's module directory.
+func rustModulePath(segments []string, filename string) string {
+	if len(segments) == 0 {
+		return ""
+	}
+
+	switch segments[0] {
+	case "crate":
+		return strings.Join(segments[1:], "/")
+	case "self", "super":
+		base := filepath.Base(filename)
+		moduleDir := "./" + strings.TrimSuffix(base, filepath.Ext(base))
+		if base == "mod.rs" || base == "lib.rs" || base == "main.rs" {
+			moduleDir = "."
+		}
+		rest := segments
+		for len(rest) > 0 && rest[0] == "super" {
+			moduleDir = filepath.ToSlash(filepath.Join(moduleDir, ".."))
+			rest = rest[1:]
+		}
+		if len(rest) > 0 && rest[0] == "self" {
+			rest = rest[1:]
+		}
+		parts := append([]string{moduleDir}, rest...)
+		path := filepath.ToSlash(filepath.Join(parts...))
+		if !strings.HasPrefix(path, ".") {
+			path = "./" + path
+		}
+		return path
+	default:
+		return strings.Join(segments, "/")
+	}
+}
+
+func (r *RustParser) extractCalls(bodyNode *sitter.Node, content []byte) []parser.CallSite {
+	if r.skip.SkipCalls {
+		return nil
+	}
+	if bodyNode == nil {
+		return nil
+	}
+
+	calls := make([]parser.CallSite, 0)
+	r.collectCalls(bodyNode, content, &calls)
+	return calls
+}
+
+func (r *RustParser) collectCalls(node *sitter.Node, content []byte, calls *[]parser.CallSite) {
+	if node == nil {
+		return
+	}
+
+	if node.Type() == "call_expression" {
+		callSite := r.extractCallSite(node, content)
+		if callSite.Name != "" {
+			*calls = append(*calls, callSite)
+		}
+	}
+
+	for i := 0; i < int(node.ChildCount()); i++ {
+		r.collectCalls(node.Child(i), content, calls)
+	}
+}
+
+func (r *RustParser) extractCallSite(callNode *sitter.Node, content []byte) parser.CallSite {
+	fnNode := callNode.ChildByFieldName("function")
+	name, qualifier := r.extractCallName(fnNode, content)
+	callSite := parser.CallSite{
+		Name:      name,
+		Qualifier: qualifier,
+		Raw:       "",
+		Line:      int(callNode.StartPoint().Row) + 1,
+	}
+	if argsNode := callNode.ChildByFieldName("arguments"); argsNode != nil {
+		callSite.Arity = int(argsNode.NamedChildCount())
+	}
+	if fnNode != nil {
+		callSite.Raw = strings.TrimSpace(fnNode.Content(content))
+	}
+	if qualifier == "self" || qualifier == "Self" {
+		callSite.Receiver = "self"
+	}
+	return callSite
+}
+
+// extractCallName returns the called name and a dot-separated qualifier so the
+// resolver's qualifier handling works the same as for other languages.
+func (r *RustParser) extractCallName(node *sitter.Node, content []byte) (name, qualifier string) {
+	if node == nil {
+		return "", ""
+	}
+
+	switch node.Type() {
+	case "identifier":
+		return node.Content(content), ""
+	case "scoped_identifier":
+		nameNode := node.ChildByFieldName("name")
+		if nameNode == nil {
+			break
+		}
+		qualifierValue := ""
+		if pathNode := node.ChildByFieldName("path"); pathNode != nil {
+			qualifierValue = strings.ReplaceAll(strings.TrimSpace(pathNode.Content(content)), "::", ".")
+		}
+		return nameNode.Content(content), qualifierValue
+	case "field_expression":
+		fieldNode := node.ChildByFieldName("field")
+		if fieldNode == nil {
+			break
+		}
+		qualifierValue := ""
+		if valueNode := node.ChildByFieldName("value"); valueNode != nil {
+			qualifierValue = strings.TrimSpace(valueNode.Content(content))
+		}
+		return fieldNode.Content(content), qualifierValue
+	case "generic_function":
+		return r.extractCallName(node.ChildByFieldName("function"), content)
+	case "parenthesized_expression":
+		if node.NamedChildCount() > 0 {
+			return r.extractCallName(node.NamedChild(0), content)
+		}
+	}
+
+	return "", ""
+}
+
+// rustDocComment collects contiguous `///` comments directly above an item.
+func rustDocComment(node *sitter.Node, content []byte) string {
+	lines := make([]string, 0)
+	for sibling := node.PrevSibling(); sibling != nil; sibling = sibling.PrevSibling() {
+		if sibling.Type() == "attribute_item" {
+			continue
+		}
+		if sibling.Type() != "line_comment" {
+			break
+		}
+		text := strings.TrimSpace(sibling.Content(content))
+		if !strings.HasPrefix(text, "///") {
+			break
+		}
+		lines = append([]string{strings.TrimSpace(strings.TrimPrefix(text, "///"))}, lines...)
+	}
+	return strings.TrimSpace(strings.Join(lines, " "))
+}
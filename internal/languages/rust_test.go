@@ -0,0 +1,179 @@
+package languages
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/morozRed/skelly/internal/parser"
+)
+
+func TestRustParserExtractsItemsImportsAndCalls(t *testing.T) {
+	parser := NewRustParser()
+	file, err := parser.Parse("src/net/client.rs", []byte(`use crate::util::{helper, codec::Codec as Wire};
+use super::server;
+
+/// A network client.
+pub struct Client { addr: String }
+
+pub enum State { Idle, Busy }
+
+pub trait Transport {
+    fn send(&self, data: &[u8]) -> usize;
+}
+
+impl Client {
+    pub fn new(addr: &str) -> Self {
+        helper(addr);
+        Self::validate(addr);
+        Client { addr: addr.to_string() }
+    }
+
+    fn validate(addr: &str) -> bool { Wire::check(addr) }
+}
+
+impl Transport for Client {
+    fn send(&self, data: &[u8]) -> usize { self.new_frame(); server::dispatch(data, 1) }
+}
+
+pub async fn connect(addr: &str) -> Result<Client, String> { Ok(Client::new(addr)) }
+`))
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	symbols := make(map[string]string)
+	for _, symbol := range file.Symbols {
+		symbols[symbol.Name+"/"+symbol.Kind.String()] = symbol.Signature
+	}
+	for key, want := range map[string]string{
+		"Client/struct":       "struct Client",
+		"State/struct":        "enum State",
+		"Transport/interface": "trait Transport",
+		"new/method":          "impl Client { fn new(addr: &str) -> Self }",
+		"send/method":         "impl Transport for Client { fn send(&self, data: &[u8]) -> usize }",
+		"connect/func":        "async fn connect(addr: &str) -> Result<Client, String>",
+	} {
+		if got := symbols[key]; got != want {
+			t.Fatalf("expected %s signature %q, got %q (all: %#v)", key, want, got, symbols)
+		}
+	}
+	if file.Symbols[0].Doc != "A network client." {
+		t.Fatalf("expected doc comment on Client, got %q", file.Symbols[0].Doc)
+	}
+
+	if got := file.ImportAliases["helper"]; got != "util/helper" {
+		t.Fatalf("expected lowercase helper to import module util/helper, got %q", got)
+	}
+	if got := file.ImportAliases["Wire"]; got != "util/codec#Codec" {
+		t.Fatalf("expected aliased Wire to util/codec#Codec, got %q", got)
+	}
+	if got := file.ImportAliases["server"]; got != "./server" {
+		t.Fatalf("expected super::server relative to src/net, got %q", got)
+	}
+
+	var newCalls []string
+	for _, symbol := range file.Symbols {
+		if symbol.Name == "new" {
+			for _, call := range symbol.Calls {
+				newCalls = append(newCalls, call.Qualifier+"|"+call.Name+"|"+call.Receiver)
+			}
+		}
+	}
+	if len(newCalls) != 3 || newCalls[0] != "|helper|" || newCalls[1] != "Self|validate|self" || newCalls[2] != "addr|to_string|" {
+		t.Fatalf("unexpected calls in Client::new: %#v", newCalls)
+	}
+}
+
+func TestRustParserExtractsConstantsAndStatics(t *testing.T) {
+	file, err := NewRustParser().Parse("src/config.rs", []byte(`/// Attempts before giving up.
+pub const MAX_RETRIES: u32 = 3;
+static mut COUNTER: u64 = 0;
+static DEFAULT_HOST: &str = "localhost";
+pub static REGISTRY: Lazy<Registry> = Lazy::new(build_registry);
+
+pub struct Limits;
+
+impl Limits {
+    pub const BURST: usize = 16;
+}
+
+fn main() {
+    const LOCAL: u8 = 1;
+}
+`))
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	got := make([]string, 0)
+	for _, symbol := range file.Symbols {
+		if symbol.Kind == parser.SymbolConstant || symbol.Kind == parser.SymbolVariable {
+			got = append(got, symbol.Kind.String()+" "+symbol.Signature+" "+symbol.Container)
+		}
+		if symbol.Name == "MAX_RETRIES" && symbol.Doc != "Attempts before giving up." {
+			t.Fatalf("expected doc comment on MAX_RETRIES, got %q", symbol.Doc)
+		}
+		if symbol.Name == "REGISTRY" && (len(symbol.Calls) != 1 || symbol.Calls[0].Name != "new") {
+			t.Fatalf("expected REGISTRY to call Lazy::new, got %+v", symbol.Calls)
+		}
+	}
+	want := []string{
+		"const const MAX_RETRIES: u32 = 3 ",
+		"var static mut COUNTER: u64 = 0 ",
+		`const static DEFAULT_HOST: &str = "localhost" `,
+		"const static REGISTRY: Lazy<Registry> = Lazy::new(build_registry) ",
+		"const const BURST: usize = 16 Limits",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("unexpected constants and statics:\n%s", strings.Join(got, "\n"))
+	}
+}
+
+func TestRustParserExtractsTraitSignaturesAndModuleContainers(t *testing.T) {
+	file, err := NewRustParser().Parse("src/lib.rs", []byte(`pub trait Store {
+    /// Loads one record.
+    fn load(&self, id: u64) -> Option<Record>;
+    fn flush(&mut self) { self.sync(); }
+}
+
+pub mod net {
+    pub struct Client;
+
+    impl Client {
+        pub fn connect(&self) {}
+    }
+
+    pub mod tls {
+        pub const VERSION: u8 = 3;
+
+        pub fn handshake() {}
+    }
+}
+
+fn main() { net::tls::handshake(); }
+`))
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	got := make([]string, 0, len(file.Symbols))
+	for _, symbol := range file.Symbols {
+		got = append(got, symbol.Kind.String()+" "+symbol.Signature+" | "+symbol.Container)
+		if symbol.Name == "load" && symbol.Doc != "Loads one record." {
+			t.Fatalf("expected doc comment on Store::load, got %q", symbol.Doc)
+		}
+	}
+	want := []string{
+		"interface trait Store | ",
+		"method trait Store { fn load(&self, id: u64) -> Option<Record> } | Store",
+		"method trait Store { fn flush(&mut self) } | Store",
+		"struct struct Client | net",
+		"method impl Client { fn connect(&self) } | net::Client",
+		"const const VERSION: u8 = 3 | net::tls",
+		"func fn handshake() | net::tls",
+		"func fn main() | ",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("unexpected symbols:\n%s", strings.Join(got, "\n"))
+	}
+}
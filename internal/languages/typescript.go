@@ -2,18 +2,22 @@ package languages
 
 import (
 	"context"
+	"slices"
 	"strings"
 
 	"github.com/morozRed/skelly/internal/parser"
 	sitter "github.com/smacker/go-tree-sitter"
 	"github.com/smacker/go-tree-sitter/javascript"
+	"github.com/smacker/go-tree-sitter/typescript/tsx"
 	"github.com/smacker/go-tree-sitter/typescript/typescript"
 )
 
 // TypeScriptParser implements parsing for TypeScript/JavaScript source files
 type TypeScriptParser struct {
-	tsParser *sitter.Parser
-	jsParser *sitter.Parser
+	extractions
+	tsParser  *sitter.Parser
+	tsxParser *sitter.Parser
+	jsParser  *sitter.Parser
 }
 
 // NewTypeScriptParser creates a new TypeScript/JavaScript parser
@@ -21,12 +25,16 @@ func NewTypeScriptParser() *TypeScriptParser {
 	ts := sitter.NewParser()
 	ts.SetLanguage(typescript.GetLanguage())
 
+	tsxParser := sitter.NewParser()
+	tsxParser.SetLanguage(tsx.GetLanguage())
+
 	js := sitter.NewParser()
 	js.SetLanguage(javascript.GetLanguage())
 
 	return &TypeScriptParser{
-		tsParser: ts,
-		jsParser: js,
+		tsParser:  ts,
+		tsxParser: tsxParser,
+		jsParser:  js,
 	}
 }
 
@@ -42,13 +50,15 @@ func (t *TypeScriptParser) Parse(filename string, content []byte) (*parser.FileS
 	// Choose parser based on extension
 	var p *sitter.Parser
 	lang := "typescript"
-	if strings.HasSuffix(filename, ".js") || strings.HasSuffix(filename, ".jsx") ||
-		strings.HasSuffix(filename, ".mjs") || strings.HasSuffix(filename, ".cjs") {
+	if isJavaScriptFile(filename) {
 		p = t.jsParser
 		lang = "javascript"
+	} else if strings.HasSuffix(filename, ".tsx") {
+		p = t.tsxParser
 	} else {
 		p = t.tsParser
 	}
+	t.begin(lang)
 
 	tree, err := p.ParseCtx(context.Background(), nil, content)
 	if err != nil {
@@ -66,6 +76,17 @@ func (t *TypeScriptParser) Parse(filename string, content []byte) (*parser.FileS
 
 	root := tree.RootNode()
 	t.extractSymbols(root, content, result, "")
+	typeScriptVisibility(root, content, result.Symbols)
+	// Module-level constants and variables are indexed when exported.
+	result.Symbols = slices.DeleteFunc(result.Symbols, func(sym parser.Symbol) bool {
+		return (sym.Kind == parser.SymbolConstant || sym.Kind == parser.SymbolVariable) && sym.Visibility != parser.VisibilityPublic
+	})
+	if usesExpress(result.Imports, content) {
+		t.extractExpressRoutes(root, content, &result.Symbols)
+	}
+	if parser.IsTestFile(lang, filename) {
+		t.extractTestBlocks(root, content, "", &result.Symbols)
+	}
 
 	return result, nil
 }
@@ -75,6 +96,7 @@ func (t *TypeScriptParser) extractSymbols(node *sitter.Node, content []byte, res
 	case "function_declaration":
 		sym := t.extractFunction(node, content)
 		if sym != nil {
+			sym.Container = className
 			result.Symbols = append(result.Symbols, *sym)
 		}
 		return
@@ -86,15 +108,40 @@ func (t *TypeScriptParser) extractSymbols(node *sitter.Node, content []byte, res
 		}
 		return
 
+	case "internal_module", "module":
+		// namespace Billing {} and module Billing {}; ambient modules named
+		// by a string (declare module "pkg") are only recursed into.
+		sym := t.extractNamespace(node, content)
+		if sym == nil {
+			break
+		}
+		sym.Container = className
+		result.Symbols = append(result.Symbols, *sym)
+		if bodyNode := node.ChildByFieldName("body"); bodyNode != nil {
+			for i := 0; i < int(bodyNode.ChildCount()); i++ {
+				t.extractSymbols(bodyNode.Child(i), content, result, sym.QualifiedName())
+			}
+		}
+		return
+
+	case "enum_declaration":
+		sym := t.extractEnum(node, content)
+		if sym != nil {
+			sym.Container = className
+			result.Symbols = append(result.Symbols, *sym)
+		}
+		return
+
 	case "class_declaration":
 		sym := t.extractClass(node, content)
 		if sym != nil {
+			sym.Container = className
 			result.Symbols = append(result.Symbols, *sym)
 			// Recurse into class body
 			bodyNode := node.ChildByFieldName("body")
 			if bodyNode != nil {
 				for i := 0; i < int(bodyNode.ChildCount()); i++ {
-					t.extractSymbols(bodyNode.Child(i), content, result, sym.Name)
+					t.extractSymbols(bodyNode.Child(i), content, result, sym.QualifiedName())
 				}
 			}
 		}
@@ -103,6 +150,7 @@ func (t *TypeScriptParser) extractSymbols(node *sitter.Node, content []byte, res
 	case "interface_declaration":
 		sym := t.extractInterface(node, content)
 		if sym != nil {
+			sym.Container = className
 			result.Symbols = append(result.Symbols, *sym)
 		}
 		return
@@ -110,6 +158,7 @@ func (t *TypeScriptParser) extractSymbols(node *sitter.Node, content []byte, res
 	case "type_alias_declaration":
 		sym := t.extractTypeAlias(node, content)
 		if sym != nil {
+			sym.Container = className
 			result.Symbols = append(result.Symbols, *sym)
 		}
 		return
@@ -117,6 +166,9 @@ func (t *TypeScriptParser) extractSymbols(node *sitter.Node, content []byte, res
 	case "lexical_declaration", "variable_declaration":
 		// Check for arrow functions or function expressions
 		syms := t.extractVariableDeclarations(node, content)
+		for i := range syms {
+			syms[i].Container = className
+		}
 		result.Symbols = append(result.Symbols, syms...)
 		return
 
@@ -148,16 +200,102 @@ func (t *TypeScriptParser) extractFunction(node *sitter.Node, content []byte) *p
 
 	name := nameNode.Content(content)
 	sig := t.buildFunctionSignature(node, content)
+	body := node.ChildByFieldName("body")
 
 	return &parser.Symbol{
-		Name:      name,
-		Kind:      parser.SymbolFunction,
-		Signature: sig,
-		Line:      int(node.StartPoint().Row) + 1,
-		Calls:     t.extractCalls(node.ChildByFieldName("body"), content),
+		Name:       name,
+		Kind:       typeScriptFunctionKind(name, body),
+		Signature:  sig,
+		Line:       int(node.StartPoint().Row) + 1,
+		Span:       symbolSpan(node),
+		Renders:    typeScriptRenders(nil, body, content),
+		References: t.references(func() []string { return typeScriptFunctionReferences(node, content) }),
+		Calls:      t.extractCalls(body, content),
 	}
 }
 
+// typeScriptFunctionKind marks capitalized functions that return JSX as
+// components (React function components).
+func typeScriptFunctionKind(name string, body *sitter.Node) parser.SymbolKind {
+	if isComponentName(name) && typeScriptReturnsJSX(body) {
+		return parser.SymbolComponent
+	}
+	return parser.SymbolFunction
+}
+
+// isComponentName reports whether name is capitalized, which JSX requires of
+// components; lowercase tags are DOM elements.
+func isComponentName(name string) bool {
+	name = parser.InnermostName(name)
+	return name != "" && name[0] >= 'A' && name[0] <= 'Z'
+}
+
+// typeScriptReturnsJSX reports whether a function body returns a JSX
+// element or fragment, either as an arrow function's expression body or
+// from a return statement outside nested functions.
+func typeScriptReturnsJSX(body *sitter.Node) bool {
+	if body == nil {
+		return false
+	}
+	if body.Type() != "statement_block" {
+		return containsJSX(body)
+	}
+	var found bool
+	var walk func(node *sitter.Node)
+	walk = func(node *sitter.Node) {
+		if found {
+			return
+		}
+		switch node.Type() {
+		case "function_declaration", "function", "function_expression", "arrow_function", "class_declaration", "class":
+			return
+		case "return_statement":
+			found = containsJSX(node)
+			return
+		}
+		for i := 0; i < int(node.NamedChildCount()); i++ {
+			walk(node.NamedChild(i))
+		}
+	}
+	walk(body)
+	return found
+}
+
+func containsJSX(node *sitter.Node) bool {
+	switch node.Type() {
+	case "jsx_element", "jsx_self_closing_element", "jsx_fragment":
+		return true
+	case "function_declaration", "function", "function_expression", "arrow_function":
+		return false
+	}
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		if containsJSX(node.NamedChild(i)) {
+			return true
+		}
+	}
+	return false
+}
+
+// typeScriptRenders appends the capitalized JSX element names under node
+// ("Button", "UI.Card").
+func typeScriptRenders(renders []string, node *sitter.Node, content []byte) []string {
+	if node == nil {
+		return renders
+	}
+	switch node.Type() {
+	case "jsx_opening_element", "jsx_self_closing_element":
+		if nameNode := node.ChildByFieldName("name"); nameNode != nil {
+			if name := nameNode.Content(content); isComponentName(name) {
+				renders = appendReference(renders, name)
+			}
+		}
+	}
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		renders = typeScriptRenders(renders, node.NamedChild(i), content)
+	}
+	return renders
+}
+
 func (t *TypeScriptParser) extractMethod(node *sitter.Node, content []byte, className string) *parser.Symbol {
 	nameNode := node.ChildByFieldName("name")
 	if nameNode == nil {
@@ -166,16 +304,139 @@ func (t *TypeScriptParser) extractMethod(node *sitter.Node, content []byte, clas
 
 	name := nameNode.Content(content)
 	sig := t.buildMethodSignature(node, content)
+	if accessor := typeScriptAccessor(node); accessor != "" {
+		sig = accessor + " " + sig
+	}
 
 	return &parser.Symbol{
-		Name:      name,
-		Kind:      parser.SymbolMethod,
-		Signature: sig,
-		Line:      int(node.StartPoint().Row) + 1,
-		Calls:     t.extractCalls(node.ChildByFieldName("body"), content),
+		Name:       name,
+		Kind:       parser.SymbolMethod,
+		Signature:  sig,
+		Line:       int(node.StartPoint().Row) + 1,
+		Span:       symbolSpan(node),
+		Decorators: typeScriptDecorators(node, content),
+		Container:  className,
+		Visibility: typeScriptMemberVisibility(node, content),
+		Renders:    typeScriptRenders(nil, node.ChildByFieldName("body"), content),
+		References: t.references(func() []string { return typeScriptFunctionReferences(node, content) }),
+		Calls:      t.extractCalls(node.ChildByFieldName("body"), content),
+	}
+}
+
+// typeScriptMemberVisibility reads a class member's accessibility modifier.
+// #private names are private and members without a modifier are public.
+func typeScriptMemberVisibility(node *sitter.Node, content []byte) string {
+	if nameNode := node.ChildByFieldName("name"); nameNode != nil && nameNode.Type() == "private_property_identifier" {
+		return parser.VisibilityPrivate
+	}
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		child := node.NamedChild(i)
+		if child.Type() != "accessibility_modifier" {
+			continue
+		}
+		switch child.Content(content) {
+		case "private":
+			return parser.VisibilityPrivate
+		case "protected":
+			return parser.VisibilityProtected
+		}
+	}
+	return parser.VisibilityPublic
+}
+
+// typeScriptVisibility marks the declarations a module exports public and
+// the rest private. Exports are declarations under an export keyword, names
+// in an export list or after export default, and values assigned to
+// module.exports or exports.name. Files with no imports or exports are
+// scripts whose declarations are global, so all public. Symbols that already
+// have a visibility (class members) are left alone.
+func typeScriptVisibility(root *sitter.Node, content []byte, symbols []parser.Symbol) {
+	exports := typeScriptExports{starts: make(map[int]bool), names: make(map[string]bool)}
+	exports.collect(root, content)
+	for i := range symbols {
+		sym := &symbols[i]
+		if sym.Visibility != "" {
+			continue
+		}
+		if !exports.module || exports.starts[sym.StartByte] || (sym.Container == "" && exports.names[sym.Name]) {
+			sym.Visibility = parser.VisibilityPublic
+		} else {
+			sym.Visibility = parser.VisibilityPrivate
+		}
 	}
 }
 
+// typeScriptExports records what a file exports: the start bytes of exported
+// declarations and the local names exported by name.
+type typeScriptExports struct {
+	module bool
+	starts map[int]bool
+	names  map[string]bool
+}
+
+func (e *typeScriptExports) collect(node *sitter.Node, content []byte) {
+	switch node.Type() {
+	case "import_statement":
+		e.module = true
+	case "export_statement":
+		e.module = true
+		if node.ChildByFieldName("source") != nil {
+			// Re-exports from another module declare nothing here.
+			break
+		}
+		for i := 0; i < int(node.NamedChildCount()); i++ {
+			child := node.NamedChild(i)
+			switch child.Type() {
+			case "export_clause":
+				for j := 0; j < int(child.NamedChildCount()); j++ {
+					if nameNode := child.NamedChild(j).ChildByFieldName("name"); nameNode != nil {
+						e.names[nameNode.Content(content)] = true
+					}
+				}
+			case "identifier":
+				e.names[child.Content(content)] = true
+			default:
+				e.starts[int(child.StartByte())] = true
+				for j := 0; j < int(child.NamedChildCount()); j++ {
+					if declarator := child.NamedChild(j); declarator.Type() == "variable_declarator" {
+						e.starts[int(declarator.StartByte())] = true
+					}
+				}
+			}
+		}
+	case "assignment_expression":
+		left := node.ChildByFieldName("left")
+		right := node.ChildByFieldName("right")
+		if left == nil || right == nil || !isCommonJSExport(left.Content(content)) {
+			break
+		}
+		e.module = true
+		switch right.Type() {
+		case "identifier":
+			e.names[right.Content(content)] = true
+		case "object":
+			for i := 0; i < int(right.NamedChildCount()); i++ {
+				property := right.NamedChild(i)
+				if property.Type() == "pair" {
+					property = property.ChildByFieldName("value")
+				}
+				if property != nil && (property.Type() == "identifier" || property.Type() == "shorthand_property_identifier") {
+					e.names[property.Content(content)] = true
+				}
+			}
+		}
+	}
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		e.collect(node.NamedChild(i), content)
+	}
+}
+
+// isCommonJSExport reports whether an assignment target is module.exports or
+// one of its properties.
+func isCommonJSExport(target string) bool {
+	return target == "module.exports" || strings.HasPrefix(target, "module.exports.") || strings.HasPrefix(target, "exports.")
+}
+
 func (t *TypeScriptParser) extractClass(node *sitter.Node, content []byte) *parser.Symbol {
 	nameNode := node.ChildByFieldName("name")
 	if nameNode == nil {
@@ -185,12 +446,214 @@ func (t *TypeScriptParser) extractClass(node *sitter.Node, content []byte) *pars
 	name := nameNode.Content(content)
 	sig := t.buildClassSignature(node, content)
 
+	var bases []parser.TypeRelation
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		heritage := node.NamedChild(i)
+		if heritage.Type() != "class_heritage" {
+			continue
+		}
+		for j := 0; j < int(heritage.NamedChildCount()); j++ {
+			clause := heritage.NamedChild(j)
+			switch clause.Type() {
+			case "extends_clause":
+				bases = appendTypeScriptBases(bases, clause, content, parser.RelationInherit)
+			case "implements_clause":
+				bases = appendTypeScriptBases(bases, clause, content, parser.RelationImplement)
+			default: // JavaScript: class A extends B
+				bases = appendTypeScriptBase(bases, clause, content, parser.RelationInherit)
+			}
+		}
+	}
+
+	kind := parser.SymbolClass
+	for _, base := range bases {
+		// class Profile extends React.Component / PureComponent
+		if name := parser.InnermostName(base.Name); base.Relation == parser.RelationInherit && (name == "Component" || name == "PureComponent") {
+			kind = parser.SymbolComponent
+		}
+	}
+
+	return &parser.Symbol{
+		Name:       name,
+		Kind:       kind,
+		Signature:  sig,
+		Line:       int(node.StartPoint().Row) + 1,
+		Span:       symbolSpan(node),
+		Decorators: typeScriptDecorators(node, content),
+		Bases:      bases,
+		References: t.references(func() []string { return typeScriptFieldReferences(node, content) }),
+	}
+}
+
+// typeScriptDecorators returns the decorators applied to a class or method
+// as written ("@Get(':id')"), in source order. Class members carry them as
+// preceding siblings in the class body; exported classes may carry them on
+// the export statement.
+func typeScriptDecorators(node *sitter.Node, content []byte) []string {
+	var decorators []string
+	for sibling := node.PrevNamedSibling(); sibling != nil && sibling.Type() == "decorator"; sibling = sibling.PrevNamedSibling() {
+		decorators = append([]string{typeScriptDecoratorText(sibling, content)}, decorators...)
+	}
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		if child := node.NamedChild(i); child.Type() == "decorator" {
+			decorators = append(decorators, typeScriptDecoratorText(child, content))
+		}
+	}
+	return decorators
+}
+
+func typeScriptDecoratorText(node *sitter.Node, content []byte) string {
+	return strings.Join(strings.Fields(node.Content(content)), " ")
+}
+
+// typeScriptAccessor returns "get" or "set" for accessor methods.
+func typeScriptAccessor(node *sitter.Node) string {
+	for i := 0; i < int(node.ChildCount()); i++ {
+		switch child := node.Child(i); child.Type() {
+		case "get", "set":
+			return child.Type()
+		case "property_identifier", "private_property_identifier", "computed_property_name":
+			return ""
+		}
+	}
+	return ""
+}
+
+func (t *TypeScriptParser) extractNamespace(node *sitter.Node, content []byte) *parser.Symbol {
+	nameNode := node.ChildByFieldName("name")
+	if nameNode == nil || (nameNode.Type() != "identifier" && nameNode.Type() != "nested_identifier") {
+		return nil
+	}
+
+	name := nameNode.Content(content)
+	keyword := "namespace"
+	if node.Type() == "module" {
+		keyword = "module"
+	}
+
+	return &parser.Symbol{
+		Name:      name,
+		Kind:      parser.SymbolModule,
+		Signature: keyword + " " + name,
+		Line:      int(node.StartPoint().Row) + 1,
+		Span:      symbolSpan(node),
+	}
+}
+
+// extractEnum records enum and const enum declarations as classes, as the
+// Java and C# parsers do.
+func (t *TypeScriptParser) extractEnum(node *sitter.Node, content []byte) *parser.Symbol {
+	nameNode := node.ChildByFieldName("name")
+	if nameNode == nil {
+		return nil
+	}
+
+	name := nameNode.Content(content)
+	sig := "enum " + name
+	if first := node.Child(0); first != nil && first.Type() == "const" {
+		sig = "const " + sig
+	}
+
 	return &parser.Symbol{
 		Name:      name,
 		Kind:      parser.SymbolClass,
 		Signature: sig,
 		Line:      int(node.StartPoint().Row) + 1,
+		Span:      symbolSpan(node),
+	}
+}
+
+// appendTypeScriptBases adds every type named in an extends or implements
+// clause.
+func appendTypeScriptBases(bases []parser.TypeRelation, clause *sitter.Node, content []byte, relation string) []parser.TypeRelation {
+	for i := 0; i < int(clause.NamedChildCount()); i++ {
+		bases = appendTypeScriptBase(bases, clause.NamedChild(i), content, relation)
+	}
+	return bases
+}
+
+// appendTypeScriptBase adds a named supertype; type arguments are dropped
+// and computed bases such as mixin(Base) are skipped.
+func appendTypeScriptBase(bases []parser.TypeRelation, node *sitter.Node, content []byte, relation string) []parser.TypeRelation {
+	if node.Type() == "generic_type" {
+		node = node.ChildByFieldName("name")
+	}
+	if node == nil {
+		return bases
+	}
+	switch node.Type() {
+	case "identifier", "type_identifier", "member_expression", "nested_type_identifier":
+		return append(bases, parser.TypeRelation{Name: node.Content(content), Relation: relation})
+	}
+	return bases
+}
+
+// typeScriptFunctionReferences returns the types a function, method or
+// arrow function names in its parameters, return type and body annotations.
+func typeScriptFunctionReferences(node *sitter.Node, content []byte) []string {
+	typeParams := typeScriptTypeParameters(node, content)
+	var refs []string
+	refs = typeScriptTypeReferences(refs, node.ChildByFieldName("parameters"), content, typeParams)
+	refs = typeScriptTypeReferences(refs, node.ChildByFieldName("return_type"), content, typeParams)
+	refs = typeScriptTypeReferences(refs, node.ChildByFieldName("body"), content, typeParams)
+	return refs
+}
+
+// typeScriptFieldReferences returns the types of a class's field
+// declarations; methods record their own references.
+func typeScriptFieldReferences(classNode *sitter.Node, content []byte) []string {
+	body := classNode.ChildByFieldName("body")
+	if body == nil {
+		return nil
+	}
+	typeParams := typeScriptTypeParameters(classNode, content)
+	var refs []string
+	for i := 0; i < int(body.NamedChildCount()); i++ {
+		if field := body.NamedChild(i); field.Type() == "public_field_definition" {
+			refs = typeScriptTypeReferences(refs, field.ChildByFieldName("type"), content, typeParams)
+		}
+	}
+	return refs
+}
+
+// typeScriptTypeReferences appends the named types under node. Builtins
+// such as string are predefined_type nodes and never match; nested classes
+// and type parameter lists are skipped.
+func typeScriptTypeReferences(refs []string, node *sitter.Node, content []byte, typeParams map[string]bool) []string {
+	if node == nil {
+		return refs
+	}
+	switch node.Type() {
+	case "type_identifier":
+		if name := node.Content(content); !typeParams[name] {
+			refs = appendReference(refs, name)
+		}
+		return refs
+	case "nested_type_identifier":
+		return appendReference(refs, node.Content(content))
+	case "type_parameters", "class_declaration", "class":
+		return refs
+	}
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		refs = typeScriptTypeReferences(refs, node.NamedChild(i), content, typeParams)
+	}
+	return refs
+}
+
+// typeScriptTypeParameters returns the names of a declaration's type
+// parameters (<T, K extends keyof T>).
+func typeScriptTypeParameters(node *sitter.Node, content []byte) map[string]bool {
+	list := node.ChildByFieldName("type_parameters")
+	if list == nil {
+		return nil
 	}
+	names := make(map[string]bool)
+	for i := 0; i < int(list.NamedChildCount()); i++ {
+		if nameNode := list.NamedChild(i).ChildByFieldName("name"); nameNode != nil {
+			names[nameNode.Content(content)] = true
+		}
+	}
+	return names
 }
 
 func (t *TypeScriptParser) extractInterface(node *sitter.Node, content []byte) *parser.Symbol {
@@ -201,11 +664,24 @@ func (t *TypeScriptParser) extractInterface(node *sitter.Node, content []byte) *
 
 	name := nameNode.Content(content)
 
+	var bases []parser.TypeRelation
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		if clause := node.NamedChild(i); clause.Type() == "extends_type_clause" {
+			bases = appendTypeScriptBases(bases, clause, content, parser.RelationInherit)
+		}
+	}
+
+	references := t.references(func() []string {
+		return typeScriptTypeReferences(nil, node.ChildByFieldName("body"), content, typeScriptTypeParameters(node, content))
+	})
 	return &parser.Symbol{
-		Name:      name,
-		Kind:      parser.SymbolInterface,
-		Signature: "interface " + name,
-		Line:      int(node.StartPoint().Row) + 1,
+		Name:       name,
+		Kind:       parser.SymbolInterface,
+		Signature:  "interface " + name,
+		Line:       int(node.StartPoint().Row) + 1,
+		Span:       symbolSpan(node),
+		Bases:      bases,
+		References: references,
 	}
 }
 
@@ -217,16 +693,28 @@ func (t *TypeScriptParser) extractTypeAlias(node *sitter.Node, content []byte) *
 
 	name := nameNode.Content(content)
 
+	references := t.references(func() []string {
+		return typeScriptTypeReferences(nil, node.ChildByFieldName("value"), content, typeScriptTypeParameters(node, content))
+	})
 	return &parser.Symbol{
-		Name:      name,
-		Kind:      parser.SymbolStruct, // Using struct for type aliases
-		Signature: "type " + name,
-		Line:      int(node.StartPoint().Row) + 1,
+		Name:       name,
+		Kind:       parser.SymbolStruct, // Using struct for type aliases
+		Signature:  "type " + name,
+		Line:       int(node.StartPoint().Row) + 1,
+		Span:       symbolSpan(node),
+		References: references,
 	}
 }
 
 func (t *TypeScriptParser) extractVariableDeclarations(node *sitter.Node, content []byte) []parser.Symbol {
 	symbols := make([]parser.Symbol, 0)
+	keyword, kind := "var", parser.SymbolVariable
+	if first := node.Child(0); first != nil && first.Type() != "var" {
+		keyword = first.Type()
+		if keyword == "const" {
+			kind = parser.SymbolConstant
+		}
+	}
 
 	for i := 0; i < int(node.ChildCount()); i++ {
 		child := node.Child(i)
@@ -234,7 +722,16 @@ func (t *TypeScriptParser) extractVariableDeclarations(node *sitter.Node, conten
 			nameNode := child.ChildByFieldName("name")
 			valueNode := child.ChildByFieldName("value")
 
-			if nameNode == nil || valueNode == nil {
+			if nameNode == nil {
+				continue
+			}
+			if valueNode == nil || (valueNode.Type() != "arrow_function" && valueNode.Type() != "function") {
+				// Other values are constants and variables, kept only at
+				// module or namespace level; typeScriptVisibility later
+				// drops those the module does not export.
+				if nameNode.Type() == "identifier" && typeScriptModuleScope(node) {
+					symbols = append(symbols, t.extractValueDeclarator(child, keyword, kind, content))
+				}
 				continue
 			}
 
@@ -242,12 +739,16 @@ func (t *TypeScriptParser) extractVariableDeclarations(node *sitter.Node, conten
 			if valueNode.Type() == "arrow_function" || valueNode.Type() == "function" {
 				name := nameNode.Content(content)
 				sig := t.buildArrowFunctionSignature(nameNode, valueNode, content)
+				body := valueNode.ChildByFieldName("body")
 				symbols = append(symbols, parser.Symbol{
-					Name:      name,
-					Kind:      parser.SymbolFunction,
-					Signature: sig,
-					Line:      int(child.StartPoint().Row) + 1,
-					Calls:     t.extractCalls(valueNode, content),
+					Name:       name,
+					Kind:       typeScriptFunctionKind(name, body),
+					Signature:  sig,
+					Line:       int(child.StartPoint().Row) + 1,
+					Span:       symbolSpan(child),
+					Renders:    typeScriptRenders(nil, body, content),
+					References: t.references(func() []string { return typeScriptFunctionReferences(valueNode, content) }),
+					Calls:      t.extractCalls(valueNode, content),
 				})
 			}
 		}
@@ -256,6 +757,55 @@ func (t *TypeScriptParser) extractVariableDeclarations(node *sitter.Node, conten
 	return symbols
 }
 
+// extractValueDeclarator returns the constant or variable symbol of a
+// declarator whose value is not a function.
+func (t *TypeScriptParser) extractValueDeclarator(node *sitter.Node, keyword string, kind parser.SymbolKind, content []byte) parser.Symbol {
+	name := node.ChildByFieldName("name").Content(content)
+	typeNode := node.ChildByFieldName("type")
+	valueNode := node.ChildByFieldName("value")
+	declaration := keyword + " " + name
+	if typeNode != nil {
+		declaration += typeNode.Content(content)
+	}
+	signature := declaration
+	if valueNode != nil {
+		signature = valueSignature(declaration, valueNode.Content(content))
+	}
+	references := t.references(func() []string {
+		return typeScriptTypeReferences(typeScriptTypeReferences(nil, typeNode, content, nil), valueNode, content, nil)
+	})
+	return parser.Symbol{
+		Name:       name,
+		Kind:       kind,
+		Signature:  signature,
+		Line:       int(node.StartPoint().Row) + 1,
+		Span:       symbolSpan(node),
+		References: references,
+		Calls:      t.extractCalls(valueNode, content),
+	}
+}
+
+// typeScriptModuleScope reports whether a declaration sits at the top level
+// of a module or namespace, possibly under export, rather than in a function
+// or callback.
+func typeScriptModuleScope(node *sitter.Node) bool {
+	parent := node.Parent()
+	if parent != nil && (parent.Type() == "export_statement" || parent.Type() == "ambient_declaration") {
+		parent = parent.Parent()
+	}
+	if parent == nil {
+		return false
+	}
+	switch parent.Type() {
+	case "program":
+		return true
+	case "statement_block":
+		grandparent := parent.Parent()
+		return grandparent != nil && (grandparent.Type() == "internal_module" || grandparent.Type() == "module")
+	}
+	return false
+}
+
 func (t *TypeScriptParser) extractImports(node *sitter.Node, content []byte) ([]string, map[string]string) {
 	imports := make([]string, 0)
 	aliases := make(map[string]string)
@@ -361,6 +911,9 @@ func (t *TypeScriptParser) buildArrowFunctionSignature(nameNode, valueNode *sitt
 }
 
 func (t *TypeScriptParser) extractCalls(node *sitter.Node, content []byte) []parser.CallSite {
+	if t.skip.SkipCalls {
+		return nil
+	}
 	if node == nil {
 		return nil
 	}
@@ -0,0 +1,113 @@
+package languages
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/morozRed/skelly/internal/parser"
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// expressRouteVerbs maps Express router methods to their HTTP verb.
+var expressRouteVerbs = map[string]string{
+	"get": "GET", "post": "POST", "put": "PUT", "patch": "PATCH", "delete": "DELETE",
+	"head": "HEAD", "options": "OPTIONS", "all": "ANY",
+}
+
+var expressRequirePattern = regexp.MustCompile(`require\(\s*['"]express['"]\s*\)`)
+
+// usesExpress reports whether a file imports or requires express; route
+// detection is limited to those files so HTTP clients (axios.get("/users",
+// config)) are not mistaken for routes.
+func usesExpress(imports []string, content []byte) bool {
+	return slices.Contains(imports, "express") || expressRequirePattern.Match(content)
+}
+
+// extractExpressRoutes returns the route symbols of Express registrations
+// anywhere in the file: app.get("/users/:id", auth, getUser), where the last
+// argument is the handler, and router.route("/users").get(list).post(create).
+func (t *TypeScriptParser) extractExpressRoutes(node *sitter.Node, content []byte, routes *[]parser.Symbol) {
+	if node.Type() == "call_expression" {
+		if route, ok := t.expressRoute(node, content); ok {
+			*routes = append(*routes, route)
+		}
+	}
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		t.extractExpressRoutes(node.NamedChild(i), content, routes)
+	}
+}
+
+func (t *TypeScriptParser) expressRoute(call *sitter.Node, content []byte) (parser.Symbol, bool) {
+	fn := call.ChildByFieldName("function")
+	if fn == nil || fn.Type() != "member_expression" {
+		return parser.Symbol{}, false
+	}
+	object, property := fn.ChildByFieldName("object"), fn.ChildByFieldName("property")
+	if object == nil || property == nil {
+		return parser.Symbol{}, false
+	}
+	verb, ok := expressRouteVerbs[property.Content(content)]
+	if !ok {
+		return parser.Symbol{}, false
+	}
+	args := namedArguments(call.ChildByFieldName("arguments"))
+
+	path, chained := expressChainPath(object, content)
+	if !chained {
+		// app.get("name") reads a setting; routes take a path and a handler.
+		if len(args) < 2 {
+			return parser.Symbol{}, false
+		}
+		if path, ok = stringLiteral(args[0], content); !ok {
+			return parser.Symbol{}, false
+		}
+	}
+	if len(args) == 0 || !strings.HasPrefix(path, "/") {
+		return parser.Symbol{}, false
+	}
+
+	var handler *parser.CallSite
+	if last := args[len(args)-1]; last.Type() == "identifier" || last.Type() == "member_expression" {
+		name, qualifier := t.extractCallName(last, content)
+		handler = &parser.CallSite{
+			Name:      name,
+			Qualifier: qualifier,
+			Raw:       strings.TrimSpace(last.Content(content)),
+		}
+		if qualifier == "this" {
+			handler.Receiver = qualifier
+		}
+	}
+	return routeSymbol(verb, path, call, content, handler), true
+}
+
+// expressChainPath returns the path of the router.route("/users") call a
+// verb method is chained onto, directly or after other verbs.
+func expressChainPath(object *sitter.Node, content []byte) (string, bool) {
+	for object.Type() == "call_expression" {
+		fn := object.ChildByFieldName("function")
+		if fn == nil || fn.Type() != "member_expression" {
+			return "", false
+		}
+		property := fn.ChildByFieldName("property")
+		if property == nil {
+			return "", false
+		}
+		if property.Content(content) == "route" {
+			args := namedArguments(object.ChildByFieldName("arguments"))
+			if len(args) != 1 {
+				return "", false
+			}
+			return stringLiteral(args[0], content)
+		}
+		if _, ok := expressRouteVerbs[property.Content(content)]; !ok {
+			return "", false
+		}
+		object = fn.ChildByFieldName("object")
+		if object == nil {
+			return "", false
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,84 @@
+package languages
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/morozRed/skelly/internal/parser"
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// extractTestBlocks returns a function symbol for each it() and test() block
+// of a test file, named by its title, contained by the titles of its
+// enclosing describe() blocks ("InvoiceService > create") and calling what
+// its callback calls. `.only`, `.skip` and `.concurrent` variants count too.
+func (t *TypeScriptParser) extractTestBlocks(node *sitter.Node, content []byte, container string, blocks *[]parser.Symbol) {
+	if node.Type() == "call_expression" {
+		if kind, title, callback, ok := typeScriptTestBlock(node, content); ok {
+			if kind == "describe" {
+				if container != "" {
+					title = container + " > " + title
+				}
+				t.extractTestBlocks(callback, content, title, blocks)
+				return
+			}
+			body := callback.ChildByFieldName("body")
+			*blocks = append(*blocks, parser.Symbol{
+				Name:      title,
+				Kind:      parser.SymbolFunction,
+				Signature: kind + "(" + strconv.Quote(title) + ")",
+				Line:      int(node.StartPoint().Row) + 1,
+				Span:      symbolSpan(node),
+				Container: container,
+				Renders:   typeScriptRenders(nil, body, content),
+				Calls:     t.extractCalls(body, content),
+			})
+			return
+		}
+	}
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		t.extractTestBlocks(node.NamedChild(i), content, container, blocks)
+	}
+}
+
+// typeScriptTestBlock recognizes describe("title", () => {...}),
+// it("title", ...) and test("title", ...), returning the block kind, title
+// and callback.
+func typeScriptTestBlock(call *sitter.Node, content []byte) (kind, title string, callback *sitter.Node, ok bool) {
+	fn := call.ChildByFieldName("function")
+	if fn == nil {
+		return "", "", nil, false
+	}
+	if fn.Type() == "member_expression" {
+		switch property := fn.ChildByFieldName("property"); {
+		case property == nil:
+			return "", "", nil, false
+		case property.Content(content) == "only", property.Content(content) == "skip", property.Content(content) == "concurrent":
+			fn = fn.ChildByFieldName("object")
+		default:
+			return "", "", nil, false
+		}
+	}
+	if fn == nil || fn.Type() != "identifier" {
+		return "", "", nil, false
+	}
+	switch kind = fn.Content(content); kind {
+	case "describe", "it", "test":
+	default:
+		return "", "", nil, false
+	}
+	args := namedArguments(call.ChildByFieldName("arguments"))
+	if len(args) < 2 {
+		return "", "", nil, false
+	}
+	title, ok = stringLiteral(args[0], content)
+	if !ok && args[0].Type() == "template_string" {
+		// it(`handles ${kind}`) keeps the template as its title.
+		title, ok = strings.Trim(args[0].Content(content), "`"), true
+	}
+	callback = args[1]
+	if !ok || (callback.Type() != "arrow_function" && callback.Type() != "function_expression" && callback.Type() != "function") {
+		return "", "", nil, false
+	}
+	return kind, title, callback, true
+}
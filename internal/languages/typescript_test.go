@@ -1,6 +1,7 @@
 package languages
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/morozRed/skelly/internal/parser"
@@ -51,3 +52,396 @@ func findSignatureByName(symbols []parser.Symbol, name string) string {
 	}
 	return ""
 }
+
+func TestTypeScriptParserRecordsHeritageClauses(t *testing.T) {
+	file, err := NewTypeScriptParser().Parse("repo.ts", []byte(`interface Repo<T> extends Reader, base.Writer<T> {}
+class UserRepo extends BaseRepo<User> implements Repo<User>, Disposable {}
+class Mixed extends mixin(BaseRepo) {}
+`))
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	want := map[string][]parser.TypeRelation{
+		"Repo": {
+			{Name: "Reader", Relation: parser.RelationInherit},
+			{Name: "base.Writer", Relation: parser.RelationInherit},
+		},
+		"UserRepo": {
+			{Name: "BaseRepo", Relation: parser.RelationInherit},
+			{Name: "Repo", Relation: parser.RelationImplement},
+			{Name: "Disposable", Relation: parser.RelationImplement},
+		},
+		"Mixed": nil,
+	}
+	for _, symbol := range file.Symbols {
+		expected, ok := want[symbol.Name]
+		if !ok {
+			continue
+		}
+		if len(symbol.Bases) != len(expected) {
+			t.Fatalf("expected %s bases %#v, got %#v", symbol.Name, expected, symbol.Bases)
+		}
+		for i := range expected {
+			if symbol.Bases[i] != expected[i] {
+				t.Fatalf("expected %s bases %#v, got %#v", symbol.Name, expected, symbol.Bases)
+			}
+		}
+	}
+}
+
+func TestTypeScriptParserRecordsTypeReferences(t *testing.T) {
+	file, err := NewTypeScriptParser().Parse("orders.ts", []byte(`class Cart<T> {
+  owner: User;
+  items: models.Item<T>[];
+  total(discount: Discount): Money { const r: Receipt = build(); return r.sum }
+}
+interface Repo { find(id: string): Promise<Order> }
+function place<K>(cart: Cart<K>, key: K): number { return 0 }
+`))
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	want := map[string][]string{
+		"Cart":  {"User", "models.Item"},
+		"total": {"Discount", "Money", "Receipt"},
+		"Repo":  {"Promise", "Order"},
+		"place": {"Cart"},
+	}
+	for _, symbol := range file.Symbols {
+		refs, ok := want[symbol.Name]
+		if !ok {
+			continue
+		}
+		if strings.Join(symbol.References, ",") != strings.Join(refs, ",") {
+			t.Fatalf("expected %s to reference %v, got %v", symbol.Name, refs, symbol.References)
+		}
+	}
+}
+
+func TestTypeScriptParserRecordsDecoratorsAccessorsEnumsAndNamespaces(t *testing.T) {
+	file, err := NewTypeScriptParser().Parse("users.controller.ts", []byte(`@Controller('users')
+export class UsersController {
+  @Input() name: string;
+
+  @Get(':id')
+  @UseGuards(AuthGuard)
+  find(id: string) {}
+
+  get total(): number { return 1 }
+  set total(value: number) {}
+}
+
+export enum Role { Admin, Member = "member" }
+const enum Direction { Up }
+
+namespace Billing {
+  export namespace Invoices {
+    export function total(): number { return 0 }
+  }
+}
+`))
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	got := make([]string, 0, len(file.Symbols))
+	decorators := make(map[string]string)
+	for _, symbol := range file.Symbols {
+		got = append(got, symbol.Kind.String()+" "+symbol.QualifiedName()+" | "+symbol.Signature)
+		if len(symbol.Decorators) > 0 {
+			decorators[symbol.QualifiedName()] = strings.Join(symbol.Decorators, " ")
+		}
+	}
+	want := []string{
+		"class UsersController | class UsersController",
+		"method UsersController.find | find(id: string)",
+		"method UsersController.total | get total(): number",
+		"method UsersController.total | set total(value: number)",
+		"class Role | enum Role",
+		"class Direction | const enum Direction",
+		"module Billing | namespace Billing",
+		"module Billing.Invoices | namespace Invoices",
+		"func Billing.Invoices.total | function total(): number",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("unexpected symbols:\n%s", strings.Join(got, "\n"))
+	}
+	if decorators["UsersController"] != "@Controller('users')" {
+		t.Fatalf("unexpected class decorators %q", decorators["UsersController"])
+	}
+	if decorators["UsersController.find"] != "@Get(':id') @UseGuards(AuthGuard)" {
+		t.Fatalf("unexpected method decorators %q", decorators["UsersController.find"])
+	}
+	if len(decorators) != 2 {
+		t.Fatalf("expected only the class and find to carry decorators, got %#v", decorators)
+	}
+}
+
+func TestTypeScriptParserDetectsReactComponents(t *testing.T) {
+	file, err := NewTypeScriptParser().Parse("App.tsx", []byte(`import { Button } from "./Button"
+import * as UI from "./ui"
+
+export function App({ user }: Props) {
+  const renderRow = () => <Row/>
+  return (<Layout><UI.Card title={user.name}/><Button/><div/><></></Layout>)
+}
+export const Avatar = () => <img/>
+function formatName(name: string) { return name.trim() }
+class Profile extends React.Component<Props> {
+  render() { return <Avatar/> }
+}
+`))
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	kinds := make(map[string]string)
+	renders := make(map[string]string)
+	for _, symbol := range file.Symbols {
+		kinds[symbol.QualifiedName()] = symbol.Kind.String()
+		renders[symbol.QualifiedName()] = strings.Join(symbol.Renders, ",")
+	}
+	wantKinds := map[string]string{
+		"App":            "component",
+		"Avatar":         "component",
+		"formatName":     "func",
+		"Profile":        "component",
+		"Profile.render": "method",
+	}
+	for name, kind := range wantKinds {
+		if kinds[name] != kind {
+			t.Fatalf("expected %s to be a %s, got %q (all: %#v)", name, kind, kinds[name], kinds)
+		}
+	}
+	if renders["App"] != "Row,Layout,UI.Card,Button" {
+		t.Fatalf("unexpected App renders %q", renders["App"])
+	}
+	if renders["Profile.render"] != "Avatar" || renders["Avatar"] != "" {
+		t.Fatalf("unexpected renders %#v", renders)
+	}
+}
+
+func TestTypeScriptParserExtractsExpressRoutes(t *testing.T) {
+	file, err := NewTypeScriptParser().Parse("app.js", []byte(`const express = require("express")
+const users = require("./users")
+
+const app = express()
+app.set("view engine", "pug")
+app.get("/users/:id", auth, users.show)
+app.post("/login", (req, res) => res.send("ok"))
+app.route("/books").get(listBooks).post(createBook)
+app.get("env")
+`))
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	var got []string
+	for _, symbol := range file.Symbols {
+		if symbol.Kind != parser.SymbolRoute {
+			continue
+		}
+		target := ""
+		if len(symbol.Calls) == 1 {
+			target = symbol.Calls[0].Raw
+		}
+		got = append(got, symbol.Name+" -> "+target)
+	}
+	want := []string{
+		"GET /users/:id -> users.show",
+		"POST /login -> ",
+		"POST /books -> createBook",
+		"GET /books -> listBooks",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("unexpected routes:\n%s", strings.Join(got, "\n"))
+	}
+
+	client, err := NewTypeScriptParser().Parse("client.ts", []byte(`import axios from "axios"
+axios.post("/users", payload)
+`))
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	if len(client.Symbols) != 0 {
+		t.Fatalf("expected no routes outside express files, got %#v", client.Symbols)
+	}
+}
+
+func TestTypeScriptParserExtractsTestBlocksInSpecFiles(t *testing.T) {
+	source := []byte(`import { render } from "@testing-library/react"
+import { InvoiceService } from "./invoice"
+
+describe("InvoiceService", () => {
+  describe("create", () => {
+    it("adds tax", () => {
+      const service = new InvoiceService()
+      expect(service.total(10)).toBe(12)
+    })
+  })
+  test.only("renders the summary", () => {
+    render(<Summary />)
+  })
+})
+`)
+	file, err := NewTypeScriptParser().Parse("src/invoice.spec.tsx", source)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	var got []string
+	for _, symbol := range file.Symbols {
+		got = append(got, symbol.Container+" | "+symbol.Signature)
+	}
+	want := []string{
+		`InvoiceService > create | it("adds tax")`,
+		`InvoiceService | test("renders the summary")`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("unexpected test blocks:\n%s", strings.Join(got, "\n"))
+	}
+	foundTotal := false
+	for _, call := range file.Symbols[0].Calls {
+		if call.Name == "total" && call.Qualifier == "service" {
+			foundTotal = true
+		}
+	}
+	if !foundTotal {
+		t.Fatalf("expected the it block to call service.total, got %#v", file.Symbols[0].Calls)
+	}
+	if len(file.Symbols[1].Renders) != 1 || file.Symbols[1].Renders[0] != "Summary" {
+		t.Fatalf("expected the test block to render Summary, got %#v", file.Symbols[1].Renders)
+	}
+
+	production, err := NewTypeScriptParser().Parse("src/invoice.tsx", source)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	if len(production.Symbols) != 0 {
+		t.Fatalf("expected no test blocks outside test files, got %#v", production.Symbols)
+	}
+}
+
+func TestTypeScriptParserRecordsExportsAndMemberVisibility(t *testing.T) {
+	parse := func(filename, source string) map[string]string {
+		t.Helper()
+		file, err := NewTypeScriptParser().Parse(filename, []byte(source))
+		if err != nil {
+			t.Fatalf("parse failed: %v", err)
+		}
+		visibilities := make(map[string]string)
+		for _, symbol := range file.Symbols {
+			visibilities[symbol.QualifiedName()] = symbol.Visibility
+		}
+		return visibilities
+	}
+	expect := func(got, want map[string]string) {
+		t.Helper()
+		for name, visibility := range want {
+			if got[name] != visibility {
+				t.Errorf("%s: expected visibility %q, got %q (all: %v)", name, visibility, got[name], got)
+			}
+		}
+	}
+
+	expect(parse("store.ts", `import { db } from "./db";
+export function load() {}
+export const save = () => {};
+function helper() {}
+function listed() {}
+export { listed };
+class Cache {
+  get() {}
+  protected evict() {}
+  private resize() {}
+  #touch() {}
+}
+export default Cache;
+namespace Internal {
+  export function exposed() {}
+  function hidden() {}
+}
+`), map[string]string{
+		"load":             parser.VisibilityPublic,
+		"save":             parser.VisibilityPublic,
+		"helper":           parser.VisibilityPrivate,
+		"listed":           parser.VisibilityPublic,
+		"Cache":            parser.VisibilityPublic,
+		"Cache.get":        parser.VisibilityPublic,
+		"Cache.evict":      parser.VisibilityProtected,
+		"Cache.resize":     parser.VisibilityPrivate,
+		"Cache.#touch":     parser.VisibilityPrivate,
+		"Internal":         parser.VisibilityPrivate,
+		"Internal.exposed": parser.VisibilityPublic,
+		"Internal.hidden":  parser.VisibilityPrivate,
+	})
+
+	expect(parse("legacy.js", `function run() {}
+function format() {}
+function parse() {}
+module.exports = { run, parseInput: parse };
+`), map[string]string{
+		"run":    parser.VisibilityPublic,
+		"format": parser.VisibilityPrivate,
+		"parse":  parser.VisibilityPublic,
+	})
+
+	// Without imports or exports a script's declarations are global.
+	expect(parse("script.js", `function boot() {}
+`), map[string]string{"boot": parser.VisibilityPublic})
+}
+
+func TestTypeScriptParserExtractsExportedConstantsAndVariables(t *testing.T) {
+	file, err := NewTypeScriptParser().Parse("config.ts", []byte(`import { load } from "./load";
+export const API_URL = "https://api.example.com";
+export const defaults: Options = load();
+export let attempts = 0;
+const secret = "hidden";
+const listed = 1;
+export { listed };
+export const handler = () => 1;
+export function boot() {
+  const local = 2;
+}
+`))
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	got := make([]string, 0)
+	for _, symbol := range file.Symbols {
+		if symbol.Kind == parser.SymbolConstant || symbol.Kind == parser.SymbolVariable {
+			got = append(got, symbol.Kind.String()+" "+symbol.Signature)
+		}
+		if symbol.Name == "defaults" && (len(symbol.References) != 1 || symbol.References[0] != "Options" || len(symbol.Calls) != 1) {
+			t.Fatalf("expected defaults to reference Options and call load, got %+v", symbol)
+		}
+	}
+	want := []string{
+		`const const API_URL = "https://api.example.com"`,
+		"const const defaults: Options = load()",
+		"var let attempts = 0",
+		"const const listed = 1",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("unexpected constants and variables:\n%s", strings.Join(got, "\n"))
+	}
+}
+
+func TestTypeScriptParserSelectsFeaturesByFileLanguage(t *testing.T) {
+	tsParser := NewTypeScriptParser()
+	tsParser.SetFeatures(map[string]parser.Features{"javascript": {SkipCalls: true}})
+	source := []byte("function load(path) { return fetch(path) }\n")
+
+	for filename, calls := range map[string]int{"vendor.js": 0, "app.ts": 1} {
+		file, err := tsParser.Parse(filename, source)
+		if err != nil {
+			t.Fatalf("parse %s failed: %v", filename, err)
+		}
+		if len(file.Symbols) != 1 || len(file.Symbols[0].Calls) != calls {
+			t.Fatalf("expected %d calls in %s, got %#v", calls, filename, file.Symbols)
+		}
+	}
+}
@@ -1,6 +1,8 @@
 package llm
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"os"
 	"strings"
@@ -11,8 +13,31 @@ import (
 const (
 	ManagedBlockStart = "<!-- skelly:managed:start -->"
 	ManagedBlockEnd   = "<!-- skelly:managed:end -->"
+
+	// ManagedTemplateVersion is bumped whenever managed block templates change shape.
+	ManagedTemplateVersion = "1"
+
+	managedProvenancePrefix = "<!-- skelly:managed:provenance "
+	managedProvenanceSuffix = " -->"
 )
 
+// Managed block statuses reported by CheckManagedBlock.
+const (
+	ManagedBlockCurrent  = "current"
+	ManagedBlockOutdated = "outdated"
+	ManagedBlockModified = "modified"
+	ManagedBlockMissing  = "missing"
+)
+
+// ManagedBlockStatus describes provenance of one managed markdown block.
+type ManagedBlockStatus struct {
+	File     string `json:"file"`
+	Status   string `json:"status"`
+	Version  string `json:"version,omitempty"`
+	Hash     string `json:"hash,omitempty"`
+	Expected string `json:"expected_hash"`
+}
+
 func UpsertManagedMarkdownFile(path, body string) (bool, error) {
 	existing := ""
 	if data, err := os.ReadFile(path); err == nil {
@@ -21,11 +46,77 @@ func UpsertManagedMarkdownFile(path, body string) (bool, error) {
 		return false, fmt.Errorf("failed to read %s: %w", path, err)
 	}
 
-	managed := fmt.Sprintf("%s\n%s\n%s", ManagedBlockStart, strings.TrimSpace(body), ManagedBlockEnd)
-	updated := UpsertManagedBlock(existing, ManagedBlockStart, ManagedBlockEnd, managed)
+	updated := UpsertManagedBlock(existing, ManagedBlockStart, ManagedBlockEnd, BuildManagedBlock(body))
 	return fileutil.WriteIfChangedTracked(path, []byte(updated))
 }
 
+// BuildManagedBlock wraps body in managed markers with a provenance comment
+// recording the template version and body hash.
+func BuildManagedBlock(body string) string {
+	body = strings.TrimSpace(body)
+	provenance := fmt.Sprintf("%sversion=%s hash=%s%s", managedProvenancePrefix, ManagedTemplateVersion, ManagedBodyHash(body), managedProvenanceSuffix)
+	return fmt.Sprintf("%s\n%s\n%s\n%s", ManagedBlockStart, provenance, body, ManagedBlockEnd)
+}
+
+func ManagedBodyHash(body string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(body)))
+	return hex.EncodeToString(sum[:])[:16]
+}
+
+// CheckManagedBlock compares the managed block in path against the body the
+// current templates would write.
+func CheckManagedBlock(path, file, expectedBody string) ManagedBlockStatus {
+	status := ManagedBlockStatus{
+		File:     file,
+		Status:   ManagedBlockMissing,
+		Expected: ManagedBodyHash(expectedBody),
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return status
+	}
+	inner, ok := extractManagedInner(string(data))
+	if !ok {
+		return status
+	}
+
+	body := inner
+	firstLine, rest, _ := strings.Cut(inner, "\n")
+	if strings.HasPrefix(firstLine, managedProvenancePrefix) && strings.HasSuffix(firstLine, managedProvenanceSuffix) {
+		fields := strings.Fields(strings.TrimSuffix(strings.TrimPrefix(firstLine, managedProvenancePrefix), managedProvenanceSuffix))
+		for _, field := range fields {
+			key, value, _ := strings.Cut(field, "=")
+			switch key {
+			case "version":
+				status.Version = value
+			case "hash":
+				status.Hash = value
+			}
+		}
+		body = rest
+	}
+
+	switch {
+	case status.Hash == "" || status.Version != ManagedTemplateVersion || status.Hash != status.Expected:
+		status.Status = ManagedBlockOutdated
+	case ManagedBodyHash(body) != status.Hash:
+		status.Status = ManagedBlockModified
+	default:
+		status.Status = ManagedBlockCurrent
+	}
+	return status
+}
+
+func extractManagedInner(text string) (string, bool) {
+	start := strings.Index(text, ManagedBlockStart)
+	end := strings.Index(text, ManagedBlockEnd)
+	if start < 0 || end < start {
+		return "", false
+	}
+	return strings.TrimSpace(text[start+len(ManagedBlockStart) : end]), true
+}
+
 func UpsertManagedBlock(existing, startMarker, endMarker, managedContent string) string {
 	if existing == "" {
 		return managedContent + "\n"
@@ -131,12 +131,61 @@ func GenerateIntegrationFiles(rootPath string, providers []string) ([]string, er
 	return updated, nil
 }
 
+// ManagedMarkdownFile is a repository file whose managed block skelly owns.
+type ManagedMarkdownFile struct {
+	Path        string
+	Integration string
+	Body        string
+}
+
+func ManagedMarkdownFiles() []ManagedMarkdownFile {
+	return []ManagedMarkdownFile{
+		{Path: "AGENTS.md", Integration: "codex", Body: BuildRootAdapterBlock("Codex")},
+		{Path: "CLAUDE.md", Integration: "claude", Body: BuildRootAdapterBlock("Claude")},
+		{Path: "CONTEXT.md", Integration: "context", Body: BuildContextBlock()},
+	}
+}
+
+// CheckManagedBlocks reports provenance for every managed markdown file that has a block.
+func CheckManagedBlocks(rootPath string) []ManagedBlockStatus {
+	statuses := make([]ManagedBlockStatus, 0)
+	for _, file := range ManagedMarkdownFiles() {
+		status := CheckManagedBlock(filepath.Join(rootPath, file.Path), file.Path, file.Body)
+		if status.Status == ManagedBlockMissing {
+			continue
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// RefreshManagedBlocks rewrites only managed blocks whose provenance is outdated.
+// Files without a managed block are left alone.
+func RefreshManagedBlocks(rootPath string) ([]string, error) {
+	refreshed := make([]string, 0)
+	for _, file := range ManagedMarkdownFiles() {
+		path := filepath.Join(rootPath, file.Path)
+		if CheckManagedBlock(path, file.Path, file.Body).Status != ManagedBlockOutdated {
+			continue
+		}
+		changed, err := UpsertManagedMarkdownFile(path, file.Body)
+		if err != nil {
+			return nil, err
+		}
+		if changed {
+			refreshed = append(refreshed, file.Path)
+		}
+	}
+	return refreshed, nil
+}
+
 func DetectContextFormat(contextDir string) string {
 	hasText := fileExists(filepath.Join(contextDir, output.IndexFile)) &&
 		fileExists(filepath.Join(contextDir, output.GraphFile))
-	hasJSONL := fileExists(filepath.Join(contextDir, output.SymbolsFile)) &&
-		fileExists(filepath.Join(contextDir, output.EdgesFile)) &&
+	hasJSONL := fileExists(fileutil.ArtifactPath(filepath.Join(contextDir, output.SymbolsFile))) &&
+		fileExists(fileutil.ArtifactPath(filepath.Join(contextDir, output.EdgesFile))) &&
 		fileExists(filepath.Join(contextDir, output.ManifestFile))
+	hasCtags := fileExists(filepath.Join(contextDir, output.TagsFile))
 
 	switch {
 	case hasText && hasJSONL:
@@ -145,6 +194,8 @@ func DetectContextFormat(contextDir string) string {
 		return string(output.FormatJSONL)
 	case hasText:
 		return string(output.FormatText)
+	case hasCtags:
+		return string(output.FormatCtags)
 	default:
 		return "none"
 	}
@@ -13,6 +13,7 @@ Workflow:
 3. Use navigation commands first: skelly symbol, skelly callers, skelly callees, skelly trace, skelly path.
 4. Use skelly status before major changes to understand impacted files.
 5. Avoid reading .skelly/.context/* files directly unless debugging or CLI output is insufficient.
+6. Before writing code, read .skelly/conventions.md (if present) for naming, directory, error-handling, and test conventions.
 `
 }
 
@@ -31,6 +32,7 @@ This repository uses Skelly as the canonical code context layer for LLM tools.
   - skelly path <from> <to>
   - skelly status
 - Treat .skelly/.context/* files as implementation detail; avoid direct reads unless debugging.
+- Project conventions: .skelly/conventions.md (generated by skelly conventions; follow it when writing code).
 
 Recommended command sequence:
 1. skelly doctor
@@ -48,6 +50,7 @@ Use Skelly outputs before broad code reads.
 2. If stale, run skelly update.
 3. Follow .skelly/skills/skelly.md.
 4. Prefer Skelly CLI commands over direct reads of .skelly/.context/* files.
+5. Follow .skelly/conventions.md when present.
 `, agentName)
 }
 
@@ -61,5 +64,6 @@ Run skelly doctor first. If context is stale, run skelly update.
 Use .skelly/skills/skelly.md and CONTEXT.md as primary guidance.
 Prefer Skelly CLI commands (symbol/callers/callees/trace/path/status) for navigation and impact analysis.
 Avoid direct reads of .skelly/.context/* unless debugging or CLI output is insufficient.
+Follow .skelly/conventions.md (if present) when writing code.
 `
 }
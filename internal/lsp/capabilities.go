@@ -18,6 +18,12 @@ var languageServers = map[string][]string{
 	"python":     {"pyright-langserver", "pylsp"},
 	"typescript": {"typescript-language-server"},
 	"ruby":       {"solargraph"},
+	"rust":       {"rust-analyzer"},
+	"java":       {"jdtls"},
+	"c":          {"clangd"},
+	"cpp":        {"clangd"},
+	"php":        {"intelephense", "phpactor"},
+	"csharp":     {"csharp-ls", "OmniSharp"},
 }
 
 var languageExtensions = map[string][]string{
@@ -25,6 +31,12 @@ var languageExtensions = map[string][]string{
 	"python":     {".py"},
 	"typescript": {".ts", ".tsx", ".js", ".jsx", ".mjs", ".cjs"},
 	"ruby":       {".rb"},
+	"rust":       {".rs"},
+	"java":       {".java"},
+	"c":          {".c", ".h"},
+	"cpp":        {".cc", ".cpp", ".cxx", ".c++", ".hh", ".hpp", ".hxx", ".h++"},
+	"php":        {".php"},
+	"csharp":     {".cs"},
 }
 
 func LanguageForPath(path string) (string, bool) {
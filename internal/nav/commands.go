@@ -3,9 +3,13 @@ package nav
 import (
 	"fmt"
 	"os"
+	"slices"
 	"sort"
+	"strings"
 
 	"github.com/morozRed/skelly/internal/fileutil"
+	"github.com/morozRed/skelly/internal/graph"
+	"github.com/morozRed/skelly/internal/languages"
 	"github.com/morozRed/skelly/internal/lsp"
 	"github.com/morozRed/skelly/internal/search"
 	"github.com/spf13/cobra"
@@ -20,6 +24,10 @@ func RunSymbol(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	withSummary, err := OptionalBoolFlag(cmd, "with-summary", false)
+	if err != nil {
+		return err
+	}
 	fuzzy, err := OptionalBoolFlag(cmd, "fuzzy", false)
 	if err != nil {
 		return err
@@ -33,21 +41,28 @@ func RunSymbol(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	if lookup, err = externalView(cmd, lookup); err != nil {
+		return err
+	}
 	var searchIndex *search.Index
 	if fuzzy {
-		searchIndex, err = search.Load(rootPath)
+		searchIndex, err = LoadSearchIndex(rootPath)
 		if err != nil {
 			return err
 		}
 	}
 	matches := ResolveWithOptions(lookup, searchIndex, args[0], ResolveOptions{Fuzzy: fuzzy, Limit: limit})
 	if len(matches) == 0 {
-		return fmt.Errorf("symbol %q not found", args[0])
+		return symbolNotFound(lookup, args[0])
 	}
 
 	records := make([]SymbolRecord, 0, len(matches))
 	for _, match := range matches {
-		records = append(records, SymbolRecordFromNode(match))
+		record := SymbolRecordFromNode(match)
+		if withSummary {
+			record.Summary = match.Summary
+		}
+		records = append(records, record)
 	}
 
 	if asJSON {
@@ -63,6 +78,7 @@ func RunSymbol(cmd *cobra.Command, args []string) error {
 		if record.Signature != "" {
 			fmt.Printf("  sig: %s\n", record.Signature)
 		}
+		printSummary(record)
 	}
 	return nil
 }
@@ -76,15 +92,33 @@ func RunCallers(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	withSummary, err := OptionalBoolFlag(cmd, "with-summary", false)
+	if err != nil {
+		return err
+	}
 	useLSP, err := OptionalBoolFlag(cmd, "lsp", false)
 	if err != nil {
 		return err
 	}
 
+	kinds, err := OptionalEdgeKinds(cmd, "kind")
+	if err != nil {
+		return err
+	}
+	includeReferences, err := OptionalBoolFlag(cmd, "include-references", false)
+	if err != nil {
+		return err
+	}
+	kinds = callerEdgeKinds(kinds, includeReferences)
+
 	lookup, err := LoadLookup(rootPath)
 	if err != nil {
 		return err
 	}
+	lookup = lookup.FilterEdgeKinds(kinds)
+	if lookup, err = externalView(cmd, lookup); err != nil {
+		return err
+	}
 	node, err := ResolveSingleSymbol(lookup, args[0])
 	if err != nil {
 		return err
@@ -100,10 +134,17 @@ func RunCallers(cmd *cobra.Command, args []string) error {
 			callers[i].Source = "parser"
 		}
 	}
+	symbol := SymbolRecordFromNode(node)
+	if withSummary {
+		attachSummaries(lookup, &symbol)
+		for i := range callers {
+			attachSummaries(lookup, &callers[i].Symbol)
+		}
+	}
 	if asJSON {
 		payload := map[string]any{
 			"query":   args[0],
-			"symbol":  SymbolRecordFromNode(node),
+			"symbol":  symbol,
 			"callers": callers,
 		}
 		if lspStatus != nil {
@@ -122,10 +163,12 @@ func RunCallers(cmd *cobra.Command, args []string) error {
 		if caller.Confidence != "" {
 			fmt.Printf(" (%s)", caller.Confidence)
 		}
+		printEdgeKind(caller.Kind)
 		if caller.Source != "" {
 			fmt.Printf(" source=%s", caller.Source)
 		}
 		fmt.Println()
+		printSummary(caller.Symbol)
 	}
 	if useLSP && lspStatus != nil && !lspStatus.Available {
 		fmt.Printf("note: lsp unavailable (%s); run skelly doctor for details\n", lspStatus.Reason)
@@ -133,6 +176,55 @@ func RunCallers(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// RunImplementations lists the types implementing an interface, or the
+// interfaces implemented by a type.
+func RunImplementations(cmd *cobra.Command, args []string) error {
+	rootPath, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to resolve working directory: %w", err)
+	}
+	asJSON, err := OptionalBoolFlag(cmd, "json", false)
+	if err != nil {
+		return err
+	}
+
+	lookup, err := LoadLookup(rootPath)
+	if err != nil {
+		return err
+	}
+	node, err := ResolveSingleSymbol(lookup, args[0])
+	if err != nil {
+		return err
+	}
+
+	related := CollectImplementations(lookup, node)
+	if asJSON {
+		key := "implementations"
+		if node.Kind != "interface" {
+			key = "implements"
+		}
+		return fileutil.PrintJSON(map[string]any{
+			"query":  args[0],
+			"symbol": SymbolRecordFromNode(node),
+			key:      related,
+		})
+	}
+
+	if node.Kind == "interface" {
+		fmt.Printf("implementations of %s (%d)\n", node.ID, len(related))
+	} else {
+		fmt.Printf("interfaces implemented by %s (%d)\n", node.ID, len(related))
+	}
+	if len(related) == 0 {
+		fmt.Println("none found")
+		return nil
+	}
+	for _, record := range related {
+		fmt.Printf("- %s [%s] %s:%d\n", record.ID, record.Kind, record.File, record.Line)
+	}
+	return nil
+}
+
 func RunCallees(cmd *cobra.Command, args []string) error {
 	rootPath, err := os.Getwd()
 	if err != nil {
@@ -142,15 +234,28 @@ func RunCallees(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	withSummary, err := OptionalBoolFlag(cmd, "with-summary", false)
+	if err != nil {
+		return err
+	}
 	useLSP, err := OptionalBoolFlag(cmd, "lsp", false)
 	if err != nil {
 		return err
 	}
 
+	kinds, err := OptionalEdgeKinds(cmd, "kind")
+	if err != nil {
+		return err
+	}
+
 	lookup, err := LoadLookup(rootPath)
 	if err != nil {
 		return err
 	}
+	lookup = lookup.FilterEdgeKinds(kinds)
+	if lookup, err = externalView(cmd, lookup); err != nil {
+		return err
+	}
 	node, err := ResolveSingleSymbol(lookup, args[0])
 	if err != nil {
 		return err
@@ -166,10 +271,17 @@ func RunCallees(cmd *cobra.Command, args []string) error {
 			callees[i].Source = "parser"
 		}
 	}
+	symbol := SymbolRecordFromNode(node)
+	if withSummary {
+		attachSummaries(lookup, &symbol)
+		for i := range callees {
+			attachSummaries(lookup, &callees[i].Symbol)
+		}
+	}
 	if asJSON {
 		payload := map[string]any{
 			"query":   args[0],
-			"symbol":  SymbolRecordFromNode(node),
+			"symbol":  symbol,
 			"callees": callees,
 		}
 		if lspStatus != nil {
@@ -188,10 +300,12 @@ func RunCallees(cmd *cobra.Command, args []string) error {
 		if callee.Confidence != "" {
 			fmt.Printf(" (%s)", callee.Confidence)
 		}
+		printEdgeKind(callee.Kind)
 		if callee.Source != "" {
 			fmt.Printf(" source=%s", callee.Source)
 		}
 		fmt.Println()
+		printSummary(callee.Symbol)
 	}
 	if useLSP && lspStatus != nil && !lspStatus.Available {
 		fmt.Printf("note: lsp unavailable (%s); run skelly doctor for details\n", lspStatus.Reason)
@@ -215,80 +329,77 @@ func RunTrace(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	withSummary, err := OptionalBoolFlag(cmd, "with-summary", false)
+	if err != nil {
+		return err
+	}
 	useLSP, err := OptionalBoolFlag(cmd, "lsp", false)
 	if err != nil {
 		return err
 	}
+	languageFilter, err := OptionalLanguageFilter(cmd, "lang")
+	if err != nil {
+		return err
+	}
+	direction, err := OptionalStringFlag(cmd, "direction")
+	if err != nil {
+		return err
+	}
+	if direction == "" {
+		direction = TraceOut
+	}
+	if direction != TraceOut && direction != TraceIn && direction != TraceBoth {
+		return fmt.Errorf("--direction must be one of: in, out, both")
+	}
+
+	kinds, err := OptionalEdgeKinds(cmd, "kind")
+	if err != nil {
+		return err
+	}
 
 	lookup, err := LoadLookup(rootPath)
 	if err != nil {
 		return err
 	}
+	lookup = lookup.FilterEdgeKinds(kinds)
+	if lookup, err = externalView(cmd, lookup); err != nil {
+		return err
+	}
 	startNode, err := ResolveSingleSymbol(lookup, args[0])
 	if err != nil {
 		return err
 	}
+	if !LanguageAllowed(languageFilter, startNode) {
+		return fmt.Errorf("symbol %s (%s) is outside the --lang filter", startNode.ID, startNode.Language)
+	}
 	lspStatus, err := ResolveLSPStatus(startNode, useLSP)
 	if err != nil {
 		return err
 	}
 
-	type queueItem struct {
-		id    string
-		depth int
+	hops, cuts := Trace(lookup, startNode, depth, direction, languageFilter)
+	for i := range hops {
+		hops[i].Source = edgeSource(useLSP)
 	}
-	queue := []queueItem{{id: startNode.ID, depth: 0}}
-	seenDepth := map[string]int{startNode.ID: 0}
-	hops := make([]TraceHop, 0)
-
-	for len(queue) > 0 {
-		current := queue[0]
-		queue = queue[1:]
-		if current.depth >= depth {
-			continue
-		}
-
-		fromNode := lookup.ByID[current.id]
-		if fromNode == nil {
-			continue
-		}
-
-		for _, nextID := range fromNode.OutEdges {
-			toNode := lookup.ByID[nextID]
-			if toNode == nil {
-				continue
-			}
-			nextDepth := current.depth + 1
-			hops = append(hops, TraceHop{
-				Depth:      nextDepth,
-				From:       SymbolRecordFromNode(fromNode),
-				To:         SymbolRecordFromNode(toNode),
-				Confidence: lookup.EdgeConfidenceValue(fromNode.ID, toNode.ID),
-				Source:     edgeSource(useLSP),
-			})
-			if previousDepth, exists := seenDepth[nextID]; !exists || nextDepth < previousDepth {
-				seenDepth[nextID] = nextDepth
-				queue = append(queue, queueItem{id: nextID, depth: nextDepth})
-			}
+	start := SymbolRecordFromNode(startNode)
+	if withSummary {
+		attachSummaries(lookup, &start)
+		for i := range hops {
+			attachSummaries(lookup, &hops[i].From, &hops[i].To)
 		}
 	}
 
-	sort.Slice(hops, func(i, j int) bool {
-		if hops[i].Depth != hops[j].Depth {
-			return hops[i].Depth < hops[j].Depth
-		}
-		if hops[i].From.ID != hops[j].From.ID {
-			return hops[i].From.ID < hops[j].From.ID
-		}
-		return hops[i].To.ID < hops[j].To.ID
-	})
-
 	if asJSON {
 		payload := map[string]any{
-			"query": args[0],
-			"start": SymbolRecordFromNode(startNode),
-			"depth": depth,
-			"hops":  hops,
+			"query":     args[0],
+			"start":     start,
+			"depth":     depth,
+			"direction": direction,
+			"hops":      hops,
+		}
+		if len(languageFilter) > 0 {
+			payload["languages"] = fileutil.MapKeysSorted(languageFilter)
+			payload["cut_edges"] = cuts
 		}
 		if lspStatus != nil {
 			payload["lsp"] = lspStatus
@@ -296,20 +407,35 @@ func RunTrace(cmd *cobra.Command, args []string) error {
 		return fileutil.PrintJSON(payload)
 	}
 
-	fmt.Printf("trace from %s depth=%d hops=%d\n", startNode.ID, depth, len(hops))
+	fmt.Printf("trace from %s direction=%s depth=%d hops=%d\n", startNode.ID, direction, depth, len(hops))
+	printBoundaryCuts(cuts)
 	if len(hops) == 0 {
-		fmt.Println("no outgoing hops found")
+		switch direction {
+		case TraceIn:
+			fmt.Println("no incoming hops found")
+		case TraceBoth:
+			fmt.Println("no hops found")
+		default:
+			fmt.Println("no outgoing hops found")
+		}
 		return nil
 	}
 	for _, hop := range hops {
-		fmt.Printf("- d=%d %s -> %s", hop.Depth, hop.From.ID, hop.To.ID)
+		fmt.Printf("- d=%d %s %s -> %s", hop.Depth, hop.Direction, hop.From.ID, hop.To.ID)
 		if hop.Confidence != "" {
 			fmt.Printf(" (%s)", hop.Confidence)
 		}
+		printEdgeKind(hop.Kind)
 		if hop.Source != "" {
 			fmt.Printf(" source=%s", hop.Source)
 		}
 		fmt.Println()
+		// The symbol the hop reaches: the callee going out, the caller going in.
+		if hop.Direction == TraceIn {
+			printSummary(hop.From)
+		} else {
+			printSummary(hop.To)
+		}
 	}
 	if useLSP && lspStatus != nil && !lspStatus.Available {
 		fmt.Printf("note: lsp unavailable (%s); run skelly doctor for details\n", lspStatus.Reason)
@@ -331,10 +457,42 @@ func RunPath(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	languageFilter, err := OptionalLanguageFilter(cmd, "lang")
+	if err != nil {
+		return err
+	}
+	allPaths, err := OptionalBoolFlag(cmd, "all", false)
+	if err != nil {
+		return err
+	}
+	maxDepth, err := OptionalIntFlag(cmd, "max-depth", DefaultPathMaxDepth)
+	if err != nil {
+		return err
+	}
+	if maxDepth < 1 {
+		return fmt.Errorf("--max-depth must be >= 1")
+	}
+	limit, err := OptionalIntFlag(cmd, "limit", DefaultPathLimit)
+	if err != nil {
+		return err
+	}
+	if limit < 0 {
+		return fmt.Errorf("--limit must be >= 0")
+	}
+
+	kinds, err := OptionalEdgeKinds(cmd, "kind")
+	if err != nil {
+		return err
+	}
+
 	lookup, err := LoadLookup(rootPath)
 	if err != nil {
 		return err
 	}
+	lookup = lookup.FilterEdgeKinds(kinds)
+	if lookup, err = externalView(cmd, lookup); err != nil {
+		return err
+	}
 	fromNode, err := ResolveSingleSymbol(lookup, args[0])
 	if err != nil {
 		return err
@@ -343,43 +501,40 @@ func RunPath(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	for _, endpoint := range []*IndexNode{fromNode, toNode} {
+		if !LanguageAllowed(languageFilter, endpoint) {
+			return fmt.Errorf("symbol %s (%s) is outside the --lang filter", endpoint.ID, endpoint.Language)
+		}
+	}
 	lspStatus, err := ResolveLSPStatus(fromNode, useLSP)
 	if err != nil {
 		return err
 	}
 
-	pathIDs := ShortestPath(lookup, fromNode.ID, toNode.ID)
-	if len(pathIDs) == 0 {
-		return fmt.Errorf("no path found between %s and %s", fromNode.ID, toNode.ID)
+	if allPaths {
+		return printAllPaths(lookup, fromNode, toNode, maxDepth, limit, languageFilter, useLSP, lspStatus, asJSON)
 	}
 
-	pathNodes := make([]SymbolRecord, 0, len(pathIDs))
-	edges := make([]map[string]string, 0, len(pathIDs)-1)
-	for i, id := range pathIDs {
-		node := lookup.ByID[id]
-		if node == nil {
-			continue
-		}
-		pathNodes = append(pathNodes, SymbolRecordFromNode(node))
-		if i == 0 {
-			continue
+	pathIDs, cuts := ShortestPathFiltered(lookup, fromNode.ID, toNode.ID, languageFilter)
+	if len(pathIDs) == 0 {
+		if len(cuts) > 0 {
+			return fmt.Errorf("no path found between %s and %s within --lang filter (%d cross-language edges cut)", fromNode.ID, toNode.ID, len(cuts))
 		}
-		prevID := pathIDs[i-1]
-		edges = append(edges, map[string]string{
-			"from_id":    prevID,
-			"to_id":      id,
-			"confidence": lookup.EdgeConfidenceValue(prevID, id),
-			"source":     edgeSource(useLSP),
-		})
+		return fmt.Errorf("no path found between %s and %s", fromNode.ID, toNode.ID)
 	}
+	result := lookup.pathResult(pathIDs, useLSP)
 
 	if asJSON {
 		payload := map[string]any{
 			"from":   SymbolRecordFromNode(fromNode),
 			"to":     SymbolRecordFromNode(toNode),
-			"length": len(pathNodes) - 1,
-			"path":   pathNodes,
-			"edges":  edges,
+			"length": result.Length,
+			"path":   result.Path,
+			"edges":  result.Edges,
+		}
+		if len(languageFilter) > 0 {
+			payload["languages"] = fileutil.MapKeysSorted(languageFilter)
+			payload["cut_edges"] = cuts
 		}
 		if lspStatus != nil {
 			payload["lsp"] = lspStatus
@@ -387,16 +542,133 @@ func RunPath(cmd *cobra.Command, args []string) error {
 		return fileutil.PrintJSON(payload)
 	}
 
-	fmt.Printf("path %s -> %s length=%d\n", fromNode.ID, toNode.ID, len(pathNodes)-1)
-	for i, node := range pathNodes {
+	fmt.Printf("path %s -> %s length=%d\n", fromNode.ID, toNode.ID, result.Length)
+	for i, node := range result.Path {
 		fmt.Printf("%d. %s [%s] %s:%d\n", i+1, node.ID, node.Kind, node.File, node.Line)
 	}
+	printBoundaryCuts(cuts)
 	if useLSP && lspStatus != nil && !lspStatus.Available {
 		fmt.Printf("note: lsp unavailable (%s); run skelly doctor for details\n", lspStatus.Reason)
 	}
 	return nil
 }
 
+// printAllPaths implements `path --all`.
+func printAllPaths(lookup *Lookup, fromNode, toNode *IndexNode, maxDepth, limit int, languageFilter map[string]bool, useLSP bool, lspStatus *LSPStatus, asJSON bool) error {
+	pathIDs, cuts := AllPathsFiltered(lookup, fromNode.ID, toNode.ID, maxDepth, limit, languageFilter)
+	if len(pathIDs) == 0 {
+		if len(cuts) > 0 {
+			return fmt.Errorf("no path of at most %d edges found between %s and %s within --lang filter (%d cross-language edges cut)", maxDepth, fromNode.ID, toNode.ID, len(cuts))
+		}
+		return fmt.Errorf("no path of at most %d edges found between %s and %s", maxDepth, fromNode.ID, toNode.ID)
+	}
+	results := make([]PathResult, 0, len(pathIDs))
+	for _, ids := range pathIDs {
+		results = append(results, lookup.pathResult(ids, useLSP))
+	}
+
+	if asJSON {
+		payload := map[string]any{
+			"from":      SymbolRecordFromNode(fromNode),
+			"to":        SymbolRecordFromNode(toNode),
+			"max_depth": maxDepth,
+			"limit":     limit,
+			"count":     len(results),
+			"paths":     results,
+		}
+		if len(languageFilter) > 0 {
+			payload["languages"] = fileutil.MapKeysSorted(languageFilter)
+			payload["cut_edges"] = cuts
+		}
+		if lspStatus != nil {
+			payload["lsp"] = lspStatus
+		}
+		return fileutil.PrintJSON(payload)
+	}
+
+	fmt.Printf("paths %s -> %s count=%d max_depth=%d\n", fromNode.ID, toNode.ID, len(results), maxDepth)
+	for i, result := range results {
+		fmt.Printf("path %d length=%d\n", i+1, result.Length)
+		for j, node := range result.Path {
+			fmt.Printf("  %d. %s [%s] %s:%d", j+1, node.ID, node.Kind, node.File, node.Line)
+			if j > 0 && result.Edges[j-1].Confidence != "" {
+				fmt.Printf(" (%s)", result.Edges[j-1].Confidence)
+			}
+			if j > 0 {
+				printEdgeKind(result.Edges[j-1].Kind)
+			}
+			fmt.Println()
+		}
+	}
+	printBoundaryCuts(cuts)
+	if useLSP && lspStatus != nil && !lspStatus.Available {
+		fmt.Printf("note: lsp unavailable (%s); run skelly doctor for details\n", lspStatus.Reason)
+	}
+	return nil
+}
+
+// pathResult expands node IDs into symbols and the edges between them.
+func (l *Lookup) pathResult(pathIDs []string, useLSP bool) PathResult {
+	result := PathResult{
+		Path:  make([]SymbolRecord, 0, len(pathIDs)),
+		Edges: make([]PathEdge, 0, len(pathIDs)),
+	}
+	for i, id := range pathIDs {
+		node := l.Node(id)
+		if node == nil {
+			continue
+		}
+		result.Path = append(result.Path, SymbolRecordFromNode(node))
+		if i == 0 {
+			continue
+		}
+		prevID := pathIDs[i-1]
+		result.Edges = append(result.Edges, PathEdge{
+			FromID:     prevID,
+			ToID:       id,
+			Confidence: l.EdgeConfidenceValue(prevID, id),
+			Kind:       l.EdgeKindValue(prevID, id),
+			Source:     edgeSource(useLSP),
+		})
+	}
+	result.Length = len(result.Path) - 1
+	return result
+}
+
+// attachSummaries copies the enrich summary of each record's symbol from the
+// navigation index, for --with-summary.
+func attachSummaries(l *Lookup, records ...*SymbolRecord) {
+	for _, record := range records {
+		if node := l.Node(record.ID); node != nil {
+			record.Summary = node.Summary
+		}
+	}
+}
+
+// printSummary prints a record's enrich summary under its text output line.
+func printSummary(record SymbolRecord) {
+	if record.Summary != "" {
+		fmt.Printf("  summary: %s\n", record.Summary)
+	}
+}
+
+// printEdgeKind annotates text output with the kind of non-call edges.
+func printEdgeKind(kind string) {
+	if kind != "" && kind != string(graph.EdgeCall) {
+		fmt.Printf(" kind=%s", kind)
+	}
+}
+
+func printBoundaryCuts(cuts []BoundaryCut) {
+	if len(cuts) == 0 {
+		return
+	}
+	fmt.Printf("language boundary cuts (%d):\n", len(cuts))
+	for _, cut := range cuts {
+		fmt.Printf("- d=%d %s -> %s [%s]\n", cut.Depth, cut.From.ID, cut.To.ID, cut.To.Language)
+	}
+}
+
 func edgeSource(useLSP bool) string {
 	if useLSP {
 		return "parser"
@@ -404,6 +676,36 @@ func edgeSource(useLSP bool) string {
 	return ""
 }
 
+// Trace walks the graph from start up to depth hops in direction (TraceOut,
+// TraceIn or TraceBoth), nearest hops first, and reports the edges the
+// language filter cut.
+func Trace(lookup *Lookup, start *IndexNode, depth int, direction string, languageFilter map[string]bool) ([]TraceHop, []BoundaryCut) {
+	hops := make([]TraceHop, 0)
+	cuts := make([]BoundaryCut, 0)
+	for _, walk := range []string{TraceOut, TraceIn} {
+		if direction != walk && direction != TraceBoth {
+			continue
+		}
+		walkHops, walkCuts := lookup.traceWalk(start, depth, walk, languageFilter)
+		hops = append(hops, walkHops...)
+		cuts = append(cuts, walkCuts...)
+	}
+
+	sort.Slice(hops, func(i, j int) bool {
+		if hops[i].Depth != hops[j].Depth {
+			return hops[i].Depth < hops[j].Depth
+		}
+		if hops[i].Direction != hops[j].Direction {
+			return hops[i].Direction > hops[j].Direction
+		}
+		if hops[i].From.ID != hops[j].From.ID {
+			return hops[i].From.ID < hops[j].From.ID
+		}
+		return hops[i].To.ID < hops[j].To.ID
+	})
+	return hops, SortBoundaryCuts(cuts)
+}
+
 func ResolveLSPStatus(node *IndexNode, enabled bool) (*LSPStatus, error) {
 	if !enabled {
 		return nil, nil
@@ -434,6 +736,95 @@ func OptionalBoolFlag(cmd *cobra.Command, name string, defaultValue bool) (bool,
 	return value, nil
 }
 
+func OptionalStringFlag(cmd *cobra.Command, name string) (string, error) {
+	if cmd == nil || cmd.Flags().Lookup(name) == nil {
+		return "", nil
+	}
+	value, err := cmd.Flags().GetString(name)
+	if err != nil {
+		return "", fmt.Errorf("failed to read --%s flag: %w", name, err)
+	}
+	return strings.TrimSpace(value), nil
+}
+
+// OptionalLanguageFilter reads a --lang style string slice flag into a canonical language set.
+func OptionalLanguageFilter(cmd *cobra.Command, name string) (map[string]bool, error) {
+	if cmd == nil || cmd.Flags().Lookup(name) == nil {
+		return nil, nil
+	}
+	values, err := cmd.Flags().GetStringSlice(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read --%s flag: %w", name, err)
+	}
+	return languages.ParseLanguageList(values)
+}
+
+// externalView hides the nodes of vendored and generated code from lookup
+// unless --include-external is set.
+func externalView(cmd *cobra.Command, lookup *Lookup) (*Lookup, error) {
+	includeExternal, err := OptionalBoolFlag(cmd, "include-external", false)
+	if err != nil || includeExternal {
+		return lookup, err
+	}
+	return lookup.WithoutExternal(), nil
+}
+
+// callerEdgeKinds returns the edge kinds `callers` follows. Reference
+// edges ("used by") are left out unless includeReferences is set or --kind
+// names them.
+func callerEdgeKinds(kinds map[string]bool, includeReferences bool) map[string]bool {
+	reference := string(graph.EdgeReference)
+	if kinds != nil {
+		if includeReferences {
+			kinds[reference] = true
+		}
+		return kinds
+	}
+	if includeReferences {
+		return nil
+	}
+	kinds = make(map[string]bool, len(EdgeKinds))
+	for _, kind := range EdgeKinds {
+		if kind != reference {
+			kinds[kind] = true
+		}
+	}
+	return kinds
+}
+
+// OptionalEdgeKinds reads a --kind style string slice flag into a set of
+// edge kinds; nil means every kind.
+func OptionalEdgeKinds(cmd *cobra.Command, name string) (map[string]bool, error) {
+	if cmd == nil || cmd.Flags().Lookup(name) == nil {
+		return nil, nil
+	}
+	values, err := cmd.Flags().GetStringSlice(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read --%s flag: %w", name, err)
+	}
+	return ParseEdgeKinds(values, "--"+name)
+}
+
+// ParseEdgeKinds validates edge kind names into a set; nil means every kind.
+// source names where the values came from in errors.
+func ParseEdgeKinds(values []string, source string) (map[string]bool, error) {
+	var kinds map[string]bool
+	for _, value := range values {
+		value = strings.ToLower(strings.TrimSpace(value))
+		if value == "" {
+			continue
+		}
+		if !slices.Contains(EdgeKinds, value) {
+			return nil, fmt.Errorf("unknown edge kind %q for %s (valid: %s)", value, source, strings.Join(EdgeKinds, ", "))
+		}
+		if kinds == nil {
+			kinds = make(map[string]bool)
+		}
+		kinds[value] = true
+	}
+	return kinds, nil
+}
+
 func OptionalIntFlag(cmd *cobra.Command, name string, defaultValue int) (int, error) {
 	if cmd == nil || cmd.Flags().Lookup(name) == nil {
 		return defaultValue, nil
@@ -173,7 +173,7 @@ func ResolveNodeAtLocation(lookup *Lookup, file string, line int) *IndexNode {
 	exact := make([]*IndexNode, 0)
 	nearest := make([]*IndexNode, 0)
 	bestLine := -1
-	for _, candidate := range lookup.ByID {
+	for _, candidate := range lookup.AllNodes() {
 		if candidate.File != file {
 			continue
 		}
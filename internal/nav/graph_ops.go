@@ -1,17 +1,22 @@
 package nav
 
-import "sort"
+import (
+	"sort"
+
+	"github.com/morozRed/skelly/internal/graph"
+)
 
 func CollectCallers(l *Lookup, node *IndexNode) []EdgeRecord {
 	out := make([]EdgeRecord, 0, len(node.InEdges))
 	for _, callerID := range node.InEdges {
-		caller := l.ByID[callerID]
+		caller := l.Node(callerID)
 		if caller == nil {
 			continue
 		}
 		out = append(out, EdgeRecord{
 			Symbol:     SymbolRecordFromNode(caller),
 			Confidence: l.EdgeConfidenceValue(caller.ID, node.ID),
+			Kind:       l.EdgeKindValue(caller.ID, node.ID),
 		})
 	}
 	sort.Slice(out, func(i, j int) bool {
@@ -20,16 +25,40 @@ func CollectCallers(l *Lookup, node *IndexNode) []EdgeRecord {
 	return out
 }
 
+// CollectImplementations returns the types implementing an interface node,
+// or the interfaces a type node implements.
+func CollectImplementations(l *Lookup, node *IndexNode) []SymbolRecord {
+	out := make([]SymbolRecord, 0)
+	if node.Kind == "interface" {
+		for _, id := range node.InEdges {
+			if related := l.Node(id); related != nil && l.EdgeKindValue(id, node.ID) == string(graph.EdgeImplement) {
+				out = append(out, SymbolRecordFromNode(related))
+			}
+		}
+	} else {
+		for _, id := range node.OutEdges {
+			if related := l.Node(id); related != nil && l.EdgeKindValue(node.ID, id) == string(graph.EdgeImplement) {
+				out = append(out, SymbolRecordFromNode(related))
+			}
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].ID < out[j].ID
+	})
+	return out
+}
+
 func CollectCallees(l *Lookup, node *IndexNode) []EdgeRecord {
 	out := make([]EdgeRecord, 0, len(node.OutEdges))
 	for _, calleeID := range node.OutEdges {
-		callee := l.ByID[calleeID]
+		callee := l.Node(calleeID)
 		if callee == nil {
 			continue
 		}
 		out = append(out, EdgeRecord{
 			Symbol:     SymbolRecordFromNode(callee),
 			Confidence: l.EdgeConfidenceValue(node.ID, callee.ID),
+			Kind:       l.EdgeKindValue(node.ID, callee.ID),
 		})
 	}
 	sort.Slice(out, func(i, j int) bool {
@@ -39,18 +68,27 @@ func CollectCallees(l *Lookup, node *IndexNode) []EdgeRecord {
 }
 
 func ShortestPath(lookup *Lookup, fromID, toID string) []string {
+	path, _ := ShortestPathFiltered(lookup, fromID, toID, nil)
+	return path
+}
+
+// ShortestPathFiltered finds the shortest call path while only traversing nodes
+// whose language is in languageFilter. Edges skipped by the filter are returned as cuts.
+func ShortestPathFiltered(lookup *Lookup, fromID, toID string, languageFilter map[string]bool) ([]string, []BoundaryCut) {
 	if fromID == toID {
-		return []string{fromID}
+		return []string{fromID}, nil
 	}
 
 	queue := []string{fromID}
 	visited := map[string]bool{fromID: true}
 	parent := map[string]string{}
+	depth := map[string]int{fromID: 0}
+	cuts := make([]BoundaryCut, 0)
 
 	for len(queue) > 0 {
 		current := queue[0]
 		queue = queue[1:]
-		node := lookup.ByID[current]
+		node := lookup.Node(current)
 		if node == nil {
 			continue
 		}
@@ -58,16 +96,206 @@ func ShortestPath(lookup *Lookup, fromID, toID string) []string {
 			if visited[nextID] {
 				continue
 			}
+			if next := lookup.Node(nextID); next != nil && !LanguageAllowed(languageFilter, next) {
+				cuts = append(cuts, lookup.boundaryCut(node, next, depth[current]+1))
+				continue
+			}
 			visited[nextID] = true
 			parent[nextID] = current
+			depth[nextID] = depth[current] + 1
 			if nextID == toID {
-				return ReconstructPath(parent, fromID, toID)
+				return ReconstructPath(parent, fromID, toID), SortBoundaryCuts(cuts)
 			}
 			queue = append(queue, nextID)
 		}
 	}
 
-	return nil
+	return nil, SortBoundaryCuts(cuts)
+}
+
+// traceWalk runs a breadth-first trace from start up to maxDepth hops,
+// following callees for TraceOut and callers for TraceIn. Every edge between
+// reached nodes is reported, not only the first one that reached a node.
+func (l *Lookup) traceWalk(start *IndexNode, maxDepth int, direction string, languageFilter map[string]bool) ([]TraceHop, []BoundaryCut) {
+	type queueItem struct {
+		id    string
+		depth int
+	}
+	queue := []queueItem{{id: start.ID, depth: 0}}
+	seenDepth := map[string]int{start.ID: 0}
+	hops := make([]TraceHop, 0)
+	cuts := make([]BoundaryCut, 0)
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if current.depth >= maxDepth {
+			continue
+		}
+		node := l.Node(current.id)
+		if node == nil {
+			continue
+		}
+
+		nextIDs := node.OutEdges
+		if direction == TraceIn {
+			nextIDs = node.InEdges
+		}
+		for _, nextID := range nextIDs {
+			next := l.Node(nextID)
+			if next == nil {
+				continue
+			}
+			caller, callee := node, next
+			if direction == TraceIn {
+				caller, callee = next, node
+			}
+			nextDepth := current.depth + 1
+			if !LanguageAllowed(languageFilter, next) {
+				cuts = append(cuts, l.boundaryCut(caller, callee, nextDepth))
+				continue
+			}
+			hops = append(hops, TraceHop{
+				Depth:      nextDepth,
+				Direction:  direction,
+				From:       SymbolRecordFromNode(caller),
+				To:         SymbolRecordFromNode(callee),
+				Confidence: l.EdgeConfidenceValue(caller.ID, callee.ID),
+				Kind:       l.EdgeKindValue(caller.ID, callee.ID),
+			})
+			if previousDepth, exists := seenDepth[nextID]; !exists || nextDepth < previousDepth {
+				seenDepth[nextID] = nextDepth
+				queue = append(queue, queueItem{id: nextID, depth: nextDepth})
+			}
+		}
+	}
+	return hops, cuts
+}
+
+// AllPathsFiltered enumerates up to limit (0 for all) simple call paths from
+// fromID to toID with at most maxDepth edges, shortest first and then by node
+// IDs. Only nodes allowed by languageFilter are traversed; skipped edges are
+// returned as cuts.
+func AllPathsFiltered(lookup *Lookup, fromID, toID string, maxDepth, limit int, languageFilter map[string]bool) ([][]string, []BoundaryCut) {
+	if fromID == toID {
+		return [][]string{{fromID}}, nil
+	}
+
+	// Distance to the target over incoming edges bounds every branch.
+	distance := map[string]int{toID: 0}
+	queue := []string{toID}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		node := lookup.Node(current)
+		if node == nil || distance[current] >= maxDepth {
+			continue
+		}
+		for _, prevID := range node.InEdges {
+			prev := lookup.Node(prevID)
+			if prev == nil || !LanguageAllowed(languageFilter, prev) {
+				continue
+			}
+			if _, seen := distance[prevID]; !seen {
+				distance[prevID] = distance[current] + 1
+				queue = append(queue, prevID)
+			}
+		}
+	}
+	if _, ok := distance[fromID]; !ok {
+		_, cuts := ShortestPathFiltered(lookup, fromID, toID, languageFilter)
+		return nil, cuts
+	}
+
+	paths := make([][]string, 0)
+	cutByEdge := make(map[string]BoundaryCut)
+	onPath := map[string]bool{fromID: true}
+	path := []string{fromID}
+	var walk func(current string, remaining int)
+	walk = func(current string, remaining int) {
+		if limit > 0 && len(paths) >= limit {
+			return
+		}
+		if remaining == 0 {
+			if current == toID {
+				paths = append(paths, append([]string(nil), path...))
+			}
+			return
+		}
+		node := lookup.Node(current)
+		if node == nil {
+			return
+		}
+		for _, nextID := range node.OutEdges {
+			next := lookup.Node(nextID)
+			if next == nil || onPath[nextID] {
+				continue
+			}
+			if !LanguageAllowed(languageFilter, next) {
+				key := current + "\x00" + nextID
+				if existing, ok := cutByEdge[key]; !ok || len(path) < existing.Depth {
+					cutByEdge[key] = lookup.boundaryCut(node, next, len(path))
+				}
+				continue
+			}
+			// The target may only appear as the last node of a simple path.
+			if dist, ok := distance[nextID]; !ok || dist > remaining-1 || (nextID == toID && remaining != 1) {
+				continue
+			}
+			onPath[nextID] = true
+			path = append(path, nextID)
+			walk(nextID, remaining-1)
+			path = path[:len(path)-1]
+			delete(onPath, nextID)
+		}
+	}
+	for length := distance[fromID]; length <= maxDepth; length++ {
+		walk(fromID, length)
+	}
+
+	cuts := make([]BoundaryCut, 0, len(cutByEdge))
+	for _, cut := range cutByEdge {
+		cuts = append(cuts, cut)
+	}
+	return paths, SortBoundaryCuts(cuts)
+}
+
+// LanguageAllowed reports whether node passes the language filter; a nil filter allows all nodes.
+func LanguageAllowed(languageFilter map[string]bool, node *IndexNode) bool {
+	if len(languageFilter) == 0 || node == nil {
+		return true
+	}
+	return languageFilter[node.Language]
+}
+
+// SortBoundaryCuts orders cuts deterministically and drops duplicates.
+func SortBoundaryCuts(cuts []BoundaryCut) []BoundaryCut {
+	sort.Slice(cuts, func(i, j int) bool {
+		if cuts[i].Depth != cuts[j].Depth {
+			return cuts[i].Depth < cuts[j].Depth
+		}
+		if cuts[i].From.ID != cuts[j].From.ID {
+			return cuts[i].From.ID < cuts[j].From.ID
+		}
+		return cuts[i].To.ID < cuts[j].To.ID
+	})
+	out := make([]BoundaryCut, 0, len(cuts))
+	for i, cut := range cuts {
+		if i > 0 && cut.From.ID == cuts[i-1].From.ID && cut.To.ID == cuts[i-1].To.ID {
+			continue
+		}
+		out = append(out, cut)
+	}
+	return out
+}
+
+func (l *Lookup) boundaryCut(from, to *IndexNode, depth int) BoundaryCut {
+	return BoundaryCut{
+		Depth:      depth,
+		From:       SymbolRecordFromNode(from),
+		To:         SymbolRecordFromNode(to),
+		Confidence: l.EdgeConfidenceValue(from.ID, to.ID),
+	}
 }
 
 func ReconstructPath(parent map[string]string, fromID, toID string) []string {
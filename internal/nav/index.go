@@ -3,22 +3,35 @@ package nav
 import (
 	"encoding/json"
 	"fmt"
+	"math"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/morozRed/skelly/internal/fileutil"
 	"github.com/morozRed/skelly/internal/graph"
 	"github.com/morozRed/skelly/internal/output"
+	"github.com/morozRed/skelly/internal/parser"
 	"github.com/morozRed/skelly/internal/search"
 )
 
-const NavigationIndexFile = "nav-index.json"
+const (
+	NavigationIndexFile = "nav-index.json"
+	// NavigationIndexVersion is the schema version written into nav-index.json.
+	NavigationIndexVersion = "nav-index-v3"
+)
 
-func WriteIndex(contextDir string, g *graph.Graph) error {
+// WriteIndex writes the navigation index. aliases forwards retired symbol IDs
+// to their replacements so saved references keep resolving. Segments of
+// files whose fingerprint matches previous are copied from the index on
+// disk instead of re-encoded; it returns the fingerprint of every file's
+// segment, or nil for a sharded index.
+func WriteIndex(contextDir string, g *graph.Graph, aliases map[string]string, previous fileutil.Segments) (map[string]string, error) {
 	if err := os.MkdirAll(contextDir, 0755); err != nil {
-		return err
+		return nil, err
 	}
 
 	ids := make([]string, 0, len(g.Nodes))
@@ -35,19 +48,32 @@ func WriteIndex(contextDir string, g *graph.Graph) error {
 			outConf = append(outConf, EdgeConfidence{
 				TargetID:   targetID,
 				Confidence: node.OutEdgeConfidence[targetID],
+				Kind:       string(node.EdgeKindTo(targetID)),
 			})
 		}
 		sort.Slice(outConf, func(i, j int) bool {
 			return outConf[i].TargetID < outConf[j].TargetID
 		})
 
+		// Rounded so float summation noise does not rewrite the index.
+		rank := math.Round(node.PageRank*1e6) / 1e6
 		nodes = append(nodes, IndexNode{
 			ID:            node.ID,
 			Name:          node.Symbol.Name,
+			Container:     node.Symbol.Container,
 			Kind:          node.Symbol.Kind.String(),
 			Signature:     node.Symbol.Signature,
+			Shape:         node.Symbol.Shape,
 			File:          node.File,
+			Language:      node.Language,
 			Line:          node.Symbol.Line,
+			EndLine:       node.Symbol.EndLine,
+			Visibility:    node.Symbol.Visibility,
+			External:      node.External,
+			Doc:           node.Symbol.Doc,
+			Summary:       node.Summary,
+			Rank:          rank,
+			Betweenness:   math.Round(node.Betweenness*1e6) / 1e6,
 			OutEdges:      append([]string(nil), node.OutEdges...),
 			InEdges:       append([]string(nil), node.InEdges...),
 			OutConfidence: outConf,
@@ -55,20 +81,124 @@ func WriteIndex(contextDir string, g *graph.Graph) error {
 	}
 
 	index := Index{
-		Version: "nav-index-v1",
+		Version: NavigationIndexVersion,
 		Nodes:   nodes,
+		Aliases: aliases,
+	}
+	if len(nodes) >= ShardMinNodes {
+		return nil, writeShardedIndex(contextDir, index)
+	}
+	if err := removeShards(contextDir); err != nil {
+		return nil, err
+	}
+	return writeSegmentedIndex(contextDir, index, previous)
+}
+
+// writeSegmentedIndex writes index as json.MarshalIndent would, assembling
+// the nodes array from per-file segments.
+func writeSegmentedIndex(contextDir string, index Index, previous fileutil.Segments) (map[string]string, error) {
+	path := filepath.Join(contextDir, NavigationIndexFile)
+	_, onDisk := previous.Load(path, "nodes")
+	fingerprints := make(map[string]string)
+	segments := make([][]byte, 0)
+	for start := 0; start < len(index.Nodes); {
+		file := fileutil.SegmentFile(index.Nodes[start].ID)
+		end := start + 1
+		for end < len(index.Nodes) && fileutil.SegmentFile(index.Nodes[end].ID) == file {
+			end++
+		}
+		group := index.Nodes[start:end]
+		start = end
+
+		fingerprint := nodesFingerprint(group)
+		if _, seen := fingerprints[file]; seen {
+			// IDs of one file are always adjacent; anything else is not
+			// segmented and records no fingerprints.
+			fingerprint = ""
+		}
+		fingerprints[file] = fingerprint
+		segment, ok := previous.Reuse(onDisk, file, fingerprint)
+		if !ok {
+			var err error
+			if segment, err = fileutil.EncodeSegment(group); err != nil {
+				return nil, err
+			}
+		}
+		segments = append(segments, segment)
+	}
+
+	header := index
+	header.Nodes = []IndexNode{}
+	data, err := json.MarshalIndent(header, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	data = fileutil.SpliceSegments(data, "nodes", segments)
+	if err := fileutil.WriteArtifact(path, data); err != nil {
+		return nil, err
+	}
+	return fingerprints, nil
+}
+
+// nodesFingerprint hashes everything a segment is encoded from. Ranks and
+// betweenness only move when the graph's topology changes (see
+// graph.RankFrom).
+func nodesFingerprint(nodes []IndexNode) string {
+	fingerprint := fileutil.NewFingerprint()
+	for _, node := range nodes {
+		fingerprint.String(node.ID, node.Name, node.Container, node.Kind, node.Signature, node.File, node.Language, node.Visibility, node.Doc, node.Summary)
+		fingerprint.String(strconv.FormatBool(node.External))
+		fingerprint.String(strconv.FormatFloat(node.Rank, 'g', -1, 64), strconv.FormatFloat(node.Betweenness, 'g', -1, 64))
+		fingerprint.Int(node.Line)
+		fingerprint.Int(node.EndLine)
+		fingerprint.Int(len(node.OutEdges))
+		fingerprint.String(node.OutEdges...)
+		fingerprint.Int(len(node.InEdges))
+		fingerprint.String(node.InEdges...)
+		for _, conf := range node.OutConfidence {
+			fingerprint.String(conf.TargetID, conf.Confidence, conf.Kind)
+		}
 	}
+	return fingerprint.Sum()
+}
 
-	data, err := json.MarshalIndent(index, "", "  ")
+// WriteAliases replaces the forwarding table of an existing navigation index
+// without rebuilding its nodes.
+func WriteAliases(contextDir string, aliases map[string]string) error {
+	path := filepath.Join(contextDir, NavigationIndexFile)
+	data, err := fileutil.ReadArtifact(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read navigation index: %w", err)
+	}
+
+	var index Index
+	if err := json.Unmarshal(data, &index); err != nil {
+		return fmt.Errorf("failed to decode navigation index: %w", err)
+	}
+	index.Aliases = aliases
+
+	data, err = json.MarshalIndent(index, "", "  ")
 	if err != nil {
 		return err
 	}
-	return fileutil.WriteIfChanged(filepath.Join(contextDir, NavigationIndexFile), data)
+	return fileutil.WriteArtifact(path, data)
 }
 
+// LoadLookup reads the navigation index, or returns the in-memory copy when
+// warm indexes are enabled and the file is unchanged.
 func LoadLookup(rootPath string) (*Lookup, error) {
+	if warm := warmIndexes; warm != nil {
+		return warm.loadLookup(rootPath)
+	}
+	return readLookup(rootPath)
+}
+
+func readLookup(rootPath string) (*Lookup, error) {
 	path := filepath.Join(rootPath, output.ContextDir, NavigationIndexFile)
-	data, err := os.ReadFile(path)
+	data, err := fileutil.ReadArtifact(path)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return nil, fmt.Errorf("navigation index missing at %s (run skelly update)", path)
@@ -80,18 +210,28 @@ func LoadLookup(rootPath string) (*Lookup, error) {
 	if err := json.Unmarshal(data, &index); err != nil {
 		return nil, fmt.Errorf("failed to decode navigation index: %w", err)
 	}
+	if index.Shards != nil {
+		return shardedLookup(filepath.Dir(path), index), nil
+	}
 
 	lookup := &Lookup{
-		ByID:   make(map[string]*IndexNode, len(index.Nodes)),
-		ByName: make(map[string][]string),
+		ByID:    make(map[string]*IndexNode, len(index.Nodes)),
+		ByName:  make(map[string][]string),
+		Aliases: index.Aliases,
 	}
 	for i := range index.Nodes {
 		node := &index.Nodes[i]
 		lookup.ByID[node.ID] = node
 		lookup.ByName[node.Name] = append(lookup.ByName[node.Name], node.ID)
+		// Qualified names (User.save, Admin::User.save) resolve the same way.
+		symbol := parser.Symbol{Name: node.Name, Container: node.Container}
+		for _, name := range symbol.QualifiedNames() {
+			lookup.ByName[name] = append(lookup.ByName[name], node.ID)
+		}
 	}
-	for name := range lookup.ByName {
-		sort.Strings(lookup.ByName[name])
+	for name, ids := range lookup.ByName {
+		sort.Strings(ids)
+		lookup.ByName[name] = slices.Compact(ids)
 	}
 	return lookup, nil
 }
@@ -101,13 +241,17 @@ func Resolve(l *Lookup, query string) []*IndexNode {
 	if query == "" {
 		return nil
 	}
-	if node, ok := l.ByID[query]; ok {
+	if node := l.Node(query); node != nil {
 		return []*IndexNode{node}
 	}
-	ids := l.ByName[query]
+	if node := l.Node(l.Aliases[query]); node != nil {
+		// Retired IDs forward to the symbol's current ID.
+		return []*IndexNode{node}
+	}
+	ids := l.IDsNamed(query)
 	out := make([]*IndexNode, 0, len(ids))
 	for _, id := range ids {
-		if node := l.ByID[id]; node != nil {
+		if node := l.Node(id); node != nil {
 			out = append(out, node)
 		}
 	}
@@ -129,7 +273,7 @@ func ResolveWithOptions(l *Lookup, index *search.Index, query string, options Re
 	results := search.Search(index, query, options.Limit)
 	out := make([]*IndexNode, 0, len(results))
 	for _, result := range results {
-		node := l.ByID[result.ID]
+		node := l.Node(result.ID)
 		if node == nil {
 			continue
 		}
@@ -141,7 +285,7 @@ func ResolveWithOptions(l *Lookup, index *search.Index, query string, options Re
 func ResolveSingleSymbol(l *Lookup, query string) (*IndexNode, error) {
 	matches := Resolve(l, query)
 	if len(matches) == 0 {
-		return nil, fmt.Errorf("symbol %q not found", query)
+		return nil, symbolNotFound(l, query)
 	}
 	if len(matches) == 1 {
 		return matches[0], nil
@@ -155,22 +299,66 @@ func ResolveSingleSymbol(l *Lookup, query string) (*IndexNode, error) {
 	return nil, fmt.Errorf("symbol %q is ambiguous; use one of: %s", query, strings.Join(options, ", "))
 }
 
+// symbolNotFound reports a query that resolved to nothing, pointing at
+// --include-external when only hidden external code has the symbol.
+func symbolNotFound(l *Lookup, query string) error {
+	if l.hideExternal && len(Resolve(l.withExternal(), query)) > 0 {
+		return fmt.Errorf("symbol %q is in external code (pass --include-external)", query)
+	}
+	return fmt.Errorf("symbol %q not found", query)
+}
+
 func SymbolRecordFromNode(node *IndexNode) SymbolRecord {
 	if node == nil {
 		return SymbolRecord{}
 	}
 	return SymbolRecord{
-		ID:        node.ID,
-		Name:      node.Name,
-		Kind:      node.Kind,
-		Signature: node.Signature,
-		File:      node.File,
-		Line:      node.Line,
+		ID:         node.ID,
+		Name:       node.Name,
+		Container:  node.Container,
+		Kind:       node.Kind,
+		Signature:  node.Signature,
+		File:       node.File,
+		Language:   node.Language,
+		Line:       node.Line,
+		EndLine:    node.EndLine,
+		Visibility: node.Visibility,
+		External:   node.External,
 	}
 }
 
+// MetricValue returns the node's score for an importance metric, as
+// graph.Node.MetricValue does for the graph it was written from.
+func (n *IndexNode) MetricValue(metric graph.Metric) float64 {
+	switch metric {
+	case graph.MetricInDegree:
+		return float64(len(n.InEdges))
+	case graph.MetricOutDegree:
+		return float64(len(n.OutEdges))
+	case graph.MetricBetweenness:
+		return n.Betweenness
+	default:
+		return n.Rank
+	}
+}
+
+// SortByMetric stably orders nodes by metric, highest first, so ties keep
+// their current order.
+func SortByMetric[T any](items []T, node func(T) *IndexNode, metric graph.Metric) {
+	sort.SliceStable(items, func(i, j int) bool {
+		return metricOf(node(items[i]), metric) > metricOf(node(items[j]), metric)
+	})
+}
+
+func metricOf(node *IndexNode, metric graph.Metric) float64 {
+	if node == nil {
+		return 0
+	}
+	return node.MetricValue(metric)
+}
+
 func (l *Lookup) EdgeConfidenceValue(fromID, toID string) string {
-	from := l.ByID[fromID]
+	from := l.Node(fromID)
 	if from == nil {
 		return ""
 	}
@@ -181,3 +369,72 @@ func (l *Lookup) EdgeConfidenceValue(fromID, toID string) string {
 	}
 	return ""
 }
+
+// EdgeKindValue returns the kind of the edge fromID -> toID, defaulting to
+// a call edge.
+func (l *Lookup) EdgeKindValue(fromID, toID string) string {
+	return edgeKindOf(l.Node(fromID), toID)
+}
+
+func edgeKindOf(from *IndexNode, toID string) string {
+	if from != nil {
+		for _, item := range from.OutConfidence {
+			if item.TargetID == toID && item.Kind != "" {
+				return item.Kind
+			}
+		}
+	}
+	return string(graph.EdgeCall)
+}
+
+// FilterEdgeKinds returns a lookup whose nodes only keep edges of the given
+// kinds. A nil set keeps every edge and returns l itself.
+func (l *Lookup) FilterEdgeKinds(kinds map[string]bool) *Lookup {
+	if kinds == nil {
+		return l
+	}
+	if l.shards != nil {
+		filtered := l.filteredShards(kinds)
+		filtered.hideExternal = l.hideExternal
+		return filtered
+	}
+	nodes := l.ByID
+	filtered := &Lookup{
+		ByID:         make(map[string]*IndexNode, len(nodes)),
+		ByName:       l.ByName,
+		Aliases:      l.Aliases,
+		hideExternal: l.hideExternal,
+	}
+	for id, node := range nodes {
+		copied := *node
+		copied.OutEdges = nil
+		for _, targetID := range node.OutEdges {
+			if kinds[l.EdgeKindValue(id, targetID)] {
+				copied.OutEdges = append(copied.OutEdges, targetID)
+			}
+		}
+		copied.InEdges = nil
+		for _, sourceID := range node.InEdges {
+			if kinds[l.EdgeKindValue(sourceID, id)] {
+				copied.InEdges = append(copied.InEdges, sourceID)
+			}
+		}
+		filtered.ByID[id] = &copied
+	}
+	return filtered
+}
+
+// WithoutExternal returns a view of the lookup in which the nodes of
+// vendored and generated code are absent: Node returns nil for them, so
+// resolution, search and edge walks pass them over.
+func (l *Lookup) WithoutExternal() *Lookup {
+	view := *l
+	view.hideExternal = true
+	return &view
+}
+
+func (l *Lookup) withExternal() *Lookup {
+	view := *l
+	view.hideExternal = false
+	return &view
+}
@@ -0,0 +1,393 @@
+package nav
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/morozRed/skelly/internal/enrich"
+	"github.com/morozRed/skelly/internal/fileutil"
+	"github.com/morozRed/skelly/internal/graph"
+	"github.com/morozRed/skelly/internal/output"
+	"github.com/morozRed/skelly/internal/parser"
+	"github.com/spf13/cobra"
+)
+
+// Weights for the signals combined by ScorePack.
+const (
+	packFocusWeight   = 3.0 // scaled by 1/(1+hops) from the focus
+	packRankWeight    = 1.0 // scaled by the share of the highest importance metric
+	packRecencyWeight = 1.0 // scaled by how recently the file changed
+
+	// packFocusDepth bounds how many edges from the focus count as near it.
+	packFocusDepth = 3
+	// packGitCommits bounds how much history recency reads.
+	packGitCommits = 200
+	// packCharsPerToken approximates tokens from characters, as most
+	// tokenizers average about four characters per token for code.
+	packCharsPerToken = 4
+)
+
+// PackSymbol is one symbol in a context pack with the score that selected it.
+type PackSymbol struct {
+	ID        string  `json:"id"`
+	Name      string  `json:"name"`
+	Kind      string  `json:"kind"`
+	Signature string  `json:"signature,omitempty"`
+	File      string  `json:"file"`
+	Line      int     `json:"line"`
+	EndLine   int     `json:"end_line,omitempty"`
+	Doc       string  `json:"doc,omitempty"`
+	Score     float64 `json:"score"`
+	// Hops is the edge distance from the focus; -1 when not near it.
+	Hops int `json:"hops"`
+}
+
+// PackFile groups the packed symbols of one file, in line order, under the
+// file's overview when `skelly enrich overview` has written one.
+type PackFile struct {
+	File     string       `json:"file"`
+	Overview string       `json:"overview,omitempty"`
+	Symbols  []PackSymbol `json:"symbols"`
+}
+
+// PackBundle is a context pack that fits a token budget.
+type PackBundle struct {
+	Focus  string `json:"focus,omitempty"`
+	Budget int    `json:"budget"`
+	// Tokens estimates the tokens of the rendered bundle.
+	Tokens int `json:"tokens"`
+	// Packed and Total count the symbols included and considered.
+	Packed int        `json:"packed"`
+	Total  int        `json:"total"`
+	Files  []PackFile `json:"files"`
+}
+
+// RunPack selects the symbols most relevant to a focus (or to the whole
+// repository) and prints them as one Markdown or JSON bundle within a token
+// budget.
+func RunPack(cmd *cobra.Command, args []string) error {
+	rootPath, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to resolve working directory: %w", err)
+	}
+	asJSON, err := OptionalBoolFlag(cmd, "json", false)
+	if err != nil {
+		return err
+	}
+	budget, err := OptionalIntFlag(cmd, "budget", 8000)
+	if err != nil {
+		return err
+	}
+	if budget < 1 {
+		return fmt.Errorf("--budget must be >= 1")
+	}
+	focus, err := OptionalStringFlag(cmd, "focus")
+	if err != nil {
+		return err
+	}
+	noGit, err := OptionalBoolFlag(cmd, "no-git", false)
+	if err != nil {
+		return err
+	}
+	metric := graph.MetricPageRank
+	sortBy, err := OptionalStringFlag(cmd, "sort")
+	if err != nil {
+		return err
+	}
+	if sortBy != "" {
+		if metric, err = graph.ParseMetric(sortBy); err != nil {
+			return fmt.Errorf("--sort: %w", err)
+		}
+	}
+
+	lookup, err := LoadLookup(rootPath)
+	if err != nil {
+		return err
+	}
+	focusNodes, err := resolvePackFocus(lookup, focus)
+	if err != nil {
+		return err
+	}
+	var recency map[string]float64
+	if !noGit {
+		if recency, err = LoadRecency(rootPath, packGitCommits); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: skipping change recency: %v\n", err)
+		}
+	}
+
+	overviews, err := loadFileOverviews(rootPath)
+	if err != nil {
+		return err
+	}
+
+	candidates := ScorePack(lookup, focusNodes, recency, metric)
+	bundle := PackBundle{Focus: focus, Budget: budget, Total: len(candidates), Files: make([]PackFile, 0)}
+	if asJSON {
+		bundle = fillPack(bundle, candidates, overviews, jsonPackCost)
+		return fileutil.PrintJSON(bundle)
+	}
+	bundle = fillPack(bundle, candidates, overviews, markdownPackCost)
+	fmt.Print(RenderPackMarkdown(bundle))
+	return nil
+}
+
+// resolvePackFocus returns the symbols of an indexed file, or the symbol a
+// query names.
+func resolvePackFocus(lookup *Lookup, focus string) ([]*IndexNode, error) {
+	if focus == "" {
+		return nil, nil
+	}
+	file := filepath.ToSlash(filepath.Clean(focus))
+	nodes := make([]*IndexNode, 0)
+	for _, node := range lookup.AllNodes() {
+		if node.File == file {
+			nodes = append(nodes, node)
+		}
+	}
+	if len(nodes) > 0 {
+		return nodes, nil
+	}
+	node, err := ResolveSingleSymbol(lookup, focus)
+	if err != nil {
+		return nil, fmt.Errorf("focus %q is neither an indexed file nor a symbol: %w", focus, err)
+	}
+	return []*IndexNode{node}, nil
+}
+
+// loadFileOverviews returns the file overviews of overview.jsonl by path.
+func loadFileOverviews(rootPath string) (map[string]string, error) {
+	overviews, err := enrich.LoadOverviews(filepath.Join(rootPath, output.ContextDir, enrich.OverviewFile))
+	if err != nil {
+		return nil, err
+	}
+	byFile := make(map[string]string)
+	for _, overview := range overviews {
+		if overview.Scope == enrich.OverviewScopeFile && strings.TrimSpace(overview.Summary) != "" {
+			byFile[overview.Path] = overview.Summary
+		}
+	}
+	return byFile, nil
+}
+
+// LoadRecency scores files by how recently they changed: 1 for uncommitted
+// changes and the newest commit, falling linearly over up to maxCommits
+// commits. Paths are relative to rootPath, matching the index.
+func LoadRecency(rootPath string, maxCommits int) (map[string]float64, error) {
+	gitCmd := exec.Command("git", "log", "--relative", "--name-only", "--format=%x00", "-n", fmt.Sprint(maxCommits), "--", ".")
+	gitCmd.Dir = rootPath
+	out, err := gitCmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read git history (is %s a git repository?): %w", rootPath, err)
+	}
+
+	recency := make(map[string]float64)
+	commits := bytes.Split(bytes.TrimPrefix(out, []byte{0}), []byte{0})
+	for i, commit := range commits {
+		score := 1 - float64(i)/float64(len(commits))
+		scanner := bufio.NewScanner(bytes.NewReader(commit))
+		for scanner.Scan() {
+			if name := strings.TrimSpace(scanner.Text()); name != "" {
+				if _, seen := recency[name]; !seen {
+					recency[name] = score
+				}
+			}
+		}
+	}
+
+	diffCmd := exec.Command("git", "diff", "--relative", "--name-only", "HEAD", "--", ".")
+	diffCmd.Dir = rootPath
+	if out, err := diffCmd.Output(); err == nil {
+		for _, name := range strings.Split(string(out), "\n") {
+			if name = strings.TrimSpace(name); name != "" {
+				recency[name] = 1
+			}
+		}
+	}
+	return recency, nil
+}
+
+// ScorePack scores every indexed symbol by an importance metric (PageRank by
+// default), edge distance from the focus symbols (calls, references and
+// other edges in either direction) and the recency of its file, highest
+// first.
+func ScorePack(lookup *Lookup, focus []*IndexNode, recency map[string]float64, metric graph.Metric) []PackSymbol {
+	hops := make(map[string]int, len(focus))
+	frontier := make([]string, 0, len(focus))
+	for _, node := range focus {
+		hops[node.ID] = 0
+		frontier = append(frontier, node.ID)
+	}
+	for depth := 1; depth <= packFocusDepth && len(frontier) > 0; depth++ {
+		var next []string
+		for _, id := range frontier {
+			node := lookup.Node(id)
+			if node == nil {
+				continue
+			}
+			for _, neighbors := range [][]string{node.OutEdges, node.InEdges} {
+				for _, neighborID := range neighbors {
+					if _, seen := hops[neighborID]; seen || lookup.Node(neighborID) == nil {
+						continue
+					}
+					hops[neighborID] = depth
+					next = append(next, neighborID)
+				}
+			}
+		}
+		frontier = next
+	}
+
+	maxImportance := 0.0
+	for _, node := range lookup.AllNodes() {
+		maxImportance = max(maxImportance, node.MetricValue(metric))
+	}
+
+	symbols := make([]PackSymbol, 0, lookup.Len())
+	for _, node := range lookup.AllNodes() {
+		symbol := PackSymbol{
+			ID:        node.ID,
+			Name:      parser.Symbol{Name: node.Name, Container: node.Container}.QualifiedName(),
+			Kind:      node.Kind,
+			Signature: node.Signature,
+			File:      node.File,
+			Line:      node.Line,
+			EndLine:   node.EndLine,
+			Doc:       node.Doc,
+			Hops:      -1,
+		}
+		if hop, ok := hops[node.ID]; ok {
+			symbol.Hops = hop
+			symbol.Score += packFocusWeight / float64(1+hop)
+		}
+		if maxImportance > 0 {
+			symbol.Score += packRankWeight * node.MetricValue(metric) / maxImportance
+		}
+		symbol.Score += packRecencyWeight * recency[node.File]
+		symbols = append(symbols, symbol)
+	}
+	sort.Slice(symbols, func(i, j int) bool {
+		if symbols[i].Score != symbols[j].Score {
+			return symbols[i].Score > symbols[j].Score
+		}
+		return symbols[i].ID < symbols[j].ID
+	})
+	return symbols
+}
+
+// packCost estimates the tokens of each part of a rendered bundle: the
+// header, a file's heading with its overview and a symbol's entry.
+type packCost struct {
+	header func(PackBundle) int
+	file   func(PackFile) int
+	symbol func(PackSymbol) int
+}
+
+// fillPack adds candidates in score order while they fit the budget; a
+// symbol that does not fit is skipped so smaller ones can still fill the
+// remainder.
+func fillPack(bundle PackBundle, candidates []PackSymbol, overviews map[string]string, cost packCost) PackBundle {
+	// The header is costed at its widest, with every candidate packed.
+	used := cost.header(PackBundle{Focus: bundle.Focus, Budget: bundle.Budget, Tokens: bundle.Budget, Packed: bundle.Total, Total: bundle.Total})
+	byFile := make(map[string]int)
+	for _, symbol := range candidates {
+		symbolCost := cost.symbol(symbol)
+		index, ok := byFile[symbol.File]
+		file := PackFile{File: symbol.File, Overview: overviews[symbol.File]}
+		if !ok {
+			symbolCost += cost.file(file)
+		}
+		if used+symbolCost > bundle.Budget {
+			continue
+		}
+		used += symbolCost
+		if !ok {
+			index = len(bundle.Files)
+			byFile[symbol.File] = index
+			bundle.Files = append(bundle.Files, file)
+		}
+		bundle.Files[index].Symbols = append(bundle.Files[index].Symbols, symbol)
+		bundle.Packed++
+	}
+	for _, file := range bundle.Files {
+		sort.Slice(file.Symbols, func(i, j int) bool { return file.Symbols[i].Line < file.Symbols[j].Line })
+	}
+	bundle.Tokens = used
+	return bundle
+}
+
+func estimateTokens(text string) int {
+	return (len(text) + packCharsPerToken - 1) / packCharsPerToken
+}
+
+var markdownPackCost = packCost{
+	header: func(bundle PackBundle) int { return estimateTokens(renderPackHeader(bundle)) },
+	file:   func(file PackFile) int { return estimateTokens(renderPackFile(file)) },
+	symbol: func(symbol PackSymbol) int { return estimateTokens(renderPackSymbol(symbol)) },
+}
+
+var jsonPackCost = packCost{
+	header: func(bundle PackBundle) int {
+		data, _ := json.MarshalIndent(bundle, "", "  ")
+		return estimateTokens(string(data))
+	},
+	file: func(file PackFile) int {
+		data, _ := json.MarshalIndent(file, "    ", "  ")
+		return estimateTokens(string(data))
+	},
+	symbol: func(symbol PackSymbol) int {
+		data, _ := json.MarshalIndent(symbol, "        ", "  ")
+		return estimateTokens(string(data))
+	},
+}
+
+// RenderPackMarkdown renders a bundle as Markdown: a header, then each file
+// with its overview and the signatures and docs of its packed symbols.
+func RenderPackMarkdown(bundle PackBundle) string {
+	var sb strings.Builder
+	sb.WriteString(renderPackHeader(bundle))
+	for _, file := range bundle.Files {
+		sb.WriteString(renderPackFile(file))
+		for _, symbol := range file.Symbols {
+			sb.WriteString(renderPackSymbol(symbol))
+		}
+	}
+	return sb.String()
+}
+
+func renderPackHeader(bundle PackBundle) string {
+	focus := "repository"
+	if bundle.Focus != "" {
+		focus = "`" + bundle.Focus + "`"
+	}
+	return fmt.Sprintf("# Context pack: %s\n\n~%d of %d tokens, %d of %d symbols\n", focus, bundle.Tokens, bundle.Budget, bundle.Packed, bundle.Total)
+}
+
+func renderPackFile(file PackFile) string {
+	if file.Overview == "" {
+		return "\n## " + file.File + "\n\n"
+	}
+	return "\n## " + file.File + "\n\n" + file.Overview + "\n\n"
+}
+
+func renderPackSymbol(symbol PackSymbol) string {
+	signature := symbol.Signature
+	if signature == "" {
+		signature = symbol.Name
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "- `%s` (%s, %s:%d)\n", signature, symbol.Kind, path.Base(symbol.File), symbol.Line)
+	for _, line := range strings.Split(strings.TrimSpace(symbol.Doc), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			sb.WriteString("  " + line + "\n")
+		}
+	}
+	return sb.String()
+}
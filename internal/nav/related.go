@@ -0,0 +1,247 @@
+package nav
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/morozRed/skelly/internal/fileutil"
+	"github.com/spf13/cobra"
+)
+
+// Weights for the signals combined by RankRelated.
+const (
+	relatedDirectWeight    = 3.0 // the files call each other
+	relatedSharedDepWeight = 1.0 // per file both call into
+	relatedCoCallerWeight  = 1.0 // per file calling into both
+	relatedCoChangeWeight  = 4.0 // scaled by the share of the file's commits that also touched the candidate
+
+	// relatedGitCommits bounds how much history --git reads.
+	relatedGitCommits = 500
+)
+
+// RunRelated ranks files an agent probably also needs when working on one file.
+func RunRelated(cmd *cobra.Command, args []string) error {
+	rootPath, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to resolve working directory: %w", err)
+	}
+	asJSON, err := OptionalBoolFlag(cmd, "json", false)
+	if err != nil {
+		return err
+	}
+	limit, err := OptionalIntFlag(cmd, "limit", 10)
+	if err != nil {
+		return err
+	}
+	useGit, err := OptionalBoolFlag(cmd, "git", false)
+	if err != nil {
+		return err
+	}
+
+	lookup, err := LoadLookup(rootPath)
+	if err != nil {
+		return err
+	}
+	file := filepath.ToSlash(filepath.Clean(args[0]))
+
+	var history *CoChangeHistory
+	if useGit {
+		history, err = LoadCoChangeHistory(rootPath, file, relatedGitCommits)
+		if err != nil {
+			return err
+		}
+	}
+
+	related, err := RankRelated(lookup, file, history)
+	if err != nil {
+		return err
+	}
+	total := len(related)
+	if limit > 0 && len(related) > limit {
+		related = related[:limit]
+	}
+
+	if asJSON {
+		return fileutil.PrintJSON(map[string]any{
+			"file":    file,
+			"git":     useGit,
+			"total":   total,
+			"related": related,
+		})
+	}
+
+	fmt.Printf("related files for %s (%d of %d)\n", file, len(related), total)
+	for _, entry := range related {
+		fmt.Printf("- %s score=%.2f %s\n", entry.File, entry.Score, strings.Join(entry.reasons(), ", "))
+	}
+	return nil
+}
+
+// CoChangeHistory counts how often other files were committed together with a file.
+type CoChangeHistory struct {
+	Commits   int
+	CoChanges map[string]int
+}
+
+// LoadCoChangeHistory reads up to maxCommits commits touching file from git.
+// Paths are reported relative to rootPath, matching the index.
+func LoadCoChangeHistory(rootPath, file string, maxCommits int) (*CoChangeHistory, error) {
+	gitCmd := exec.Command("git", "log", "--relative", "--name-only", "--format=%x00", "-n", fmt.Sprint(maxCommits), "--", ".")
+	gitCmd.Dir = rootPath
+	out, err := gitCmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read git history (is %s a git repository?): %w", rootPath, err)
+	}
+
+	history := &CoChangeHistory{CoChanges: make(map[string]int)}
+	for _, commit := range bytes.Split(out, []byte{0}) {
+		files := make([]string, 0)
+		touchesFile := false
+		scanner := bufio.NewScanner(bytes.NewReader(commit))
+		for scanner.Scan() {
+			name := strings.TrimSpace(scanner.Text())
+			if name == "" {
+				continue
+			}
+			if name == file {
+				touchesFile = true
+				continue
+			}
+			files = append(files, name)
+		}
+		if !touchesFile {
+			continue
+		}
+		history.Commits++
+		for _, name := range files {
+			history.CoChanges[name]++
+		}
+	}
+	return history, nil
+}
+
+// RankRelated scores every other indexed file against file using direct
+// calls, shared callees, shared callers, directory proximity and, when
+// history is non-nil, git co-change. Files without any signal are omitted.
+func RankRelated(lookup *Lookup, file string, history *CoChangeHistory) ([]RelatedFile, error) {
+	callees := make(map[string]map[string]bool)
+	callers := make(map[string]map[string]bool)
+	files := make(map[string]bool)
+	for _, node := range lookup.AllNodes() {
+		files[node.File] = true
+		for _, targetID := range node.OutEdges {
+			target := lookup.Node(targetID)
+			if target == nil || target.File == node.File {
+				continue
+			}
+			addRelatedLink(callees, node.File, target.File)
+			addRelatedLink(callers, target.File, node.File)
+		}
+	}
+	if !files[file] {
+		return nil, fmt.Errorf("file %q is not indexed (run skelly update)", file)
+	}
+
+	related := make([]RelatedFile, 0)
+	for candidate := range files {
+		if candidate == file {
+			continue
+		}
+		entry := RelatedFile{
+			File:       candidate,
+			Calls:      callees[file][candidate],
+			CalledBy:   callers[file][candidate],
+			SharedDeps: countShared(callees[file], callees[candidate], file, candidate),
+			CoCallers:  countShared(callers[file], callers[candidate], file, candidate),
+			Proximity:  directoryProximity(file, candidate),
+		}
+		if history != nil && history.Commits > 0 {
+			entry.CoChanges = history.CoChanges[candidate]
+		}
+
+		if entry.Calls || entry.CalledBy {
+			entry.Score += relatedDirectWeight
+		}
+		entry.Score += relatedSharedDepWeight*float64(entry.SharedDeps) +
+			relatedCoCallerWeight*float64(entry.CoCallers) +
+			entry.Proximity
+		if entry.CoChanges > 0 {
+			entry.Score += relatedCoChangeWeight * float64(entry.CoChanges) / float64(history.Commits)
+		}
+		if entry.Score > 0 {
+			related = append(related, entry)
+		}
+	}
+
+	sort.Slice(related, func(i, j int) bool {
+		if related[i].Score != related[j].Score {
+			return related[i].Score > related[j].Score
+		}
+		return related[i].File < related[j].File
+	})
+	return related, nil
+}
+
+func addRelatedLink(links map[string]map[string]bool, from, to string) {
+	if links[from] == nil {
+		links[from] = make(map[string]bool)
+	}
+	links[from][to] = true
+}
+
+// countShared counts files linked from both a and b, ignoring the pair itself.
+func countShared(a, b map[string]bool, fileA, fileB string) int {
+	shared := 0
+	for file := range a {
+		if b[file] && file != fileA && file != fileB {
+			shared++
+		}
+	}
+	return shared
+}
+
+// directoryProximity is 1 for files in the same directory and 0.5 when one
+// directory directly contains the other.
+func directoryProximity(a, b string) float64 {
+	dirA, dirB := path.Dir(a), path.Dir(b)
+	switch {
+	case dirA == dirB:
+		return 1
+	case path.Dir(dirA) == dirB || path.Dir(dirB) == dirA:
+		return 0.5
+	default:
+		return 0
+	}
+}
+
+func (r RelatedFile) reasons() []string {
+	reasons := make([]string, 0)
+	switch {
+	case r.Calls && r.CalledBy:
+		reasons = append(reasons, "calls each other")
+	case r.Calls:
+		reasons = append(reasons, "called")
+	case r.CalledBy:
+		reasons = append(reasons, "caller")
+	}
+	if r.SharedDeps > 0 {
+		reasons = append(reasons, fmt.Sprintf("shared_deps=%d", r.SharedDeps))
+	}
+	if r.CoCallers > 0 {
+		reasons = append(reasons, fmt.Sprintf("co_callers=%d", r.CoCallers))
+	}
+	if r.Proximity > 0 {
+		reasons = append(reasons, fmt.Sprintf("proximity=%.1f", r.Proximity))
+	}
+	if r.CoChanges > 0 {
+		reasons = append(reasons, fmt.Sprintf("co_changes=%d", r.CoChanges))
+	}
+	return reasons
+}
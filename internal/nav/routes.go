@@ -0,0 +1,228 @@
+package nav
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/morozRed/skelly/internal/fileutil"
+	"github.com/morozRed/skelly/internal/graph"
+	"github.com/morozRed/skelly/internal/output"
+	"github.com/morozRed/skelly/internal/parser"
+	"github.com/spf13/cobra"
+)
+
+// RoutesFile maps HTTP routes to their handler symbols.
+const RoutesFile = "routes.jsonl"
+
+// RouteRecord is one method and path registration in routes.jsonl.
+type RouteRecord struct {
+	Method string `json:"method"` // GET, POST, ... or ANY
+	Path   string `json:"path"`   // as written by the framework: /users/:id, /users/{id}, /users/<int:id>
+	// Handler is the handler's symbol ID; HandlerName is the handler as
+	// written at the registration. Both are empty for inline handlers.
+	Handler     string `json:"handler,omitempty"`
+	HandlerName string `json:"handler_name,omitempty"`
+	Confidence  string `json:"confidence,omitempty"`
+	ID          string `json:"id"` // route symbol ID
+	File        string `json:"file"`
+	Line        int    `json:"line"`
+	Language    string `json:"language,omitempty"`
+}
+
+// BuildRoutes collects the route symbols of the graph, sorted by path, method
+// and location.
+func BuildRoutes(g *graph.Graph) []RouteRecord {
+	routes := make([]RouteRecord, 0)
+	for _, file := range g.Files() {
+		for _, node := range g.NodesForFile(file) {
+			if node.Symbol.Kind != parser.SymbolRoute {
+				continue
+			}
+			method, path, _ := strings.Cut(node.Symbol.Name, " ")
+			route := RouteRecord{
+				Method:   method,
+				Path:     path,
+				ID:       node.ID,
+				File:     node.File,
+				Line:     node.Symbol.Line,
+				Language: node.Language,
+			}
+			if len(node.Symbol.Calls) > 0 {
+				route.HandlerName = node.Symbol.Calls[0].Raw
+			}
+			targets := make([]string, 0, len(node.OutEdges))
+			for _, targetID := range node.OutEdges {
+				if node.EdgeKindTo(targetID) == graph.EdgeCall {
+					targets = append(targets, targetID)
+				}
+			}
+			if len(targets) > 0 {
+				sort.Strings(targets)
+				route.Handler = targets[0]
+				route.Confidence = node.OutEdgeConfidence[targets[0]]
+			}
+			routes = append(routes, route)
+		}
+	}
+	sort.SliceStable(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		if routes[i].Method != routes[j].Method {
+			return routes[i].Method < routes[j].Method
+		}
+		if routes[i].File != routes[j].File {
+			return routes[i].File < routes[j].File
+		}
+		return routes[i].Line < routes[j].Line
+	})
+	return routes
+}
+
+// WriteRoutes writes routes.jsonl.
+func WriteRoutes(contextDir string, g *graph.Graph) error {
+	data, err := fileutil.EncodeJSONL(BuildRoutes(g))
+	if err != nil {
+		return fmt.Errorf("failed to encode routes: %w", err)
+	}
+	return fileutil.WriteIfChanged(filepath.Join(contextDir, RoutesFile), data)
+}
+
+// LoadRoutes reads routes.jsonl from a repository's context directory.
+func LoadRoutes(rootPath string) ([]RouteRecord, error) {
+	path := filepath.Join(rootPath, output.ContextDir, RoutesFile)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("routes index missing at %s (run skelly update)", path)
+		}
+		return nil, fmt.Errorf("failed to read routes index: %w", err)
+	}
+
+	routes := make([]RouteRecord, 0)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var route RouteRecord
+		if err := json.Unmarshal(line, &route); err != nil {
+			return nil, fmt.Errorf("failed to decode routes index: %w", err)
+		}
+		routes = append(routes, route)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read routes index: %w", err)
+	}
+	return routes, nil
+}
+
+// RouteMatches reports whether a request path (or a route path as written)
+// matches a route path. Parameter segments (:id, {id}, <int:id>) match any
+// one segment; catch-all segments (*, *path, {path...}) and net/http subtree
+// patterns ending in / (other than the root) match the rest of the path.
+func RouteMatches(route, path string) bool {
+	if route == path {
+		return true
+	}
+	routeSegments := strings.Split(strings.Trim(route, "/"), "/")
+	pathSegments := strings.Split(strings.Trim(path, "/"), "/")
+	for i, segment := range routeSegments {
+		if strings.HasPrefix(segment, "*") || (strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "...}")) {
+			return i < len(pathSegments)
+		}
+		if i >= len(pathSegments) {
+			return false
+		}
+		if isRouteParameter(segment) {
+			if pathSegments[i] == "" {
+				return false
+			}
+			continue
+		}
+		if segment != pathSegments[i] {
+			return false
+		}
+	}
+	if len(pathSegments) > len(routeSegments) {
+		return route != "/" && strings.HasSuffix(route, "/")
+	}
+	return true
+}
+
+func isRouteParameter(segment string) bool {
+	return strings.HasPrefix(segment, ":") ||
+		(strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}")) ||
+		(strings.HasPrefix(segment, "<") && strings.HasSuffix(segment, ">"))
+}
+
+func RunRoutes(cmd *cobra.Command, args []string) error {
+	rootPath, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to resolve working directory: %w", err)
+	}
+	asJSON, err := OptionalBoolFlag(cmd, "json", false)
+	if err != nil {
+		return err
+	}
+	method, err := OptionalStringFlag(cmd, "method")
+	if err != nil {
+		return err
+	}
+	path := ""
+	if len(args) > 0 {
+		path = args[0]
+	}
+
+	routes, err := LoadRoutes(rootPath)
+	if err != nil {
+		return err
+	}
+	matches := make([]RouteRecord, 0, len(routes))
+	for _, route := range routes {
+		if method != "" && route.Method != "ANY" && !strings.EqualFold(route.Method, method) {
+			continue
+		}
+		if path != "" && !RouteMatches(route.Path, path) {
+			continue
+		}
+		matches = append(matches, route)
+	}
+
+	if asJSON {
+		return fileutil.PrintJSON(map[string]any{
+			"path":   path,
+			"method": strings.ToUpper(method),
+			"total":  len(routes),
+			"routes": matches,
+		})
+	}
+
+	if path == "" && method == "" {
+		fmt.Printf("routes (%d)\n", len(matches))
+	} else {
+		fmt.Printf("routes matching %q (%d of %d)\n", strings.TrimSpace(strings.ToUpper(method)+" "+path), len(matches), len(routes))
+	}
+	for _, route := range matches {
+		handler := route.Handler
+		switch {
+		case handler != "":
+			handler += " [" + route.Confidence + "]"
+		case route.HandlerName != "":
+			handler = route.HandlerName + " [unresolved]"
+		default:
+			handler = "(inline)"
+		}
+		fmt.Printf("- %s %s -> %s\n", route.Method, route.Path, handler)
+		fmt.Printf("  at %s:%d\n", route.File, route.Line)
+	}
+	return nil
+}
@@ -0,0 +1,365 @@
+package nav
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/morozRed/skelly/internal/fileutil"
+	"github.com/morozRed/skelly/internal/graph"
+	"github.com/morozRed/skelly/internal/parser"
+	"github.com/morozRed/skelly/internal/search"
+	"github.com/spf13/cobra"
+)
+
+var (
+	signatureSpaceRe = regexp.MustCompile(`\s+`)
+	signatureCommaRe = regexp.MustCompile(`\s*,\s*`)
+)
+
+// SignatureMatcher matches stored symbol signatures against a glob or regex pattern.
+type SignatureMatcher struct {
+	pattern *regexp.Regexp
+}
+
+// NewSignatureMatcher compiles a signature pattern. Glob patterns use * and ?
+// wildcards and must match the whole (whitespace-normalized) signature; regex
+// patterns match anywhere. In a glob, \* is a literal star, and so is a *
+// that opens a type, written right after "(", "[", "]", "*" or ", " and
+// before a name, so "func (*Server) Handle*(*)" keeps to pointer receivers.
+func NewSignatureMatcher(pattern string, isRegex bool) (*SignatureMatcher, error) {
+	pattern = strings.TrimSpace(pattern)
+	if pattern == "" {
+		return nil, fmt.Errorf("signature pattern is empty")
+	}
+
+	var expr string
+	if isRegex {
+		expr = pattern
+	} else {
+		var sb strings.Builder
+		sb.WriteString("^")
+		runes := []rune(NormalizeSignature(pattern))
+		for i := 0; i < len(runes); i++ {
+			switch r := runes[i]; {
+			case r == '\\' && i+1 < len(runes):
+				i++
+				sb.WriteString(regexp.QuoteMeta(string(runes[i])))
+			case r == '*' && isPointerStar(runes, i):
+				sb.WriteString(`\*`)
+			case r == '*':
+				sb.WriteString(".*")
+			case r == '?':
+				sb.WriteString(".")
+			default:
+				sb.WriteString(regexp.QuoteMeta(string(r)))
+			}
+		}
+		sb.WriteString("$")
+		expr = sb.String()
+	}
+
+	compiled, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signature pattern %q: %w", pattern, err)
+	}
+	return &SignatureMatcher{pattern: compiled}, nil
+}
+
+// isPointerStar reports whether the * at runes[i] opens a pointer type: it
+// follows "(", "[", "]", "*" or ", " and precedes an identifier.
+func isPointerStar(runes []rune, i int) bool {
+	if i == 0 || i+1 >= len(runes) {
+		return false
+	}
+	next := runes[i+1]
+	if next != '_' && !unicode.IsLetter(next) {
+		return false
+	}
+	switch runes[i-1] {
+	case '(', '[', ']', '*':
+		return true
+	case ' ':
+		return i >= 2 && runes[i-2] == ','
+	}
+	return false
+}
+
+// Match reports whether the node signature, or its type-only shape for Go, matches.
+func (m *SignatureMatcher) Match(node *IndexNode) bool {
+	if node == nil || node.Signature == "" {
+		return false
+	}
+	if m.pattern.MatchString(NormalizeSignature(node.Signature)) {
+		return true
+	}
+	return node.Shape != "" && m.pattern.MatchString(NormalizeSignature(node.Shape))
+}
+
+// NormalizeSignature collapses whitespace so patterns are not sensitive to formatting.
+func NormalizeSignature(signature string) string {
+	normalized := signatureSpaceRe.ReplaceAllString(strings.TrimSpace(signature), " ")
+	normalized = signatureCommaRe.ReplaceAllString(normalized, ", ")
+	normalized = strings.ReplaceAll(normalized, "( ", "(")
+	return strings.ReplaceAll(normalized, " )", ")")
+}
+
+// SearchFilter narrows search results to symbol kinds and a path prefix.
+type SearchFilter struct {
+	Kinds map[string]bool
+	File  string
+	// Visibilities, when set, keeps symbols with one of these visibilities;
+	// symbols whose language records none never match.
+	Visibilities map[string]bool
+	// IncludeExternal keeps the symbols of vendored and generated code,
+	// which are left out by default.
+	IncludeExternal bool
+	// Sort, when set, orders results by that importance metric, highest
+	// first, instead of by relevance or location.
+	Sort graph.Metric
+}
+
+// Match reports whether a node passes the filter. File matches the node's
+// file or any directory above it.
+func (f SearchFilter) Match(node *IndexNode) bool {
+	if node == nil {
+		return false
+	}
+	if len(f.Kinds) > 0 && !f.Kinds[node.Kind] {
+		return false
+	}
+	if f.File != "" && node.File != f.File && !strings.HasPrefix(node.File, f.File+"/") {
+		return false
+	}
+	if len(f.Visibilities) > 0 && !f.Visibilities[node.Visibility] {
+		return false
+	}
+	if node.External && !f.IncludeExternal {
+		return false
+	}
+	return true
+}
+
+// HybridMatch is a symbol ranked by a plain search query.
+type HybridMatch struct {
+	SymbolRecord
+	Score float64 `json:"score"`
+	Exact bool    `json:"exact,omitempty"`
+	BM25  float64 `json:"bm25,omitempty"`
+	Fuzzy float64 `json:"fuzzy,omitempty"`
+}
+
+func RunSearch(cmd *cobra.Command, args []string) error {
+	rootPath, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to resolve working directory: %w", err)
+	}
+	asJSON, err := OptionalBoolFlag(cmd, "json", false)
+	if err != nil {
+		return err
+	}
+	signature, err := OptionalStringFlag(cmd, "signature")
+	if err != nil {
+		return err
+	}
+	isRegex, err := OptionalBoolFlag(cmd, "regex", false)
+	if err != nil {
+		return err
+	}
+	limit, err := OptionalIntFlag(cmd, "limit", 50)
+	if err != nil {
+		return err
+	}
+	semantic, err := OptionalStringFlag(cmd, "semantic")
+	if err != nil {
+		return err
+	}
+	filter, err := searchFilterFromFlags(cmd)
+	if err != nil {
+		return err
+	}
+	query := ""
+	if len(args) > 0 {
+		query = strings.TrimSpace(args[0])
+	}
+	modes := 0
+	for _, value := range []string{query, signature, semantic} {
+		if value != "" {
+			modes++
+		}
+	}
+	switch {
+	case modes > 1:
+		return fmt.Errorf("use only one of <query>, --signature or --semantic")
+	case semantic != "":
+		return runSemanticSearch(cmd, rootPath, semantic, filter, limit, asJSON)
+	case query != "":
+		return runHybridSearch(rootPath, query, filter, limit, asJSON)
+	case signature == "":
+		return fmt.Errorf("search requires a <query>, --signature <pattern> or --semantic <query>")
+	}
+
+	matcher, err := NewSignatureMatcher(signature, isRegex)
+	if err != nil {
+		return err
+	}
+	lookup, err := LoadLookup(rootPath)
+	if err != nil {
+		return err
+	}
+
+	matches := make([]*IndexNode, 0)
+	for _, node := range lookup.AllNodes() {
+		if filter.Match(node) && matcher.Match(node) {
+			matches = append(matches, node)
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].File == matches[j].File {
+			if matches[i].Line == matches[j].Line {
+				return matches[i].ID < matches[j].ID
+			}
+			return matches[i].Line < matches[j].Line
+		}
+		return matches[i].File < matches[j].File
+	})
+	if filter.Sort != "" {
+		SortByMetric(matches, func(node *IndexNode) *IndexNode { return node }, filter.Sort)
+	}
+	total := len(matches)
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+
+	records := make([]SymbolRecord, 0, len(matches))
+	for _, match := range matches {
+		records = append(records, SymbolRecordFromNode(match))
+	}
+
+	if asJSON {
+		return fileutil.PrintJSON(map[string]any{
+			"signature": signature,
+			"regex":     isRegex,
+			"total":     total,
+			"matches":   records,
+		})
+	}
+
+	fmt.Printf("signature matches for %q (%d of %d)\n", signature, len(records), total)
+	for _, record := range records {
+		fmt.Printf("- %s [%s] %s:%d\n", record.ID, record.Kind, record.File, record.Line)
+		fmt.Printf("  sig: %s\n", record.Signature)
+	}
+	return nil
+}
+
+func searchFilterFromFlags(cmd *cobra.Command) (SearchFilter, error) {
+	filter := SearchFilter{}
+	if flag := cmd.Flags().Lookup("kind"); flag != nil {
+		values, err := cmd.Flags().GetStringSlice("kind")
+		if err != nil {
+			return filter, fmt.Errorf("failed to read --kind flag: %w", err)
+		}
+		for _, value := range values {
+			if value = strings.ToLower(strings.TrimSpace(value)); value != "" {
+				if filter.Kinds == nil {
+					filter.Kinds = make(map[string]bool)
+				}
+				filter.Kinds[value] = true
+			}
+		}
+	}
+	file, err := OptionalStringFlag(cmd, "file")
+	if err != nil {
+		return filter, err
+	}
+	filter.File = strings.TrimSuffix(filepath.ToSlash(filepath.Clean(file)), "/")
+	if file == "" || filter.File == "." {
+		filter.File = ""
+	}
+	if flag := cmd.Flags().Lookup("visibility"); flag != nil {
+		values, err := cmd.Flags().GetStringSlice("visibility")
+		if err != nil {
+			return filter, fmt.Errorf("failed to read --visibility flag: %w", err)
+		}
+		if filter.Visibilities, err = parser.ParseVisibilities(values); err != nil {
+			return filter, fmt.Errorf("--visibility: %w", err)
+		}
+	}
+	if filter.IncludeExternal, err = OptionalBoolFlag(cmd, "include-external", false); err != nil {
+		return filter, err
+	}
+	sortBy, err := OptionalStringFlag(cmd, "sort")
+	if err != nil {
+		return filter, err
+	}
+	if sortBy != "" {
+		if filter.Sort, err = graph.ParseMetric(sortBy); err != nil {
+			return filter, fmt.Errorf("--sort: %w", err)
+		}
+	}
+	return filter, nil
+}
+
+// HybridSearch ranks the symbols passing filter for a plain query, best
+// first.
+func HybridSearch(lookup *Lookup, index *search.Index, query string, filter SearchFilter) []HybridMatch {
+	matches := make([]HybridMatch, 0)
+	for _, hit := range search.Hybrid(index, query) {
+		node := lookup.Node(hit.ID)
+		if !filter.Match(node) {
+			continue
+		}
+		matches = append(matches, HybridMatch{
+			SymbolRecord: SymbolRecordFromNode(node),
+			Score:        hit.Score,
+			Exact:        hit.Exact,
+			BM25:         hit.BM25,
+			Fuzzy:        hit.Fuzzy,
+		})
+	}
+	if filter.Sort != "" {
+		SortByMetric(matches, func(match HybridMatch) *IndexNode { return lookup.Node(match.ID) }, filter.Sort)
+	}
+	return matches
+}
+
+// runHybridSearch ranks symbols for a plain query by exact name, BM25 and
+// typo-tolerant name matches.
+func runHybridSearch(rootPath, query string, filter SearchFilter, limit int, asJSON bool) error {
+	lookup, err := LoadLookup(rootPath)
+	if err != nil {
+		return err
+	}
+	index, err := LoadSearchIndex(rootPath)
+	if err != nil {
+		return err
+	}
+
+	matches := HybridSearch(lookup, index, query, filter)
+	total := len(matches)
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+
+	if asJSON {
+		return fileutil.PrintJSON(map[string]any{
+			"query":   query,
+			"total":   total,
+			"matches": matches,
+		})
+	}
+
+	fmt.Printf("search matches for %q (%d of %d)\n", query, len(matches), total)
+	for _, match := range matches {
+		fmt.Printf("- %s [%s] %s:%d score=%.3f\n", match.ID, match.Kind, match.File, match.Line, match.Score)
+		if match.Signature != "" {
+			fmt.Printf("  sig: %s\n", match.Signature)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+package nav
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/morozRed/skelly/internal/embed"
+	"github.com/morozRed/skelly/internal/fileutil"
+	"github.com/morozRed/skelly/internal/search"
+	"github.com/spf13/cobra"
+)
+
+// semanticWeight is the cosine similarity's share of a semantic search
+// score; BM25, scaled to the best lexical match, gets the rest.
+const semanticWeight = 0.7
+
+// SemanticMatch is a symbol ranked by search --semantic.
+type SemanticMatch struct {
+	SymbolRecord
+	Score      float64 `json:"score"`
+	Similarity float64 `json:"similarity"`
+	BM25       float64 `json:"bm25"`
+}
+
+// EmbedderFromFlags builds the embeddings provider from the --embed-command,
+// --embed-endpoint and --embed-model flags, returning it with the model.
+func EmbedderFromFlags(cmd *cobra.Command) (embed.Embedder, string, error) {
+	command, err := OptionalStringFlag(cmd, "embed-command")
+	if err != nil {
+		return nil, "", err
+	}
+	endpoint, err := OptionalStringFlag(cmd, "embed-endpoint")
+	if err != nil {
+		return nil, "", err
+	}
+	model, err := OptionalStringFlag(cmd, "embed-model")
+	if err != nil {
+		return nil, "", err
+	}
+	embedder, err := embed.NewEmbedder(command, endpoint, model)
+	if err != nil {
+		return nil, "", err
+	}
+	return embedder, model, nil
+}
+
+// RankSemantic blends each symbol's cosine similarity to the query vector
+// with its BM25 score for the query text, highest first. Symbols with
+// neither signal are omitted.
+func RankSemantic(lookup *Lookup, index *search.Index, store *embed.Store, query string, queryVector []float32) ([]SemanticMatch, error) {
+	similarities, err := store.Similarities(queryVector)
+	if err != nil {
+		return nil, err
+	}
+	bm25 := make(map[string]float64)
+	maxBM25 := 0.0
+	if index != nil {
+		for _, result := range search.Search(index, query, len(index.Documents)) {
+			bm25[result.ID] = result.Score
+			maxBM25 = max(maxBM25, result.Score)
+		}
+	}
+
+	matches := make([]SemanticMatch, 0, lookup.Len())
+	for id, node := range lookup.AllNodes() {
+		similarity, embedded := similarities[id]
+		lexical := bm25[id]
+		if !embedded && lexical == 0 {
+			continue
+		}
+		match := SemanticMatch{
+			SymbolRecord: SymbolRecordFromNode(node),
+			Similarity:   similarity,
+			BM25:         lexical,
+			Score:        semanticWeight * similarity,
+		}
+		if maxBM25 > 0 {
+			match.Score += (1 - semanticWeight) * lexical / maxBM25
+		}
+		matches = append(matches, match)
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return matches[i].ID < matches[j].ID
+	})
+	return matches, nil
+}
+
+func runSemanticSearch(cmd *cobra.Command, rootPath, query string, filter SearchFilter, limit int, asJSON bool) error {
+	embedder, model, err := EmbedderFromFlags(cmd)
+	if err != nil {
+		return err
+	}
+	store, err := embed.Load(rootPath)
+	if err != nil {
+		return err
+	}
+	if store == nil {
+		return fmt.Errorf("embeddings missing at %s (run skelly enrich embed)", embed.Path(rootPath))
+	}
+	if store.Model != model {
+		return fmt.Errorf("embeddings were built with model %q, not %q (run skelly enrich embed)", store.Model, model)
+	}
+	lookup, err := LoadLookup(rootPath)
+	if err != nil {
+		return err
+	}
+	index, err := LoadSearchIndex(rootPath)
+	if err != nil {
+		return err
+	}
+	embedded := make(map[string]bool, len(store.Entries))
+	for _, entry := range store.Entries {
+		embedded[entry.ID] = true
+	}
+	missing := 0
+	for id := range lookup.AllNodes() {
+		if !embedded[id] {
+			missing++
+		}
+	}
+	if missing > 0 {
+		fmt.Fprintf(os.Stderr, "warning: %d symbols have no embedding (run skelly enrich embed)\n", missing)
+	}
+
+	vectors, err := embedder.Embed([]string{query})
+	if err != nil {
+		return err
+	}
+	ranked, err := RankSemantic(lookup, index, store, query, vectors[0])
+	if err != nil {
+		return err
+	}
+	matches := make([]SemanticMatch, 0, len(ranked))
+	for _, match := range ranked {
+		if filter.Match(lookup.Node(match.ID)) {
+			matches = append(matches, match)
+		}
+	}
+	if filter.Sort != "" {
+		SortByMetric(matches, func(match SemanticMatch) *IndexNode { return lookup.Node(match.ID) }, filter.Sort)
+	}
+	total := len(matches)
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+
+	if asJSON {
+		return fileutil.PrintJSON(map[string]any{
+			"semantic": query,
+			"model":    model,
+			"total":    total,
+			"matches":  matches,
+		})
+	}
+
+	fmt.Printf("semantic matches for %q (%d of %d)\n", query, len(matches), total)
+	for _, match := range matches {
+		fmt.Printf("- %s [%s] %s:%d score=%.3f (cosine=%.3f bm25=%.2f)\n", match.ID, match.Kind, match.File, match.Line, match.Score, match.Similarity, match.BM25)
+		if match.Signature != "" {
+			fmt.Printf("  sig: %s\n", match.Signature)
+		}
+	}
+	return nil
+}
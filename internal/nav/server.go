@@ -0,0 +1,473 @@
+package nav
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/morozRed/skelly/internal/enrich"
+	"github.com/morozRed/skelly/internal/output"
+	"github.com/morozRed/skelly/internal/search"
+	"github.com/morozRed/skelly/internal/webui"
+	"github.com/spf13/cobra"
+)
+
+// DefaultHTTPAddress is where `serve --http` listens when no address is given.
+const DefaultHTTPAddress = "127.0.0.1:7878"
+
+// Server exposes the navigation index, search index and enrich records as a
+// read-only JSON API. Each artifact is reloaded when `update` rewrites it, so
+// a long-running server follows the working tree.
+type Server struct {
+	rootPath string
+	// ui serves the graph explorer under /ui/ next to the API.
+	ui bool
+
+	mu      sync.Mutex
+	lookup  cachedArtifact[*Lookup]
+	index   cachedArtifact[*search.Index]
+	records cachedArtifact[map[string]enrich.Record]
+}
+
+// apiError is an error with the HTTP status it is reported with.
+type apiError struct {
+	status     int
+	message    string
+	candidates []string
+}
+
+func (e *apiError) Error() string { return e.message }
+
+// NewServer returns a server for the repository at rootPath.
+func NewServer(rootPath string) *Server {
+	return &Server{rootPath: rootPath}
+}
+
+// EnableUI serves the web graph explorer under /ui/ and redirects / to it.
+func (s *Server) EnableUI() {
+	s.ui = true
+}
+
+// Handler routes the API endpoints. Symbol arguments accept anything
+// `symbol` resolves (ID, name or qualified name); IDs contain "|" and "/",
+// so clients should escape them.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /health", s.handle(s.health))
+	mux.HandleFunc("GET /symbols", s.handle(s.symbols))
+	mux.HandleFunc("GET /symbols/{id...}", s.handle(s.symbol))
+	mux.HandleFunc("GET /callers/{id...}", s.handle(s.callers))
+	mux.HandleFunc("GET /callees/{id...}", s.handle(s.callees))
+	mux.HandleFunc("GET /trace/{id...}", s.handle(s.trace))
+	mux.HandleFunc("GET /search", s.handle(s.search))
+	mux.HandleFunc("GET /enrich/{id...}", s.handle(s.enrichRecords))
+	if s.ui {
+		mux.Handle("GET /ui/", http.StripPrefix("/ui", webui.Handler()))
+		mux.Handle("GET /{$}", http.RedirectHandler("/ui/", http.StatusFound))
+	}
+	return mux
+}
+
+func (s *Server) handle(endpoint func(*http.Request) (any, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		status := http.StatusOK
+		payload, err := endpoint(r)
+		if err != nil {
+			status = http.StatusInternalServerError
+			body := map[string]any{"error": err.Error()}
+			var apiErr *apiError
+			if errors.As(err, &apiErr) {
+				status = apiErr.status
+				if len(apiErr.candidates) > 0 {
+					body["candidates"] = apiErr.candidates
+				}
+			}
+			payload = body
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(payload)
+	}
+}
+
+func (s *Server) health(r *http.Request) (any, error) {
+	lookup, err := s.loadLookup()
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{"status": "ok", "root": s.rootPath, "symbols": lookup.Len()}, nil
+}
+
+// symbols resolves ?q= like `symbol` (with &fuzzy=true for BM25 and typo
+// matches) or, without q, lists symbols by file and line. &kind=, &file=
+// and &visibility= filter, &include_external=true keeps vendored and
+// generated code, &limit= caps (default 50, 0 for all).
+func (s *Server) symbols(r *http.Request) (any, error) {
+	lookup, err := s.loadLookup()
+	if err != nil {
+		return nil, err
+	}
+	filter := searchFilterFromQuery(r)
+	limit, err := intParam(r, "limit", 50)
+	if err != nil {
+		return nil, err
+	}
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+
+	var nodes []*IndexNode
+	if query != "" {
+		// Filters apply after resolution, so resolve without a cap.
+		options := ResolveOptions{Fuzzy: r.URL.Query().Get("fuzzy") == "true", Limit: lookup.Len()}
+		var index *search.Index
+		if options.Fuzzy {
+			if index, err = s.loadSearchIndex(); err != nil {
+				return nil, err
+			}
+		}
+		nodes = ResolveWithOptions(lookup, index, query, options)
+	} else {
+		nodes = make([]*IndexNode, 0, lookup.Len())
+		for _, node := range lookup.AllNodes() {
+			nodes = append(nodes, node)
+		}
+		sort.Slice(nodes, func(i, j int) bool {
+			if nodes[i].File != nodes[j].File {
+				return nodes[i].File < nodes[j].File
+			}
+			if nodes[i].Line != nodes[j].Line {
+				return nodes[i].Line < nodes[j].Line
+			}
+			return nodes[i].ID < nodes[j].ID
+		})
+	}
+
+	records := make([]SymbolRecord, 0)
+	for _, node := range nodes {
+		if filter.Match(node) {
+			records = append(records, SymbolRecordFromNode(node))
+		}
+	}
+	total := len(records)
+	if limit > 0 && len(records) > limit {
+		records = records[:limit]
+	}
+	return map[string]any{"query": query, "total": total, "symbols": records}, nil
+}
+
+func (s *Server) symbol(r *http.Request) (any, error) {
+	lookup, err := s.loadLookup()
+	if err != nil {
+		return nil, err
+	}
+	node, err := resolveForAPI(lookup, r.PathValue("id"))
+	if err != nil {
+		return nil, err
+	}
+	payload := map[string]any{
+		"symbol":  SymbolRecordFromNode(node),
+		"callers": len(node.InEdges),
+		"callees": len(node.OutEdges),
+	}
+	if node.Doc != "" {
+		payload["doc"] = node.Doc
+	}
+	if node.Rank > 0 {
+		payload["rank"] = node.Rank
+	}
+	records, err := s.loadEnrich()
+	if err != nil {
+		return nil, err
+	}
+	if matched := enrichRecordsFor(records, node.ID); len(matched) > 0 {
+		payload["enrich"] = matched[0].Output
+	}
+	return payload, nil
+}
+
+// callers lists incoming edges; &kind= picks edge kinds (default: all but
+// reference, as `callers` does).
+func (s *Server) callers(r *http.Request) (any, error) {
+	kinds, err := ParseEdgeKinds(listParam(r, "kind"), "kind")
+	if err != nil {
+		return nil, &apiError{status: http.StatusBadRequest, message: err.Error()}
+	}
+	lookup, node, err := s.resolveEdges(r, callerEdgeKinds(kinds, false))
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{"symbol": SymbolRecordFromNode(node), "callers": CollectCallers(lookup, node)}, nil
+}
+
+func (s *Server) callees(r *http.Request) (any, error) {
+	kinds, err := ParseEdgeKinds(listParam(r, "kind"), "kind")
+	if err != nil {
+		return nil, &apiError{status: http.StatusBadRequest, message: err.Error()}
+	}
+	lookup, node, err := s.resolveEdges(r, kinds)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{"symbol": SymbolRecordFromNode(node), "callees": CollectCallees(lookup, node)}, nil
+}
+
+// trace walks &direction= (out, in or both; default out) up to &depth= hops
+// (default 2) over &kind= edges.
+func (s *Server) trace(r *http.Request) (any, error) {
+	depth, err := intParam(r, "depth", 2)
+	if err != nil {
+		return nil, err
+	}
+	if depth < 1 {
+		return nil, &apiError{status: http.StatusBadRequest, message: "depth must be >= 1"}
+	}
+	direction := r.URL.Query().Get("direction")
+	if direction == "" {
+		direction = TraceOut
+	}
+	if direction != TraceOut && direction != TraceIn && direction != TraceBoth {
+		return nil, &apiError{status: http.StatusBadRequest, message: "direction must be one of: in, out, both"}
+	}
+	kinds, err := ParseEdgeKinds(listParam(r, "kind"), "kind")
+	if err != nil {
+		return nil, &apiError{status: http.StatusBadRequest, message: err.Error()}
+	}
+	lookup, node, err := s.resolveEdges(r, kinds)
+	if err != nil {
+		return nil, err
+	}
+	hops, _ := Trace(lookup, node, depth, direction, nil)
+	return map[string]any{"start": SymbolRecordFromNode(node), "depth": depth, "direction": direction, "hops": hops}, nil
+}
+
+// search ranks symbols for ?q= like `search <query>`, with &kind=, &file=,
+// &visibility=, &include_external= and &limit= (default 50).
+func (s *Server) search(r *http.Request) (any, error) {
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		return nil, &apiError{status: http.StatusBadRequest, message: "search requires ?q=<query>"}
+	}
+	limit, err := intParam(r, "limit", 50)
+	if err != nil {
+		return nil, err
+	}
+	lookup, err := s.loadLookup()
+	if err != nil {
+		return nil, err
+	}
+	index, err := s.loadSearchIndex()
+	if err != nil {
+		return nil, err
+	}
+	matches := HybridSearch(lookup, index, query, searchFilterFromQuery(r))
+	total := len(matches)
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return map[string]any{"query": query, "total": total, "matches": matches}, nil
+}
+
+// enrichRecords returns a symbol's enrich records, most recently updated
+// first.
+func (s *Server) enrichRecords(r *http.Request) (any, error) {
+	lookup, err := s.loadLookup()
+	if err != nil {
+		return nil, err
+	}
+	node, err := resolveForAPI(lookup, r.PathValue("id"))
+	if err != nil {
+		return nil, err
+	}
+	records, err := s.loadEnrich()
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{"symbol": SymbolRecordFromNode(node), "records": enrichRecordsFor(records, node.ID)}, nil
+}
+
+func (s *Server) resolveEdges(r *http.Request, kinds map[string]bool) (*Lookup, *IndexNode, error) {
+	lookup, err := s.loadLookup()
+	if err != nil {
+		return nil, nil, err
+	}
+	lookup = lookup.FilterEdgeKinds(kinds)
+	node, err := resolveForAPI(lookup, r.PathValue("id"))
+	if err != nil {
+		return nil, nil, err
+	}
+	return lookup, node, nil
+}
+
+func (s *Server) loadLookup() (*Lookup, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	path := filepath.Join(s.rootPath, output.ContextDir, NavigationIndexFile)
+	return loadCached(&s.lookup, path, func() (*Lookup, error) { return readLookup(s.rootPath) })
+}
+
+func (s *Server) loadSearchIndex() (*search.Index, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	path := filepath.Join(s.rootPath, output.ContextDir, search.IndexFile)
+	return loadCached(&s.index, path, func() (*search.Index, error) { return search.Load(s.rootPath) })
+}
+
+func (s *Server) loadEnrich() (map[string]enrich.Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	path := filepath.Join(s.rootPath, output.ContextDir, enrich.OutputFile)
+	return loadCached(&s.records, path, func() (map[string]enrich.Record, error) { return enrich.LoadCache(path) })
+}
+
+// resolveForAPI resolves a symbol argument, reporting unknown symbols as 404
+// and ambiguous ones as 409 with their candidate IDs.
+func resolveForAPI(lookup *Lookup, query string) (*IndexNode, error) {
+	matches := Resolve(lookup, query)
+	switch len(matches) {
+	case 0:
+		return nil, &apiError{status: http.StatusNotFound, message: fmt.Sprintf("symbol %q not found", query)}
+	case 1:
+		return matches[0], nil
+	}
+	candidates := make([]string, 0, len(matches))
+	for _, match := range matches {
+		candidates = append(candidates, match.ID)
+	}
+	sort.Strings(candidates)
+	return nil, &apiError{status: http.StatusConflict, message: fmt.Sprintf("symbol %q is ambiguous", query), candidates: candidates}
+}
+
+func enrichRecordsFor(records map[string]enrich.Record, symbolID string) []enrich.Record {
+	matched := make([]enrich.Record, 0)
+	for _, record := range records {
+		if record.SymbolID == symbolID && record.Status != "error" {
+			matched = append(matched, record)
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		if matched[i].UpdatedAt != matched[j].UpdatedAt {
+			return matched[i].UpdatedAt > matched[j].UpdatedAt
+		}
+		return matched[i].CacheKey < matched[j].CacheKey
+	})
+	return matched
+}
+
+func searchFilterFromQuery(r *http.Request) SearchFilter {
+	filter := SearchFilter{}
+	for _, kind := range listParam(r, "kind") {
+		if filter.Kinds == nil {
+			filter.Kinds = make(map[string]bool)
+		}
+		filter.Kinds[strings.ToLower(kind)] = true
+	}
+	if file := strings.TrimSpace(r.URL.Query().Get("file")); file != "" {
+		filter.File = strings.TrimSuffix(filepath.ToSlash(filepath.Clean(file)), "/")
+	}
+	for _, visibility := range listParam(r, "visibility") {
+		if filter.Visibilities == nil {
+			filter.Visibilities = make(map[string]bool)
+		}
+		filter.Visibilities[strings.ToLower(visibility)] = true
+	}
+	filter.IncludeExternal = r.URL.Query().Get("include_external") == "true"
+	return filter
+}
+
+// listParam reads a repeated or comma-separated query parameter.
+func listParam(r *http.Request, name string) []string {
+	values := make([]string, 0)
+	for _, raw := range r.URL.Query()[name] {
+		for _, value := range strings.Split(raw, ",") {
+			if value = strings.TrimSpace(value); value != "" {
+				values = append(values, value)
+			}
+		}
+	}
+	return values
+}
+
+func intParam(r *http.Request, name string, defaultValue int) (int, error) {
+	raw := strings.TrimSpace(r.URL.Query().Get(name))
+	if raw == "" {
+		return defaultValue, nil
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, &apiError{status: http.StatusBadRequest, message: fmt.Sprintf("%s must be an integer", name)}
+	}
+	return value, nil
+}
+
+// RunServe serves the JSON API on --http until interrupted, with the web
+// explorer under /ui/ when --ui is set.
+func RunServe(cmd *cobra.Command, args []string) error {
+	rootPath, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to resolve working directory: %w", err)
+	}
+	address, err := OptionalStringFlag(cmd, "http")
+	if err != nil {
+		return err
+	}
+	ui, err := OptionalBoolFlag(cmd, "ui", false)
+	if err != nil {
+		return err
+	}
+	if address == "" && ui {
+		address = DefaultHTTPAddress
+	}
+	if address == "" {
+		return fmt.Errorf("serve requires --http [address], e.g. --http %s", DefaultHTTPAddress)
+	}
+	// --http takes an optional value, so "--http :9000" leaves the address
+	// as an argument.
+	if len(args) == 1 {
+		if address != DefaultHTTPAddress {
+			return fmt.Errorf("give the address once: --http=%s or --http %s", address, args[0])
+		}
+		address = args[0]
+	}
+
+	server := NewServer(rootPath)
+	if ui {
+		server.EnableUI()
+	}
+	if _, err := server.loadLookup(); err != nil {
+		return err
+	}
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", address, err)
+	}
+	httpServer := &http.Server{Handler: server.Handler(), ReadHeaderTimeout: 10 * time.Second}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	errs := make(chan error, 1)
+	go func() { errs <- httpServer.Serve(listener) }()
+	fmt.Printf("serving skelly API for %s at http://%s (Ctrl-C to stop)\n", rootPath, listener.Addr())
+	if ui {
+		fmt.Printf("code map: http://%s/ui/\n", listener.Addr())
+	}
+
+	select {
+	case err := <-errs:
+		return fmt.Errorf("http server failed: %w", err)
+	case <-ctx.Done():
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return httpServer.Shutdown(shutdownCtx)
+}
@@ -0,0 +1,332 @@
+package nav
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/morozRed/skelly/internal/fileutil"
+	"github.com/morozRed/skelly/internal/parser"
+)
+
+// NavigationShardDir holds the shards of a sharded navigation index.
+const NavigationShardDir = "nav"
+
+// ShardMinNodes is the node count from which WriteIndex shards the
+// navigation index; smaller graphs keep one nav-index.json.
+var ShardMinNodes = 20000
+
+// shardTargetNodes is the average number of nodes per shard.
+const shardTargetNodes = 2000
+
+// ShardManifest describes a sharded navigation index. Nodes are grouped by
+// the directory of their file, names by the name itself, each hashed into
+// len(Nodes) shards; Nodes and Names hold the content hash of every shard,
+// so the manifest changes whenever a shard does.
+type ShardManifest struct {
+	Count int      `json:"count"`
+	Nodes []string `json:"nodes"`
+	Names []string `json:"names"`
+}
+
+// navShards loads the shards of a sharded index into its Lookup on demand.
+type navShards struct {
+	mu          sync.Mutex
+	dir         string
+	manifest    ShardManifest
+	nodesLoaded []bool
+	namesLoaded []bool
+	warned      bool
+	// kinds, when set, limits the edges of every node returned, as
+	// FilterEdgeKinds does for a monolithic index; filtered records the
+	// nodes already narrowed.
+	kinds    map[string]bool
+	filtered map[string]bool
+}
+
+// writeShardedIndex writes the nodes and name table of index to shard files
+// and index itself, without nodes, as the manifest in nav-index.json.
+func writeShardedIndex(contextDir string, index Index) error {
+	count := (len(index.Nodes) + shardTargetNodes - 1) / shardTargetNodes
+	nodeShards := make([][]IndexNode, count)
+	nameShards := make([]map[string][]string, count)
+	for i := range nameShards {
+		nameShards[i] = make(map[string][]string)
+	}
+	for _, node := range index.Nodes {
+		shard := nodeShard(node.ID, count)
+		nodeShards[shard] = append(nodeShards[shard], node)
+		symbol := parser.Symbol{Name: node.Name, Container: node.Container}
+		for _, name := range append([]string{node.Name}, symbol.QualifiedNames()...) {
+			names := nameShards[nameShard(name, count)]
+			names[name] = append(names[name], node.ID)
+		}
+	}
+
+	shardDir := filepath.Join(contextDir, NavigationShardDir)
+	if err := os.MkdirAll(shardDir, 0755); err != nil {
+		return err
+	}
+	manifest := ShardManifest{Count: len(index.Nodes), Nodes: make([]string, count), Names: make([]string, count)}
+	written := make(map[string]bool, 2*count)
+	for i := 0; i < count; i++ {
+		for name, ids := range nameShards[i] {
+			sort.Strings(ids)
+			nameShards[i][name] = slices.Compact(ids)
+		}
+		var err error
+		if manifest.Nodes[i], err = writeShard(shardDir, nodeShardFile(i), nodeShards[i], written); err != nil {
+			return err
+		}
+		if manifest.Names[i], err = writeShard(shardDir, nameShardFile(i), nameShards[i], written); err != nil {
+			return err
+		}
+	}
+	if err := removeStaleShards(shardDir, written); err != nil {
+		return err
+	}
+
+	index.Nodes = []IndexNode{}
+	index.Shards = &manifest
+	data, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return err
+	}
+	return fileutil.WriteArtifact(filepath.Join(contextDir, NavigationIndexFile), data)
+}
+
+// writeShard writes one shard and returns the hash of its content.
+func writeShard(shardDir, name string, value any, written map[string]bool) (string, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	written[name] = true
+	if err := fileutil.WriteArtifact(filepath.Join(shardDir, name), data); err != nil {
+		return "", fmt.Errorf("failed to write navigation shard %s: %w", name, err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])[:16], nil
+}
+
+// removeStaleShards removes shard files left from a larger index.
+func removeStaleShards(shardDir string, written map[string]bool) error {
+	entries, err := os.ReadDir(shardDir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if written[fileutil.TrimCompressedSuffix(entry.Name())] {
+			continue
+		}
+		if err := os.Remove(filepath.Join(shardDir, entry.Name())); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeShards removes the shards of a previously sharded index.
+func removeShards(contextDir string) error {
+	return os.RemoveAll(filepath.Join(contextDir, NavigationShardDir))
+}
+
+func nodeShardFile(shard int) string { return fmt.Sprintf("nodes-%04d.json", shard) }
+func nameShardFile(shard int) string { return fmt.Sprintf("names-%04d.json", shard) }
+
+// nodeShard places a node by the directory of its file, which its ID starts
+// with, so the symbols of one package share a shard.
+func nodeShard(id string, count int) int {
+	file, _, _ := strings.Cut(id, "|")
+	return shardOf(path.Dir(file), count)
+}
+
+func nameShard(name string, count int) int {
+	return shardOf(name, count)
+}
+
+func shardOf(key string, count int) int {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return int(h.Sum32() % uint32(count))
+}
+
+// Node returns the node with the given ID, or nil.
+func (l *Lookup) Node(id string) *IndexNode {
+	var node *IndexNode
+	if l.shards == nil {
+		node = l.ByID[id]
+	} else {
+		l.shards.mu.Lock()
+		node = l.shardNode(id)
+		l.shards.mu.Unlock()
+	}
+	if node != nil && node.External && l.hideExternal {
+		return nil
+	}
+	return node
+}
+
+// IDsNamed returns the IDs of the nodes with the given plain or qualified
+// name, sorted.
+func (l *Lookup) IDsNamed(name string) []string {
+	if l.shards == nil {
+		return l.ByName[name]
+	}
+	l.shards.mu.Lock()
+	defer l.shards.mu.Unlock()
+	l.loadNameShard(nameShard(name, len(l.shards.manifest.Names)))
+	return l.ByName[name]
+}
+
+// AllNodes returns every node by ID, loading all shards of a sharded index.
+// Callers must not modify the map.
+func (l *Lookup) AllNodes() map[string]*IndexNode {
+	nodes := l.allNodes()
+	if !l.hideExternal {
+		return nodes
+	}
+	visible := make(map[string]*IndexNode, len(nodes))
+	for id, node := range nodes {
+		if !node.External {
+			visible[id] = node
+		}
+	}
+	return visible
+}
+
+func (l *Lookup) allNodes() map[string]*IndexNode {
+	if l.shards == nil {
+		return l.ByID
+	}
+	l.shards.mu.Lock()
+	defer l.shards.mu.Unlock()
+	for i := range l.shards.manifest.Nodes {
+		l.loadNodeShard(i)
+		l.loadNameShard(i)
+	}
+	if l.shards.kinds != nil {
+		ids := make([]string, 0, len(l.ByID))
+		for id := range l.ByID {
+			ids = append(ids, id)
+		}
+		for _, id := range ids {
+			l.shardNode(id)
+		}
+	}
+	return l.ByID
+}
+
+// shardNode loads the shard of id and, for a filtered lookup, narrows the
+// node's edges on first access. The caller holds the shard lock.
+func (l *Lookup) shardNode(id string) *IndexNode {
+	l.loadNodeShard(nodeShard(id, len(l.shards.manifest.Nodes)))
+	node := l.ByID[id]
+	if node == nil || l.shards.kinds == nil || l.shards.filtered[id] {
+		return node
+	}
+	l.shards.filtered[id] = true
+	copied := *node
+	copied.OutEdges = nil
+	for _, targetID := range node.OutEdges {
+		if l.shards.kinds[edgeKindOf(node, targetID)] {
+			copied.OutEdges = append(copied.OutEdges, targetID)
+		}
+	}
+	copied.InEdges = nil
+	for _, sourceID := range node.InEdges {
+		// Edge kinds are recorded on the source, which narrowing keeps.
+		l.loadNodeShard(nodeShard(sourceID, len(l.shards.manifest.Nodes)))
+		if source := l.ByID[sourceID]; source != nil && l.shards.kinds[edgeKindOf(source, id)] {
+			copied.InEdges = append(copied.InEdges, sourceID)
+		}
+	}
+	l.ByID[id] = &copied
+	return &copied
+}
+
+// filteredShards returns a lookup over the same shards that narrows edges
+// to kinds as nodes load, so filtering does not load the whole index.
+func (l *Lookup) filteredShards(kinds map[string]bool) *Lookup {
+	index := Index{Aliases: l.Aliases, Shards: &l.shards.manifest}
+	filtered := shardedLookup(filepath.Dir(l.shards.dir), index)
+	filtered.shards.kinds = kinds
+	filtered.shards.filtered = make(map[string]bool)
+	return filtered
+}
+
+// Len returns the number of nodes without loading any shard.
+func (l *Lookup) Len() int {
+	if l.shards == nil {
+		return len(l.ByID)
+	}
+	return l.shards.manifest.Count
+}
+
+func (l *Lookup) loadNodeShard(shard int) {
+	if l.shards.nodesLoaded[shard] {
+		return
+	}
+	var nodes []IndexNode
+	if l.readShard(nodeShardFile(shard), &nodes) {
+		for i := range nodes {
+			l.ByID[nodes[i].ID] = &nodes[i]
+		}
+	}
+	l.shards.nodesLoaded[shard] = true
+}
+
+func (l *Lookup) loadNameShard(shard int) {
+	if l.shards.namesLoaded[shard] {
+		return
+	}
+	var names map[string][]string
+	if l.readShard(nameShardFile(shard), &names) {
+		for name, ids := range names {
+			l.ByName[name] = ids
+		}
+	}
+	l.shards.namesLoaded[shard] = true
+}
+
+// readShard decodes one shard. A missing or corrupt shard is reported once
+// and treated as empty, so lookups degrade to "not found".
+func (l *Lookup) readShard(name string, value any) bool {
+	data, err := fileutil.ReadArtifact(filepath.Join(l.shards.dir, name))
+	if err == nil {
+		err = json.Unmarshal(data, value)
+	}
+	if err != nil {
+		if !l.shards.warned {
+			fmt.Fprintf(os.Stderr, "warning: failed to read navigation shard %s: %v (run skelly generate)\n", name, err)
+			l.shards.warned = true
+		}
+		return false
+	}
+	return true
+}
+
+// shardedLookup returns a Lookup that loads the shards of index on demand.
+func shardedLookup(contextDir string, index Index) *Lookup {
+	count := len(index.Shards.Nodes)
+	return &Lookup{
+		ByID:    make(map[string]*IndexNode),
+		ByName:  make(map[string][]string),
+		Aliases: index.Aliases,
+		shards: &navShards{
+			dir:         filepath.Join(contextDir, NavigationShardDir),
+			manifest:    *index.Shards,
+			nodesLoaded: make([]bool, count),
+			namesLoaded: make([]bool, count),
+		},
+	}
+}
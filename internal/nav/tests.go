@@ -0,0 +1,253 @@
+package nav
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/morozRed/skelly/internal/fileutil"
+	"github.com/morozRed/skelly/internal/graph"
+	"github.com/morozRed/skelly/internal/output"
+	"github.com/morozRed/skelly/internal/parser"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// TestsFile maps test symbols to the production symbols they exercise.
+	TestsFile = "tests.jsonl"
+	// testHelperDepth bounds how many helpers in test files are followed
+	// from a test to the production code it reaches.
+	testHelperDepth = 3
+)
+
+// TestRecord is one test symbol in tests.jsonl.
+type TestRecord struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Language string `json:"language,omitempty"`
+	// Targets are the production symbols the test calls or renders,
+	// directly or through helpers defined in test files.
+	Targets []string `json:"targets"`
+}
+
+// TestMatch is a test that exercises a symbol, directly (Depth 1) or by
+// calling Via, a production caller of the symbol.
+type TestMatch struct {
+	Test  TestRecord `json:"test"`
+	Via   string     `json:"via,omitempty"`
+	Depth int        `json:"depth"`
+}
+
+// BuildTests collects the test symbols of files that are tests by their
+// language's convention, sorted by file and line.
+func BuildTests(g *graph.Graph) []TestRecord {
+	tests := make([]TestRecord, 0)
+	for _, file := range g.Files() {
+		for _, node := range g.NodesForFile(file) {
+			if !parser.IsTestFile(node.Language, node.File) || !parser.IsTestSymbol(node.Language, node.Symbol) {
+				continue
+			}
+			tests = append(tests, TestRecord{
+				ID:       node.ID,
+				Name:     node.Symbol.QualifiedName(),
+				File:     node.File,
+				Line:     node.Symbol.Line,
+				Language: node.Language,
+				Targets:  testTargets(g, node),
+			})
+		}
+	}
+	return tests
+}
+
+// testTargets follows call and render edges from a test through helpers in
+// test files and returns the production symbols it reaches.
+func testTargets(g *graph.Graph, test *graph.Node) []string {
+	targets := make([]string, 0)
+	seen := map[string]bool{test.ID: true}
+	frontier := []*graph.Node{test}
+	for depth := 0; depth <= testHelperDepth && len(frontier) > 0; depth++ {
+		var next []*graph.Node
+		for _, node := range frontier {
+			for _, targetID := range node.OutEdges {
+				if kind := node.EdgeKindTo(targetID); kind != graph.EdgeCall && kind != graph.EdgeRender {
+					continue
+				}
+				target, ok := g.Nodes[targetID]
+				if !ok || seen[targetID] {
+					continue
+				}
+				seen[targetID] = true
+				if parser.IsTestFile(target.Language, target.File) {
+					next = append(next, target)
+					continue
+				}
+				targets = append(targets, targetID)
+			}
+		}
+		frontier = next
+	}
+	sort.Strings(targets)
+	return targets
+}
+
+// WriteTests writes tests.jsonl.
+func WriteTests(contextDir string, g *graph.Graph) error {
+	data, err := fileutil.EncodeJSONL(BuildTests(g))
+	if err != nil {
+		return fmt.Errorf("failed to encode tests: %w", err)
+	}
+	return fileutil.WriteIfChanged(filepath.Join(contextDir, TestsFile), data)
+}
+
+// LoadTests reads tests.jsonl from a repository's context directory.
+func LoadTests(rootPath string) ([]TestRecord, error) {
+	path := filepath.Join(rootPath, output.ContextDir, TestsFile)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("tests index missing at %s (run skelly update)", path)
+		}
+		return nil, fmt.Errorf("failed to read tests index: %w", err)
+	}
+
+	tests := make([]TestRecord, 0)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var test TestRecord
+		if err := json.Unmarshal(line, &test); err != nil {
+			return nil, fmt.Errorf("failed to decode tests index: %w", err)
+		}
+		tests = append(tests, test)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read tests index: %w", err)
+	}
+	return tests, nil
+}
+
+// TestsFor returns the tests that target node, or one of its production
+// callers up to depth-1 call hops away, nearest first.
+func TestsFor(lookup *Lookup, tests []TestRecord, node *IndexNode, depth int) []TestMatch {
+	byTarget := make(map[string][]int)
+	for i, test := range tests {
+		for _, target := range test.Targets {
+			byTarget[target] = append(byTarget[target], i)
+		}
+	}
+
+	matched := make(map[int]bool)
+	matches := make([]TestMatch, 0)
+	seen := map[string]bool{node.ID: true}
+	frontier := []string{node.ID}
+	for hop := 1; hop <= depth && len(frontier) > 0; hop++ {
+		var next []string
+		for _, id := range frontier {
+			for _, i := range byTarget[id] {
+				if matched[i] {
+					continue
+				}
+				matched[i] = true
+				match := TestMatch{Test: tests[i], Depth: hop}
+				if id != node.ID {
+					match.Via = id
+				}
+				matches = append(matches, match)
+			}
+			current := lookup.Node(id)
+			if current == nil {
+				continue
+			}
+			for _, callerID := range current.InEdges {
+				caller := lookup.Node(callerID)
+				if caller == nil || seen[callerID] || parser.IsTestFile(caller.Language, caller.File) {
+					continue
+				}
+				if kind := lookup.EdgeKindValue(callerID, id); kind != string(graph.EdgeCall) && kind != string(graph.EdgeRender) {
+					continue
+				}
+				seen[callerID] = true
+				next = append(next, callerID)
+			}
+		}
+		sort.Strings(next)
+		frontier = next
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Depth != matches[j].Depth {
+			return matches[i].Depth < matches[j].Depth
+		}
+		if matches[i].Test.File != matches[j].Test.File {
+			return matches[i].Test.File < matches[j].Test.File
+		}
+		return matches[i].Test.Line < matches[j].Test.Line
+	})
+	return matches
+}
+
+func RunTestsFor(cmd *cobra.Command, args []string) error {
+	rootPath, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to resolve working directory: %w", err)
+	}
+	asJSON, err := OptionalBoolFlag(cmd, "json", false)
+	if err != nil {
+		return err
+	}
+	depth, err := OptionalIntFlag(cmd, "depth", 2)
+	if err != nil {
+		return err
+	}
+	if depth < 1 {
+		return fmt.Errorf("--depth must be >= 1")
+	}
+
+	lookup, err := LoadLookup(rootPath)
+	if err != nil {
+		return err
+	}
+	node, err := ResolveSingleSymbol(lookup, args[0])
+	if err != nil {
+		return err
+	}
+	tests, err := LoadTests(rootPath)
+	if err != nil {
+		return err
+	}
+	matches := TestsFor(lookup, tests, node, depth)
+
+	if asJSON {
+		return fileutil.PrintJSON(map[string]any{
+			"query":  args[0],
+			"symbol": SymbolRecordFromNode(node),
+			"depth":  depth,
+			"tests":  matches,
+		})
+	}
+
+	fmt.Printf("tests for %s (%d)\n", node.ID, len(matches))
+	if len(matches) == 0 {
+		fmt.Println("no tests found")
+		return nil
+	}
+	for _, match := range matches {
+		fmt.Printf("- %s %s:%d", match.Test.ID, match.Test.File, match.Test.Line)
+		if match.Via != "" {
+			fmt.Printf(" (via %s, depth %d)", match.Via, match.Depth)
+		}
+		fmt.Println()
+	}
+	return nil
+}
@@ -1,17 +1,30 @@
 package nav
 
 type Index struct {
-	Version string      `json:"version"`
-	Nodes   []IndexNode `json:"nodes"`
+	Version string            `json:"version"`
+	Nodes   []IndexNode       `json:"nodes"`
+	Aliases map[string]string `json:"aliases,omitempty"`
+	// Shards is set when the nodes live in shard files under nav/ instead.
+	Shards *ShardManifest `json:"shards,omitempty"`
 }
 
 type IndexNode struct {
 	ID            string           `json:"id"`
 	Name          string           `json:"name"`
+	Container     string           `json:"container,omitempty"`
 	Kind          string           `json:"kind"`
 	Signature     string           `json:"signature,omitempty"`
+	Shape         string           `json:"shape,omitempty"` // type-only Go signature
 	File          string           `json:"file"`
+	Language      string           `json:"language,omitempty"`
 	Line          int              `json:"line"`
+	EndLine       int              `json:"end_line,omitempty"` // last line of the declaration
+	Visibility    string           `json:"visibility,omitempty"`
+	External      bool             `json:"external,omitempty"` // vendored or generated code
+	Doc           string           `json:"doc,omitempty"`
+	Summary       string           `json:"summary,omitempty"`     // enrich summary
+	Rank          float64          `json:"rank,omitempty"`        // PageRank, rounded
+	Betweenness   float64          `json:"betweenness,omitempty"` // approximate betweenness centrality, rounded
 	OutEdges      []string         `json:"out_edges,omitempty"`
 	InEdges       []string         `json:"in_edges,omitempty"`
 	OutConfidence []EdgeConfidence `json:"out_confidence,omitempty"`
@@ -20,11 +33,19 @@ type IndexNode struct {
 type EdgeConfidence struct {
 	TargetID   string `json:"target_id"`
 	Confidence string `json:"confidence,omitempty"`
+	Kind       string `json:"kind,omitempty"`
 }
 
+// Lookup resolves symbols of the navigation index. ByID and ByName are
+// complete for a monolithic index; for a sharded one they fill as shards
+// load, so read them through Node, IDsNamed and AllNodes.
 type Lookup struct {
-	ByID   map[string]*IndexNode
-	ByName map[string][]string
+	ByID    map[string]*IndexNode
+	ByName  map[string][]string
+	Aliases map[string]string
+	shards  *navShards
+	// hideExternal makes external nodes look absent, see WithoutExternal.
+	hideExternal bool
 }
 
 type ResolveOptions struct {
@@ -35,29 +56,95 @@ type ResolveOptions struct {
 type SymbolRecord struct {
 	ID        string `json:"id"`
 	Name      string `json:"name"`
+	Container string `json:"container,omitempty"`
 	Kind      string `json:"kind"`
 	Signature string `json:"signature,omitempty"`
 	File      string `json:"file"`
+	Language  string `json:"language,omitempty"`
 	Line      int    `json:"line"`
+	EndLine   int    `json:"end_line,omitempty"`
+	// Visibility is public, protected or private when the parser tells.
+	Visibility string `json:"visibility,omitempty"`
+	External   bool   `json:"external,omitempty"`
+	// Summary is set by --with-summary.
+	Summary string `json:"summary,omitempty"`
 }
 
 type EdgeRecord struct {
 	Symbol     SymbolRecord `json:"symbol"`
 	Confidence string       `json:"confidence,omitempty"`
+	Kind       string       `json:"kind"`
 	Source     string       `json:"source,omitempty"`
 }
 
 type TraceHop struct {
-	Depth      int          `json:"depth"`
+	Depth int `json:"depth"`
+	// Direction is TraceOut for callees of the start symbol and TraceIn for
+	// its callers; From -> To always follows the call edge.
+	Direction  string       `json:"direction"`
 	From       SymbolRecord `json:"from"`
 	To         SymbolRecord `json:"to"`
 	Confidence string       `json:"confidence,omitempty"`
+	Kind       string       `json:"kind"`
 	Source     string       `json:"source,omitempty"`
 }
 
+// Edge kinds accepted by `--kind`; they mirror graph.EdgeKind.
+var EdgeKinds = []string{"call", "inherit", "implement", "reference", "render", "generated-from"}
+
+// Trace directions accepted by `trace --direction`.
+const (
+	TraceOut  = "out"
+	TraceIn   = "in"
+	TraceBoth = "both"
+)
+
+// Defaults for `path --all`.
+const (
+	DefaultPathMaxDepth = 6
+	DefaultPathLimit    = 10
+)
+
+// PathResult is one call path with the edges between consecutive symbols.
+type PathResult struct {
+	Length int            `json:"length"`
+	Path   []SymbolRecord `json:"path"`
+	Edges  []PathEdge     `json:"edges"`
+}
+
+// PathEdge is one hop of a call path.
+type PathEdge struct {
+	FromID     string `json:"from_id"`
+	ToID       string `json:"to_id"`
+	Confidence string `json:"confidence"`
+	Kind       string `json:"kind"`
+	Source     string `json:"source"`
+}
+
+// BoundaryCut records an edge that was not followed because its target
+// falls outside the active language filter.
+type BoundaryCut struct {
+	Depth      int          `json:"depth"`
+	From       SymbolRecord `json:"from"`
+	To         SymbolRecord `json:"to"`
+	Confidence string       `json:"confidence,omitempty"`
+}
+
 type LSPStatus struct {
 	Language  string `json:"language,omitempty"`
 	Server    string `json:"server,omitempty"`
 	Available bool   `json:"available"`
 	Reason    string `json:"reason,omitempty"`
 }
+
+// RelatedFile is one ranked entry from `skelly related`.
+type RelatedFile struct {
+	File       string  `json:"file"`
+	Score      float64 `json:"score"`
+	Calls      bool    `json:"calls,omitempty"`
+	CalledBy   bool    `json:"called_by,omitempty"`
+	SharedDeps int     `json:"shared_dependencies,omitempty"`
+	CoCallers  int     `json:"co_callers,omitempty"`
+	Proximity  float64 `json:"proximity,omitempty"`
+	CoChanges  int     `json:"co_changes,omitempty"`
+}
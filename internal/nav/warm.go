@@ -0,0 +1,89 @@
+package nav
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/morozRed/skelly/internal/fileutil"
+	"github.com/morozRed/skelly/internal/output"
+	"github.com/morozRed/skelly/internal/search"
+)
+
+// warmIndexes keeps decoded indexes in memory across commands in one
+// process. It is nil unless EnableWarmIndexes was called, so one-shot CLI
+// runs read the files as before.
+var warmIndexes *warmCache
+
+type warmCache struct {
+	mu      sync.Mutex
+	lookups map[string]*cachedArtifact[*Lookup]
+	indexes map[string]*cachedArtifact[*search.Index]
+}
+
+// cachedArtifact holds a loaded context file with the modification time and
+// size it was loaded at.
+type cachedArtifact[T any] struct {
+	loaded  bool
+	modTime time.Time
+	size    int64
+	value   T
+}
+
+// EnableWarmIndexes makes LoadLookup and LoadSearchIndex keep what they read
+// and return it until `update` rewrites the file. Callers must treat the
+// returned values as read-only, since later calls share them.
+func EnableWarmIndexes() {
+	warmIndexes = &warmCache{
+		lookups: make(map[string]*cachedArtifact[*Lookup]),
+		indexes: make(map[string]*cachedArtifact[*search.Index]),
+	}
+}
+
+// LoadSearchIndex reads the search index, or returns the in-memory copy when
+// warm indexes are enabled and the file is unchanged.
+func LoadSearchIndex(rootPath string) (*search.Index, error) {
+	if warm := warmIndexes; warm != nil {
+		return warm.loadSearchIndex(rootPath)
+	}
+	return search.Load(rootPath)
+}
+
+func (w *warmCache) loadLookup(rootPath string) (*Lookup, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	path := filepath.Join(rootPath, output.ContextDir, NavigationIndexFile)
+	if w.lookups[path] == nil {
+		w.lookups[path] = &cachedArtifact[*Lookup]{}
+	}
+	return loadCached(w.lookups[path], path, func() (*Lookup, error) { return readLookup(rootPath) })
+}
+
+func (w *warmCache) loadSearchIndex(rootPath string) (*search.Index, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	path := filepath.Join(rootPath, output.ContextDir, search.IndexFile)
+	if w.indexes[path] == nil {
+		w.indexes[path] = &cachedArtifact[*search.Index]{}
+	}
+	return loadCached(w.indexes[path], path, func() (*search.Index, error) { return search.Load(rootPath) })
+}
+
+// loadCached returns the cached value unless the file at path, or its
+// compressed variant, changed since it was loaded. Missing files are not
+// cached.
+func loadCached[T any](cached *cachedArtifact[T], path string, load func() (T, error)) (T, error) {
+	info, statErr := os.Stat(fileutil.ArtifactPath(path))
+	if statErr == nil && cached.loaded && info.ModTime().Equal(cached.modTime) && info.Size() == cached.size {
+		return cached.value, nil
+	}
+	value, err := load()
+	if err != nil {
+		return value, err
+	}
+	if statErr == nil {
+		*cached = cachedArtifact[T]{loaded: true, modTime: info.ModTime(), size: info.Size(), value: value}
+	}
+	return value, nil
+}
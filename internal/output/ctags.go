@@ -0,0 +1,108 @@
+package output
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/morozRed/skelly/internal/fileutil"
+	"github.com/morozRed/skelly/internal/graph"
+	"github.com/morozRed/skelly/internal/parser"
+)
+
+// TagsFile is the ctags-compatible symbol list written with ctags output.
+const TagsFile = "tags"
+
+// ctagsKinds maps symbol kinds to the single-letter kinds of Universal Ctags.
+var ctagsKinds = map[string]string{
+	"func":      "f",
+	"method":    "m",
+	"class":     "c",
+	"struct":    "s",
+	"interface": "i",
+	"module":    "n",
+	"const":     "C",
+	"var":       "v",
+	"component": "f",
+	"route":     "r",
+}
+
+// ctagsFilePrefix makes tag file paths relative to the tags file itself, which
+// is how vim resolves them with the default 'tagrelative'.
+func ctagsFilePrefix() string {
+	return strings.Repeat("../", strings.Count(ContextDir, "/")+1)
+}
+
+// WriteCtags writes the symbol records as an extended-format tags file sorted
+// by tag name, so `:set tags+=.skelly/.context/tags` works in vim and other
+// ctags consumers. Addresses are line numbers, which keeps the file free of
+// source text and stable across formatting-only edits on other lines.
+func (w *Writer) WriteCtags(g *graph.Graph, parseResult *parser.ParseResult) error {
+	fileLanguage := make(map[string]string, len(parseResult.Files))
+	for _, file := range parseResult.Files {
+		fileLanguage[file.Path] = file.Language
+	}
+
+	records := make([]symbolRecord, 0, len(g.Nodes))
+	for _, file := range g.Files() {
+		for _, node := range g.NodesForFile(file) {
+			records = append(records, symbolRecord{
+				ID:        node.ID,
+				Name:      node.Symbol.Name,
+				Kind:      node.Symbol.Kind.String(),
+				Signature: node.Symbol.Signature,
+				File:      node.File,
+				Language:  fileLanguage[node.File],
+				Line:      node.Symbol.Line,
+			})
+		}
+	}
+	// Byte order by name, as required by !_TAG_FILE_SORTED=1 for binary search.
+	sort.Slice(records, func(i, j int) bool {
+		if records[i].Name != records[j].Name {
+			return records[i].Name < records[j].Name
+		}
+		if records[i].File != records[j].File {
+			return records[i].File < records[j].File
+		}
+		if records[i].Line != records[j].Line {
+			return records[i].Line < records[j].Line
+		}
+		return records[i].ID < records[j].ID
+	})
+
+	var sb strings.Builder
+	sb.WriteString("!_TAG_FILE_FORMAT\t2\t/extended format; --format=1 will not append ;\" to lines/\n")
+	sb.WriteString("!_TAG_FILE_SORTED\t1\t/0=unsorted, 1=sorted, 2=foldcase/\n")
+	sb.WriteString("!_TAG_PROGRAM_NAME\tskelly\t//\n")
+	sb.WriteString("!_TAG_PROGRAM_URL\thttps://github.com/morozRed/skelly\t//\n")
+	for _, record := range records {
+		if record.Name == "" || strings.ContainsAny(record.Name, "\t\n") {
+			continue
+		}
+		fmt.Fprintf(&sb, "%s\t%s\t%d;\"", record.Name, ctagsFilePrefix()+path.Clean(record.File), record.Line)
+		if kind, ok := ctagsKinds[record.Kind]; ok {
+			sb.WriteString("\t" + kind)
+		}
+		fmt.Fprintf(&sb, "\tline:%d", record.Line)
+		if record.Language != "" {
+			sb.WriteString("\tlanguage:" + record.Language)
+		}
+		if signature := ctagsFieldValue(record.Signature); signature != "" {
+			sb.WriteString("\tsignature:" + signature)
+		}
+		sb.WriteString("\n")
+	}
+
+	return fileutil.WriteIfChanged(filepath.Join(w.contextDir, TagsFile), []byte(sb.String()))
+}
+
+// ctagsFieldValue escapes an extension field value per the tags file format.
+func ctagsFieldValue(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, "\t", `\t`)
+	value = strings.ReplaceAll(value, "\r", `\r`)
+	return strings.ReplaceAll(value, "\n", `\n`)
+}
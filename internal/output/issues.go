@@ -0,0 +1,59 @@
+package output
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/morozRed/skelly/internal/fileutil"
+	"github.com/morozRed/skelly/internal/parser"
+)
+
+// IssuesFile records the parse warnings and errors of the last index run.
+const IssuesFile = "issues.jsonl"
+
+// WriteIssues writes issues.jsonl, one issue per line sorted by file.
+func WriteIssues(contextDir string, issues []parser.ParseIssue) error {
+	if issues == nil {
+		issues = []parser.ParseIssue{}
+	}
+	data, err := fileutil.EncodeJSONL(issues)
+	if err != nil {
+		return fmt.Errorf("failed to encode parse issues: %w", err)
+	}
+	return fileutil.WriteIfChanged(filepath.Join(contextDir, IssuesFile), data)
+}
+
+// LoadIssues reads issues.jsonl from a repository's context directory.
+func LoadIssues(rootPath string) ([]parser.ParseIssue, error) {
+	path := filepath.Join(rootPath, ContextDir, IssuesFile)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("parse issues missing at %s (run skelly generate)", path)
+		}
+		return nil, fmt.Errorf("failed to read parse issues: %w", err)
+	}
+
+	issues := make([]parser.ParseIssue, 0)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var issue parser.ParseIssue
+		if err := json.Unmarshal(line, &issue); err != nil {
+			return nil, fmt.Errorf("failed to decode parse issues: %w", err)
+		}
+		issues = append(issues, issue)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read parse issues: %w", err)
+	}
+	return issues, nil
+}
@@ -0,0 +1,135 @@
+package output
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/morozRed/skelly/internal/fileutil"
+)
+
+// jsonlStream encodes records straight to a temporary file next to the
+// artifact, hashing as it goes, so WriteJSONL never holds a namespace's
+// records or its encoded bytes in memory. Commit keeps WriteIfChanged
+// semantics: an artifact whose content did not change is left untouched.
+type jsonlStream struct {
+	path    string
+	tmp     *os.File
+	buffer  *bufio.Writer
+	hasher  hash.Hash
+	encoder *json.Encoder
+	count   int
+}
+
+func newJSONLStream(path string) (*jsonlStream, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return nil, err
+	}
+	stream := &jsonlStream{
+		path:   path,
+		tmp:    tmp,
+		buffer: bufio.NewWriterSize(tmp, 64*1024),
+		hasher: sha256.New(),
+	}
+	stream.encoder = json.NewEncoder(io.MultiWriter(stream.buffer, stream.hasher))
+	stream.encoder.SetEscapeHTML(false)
+	return stream, nil
+}
+
+// Encode appends one record; output matches fileutil.EncodeJSONL byte for byte.
+func (s *jsonlStream) Encode(record any) error {
+	s.count++
+	return s.encoder.Encode(record)
+}
+
+// Commit moves the temporary file over the artifact unless the artifact
+// already has identical content, and returns the short content hash. When
+// fileutil.ArtifactCompression says so the artifact is stored compressed
+// next to it instead; the hash is always of the uncompressed content.
+func (s *jsonlStream) Commit() (string, error) {
+	if err := s.buffer.Flush(); err != nil {
+		s.Abort()
+		return "", err
+	}
+	if err := s.tmp.Close(); err != nil {
+		os.Remove(s.tmp.Name())
+		return "", err
+	}
+	defer os.Remove(s.tmp.Name())
+	sum := s.hasher.Sum(nil)
+
+	compression := fileutil.ArtifactCompression(s.path)
+	target := s.path + compression.Suffix()
+	unchanged, err := fileHasSum(target, sum)
+	if err != nil {
+		return "", err
+	}
+	switch {
+	case unchanged:
+	case compression != fileutil.CompressionNone:
+		if err := fileutil.CompressFile(s.tmp.Name(), target, compression); err != nil {
+			return "", err
+		}
+	default:
+		if err := os.Chmod(s.tmp.Name(), 0644); err != nil {
+			return "", err
+		}
+		if err := os.Rename(s.tmp.Name(), s.path); err != nil {
+			return "", err
+		}
+	}
+	for _, variant := range fileutil.ArtifactVariants(s.path) {
+		if variant == target {
+			continue
+		}
+		if err := os.Remove(variant); err != nil && !os.IsNotExist(err) {
+			return "", err
+		}
+	}
+	return hex.EncodeToString(sum)[:16], nil
+}
+
+// Abort discards the temporary file.
+func (s *jsonlStream) Abort() {
+	s.tmp.Close()
+	os.Remove(s.tmp.Name())
+}
+
+// fileHasSum reports whether the artifact stored at path, decompressed when
+// it is a compressed variant, has the given sha256 sum.
+func fileHasSum(path string, sum []byte) (bool, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	defer file.Close()
+
+	compressed := fileutil.TrimCompressedSuffix(path) != path
+	reader, err := fileutil.NewArtifactReader(file, path)
+	if err != nil {
+		// A corrupt compressed artifact is rewritten.
+		return false, nil
+	}
+	defer reader.Close()
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, reader); err != nil {
+		if compressed {
+			return false, nil
+		}
+		return false, err
+	}
+	return bytes.Equal(hasher.Sum(nil), sum), nil
+}
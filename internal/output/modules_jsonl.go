@@ -0,0 +1,220 @@
+package output
+
+import (
+	"math"
+	"path"
+	"path/filepath"
+	"sort"
+
+	"github.com/morozRed/skelly/internal/graph"
+	"github.com/morozRed/skelly/internal/parser"
+)
+
+// ModulesFile is the directory-level dependency graph written with JSONL output.
+const ModulesFile = "modules.jsonl"
+
+// Record types in modules.jsonl. Module records come first, sorted by ID,
+// followed by dependency records sorted by source and target.
+const (
+	ModuleRecordType     = "module"
+	DependencyRecordType = "dependency"
+)
+
+// moduleTopSymbols is how many of a module's highest-ranked symbols its
+// record lists.
+const moduleTopSymbols = 5
+
+// moduleRecord aggregates the files of one directory (a package in Go), so
+// an agent can orient in one directory without loading every symbol.
+type moduleRecord struct {
+	Type      string   `json:"type"`
+	ID        string   `json:"id"`
+	Files     int      `json:"files"`
+	Symbols   int      `json:"symbols"`
+	LOC       int      `json:"loc"`
+	Languages []string `json:"languages"`
+	// Imports are the distinct imports of the module's files, as written.
+	Imports    []string       `json:"imports,omitempty"`
+	TopSymbols []moduleSymbol `json:"top_symbols,omitempty"`
+	// FanIn and FanOut count distinct modules; CallsIn and CallsOut count
+	// the symbol-level edges crossing the module boundary.
+	FanIn    int     `json:"fan_in"`
+	FanOut   int     `json:"fan_out"`
+	CallsIn  int     `json:"calls_in"`
+	CallsOut int     `json:"calls_out"`
+	Rank     float64 `json:"rank"`
+}
+
+// moduleSymbol is one of a module's highest-ranked symbols.
+type moduleSymbol struct {
+	ID   string  `json:"id"`
+	Name string  `json:"name"`
+	Kind string  `json:"kind"`
+	Rank float64 `json:"rank"`
+}
+
+// moduleDependencyRecord is a weighted module-to-module edge.
+type moduleDependencyRecord struct {
+	Type       string         `json:"type"`
+	Source     string         `json:"source"`
+	Target     string         `json:"target"`
+	Weight     int            `json:"weight"`
+	Confidence map[string]int `json:"confidence"`
+}
+
+// moduleOf returns the module (directory) a file belongs to; "." is the root.
+func moduleOf(file string) string {
+	return path.Dir(file)
+}
+
+// buildModuleGraph aggregates symbol edges into module records and weighted
+// dependencies between distinct modules.
+func buildModuleGraph(g *graph.Graph, parseResult *parser.ParseResult) ([]moduleRecord, []moduleDependencyRecord) {
+	fileData := make(map[string]parser.FileSymbols, len(parseResult.Files))
+	for _, file := range parseResult.Files {
+		fileData[file.Path] = file
+	}
+
+	modules := make(map[string]*moduleRecord)
+	languages := make(map[string]map[string]bool)
+	imports := make(map[string]map[string]bool)
+	nodes := make(map[string][]*graph.Node)
+	dependencies := make(map[[2]string]*moduleDependencyRecord)
+	for _, file := range g.Files() {
+		id := moduleOf(file)
+		module, ok := modules[id]
+		if !ok {
+			module = &moduleRecord{Type: ModuleRecordType, ID: id}
+			modules[id] = module
+			languages[id] = make(map[string]bool)
+			imports[id] = make(map[string]bool)
+		}
+		module.Files++
+		module.LOC += fileData[file].Lines
+		if language := fileData[file].Language; language != "" {
+			languages[id][language] = true
+		}
+		for _, imported := range fileData[file].Imports {
+			imports[id][imported] = true
+		}
+
+		for _, node := range g.NodesForFile(file) {
+			nodes[id] = append(nodes[id], node)
+			module.Symbols++
+			module.Rank += node.PageRank
+			for _, targetID := range node.OutEdges {
+				target, ok := g.Nodes[targetID]
+				if !ok {
+					continue
+				}
+				targetModule := moduleOf(target.File)
+				if targetModule == id {
+					continue
+				}
+				key := [2]string{id, targetModule}
+				dependency, ok := dependencies[key]
+				if !ok {
+					dependency = &moduleDependencyRecord{
+						Type:       DependencyRecordType,
+						Source:     id,
+						Target:     targetModule,
+						Confidence: make(map[string]int),
+					}
+					dependencies[key] = dependency
+				}
+				confidence := node.OutEdgeConfidence[targetID]
+				if confidence == "" {
+					confidence = "heuristic"
+				}
+				dependency.Weight++
+				dependency.Confidence[confidence]++
+			}
+		}
+	}
+
+	dependencyRecords := make([]moduleDependencyRecord, 0, len(dependencies))
+	for _, dependency := range dependencies {
+		source, target := modules[dependency.Source], modules[dependency.Target]
+		source.FanOut++
+		source.CallsOut += dependency.Weight
+		if target != nil {
+			target.FanIn++
+			target.CallsIn += dependency.Weight
+		}
+		dependencyRecords = append(dependencyRecords, *dependency)
+	}
+	sort.Slice(dependencyRecords, func(i, j int) bool {
+		if dependencyRecords[i].Source != dependencyRecords[j].Source {
+			return dependencyRecords[i].Source < dependencyRecords[j].Source
+		}
+		return dependencyRecords[i].Target < dependencyRecords[j].Target
+	})
+
+	moduleRecords := make([]moduleRecord, 0, len(modules))
+	for id, module := range modules {
+		module.Languages = make([]string, 0, len(languages[id]))
+		for language := range languages[id] {
+			module.Languages = append(module.Languages, language)
+		}
+		sort.Strings(module.Languages)
+		module.Imports = make([]string, 0, len(imports[id]))
+		for imported := range imports[id] {
+			module.Imports = append(module.Imports, imported)
+		}
+		sort.Strings(module.Imports)
+		module.TopSymbols = topModuleSymbols(nodes[id])
+		// Rounded so float summation noise does not rewrite the artifact.
+		module.Rank = math.Round(module.Rank*1e6) / 1e6
+		moduleRecords = append(moduleRecords, *module)
+	}
+	sort.Slice(moduleRecords, func(i, j int) bool { return moduleRecords[i].ID < moduleRecords[j].ID })
+	return moduleRecords, dependencyRecords
+}
+
+// topModuleSymbols returns a module's highest-ranked symbols, ties broken
+// by ID.
+func topModuleSymbols(nodes []*graph.Node) []moduleSymbol {
+	sort.Slice(nodes, func(i, j int) bool {
+		if nodes[i].PageRank == nodes[j].PageRank {
+			return nodes[i].ID < nodes[j].ID
+		}
+		return nodes[i].PageRank > nodes[j].PageRank
+	})
+	if len(nodes) > moduleTopSymbols {
+		nodes = nodes[:moduleTopSymbols]
+	}
+	top := make([]moduleSymbol, 0, len(nodes))
+	for _, node := range nodes {
+		top = append(top, moduleSymbol{
+			ID:   node.ID,
+			Name: node.Symbol.QualifiedName(),
+			Kind: node.Symbol.Kind.String(),
+			Rank: math.Round(node.PageRank*1e6) / 1e6,
+		})
+	}
+	return top
+}
+
+// writeModulesJSONL writes modules.jsonl and returns its short content hash
+// and the number of modules.
+func (w *Writer) writeModulesJSONL(g *graph.Graph, parseResult *parser.ParseResult) (string, int, error) {
+	modules, dependencies := buildModuleGraph(g, parseResult)
+	stream, err := newJSONLStream(filepath.Join(w.contextDir, ModulesFile))
+	if err != nil {
+		return "", 0, err
+	}
+	for _, module := range modules {
+		if err := stream.Encode(module); err != nil {
+			stream.Abort()
+			return "", 0, err
+		}
+	}
+	for _, dependency := range dependencies {
+		if err := stream.Encode(dependency); err != nil {
+			stream.Abort()
+			return "", 0, err
+		}
+	}
+	hash, err := stream.Commit()
+	return hash, len(modules), err
+}
@@ -0,0 +1,58 @@
+package output
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/morozRed/skelly/internal/parser"
+)
+
+// Namespaces split JSONL artifacts so agents read handwritten code by default
+// while generated and vendored code stays available on demand.
+const (
+	NamespacePrimary   = "primary"
+	NamespaceGenerated = "generated"
+	NamespaceVendor    = "vendor"
+	NamespacesDir      = "namespaces"
+)
+
+var generatedSuffixes = []string{
+	".pb.go",
+	".pb.gw.go",
+	"_pb2.py",
+	"_pb2_grpc.py",
+	"_pb.js",
+	"_pb.d.ts",
+	".gen.go",
+	"_gen.go",
+	"_generated.go",
+	".generated.ts",
+	".generated.js",
+	".min.js",
+}
+
+// NamespaceForFile classifies a repository-relative path by directory and filename conventions.
+func NamespaceForFile(file string) string {
+	normalized := filepath.ToSlash(file)
+	if parser.IsVendored(normalized) {
+		return NamespaceVendor
+	}
+
+	base := strings.ToLower(path.Base(normalized))
+	for _, suffix := range generatedSuffixes {
+		if strings.HasSuffix(base, suffix) {
+			return NamespaceGenerated
+		}
+	}
+	return NamespacePrimary
+}
+
+// NamespaceArtifactPath returns the context-relative path of a JSONL artifact for a namespace.
+// Primary artifacts keep their top-level names for compatibility.
+func NamespaceArtifactPath(namespace, filename string) string {
+	if namespace == NamespacePrimary {
+		return filename
+	}
+	return path.Join(NamespacesDir, namespace, filename)
+}
@@ -1,10 +1,9 @@
 package output
 
 import (
-	"crypto/sha256"
-	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"math"
 	"os"
 	"path/filepath"
 	"sort"
@@ -12,18 +11,21 @@ import (
 
 	"github.com/morozRed/skelly/internal/fileutil"
 	"github.com/morozRed/skelly/internal/graph"
+	"github.com/morozRed/skelly/internal/owners"
 	"github.com/morozRed/skelly/internal/parser"
 )
 
 const (
 	SkellyDir    = ".skelly"
-	ContextDir   = ".skelly/.context"
 	IndexFile    = "index.txt"
 	GraphFile    = "graph.txt"
 	ModulesDir   = "modules"
 	SymbolsFile  = "symbols.jsonl"
 	EdgesFile    = "edges.jsonl"
 	ManifestFile = "manifest.json"
+
+	// JSONLSchemaVersion is the schema version recorded in manifest.json.
+	JSONLSchemaVersion = "jsonl-v3"
 )
 
 type Format string
@@ -31,6 +33,7 @@ type Format string
 const (
 	FormatText  Format = "text"
 	FormatJSONL Format = "jsonl"
+	FormatCtags Format = "ctags"
 )
 
 func ParseFormat(raw string) (Format, error) {
@@ -40,8 +43,50 @@ func ParseFormat(raw string) (Format, error) {
 		return FormatText, nil
 	case FormatJSONL:
 		return FormatJSONL, nil
+	case FormatCtags:
+		return FormatCtags, nil
+	default:
+		return "", fmt.Errorf("unsupported format %q (supported: text, jsonl, ctags)", raw)
+	}
+}
+
+// Order controls how index.txt sections are ordered.
+type Order string
+
+const (
+	OrderImportance Order = "importance"
+	OrderPath       Order = "path"
+)
+
+// DefaultContextDir is where the context is written unless output_dir in
+// .skelly/config.yaml names another directory.
+const DefaultContextDir = ".skelly/.context"
+
+// ContextDir is the context directory relative to the repository root, in
+// slash form. SetContextDir moves it for the rest of the process.
+var ContextDir = DefaultContextDir
+
+// SetContextDir points ContextDir at dir, a clean relative slash path inside
+// the repository; empty restores DefaultContextDir.
+func SetContextDir(dir string) {
+	if dir == "" {
+		dir = DefaultContextDir
+	}
+	ContextDir = dir
+}
+
+// indexModuleKeySymbols caps the per-module symbol list in importance-ordered index.txt.
+const indexModuleKeySymbols = 5
+
+func ParseOrder(raw string) (Order, error) {
+	order := Order(strings.ToLower(strings.TrimSpace(raw)))
+	switch order {
+	case "", OrderImportance:
+		return OrderImportance, nil
+	case OrderPath:
+		return OrderPath, nil
 	default:
-		return "", fmt.Errorf("unsupported format %q (supported: text, jsonl)", raw)
+		return "", fmt.Errorf("unsupported order %q (supported: importance, path)", raw)
 	}
 }
 
@@ -49,6 +94,7 @@ func ParseFormat(raw string) (Format, error) {
 type Writer struct {
 	rootPath   string
 	contextDir string
+	order      Order
 }
 
 // NewWriter creates a new output writer
@@ -56,9 +102,18 @@ func NewWriter(rootPath string) *Writer {
 	return &Writer{
 		rootPath:   rootPath,
 		contextDir: filepath.Join(rootPath, ContextDir),
+		order:      OrderImportance,
 	}
 }
 
+// SetOrder selects index.txt ordering; empty values keep the importance default.
+func (w *Writer) SetOrder(order Order) {
+	if order == "" {
+		order = OrderImportance
+	}
+	w.order = order
+}
+
 // Init creates the output directory structure.
 func (w *Writer) Init() error {
 	dirs := []string{
@@ -95,6 +150,9 @@ func (w *Writer) WriteAll(g *graph.Graph, parseResult *parser.ParseResult, forma
 		if err := w.removeJSONLArtifacts(); err != nil {
 			return err
 		}
+		if err := w.removeCtagsArtifacts(); err != nil {
+			return err
+		}
 	case FormatJSONL:
 		if err := w.WriteJSONL(g, parseResult); err != nil {
 			return err
@@ -102,6 +160,19 @@ func (w *Writer) WriteAll(g *graph.Graph, parseResult *parser.ParseResult, forma
 		if err := w.removeTextArtifacts(); err != nil {
 			return err
 		}
+		if err := w.removeCtagsArtifacts(); err != nil {
+			return err
+		}
+	case FormatCtags:
+		if err := w.WriteCtags(g, parseResult); err != nil {
+			return err
+		}
+		if err := w.removeTextArtifacts(); err != nil {
+			return err
+		}
+		if err := w.removeJSONLArtifacts(); err != nil {
+			return err
+		}
 	default:
 		return fmt.Errorf("unsupported format %q", format)
 	}
@@ -125,19 +196,104 @@ func (w *Writer) WriteIndex(g *graph.Graph) error {
 			node.Symbol.Kind.String(),
 			node.Symbol.Signature,
 		))
+		if node.Summary != "" {
+			sb.WriteString(fmt.Sprintf("  summary: %s\n", node.Summary))
+		}
 	}
 
-	// File summary
-	sb.WriteString("\n## Files\n\n")
-	for _, file := range g.Files() {
-		nodes := g.NodesForFile(file)
-		sb.WriteString(fmt.Sprintf("- %s (%d symbols)\n", file, len(nodes)))
+	if w.order == OrderPath {
+		// File summary
+		sb.WriteString("\n## Files\n\n")
+		for _, file := range g.Files() {
+			nodes := g.NodesForFile(file)
+			sb.WriteString(fmt.Sprintf("- %s (%d symbols)\n", file, len(nodes)))
+		}
+	} else {
+		writeModulesByImportance(&sb, g)
 	}
 
 	path := filepath.Join(w.contextDir, IndexFile)
 	return fileutil.WriteIfChanged(path, []byte(sb.String()))
 }
 
+type rankedModule struct {
+	name  string
+	rank  float64
+	files []rankedFile
+	nodes []*graph.Node
+}
+
+type rankedFile struct {
+	path  string
+	rank  float64
+	count int
+}
+
+// writeModulesByImportance lists modules by aggregate PageRank so truncated reads
+// of index.txt see the most connected code first.
+func writeModulesByImportance(sb *strings.Builder, g *graph.Graph) {
+	byName := make(map[string]*rankedModule)
+	for _, file := range g.Files() {
+		name := getModuleName(file)
+		module, ok := byName[name]
+		if !ok {
+			module = &rankedModule{name: name}
+			byName[name] = module
+		}
+
+		nodes := g.NodesForFile(file)
+		entry := rankedFile{path: file, count: len(nodes)}
+		for _, node := range nodes {
+			entry.rank += node.PageRank
+		}
+		module.rank += entry.rank
+		module.files = append(module.files, entry)
+		module.nodes = append(module.nodes, nodes...)
+	}
+
+	modules := make([]*rankedModule, 0, len(byName))
+	for _, module := range byName {
+		sort.SliceStable(module.files, func(i, j int) bool {
+			if module.files[i].rank == module.files[j].rank {
+				return module.files[i].path < module.files[j].path
+			}
+			return module.files[i].rank > module.files[j].rank
+		})
+		sort.Slice(module.nodes, func(i, j int) bool {
+			if module.nodes[i].PageRank == module.nodes[j].PageRank {
+				return module.nodes[i].ID < module.nodes[j].ID
+			}
+			return module.nodes[i].PageRank > module.nodes[j].PageRank
+		})
+		modules = append(modules, module)
+	}
+	sort.Slice(modules, func(i, j int) bool {
+		if modules[i].rank == modules[j].rank {
+			return modules[i].name < modules[j].name
+		}
+		return modules[i].rank > modules[j].rank
+	})
+
+	sb.WriteString("\n## Modules (by importance)\n")
+	for _, module := range modules {
+		sb.WriteString(fmt.Sprintf("\n### %s (rank=%.4f, %d files)\n", module.name, module.rank, len(module.files)))
+		keyNodes := module.nodes
+		if len(keyNodes) > indexModuleKeySymbols {
+			keyNodes = keyNodes[:indexModuleKeySymbols]
+		}
+		if len(keyNodes) > 0 {
+			names := make([]string, 0, len(keyNodes))
+			for _, node := range keyNodes {
+				names = append(names, node.Symbol.Name)
+			}
+			sb.WriteString(fmt.Sprintf("key symbols: [%s]\n", strings.Join(names, ", ")))
+		}
+		for _, file := range module.files {
+			sb.WriteString(fmt.Sprintf("- %s (%d symbols)\n", file.path, file.count))
+		}
+	}
+}
+
 // WriteGraph writes the graph.txt adjacency list
 func (w *Writer) WriteGraph(g *graph.Graph) error {
 	var sb strings.Builder
@@ -149,7 +305,7 @@ func (w *Writer) WriteGraph(g *graph.Graph) error {
 		nodes := g.NodesForFile(file)
 		for _, node := range nodes {
 			if len(node.OutEdges) > 0 {
-				formattedEdges := formatEdgesWithConfidence(node)
+				formattedEdges := formatEdgesWithConfidence(node, node.OutEdges)
 				sb.WriteString(fmt.Sprintf("%s -> [%s]\n",
 					node.ID,
 					strings.Join(formattedEdges, ", "),
@@ -172,10 +328,10 @@ func (w *Writer) WriteModules(g *graph.Graph, parseResult *parser.ParseResult) e
 		modules[module] = append(modules[module], file)
 	}
 
-	// Create imports lookup
-	fileImports := make(map[string][]string)
+	// Create per-file lookup for imports and file tags
+	fileData := make(map[string]parser.FileSymbols, len(parseResult.Files))
 	for _, f := range parseResult.Files {
-		fileImports[f.Path] = f.Imports
+		fileData[f.Path] = f
 	}
 
 	for _, files := range modules {
@@ -191,7 +347,7 @@ func (w *Writer) WriteModules(g *graph.Graph, parseResult *parser.ParseResult) e
 	desired := make(map[string]bool, len(moduleNames))
 	for _, module := range moduleNames {
 		files := modules[module]
-		if err := w.writeModuleFile(module, files, g, fileImports); err != nil {
+		if err := w.writeModuleFile(module, files, g, fileData); err != nil {
 			return err
 		}
 		desired[moduleFilename(module)] = true
@@ -204,7 +360,7 @@ func (w *Writer) WriteModules(g *graph.Graph, parseResult *parser.ParseResult) e
 	return nil
 }
 
-func (w *Writer) writeModuleFile(module string, files []string, g *graph.Graph, fileImports map[string][]string) error {
+func (w *Writer) writeModuleFile(module string, files []string, g *graph.Graph, fileData map[string]parser.FileSymbols) error {
 	var sb strings.Builder
 
 	sb.WriteString(fmt.Sprintf("# Module: %s\n\n", module))
@@ -212,8 +368,19 @@ func (w *Writer) writeModuleFile(module string, files []string, g *graph.Graph,
 	for _, file := range files {
 		sb.WriteString(fmt.Sprintf("## %s\n", file))
 
+		data := fileData[file]
+		if data.Generated {
+			sb.WriteString("generated: true\n")
+		}
+		if data.External {
+			sb.WriteString("external: true\n")
+		}
+		if data.BuildConstraint != "" {
+			sb.WriteString(fmt.Sprintf("build: %s\n", data.BuildConstraint))
+		}
+
 		// Imports
-		if imports, ok := fileImports[file]; ok && len(imports) > 0 {
+		if imports := data.Imports; len(imports) > 0 {
 			imports = append([]string(nil), imports...)
 			sort.Strings(imports)
 			sb.WriteString("imports: [")
@@ -233,15 +400,30 @@ func (w *Writer) writeModuleFile(module string, files []string, g *graph.Graph,
 			if node.Symbol.Doc != "" {
 				sb.WriteString(fmt.Sprintf("doc: %s\n", node.Symbol.Doc))
 			}
+			if node.Summary != "" {
+				sb.WriteString(fmt.Sprintf("summary: %s\n", node.Summary))
+			}
 
-			if len(node.OutEdges) > 0 {
-				sb.WriteString(fmt.Sprintf("calls: [%s]\n", strings.Join(formatEdgesWithConfidence(node), ", ")))
+			if len(node.Symbol.Decorators) > 0 {
+				sb.WriteString(fmt.Sprintf("decorators: [%s]\n", strings.Join(node.Symbol.Decorators, ", ")))
+			}
+
+			if calls := node.OutEdgesOfKind(graph.EdgeCall); len(calls) > 0 {
+				sb.WriteString(fmt.Sprintf("calls: [%s]\n", strings.Join(formatEdgesWithConfidence(node, calls), ", ")))
 			}
 
-			if len(node.InEdges) > 0 {
-				inEdges := append([]string(nil), node.InEdges...)
-				sort.Strings(inEdges)
-				sb.WriteString(fmt.Sprintf("called_by: [%s]\n", strings.Join(inEdges, ", ")))
+			if callers := g.InEdgesOfKind(node, graph.EdgeCall); len(callers) > 0 {
+				sort.Strings(callers)
+				sb.WriteString(fmt.Sprintf("called_by: [%s]\n", strings.Join(callers, ", ")))
+			}
+
+			for _, label := range typeEdgeLabels {
+				if targets := node.OutEdgesOfKind(label.kind); len(targets) > 0 {
+					sb.WriteString(fmt.Sprintf("%s: [%s]\n", label.out, strings.Join(targets, ", ")))
+				}
+				if sources := g.InEdgesOfKind(node, label.kind); len(sources) > 0 {
+					sb.WriteString(fmt.Sprintf("%s: [%s]\n", label.in, strings.Join(sources, ", ")))
+				}
 			}
 		}
 
@@ -264,13 +446,31 @@ func getModuleName(file string) string {
 	return parts[0]
 }
 
-func formatEdgesWithConfidence(node *graph.Node) []string {
-	formatted := make([]string, 0, len(node.OutEdges))
-	for _, edge := range node.OutEdges {
+// typeEdgeLabels name the module file lines listing a symbol's type edges
+// in each direction.
+var typeEdgeLabels = []struct {
+	kind    graph.EdgeKind
+	out, in string
+}{
+	{graph.EdgeInherit, "inherits", "inherited_by"},
+	{graph.EdgeImplement, "implements", "implemented_by"},
+	{graph.EdgeReference, "references", "referenced_by"},
+	{graph.EdgeRender, "renders", "rendered_by"},
+	{graph.EdgeGeneratedFrom, "generated_from", "generates"},
+}
+
+// formatEdgesWithConfidence renders edges as target{confidence}, or
+// target{kind,confidence} for edges other than calls.
+func formatEdgesWithConfidence(node *graph.Node, targets []string) []string {
+	formatted := make([]string, 0, len(targets))
+	for _, edge := range targets {
 		confidence := node.OutEdgeConfidence[edge]
 		if confidence == "" {
 			confidence = "heuristic"
 		}
+		if kind := node.EdgeKindTo(edge); kind != graph.EdgeCall {
+			confidence = string(kind) + "," + confidence
+		}
 		formatted = append(formatted, fmt.Sprintf("%s{%s}", edge, confidence))
 	}
 	sort.Strings(formatted)
@@ -289,26 +489,61 @@ type symbolRecord struct {
 	File      string `json:"file"`
 	Language  string `json:"language"`
 	Line      int    `json:"line"`
+	// EndLine, StartByte and EndByte locate the declaration's body.
+	EndLine   int    `json:"end_line,omitempty"`
+	StartByte int    `json:"start_byte,omitempty"`
+	EndByte   int    `json:"end_byte,omitempty"`
 	Doc       string `json:"doc,omitempty"`
+	// Summary is the symbol's enrich summary, when it has one.
+	Summary string `json:"summary,omitempty"`
+	// Visibility is public, protected or private when the parser tells.
+	Visibility string `json:"visibility,omitempty"`
+	// Decorators are the decorators applied to the symbol, as written.
+	Decorators []string `json:"decorators,omitempty"`
+	// Owners are the CODEOWNERS owners of the symbol's file.
+	Owners []string `json:"owners,omitempty"`
+	// External marks symbols of vendored or generated code.
+	External bool `json:"external,omitempty"`
+	// InDegree, OutDegree and Betweenness are importance metrics besides
+	// PageRank; betweenness is approximate and rounded.
+	InDegree    int     `json:"in_degree,omitempty"`
+	OutDegree   int     `json:"out_degree,omitempty"`
+	Betweenness float64 `json:"betweenness,omitempty"`
 }
 
 type edgeRecord struct {
 	SourceID   string `json:"source_id"`
 	TargetID   string `json:"target_id"`
+	EdgeType   string `json:"edge_type"` // a graph.EdgeKind; import edges have file paths as IDs
 	Confidence string `json:"confidence"`
 }
 
 type manifestRecord struct {
-	SchemaVersion string             `json:"schema_version"`
-	Format        string             `json:"format"`
-	Counts        manifestCount      `json:"counts"`
-	Artifacts     []manifestArtifact `json:"artifacts"`
+	SchemaVersion string              `json:"schema_version"`
+	Format        string              `json:"format"`
+	Counts        manifestCount       `json:"counts"`
+	Artifacts     []manifestArtifact  `json:"artifacts"`
+	Namespaces    []manifestNamespace `json:"namespaces"`
+	// Codeowners is the CODEOWNERS file owners were read from, if any.
+	Codeowners string          `json:"codeowners,omitempty"`
+	Owners     []manifestOwner `json:"owners,omitempty"`
+	// External counts the files, symbols and edges of vendored and
+	// generated code, whichever namespace they were written to.
+	External *manifestCount `json:"external,omitempty"`
 }
 
 type manifestCount struct {
 	Files   int `json:"files"`
 	Symbols int `json:"symbols"`
 	Edges   int `json:"edges"`
+	Modules int `json:"modules,omitempty"`
+}
+
+// manifestOwner counts the files and symbols a CODEOWNERS owner holds.
+type manifestOwner struct {
+	Owner   string `json:"owner"`
+	Files   int    `json:"files"`
+	Symbols int    `json:"symbols"`
 }
 
 type manifestArtifact struct {
@@ -316,76 +551,243 @@ type manifestArtifact struct {
 	Hash string `json:"hash"`
 }
 
-func (w *Writer) WriteJSONL(g *graph.Graph, parseResult *parser.ParseResult) error {
+type manifestNamespace struct {
+	Name      string             `json:"name"`
+	Primary   bool               `json:"primary"`
+	Counts    manifestCount      `json:"counts"`
+	Artifacts []manifestArtifact `json:"artifacts"`
+}
+
+type namespaceStreams struct {
+	files   int
+	symbols *jsonlStream
+	edges   *jsonlStream
+}
+
+// WriteJSONL streams symbol and edge records into per-namespace artifacts as
+// each file's nodes are visited, so peak memory stays at the graph itself
+// rather than growing with a second copy of every record.
+func (w *Writer) WriteJSONL(g *graph.Graph, parseResult *parser.ParseResult) (err error) {
 	fileLanguage := make(map[string]string, len(parseResult.Files))
+	externalFiles := make(map[string]bool)
 	for _, file := range parseResult.Files {
 		fileLanguage[file.Path] = file.Language
+		if file.External {
+			externalFiles[file.Path] = true
+		}
 	}
+	codeowners, err := owners.Load(w.rootPath)
+	if err != nil {
+		return err
+	}
+	ownerCounts := make(map[string]*manifestOwner)
+	var external *manifestCount
 
-	symbols := make([]symbolRecord, 0, len(g.Nodes))
-	for _, file := range g.Files() {
-		for _, node := range g.NodesForFile(file) {
-			symbols = append(symbols, symbolRecord{
-				ID:        node.ID,
-				Name:      node.Symbol.Name,
-				Kind:      node.Symbol.Kind.String(),
-				Signature: node.Symbol.Signature,
-				File:      node.File,
-				Language:  fileLanguage[node.File],
-				Line:      node.Symbol.Line,
-				Doc:       node.Symbol.Doc,
-			})
+	byNamespace := make(map[string]*namespaceStreams)
+	defer func() {
+		if err == nil {
+			return
+		}
+		for _, streams := range byNamespace {
+			if streams.symbols != nil {
+				streams.symbols.Abort()
+			}
+			if streams.edges != nil {
+				streams.edges.Abort()
+			}
+		}
+	}()
+	open := func(namespace string) (*namespaceStreams, error) {
+		if streams, ok := byNamespace[namespace]; ok {
+			return streams, nil
+		}
+		streams := &namespaceStreams{}
+		byNamespace[namespace] = streams
+		var err error
+		streams.symbols, err = newJSONLStream(w.artifactPath(namespace, SymbolsFile))
+		if err != nil {
+			return nil, err
+		}
+		streams.edges, err = newJSONLStream(w.artifactPath(namespace, EdgesFile))
+		if err != nil {
+			return nil, err
 		}
+		return streams, nil
 	}
 
-	edges := make([]edgeRecord, 0)
+	// Primary artifacts are always written, even when empty, so readers can rely on them.
+	if _, err := open(NamespacePrimary); err != nil {
+		return err
+	}
+	totalSymbols, totalEdges := 0, 0
 	for _, file := range g.Files() {
-		for _, node := range g.NodesForFile(file) {
+		streams, err := open(NamespaceForFile(file))
+		if err != nil {
+			return err
+		}
+		streams.files++
+		fileOwners := codeowners.For(file)
+		nodes := g.NodesForFile(file)
+		fileExternal := externalFiles[file]
+		if fileExternal {
+			if external == nil {
+				external = &manifestCount{}
+			}
+			external.Files++
+			external.Symbols += len(nodes)
+		}
+		fileEdges := totalEdges
+		for _, owner := range fileOwners {
+			if ownerCounts[owner] == nil {
+				ownerCounts[owner] = &manifestOwner{Owner: owner}
+			}
+			ownerCounts[owner].Files++
+			ownerCounts[owner].Symbols += len(nodes)
+		}
+
+		for _, node := range nodes {
+			if err := streams.symbols.Encode(symbolRecord{
+				ID:         node.ID,
+				Name:       node.Symbol.Name,
+				Kind:       node.Symbol.Kind.String(),
+				Signature:  node.Symbol.Signature,
+				File:       node.File,
+				Language:   fileLanguage[node.File],
+				Line:       node.Symbol.Line,
+				EndLine:    node.Symbol.EndLine,
+				StartByte:  node.Symbol.StartByte,
+				EndByte:    node.Symbol.EndByte,
+				Doc:        node.Symbol.Doc,
+				Summary:    node.Summary,
+				Visibility: node.Symbol.Visibility,
+				Decorators: node.Symbol.Decorators,
+				Owners:     fileOwners,
+				External:   node.External,
+				InDegree:   len(node.InEdges),
+				OutDegree:  len(node.OutEdges),
+				// Rounded so float summation noise does not rewrite the file.
+				Betweenness: math.Round(node.Betweenness*1e6) / 1e6,
+			}); err != nil {
+				return err
+			}
+			totalSymbols++
+
+			// Edges belong to the namespace of their source symbol.
 			for _, targetID := range node.OutEdges {
 				confidence := node.OutEdgeConfidence[targetID]
 				if confidence == "" {
 					confidence = "heuristic"
 				}
-				edges = append(edges, edgeRecord{
+				if err := streams.edges.Encode(edgeRecord{
 					SourceID:   node.ID,
 					TargetID:   targetID,
+					EdgeType:   string(node.EdgeKindTo(targetID)),
 					Confidence: confidence,
-				})
+				}); err != nil {
+					return err
+				}
+				totalEdges++
 			}
 		}
-	}
 
-	symbolsData, err := fileutil.EncodeJSONL(symbols)
-	if err != nil {
-		return err
-	}
-	symbolPath := filepath.Join(w.contextDir, SymbolsFile)
-	if err := fileutil.WriteIfChanged(symbolPath, symbolsData); err != nil {
-		return err
+		// Import edges connect files rather than symbols.
+		includes := make(map[string]bool, len(g.FileIncludes[file]))
+		for _, include := range g.FileIncludes[file] {
+			includes[include] = true
+		}
+		for _, target := range g.FileImports[file] {
+			confidence := "heuristic"
+			if includes[target] {
+				confidence = "resolved"
+			}
+			if err := streams.edges.Encode(edgeRecord{
+				SourceID:   file,
+				TargetID:   target,
+				EdgeType:   string(graph.EdgeImport),
+				Confidence: confidence,
+			}); err != nil {
+				return err
+			}
+			totalEdges++
+		}
+		if fileExternal {
+			external.Edges += totalEdges - fileEdges
+		}
 	}
 
-	edgesData, err := fileutil.EncodeJSONL(edges)
-	if err != nil {
-		return err
-	}
-	edgesPath := filepath.Join(w.contextDir, EdgesFile)
-	if err := fileutil.WriteIfChanged(edgesPath, edgesData); err != nil {
-		return err
+	namespaceNames := make([]string, 0, len(byNamespace))
+	for namespace := range byNamespace {
+		if namespace != NamespacePrimary {
+			namespaceNames = append(namespaceNames, namespace)
+		}
 	}
+	sort.Strings(namespaceNames)
+	namespaceNames = append([]string{NamespacePrimary}, namespaceNames...)
 
 	manifest := manifestRecord{
-		SchemaVersion: "jsonl-v1",
+		SchemaVersion: JSONLSchemaVersion,
 		Format:        string(FormatJSONL),
 		Counts: manifestCount{
 			Files:   len(g.Files()),
-			Symbols: len(symbols),
-			Edges:   len(edges),
-		},
-		Artifacts: []manifestArtifact{
-			{Path: SymbolsFile, Hash: shortHash(symbolsData)},
-			{Path: EdgesFile, Hash: shortHash(edgesData)},
+			Symbols: totalSymbols,
+			Edges:   totalEdges,
 		},
+		Artifacts:  make([]manifestArtifact, 0, 2*len(namespaceNames)),
+		Namespaces: make([]manifestNamespace, 0, len(namespaceNames)),
+		External:   external,
+	}
+	if codeowners != nil {
+		manifest.Codeowners = codeowners.Path
+		for _, count := range ownerCounts {
+			manifest.Owners = append(manifest.Owners, *count)
+		}
+		sort.Slice(manifest.Owners, func(i, j int) bool { return manifest.Owners[i].Owner < manifest.Owners[j].Owner })
+	}
+	desired := make(map[string]bool, len(namespaceNames))
+	for _, namespace := range namespaceNames {
+		streams := byNamespace[namespace]
+		desired[namespace] = true
+
+		symbolCount, edgeCount := streams.symbols.count, streams.edges.count
+		symbolsHash, err := streams.symbols.Commit()
+		streams.symbols = nil
+		if err != nil {
+			return err
+		}
+		edgesHash, err := streams.edges.Commit()
+		streams.edges = nil
+		if err != nil {
+			return err
+		}
+
+		artifacts := []manifestArtifact{
+			{Path: NamespaceArtifactPath(namespace, SymbolsFile), Hash: symbolsHash},
+			{Path: NamespaceArtifactPath(namespace, EdgesFile), Hash: edgesHash},
+		}
+		manifest.Artifacts = append(manifest.Artifacts, artifacts...)
+		manifest.Namespaces = append(manifest.Namespaces, manifestNamespace{
+			Name:    namespace,
+			Primary: namespace == NamespacePrimary,
+			Counts: manifestCount{
+				Files:   streams.files,
+				Symbols: symbolCount,
+				Edges:   edgeCount,
+			},
+			Artifacts: artifacts,
+		})
 	}
+	if err := w.removeStaleNamespaces(desired); err != nil {
+		return err
+	}
+
+	// The module graph spans every namespace, so it lives at the top level.
+	modulesHash, moduleCount, err := w.writeModulesJSONL(g, parseResult)
+	if err != nil {
+		return err
+	}
+	manifest.Counts.Modules = moduleCount
+	manifest.Artifacts = append(manifest.Artifacts, manifestArtifact{Path: ModulesFile, Hash: modulesHash})
+
 	manifestData, err := json.MarshalIndent(manifest, "", "  ")
 	if err != nil {
 		return err
@@ -396,6 +798,30 @@ func (w *Writer) WriteJSONL(g *graph.Graph, parseResult *parser.ParseResult) err
 	return fileutil.WriteIfChanged(manifestPath, manifestData)
 }
 
+func (w *Writer) artifactPath(namespace, filename string) string {
+	return filepath.Join(w.contextDir, filepath.FromSlash(NamespaceArtifactPath(namespace, filename)))
+}
+
+func (w *Writer) removeStaleNamespaces(desired map[string]bool) error {
+	entries, err := os.ReadDir(filepath.Join(w.contextDir, NamespacesDir))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	for _, entry := range entries {
+		if desired[entry.Name()] {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(w.contextDir, NamespacesDir, entry.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (w *Writer) removeStaleModuleFiles(desired map[string]bool) error {
 	pattern := filepath.Join(w.contextDir, ModulesDir, "*.txt")
 	existing, err := filepath.Glob(pattern)
@@ -427,16 +853,20 @@ func (w *Writer) removeTextArtifacts() error {
 }
 
 func (w *Writer) removeJSONLArtifacts() error {
-	for _, filename := range []string{SymbolsFile, EdgesFile, ManifestFile} {
+	for _, filename := range []string{SymbolsFile, EdgesFile, ModulesFile, ManifestFile} {
 		path := filepath.Join(w.contextDir, filename)
-		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
-			return err
+		for _, variant := range fileutil.ArtifactVariants(path) {
+			if err := os.Remove(variant); err != nil && !os.IsNotExist(err) {
+				return err
+			}
 		}
 	}
-	return nil
+	return os.RemoveAll(filepath.Join(w.contextDir, NamespacesDir))
 }
 
-func shortHash(data []byte) string {
-	sum := sha256.Sum256(data)
-	return hex.EncodeToString(sum[:])[:16]
+func (w *Writer) removeCtagsArtifacts() error {
+	if err := os.Remove(filepath.Join(w.contextDir, TagsFile)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
 }
@@ -0,0 +1,125 @@
+// Package owners reads CODEOWNERS files and resolves the owners of
+// repository paths.
+package owners
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/morozRed/skelly/internal/ignore"
+)
+
+// Unowned labels files no CODEOWNERS rule assigns to anyone.
+const Unowned = "(unowned)"
+
+// Locations are the CODEOWNERS paths GitHub reads, in lookup order; the
+// first one that exists is used.
+var Locations = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"}
+
+type rule struct {
+	pattern ignore.Pattern
+	owners  []string
+}
+
+// Codeowners is a parsed CODEOWNERS file.
+type Codeowners struct {
+	// Path is the file's location relative to the repository root.
+	Path  string
+	rules []rule
+}
+
+// Group is the files one owner is responsible for.
+type Group struct {
+	Owner string   `json:"owner"`
+	Files []string `json:"files"`
+}
+
+// Load reads the repository's CODEOWNERS file. It returns nil without an
+// error when the repository has none.
+func Load(rootPath string) (*Codeowners, error) {
+	for _, location := range Locations {
+		data, err := os.ReadFile(filepath.Join(rootPath, filepath.FromSlash(location)))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to read %s: %w", location, err)
+		}
+		return Parse(location, data), nil
+	}
+	return nil, nil
+}
+
+// Parse reads CODEOWNERS lines: a gitignore-style pattern followed by
+// owners (@user, @org/team or an email). A pattern without owners leaves
+// matching files unowned; # starts a comment.
+func Parse(path string, data []byte) *Codeowners {
+	codeowners := &Codeowners{Path: path}
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		pattern, ok := ignore.ParsePattern(fields[0])
+		if !ok {
+			continue
+		}
+		owners := make([]string, 0, len(fields)-1)
+		for _, field := range fields[1:] {
+			if strings.HasPrefix(field, "#") {
+				break
+			}
+			owners = append(owners, field)
+		}
+		codeowners.rules = append(codeowners.rules, rule{pattern: pattern, owners: owners})
+	}
+	return codeowners
+}
+
+// For returns the owners of a file: those of the last matching rule, or
+// nil when no rule matches. A nil Codeowners owns nothing.
+func (c *Codeowners) For(relPath string) []string {
+	if c == nil {
+		return nil
+	}
+	for i := len(c.rules) - 1; i >= 0; i-- {
+		if c.rules[i].pattern.Matches(relPath) {
+			if len(c.rules[i].owners) == 0 {
+				return nil
+			}
+			return c.rules[i].owners
+		}
+	}
+	return nil
+}
+
+// GroupFiles groups files by owner, sorted by owner with unowned files
+// last. A file with several owners appears in each of their groups.
+func (c *Codeowners) GroupFiles(files []string) []Group {
+	byOwner := make(map[string][]string)
+	for _, file := range files {
+		owners := c.For(file)
+		if len(owners) == 0 {
+			owners = []string{Unowned}
+		}
+		for _, owner := range owners {
+			byOwner[owner] = append(byOwner[owner], file)
+		}
+	}
+
+	groups := make([]Group, 0, len(byOwner))
+	for owner, files := range byOwner {
+		sort.Strings(files)
+		groups = append(groups, Group{Owner: owner, Files: files})
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if (groups[i].Owner == Unowned) != (groups[j].Owner == Unowned) {
+			return groups[j].Owner == Unowned
+		}
+		return groups[i].Owner < groups[j].Owner
+	})
+	return groups
+}
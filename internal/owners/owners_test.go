@@ -0,0 +1,46 @@
+package owners
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCodeownersLastMatchingRuleWins(t *testing.T) {
+	codeowners := Parse("CODEOWNERS", []byte(`# Default owners
+*       @acme/core
+*.md    @acme/docs   # docs team
+/billing/ @acme/billing alice@example.com
+billing/legacy/
+internal/**/cache.go @acme/perf
+`))
+
+	cases := map[string][]string{
+		"main.go":                        {"@acme/core"},
+		"README.md":                      {"@acme/docs"},
+		"billing/invoice.go":             {"@acme/billing", "alice@example.com"},
+		"billing/legacy/old.go":          nil,
+		"internal/store/redis/cache.go":  {"@acme/perf"},
+		"internal/store/redis/client.go": {"@acme/core"},
+	}
+	for file, want := range cases {
+		if got := codeowners.For(file); !reflect.DeepEqual(got, want) {
+			t.Fatalf("owners of %s: expected %v, got %v", file, want, got)
+		}
+	}
+
+	groups := codeowners.GroupFiles([]string{"billing/legacy/old.go", "main.go", "billing/invoice.go", "cmd/run.go"})
+	want := []Group{
+		{Owner: "@acme/billing", Files: []string{"billing/invoice.go"}},
+		{Owner: "@acme/core", Files: []string{"cmd/run.go", "main.go"}},
+		{Owner: "alice@example.com", Files: []string{"billing/invoice.go"}},
+		{Owner: Unowned, Files: []string{"billing/legacy/old.go"}},
+	}
+	if !reflect.DeepEqual(groups, want) {
+		t.Fatalf("unexpected groups: %+v", groups)
+	}
+
+	var missing *Codeowners
+	if got := missing.GroupFiles([]string{"main.go"}); !reflect.DeepEqual(got, []Group{{Owner: Unowned, Files: []string{"main.go"}}}) {
+		t.Fatalf("expected every file unowned without CODEOWNERS, got %+v", got)
+	}
+}
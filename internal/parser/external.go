@@ -0,0 +1,43 @@
+package parser
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// vendorDirs name the directories vendored dependencies are checked into.
+var vendorDirs = map[string]bool{
+	"vendor":       true,
+	"node_modules": true,
+	"third_party":  true,
+	"third-party":  true,
+}
+
+// generatedDirs name the directories code generators conventionally write to.
+var generatedDirs = map[string]bool{
+	"generated":     true,
+	"__generated__": true,
+}
+
+// IsVendored reports whether a repository-relative path lies under a
+// vendored dependency directory (vendor/, node_modules/, third_party/).
+func IsVendored(file string) bool {
+	return underDir(file, vendorDirs)
+}
+
+// IsExternalPath reports whether a repository-relative path lies under a
+// vendored dependency directory or a generated code directory (generated/,
+// __generated__/), that is, code the repository does not maintain by hand.
+func IsExternalPath(file string) bool {
+	return underDir(file, vendorDirs) || underDir(file, generatedDirs)
+}
+
+func underDir(file string, dirs map[string]bool) bool {
+	for _, part := range strings.Split(path.Dir(filepath.ToSlash(file)), "/") {
+		if dirs[part] {
+			return true
+		}
+	}
+	return false
+}
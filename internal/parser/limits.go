@@ -0,0 +1,43 @@
+package parser
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// DefaultMaxFileBytes is the size above which ParseFile skips a file
+// unread, so minified bundles and large generated files do not stall
+// tree-sitter.
+const DefaultMaxFileBytes = 1536 * 1024
+
+// binarySniffBytes is how much of a file is checked for NUL bytes, as git
+// does to tell binary files from text.
+const binarySniffBytes = 8000
+
+// Reasons ParseFile skips a file without parsing it.
+const (
+	SkipTooLarge = "too_large"
+	SkipBinary   = "binary"
+)
+
+// SkipError reports a file ParseFile skipped on purpose rather than failed on.
+type SkipError struct {
+	Reason  string
+	Message string
+}
+
+func (e *SkipError) Error() string {
+	return e.Message
+}
+
+// IsBinary reports whether content has a NUL byte among its first bytes.
+func IsBinary(content []byte) bool {
+	return bytes.IndexByte(content[:min(len(content), binarySniffBytes)], 0) >= 0
+}
+
+func tooLargeError(size, limit int64) *SkipError {
+	return &SkipError{
+		Reason:  SkipTooLarge,
+		Message: fmt.Sprintf("skipped: %d bytes is over the %d byte limit (max_file_bytes)", size, limit),
+	}
+}
@@ -0,0 +1,77 @@
+package parser
+
+import (
+	"bytes"
+	"go/build/constraint"
+	"regexp"
+)
+
+// generatedMarker matches the conventional generated-code header
+// (https://go.dev/s/generatedcode), which protoc, stringer, mockgen and most
+// other generators write, and the @generated tag used by JS and PHP tools.
+var generatedMarker = regexp.MustCompile(`^Code generated .* DO NOT EDIT\.?$|@generated\b`)
+
+// commentPrefixes are the line-comment and block-comment openers checked in
+// file headers.
+var commentPrefixes = [][]byte{[]byte("//"), []byte("#"), []byte("/*"), []byte("*"), []byte("--"), []byte("<!--")}
+
+// IsGenerated reports whether the comment block at the top of a file carries
+// a generated-code marker. Scanning stops at the first line that is neither
+// blank nor a comment, so markers further down are ignored.
+func IsGenerated(content []byte) bool {
+	for len(content) > 0 {
+		line := content
+		if idx := bytes.IndexByte(content, '\n'); idx != -1 {
+			line, content = content[:idx], content[idx+1:]
+		} else {
+			content = nil
+		}
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		text, ok := stripCommentPrefix(line)
+		if !ok {
+			return false
+		}
+		if generatedMarker.Match(bytes.TrimSpace(bytes.TrimSuffix(text, []byte("*/")))) {
+			return true
+		}
+	}
+	return false
+}
+
+func stripCommentPrefix(line []byte) ([]byte, bool) {
+	for _, prefix := range commentPrefixes {
+		if rest, ok := bytes.CutPrefix(line, prefix); ok {
+			return bytes.TrimSpace(rest), true
+		}
+	}
+	return nil, false
+}
+
+// BuildIgnored reports whether a Go build constraint can only be satisfied
+// with the "ignore" tag, the convention for files kept out of every build
+// (`//go:build ignore` on generator scripts and examples).
+func BuildIgnored(buildConstraint string) bool {
+	if buildConstraint == "" {
+		return false
+	}
+	expr, err := constraint.Parse("//go:build " + buildConstraint)
+	if err != nil {
+		return false
+	}
+	return requiresTag(expr, "ignore")
+}
+
+func requiresTag(expr constraint.Expr, tag string) bool {
+	switch e := expr.(type) {
+	case *constraint.TagExpr:
+		return e.Tag == tag
+	case *constraint.AndExpr:
+		return requiresTag(e.X, tag) || requiresTag(e.Y, tag)
+	case *constraint.OrExpr:
+		return requiresTag(e.X, tag) && requiresTag(e.Y, tag)
+	}
+	return false
+}
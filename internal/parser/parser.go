@@ -1,13 +1,16 @@
 package parser
 
 import (
+	"bytes"
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"sort"
 	"strings"
+	"sync"
 
 	"github.com/morozRed/skelly/internal/ignore"
 )
@@ -24,10 +27,30 @@ type LanguageParser interface {
 	Parse(filename string, content []byte) (*FileSymbols, error)
 }
 
+// Features lists the optional extractions a parser leaves out of a
+// language's symbols, trading fidelity for parse time.
+type Features struct {
+	SkipCalls      bool
+	SkipDocs       bool
+	SkipReferences bool
+}
+
+// FeatureParser is a LanguageParser that can leave out optional extractions.
+// features is keyed by the language files are reported as, so a parser that
+// handles several languages looks up each file's own.
+type FeatureParser interface {
+	SetFeatures(features map[string]Features)
+}
+
 // Registry holds all registered language parsers
 type Registry struct {
 	parsers   map[string]LanguageParser // language name -> parser
 	extToLang map[string]string         // extension -> language name
+	// maxFileBytes is the size above which ParseFile skips a file; 0
+	// disables the limit.
+	maxFileBytes int64
+	// features are the extractions registered parsers leave out, by language.
+	features map[string]Features
 }
 
 // ParseProgress reports incremental parse progress for supported source files.
@@ -39,13 +62,36 @@ type ParseProgress struct {
 // NewRegistry creates a new parser registry
 func NewRegistry() *Registry {
 	return &Registry{
-		parsers:   make(map[string]LanguageParser),
-		extToLang: make(map[string]string),
+		parsers:      make(map[string]LanguageParser),
+		extToLang:    make(map[string]string),
+		maxFileBytes: DefaultMaxFileBytes,
+	}
+}
+
+// SetMaxFileBytes sets the size above which ParseFile skips a file instead
+// of parsing it; 0 disables the limit. Worker registries of a directory
+// parse inherit it.
+func (r *Registry) SetMaxFileBytes(limit int64) {
+	r.maxFileBytes = limit
+}
+
+// SetFeatures turns off optional extractions by language for the parsers
+// that support it (FeatureParser). Worker registries of a directory parse
+// inherit them.
+func (r *Registry) SetFeatures(features map[string]Features) {
+	r.features = features
+	for _, p := range r.parsers {
+		if fp, ok := p.(FeatureParser); ok {
+			fp.SetFeatures(features)
+		}
 	}
 }
 
 // Register adds a language parser to the registry
 func (r *Registry) Register(p LanguageParser) {
+	if fp, ok := p.(FeatureParser); ok && r.features != nil {
+		fp.SetFeatures(r.features)
+	}
 	lang := p.Language()
 	r.parsers[lang] = p
 	for _, ext := range p.Extensions() {
@@ -73,6 +119,18 @@ func (r *Registry) SupportedExtensions() []string {
 	return exts
 }
 
+// LanguageExtensions returns each registered language with its sorted file extensions
+func (r *Registry) LanguageExtensions() map[string][]string {
+	out := make(map[string][]string, len(r.parsers))
+	for ext, lang := range r.extToLang {
+		out[lang] = append(out[lang], ext)
+	}
+	for lang := range out {
+		sort.Strings(out[lang])
+	}
+	return out
+}
+
 // ParseFile parses a single file and returns its symbols
 func (r *Registry) ParseFile(path string) (*FileSymbols, error) {
 	parser, ok := r.GetParserForFile(path)
@@ -80,10 +138,23 @@ func (r *Registry) ParseFile(path string) (*FileSymbols, error) {
 		return nil, nil // unsupported file type, skip silently
 	}
 
+	if r.maxFileBytes > 0 {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if info.Size() > r.maxFileBytes {
+			return nil, tooLargeError(info.Size(), r.maxFileBytes)
+		}
+	}
+
 	content, err := os.ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
+	if IsBinary(content) {
+		return nil, &SkipError{Reason: SkipBinary, Message: "skipped: binary content"}
+	}
 
 	symbols, err := parser.Parse(path, content)
 	if err != nil {
@@ -96,6 +167,9 @@ func (r *Registry) ParseFile(path string) (*FileSymbols, error) {
 		symbols.Symbols[i].Calls = normalizeCallSites(symbols.Symbols[i].Calls)
 	}
 
+	symbols.Generated = IsGenerated(content)
+	symbols.Lines = countLines(content)
+
 	// Compute file hash for incremental updates
 	symbols.Hash = hashContent(content)
 
@@ -110,6 +184,39 @@ func (r *Registry) ParseDirectory(root string, ignorePaths []string) (*ParseResu
 // ParseDirectoryWithProgress recursively parses all supported files in a directory
 // and invokes onProgress before each supported file parse when provided.
 func (r *Registry) ParseDirectoryWithProgress(root string, ignorePaths []string, onProgress func(ParseProgress)) (*ParseResult, error) {
+	return r.ParseDirectoryWithOptions(root, ignorePaths, ParseOptions{OnProgress: onProgress})
+}
+
+// ParseOptions configures ParseDirectoryWithOptions.
+type ParseOptions struct {
+	// Jobs is the number of files parsed concurrently; values below 2 parse
+	// sequentially with this registry.
+	Jobs int
+	// NewWorker returns a registry for one parse worker. Tree-sitter parsers
+	// are not safe for concurrent use, so each worker needs its own. Without
+	// it, parsing is sequential regardless of Jobs.
+	NewWorker func() *Registry
+	// OnProgress is invoked before each supported file parse when provided.
+	OnProgress func(ParseProgress)
+	// OnScan is invoked once the walk has found the files to parse, with
+	// their count, before any is parsed.
+	OnScan func(files int)
+}
+
+type parseJob struct {
+	path    string
+	relPath string
+}
+
+type parseOutcome struct {
+	symbols *FileSymbols
+	issue   *ParseIssue
+}
+
+// ParseDirectoryWithOptions walks root for supported files and parses them,
+// concurrently when opts allow it. Results are sorted by path so output does
+// not depend on scheduling.
+func (r *Registry) ParseDirectoryWithOptions(root string, ignorePaths []string, opts ParseOptions) (*ParseResult, error) {
 	ignoreMatcher := ignore.NewMatcher(ignorePaths)
 
 	result := &ParseResult{
@@ -117,7 +224,7 @@ func (r *Registry) ParseDirectoryWithProgress(root string, ignorePaths []string,
 		Files:    make([]FileSymbols, 0),
 		Issues:   make([]ParseIssue, 0),
 	}
-	parsedCount := 0
+	jobs := make([]parseJob, 0)
 
 	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
@@ -152,38 +259,63 @@ func (r *Registry) ParseDirectoryWithProgress(root string, ignorePaths []string,
 		if _, ok := r.GetParserForFile(path); !ok {
 			return nil
 		}
-		parsedCount++
-		if onProgress != nil {
-			onProgress(ParseProgress{
-				File:  relPath,
-				Count: parsedCount,
-			})
-		}
+		jobs = append(jobs, parseJob{path: path, relPath: relPath})
+		return nil
+	})
 
-		symbols, err := r.ParseFile(path)
-		if err != nil {
-			lang := ""
-			if langParser, ok := r.GetParserForFile(path); ok {
-				lang = langParser.Language()
+	if opts.OnScan != nil {
+		opts.OnScan(len(jobs))
+	}
+
+	outcomes := make([]parseOutcome, len(jobs))
+	workers := min(opts.Jobs, len(jobs))
+	if opts.NewWorker == nil || workers < 2 {
+		for i, job := range jobs {
+			if opts.OnProgress != nil {
+				opts.OnProgress(ParseProgress{File: job.relPath, Count: i + 1})
 			}
-			result.Issues = append(result.Issues, ParseIssue{
-				File:     relPath,
-				Language: lang,
-				Severity: "error",
-				Message:  err.Error(),
-			})
-			return nil
+			outcomes[i] = r.parseJob(job)
 		}
-		if symbols != nil {
-			symbols.Path = relPath
-			for i := range symbols.Symbols {
-				symbols.Symbols[i].ID = StableSymbolID(relPath, symbols.Symbols[i])
-			}
-			result.Files = append(result.Files, *symbols)
+	} else {
+		var (
+			mu     sync.Mutex
+			count  int
+			wg     sync.WaitGroup
+			queued = make(chan int)
+		)
+		for w := 0; w < workers; w++ {
+			worker := opts.NewWorker()
+			worker.maxFileBytes = r.maxFileBytes
+			worker.SetFeatures(r.features)
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := range queued {
+					if opts.OnProgress != nil {
+						mu.Lock()
+						count++
+						opts.OnProgress(ParseProgress{File: jobs[i].relPath, Count: count})
+						mu.Unlock()
+					}
+					outcomes[i] = worker.parseJob(jobs[i])
+				}
+			}()
 		}
+		for i := range jobs {
+			queued <- i
+		}
+		close(queued)
+		wg.Wait()
+	}
 
-		return nil
-	})
+	for _, outcome := range outcomes {
+		if outcome.issue != nil {
+			result.Issues = append(result.Issues, *outcome.issue)
+		}
+		if outcome.symbols != nil {
+			result.Files = append(result.Files, *outcome.symbols)
+		}
+	}
 
 	sort.Slice(result.Files, func(i, j int) bool {
 		return result.Files[i].Path < result.Files[j].Path
@@ -198,12 +330,51 @@ func (r *Registry) ParseDirectoryWithProgress(root string, ignorePaths []string,
 	return result, err
 }
 
+func (r *Registry) parseJob(job parseJob) parseOutcome {
+	symbols, err := r.ParseFile(job.path)
+	if err != nil {
+		lang := ""
+		if langParser, ok := r.GetParserForFile(job.path); ok {
+			lang = langParser.Language()
+		}
+		issue := &ParseIssue{
+			File:     job.relPath,
+			Language: lang,
+			Severity: "error",
+			Message:  err.Error(),
+		}
+		var skip *SkipError
+		if errors.As(err, &skip) {
+			issue.Severity = "warning"
+			issue.Reason = skip.Reason
+		}
+		return parseOutcome{issue: issue}
+	}
+	if symbols == nil {
+		return parseOutcome{}
+	}
+	symbols.Path = job.relPath
+	for i, id := range SymbolIDs(job.relPath, symbols.Symbols) {
+		symbols.Symbols[i].ID = id
+	}
+	return parseOutcome{symbols: symbols}
+}
+
 func hashContent(content []byte) string {
 	h := sha256.New()
 	h.Write(content)
 	return hex.EncodeToString(h.Sum(nil))[:16] // short hash
 }
 
+// countLines counts newline-terminated lines plus a final unterminated one.
+func countLines(content []byte) int {
+	lines := bytes.Count(content, []byte{'\n'})
+	if len(content) > 0 && content[len(content)-1] != '\n' {
+		lines++
+	}
+	return lines
+}
+
 func normalizeStrings(values []string) []string {
 	if len(values) == 0 {
 		return nil
@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strings"
 	"testing"
 )
 
@@ -92,3 +93,212 @@ func mustWriteFile(t *testing.T, path, content string) {
 		t.Fatalf("failed to write %s: %v", path, err)
 	}
 }
+
+func TestParseDirectoryWithOptionsIsDeterministicAcrossJobs(t *testing.T) {
+	root := t.TempDir()
+	for i := 0; i < 40; i++ {
+		mustWriteFile(t, filepath.Join(root, "pkg"+string(rune('a'+i%5)), "file"+string(rune('a'+i))+".mock"), "x")
+	}
+	newRegistry := func() *Registry {
+		r := NewRegistry()
+		r.Register(mockParser{lang: "mock", exts: []string{".mock"}})
+		return r
+	}
+
+	sequential, err := newRegistry().ParseDirectoryWithOptions(root, nil, ParseOptions{Jobs: 1})
+	if err != nil {
+		t.Fatalf("sequential parse failed: %v", err)
+	}
+	progressCount := 0
+	parallel, err := newRegistry().ParseDirectoryWithOptions(root, nil, ParseOptions{
+		Jobs:       8,
+		NewWorker:  newRegistry,
+		OnProgress: func(ParseProgress) { progressCount++ },
+	})
+	if err != nil {
+		t.Fatalf("parallel parse failed: %v", err)
+	}
+
+	if len(parallel.Files) != 40 || len(sequential.Files) != 40 || progressCount != 40 {
+		t.Fatalf("expected 40 files and progress steps, got sequential=%d parallel=%d progress=%d", len(sequential.Files), len(parallel.Files), progressCount)
+	}
+	for i := range sequential.Files {
+		if sequential.Files[i].Path != parallel.Files[i].Path || sequential.Files[i].Symbols[0].ID != parallel.Files[i].Symbols[0].ID {
+			t.Fatalf("parallel result differs at %d: %s vs %s", i, sequential.Files[i].Path, parallel.Files[i].Path)
+		}
+	}
+}
+
+// featureMockParser records a call site unless calls are turned off for its
+// language.
+type featureMockParser struct {
+	mockParser
+	features map[string]Features
+}
+
+func (m *featureMockParser) SetFeatures(features map[string]Features) {
+	m.features = features
+}
+
+func (m *featureMockParser) Parse(filename string, content []byte) (*FileSymbols, error) {
+	file, err := m.mockParser.Parse(filename, content)
+	if err == nil && !m.features[m.lang].SkipCalls {
+		file.Symbols[0].Calls = []CallSite{{Name: "helper"}}
+	}
+	return file, err
+}
+
+func TestParseDirectoryPassesFeaturesToWorkers(t *testing.T) {
+	root := t.TempDir()
+	for i := 0; i < 8; i++ {
+		mustWriteFile(t, filepath.Join(root, "file"+string(rune('a'+i))+".mock"), "x")
+	}
+	newRegistry := func() *Registry {
+		r := NewRegistry()
+		r.Register(&featureMockParser{mockParser: mockParser{lang: "mock", exts: []string{".mock"}}})
+		return r
+	}
+
+	for _, jobs := range []int{1, 4} {
+		r := newRegistry()
+		r.SetFeatures(map[string]Features{"mock": {SkipCalls: true}})
+		result, err := r.ParseDirectoryWithOptions(root, nil, ParseOptions{Jobs: jobs, NewWorker: newRegistry})
+		if err != nil {
+			t.Fatalf("parse with %d jobs failed: %v", jobs, err)
+		}
+		for _, file := range result.Files {
+			if len(file.Symbols[0].Calls) != 0 {
+				t.Fatalf("expected calls to be turned off with %d jobs, got %#v", jobs, file.Symbols[0])
+			}
+		}
+	}
+}
+
+func TestParseDirectorySkipsOversizedAndBinaryFiles(t *testing.T) {
+	root := t.TempDir()
+	mustWriteFile(t, filepath.Join(root, "small.mock"), "ok")
+	mustWriteFile(t, filepath.Join(root, "bundle.mock"), strings.Repeat("x", 64))
+	mustWriteFile(t, filepath.Join(root, "blob.mock"), "ok\x00\x01")
+	newRegistry := func() *Registry {
+		r := NewRegistry()
+		r.Register(mockParser{lang: "mock", exts: []string{".mock"}})
+		return r
+	}
+
+	for _, jobs := range []int{1, 4} {
+		r := newRegistry()
+		r.SetMaxFileBytes(32)
+		result, err := r.ParseDirectoryWithOptions(root, nil, ParseOptions{Jobs: jobs, NewWorker: newRegistry})
+		if err != nil {
+			t.Fatalf("parse failed: %v", err)
+		}
+		if len(result.Files) != 1 || result.Files[0].Path != "small.mock" {
+			t.Fatalf("jobs=%d: expected only small.mock to be parsed, got %#v", jobs, result.Files)
+		}
+		if len(result.Issues) != 2 ||
+			result.Issues[0].File != "blob.mock" || result.Issues[0].Reason != SkipBinary || result.Issues[0].Severity != "warning" ||
+			result.Issues[1].File != "bundle.mock" || result.Issues[1].Reason != SkipTooLarge || result.Issues[1].Severity != "warning" {
+			t.Fatalf("jobs=%d: expected skip warnings, got %#v", jobs, result.Issues)
+		}
+	}
+
+	r := newRegistry()
+	r.SetMaxFileBytes(0)
+	if _, err := r.ParseFile(filepath.Join(root, "bundle.mock")); err != nil {
+		t.Fatalf("expected no size limit when disabled, got %v", err)
+	}
+}
+
+func TestSymbolIDsIgnoreLinesAndDisambiguateCollisions(t *testing.T) {
+	symbols := []Symbol{
+		{Name: "init", Kind: SymbolFunction, Signature: "func init()", Line: 20},
+		{Name: "Run", Kind: SymbolFunction, Signature: "func Run()", Line: 3},
+		{Name: "init", Kind: SymbolFunction, Signature: "func init()", Line: 8},
+	}
+	ids := SymbolIDs("a.go", symbols)
+	base := StableSymbolID("a.go", symbols[0])
+	if ids[2] != base || ids[0] != base+"#2" {
+		t.Fatalf("expected the earlier init to keep %q, got %v", base, ids)
+	}
+
+	shifted := symbols[1]
+	shifted.Line = 40
+	if StableSymbolID("a.go", shifted) != ids[1] {
+		t.Fatalf("expected a line shift to keep the ID %q", ids[1])
+	}
+}
+
+func TestIsGeneratedReadsHeaderComments(t *testing.T) {
+	cases := map[string]bool{
+		"// Code generated by protoc-gen-go. DO NOT EDIT.\n// source: api.proto\n\npackage api\n": true,
+		"//go:build linux\n\n// Code generated by stringer; DO NOT EDIT.\n\npackage main\n":       true,
+		"# Code generated by sqlc. DO NOT EDIT.\nimport os\n":                                     true,
+		"/**\n * @generated SignedSource<<abc>>\n */\nexport const x = 1;\n":                      true,
+		"package main\n\n// Code generated by hand. DO NOT EDIT.\n":                               false,
+		"// Package api talks to the API.\npackage api\n":                                         false,
+	}
+	for content, want := range cases {
+		if got := IsGenerated([]byte(content)); got != want {
+			t.Errorf("IsGenerated(%q) = %v, want %v", content, got, want)
+		}
+	}
+}
+
+func TestBuildIgnoredRequiresIgnoreTag(t *testing.T) {
+	cases := map[string]bool{
+		"ignore":             true,
+		"ignore && linux":    true,
+		"ignore || tools":    false,
+		"linux && !cgo":      false,
+		"!ignore":            false,
+		"":                   false,
+		"(ignore || ignore)": true,
+	}
+	for constraint, want := range cases {
+		if got := BuildIgnored(constraint); got != want {
+			t.Errorf("BuildIgnored(%q) = %v, want %v", constraint, got, want)
+		}
+	}
+}
+
+func TestTestFilesAndSymbolsFollowLanguageConventions(t *testing.T) {
+	files := map[string]bool{
+		"go|billing/invoice_test.go":           true,
+		"go|billing/invoice.go":                false,
+		"python|tests/test_invoice.py":         true,
+		"python|app/testing.py":                false,
+		"typescript|src/invoice.spec.ts":       true,
+		"javascript|src/__tests__/invoice.js":  true,
+		"typescript|src/invoice.ts":            false,
+		"ruby|spec/models/invoice_spec.rb":     true,
+		"php|tests/InvoiceTest.php":            true,
+		"csharp|Billing.Tests/InvoiceTests.cs": true,
+	}
+	for key, want := range files {
+		language, file, _ := strings.Cut(key, "|")
+		if got := IsTestFile(language, file); got != want {
+			t.Fatalf("IsTestFile(%q, %q) = %v, want %v", language, file, got, want)
+		}
+	}
+
+	symbols := []struct {
+		language string
+		symbol   Symbol
+		want     bool
+	}{
+		{"go", Symbol{Name: "TestInvoiceTotal", Kind: SymbolFunction}, true},
+		{"go", Symbol{Name: "BenchmarkTotal", Kind: SymbolFunction}, true},
+		{"go", Symbol{Name: "newFixture", Kind: SymbolFunction}, false},
+		{"go", Symbol{Name: "TestMain", Kind: SymbolMethod, Container: "suite"}, false},
+		{"python", Symbol{Name: "test_total", Kind: SymbolMethod, Container: "TestInvoice"}, true},
+		{"python", Symbol{Name: "make_invoice", Kind: SymbolFunction}, false},
+		{"typescript", Symbol{Name: "adds tax", Kind: SymbolFunction, Signature: `it("adds tax")`}, true},
+		{"typescript", Symbol{Name: "render", Kind: SymbolFunction, Signature: "function render()"}, false},
+		{"python", Symbol{Name: "TestInvoice", Kind: SymbolClass}, false},
+	}
+	for _, tc := range symbols {
+		if got := IsTestSymbol(tc.language, tc.symbol); got != tc.want {
+			t.Fatalf("IsTestSymbol(%q, %#v) = %v, want %v", tc.language, tc.symbol, got, tc.want)
+		}
+	}
+}
@@ -4,12 +4,22 @@ import (
 	"crypto/sha1"
 	"encoding/hex"
 	"fmt"
+	"sort"
+	"strconv"
 )
 
-// StableSymbolID returns a deterministic ID for a symbol.
-// Format: file|line|kind|name|signature-hash.
+// SymbolIDVersion identifies the StableSymbolID scheme. Version 1 embedded
+// the line number (file|line|kind|name|signature-hash), so any edit above a
+// symbol changed its ID; state recorded under it is migrated on load by
+// callers that rewrite IDs (see state.MigrateSymbolIDs).
+const SymbolIDVersion = "2"
+
+// StableSymbolID returns a deterministic ID for a symbol that survives line
+// churn. Format: file|kind|qualified-name|signature-hash, where the
+// qualified name is Container.Name. Symbols sharing that ID within one file
+// are told apart by SymbolIDs.
 func StableSymbolID(file string, symbol Symbol) string {
-	base := fmt.Sprintf("%s|%d|%s|%s", file, symbol.Line, symbol.Kind.String(), symbol.Name)
+	base := fmt.Sprintf("%s|%s|%s", file, symbol.Kind.String(), symbol.QualifiedName())
 
 	if symbol.Signature == "" {
 		return base
@@ -18,3 +28,27 @@ func StableSymbolID(file string, symbol Symbol) string {
 	sigHash := sha1.Sum([]byte(symbol.Signature))
 	return fmt.Sprintf("%s|%s", base, hex.EncodeToString(sigHash[:4]))
 }
+
+// SymbolIDs returns the stable ID of each symbol declared in file. When
+// several symbols share a StableSymbolID, the first by line keeps it and
+// the others get "#2", "#3", ... in line order.
+func SymbolIDs(file string, symbols []Symbol) []string {
+	ids := make([]string, len(symbols))
+	groups := make(map[string][]int)
+	for i, symbol := range symbols {
+		ids[i] = StableSymbolID(file, symbol)
+		groups[ids[i]] = append(groups[ids[i]], i)
+	}
+	for _, indexes := range groups {
+		if len(indexes) < 2 {
+			continue
+		}
+		sort.SliceStable(indexes, func(a, b int) bool {
+			return symbols[indexes[a]].Line < symbols[indexes[b]].Line
+		})
+		for n, index := range indexes[1:] {
+			ids[index] += "#" + strconv.Itoa(n+2)
+		}
+	}
+	return ids
+}
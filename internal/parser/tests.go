@@ -0,0 +1,95 @@
+package parser
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// TestPattern returns the naming or placement convention that marks file as
+// a test in language ("*_test.go", "test_*.py", "*.spec.*", ...), or "" for
+// production files.
+func TestPattern(language, file string) string {
+	slashed := filepath.ToSlash(file)
+	base := path.Base(slashed)
+	switch language {
+	case "go":
+		if strings.HasSuffix(base, "_test.go") {
+			return "*_test.go"
+		}
+	case "python":
+		switch {
+		case strings.HasPrefix(base, "test_"):
+			return "test_*.py"
+		case strings.HasSuffix(base, "_test.py"):
+			return "*_test.py"
+		}
+	case "typescript", "javascript":
+		switch {
+		case strings.Contains(base, ".test."):
+			return "*.test.*"
+		case strings.Contains(base, ".spec."):
+			return "*.spec.*"
+		case strings.Contains(slashed, "__tests__/"):
+			return "__tests__/"
+		}
+	case "ruby":
+		switch {
+		case strings.HasSuffix(base, "_spec.rb"):
+			return "*_spec.rb"
+		case strings.HasSuffix(base, "_test.rb"):
+			return "*_test.rb"
+		}
+	case "rust":
+		if strings.HasPrefix(slashed, "tests/") || strings.Contains(slashed, "/tests/") {
+			return "tests/*.rs"
+		}
+	case "java":
+		switch {
+		case strings.HasSuffix(base, "Test.java") || strings.HasSuffix(base, "Tests.java"):
+			return "*Test.java"
+		case strings.Contains(slashed, "src/test/"):
+			return "src/test/"
+		}
+	case "csharp":
+		if strings.HasSuffix(base, "Test.cs") || strings.HasSuffix(base, "Tests.cs") {
+			return "*Tests.cs"
+		}
+	case "php":
+		if strings.HasSuffix(base, "Test.php") {
+			return "*Test.php"
+		}
+	}
+	return ""
+}
+
+// IsTestFile reports whether file is a test by its language's convention.
+func IsTestFile(language, file string) bool {
+	return TestPattern(language, file) != ""
+}
+
+// IsTestSymbol reports whether a symbol of a test file is a test case rather
+// than a helper: Go Test, Benchmark, Fuzz and Example functions, Python, Ruby
+// and PHP test* functions and methods, JS/TS it() and test() blocks, and the
+// methods of Java, C# and Rust test files.
+func IsTestSymbol(language string, sym Symbol) bool {
+	if sym.Kind != SymbolFunction && sym.Kind != SymbolMethod {
+		return false
+	}
+	switch language {
+	case "go":
+		for _, prefix := range []string{"Test", "Benchmark", "Fuzz", "Example"} {
+			if strings.HasPrefix(sym.Name, prefix) {
+				return sym.Container == ""
+			}
+		}
+		return false
+	case "python", "ruby", "php":
+		return strings.HasPrefix(sym.Name, "test")
+	case "typescript", "javascript":
+		return strings.HasPrefix(sym.Signature, "it(") || strings.HasPrefix(sym.Signature, "test(")
+	case "java", "csharp", "rust":
+		return true
+	}
+	return false
+}
@@ -2,6 +2,7 @@ package parser
 
 import (
 	"encoding/json"
+	"fmt"
 	"strings"
 )
 
@@ -17,6 +18,12 @@ const (
 	SymbolModule
 	SymbolConstant
 	SymbolVariable
+	// SymbolComponent is a UI component, such as a React function or class
+	// component.
+	SymbolComponent
+	// SymbolRoute is an HTTP route registration, named by method and path
+	// ("GET /users/:id"), whose call is the handler it routes to.
+	SymbolRoute
 )
 
 func (k SymbolKind) String() string {
@@ -37,6 +44,10 @@ func (k SymbolKind) String() string {
 		return "const"
 	case SymbolVariable:
 		return "var"
+	case SymbolComponent:
+		return "component"
+	case SymbolRoute:
+		return "route"
 	default:
 		return "unknown"
 	}
@@ -50,6 +61,55 @@ type CallSite struct {
 	Arity     int    `json:"arity,omitempty"`
 	Line      int    `json:"line,omitempty"`
 	Raw       string `json:"raw,omitempty"`
+	// ReceiverType is the declared type of the receiver when the parser can
+	// tell it from local declarations ("Writer", or "csv.Writer" for an
+	// imported type).
+	ReceiverType string `json:"receiver_type,omitempty"`
+}
+
+// Relations between a type and the supertypes it declares.
+const (
+	// RelationInherit covers superclasses, extended interfaces and Ruby
+	// mixins (include, extend, prepend).
+	RelationInherit = "inherit"
+	// RelationImplement covers interfaces a class implements (TypeScript).
+	RelationImplement = "implement"
+)
+
+// TypeRelation is a supertype named in a class or interface declaration.
+type TypeRelation struct {
+	Name     string `json:"name"` // as written, without type arguments: "Base", "models.Model", "Admin::Base"
+	Relation string `json:"relation"`
+}
+
+// Symbol visibilities, following each language's convention: Go
+// capitalization, TypeScript exports and accessibility modifiers, Python's
+// leading underscore and Ruby's private and protected sections.
+const (
+	VisibilityPublic    = "public"
+	VisibilityProtected = "protected"
+	VisibilityPrivate   = "private"
+)
+
+// ParseVisibilities parses --visibility values into a set, nil when there
+// are none.
+func ParseVisibilities(values []string) (map[string]bool, error) {
+	var visibilities map[string]bool
+	for _, value := range values {
+		value = strings.ToLower(strings.TrimSpace(value))
+		switch value {
+		case "":
+			continue
+		case VisibilityPublic, VisibilityProtected, VisibilityPrivate:
+		default:
+			return nil, fmt.Errorf("unknown visibility %q (use public, protected or private)", value)
+		}
+		if visibilities == nil {
+			visibilities = make(map[string]bool)
+		}
+		visibilities[value] = true
+	}
+	return visibilities, nil
 }
 
 // Symbol represents a code symbol (function, class, etc.)
@@ -58,13 +118,108 @@ type Symbol struct {
 	Name      string
 	Kind      SymbolKind
 	Signature string // e.g., "func(ctx context.Context, id string) (*User, error)"
-	File      string // relative file path
-	Line      int    // line number
-	Doc       string // docstring/comment if available
+	// Shape is the type-only form of a Go function or method signature
+	// ("func (*Server) Handle(http.ResponseWriter) error"), which signature
+	// search matches besides Signature; empty for other symbols.
+	Shape string
+	File  string // relative file path
+	Line  int    // line number
+	Doc   string // docstring/comment if available
+	// Span is where the declaration ends and its byte range in the file.
+	Span
+	// Container is the enclosing type, class or module in the language's own
+	// notation ("User", "Billing::Invoice"), empty for top-level symbols.
+	Container string
+	// Visibility is VisibilityPublic, VisibilityProtected or
+	// VisibilityPrivate, empty when the parser does not tell.
+	Visibility string
+	// Decorators lists the decorators applied to the symbol as written
+	// ("@Get(':id')"), in source order.
+	Decorators []string
+	// Methods lists the method names a Go interface declares.
+	Methods []string
+	// Bases lists the superclasses, mixins and interfaces a class or
+	// interface declares, in declaration order.
+	Bases []TypeRelation
+	// Renders lists the components a symbol renders as JSX elements
+	// ("Button", "UI.Card"), in first-seen order; DOM elements are left out.
+	Renders []string
+	// References lists the types the symbol mentions without calling them
+	// (parameter, result, field and variable types), as written and without
+	// type arguments, in first-seen order.
+	References []string
+	// Prototype marks a declaration without a body, such as a C function
+	// prototype in a header. Call resolution prefers a definition of the
+	// same name when there is one.
+	Prototype bool
 	Calls     []CallSite
 	CalledBy  []string // symbols that call this one
 }
 
+// Span locates a symbol's declaration in its source file beyond its start
+// line: the line it ends on and its byte range, from the first byte of the
+// declaration to just past its last. Zero values mean the parser did not
+// record a span, as in state written by older versions.
+type Span struct {
+	EndLine   int `json:",omitempty"`
+	StartByte int `json:",omitempty"`
+	EndByte   int `json:",omitempty"`
+}
+
+// Body returns the declaration's source from content, the file the symbol
+// was parsed from, or "" when the span is missing or out of range.
+func (s Symbol) Body(content []byte) string {
+	if s.EndByte <= s.StartByte || s.EndByte > len(content) {
+		return ""
+	}
+	return string(content[s.StartByte:s.EndByte])
+}
+
+// QualifiedName returns Container.Name, or Name for top-level symbols, so
+// User.save and Order.save stay apart.
+func (s Symbol) QualifiedName() string {
+	if s.Container == "" {
+		return s.Name
+	}
+	return s.Container + "." + s.Name
+}
+
+// QualifiedNames returns the qualified names a symbol can be looked up by:
+// QualifiedName and, when the container is nested or namespaced, the
+// innermost type plus the name (Invoice.save for Billing::Invoice). Ruby
+// singleton methods (self.find) are also listed as Type.find. It returns nil
+// for top-level symbols.
+func (s Symbol) QualifiedNames() []string {
+	if s.Container == "" {
+		return nil
+	}
+	names := []string{s.QualifiedName()}
+	add := func(name string) {
+		for _, existing := range names {
+			if existing == name {
+				return
+			}
+		}
+		names = append(names, name)
+	}
+	inner := InnermostName(s.Container)
+	add(inner + "." + s.Name)
+	if bare, ok := strings.CutPrefix(s.Name, "self."); ok {
+		add(s.Container + "." + bare)
+		add(inner + "." + bare)
+	}
+	return names
+}
+
+// InnermostName returns the last segment of a qualified type or namespace
+// name written with ".", "::", "\\" or "/" separators.
+func InnermostName(name string) string {
+	if idx := strings.LastIndexAny(name, ".:\\/"); idx != -1 {
+		return name[idx+1:]
+	}
+	return name
+}
+
 // UnmarshalJSON supports both legacy []string call payloads and the newer []CallSite shape.
 func (s *Symbol) UnmarshalJSON(data []byte) error {
 	type wireSymbol struct {
@@ -72,11 +227,21 @@ func (s *Symbol) UnmarshalJSON(data []byte) error {
 		Name      string
 		Kind      SymbolKind
 		Signature string
+		Shape     string
 		File      string
 		Line      int
-		Doc       string
-		Calls     json.RawMessage
-		CalledBy  []string
+		Span
+		Doc        string
+		Container  string
+		Visibility string
+		Decorators []string
+		Methods    []string
+		Bases      []TypeRelation
+		Renders    []string
+		References []string
+		Prototype  bool
+		Calls      json.RawMessage
+		CalledBy   []string
 	}
 
 	var wire wireSymbol
@@ -88,9 +253,19 @@ func (s *Symbol) UnmarshalJSON(data []byte) error {
 	s.Name = wire.Name
 	s.Kind = wire.Kind
 	s.Signature = wire.Signature
+	s.Shape = wire.Shape
 	s.File = wire.File
 	s.Line = wire.Line
+	s.Span = wire.Span
 	s.Doc = wire.Doc
+	s.Container = wire.Container
+	s.Visibility = wire.Visibility
+	s.Decorators = wire.Decorators
+	s.Methods = wire.Methods
+	s.Bases = wire.Bases
+	s.Renders = wire.Renders
+	s.References = wire.References
+	s.Prototype = wire.Prototype
 	s.CalledBy = wire.CalledBy
 
 	rawCalls := strings.TrimSpace(string(wire.Calls))
@@ -128,6 +303,17 @@ type FileSymbols struct {
 	Imports       []string          // imported modules/packages
 	ImportAliases map[string]string // alias -> import target (module/package path, optionally module#symbol)
 	Hash          string            // file content hash for incremental updates
+	Lines         int               // line count, for per-module LOC
+	// Generated is set for files whose header carries a generated-code
+	// marker ("Code generated ... DO NOT EDIT.", "@generated").
+	Generated bool
+	// BuildConstraint is the file's Go build constraint expression
+	// ("linux && !cgo", "ignore"), from //go:build or legacy // +build lines.
+	BuildConstraint string
+	// External is set for vendored, third-party and generated-directory
+	// code (see IsExternalPath) and for files matching the project's
+	// external globs.
+	External bool
 }
 
 // ParseIssue captures non-fatal parser warnings/errors encountered while scanning files.
@@ -135,7 +321,9 @@ type ParseIssue struct {
 	File     string `json:"file"`
 	Language string `json:"language,omitempty"`
 	Severity string `json:"severity"` // warning | error
-	Message  string `json:"message"`
+	// Reason is set for files skipped on purpose (too_large, binary).
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message"`
 }
 
 // ParseResult holds the complete parse result for a codebase
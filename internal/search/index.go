@@ -17,10 +17,13 @@ import (
 
 const (
 	IndexFile = "search-index.json"
-	Version   = "search-index-v1"
+	Version   = "search-index-v2"
 )
 
-var tokenPattern = regexp.MustCompile(`[a-z0-9_]+`)
+var (
+	tokenPattern = regexp.MustCompile(`[a-z0-9_]+`)
+	wordPattern  = regexp.MustCompile(`[A-Za-z0-9_]+`)
+)
 
 type Document struct {
 	ID        string         `json:"id"`
@@ -58,29 +61,13 @@ func Build(g *graph.Graph) *Index {
 
 	for _, file := range g.Files() {
 		for _, node := range g.NodesForFile(file) {
-			terms := buildTerms(node.Symbol.Name, node.Symbol.Signature, node.File, node.Symbol.Doc)
-			length := 0
-			for _, count := range terms {
-				length += count
-			}
-			if length == 0 {
+			document, ok := newDocument(node)
+			if !ok {
 				continue
 			}
-
-			documents = append(documents, Document{
-				ID:        node.ID,
-				Name:      node.Symbol.Name,
-				Kind:      node.Symbol.Kind.String(),
-				Signature: node.Symbol.Signature,
-				File:      node.File,
-				Line:      node.Symbol.Line,
-				Doc:       node.Symbol.Doc,
-				Length:    length,
-				Terms:     terms,
-			})
-			totalLength += length
-
-			for term := range terms {
+			documents = append(documents, document)
+			totalLength += document.Length
+			for term := range document.Terms {
 				docFreq[term]++
 			}
 		}
@@ -104,19 +91,139 @@ func Build(g *graph.Graph) *Index {
 	}
 }
 
-func Write(contextDir string, g *graph.Graph) error {
-	index := Build(g)
-	data, err := json.MarshalIndent(index, "", "  ")
+// newDocument returns the search document of a node; false when it has no
+// searchable terms.
+func newDocument(node *graph.Node) (Document, bool) {
+	terms := buildTerms(node.Symbol.Name, node.Symbol.Signature, node.File, node.Symbol.Doc)
+	length := 0
+	for _, count := range terms {
+		length += count
+	}
+	if length == 0 {
+		return Document{}, false
+	}
+	return Document{
+		ID:        node.ID,
+		Name:      node.Symbol.Name,
+		Kind:      node.Symbol.Kind.String(),
+		Signature: node.Symbol.Signature,
+		File:      node.File,
+		Line:      node.Symbol.Line,
+		Doc:       node.Symbol.Doc,
+		Length:    length,
+		Terms:     terms,
+	}, true
+}
+
+// Write writes the search index as Build would. Documents of files whose
+// fingerprint matches previous are copied from the index on disk, and the
+// document frequencies are patched with the ones that changed; it returns
+// the fingerprint of every file's documents.
+func Write(contextDir string, g *graph.Graph, previous fileutil.Segments) (map[string]string, error) {
+	path := filepath.Join(contextDir, IndexFile)
+	data, onDisk := previous.Load(path, "documents")
+	header := Index{Version: Version, DocFreq: map[string]int{}, Documents: []Document{}}
+	totalLength := 0
+	if onDisk != nil {
+		if err := json.Unmarshal(fileutil.EmptySegments(data, "documents"), &header); err != nil || header.Version != Version {
+			onDisk = nil
+			header = Index{Version: Version, DocFreq: map[string]int{}, Documents: []Document{}}
+		} else {
+			totalLength = int(math.Round(header.AvgDocLength * float64(header.DocumentCount)))
+			if header.DocFreq == nil {
+				header.DocFreq = map[string]int{}
+			}
+		}
+	}
+	count := func(documents []Document, sign int) {
+		for _, document := range documents {
+			header.DocumentCount += sign
+			totalLength += sign * document.Length
+			for term := range document.Terms {
+				header.DocFreq[term] += sign
+				if header.DocFreq[term] == 0 {
+					delete(header.DocFreq, term)
+				}
+			}
+		}
+	}
+
+	ids := make([]string, 0, len(g.Nodes))
+	for id := range g.Nodes {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	fingerprints := make(map[string]string)
+	segments := make([][]byte, 0)
+	reused := make(map[string]bool)
+	for start := 0; start < len(ids); {
+		file := fileutil.SegmentFile(ids[start])
+		end := start + 1
+		for end < len(ids) && fileutil.SegmentFile(ids[end]) == file {
+			end++
+		}
+		group := ids[start:end]
+		start = end
+
+		fingerprint := fileutil.NewFingerprint()
+		for _, id := range group {
+			node := g.Nodes[id]
+			fingerprint.String(node.ID, node.Symbol.Name, node.Symbol.Kind.String(), node.Symbol.Signature, node.File, node.Symbol.Doc)
+			fingerprint.Int(node.Symbol.Line)
+		}
+		sum := fingerprint.Sum()
+		if _, seen := fingerprints[file]; seen {
+			sum = ""
+		}
+		fingerprints[file] = sum
+		if segment, ok := previous.Reuse(onDisk, file, sum); ok {
+			reused[file] = true
+			segments = append(segments, segment)
+			continue
+		}
+		documents := make([]Document, 0, len(group))
+		for _, id := range group {
+			if document, ok := newDocument(g.Nodes[id]); ok {
+				documents = append(documents, document)
+			}
+		}
+		count(documents, 1)
+		segment, err := fileutil.EncodeSegment(documents)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode search index: %w", err)
+		}
+		segments = append(segments, segment)
+	}
+	// The documents of files re-encoded or gone leave the frequencies.
+	for file, segment := range onDisk {
+		if reused[file] {
+			continue
+		}
+		var documents []Document
+		if err := json.Unmarshal([]byte("["+string(segment)+"]"), &documents); err != nil {
+			return Write(contextDir, g, fileutil.Segments{})
+		}
+		count(documents, -1)
+	}
+
+	header.AvgDocLength = 0
+	if header.DocumentCount > 0 {
+		header.AvgDocLength = float64(totalLength) / float64(header.DocumentCount)
+	}
+	encoded, err := json.MarshalIndent(header, "", "  ")
 	if err != nil {
-		return fmt.Errorf("failed to encode search index: %w", err)
+		return nil, fmt.Errorf("failed to encode search index: %w", err)
 	}
-	data = append(data, '\n')
-	return fileutil.WriteIfChanged(filepath.Join(contextDir, IndexFile), data)
+	encoded = append(fileutil.SpliceSegments(encoded, "documents", segments), '\n')
+	if err := fileutil.WriteArtifact(path, encoded); err != nil {
+		return nil, err
+	}
+	return fingerprints, nil
 }
 
 func Load(rootPath string) (*Index, error) {
 	path := filepath.Join(rootPath, output.ContextDir, IndexFile)
-	data, err := os.ReadFile(path)
+	data, err := fileutil.ReadArtifact(path)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return nil, fmt.Errorf("search index missing at %s (run skelly update)", path)
@@ -128,6 +235,9 @@ func Load(rootPath string) (*Index, error) {
 	if err := json.Unmarshal(data, &index); err != nil {
 		return nil, fmt.Errorf("failed to decode search index: %w", err)
 	}
+	if index.Version != Version {
+		return nil, fmt.Errorf("search index version %q is outdated (run skelly generate)", index.Version)
+	}
 	if index.DocFreq == nil {
 		index.DocFreq = map[string]int{}
 	}
@@ -142,7 +252,91 @@ func Search(index *Index, query string, limit int) []Result {
 		limit = 10
 	}
 
-	queryTerms := tokenize(query)
+	results := scoreBM25(index, query)
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].ID < results[j].ID
+	})
+
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	if len(results) == 0 {
+		fallback := fuzzyNameFallback(index.Documents, query, limit)
+		if len(fallback) > 0 {
+			return fallback
+		}
+	}
+	return results
+}
+
+const (
+	// exactNameWeight is added when the query is a symbol's name, ignoring case.
+	exactNameWeight = 2.0
+	// fuzzyNameWeight scales the typo score of symbols without a BM25 match,
+	// so they rank below every lexical match.
+	fuzzyNameWeight = 0.5
+)
+
+// Hit is a Hybrid result with the signals behind its score.
+type Hit struct {
+	ID    string  `json:"id"`
+	Score float64 `json:"score"`
+	Exact bool    `json:"exact,omitempty"`
+	BM25  float64 `json:"bm25,omitempty"`
+	Fuzzy float64 `json:"fuzzy,omitempty"`
+}
+
+// Hybrid scores every document for query by an exact name match (+2), BM25
+// scaled to the best match (up to +1) and, for documents BM25 misses, name
+// edit distance (up to +0.25). Hits are sorted best first, unlimited.
+func Hybrid(index *Index, query string) []Hit {
+	if index == nil || len(index.Documents) == 0 {
+		return nil
+	}
+	bm25 := make(map[string]float64)
+	maxBM25 := 0.0
+	for _, result := range scoreBM25(index, query) {
+		bm25[result.ID] = result.Score
+		maxBM25 = math.Max(maxBM25, result.Score)
+	}
+	needle := normalizeForFuzzy(query)
+	trimmed := strings.TrimSpace(query)
+
+	hits := make([]Hit, 0)
+	for _, doc := range index.Documents {
+		hit := Hit{ID: doc.ID, BM25: bm25[doc.ID]}
+		if trimmed != "" && strings.EqualFold(doc.Name, trimmed) {
+			hit.Exact = true
+			hit.Score += exactNameWeight
+		}
+		if hit.BM25 > 0 {
+			hit.Score += hit.BM25 / maxBM25
+		} else if !hit.Exact && needle != "" {
+			if distance, ok := fuzzyNameDistance(needle, doc.Name); ok {
+				hit.Fuzzy = 1.0 / float64(1+distance)
+				hit.Score += fuzzyNameWeight * hit.Fuzzy
+			}
+		}
+		if hit.Score > 0 {
+			hits = append(hits, hit)
+		}
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].ID < hits[j].ID
+	})
+	return hits
+}
+
+// scoreBM25 returns the positive BM25 score of each document for query, in
+// document order.
+func scoreBM25(index *Index, query string) []Result {
+	queryTerms := tokenizeQuery(index, query)
 	if len(queryTerms) == 0 {
 		return nil
 	}
@@ -187,23 +381,6 @@ func Search(index *Index, query string, limit int) []Result {
 			results = append(results, Result{ID: doc.ID, Score: score})
 		}
 	}
-
-	sort.Slice(results, func(i, j int) bool {
-		if results[i].Score != results[j].Score {
-			return results[i].Score > results[j].Score
-		}
-		return results[i].ID < results[j].ID
-	})
-
-	if len(results) > limit {
-		results = results[:limit]
-	}
-	if len(results) == 0 {
-		fallback := fuzzyNameFallback(index.Documents, query, limit)
-		if len(fallback) > 0 {
-			return fallback
-		}
-	}
 	return results
 }
 
@@ -225,14 +402,80 @@ func addWeighted(terms map[string]int, value string, weight int) {
 	}
 }
 
+// tokenize returns each word of value lowercased and, for compound
+// identifiers, its camelCase, snake_case and digit-boundary parts too, so
+// HTTPServerConfig matches "server" as well as "httpserverconfig".
 func tokenize(value string) []string {
-	value = strings.ToLower(value)
-	if value == "" {
+	words := wordPattern.FindAllString(value, -1)
+	if len(words) == 0 {
 		return nil
 	}
-	return tokenPattern.FindAllString(value, -1)
+	tokens := make([]string, 0, len(words))
+	for _, word := range words {
+		tokens = append(tokens, strings.ToLower(word))
+		if parts := splitIdentifier(word); len(parts) > 1 {
+			tokens = append(tokens, parts...)
+		}
+	}
+	return tokens
+}
+
+// tokenizeQuery tokenizes query like tokenize, except that a compound
+// word's parts are only kept when the index knows every one of them: with a
+// typo in one part (SaevAll) the others would match unrelated symbols and
+// hide the typo-tolerant match of the whole word.
+func tokenizeQuery(index *Index, query string) []string {
+	words := wordPattern.FindAllString(query, -1)
+	tokens := make([]string, 0, len(words))
+	for _, word := range words {
+		tokens = append(tokens, strings.ToLower(word))
+		parts := splitIdentifier(word)
+		if len(parts) < 2 {
+			continue
+		}
+		known := true
+		for _, part := range parts {
+			if index.DocFreq[part] == 0 {
+				known = false
+				break
+			}
+		}
+		if known {
+			tokens = append(tokens, parts...)
+		}
+	}
+	return tokens
 }
 
+// splitIdentifier splits a word at underscores, lower-to-upper case changes,
+// the last capital of an acronym (HTTPServer) and letter/digit boundaries,
+// returning the lowercased parts.
+func splitIdentifier(word string) []string {
+	parts := make([]string, 0)
+	for _, piece := range strings.Split(word, "_") {
+		runes := []rune(piece)
+		start := 0
+		for i := 1; i < len(runes); i++ {
+			prev, cur := runes[i-1], runes[i]
+			boundary := isLower(prev) && isUpper(cur) ||
+				isUpper(prev) && isUpper(cur) && i+1 < len(runes) && isLower(runes[i+1]) ||
+				isDigit(prev) != isDigit(cur)
+			if boundary {
+				parts = append(parts, strings.ToLower(string(runes[start:i])))
+				start = i
+			}
+		}
+		if start < len(runes) {
+			parts = append(parts, strings.ToLower(string(runes[start:])))
+		}
+	}
+	return parts
+}
+
+func isLower(r rune) bool { return r >= 'a' && r <= 'z' }
+func isUpper(r rune) bool { return r >= 'A' && r <= 'Z' }
+func isDigit(r rune) bool { return r >= '0' && r <= '9' }
+
 func fuzzyNameFallback(documents []Document, query string, limit int) []Result {
 	needle := normalizeForFuzzy(query)
 	if needle == "" {
@@ -241,16 +484,8 @@ func fuzzyNameFallback(documents []Document, query string, limit int) []Result {
 
 	results := make([]Result, 0)
 	for _, doc := range documents {
-		candidate := normalizeForFuzzy(doc.Name)
-		if candidate == "" {
-			continue
-		}
-		distance := levenshteinDistance(needle, candidate)
-		threshold := len(candidate) / 3
-		if threshold < 2 {
-			threshold = 2
-		}
-		if distance > threshold {
+		distance, ok := fuzzyNameDistance(needle, doc.Name)
+		if !ok {
 			continue
 		}
 		results = append(results, Result{ID: doc.ID, Score: 1.0 / float64(1+distance)})
@@ -268,8 +503,23 @@ func fuzzyNameFallback(documents []Document, query string, limit int) []Result {
 	return results
 }
 
+// fuzzyNameDistance returns the edit distance between a normalized query and
+// a symbol name, and whether it is close enough to count as a typo.
+func fuzzyNameDistance(needle, name string) (int, bool) {
+	candidate := normalizeForFuzzy(name)
+	if candidate == "" {
+		return 0, false
+	}
+	distance := levenshteinDistance(needle, candidate)
+	threshold := len(candidate) / 3
+	if threshold < 2 {
+		threshold = 2
+	}
+	return distance, distance <= threshold
+}
+
 func normalizeForFuzzy(value string) string {
-	tokens := tokenize(value)
+	tokens := tokenPattern.FindAllString(strings.ToLower(value), -1)
 	if len(tokens) == 0 {
 		return ""
 	}
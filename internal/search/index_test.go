@@ -1,6 +1,7 @@
 package search
 
 import (
+	"reflect"
 	"testing"
 
 	"github.com/morozRed/skelly/internal/graph"
@@ -73,3 +74,98 @@ func TestSearchDeterministicOrdering(t *testing.T) {
 		t.Fatalf("expected stable tie-break by id, got %#v", results)
 	}
 }
+
+func TestHybridRanksExactNameThenBM25ThenTypos(t *testing.T) {
+	parseResult := &parser.ParseResult{
+		RootPath: ".",
+		Files: []parser.FileSymbols{
+			{
+				Path:     "store/store.go",
+				Language: "go",
+				Symbols: []parser.Symbol{
+					{ID: "id-save", Name: "Save", Kind: parser.SymbolFunction, Signature: "func Save(path string) error", Doc: "Save writes the store to disk."},
+					{ID: "id-saveall", Name: "SaveAll", Kind: parser.SymbolFunction, Signature: "func SaveAll(paths []string) error", Doc: "SaveAll calls save for every store."},
+					{ID: "id-sav", Name: "Sav", Kind: parser.SymbolFunction, Signature: "func Sav()"},
+					{ID: "id-load", Name: "Load", Kind: parser.SymbolFunction, Signature: "func Load(path string) error"},
+				},
+			},
+		},
+	}
+
+	hits := Hybrid(Build(graph.BuildFromParseResult(parseResult)), "save")
+	if len(hits) != 3 {
+		t.Fatalf("expected exact, lexical and typo hits only, got %#v", hits)
+	}
+	if hits[0].ID != "id-save" || !hits[0].Exact || hits[0].Score <= 2 {
+		t.Fatalf("expected the exact name to rank first, got %#v", hits[0])
+	}
+	if hits[1].ID != "id-saveall" || hits[1].BM25 == 0 {
+		t.Fatalf("expected the BM25 match second, got %#v", hits[1])
+	}
+	if hits[2].ID != "id-sav" || hits[2].Fuzzy == 0 || hits[2].Score >= hits[1].Score {
+		t.Fatalf("expected the typo match last, got %#v", hits[2])
+	}
+}
+
+func TestTokenizeSplitsCompoundIdentifiers(t *testing.T) {
+	cases := map[string][]string{
+		"HTTPServerConfig": {"httpserverconfig", "http", "server", "config"},
+		"parse_directory":  {"parse_directory", "parse", "directory"},
+		"utf8Decode(buf)":  {"utf8decode", "utf", "8", "decode", "buf"},
+		"server":           {"server"},
+	}
+	for input, want := range cases {
+		if got := tokenize(input); !reflect.DeepEqual(got, want) {
+			t.Fatalf("tokenize(%q) = %v, want %v", input, got, want)
+		}
+	}
+}
+
+func TestSearchMatchesCamelCaseParts(t *testing.T) {
+	parseResult := &parser.ParseResult{
+		RootPath: ".",
+		Files: []parser.FileSymbols{
+			{
+				Path:     "config.go",
+				Language: "go",
+				Symbols: []parser.Symbol{
+					{ID: "id-1", Name: "HTTPServerConfig", Kind: parser.SymbolStruct},
+					{ID: "id-2", Name: "ClientOptions", Kind: parser.SymbolStruct},
+				},
+			},
+		},
+	}
+
+	results := Search(Build(graph.BuildFromParseResult(parseResult)), "server", 5)
+	if len(results) != 1 || results[0].ID != "id-1" {
+		t.Fatalf("expected a BM25 match for a camelCase part, got %#v", results)
+	}
+}
+
+func TestSearchIgnoresPartsOfMisspelledCompoundQueries(t *testing.T) {
+	parseResult := &parser.ParseResult{
+		RootPath: ".",
+		Files: []parser.FileSymbols{
+			{
+				Path:     "store.go",
+				Language: "go",
+				Symbols: []parser.Symbol{
+					{ID: "id-2", Name: "SaveAll", Kind: parser.SymbolFunction},
+					{ID: "id-1", Name: "LoadAll", Kind: parser.SymbolFunction},
+				},
+			},
+		},
+	}
+	index := Build(graph.BuildFromParseResult(parseResult))
+
+	results := Search(index, "SaevAll", 5)
+	if len(results) != 1 || results[0].ID != "id-2" {
+		t.Fatalf("expected the typo to fall back to SaveAll, got %#v", results)
+	}
+	if results = Search(index, "LoadAll", 5); len(results) == 0 || results[0].ID != "id-1" {
+		t.Fatalf("expected a correctly spelled compound query to match, got %#v", results)
+	}
+	if got := tokenizeQuery(index, "SaveLoad"); !reflect.DeepEqual(got, []string{"saveload", "save", "load"}) {
+		t.Fatalf("expected the parts of a known compound to be kept, got %v", got)
+	}
+}
@@ -0,0 +1,364 @@
+package snapshot
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/morozRed/skelly/internal/fileutil"
+	"github.com/morozRed/skelly/internal/graph"
+	"github.com/morozRed/skelly/internal/parser"
+	"github.com/morozRed/skelly/internal/state"
+)
+
+// ChangeReport is the symbol- and edge-level diff from snapshot A (before)
+// to B (after). Symbols are matched by file, kind and name, so moving a
+// symbol within its file is not a change.
+type ChangeReport struct {
+	Before           string            `json:"before"`
+	After            string            `json:"after"`
+	Added            []SymbolChange    `json:"added"`
+	Removed          []SymbolChange    `json:"removed"`
+	Renamed          []SymbolRename    `json:"renamed"`
+	SignatureChanged []SignatureChange `json:"signature_changed"`
+	EdgesAdded       []EdgeChange      `json:"edges_added"`
+	EdgesRemoved     []EdgeChange      `json:"edges_removed"`
+}
+
+// SymbolChange is a symbol that exists on only one side.
+type SymbolChange struct {
+	ID        string `json:"id"`
+	File      string `json:"file"`
+	Line      int    `json:"line"`
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Signature string `json:"signature,omitempty"`
+}
+
+// SymbolRename pairs a removed symbol with the added symbol that replaced it.
+// Reason is state.AliasMoved (same name, kind and signature in another file)
+// or state.AliasRenamed (same file and kind, signature differing only by the name).
+type SymbolRename struct {
+	From   SymbolChange `json:"from"`
+	To     SymbolChange `json:"to"`
+	Reason string       `json:"reason"`
+}
+
+// SignatureChange is a symbol whose file, kind and name held but whose
+// signature changed.
+type SignatureChange struct {
+	ID     string `json:"id"`
+	File   string `json:"file"`
+	Kind   string `json:"kind"`
+	Name   string `json:"name"`
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+// EdgeChange is a call edge between two symbols, named "file:Name".
+// Renamed symbols are compared under their new name.
+type EdgeChange struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Changes reports symbols added, removed, renamed or re-signed and call
+// edges added or removed from a to b.
+func Changes(a, b *Snapshot) ChangeReport {
+	report := ChangeReport{
+		Before:           a.Path,
+		After:            b.Path,
+		Added:            make([]SymbolChange, 0),
+		Removed:          make([]SymbolChange, 0),
+		Renamed:          make([]SymbolRename, 0),
+		SignatureChanged: make([]SignatureChange, 0),
+	}
+
+	before, after := symbolsByKey(a.State), symbolsByKey(b.State)
+	removed := make([]parser.Symbol, 0)
+	added := make([]parser.Symbol, 0)
+	// renamedTo maps a before symbol ID to the key it is compared under.
+	renamedTo := make(map[string]string)
+	for _, key := range unionSymbolKeys(before, after) {
+		olds, news := unmatchedSignatures(before[key], after[key])
+		paired := min(len(olds), len(news))
+		for i := 0; i < paired; i++ {
+			report.SignatureChanged = append(report.SignatureChanged, SignatureChange{
+				ID:     news[i].ID,
+				File:   news[i].File,
+				Kind:   news[i].Kind.String(),
+				Name:   news[i].Name,
+				Before: olds[i].Signature,
+				After:  news[i].Signature,
+			})
+		}
+		removed = append(removed, olds[paired:]...)
+		added = append(added, news[paired:]...)
+	}
+
+	targets := make([]renameCandidate, len(removed))
+	claims := make(map[string]int)
+	for i, old := range removed {
+		targets[i] = renameTarget(old, added)
+		if targets[i].ok {
+			claims[targets[i].symbol.ID]++
+		}
+	}
+	matched := make(map[string]bool)
+	for i, old := range removed {
+		next := targets[i]
+		// A symbol that could replace several removed ones stays added.
+		if !next.ok || claims[next.symbol.ID] > 1 {
+			report.Removed = append(report.Removed, symbolChange(old))
+			continue
+		}
+		matched[next.symbol.ID] = true
+		renamedTo[old.ID] = edgeName(next.symbol)
+		report.Renamed = append(report.Renamed, SymbolRename{
+			From:   symbolChange(old),
+			To:     symbolChange(next.symbol),
+			Reason: next.reason,
+		})
+	}
+	for _, sym := range added {
+		if !matched[sym.ID] {
+			report.Added = append(report.Added, symbolChange(sym))
+		}
+	}
+	sortSymbolChanges(report.Added)
+	sortSymbolChanges(report.Removed)
+	sort.Slice(report.Renamed, func(i, j int) bool {
+		return symbolChangeLess(report.Renamed[i].To, report.Renamed[j].To)
+	})
+
+	beforeEdges := callEdges(a.State, renamedTo)
+	afterEdges := callEdges(b.State, nil)
+	report.EdgesAdded = edgesMissingFrom(afterEdges, beforeEdges)
+	report.EdgesRemoved = edgesMissingFrom(beforeEdges, afterEdges)
+	return report
+}
+
+// Empty reports whether the report has no changes.
+func (r ChangeReport) Empty() bool {
+	return len(r.Added) == 0 && len(r.Removed) == 0 && len(r.Renamed) == 0 &&
+		len(r.SignatureChanged) == 0 && len(r.EdgesAdded) == 0 && len(r.EdgesRemoved) == 0
+}
+
+func symbolKey(sym parser.Symbol) string {
+	return sym.File + "|" + sym.Kind.String() + "|" + sym.Name
+}
+
+func symbolsByKey(st *state.State) map[string][]parser.Symbol {
+	symbols := make(map[string][]parser.Symbol)
+	for file, fileState := range st.Files {
+		for _, sym := range fileState.Symbols {
+			if sym.File == "" {
+				sym.File = file
+			}
+			if sym.ID == "" {
+				sym.ID = parser.StableSymbolID(file, sym)
+			}
+			symbols[symbolKey(sym)] = append(symbols[symbolKey(sym)], sym)
+		}
+	}
+	for key := range symbols {
+		sort.Slice(symbols[key], func(i, j int) bool {
+			return symbols[key][i].Line < symbols[key][j].Line
+		})
+	}
+	return symbols
+}
+
+func unionSymbolKeys(a, b map[string][]parser.Symbol) []string {
+	keys := make([]string, 0, len(a)+len(b))
+	for key := range a {
+		keys = append(keys, key)
+	}
+	for key := range b {
+		if _, ok := a[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// unmatchedSignatures drops symbols whose signature appears on both sides,
+// leaving overloads that changed, in line order.
+func unmatchedSignatures(olds, news []parser.Symbol) ([]parser.Symbol, []parser.Symbol) {
+	remaining := make(map[string]int, len(news))
+	for _, sym := range news {
+		remaining[sym.Signature]++
+	}
+	keptOld := make([]parser.Symbol, 0)
+	for _, sym := range olds {
+		if remaining[sym.Signature] > 0 {
+			remaining[sym.Signature]--
+			continue
+		}
+		keptOld = append(keptOld, sym)
+	}
+
+	consumed := make(map[string]int, len(olds))
+	for _, sym := range olds {
+		consumed[sym.Signature]++
+	}
+	for _, sym := range keptOld {
+		consumed[sym.Signature]--
+	}
+	keptNew := make([]parser.Symbol, 0)
+	for _, sym := range news {
+		if consumed[sym.Signature] > 0 {
+			consumed[sym.Signature]--
+			continue
+		}
+		keptNew = append(keptNew, sym)
+	}
+	return keptOld, keptNew
+}
+
+type renameCandidate struct {
+	symbol parser.Symbol
+	reason string
+	ok     bool
+}
+
+// renameTarget finds the single added symbol that could have replaced old,
+// using the same rules as the state alias forwarding.
+func renameTarget(old parser.Symbol, added []parser.Symbol) renameCandidate {
+	moved := make([]parser.Symbol, 0)
+	renamed := make([]parser.Symbol, 0)
+	for _, sym := range added {
+		if sym.Kind != old.Kind {
+			continue
+		}
+		if sym.Name == old.Name && sym.Signature == old.Signature && sym.File != old.File {
+			moved = append(moved, sym)
+		}
+		if sym.File == old.File && sym.Name != old.Name &&
+			strings.Replace(old.Signature, old.Name, sym.Name, 1) == sym.Signature {
+			renamed = append(renamed, sym)
+		}
+	}
+	switch {
+	case len(moved) == 1:
+		return renameCandidate{symbol: moved[0], reason: state.AliasMoved, ok: true}
+	case len(renamed) == 1:
+		return renameCandidate{symbol: renamed[0], reason: state.AliasRenamed, ok: true}
+	}
+	return renameCandidate{}
+}
+
+func symbolChange(sym parser.Symbol) SymbolChange {
+	return SymbolChange{
+		ID:        sym.ID,
+		File:      sym.File,
+		Line:      sym.Line,
+		Kind:      sym.Kind.String(),
+		Name:      sym.Name,
+		Signature: sym.Signature,
+	}
+}
+
+func symbolChangeLess(a, b SymbolChange) bool {
+	if a.File != b.File {
+		return a.File < b.File
+	}
+	if a.Line != b.Line {
+		return a.Line < b.Line
+	}
+	return a.Name < b.Name
+}
+
+func sortSymbolChanges(changes []SymbolChange) {
+	sort.Slice(changes, func(i, j int) bool {
+		return symbolChangeLess(changes[i], changes[j])
+	})
+}
+
+func edgeName(sym parser.Symbol) string {
+	return sym.File + ":" + sym.Name
+}
+
+// callEdges builds the call graph of st and names each edge by its
+// endpoints, substituting renamed before-side symbols.
+func callEdges(st *state.State, renamedTo map[string]string) map[EdgeChange]bool {
+	hashes := make(map[string]string, len(st.Files))
+	for file, fileState := range st.Files {
+		hashes[file] = fileState.Hash
+	}
+	g := graph.BuildFromParseResult(fileutil.ParseResultFromState(st, "", hashes))
+
+	name := func(node *graph.Node) string {
+		if renamed, ok := renamedTo[node.ID]; ok {
+			return renamed
+		}
+		return node.File + ":" + node.Symbol.Name
+	}
+	edges := make(map[EdgeChange]bool)
+	for _, node := range g.Nodes {
+		for _, targetID := range node.OutEdgesOfKind(graph.EdgeCall) {
+			target, ok := g.Nodes[targetID]
+			if !ok {
+				continue
+			}
+			edges[EdgeChange{From: name(node), To: name(target)}] = true
+		}
+	}
+	return edges
+}
+
+func edgesMissingFrom(edges, other map[EdgeChange]bool) []EdgeChange {
+	missing := make([]EdgeChange, 0)
+	for edge := range edges {
+		if !other[edge] {
+			missing = append(missing, edge)
+		}
+	}
+	sort.Slice(missing, func(i, j int) bool {
+		if missing[i].From != missing[j].From {
+			return missing[i].From < missing[j].From
+		}
+		return missing[i].To < missing[j].To
+	})
+	return missing
+}
+
+// RenderChanges renders the report as a compact text diff: "+" added,
+// "-" removed, "~" signature changed and ">" renamed or moved.
+func RenderChanges(report ChangeReport) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb,
+		"diff %s -> %s: symbols added=%d removed=%d renamed=%d signature_changed=%d edges added=%d removed=%d\n",
+		report.Before, report.After,
+		len(report.Added), len(report.Removed), len(report.Renamed), len(report.SignatureChanged),
+		len(report.EdgesAdded), len(report.EdgesRemoved),
+	)
+	for _, sym := range report.Added {
+		fmt.Fprintf(&sb, "+ %s %s:%d %s\n", sym.Kind, sym.File, sym.Line, symbolLabel(sym))
+	}
+	for _, sym := range report.Removed {
+		fmt.Fprintf(&sb, "- %s %s:%d %s\n", sym.Kind, sym.File, sym.Line, symbolLabel(sym))
+	}
+	for _, rename := range report.Renamed {
+		fmt.Fprintf(&sb, "> %s %s:%s -> %s:%s (%s)\n",
+			rename.To.Kind, rename.From.File, rename.From.Name, rename.To.File, rename.To.Name, rename.Reason)
+	}
+	for _, change := range report.SignatureChanged {
+		fmt.Fprintf(&sb, "~ %s %s:%s %s -> %s\n", change.Kind, change.File, change.Name, change.Before, change.After)
+	}
+	for _, edge := range report.EdgesAdded {
+		fmt.Fprintf(&sb, "+ edge %s -> %s\n", edge.From, edge.To)
+	}
+	for _, edge := range report.EdgesRemoved {
+		fmt.Fprintf(&sb, "- edge %s -> %s\n", edge.From, edge.To)
+	}
+	return sb.String()
+}
+
+func symbolLabel(sym SymbolChange) string {
+	if sym.Signature == "" {
+		return sym.Name
+	}
+	return sym.Name + " " + sym.Signature
+}
@@ -0,0 +1,333 @@
+// Package snapshot compares two indexed context directories and reports
+// structural drift between them.
+package snapshot
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/morozRed/skelly/internal/enrich"
+	"github.com/morozRed/skelly/internal/output"
+	"github.com/morozRed/skelly/internal/state"
+)
+
+// Snapshot is the indexed state and enrich coverage of one context directory.
+type Snapshot struct {
+	Path       string
+	State      *state.State
+	Summarized map[string]bool // symbol IDs with an enrich summary
+}
+
+// Report is the structural drift from snapshot A (before) to B (after).
+type Report struct {
+	Before         string         `json:"before"`
+	After          string         `json:"after"`
+	Files          Delta          `json:"files"`
+	Symbols        Delta          `json:"symbols"`
+	Modules        []ModuleDelta  `json:"modules,omitempty"`
+	Coupling       []CouplingDiff `json:"coupling,omitempty"`
+	NewCycles      [][]string     `json:"new_cycles,omitempty"`
+	ResolvedCycles [][]string     `json:"resolved_cycles,omitempty"`
+	Coverage       CoverageDelta  `json:"summary_coverage"`
+}
+
+// Delta is a before/after count.
+type Delta struct {
+	Before int `json:"before"`
+	After  int `json:"after"`
+}
+
+// ModuleDelta is symbol and file growth for one top-level module.
+type ModuleDelta struct {
+	Module  string `json:"module"`
+	Files   Delta  `json:"files"`
+	Symbols Delta  `json:"symbols"`
+}
+
+// CouplingDiff counts file-level dependencies from one module to another.
+type CouplingDiff struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Links Delta  `json:"links"`
+}
+
+// CoverageDelta is the share of symbols that carry an enrich summary.
+type CoverageDelta struct {
+	Before      float64 `json:"before"`
+	After       float64 `json:"after"`
+	Summarized  Delta   `json:"summarized"`
+	TotalBefore int     `json:"total_before"`
+	TotalAfter  int     `json:"total_after"`
+}
+
+// Load reads a snapshot from a context directory or from a repository root
+// that contains .skelly/.context.
+func Load(path string) (*Snapshot, error) {
+	contextDir := path
+	if _, ok := state.Path(path); !ok {
+		contextDir = filepath.Join(path, output.ContextDir)
+		if _, ok := state.Path(contextDir); !ok {
+			return nil, fmt.Errorf("no %s found in %s or %s", state.StateFile, path, contextDir)
+		}
+	}
+
+	st, err := state.Load(contextDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load state from %s: %w", contextDir, err)
+	}
+	cache, err := enrich.LoadCache(filepath.Join(contextDir, enrich.OutputFile))
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &Snapshot{Path: path, State: st, Summarized: make(map[string]bool)}
+	for _, record := range cache {
+		if strings.TrimSpace(record.Output.Summary) != "" {
+			snapshot.Summarized[record.SymbolID] = true
+		}
+	}
+	return snapshot, nil
+}
+
+// Diff reports structural drift from a to b.
+func Diff(a, b *Snapshot) Report {
+	report := Report{Before: a.Path, After: b.Path}
+	before, after := summarize(a), summarize(b)
+
+	report.Files = Delta{Before: len(a.State.Files), After: len(b.State.Files)}
+	report.Symbols = Delta{Before: before.symbols, After: after.symbols}
+
+	for _, module := range unionKeys(before.moduleSymbols, after.moduleSymbols) {
+		delta := ModuleDelta{
+			Module:  module,
+			Files:   Delta{Before: before.moduleFiles[module], After: after.moduleFiles[module]},
+			Symbols: Delta{Before: before.moduleSymbols[module], After: after.moduleSymbols[module]},
+		}
+		if delta.Files.Before != delta.Files.After || delta.Symbols.Before != delta.Symbols.After {
+			report.Modules = append(report.Modules, delta)
+		}
+	}
+
+	for _, pair := range unionKeys(before.coupling, after.coupling) {
+		if before.coupling[pair] == after.coupling[pair] {
+			continue
+		}
+		from, to, _ := strings.Cut(pair, "\x00")
+		report.Coupling = append(report.Coupling, CouplingDiff{
+			From:  from,
+			To:    to,
+			Links: Delta{Before: before.coupling[pair], After: after.coupling[pair]},
+		})
+	}
+
+	beforeCycles := moduleCycles(before.coupling)
+	afterCycles := moduleCycles(after.coupling)
+	report.NewCycles = cyclesMissingFrom(afterCycles, beforeCycles)
+	report.ResolvedCycles = cyclesMissingFrom(beforeCycles, afterCycles)
+
+	report.Coverage = CoverageDelta{
+		Summarized:  Delta{Before: before.summarized, After: after.summarized},
+		TotalBefore: before.symbols,
+		TotalAfter:  after.symbols,
+		Before:      share(before.summarized, before.symbols),
+		After:       share(after.summarized, after.symbols),
+	}
+	return report
+}
+
+type snapshotSummary struct {
+	symbols       int
+	summarized    int
+	moduleFiles   map[string]int
+	moduleSymbols map[string]int
+	coupling      map[string]int // "from\x00to" -> file dependency count
+}
+
+func summarize(s *Snapshot) snapshotSummary {
+	summary := snapshotSummary{
+		moduleFiles:   make(map[string]int),
+		moduleSymbols: make(map[string]int),
+		coupling:      make(map[string]int),
+	}
+	for file, fileState := range s.State.Files {
+		module := ModuleName(file)
+		summary.moduleFiles[module]++
+		summary.moduleSymbols[module] += len(fileState.Symbols)
+		summary.symbols += len(fileState.Symbols)
+		for _, symbol := range fileState.Symbols {
+			if s.Summarized[symbol.ID] {
+				summary.summarized++
+			}
+		}
+		for _, dependency := range fileState.Dependencies {
+			if target := ModuleName(dependency); target != module {
+				summary.coupling[module+"\x00"+target]++
+			}
+		}
+	}
+	return summary
+}
+
+// ModuleName groups files by their top-level directory, matching the
+// modules/ artifacts ("root" for files at the repository root).
+func ModuleName(file string) string {
+	dir := filepath.ToSlash(filepath.Dir(file))
+	if dir == "." {
+		return "root"
+	}
+	return strings.Split(dir, "/")[0]
+}
+
+// moduleCycles returns each strongly connected group of two or more modules,
+// sorted, using Tarjan's algorithm over the module coupling graph.
+func moduleCycles(coupling map[string]int) [][]string {
+	adjacency := make(map[string][]string)
+	for pair := range coupling {
+		from, to, _ := strings.Cut(pair, "\x00")
+		adjacency[from] = append(adjacency[from], to)
+		if _, ok := adjacency[to]; !ok {
+			adjacency[to] = nil
+		}
+	}
+	modules := make([]string, 0, len(adjacency))
+	for module := range adjacency {
+		sort.Strings(adjacency[module])
+		modules = append(modules, module)
+	}
+	sort.Strings(modules)
+
+	index := 0
+	indices := make(map[string]int)
+	lowlink := make(map[string]int)
+	onStack := make(map[string]bool)
+	stack := make([]string, 0)
+	cycles := make([][]string, 0)
+
+	var connect func(module string)
+	connect = func(module string) {
+		indices[module] = index
+		lowlink[module] = index
+		index++
+		stack = append(stack, module)
+		onStack[module] = true
+
+		for _, next := range adjacency[module] {
+			if _, seen := indices[next]; !seen {
+				connect(next)
+				lowlink[module] = min(lowlink[module], lowlink[next])
+			} else if onStack[next] {
+				lowlink[module] = min(lowlink[module], indices[next])
+			}
+		}
+
+		if lowlink[module] != indices[module] {
+			return
+		}
+		component := make([]string, 0)
+		for {
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[top] = false
+			component = append(component, top)
+			if top == module {
+				break
+			}
+		}
+		if len(component) > 1 {
+			sort.Strings(component)
+			cycles = append(cycles, component)
+		}
+	}
+	for _, module := range modules {
+		if _, seen := indices[module]; !seen {
+			connect(module)
+		}
+	}
+
+	sort.Slice(cycles, func(i, j int) bool {
+		return strings.Join(cycles[i], ",") < strings.Join(cycles[j], ",")
+	})
+	return cycles
+}
+
+func cyclesMissingFrom(cycles, other [][]string) [][]string {
+	known := make(map[string]bool, len(other))
+	for _, cycle := range other {
+		known[strings.Join(cycle, ",")] = true
+	}
+	missing := make([][]string, 0)
+	for _, cycle := range cycles {
+		if !known[strings.Join(cycle, ",")] {
+			missing = append(missing, cycle)
+		}
+	}
+	return missing
+}
+
+func unionKeys(a, b map[string]int) []string {
+	keys := make([]string, 0, len(a)+len(b))
+	for key := range a {
+		keys = append(keys, key)
+	}
+	for key := range b {
+		if _, ok := a[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func share(part, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(part) / float64(total)
+}
+
+// RenderMarkdown renders the report for release notes or architecture reviews.
+func RenderMarkdown(report Report) string {
+	var sb strings.Builder
+	sb.WriteString("# Structural Drift\n\n")
+	fmt.Fprintf(&sb, "From `%s` to `%s`.\n\n", report.Before, report.After)
+	fmt.Fprintf(&sb, "- Files: %s\n", formatDelta(report.Files))
+	fmt.Fprintf(&sb, "- Symbols: %s\n", formatDelta(report.Symbols))
+	fmt.Fprintf(&sb, "- Summary coverage: %.1f%% -> %.1f%% (%d/%d -> %d/%d symbols)\n",
+		report.Coverage.Before*100, report.Coverage.After*100,
+		report.Coverage.Summarized.Before, report.Coverage.TotalBefore,
+		report.Coverage.Summarized.After, report.Coverage.TotalAfter)
+
+	sb.WriteString("\n## Module Growth\n\n")
+	if len(report.Modules) == 0 {
+		sb.WriteString("- (no module changes)\n")
+	}
+	for _, module := range report.Modules {
+		fmt.Fprintf(&sb, "- `%s`: symbols %s, files %s\n", module.Module, formatDelta(module.Symbols), formatDelta(module.Files))
+	}
+
+	sb.WriteString("\n## Module Coupling\n\n")
+	if len(report.Coupling) == 0 {
+		sb.WriteString("- (no coupling changes)\n")
+	}
+	for _, coupling := range report.Coupling {
+		fmt.Fprintf(&sb, "- `%s` -> `%s`: %s file dependencies\n", coupling.From, coupling.To, formatDelta(coupling.Links))
+	}
+
+	sb.WriteString("\n## Dependency Cycles\n\n")
+	if len(report.NewCycles) == 0 && len(report.ResolvedCycles) == 0 {
+		sb.WriteString("- (no cycle changes)\n")
+	}
+	for _, cycle := range report.NewCycles {
+		fmt.Fprintf(&sb, "- new: `%s`\n", strings.Join(cycle, "` <-> `"))
+	}
+	for _, cycle := range report.ResolvedCycles {
+		fmt.Fprintf(&sb, "- resolved: `%s`\n", strings.Join(cycle, "` <-> `"))
+	}
+	return sb.String()
+}
+
+func formatDelta(delta Delta) string {
+	return fmt.Sprintf("%d -> %d (%+d)", delta.Before, delta.After, delta.After-delta.Before)
+}
@@ -0,0 +1,138 @@
+package snapshot
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/morozRed/skelly/internal/parser"
+	"github.com/morozRed/skelly/internal/state"
+)
+
+func TestDiffReportsGrowthCouplingCyclesAndCoverage(t *testing.T) {
+	symbols := func(ids ...string) []parser.Symbol {
+		out := make([]parser.Symbol, 0, len(ids))
+		for _, id := range ids {
+			out = append(out, parser.Symbol{ID: id, Name: id})
+		}
+		return out
+	}
+
+	before := &Snapshot{
+		Path: "v1",
+		State: &state.State{Files: map[string]state.FileState{
+			"api/handler.go": {Symbols: symbols("Handle"), Dependencies: []string{"svc/orders.go"}},
+			"svc/orders.go":  {Symbols: symbols("Place")},
+		}},
+		Summarized: map[string]bool{"Handle": true},
+	}
+	after := &Snapshot{
+		Path: "v2",
+		State: &state.State{Files: map[string]state.FileState{
+			"api/handler.go": {Symbols: symbols("Handle"), Dependencies: []string{"svc/orders.go"}},
+			"svc/orders.go":  {Symbols: symbols("Place", "Cancel"), Dependencies: []string{"api/handler.go"}},
+			"main.go":        {Symbols: symbols("main"), Dependencies: []string{"api/handler.go"}},
+		}},
+		Summarized: map[string]bool{"Handle": true, "Place": true, "Cancel": true},
+	}
+
+	report := Diff(before, after)
+	if report.Files != (Delta{Before: 2, After: 3}) || report.Symbols != (Delta{Before: 2, After: 4}) {
+		t.Fatalf("unexpected totals: files=%#v symbols=%#v", report.Files, report.Symbols)
+	}
+
+	modules := make([]string, 0, len(report.Modules))
+	for _, module := range report.Modules {
+		modules = append(modules, module.Module)
+	}
+	if strings.Join(modules, ",") != "root,svc" {
+		t.Fatalf("expected root and svc to change, got %#v", report.Modules)
+	}
+
+	coupling := make([]string, 0, len(report.Coupling))
+	for _, diff := range report.Coupling {
+		coupling = append(coupling, diff.From+"->"+diff.To)
+	}
+	if strings.Join(coupling, ",") != "root->api,svc->api" {
+		t.Fatalf("expected new root->api and svc->api coupling, got %#v", report.Coupling)
+	}
+
+	if len(report.NewCycles) != 1 || strings.Join(report.NewCycles[0], ",") != "api,svc" || len(report.ResolvedCycles) != 0 {
+		t.Fatalf("expected a new api<->svc cycle, got new=%#v resolved=%#v", report.NewCycles, report.ResolvedCycles)
+	}
+
+	if report.Coverage.Before != 0.5 || report.Coverage.After != 0.75 {
+		t.Fatalf("expected coverage 50%% -> 75%%, got %#v", report.Coverage)
+	}
+
+	markdown := RenderMarkdown(report)
+	for _, want := range []string{"- Symbols: 2 -> 4 (+2)", "- `svc`: symbols 1 -> 2 (+1)", "- new: `api` <-> `svc`"} {
+		if !strings.Contains(markdown, want) {
+			t.Fatalf("expected markdown to contain %q, got:\n%s", want, markdown)
+		}
+	}
+}
+
+func TestChangesReportsSymbolsRenamesSignaturesAndEdges(t *testing.T) {
+	fn := func(file string, line int, name, signature string, calls ...string) parser.Symbol {
+		sym := parser.Symbol{Name: name, Kind: parser.SymbolFunction, Signature: signature, File: file, Line: line}
+		for _, call := range calls {
+			sym.Calls = append(sym.Calls, parser.CallSite{Name: call})
+		}
+		sym.ID = parser.StableSymbolID(file, sym)
+		return sym
+	}
+	files := func(symbols ...parser.Symbol) map[string]state.FileState {
+		out := make(map[string]state.FileState)
+		for _, sym := range symbols {
+			fileState := out[sym.File]
+			fileState.Language = "go"
+			fileState.Symbols = append(fileState.Symbols, sym)
+			out[sym.File] = fileState
+		}
+		return out
+	}
+
+	before := &Snapshot{Path: "v1", State: &state.State{Files: files(
+		fn("a.go", 1, "Run", "func Run()", "load"),
+		fn("a.go", 5, "load", "func load(path string)"),
+		fn("a.go", 9, "Close", "func Close()"),
+		fn("b.go", 1, "Parse", "func Parse(s string)"),
+	)}}
+	after := &Snapshot{Path: "v2", State: &state.State{Files: files(
+		fn("a.go", 3, "Run", "func Run()", "fetch", "Parse"),
+		fn("a.go", 7, "fetch", "func fetch(path string)"),
+		fn("b.go", 1, "Parse", "func Parse(s string, strict bool)"),
+		fn("b.go", 5, "Format", "func Format()"),
+	)}}
+
+	report := Changes(before, after)
+	if len(report.Added) != 1 || report.Added[0].Name != "Format" {
+		t.Fatalf("expected Format to be added, got %#v", report.Added)
+	}
+	if len(report.Removed) != 1 || report.Removed[0].Name != "Close" {
+		t.Fatalf("expected Close to be removed, got %#v", report.Removed)
+	}
+	if len(report.Renamed) != 1 || report.Renamed[0].From.Name != "load" || report.Renamed[0].To.Name != "fetch" || report.Renamed[0].Reason != state.AliasRenamed {
+		t.Fatalf("expected load to be renamed to fetch, got %#v", report.Renamed)
+	}
+	if len(report.SignatureChanged) != 1 || report.SignatureChanged[0].After != "func Parse(s string, strict bool)" {
+		t.Fatalf("expected Parse signature change, got %#v", report.SignatureChanged)
+	}
+	// Run -> load becomes Run -> fetch through the rename, so only the Parse edge is new.
+	if len(report.EdgesAdded) != 1 || report.EdgesAdded[0] != (EdgeChange{From: "a.go:Run", To: "b.go:Parse"}) {
+		t.Fatalf("expected one added edge to Parse, got %#v", report.EdgesAdded)
+	}
+	if len(report.EdgesRemoved) != 0 {
+		t.Fatalf("expected no removed edges, got %#v", report.EdgesRemoved)
+	}
+
+	text := RenderChanges(report)
+	for _, want := range []string{"+ func b.go:5 Format", "- func a.go:9 Close", "> func a.go:load -> a.go:fetch (renamed)", "+ edge a.go:Run -> b.go:Parse"} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("expected %q in rendered diff:\n%s", want, text)
+		}
+	}
+	if !Changes(after, after).Empty() {
+		t.Fatalf("expected no changes between identical snapshots")
+	}
+}
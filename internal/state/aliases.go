@@ -0,0 +1,219 @@
+package state
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/morozRed/skelly/internal/parser"
+)
+
+const (
+	// AliasMoved marks a symbol whose name, kind and signature are unchanged
+	// but whose file or line moved.
+	AliasMoved = "moved"
+	// AliasRenamed marks a symbol that kept its file, kind and line under a new name.
+	AliasRenamed = "renamed"
+	// AliasMigrated marks an ID rewritten by MigrateSymbolIDs.
+	AliasMigrated = "migrated"
+)
+
+// SymbolAlias forwards a retired stable symbol ID to the ID that replaced it.
+type SymbolAlias struct {
+	Target    string    `json:"target"`
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// SnapshotSymbols returns the recorded symbols for files so they can be
+// compared after the files are re-indexed.
+func (s *State) SnapshotSymbols(files []string) map[string][]parser.Symbol {
+	snapshot := make(map[string][]parser.Symbol, len(files))
+	for _, file := range files {
+		if fileState, ok := s.Files[file]; ok {
+			snapshot[file] = fileState.Symbols
+		}
+	}
+	return snapshot
+}
+
+// ForwardSymbols records aliases for symbol IDs in before that disappeared
+// from the touched files, matching each one to a symbol that appeared in
+// their place. Existing forwards are re-pointed so chains collapse to the
+// newest ID. It returns the number of aliases added.
+func (s *State) ForwardSymbols(before map[string][]parser.Symbol, touched []string, now time.Time) int {
+	if s.Aliases == nil {
+		s.Aliases = make(map[string]SymbolAlias)
+	}
+
+	type candidate struct {
+		file   string
+		symbol parser.Symbol
+	}
+	oldIDs := make(map[string]bool)
+	for _, symbols := range before {
+		for _, sym := range symbols {
+			oldIDs[sym.ID] = true
+		}
+	}
+	live := make(map[string]bool)
+	byShape := make(map[string][]candidate)
+	bySlot := make(map[string][]candidate)
+	for _, file := range touched {
+		for _, sym := range s.Files[file].Symbols {
+			live[sym.ID] = true
+			if oldIDs[sym.ID] {
+				continue
+			}
+			byShape[aliasShapeKey(sym)] = append(byShape[aliasShapeKey(sym)], candidate{file: file, symbol: sym})
+			bySlot[aliasSlotKey(file, sym)] = append(bySlot[aliasSlotKey(file, sym)], candidate{file: file, symbol: sym})
+		}
+	}
+
+	// A live ID no longer needs forwarding (e.g. a symbol moved back).
+	for id := range live {
+		delete(s.Aliases, id)
+	}
+
+	files := make([]string, 0, len(before))
+	for file := range before {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	forwarded := make(map[string]string)
+	for _, file := range files {
+		for _, sym := range before[file] {
+			if sym.ID == "" || live[sym.ID] {
+				continue
+			}
+
+			target, reason := "", ""
+			candidates := byShape[aliasShapeKey(sym)]
+			sameFile := make([]candidate, 0, len(candidates))
+			for _, c := range candidates {
+				if c.file == file {
+					sameFile = append(sameFile, c)
+				}
+			}
+			switch {
+			case len(sameFile) == 1:
+				target, reason = sameFile[0].symbol.ID, AliasMoved
+			case len(candidates) == 1:
+				target, reason = candidates[0].symbol.ID, AliasMoved
+			default:
+				// Renames keep the slot and the signature apart from the name itself.
+				if slot := bySlot[aliasSlotKey(file, sym)]; len(slot) == 1 && isRename(sym, slot[0].symbol) {
+					target, reason = slot[0].symbol.ID, AliasRenamed
+				}
+			}
+			if target == "" {
+				continue
+			}
+			s.Aliases[sym.ID] = SymbolAlias{Target: target, Reason: reason, CreatedAt: now}
+			forwarded[sym.ID] = target
+		}
+	}
+
+	for id, alias := range s.Aliases {
+		if next, ok := forwarded[alias.Target]; ok && next != id {
+			alias.Target = next
+			s.Aliases[id] = alias
+		}
+	}
+	return len(forwarded)
+}
+
+// MigrateSymbolIDs rewrites symbol IDs recorded under an older
+// parser.SymbolIDVersion to the current scheme, forwarding each old ID to
+// its replacement so enrich records and external references follow. Symbols
+// are not reparsed; their recorded file, kind, name and signature suffice.
+// It returns the number of IDs forwarded and is a no-op on current state.
+func (s *State) MigrateSymbolIDs(now time.Time) int {
+	if s.SymbolIDVersion == parser.SymbolIDVersion {
+		return 0
+	}
+	if s.Aliases == nil {
+		s.Aliases = make(map[string]SymbolAlias)
+	}
+
+	forwarded := make(map[string]string)
+	for file, fileState := range s.Files {
+		symbols := append([]parser.Symbol(nil), fileState.Symbols...)
+		for i, id := range parser.SymbolIDs(file, symbols) {
+			if old := symbols[i].ID; old != "" && old != id {
+				s.Aliases[old] = SymbolAlias{Target: id, Reason: AliasMigrated, CreatedAt: now}
+				forwarded[old] = id
+			}
+			symbols[i].ID = id
+		}
+		fileState.Symbols = symbols
+		s.Files[file] = fileState
+	}
+	for id, alias := range s.Aliases {
+		if next, ok := forwarded[alias.Target]; ok && next != id {
+			alias.Target = next
+			s.Aliases[id] = alias
+		}
+	}
+	s.SymbolIDVersion = parser.SymbolIDVersion
+	return len(forwarded)
+}
+
+// ResolveAlias follows a forwarding entry for id, if one exists.
+func (s *State) ResolveAlias(id string) (string, bool) {
+	alias, ok := s.Aliases[id]
+	if !ok {
+		return "", false
+	}
+	return alias.Target, true
+}
+
+// AliasTargets returns the forwarding table as old ID -> current ID.
+func (s *State) AliasTargets() map[string]string {
+	if len(s.Aliases) == 0 {
+		return nil
+	}
+	targets := make(map[string]string, len(s.Aliases))
+	for id, alias := range s.Aliases {
+		targets[id] = alias.Target
+	}
+	return targets
+}
+
+// PruneAliases retires forwards created before cutoff and forwards whose
+// target no longer exists. It returns the removed IDs sorted.
+func (s *State) PruneAliases(cutoff time.Time) []string {
+	liveIDs := make(map[string]bool)
+	for _, fileState := range s.Files {
+		for _, sym := range fileState.Symbols {
+			liveIDs[sym.ID] = true
+		}
+	}
+
+	removed := make([]string, 0)
+	for id, alias := range s.Aliases {
+		if alias.CreatedAt.Before(cutoff) || !liveIDs[alias.Target] {
+			removed = append(removed, id)
+			delete(s.Aliases, id)
+		}
+	}
+	sort.Strings(removed)
+	return removed
+}
+
+func aliasShapeKey(sym parser.Symbol) string {
+	return sym.Kind.String() + "|" + sym.Name + "|" + sym.Signature
+}
+
+func aliasSlotKey(file string, sym parser.Symbol) string {
+	return file + "|" + sym.Kind.String() + "|" + strconv.Itoa(sym.Line)
+}
+
+func isRename(from, to parser.Symbol) bool {
+	if from.Name == to.Name {
+		return false
+	}
+	return strings.Replace(from.Signature, from.Name, to.Name, 1) == to.Signature
+}
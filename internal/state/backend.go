@@ -0,0 +1,299 @@
+package state
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Backend selects how state is persisted in the context directory.
+type Backend string
+
+const (
+	// BackendJSON is the default human-readable .state.json.
+	BackendJSON Backend = "json"
+	// BackendBinary is a gob-encoded .state.bin with a per-file offset
+	// table, so readers that only need hashes or a few files skip the rest.
+	BackendBinary Backend = "binary"
+
+	BinaryStateFile = ".state.bin"
+)
+
+// binaryMagic prefixes every .state.bin; the trailing byte is the layout version.
+var binaryMagic = []byte("SKLYST\x00\x01")
+
+// ParseBackend validates a --state-backend value.
+func ParseBackend(raw string) (Backend, error) {
+	backend := Backend(strings.ToLower(strings.TrimSpace(raw)))
+	switch backend {
+	case "", BackendJSON:
+		return BackendJSON, nil
+	case BackendBinary:
+		return backend, nil
+	default:
+		return "", fmt.Errorf("unsupported state backend %q (supported: json, binary)", raw)
+	}
+}
+
+// Path returns the state file present in contextDir and whether one exists.
+// A binary store takes precedence; Save never leaves both behind.
+func Path(contextDir string) (string, bool) {
+	for _, name := range []string{BinaryStateFile, StateFile} {
+		path := filepath.Join(contextDir, name)
+		if _, err := os.Stat(path); err == nil {
+			return path, true
+		}
+	}
+	return filepath.Join(contextDir, StateFile), false
+}
+
+// Migrate rewrites the state in contextDir with backend. It reports whether
+// anything changed; a missing state is created empty in the new backend.
+func Migrate(contextDir string, backend Backend) (bool, error) {
+	st, err := Load(contextDir)
+	if err != nil {
+		return false, err
+	}
+	_, exists := Path(contextDir)
+	if exists && st.Backend == backend {
+		return false, nil
+	}
+	if err := os.MkdirAll(contextDir, 0755); err != nil {
+		return false, err
+	}
+	st.Backend = backend
+	return true, st.Save(contextDir)
+}
+
+// binaryHeader is everything except per-file records, which follow the
+// header and are addressed by Entries.
+type binaryHeader struct {
+	Meta    State
+	Entries []binaryEntry
+}
+
+type binaryEntry struct {
+	Path   string
+	Hash   string
+	Offset int64 // relative to the first record
+	Length int64
+}
+
+func (s *State) saveBinary(path string) error {
+	files := make([]string, 0, len(s.Files))
+	for file := range s.Files {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	var records bytes.Buffer
+	header := binaryHeader{Meta: *s, Entries: make([]binaryEntry, 0, len(files))}
+	header.Meta.Files = nil
+	for _, file := range files {
+		offset := int64(records.Len())
+		if err := gob.NewEncoder(&records).Encode(s.Files[file]); err != nil {
+			return fmt.Errorf("failed to encode state for %s: %w", file, err)
+		}
+		header.Entries = append(header.Entries, binaryEntry{
+			Path:   file,
+			Hash:   s.Files[file].Hash,
+			Offset: offset,
+			Length: int64(records.Len()) - offset,
+		})
+	}
+
+	var headerData bytes.Buffer
+	if err := gob.NewEncoder(&headerData).Encode(header); err != nil {
+		return fmt.Errorf("failed to encode state header: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	writer := bufio.NewWriter(tmp)
+	lengthPrefix := make([]byte, 8)
+	binary.LittleEndian.PutUint64(lengthPrefix, uint64(headerData.Len()))
+	for _, chunk := range [][]byte{binaryMagic, lengthPrefix, headerData.Bytes(), records.Bytes()} {
+		if _, err := writer.Write(chunk); err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+			return err
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func loadBinary(path string) (*State, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	header, base, err := decodeBinaryHeader(bytes.NewReader(data), path)
+	if err != nil {
+		return nil, err
+	}
+
+	st := header.Meta
+	st.Files = make(map[string]FileState, len(header.Entries))
+	for _, entry := range header.Entries {
+		start := base + entry.Offset
+		if start < base || start+entry.Length > int64(len(data)) {
+			return nil, fmt.Errorf("state record for %s is truncated", entry.Path)
+		}
+		var fileState FileState
+		if err := gob.NewDecoder(bytes.NewReader(data[start : start+entry.Length])).Decode(&fileState); err != nil {
+			return nil, fmt.Errorf("failed to decode state for %s: %w", entry.Path, err)
+		}
+		st.Files[entry.Path] = fileState
+	}
+	return &st, nil
+}
+
+// decodeBinaryHeader reads the magic, header length and header from r and
+// returns the offset at which per-file records start.
+func decodeBinaryHeader(r io.Reader, path string) (binaryHeader, int64, error) {
+	var header binaryHeader
+	prefix := make([]byte, len(binaryMagic)+8)
+	if _, err := io.ReadFull(r, prefix); err != nil {
+		return header, 0, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if !bytes.Equal(prefix[:len(binaryMagic)], binaryMagic) {
+		return header, 0, fmt.Errorf("%s is not a skelly binary state file", path)
+	}
+	headerLength := int64(binary.LittleEndian.Uint64(prefix[len(binaryMagic):]))
+	if err := gob.NewDecoder(io.LimitReader(r, headerLength)).Decode(&header); err != nil {
+		return header, 0, fmt.Errorf("failed to decode %s header: %w", path, err)
+	}
+	migrateState(&header.Meta)
+	header.Meta.Backend = BackendBinary
+	return header, int64(len(prefix)) + headerLength, nil
+}
+
+// Store gives per-file access to persisted state without decoding every
+// file. With the binary backend only the header is read up front; the JSON
+// backend has no index, so it is loaded whole.
+type Store struct {
+	header  binaryHeader
+	entries map[string]binaryEntry
+	file    *os.File
+	base    int64
+	loaded  *State
+}
+
+// OpenStore opens the state in contextDir for lazy per-file reads. Callers
+// must Close it. A missing state yields an empty store.
+func OpenStore(contextDir string) (*Store, error) {
+	path, exists := Path(contextDir)
+	if exists && filepath.Base(path) == BinaryStateFile {
+		return openBinaryStore(path)
+	}
+	st, err := Load(contextDir)
+	if err != nil {
+		return nil, err
+	}
+	return &Store{loaded: st}, nil
+}
+
+func openBinaryStore(path string) (*Store, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	header, base, err := decodeBinaryHeader(bufio.NewReader(file), path)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	store := &Store{
+		header:  header,
+		entries: make(map[string]binaryEntry, len(header.Entries)),
+		file:    file,
+		base:    base,
+	}
+	for _, entry := range header.Entries {
+		store.entries[entry.Path] = entry
+	}
+	return store, nil
+}
+
+// Hash returns the recorded content hash for file.
+func (s *Store) Hash(file string) (string, bool) {
+	if s.loaded != nil {
+		return s.loaded.GetFileHash(file)
+	}
+	entry, ok := s.entries[file]
+	return entry.Hash, ok
+}
+
+// File decodes the recorded state for one file.
+func (s *Store) File(file string) (FileState, bool, error) {
+	if s.loaded != nil {
+		fileState, ok := s.loaded.Files[file]
+		return fileState, ok, nil
+	}
+	entry, ok := s.entries[file]
+	if !ok {
+		return FileState{}, false, nil
+	}
+	fileState, err := s.readEntry(entry)
+	return fileState, err == nil, err
+}
+
+// Files lists every recorded file path in sorted order.
+func (s *Store) Files() []string {
+	if s.loaded != nil {
+		files := make([]string, 0, len(s.loaded.Files))
+		for file := range s.loaded.Files {
+			files = append(files, file)
+		}
+		sort.Strings(files)
+		return files
+	}
+	files := make([]string, 0, len(s.header.Entries))
+	for _, entry := range s.header.Entries {
+		files = append(files, entry.Path)
+	}
+	return files
+}
+
+// Close releases the underlying file, if any.
+func (s *Store) Close() error {
+	if s.file == nil {
+		return nil
+	}
+	return s.file.Close()
+}
+
+func (s *Store) readEntry(entry binaryEntry) (FileState, error) {
+	var fileState FileState
+	section := io.NewSectionReader(s.file, s.base+entry.Offset, entry.Length)
+	if err := gob.NewDecoder(section).Decode(&fileState); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+			return FileState{}, fmt.Errorf("state record for %s is truncated", entry.Path)
+		}
+		return FileState{}, fmt.Errorf("failed to decode state for %s: %w", entry.Path, err)
+	}
+	return fileState, nil
+}
@@ -0,0 +1,51 @@
+package state
+
+import (
+	"reflect"
+	"time"
+)
+
+// CalibrationHistoryLimit bounds how many calibration runs state keeps.
+const CalibrationHistoryLimit = 20
+
+// CalibrationCounts are call-site resolution outcomes for one language.
+type CalibrationCounts struct {
+	Resolved   int `json:"resolved"`
+	Heuristic  int `json:"heuristic"`
+	Ambiguous  int `json:"ambiguous"`
+	Unresolved int `json:"unresolved"`
+}
+
+// Total is the number of call sites counted.
+func (c CalibrationCounts) Total() int {
+	return c.Resolved + c.Heuristic + c.Ambiguous + c.Unresolved
+}
+
+// CalibrationRun is the per-language resolution snapshot of one generate or update.
+type CalibrationRun struct {
+	At        time.Time                    `json:"at"`
+	Languages map[string]CalibrationCounts `json:"languages"`
+}
+
+// RecordCalibration appends run unless it matches the latest recorded run,
+// so repeated no-op updates do not push older runs out of the history.
+func (s *State) RecordCalibration(run CalibrationRun) {
+	if n := len(s.Calibration); n > 0 && reflect.DeepEqual(s.Calibration[n-1].Languages, run.Languages) {
+		return
+	}
+	s.Calibration = append(s.Calibration, run)
+	if len(s.Calibration) > CalibrationHistoryLimit {
+		s.Calibration = s.Calibration[len(s.Calibration)-CalibrationHistoryLimit:]
+	}
+}
+
+// PreviousCalibration returns the most recent recorded run whose counts
+// differ from current, i.e. the baseline a trend should be measured against.
+func (s *State) PreviousCalibration(current map[string]CalibrationCounts) (CalibrationRun, bool) {
+	for i := len(s.Calibration) - 1; i >= 0; i-- {
+		if !reflect.DeepEqual(s.Calibration[i].Languages, current) {
+			return s.Calibration[i], true
+		}
+	}
+	return CalibrationRun{}, false
+}
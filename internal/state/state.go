@@ -13,8 +13,8 @@ import (
 const (
 	StateFile            = ".state.json"
 	CurrentStateVersion  = "2"
-	CurrentParserVersion = "tree-sitter-v1"
-	CurrentOutputVersion = "context-v1"
+	CurrentParserVersion = "tree-sitter-v24"
+	CurrentOutputVersion = "context-v3"
 )
 
 // FileState tracks the state of a single file
@@ -25,32 +25,77 @@ type FileState struct {
 	Imports       []string          `json:"imports,omitempty"`
 	ImportAliases map[string]string `json:"import_aliases,omitempty"`
 	Dependencies  []string          `json:"dependencies,omitempty"`
-	UpdatedAt     time.Time         `json:"updated_at"`
+	// Generated, BuildConstraint, External and Lines mirror parser.FileSymbols.
+	Generated       bool      `json:"generated,omitempty"`
+	BuildConstraint string    `json:"build_constraint,omitempty"`
+	External        bool      `json:"external,omitempty"`
+	Lines           int       `json:"lines,omitempty"`
+	UpdatedAt       time.Time `json:"updated_at"`
+	// NavSegment and SearchSegment fingerprint the file's part of the
+	// navigation and search indexes on disk, so update re-encodes only the
+	// parts that changed.
+	NavSegment    string `json:"nav_segment,omitempty"`
+	SearchSegment string `json:"search_segment,omitempty"`
 }
 
 // State tracks the state of all files for incremental updates
 type State struct {
-	Version       string               `json:"version"`
-	ParserVersion string               `json:"parser_version,omitempty"`
-	OutputVersion string               `json:"output_version,omitempty"`
-	UpdatedAt     time.Time            `json:"updated_at"`
-	Files         map[string]FileState `json:"files"`
-	OutputHashes  map[string]string    `json:"output_hashes,omitempty"`
+	Version       string `json:"version"`
+	ParserVersion string `json:"parser_version,omitempty"`
+	OutputVersion string `json:"output_version,omitempty"`
+	// SymbolIDVersion is the parser.SymbolIDVersion the recorded symbol IDs
+	// use; empty means version 1. See MigrateSymbolIDs.
+	SymbolIDVersion string               `json:"symbol_id_version,omitempty"`
+	UpdatedAt       time.Time            `json:"updated_at"`
+	Files           map[string]FileState `json:"files"`
+	OutputHashes    map[string]string    `json:"output_hashes,omitempty"`
+	IndexOrder      string               `json:"index_order,omitempty"`
+	// NoGitignore records that the context was generated without .gitignore
+	// rules (--no-gitignore); update and watch keep that choice unless told
+	// otherwise.
+	NoGitignore bool `json:"no_gitignore,omitempty"`
+	// SearchStale is set when `update --quick` refreshed outputs without
+	// rebuilding the search index; the next full update rebuilds it.
+	SearchStale bool `json:"search_stale,omitempty"`
+	// EnrichHash is the hash of enrich.jsonl when its summaries were last
+	// merged into the artifacts; a different hash makes update rewrite them.
+	EnrichHash string `json:"enrich_hash,omitempty"`
+	// ParseIssues are the warnings and errors of the last generate, written
+	// to issues.jsonl; a file's issues are dropped once it parses.
+	ParseIssues []parser.ParseIssue `json:"parse_issues,omitempty"`
+	// Aliases forwards retired symbol IDs (old ID -> replacement) across moves and renames.
+	Aliases map[string]SymbolAlias `json:"aliases,omitempty"`
+	// Calibration is the recent history of per-language call resolution.
+	Calibration []CalibrationRun `json:"calibration,omitempty"`
+	// Ranks and Betweenness are the PageRank and betweenness scores of the
+	// last build by symbol ID, and RankTopology the hash of the graph they
+	// were computed for, so the next build can reuse or seed them instead
+	// of computing them from scratch.
+	RankTopology string             `json:"rank_topology,omitempty"`
+	Ranks        map[string]float64 `json:"ranks,omitempty"`
+	Betweenness  map[string]float64 `json:"betweenness,omitempty"`
+	// Backend records which file the state was loaded from; Save writes
+	// the same backend back unless it was changed (see Migrate).
+	Backend Backend `json:"-"`
 }
 
 // NewState creates a new empty state
 func NewState() *State {
 	return &State{
-		Version:       CurrentStateVersion,
-		ParserVersion: CurrentParserVersion,
-		OutputVersion: CurrentOutputVersion,
-		Files:         make(map[string]FileState),
-		OutputHashes:  make(map[string]string),
+		Version:         CurrentStateVersion,
+		ParserVersion:   CurrentParserVersion,
+		OutputVersion:   CurrentOutputVersion,
+		SymbolIDVersion: parser.SymbolIDVersion,
+		Files:           make(map[string]FileState),
+		OutputHashes:    make(map[string]string),
 	}
 }
 
-// Load reads state from the context state file.
+// Load reads state from the context state file, whichever backend wrote it.
 func Load(contextDir string) (*State, error) {
+	if _, err := os.Stat(filepath.Join(contextDir, BinaryStateFile)); err == nil {
+		return loadBinary(filepath.Join(contextDir, BinaryStateFile))
+	}
 	path := filepath.Join(contextDir, StateFile)
 
 	data, err := os.ReadFile(path)
@@ -67,6 +112,7 @@ func Load(contextDir string) (*State, error) {
 	}
 
 	migrateState(&state)
+	state.Backend = BackendJSON
 
 	return &state, nil
 }
@@ -91,13 +137,26 @@ func (s *State) Save(contextDir string) error {
 
 	s.UpdatedAt = time.Now()
 
-	data, err := json.MarshalIndent(s, "", "  ")
-	if err != nil {
+	// Only one backend's file may exist, or Load would read a stale one.
+	stale := filepath.Join(contextDir, BinaryStateFile)
+	if s.Backend == BackendBinary {
+		if err := s.saveBinary(filepath.Join(contextDir, BinaryStateFile)); err != nil {
+			return err
+		}
+		stale = filepath.Join(contextDir, StateFile)
+	} else {
+		data, err := json.MarshalIndent(s, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(contextDir, StateFile), data, 0644); err != nil {
+			return err
+		}
+	}
+	if err := os.Remove(stale); err != nil && !os.IsNotExist(err) {
 		return err
 	}
-
-	path := filepath.Join(contextDir, StateFile)
-	return os.WriteFile(path, data, 0644)
+	return nil
 }
 
 // SetFileHash updates the hash for a file
@@ -110,13 +169,35 @@ func (s *State) SetFileHash(file, hash string) {
 
 // SetFileData stores parsed file metadata for incremental updates.
 func (s *State) SetFileData(file parser.FileSymbols) {
+	s.RemoveParseIssues(file.Path)
 	s.Files[file.Path] = FileState{
-		Hash:          file.Hash,
-		Language:      file.Language,
-		Symbols:       file.Symbols,
-		Imports:       file.Imports,
-		ImportAliases: file.ImportAliases,
-		UpdatedAt:     time.Now(),
+		Hash:            file.Hash,
+		Language:        file.Language,
+		Symbols:         file.Symbols,
+		Imports:         file.Imports,
+		ImportAliases:   file.ImportAliases,
+		Generated:       file.Generated,
+		BuildConstraint: file.BuildConstraint,
+		External:        file.External,
+		Lines:           file.Lines,
+		UpdatedAt:       time.Now(),
+	}
+}
+
+// SetNavSegments records the navigation index segment fingerprints returned
+// by nav.WriteIndex; files without one are cleared.
+func (s *State) SetNavSegments(fingerprints map[string]string) {
+	for file, fileState := range s.Files {
+		fileState.NavSegment = fingerprints[filepath.ToSlash(file)]
+		s.Files[file] = fileState
+	}
+}
+
+// SetSearchSegments does the same for the search index.
+func (s *State) SetSearchSegments(fingerprints map[string]string) {
+	for file, fileState := range s.Files {
+		fileState.SearchSegment = fingerprints[filepath.ToSlash(file)]
+		s.Files[file] = fileState
 	}
 }
 
@@ -141,6 +222,46 @@ func (s *State) HasChanged(file, currentHash string) bool {
 // RemoveFile removes a file from state tracking
 func (s *State) RemoveFile(file string) {
 	delete(s.Files, file)
+	s.RemoveParseIssues(file)
+}
+
+// SetParseIssues replaces the recorded parse issues.
+func (s *State) SetParseIssues(issues []parser.ParseIssue) {
+	s.ParseIssues = append([]parser.ParseIssue(nil), issues...)
+}
+
+// AddParseIssue records issue in place of the file's earlier issues.
+func (s *State) AddParseIssue(issue parser.ParseIssue) {
+	s.RemoveParseIssues(issue.File)
+	s.ParseIssues = append(s.ParseIssues, issue)
+	sort.SliceStable(s.ParseIssues, func(i, j int) bool {
+		return s.ParseIssues[i].File < s.ParseIssues[j].File
+	})
+}
+
+// ParseFailures returns the files whose last parse failed with an error.
+func (s *State) ParseFailures() map[string]bool {
+	failed := make(map[string]bool)
+	for _, issue := range s.ParseIssues {
+		if issue.Severity == "error" {
+			failed[issue.File] = true
+		}
+	}
+	return failed
+}
+
+// RemoveParseIssues drops the recorded issues of file.
+func (s *State) RemoveParseIssues(file string) {
+	if len(s.ParseIssues) == 0 {
+		return
+	}
+	kept := s.ParseIssues[:0]
+	for _, issue := range s.ParseIssues {
+		if issue.File != file {
+			kept = append(kept, issue)
+		}
+	}
+	s.ParseIssues = kept
 }
 
 // ChangedFiles returns files that have changed based on provided hashes
@@ -1,8 +1,13 @@
 package state
 
 import (
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
+	"time"
+
+	"github.com/morozRed/skelly/internal/parser"
 )
 
 func TestChangedAndDeletedFiles(t *testing.T) {
@@ -39,6 +44,25 @@ func TestImpactedFilesClosure(t *testing.T) {
 	}
 }
 
+func TestParseIssuesAreDroppedOnceAFileParses(t *testing.T) {
+	s := NewState()
+	s.SetParseIssues([]parser.ParseIssue{
+		{File: "a.go", Language: "go", Severity: "error", Message: "read failed"},
+		{File: "b.py", Language: "python", Severity: "error", Message: "read failed"},
+		{File: "vendor", Severity: "warning", Message: "walk error"},
+	})
+	if failed := s.ParseFailures(); !reflect.DeepEqual(failed, map[string]bool{"a.go": true, "b.py": true}) {
+		t.Fatalf("unexpected parse failures: %v", failed)
+	}
+
+	s.SetFileData(parser.FileSymbols{Path: "a.go", Language: "go"})
+	s.RemoveFile("b.py")
+	want := []parser.ParseIssue{{File: "vendor", Severity: "warning", Message: "walk error"}}
+	if !reflect.DeepEqual(s.ParseIssues, want) {
+		t.Fatalf("expected only the walk warning to remain, got %#v", s.ParseIssues)
+	}
+}
+
 func TestMigrateStateSetsOutputVersion(t *testing.T) {
 	s := &State{
 		Version:       "1",
@@ -57,6 +81,112 @@ func TestMigrateStateSetsOutputVersion(t *testing.T) {
 	}
 }
 
+func TestForwardSymbolsTracksMovesRenamesAndChains(t *testing.T) {
+	sym := func(file, name string, line int) parser.Symbol {
+		s := parser.Symbol{Name: name, Kind: parser.SymbolFunction, Signature: "func " + name + "()", Line: line}
+		s.ID = parser.StableSymbolID(file, s)
+		return s
+	}
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	s := NewState()
+	s.Files["a.go"] = FileState{Symbols: []parser.Symbol{sym("a.go", "Keep", 3), sym("a.go", "Move", 5), sym("a.go", "Old", 7)}}
+	before := s.SnapshotSymbols([]string{"a.go", "b.go"})
+	original := before["a.go"]
+
+	// Move relocates to b.go; Old is renamed in place.
+	s.Files["a.go"] = FileState{Symbols: []parser.Symbol{sym("a.go", "Keep", 3), sym("a.go", "New", 7)}}
+	s.Files["b.go"] = FileState{Symbols: []parser.Symbol{sym("b.go", "Move", 3)}}
+	if added := s.ForwardSymbols(before, []string{"a.go", "b.go"}, now); added != 2 {
+		t.Fatalf("expected 2 forwards, got %d (%#v)", added, s.Aliases)
+	}
+	if got := s.Aliases[original[1].ID]; got.Target != sym("b.go", "Move", 3).ID || got.Reason != AliasMoved {
+		t.Fatalf("unexpected move alias: %#v", got)
+	}
+	if got := s.Aliases[original[2].ID]; got.Target != sym("a.go", "New", 7).ID || got.Reason != AliasRenamed {
+		t.Fatalf("unexpected rename alias: %#v", got)
+	}
+
+	// Moving again re-points the first forward to the newest ID.
+	before = s.SnapshotSymbols([]string{"b.go"})
+	delete(s.Files, "b.go")
+	s.Files["c.go"] = FileState{Symbols: []parser.Symbol{sym("c.go", "Move", 9)}}
+	s.ForwardSymbols(before, []string{"c.go"}, now.Add(time.Hour))
+	if target, _ := s.ResolveAlias(original[1].ID); target != sym("c.go", "Move", 9).ID {
+		t.Fatalf("expected chained forward to collapse, got %q", target)
+	}
+
+	removed := s.PruneAliases(now.Add(30 * time.Minute))
+	if len(removed) != 2 || len(s.Aliases) != 1 {
+		t.Fatalf("expected forwards created before the cutoff to be pruned, removed=%v remaining=%#v", removed, s.Aliases)
+	}
+}
+
+func TestBinaryBackendRoundTripsAndReadsFilesLazily(t *testing.T) {
+	contextDir := t.TempDir()
+	s := NewState()
+	s.IndexOrder = "path"
+	s.SetOutputHash("index.txt", "abc")
+	s.SetFileData(parser.FileSymbols{
+		Path:     "a.go",
+		Hash:     "a1",
+		Language: "go",
+		Symbols:  []parser.Symbol{{ID: "a.go:A", Name: "A", Kind: parser.SymbolFunction, Line: 3, Calls: []parser.CallSite{{Name: "B"}}}},
+		Imports:  []string{"fmt"},
+	})
+	s.Files["b.go"] = FileState{Hash: "b1", Dependencies: []string{"a.go"}}
+	if err := s.Save(contextDir); err != nil {
+		t.Fatalf("json save failed: %v", err)
+	}
+
+	if migrated, err := Migrate(contextDir, BackendBinary); err != nil || !migrated {
+		t.Fatalf("expected migration to binary, migrated=%v err=%v", migrated, err)
+	}
+	if path, ok := Path(contextDir); !ok || filepath.Base(path) != BinaryStateFile {
+		t.Fatalf("expected only %s after migration, got %q", BinaryStateFile, path)
+	}
+	if _, err := os.Stat(filepath.Join(contextDir, StateFile)); !os.IsNotExist(err) {
+		t.Fatalf("expected %s to be removed, got %v", StateFile, err)
+	}
+
+	loaded, err := Load(contextDir)
+	if err != nil {
+		t.Fatalf("binary load failed: %v", err)
+	}
+	if loaded.Backend != BackendBinary || loaded.IndexOrder != "path" || loaded.OutputHashes["index.txt"] != "abc" {
+		t.Fatalf("unexpected binary metadata: %#v", loaded)
+	}
+	if got := loaded.Files["a.go"]; got.Hash != "a1" || len(got.Symbols) != 1 || got.Symbols[0].Calls[0].Name != "B" || got.Imports[0] != "fmt" {
+		t.Fatalf("unexpected a.go state: %#v", got)
+	}
+	if got := loaded.Files["b.go"]; !reflect.DeepEqual(got.Dependencies, []string{"a.go"}) {
+		t.Fatalf("unexpected b.go state: %#v", got)
+	}
+
+	store, err := OpenStore(contextDir)
+	if err != nil {
+		t.Fatalf("OpenStore failed: %v", err)
+	}
+	defer store.Close()
+	if hash, ok := store.Hash("b.go"); !ok || hash != "b1" {
+		t.Fatalf("expected b.go hash from header, got %q %v", hash, ok)
+	}
+	if !reflect.DeepEqual(store.Files(), []string{"a.go", "b.go"}) {
+		t.Fatalf("unexpected store files %v", store.Files())
+	}
+	fileState, ok, err := store.File("a.go")
+	if err != nil || !ok || fileState.Symbols[0].Name != "A" {
+		t.Fatalf("unexpected lazy a.go read: %#v ok=%v err=%v", fileState, ok, err)
+	}
+
+	if migrated, err := Migrate(contextDir, BackendJSON); err != nil || !migrated {
+		t.Fatalf("expected migration back to json, migrated=%v err=%v", migrated, err)
+	}
+	if path, ok := Path(contextDir); !ok || filepath.Base(path) != StateFile {
+		t.Fatalf("expected only %s after migrating back, got %q", StateFile, path)
+	}
+}
+
 func expectSet(t *testing.T, got, want []string) {
 	t.Helper()
 	if len(got) != len(want) {
@@ -74,3 +204,30 @@ func expectSet(t *testing.T, got, want []string) {
 		}
 	}
 }
+
+func TestMigrateSymbolIDsForwardsLegacyIDs(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := &State{
+		Files: map[string]FileState{"a.go": {Symbols: []parser.Symbol{
+			{ID: "a.go|3|func|Run|1234abcd", Name: "Run", Kind: parser.SymbolFunction, Signature: "func Run()", Line: 3},
+		}}},
+		Aliases: map[string]SymbolAlias{"a.go|1|func|Run|1234abcd": {Target: "a.go|3|func|Run|1234abcd", Reason: AliasMoved, CreatedAt: now}},
+	}
+
+	if forwarded := s.MigrateSymbolIDs(now); forwarded != 1 {
+		t.Fatalf("expected 1 forwarded ID, got %d", forwarded)
+	}
+	newID := s.Files["a.go"].Symbols[0].ID
+	if newID != parser.StableSymbolID("a.go", s.Files["a.go"].Symbols[0]) || s.SymbolIDVersion != parser.SymbolIDVersion {
+		t.Fatalf("expected current-scheme ID and version, got %q (version %q)", newID, s.SymbolIDVersion)
+	}
+	if got := s.Aliases["a.go|3|func|Run|1234abcd"]; got.Target != newID || got.Reason != AliasMigrated {
+		t.Fatalf("unexpected migration alias: %#v", got)
+	}
+	if target, _ := s.ResolveAlias("a.go|1|func|Run|1234abcd"); target != newID {
+		t.Fatalf("expected older forwards to be re-pointed, got %q", target)
+	}
+	if forwarded := s.MigrateSymbolIDs(now); forwarded != 0 {
+		t.Fatalf("expected migration to be a no-op on current state, got %d", forwarded)
+	}
+}
@@ -0,0 +1,265 @@
+// skelly code map: a read-only explorer over the `serve` JSON API. Search
+// lists symbols, selecting one centers it with its callers on the left and
+// callees on the right, and clicking a neighbor re-centers on it.
+"use strict";
+
+const SVG_NS = "http://www.w3.org/2000/svg";
+const NODE_WIDTH = 220;
+const NODE_HEIGHT = 40;
+const ROW_GAP = 12;
+const MAX_NEIGHBORS = 25;
+
+const searchForm = document.getElementById("search-form");
+const searchInput = document.getElementById("search-input");
+const results = document.getElementById("results");
+const details = document.getElementById("details");
+const graph = document.getElementById("graph");
+const trail = document.getElementById("trail");
+const statusLine = document.getElementById("status");
+
+let visited = [];
+
+async function api(path) {
+  const response = await fetch(path, { headers: { Accept: "application/json" } });
+  const body = await response.json();
+  if (!response.ok) {
+    throw new Error(body.error || response.statusText);
+  }
+  return body;
+}
+
+function symbolPath(prefix, id) {
+  return prefix + "/" + encodeURIComponent(id);
+}
+
+function element(tag, attrs, text) {
+  const node = document.createElement(tag);
+  for (const [name, value] of Object.entries(attrs || {})) {
+    node.setAttribute(name, value);
+  }
+  if (text !== undefined) {
+    node.textContent = text;
+  }
+  return node;
+}
+
+function svgElement(tag, attrs, text) {
+  const node = document.createElementNS(SVG_NS, tag);
+  for (const [name, value] of Object.entries(attrs || {})) {
+    node.setAttribute(name, value);
+  }
+  if (text !== undefined) {
+    node.textContent = text;
+  }
+  return node;
+}
+
+function truncate(text, length) {
+  return text.length > length ? text.slice(0, length - 1) + "…" : text;
+}
+
+function displayName(symbol) {
+  return symbol.container ? symbol.container + "." + symbol.name : symbol.name;
+}
+
+async function loadStatus() {
+  try {
+    const health = await api("/health");
+    statusLine.textContent = health.symbols + " symbols";
+  } catch (err) {
+    statusLine.textContent = err.message;
+  }
+}
+
+async function runSearch(query) {
+  results.replaceChildren();
+  if (!query) {
+    results.append(element("p", { class: "hint" }, "Search for a symbol to start exploring."));
+    return;
+  }
+  let body;
+  try {
+    body = await api("/search?limit=30&q=" + encodeURIComponent(query));
+  } catch (err) {
+    results.append(element("p", { class: "hint" }, err.message));
+    return;
+  }
+  if (body.matches.length === 0) {
+    results.append(element("p", { class: "hint" }, "No symbols match “" + query + "”."));
+    return;
+  }
+  for (const match of body.matches) {
+    const button = element("button", { class: "result", type: "button", "data-id": match.id });
+    button.append(element("div", { class: "name" }, displayName(match)));
+    button.append(element("div", { class: "meta" }, match.kind + " · " + match.file + ":" + match.line));
+    button.addEventListener("click", () => select(match.id, true));
+    results.append(button);
+  }
+}
+
+async function select(id, resetTrail) {
+  let symbol, callers, callees;
+  try {
+    [symbol, callers, callees] = await Promise.all([
+      api(symbolPath("/symbols", id)),
+      api(symbolPath("/callers", id)),
+      api(symbolPath("/callees", id)),
+    ]);
+  } catch (err) {
+    details.replaceChildren(element("p", { class: "hint" }, err.message));
+    return;
+  }
+  if (resetTrail) {
+    visited = [];
+  }
+  const existing = visited.findIndex((entry) => entry.id === id);
+  if (existing >= 0) {
+    visited = visited.slice(0, existing + 1);
+  } else {
+    visited.push(symbol.symbol);
+  }
+  if (window.location.hash !== "#symbol=" + encodeURIComponent(id)) {
+    history.replaceState(null, "", "#symbol=" + encodeURIComponent(id));
+  }
+  for (const button of results.querySelectorAll(".result")) {
+    button.classList.toggle("active", button.dataset.id === id);
+  }
+  renderTrail();
+  renderDetails(symbol);
+  renderGraph(symbol.symbol, callers.callers, callees.callees);
+}
+
+function renderTrail() {
+  trail.replaceChildren();
+  visited.forEach((symbol, index) => {
+    if (index > 0) {
+      trail.append(element("span", { class: "sep" }, "›"));
+    }
+    const link = element("a", { title: symbol.id }, displayName(symbol));
+    link.addEventListener("click", () => select(symbol.id, false));
+    trail.append(link);
+  });
+}
+
+function renderDetails(body) {
+  const symbol = body.symbol;
+  details.replaceChildren();
+  details.append(element("h2", {}, displayName(symbol)));
+  details.append(element("p", { class: "meta" }, symbol.kind + " · " + symbol.file + ":" + symbol.line));
+  details.append(element("p", { class: "meta" }, body.callers + " callers · " + body.callees + " callees"));
+  if (symbol.signature) {
+    details.append(element("h3", {}, "Signature"));
+    details.append(element("pre", {}, symbol.signature));
+  }
+  if (body.doc) {
+    details.append(element("h3", {}, "Doc"));
+    details.append(element("pre", {}, body.doc));
+  }
+  const summary = body.enrich;
+  if (summary && (summary.summary || summary.purpose || summary.side_effects)) {
+    details.append(element("h3", {}, "Enrich summary"));
+    if (summary.summary) {
+      details.append(element("p", {}, summary.summary));
+    }
+    if (summary.purpose) {
+      details.append(element("p", {}, "Purpose: " + summary.purpose));
+    }
+    if (summary.side_effects) {
+      details.append(element("p", {}, "Side effects: " + summary.side_effects));
+    }
+    if (summary.confidence) {
+      details.append(element("p", { class: "meta" }, "Confidence: " + summary.confidence));
+    }
+  }
+  details.append(element("h3", {}, "ID"));
+  details.append(element("p", { class: "meta" }, symbol.id));
+}
+
+function renderGraph(focus, callers, callees) {
+  graph.replaceChildren();
+  const width = Math.max(graph.clientWidth, 3 * NODE_WIDTH + 160);
+  const shownCallers = callers.slice(0, MAX_NEIGHBORS);
+  const shownCallees = callees.slice(0, MAX_NEIGHBORS);
+  const rows = Math.max(shownCallers.length, shownCallees.length, 1);
+  const height = Math.max(graph.clientHeight, rows * (NODE_HEIGHT + ROW_GAP) + 80);
+  graph.setAttribute("viewBox", "0 0 " + width + " " + height);
+
+  const columns = { caller: 20, focus: (width - NODE_WIDTH) / 2, callee: width - NODE_WIDTH - 20 };
+  const focusY = (height - NODE_HEIGHT) / 2;
+  const edges = svgElement("g");
+  graph.append(edges);
+
+  columnLabel(columns.caller, "callers (" + callers.length + ")");
+  columnLabel(columns.callee, "callees (" + callees.length + ")");
+
+  const place = (records, role) => {
+    const total = records.length * (NODE_HEIGHT + ROW_GAP) - ROW_GAP;
+    let y = Math.max(40, (height - total) / 2);
+    for (const record of records) {
+      const x = columns[role];
+      drawNode(record.symbol, role, x, y, record.kind);
+      const fromX = role === "caller" ? x + NODE_WIDTH : columns.focus + NODE_WIDTH;
+      const toX = role === "caller" ? columns.focus : x;
+      const fromY = role === "caller" ? y + NODE_HEIGHT / 2 : focusY + NODE_HEIGHT / 2;
+      const toY = role === "caller" ? focusY + NODE_HEIGHT / 2 : y + NODE_HEIGHT / 2;
+      const middle = (fromX + toX) / 2;
+      edges.append(svgElement("path", {
+        class: "edge",
+        d: "M" + fromX + "," + fromY + " C" + middle + "," + fromY + " " + middle + "," + toY + " " + toX + "," + toY,
+      }));
+      y += NODE_HEIGHT + ROW_GAP;
+    }
+  };
+  place(shownCallers, "caller");
+  place(shownCallees, "callee");
+  drawNode(focus, "focus", columns.focus, focusY);
+
+  if (callers.length > shownCallers.length || callees.length > shownCallees.length) {
+    graph.append(svgElement("text", { class: "column-label", x: 20, y: height - 10 },
+      "showing the first " + MAX_NEIGHBORS + " callers and callees"));
+  }
+}
+
+function columnLabel(x, text) {
+  graph.append(svgElement("text", { class: "column-label", x: x, y: 24 }, text));
+}
+
+function drawNode(symbol, role, x, y, edgeKind) {
+  const group = svgElement("g", { class: "node " + role, transform: "translate(" + x + "," + y + ")" });
+  group.append(svgElement("title", {}, symbol.id));
+  group.append(svgElement("rect", { width: NODE_WIDTH, height: NODE_HEIGHT }));
+  group.append(svgElement("text", { x: 8, y: 17 }, truncate(displayName(symbol), 30)));
+  const kind = edgeKind && edgeKind !== "call" ? symbol.kind + " · " + edgeKind : symbol.kind;
+  group.append(svgElement("text", { class: "kind", x: 8, y: 32 }, truncate(kind + " · " + symbol.file, 40)));
+  if (role !== "focus") {
+    group.addEventListener("click", () => select(symbol.id, false));
+  }
+  graph.append(group);
+}
+
+function symbolFromHash() {
+  const match = window.location.hash.match(/^#symbol=(.+)$/);
+  return match ? decodeURIComponent(match[1]) : "";
+}
+
+let searchTimer;
+searchInput.addEventListener("input", () => {
+  clearTimeout(searchTimer);
+  searchTimer = setTimeout(() => runSearch(searchInput.value.trim()), 150);
+});
+searchForm.addEventListener("submit", (event) => {
+  event.preventDefault();
+  clearTimeout(searchTimer);
+  runSearch(searchInput.value.trim());
+});
+window.addEventListener("hashchange", () => {
+  const id = symbolFromHash();
+  if (id && (visited.length === 0 || visited[visited.length - 1].id !== id)) {
+    select(id, true);
+  }
+});
+
+loadStatus();
+if (symbolFromHash()) {
+  select(symbolFromHash(), true);
+}
@@ -0,0 +1,22 @@
+// Package webui embeds the static graph explorer served by `serve --ui`. It
+// is plain HTML, CSS and JavaScript that reads the JSON API, so it has no
+// build step.
+package webui
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+//go:embed static
+var static embed.FS
+
+// Handler serves the explorer's files from its root.
+func Handler() http.Handler {
+	files, err := fs.Sub(static, "static")
+	if err != nil {
+		panic(err)
+	}
+	return http.FileServerFS(files)
+}